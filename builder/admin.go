@@ -0,0 +1,188 @@
+package builder
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/builder/searcherauth"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
+)
+
+var errSearcherAuthDisabled = errors.New("searcher authentication is disabled")
+
+// adminMiner is the runtime configuration of the miner managed by the builder admin API.
+type adminMiner interface {
+	SetBundleFloor(config miner.BundleFloorConfig) error
+	BundleFloor() miner.BundleFloorConfig
+	SetAlgorithm(algo miner.AlgoType) error
+	Algorithms() []miner.AlgoType
+	SetActiveWorkers(count int) error
+	Workers() (active int, total int)
+}
+
+// BundleFloorArgs are the minimum effective gas price and coinbase profit of the bundles, in wei.
+// The floors of the first block of a Bor sprint default to the floors of the other blocks.
+type BundleFloorArgs struct {
+	MinGasPrice       *hexutil.Big `json:"minGasPrice"`
+	MinProfit         *hexutil.Big `json:"minProfit"`
+	SprintMinGasPrice *hexutil.Big `json:"sprintMinGasPrice,omitempty"`
+	SprintMinProfit   *hexutil.Big `json:"sprintMinProfit,omitempty"`
+}
+
+// AdminConfig is the runtime configuration of the builder.
+type AdminConfig struct {
+	BundleFloor BundleFloorArgs `json:"bundleFloor"`
+	Relays      []string        `json:"relays"`     // Remote relay endpoints, the primary relay first
+	Algorithms  []string        `json:"algorithms"` // Algorithms of the workers, the configured one first
+	Workers     int             `json:"workers"`    // Workers building blocks in parallel
+	MaxWorkers  int             `json:"maxWorkers"` // Workers the node was started with
+	Searchers   *SearcherLists  `json:"searchers,omitempty"`
+}
+
+// builderAdminAPI changes the configuration of the running builder from the authenticated
+// builderadmin API, without restarting the node. The changes are not written to the builder
+// config file, they are lost on restart and the relays are reset by a config file reload.
+type builderAdminAPI struct {
+	miner   adminMiner
+	builder *Builder
+	auth    *searcherauth.Authenticator // nil unless the bundle signers are authenticated
+
+	relayMu    sync.Mutex
+	cfg        Config // Config the relays of the builder were created from
+	localRelay *LocalRelay
+	relayKey   *ecdsa.PrivateKey
+}
+
+func newBuilderAdminAPI(miner adminMiner, builder *Builder, auth *searcherauth.Authenticator, cfg *Config, localRelay *LocalRelay, relayKey *ecdsa.PrivateKey) *builderAdminAPI {
+	return &builderAdminAPI{
+		miner:      miner,
+		builder:    builder,
+		auth:       auth,
+		cfg:        *cfg,
+		localRelay: localRelay,
+		relayKey:   relayKey,
+	}
+}
+
+// GetConfig returns the runtime configuration of the builder.
+func (api *builderAdminAPI) GetConfig() AdminConfig {
+	floor := api.miner.BundleFloor()
+	config := AdminConfig{
+		BundleFloor: BundleFloorArgs{
+			MinGasPrice:       (*hexutil.Big)(floor.MinGasPrice),
+			MinProfit:         (*hexutil.Big)(floor.MinProfit),
+			SprintMinGasPrice: (*hexutil.Big)(floor.SprintMinGasPrice),
+			SprintMinProfit:   (*hexutil.Big)(floor.SprintMinProfit),
+		},
+		Relays: api.relayEndpoints(),
+	}
+	for _, algo := range api.miner.Algorithms() {
+		config.Algorithms = append(config.Algorithms, algo.String())
+	}
+	config.Workers, config.MaxWorkers = api.miner.Workers()
+	if api.auth != nil {
+		allowlist, denylist := api.auth.Lists()
+		config.Searchers = &SearcherLists{Allowlist: allowlist, Denylist: denylist}
+	}
+	return config
+}
+
+// SetBundleFloor replaces the floors of the bundles simulated from now on, omitted floors are
+// disabled.
+func (api *builderAdminAPI) SetBundleFloor(args BundleFloorArgs) (bool, error) {
+	config := miner.BundleFloorConfig{
+		MinGasPrice:       (*big.Int)(args.MinGasPrice),
+		MinProfit:         (*big.Int)(args.MinProfit),
+		SprintMinGasPrice: (*big.Int)(args.SprintMinGasPrice),
+		SprintMinProfit:   (*big.Int)(args.SprintMinProfit),
+	}
+	if err := api.miner.SetBundleFloor(config); err != nil {
+		return false, err
+	}
+	log.Info("Replaced bundle floors", "minGasPrice", config.MinGasPrice, "minProfit", config.MinProfit,
+		"sprintMinGasPrice", config.SprintMinGasPrice, "sprintMinProfit", config.SprintMinProfit)
+	return true, nil
+}
+
+// SetRelays replaces the remote relays the blocks are submitted to, the primary relay first. The
+// endpoints have the format of --builder.remote_relay_endpoint, the relay policies of the config
+// file apply to the endpoints they name.
+func (api *builderAdminAPI) SetRelays(endpoints []string) (bool, error) {
+	if len(endpoints) == 0 || endpoints[0] == "" {
+		return false, errors.New("no relay endpoint")
+	}
+	if _, ok := api.builder.getRelay().(*LocalRelay); ok {
+		return false, errors.New("the local relay can't be replaced")
+	}
+
+	api.relayMu.Lock()
+	defer api.relayMu.Unlock()
+
+	next := api.cfg
+	next.RemoteRelayEndpoint = endpoints[0]
+	next.SecondaryRemoteRelayEndpoints = endpoints[1:]
+	relay, err := newRelay(&next, api.localRelay, api.relayKey)
+	if err != nil {
+		return false, err
+	}
+	if err := api.builder.setRelay(relay); err != nil {
+		return false, fmt.Errorf("failed to start relays: %w", err)
+	}
+	api.cfg = next
+
+	log.Info("Replaced builder relays", "primary", next.RemoteRelayEndpoint, "secondary", next.SecondaryRemoteRelayEndpoints)
+	return true, nil
+}
+
+// SetAlgorithm replaces the block building algorithm of the blocks built from now on, the
+// parallel workers run the other greedy algorithms.
+func (api *builderAdminAPI) SetAlgorithm(algo string) (bool, error) {
+	algoType, err := miner.AlgoTypeFlagToEnum(algo)
+	if err != nil {
+		return false, err
+	}
+	if err := api.miner.SetAlgorithm(algoType); err != nil {
+		return false, err
+	}
+	log.Info("Replaced block building algorithm", "algo", algoType)
+	return true, nil
+}
+
+// SetWorkers sets the number of workers building blocks in parallel, up to the number of
+// workers the node was started with.
+func (api *builderAdminAPI) SetWorkers(count int) (bool, error) {
+	if err := api.miner.SetActiveWorkers(count); err != nil {
+		return false, err
+	}
+	log.Info("Changed the block building workers", "workers", count)
+	return true, nil
+}
+
+// SetSearcherLists replaces the allowlist and the denylist of the bundle signers.
+func (api *builderAdminAPI) SetSearcherLists(lists SearcherLists) (bool, error) {
+	if api.auth == nil {
+		return false, errSearcherAuthDisabled
+	}
+	api.auth.SetLists(lists.Allowlist, lists.Denylist)
+	log.Info("Replaced searcher lists", "allowlisted", len(lists.Allowlist), "denylisted", len(lists.Denylist))
+	return true, nil
+}
+
+// relayEndpoints returns the endpoints of the remote relays, the primary relay first.
+func (api *builderAdminAPI) relayEndpoints() []string {
+	api.relayMu.Lock()
+	defer api.relayMu.Unlock()
+
+	var endpoints []string
+	for _, endpoint := range append([]string{api.cfg.RemoteRelayEndpoint}, api.cfg.SecondaryRemoteRelayEndpoints...) {
+		if endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,106 @@
+package builder
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/builder/searcherauth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/stretchr/testify/require"
+)
+
+type testAdminMiner struct {
+	floor  miner.BundleFloorConfig
+	algo   miner.AlgoType
+	active int
+}
+
+func (m *testAdminMiner) SetBundleFloor(config miner.BundleFloorConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	m.floor = config
+	return nil
+}
+
+func (m *testAdminMiner) BundleFloor() miner.BundleFloorConfig { return m.floor }
+
+func (m *testAdminMiner) SetAlgorithm(algo miner.AlgoType) error {
+	if algo == miner.ALGO_MEV_GETH {
+		return errors.New("mev-geth")
+	}
+	m.algo = algo
+	return nil
+}
+
+func (m *testAdminMiner) Algorithms() []miner.AlgoType { return []miner.AlgoType{m.algo} }
+
+func (m *testAdminMiner) SetActiveWorkers(count int) error {
+	if count < 1 || count > 2 {
+		return errors.New("invalid worker count")
+	}
+	m.active = count
+	return nil
+}
+
+func (m *testAdminMiner) Workers() (int, int) { return m.active, 2 }
+
+func TestBuilderAdminAPI(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.RemoteRelayEndpoint = "http://relay1.example"
+	initial := NewRemoteRelay(RelayConfig{Endpoint: cfg.RemoteRelayEndpoint}, nil, false)
+	builder := &Builder{relay: initial}
+	m := &testAdminMiner{algo: miner.ALGO_GREEDY, active: 2}
+	api := newBuilderAdminAPI(m, builder, nil, &cfg, nil, nil)
+
+	config := api.GetConfig()
+	require.Equal(t, []string{"http://relay1.example"}, config.Relays)
+	require.Equal(t, []string{"greedy"}, config.Algorithms)
+	require.Equal(t, 2, config.Workers)
+	require.Nil(t, config.Searchers)
+
+	// profit floors
+	_, err := api.SetBundleFloor(BundleFloorArgs{MinProfit: (*hexutil.Big)(big.NewInt(-1))})
+	require.Error(t, err)
+	_, err = api.SetBundleFloor(BundleFloorArgs{MinGasPrice: (*hexutil.Big)(big.NewInt(10)), MinProfit: (*hexutil.Big)(big.NewInt(1000))})
+	require.NoError(t, err)
+	config = api.GetConfig()
+	require.Equal(t, big.NewInt(10), config.BundleFloor.MinGasPrice.ToInt())
+	require.Equal(t, big.NewInt(1000), config.BundleFloor.MinProfit.ToInt())
+
+	// algorithm and workers
+	_, err = api.SetAlgorithm("unknown")
+	require.Error(t, err)
+	_, err = api.SetAlgorithm("greedy-buckets")
+	require.NoError(t, err)
+	require.Equal(t, miner.ALGO_GREEDY_BUCKETS, m.algo)
+	_, err = api.SetWorkers(3)
+	require.Error(t, err)
+	_, err = api.SetWorkers(1)
+	require.NoError(t, err)
+	require.Equal(t, 1, api.GetConfig().Workers)
+
+	// relays, the builder submits to the new relays
+	_, err = api.SetRelays(nil)
+	require.Error(t, err)
+	_, err = api.SetRelays([]string{"http://relay2.example", "http://relay3.example;ssz=true"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://relay2.example", "http://relay3.example;ssz=true"}, api.GetConfig().Relays)
+	aggregator, ok := builder.getRelay().(*RemoteRelayAggregator)
+	require.True(t, ok)
+	require.Len(t, aggregator.relays, 2)
+	require.Equal(t, "http://relay2.example", aggregator.relays[0].Config().Endpoint)
+
+	// searcher lists, only with searcher authentication
+	lists := SearcherLists{Allowlist: []common.Address{{1}}, Denylist: []common.Address{{2}}}
+	_, err = api.SetSearcherLists(lists)
+	require.ErrorIs(t, err, errSearcherAuthDisabled)
+
+	api.auth = searcherauth.New(searcherauth.Config{})
+	_, err = api.SetSearcherLists(lists)
+	require.NoError(t, err)
+	require.Equal(t, &lists, api.GetConfig().Searchers)
+}
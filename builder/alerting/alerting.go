@@ -0,0 +1,250 @@
+// Package alerting posts builder anomalies to operator webhooks.
+//
+// An alert is delivered as an HTTP POST to every configured webhook. The body is the JSON
+// encoded Alert, or the output of a Go text/template executed with the Alert, so payloads
+// can match what Slack, PagerDuty or any other receiver expects. Alerts of the same kind
+// are rate limited, the alerts dropped in between are counted in the next delivered one.
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+type Kind string
+
+const (
+	KindBuildFailure        Kind = "build_failure"        // The miner failed to build a block for a slot
+	KindLowProfit           Kind = "low_profit"           // Block profit stayed below the threshold for consecutive slots
+	KindRelayDemotion       Kind = "relay_demotion"       // A relay demoted the builder
+	KindSnapshotInvalidated Kind = "snapshot_invalidated" // The multi-tx snapshot stack of a build could not be used
+)
+
+const (
+	// DefaultMinInterval is the default minimum interval between two alerts of the same kind
+	DefaultMinInterval = 5 * time.Minute
+
+	// queueSize bounds the alerts waiting to be delivered
+	queueSize = 64
+	// webhookTimeout bounds the duration of a webhook call
+	webhookTimeout = 10 * time.Second
+)
+
+var (
+	firedMeter      = metrics.NewRegisteredMeter("builder/alerts/fired", nil)
+	suppressedMeter = metrics.NewRegisteredMeter("builder/alerts/suppressed", nil)
+	failedMeter     = metrics.NewRegisteredMeter("builder/alerts/failed", nil)
+)
+
+// defaultNotifier holds the process-wide *Notifier used by Fire
+var defaultNotifier atomic.Value
+
+// Alert is an anomaly detected by the builder, and the data available to payload templates.
+type Alert struct {
+	Kind    Kind              `json:"kind"`
+	Time    time.Time         `json:"time"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+	// Suppressed is the number of alerts of the same kind dropped by the rate limit since the
+	// last delivered one
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+var templateFuncs = template.FuncMap{
+	// json encodes a value, e.g. {{json .Message}} to embed a string in a JSON payload
+	"json": func(v interface{}) (string, error) {
+		encoded, err := json.Marshal(v)
+		return string(encoded), err
+	},
+}
+
+// Notifier rate limits alerts and delivers them to the webhooks from a background goroutine.
+type Notifier struct {
+	webhooks    []string
+	template    *template.Template
+	minInterval time.Duration
+	client      *http.Client
+
+	queue chan *Alert
+	quit  chan struct{}
+	wg    sync.WaitGroup
+
+	mu         sync.Mutex
+	lastFired  map[Kind]time.Time
+	suppressed map[Kind]int
+	closed     bool
+}
+
+// New creates a notifier posting to the given webhooks. The payload template is optional,
+// alerts are posted as JSON without one. minInterval is the minimum interval between two
+// alerts of the same kind, 0 disables the rate limit.
+func New(webhooks []string, payloadTemplate string, minInterval time.Duration) (*Notifier, error) {
+	n := &Notifier{
+		webhooks:    webhooks,
+		minInterval: minInterval,
+		client:      &http.Client{Timeout: webhookTimeout},
+		queue:       make(chan *Alert, queueSize),
+		quit:        make(chan struct{}),
+		lastFired:   make(map[Kind]time.Time),
+		suppressed:  make(map[Kind]int),
+	}
+	if payloadTemplate != "" {
+		tmpl, err := template.New("alert").Funcs(templateFuncs).Parse(payloadTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert payload template: %w", err)
+		}
+		n.template = tmpl
+	}
+	return n, nil
+}
+
+// Fire queues an alert, unless an alert of the same kind was fired less than the minimum
+// interval ago, the queue is full or the notifier is closed.
+func (n *Notifier) Fire(kind Kind, message string, details map[string]string) {
+	now := time.Now()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.closed {
+		return
+	}
+	if last, ok := n.lastFired[kind]; ok && now.Sub(last) < n.minInterval {
+		n.suppressed[kind]++
+		suppressedMeter.Mark(1)
+		return
+	}
+	alert := &Alert{
+		Kind:       kind,
+		Time:       now.UTC(),
+		Message:    message,
+		Details:    details,
+		Suppressed: n.suppressed[kind],
+	}
+	select {
+	case n.queue <- alert:
+		n.lastFired[kind] = now
+		n.suppressed[kind] = 0
+		firedMeter.Mark(1)
+		log.Warn("Builder alert fired", "kind", kind, "message", message)
+	default:
+		n.suppressed[kind]++
+		suppressedMeter.Mark(1)
+	}
+}
+
+// payload renders the webhook body of an alert.
+func (n *Notifier) payload(alert *Alert) ([]byte, error) {
+	if n.template == nil {
+		return json.Marshal(alert)
+	}
+	var buf bytes.Buffer
+	if err := n.template.Execute(&buf, alert); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (n *Notifier) deliver(alert *Alert) {
+	payload, err := n.payload(alert)
+	if err != nil {
+		failedMeter.Mark(1)
+		log.Error("Failed to render builder alert", "kind", alert.Kind, "err", err)
+		return
+	}
+	for _, webhook := range n.webhooks {
+		if err := n.post(webhook, payload); err != nil {
+			failedMeter.Mark(1)
+			log.Warn("Failed to deliver builder alert", "kind", alert.Kind, "err", err)
+		}
+	}
+}
+
+func (n *Notifier) post(webhook string, payload []byte) error {
+	resp, err := n.client.Post(webhook, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+func (n *Notifier) loop() {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case alert := <-n.queue:
+			n.deliver(alert)
+		case <-n.quit:
+			// deliver what was queued before shutdown
+			for {
+				select {
+				case alert := <-n.queue:
+					n.deliver(alert)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Start implements node.Lifecycle, starting the delivery goroutine.
+func (n *Notifier) Start() error {
+	n.wg.Add(1)
+	go n.loop()
+	return nil
+}
+
+// Stop implements node.Lifecycle, delivering the queued alerts.
+func (n *Notifier) Stop() error {
+	n.mu.Lock()
+	if n.closed {
+		n.mu.Unlock()
+		return nil
+	}
+	n.closed = true
+	n.mu.Unlock()
+
+	close(n.quit)
+	n.wg.Wait()
+	return nil
+}
+
+// SetDefault installs the process-wide notifier used by Fire. Passing nil disables it.
+func SetDefault(n *Notifier) {
+	defaultNotifier.Store(&n)
+}
+
+// Enabled returns true if a process-wide notifier is installed.
+func Enabled() bool {
+	return getDefault() != nil
+}
+
+func getDefault() *Notifier {
+	n, ok := defaultNotifier.Load().(**Notifier)
+	if !ok {
+		return nil
+	}
+	return *n
+}
+
+// Fire raises an alert through the process-wide notifier, if one is installed.
+func Fire(kind Kind, message string, details map[string]string) {
+	if n := getDefault(); n != nil {
+		n.Fire(kind, message, details)
+	}
+}
@@ -0,0 +1,84 @@
+package alerting
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func serveWebhook(t *testing.T) (*httptest.Server, <-chan []byte) {
+	bodies := make(chan []byte, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	return server, bodies
+}
+
+func TestNotifierRateLimit(t *testing.T) {
+	server, bodies := serveWebhook(t)
+	defer server.Close()
+
+	notifier, err := New([]string{server.URL}, "", time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Start())
+
+	notifier.Fire(KindBuildFailure, "Failed to build block", map[string]string{"slot": "1"})
+	notifier.Fire(KindBuildFailure, "Failed to build block", map[string]string{"slot": "2"})
+	notifier.Fire(KindRelayDemotion, "Relay demoted the builder", nil)
+
+	var alert Alert
+	require.NoError(t, json.Unmarshal(<-bodies, &alert))
+	require.Equal(t, KindBuildFailure, alert.Kind)
+	require.Equal(t, "1", alert.Details["slot"])
+	require.Zero(t, alert.Suppressed)
+
+	// other kinds are not rate limited by the build failures
+	require.NoError(t, json.Unmarshal(<-bodies, &alert))
+	require.Equal(t, KindRelayDemotion, alert.Kind)
+
+	// the next alert of the kind counts the dropped ones
+	notifier.mu.Lock()
+	notifier.lastFired[KindBuildFailure] = time.Now().Add(-2 * time.Hour)
+	notifier.mu.Unlock()
+	notifier.Fire(KindBuildFailure, "Failed to build block", map[string]string{"slot": "3"})
+	require.NoError(t, json.Unmarshal(<-bodies, &alert))
+	require.Equal(t, "3", alert.Details["slot"])
+	require.Equal(t, 1, alert.Suppressed)
+
+	require.NoError(t, notifier.Stop())
+	// alerts fired after shutdown are dropped
+	notifier.Fire(KindLowProfit, "Block profit below threshold", nil)
+	require.Empty(t, bodies)
+}
+
+func TestNotifierTemplate(t *testing.T) {
+	server, bodies := serveWebhook(t)
+	defer server.Close()
+
+	notifier, err := New([]string{server.URL}, `{"text": {{json (printf "%s: %s" .Kind .Message)}}, "slot": {{json .Details.slot}}}`, 0)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Start())
+	defer notifier.Stop()
+
+	notifier.Fire(KindSnapshotInvalidated, `Snapshot "stack" invalidated`, map[string]string{"slot": "7"})
+
+	var payload struct {
+		Text string `json:"text"`
+		Slot string `json:"slot"`
+	}
+	require.NoError(t, json.Unmarshal(<-bodies, &payload))
+	require.Equal(t, `snapshot_invalidated: Snapshot "stack" invalidated`, payload.Text)
+	require.Equal(t, "7", payload.Slot)
+}
+
+func TestInvalidTemplate(t *testing.T) {
+	_, err := New([]string{"http://localhost"}, "{{.Kind", 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package builder
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/builder/alerting"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// lowProfitWatch raises an alert when the best block built for a slot is worth less than
+// the threshold for a number of consecutive slots.
+type lowProfitWatch struct {
+	threshold *big.Int
+	slots     int
+
+	mu     sync.Mutex
+	slot   uint64   // Slot being built
+	best   *big.Int // Best block value of the slot being built
+	streak int      // Consecutive past slots below the threshold
+}
+
+func newLowProfitWatch(threshold *big.Int, slots int) *lowProfitWatch {
+	if slots < 1 {
+		slots = 1
+	}
+	return &lowProfitWatch{threshold: threshold, slots: slots}
+}
+
+// built records the value of a block built for the slot. The previous slot is settled when
+// the first block of a later slot is built.
+func (w *lowProfitWatch) built(slot uint64, value *big.Int) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if slot < w.slot {
+		return
+	}
+	if slot > w.slot {
+		if w.best != nil {
+			w.settle()
+		}
+		w.slot = slot
+		w.best = nil
+	}
+	if w.best == nil || value.Cmp(w.best) > 0 {
+		w.best = new(big.Int).Set(value)
+	}
+}
+
+// settle counts the slot being built in the streak, alerting every slots consecutive slots
+// below the threshold.
+func (w *lowProfitWatch) settle() {
+	if w.best.Cmp(w.threshold) >= 0 {
+		w.streak = 0
+		return
+	}
+	w.streak++
+	if w.streak%w.slots != 0 {
+		return
+	}
+	alerting.Fire(alerting.KindLowProfit,
+		fmt.Sprintf("Block profit below %s wei for %d consecutive slots", w.threshold, w.streak),
+		map[string]string{
+			"slot":      strconv.FormatUint(w.slot, 10),
+			"value":     w.best.String(),
+			"threshold": w.threshold.String(),
+			"streak":    strconv.Itoa(w.streak),
+		})
+}
+
+// isDemotion returns true if a relay rejected a submission because the builder was demoted.
+// Relays report the demotion in the body of the error response.
+func isDemotion(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "demot")
+}
+
+// alertBlockSubmission raises an alert if a relay submission failed because of a demotion
+func alertBlockSubmission(slot uint64, blockHash common.Hash, err error) {
+	if !isDemotion(err) {
+		return
+	}
+	alerting.Fire(alerting.KindRelayDemotion, "Relay demoted the builder", map[string]string{
+		"slot":  strconv.FormatUint(slot, 10),
+		"block": blockHash.Hex(),
+		"error": err.Error(),
+	})
+}
+
+// alertBuildFailure raises an alert for a failed block build
+func alertBuildFailure(attrs *types.BuilderPayloadAttributes, err error) {
+	alerting.Fire(alerting.KindBuildFailure, "Failed to build block", map[string]string{
+		"slot":   strconv.FormatUint(attrs.Slot, 10),
+		"parent": attrs.HeadHash.Hex(),
+		"error":  err.Error(),
+	})
+}
@@ -0,0 +1,60 @@
+package builder
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/builder/alerting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLowProfitWatch(t *testing.T) {
+	alerts := make(chan alerting.Alert, 8)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert alerting.Alert
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &alert))
+		alerts <- alert
+	}))
+	defer server.Close()
+
+	notifier, err := alerting.New([]string{server.URL}, "", 0)
+	require.NoError(t, err)
+	require.NoError(t, notifier.Start())
+	alerting.SetDefault(notifier)
+	defer alerting.SetDefault(nil)
+
+	watch := newLowProfitWatch(big.NewInt(100), 2)
+	// the best block of the slot counts
+	watch.built(1, big.NewInt(50))
+	watch.built(1, big.NewInt(150))
+	watch.built(2, big.NewInt(50))
+	watch.built(3, big.NewInt(50))
+	// the streak is reached once slot 3 is settled
+	watch.built(4, big.NewInt(200))
+	require.Equal(t, 2, watch.streak)
+	watch.built(5, big.NewInt(10))
+	require.Equal(t, 0, watch.streak)
+	require.NoError(t, notifier.Stop())
+
+	require.Len(t, alerts, 1)
+	alert := <-alerts
+	require.Equal(t, alerting.KindLowProfit, alert.Kind)
+	require.Equal(t, "3", alert.Details["slot"])
+	require.Equal(t, "2", alert.Details["streak"])
+
+	// a nil watch is disabled
+	var disabled *lowProfitWatch
+	disabled.built(1, big.NewInt(1))
+}
+
+func TestIsDemotion(t *testing.T) {
+	require.True(t, isDemotion(errors.New(`non-ok response code 400 from relay: {"code":400,"message":"builder is Demoted"}`)))
+	require.False(t, isDemotion(errors.New("non-ok response code 500 from relay")))
+	require.False(t, isDemotion(nil))
+}
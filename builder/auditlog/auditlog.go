@@ -0,0 +1,397 @@
+// Package auditlog implements an append-only, hash-chained log of builder decisions.
+//
+// Every record commits to the hash of the record before it, so any modification,
+// removal or reordering of past records breaks the chain and is detected by Verify.
+// Records are stored as one JSON object per line, and are synced to disk before Append
+// returns. A record torn by a crash while it was written is dropped when the log is opened.
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+type EventType string
+
+const (
+	EventBundleReceived  EventType = "bundle_received"
+	EventSBundleReceived EventType = "sbundle_received"
+	EventBundleIncluded  EventType = "bundle_included"
+	EventBundleExcluded  EventType = "bundle_excluded"
+	EventBlockSubmitted  EventType = "block_submitted"
+
+	EventReservationUnfilled EventType = "reservation_unfilled"
+)
+
+const (
+	// maxRecordSize bounds a single line of the log, written or read back
+	maxRecordSize = 16 * 1024 * 1024
+
+	// queueSize is the number of records of the process-wide log waiting to be written before
+	// Append blocks
+	queueSize = 4096
+)
+
+var (
+	ErrBrokenChain    = errors.New("audit log chain is broken")
+	ErrInvalidHash    = errors.New("audit log record hash mismatch")
+	ErrInvalidSeq     = errors.New("audit log record out of sequence")
+	ErrLogIsClosed    = errors.New("audit log is closed")
+	ErrRecordTooLarge = errors.New("audit log record too large")
+)
+
+// defaultAuditLog holds the process-wide *Log written to by Append
+var defaultAuditLog atomic.Value
+
+// Record is a single entry of the audit log.
+type Record struct {
+	Seq      uint64          `json:"seq"`
+	Time     time.Time       `json:"time"`
+	Type     EventType       `json:"type"`
+	Data     json.RawMessage `json:"data"`
+	PrevHash common.Hash     `json:"prevHash"`
+	Hash     common.Hash     `json:"hash"`
+}
+
+// ComputeHash returns the hash committing to the record contents and the previous record.
+func (r *Record) ComputeHash() common.Hash {
+	var buf [16]byte
+	binary.BigEndian.PutUint64(buf[:8], r.Seq)
+	binary.BigEndian.PutUint64(buf[8:], uint64(r.Time.UnixNano()))
+	return crypto.Keccak256Hash(buf[:], []byte(r.Type), r.Data, r.PrevHash[:])
+}
+
+// queuedRecord is a record of the process-wide log waiting to be written.
+type queuedRecord struct {
+	typ  EventType
+	data json.RawMessage
+}
+
+// Log is an append-only, hash-chained audit log backed by a file.
+type Log struct {
+	syncMu sync.Mutex // Held while syncing, before mu
+	synced uint64     // Number of records synced to disk
+
+	mu     sync.Mutex
+	file   *os.File
+	seq    uint64
+	head   common.Hash
+	closed bool
+
+	queueMu sync.RWMutex // Held to send to the queue, and to close it
+	queue   chan queuedRecord
+	done    chan struct{} // Closed once the queued records are written
+}
+
+// Open opens the audit log at path, creating it if necessary. An existing log is verified
+// and new records continue its chain. A partial record at the end of the log, left by a
+// crash while it was written, is truncated.
+func Open(path string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	torn, err := truncateTornRecord(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to recover audit log %s: %w", path, err)
+	}
+	if torn > 0 {
+		log.Warn("Truncated partial audit log record", "path", path, "bytes", torn)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	last, err := verify(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("existing audit log %s is invalid: %w", path, err)
+	}
+
+	l := &Log{
+		file:  file,
+		queue: make(chan queuedRecord, queueSize),
+		done:  make(chan struct{}),
+	}
+	if last != nil {
+		l.seq = last.Seq + 1
+		l.head = last.Hash
+	}
+	l.synced = l.seq
+	go l.writeQueued(l.queue)
+	return l, nil
+}
+
+// truncateTornRecord truncates the bytes after the last newline of the log, a record whose
+// write was interrupted, and returns their number. Records are always written with their
+// trailing newline at once, so only the last record can be partial.
+func truncateTornRecord(file *os.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	var (
+		size = info.Size()
+		end  = size
+		buf  = make([]byte, 64*1024)
+	)
+	for end > 0 {
+		n := int64(len(buf))
+		if n > end {
+			n = end
+		}
+		if _, err := file.ReadAt(buf[:n], end-n); err != nil {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			end = end - n + int64(i) + 1
+			break
+		}
+		end -= n
+	}
+	if end == size {
+		return 0, nil
+	}
+	if err := file.Truncate(end); err != nil {
+		return 0, err
+	}
+	return size - end, file.Sync()
+}
+
+// Append adds a record with the JSON encoding of data to the log, and returns once the record
+// is synced to disk.
+func (l *Log) Append(typ EventType, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	seq, err := l.write(typ, encoded)
+	if err != nil {
+		return err
+	}
+	return l.sync(seq)
+}
+
+// write appends a record to the file and returns the number of records written.
+func (l *Log) write(typ EventType, data json.RawMessage) (uint64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return 0, ErrLogIsClosed
+	}
+
+	record := Record{
+		Seq:      l.seq,
+		Time:     time.Now().UTC(),
+		Type:     typ,
+		Data:     data,
+		PrevHash: l.head,
+	}
+	record.Hash = record.ComputeHash()
+
+	line, err := json.Marshal(&record)
+	if err != nil {
+		return 0, err
+	}
+	if len(line)+1 > maxRecordSize {
+		return 0, fmt.Errorf("%w: %d bytes", ErrRecordTooLarge, len(line)+1)
+	}
+	if _, err := l.file.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+
+	l.seq++
+	l.head = record.Hash
+	return l.seq, nil
+}
+
+// sync syncs the file until the record seq is on disk. The records written by concurrent
+// appends while the file was synced are synced at once.
+func (l *Log) sync(seq uint64) error {
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+
+	if l.synced >= seq {
+		return nil
+	}
+	l.mu.Lock()
+	written, closed := l.seq, l.closed
+	l.mu.Unlock()
+	if closed {
+		// the records are synced on close
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.synced = written
+	return nil
+}
+
+// enqueue queues a record to be written by the writer goroutine of the log, blocking while the
+// queue is full.
+func (l *Log) enqueue(typ EventType, data json.RawMessage) error {
+	l.queueMu.RLock()
+	defer l.queueMu.RUnlock()
+
+	if l.queue == nil {
+		return ErrLogIsClosed
+	}
+	l.queue <- queuedRecord{typ: typ, data: data}
+	return nil
+}
+
+// writeQueued writes the queued records until the queue is closed. The file is synced once the
+// queue is drained.
+func (l *Log) writeQueued(queue chan queuedRecord) {
+	defer close(l.done)
+
+	for record := range queue {
+		seq, err := l.write(record.typ, record.data)
+		if err == nil && len(queue) == 0 {
+			err = l.sync(seq)
+		}
+		if err != nil {
+			log.Error("Failed to write audit log record", "type", record.typ, "err", err)
+		}
+	}
+}
+
+// Head returns the number of records in the log and the hash of the last record.
+func (l *Log) Head() (uint64, common.Hash) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.seq, l.head
+}
+
+// Close writes the queued records, then flushes and closes the underlying file.
+func (l *Log) Close() error {
+	l.queueMu.Lock()
+	if l.queue != nil {
+		close(l.queue)
+		l.queue = nil
+	}
+	l.queueMu.Unlock()
+	<-l.done
+
+	l.syncMu.Lock()
+	defer l.syncMu.Unlock()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if err := l.file.Sync(); err != nil {
+		l.file.Close()
+		return err
+	}
+	return l.file.Close()
+}
+
+// Start implements node.Lifecycle.
+func (l *Log) Start() error {
+	return nil
+}
+
+// Stop implements node.Lifecycle, closing the log on node shutdown.
+func (l *Log) Stop() error {
+	return l.Close()
+}
+
+// Verify checks the hash chain of an audit log and returns the number of valid records.
+func Verify(r io.Reader) (uint64, error) {
+	last, err := verify(r)
+	if err != nil {
+		return 0, err
+	}
+	if last == nil {
+		return 0, nil
+	}
+	return last.Seq + 1, nil
+}
+
+func verify(r io.Reader) (*Record, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+
+	var (
+		last *Record
+		seq  uint64
+		prev common.Hash
+	)
+	for scanner.Scan() {
+		record := new(Record)
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, fmt.Errorf("record %d: %w", seq, err)
+		}
+		if record.Seq != seq {
+			return nil, fmt.Errorf("%w: expected seq %d, found %d", ErrInvalidSeq, seq, record.Seq)
+		}
+		if record.PrevHash != prev {
+			return nil, fmt.Errorf("%w at record %d", ErrBrokenChain, seq)
+		}
+		if record.ComputeHash() != record.Hash {
+			return nil, fmt.Errorf("%w at record %d", ErrInvalidHash, seq)
+		}
+		last = record
+		prev = record.Hash
+		seq++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return last, nil
+}
+
+// SetDefault installs the process-wide audit log used by Append. Passing nil disables auditing.
+func SetDefault(l *Log) {
+	defaultAuditLog.Store(&l)
+}
+
+// Enabled returns true if a process-wide audit log is installed.
+func Enabled() bool {
+	return getDefault() != nil
+}
+
+func getDefault() *Log {
+	l, ok := defaultAuditLog.Load().(**Log)
+	if !ok {
+		return nil
+	}
+	return *l
+}
+
+// Append adds a record to the process-wide audit log, if one is installed. The record is
+// written in the background, so that the callers don't wait for the disk.
+func Append(typ EventType, data interface{}) {
+	l := getDefault()
+	if l == nil {
+		return
+	}
+	encoded, err := json.Marshal(data)
+	if err == nil {
+		err = l.enqueue(typ, encoded)
+	}
+	if err != nil {
+		log.Error("Failed to write audit log record", "type", typ, "err", err)
+	}
+}
@@ -0,0 +1,145 @@
+package auditlog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, l.Append(EventBundleReceived, &BundleReceived{BundleHash: common.HexToHash("0x01"), BlockNumber: 10}))
+	require.NoError(t, l.Append(EventBundleIncluded, &BundleDecision{BundleHash: common.HexToHash("0x01"), BlockNumber: 10}))
+	_, head := l.Head()
+	require.NoError(t, l.Close())
+	require.ErrorIs(t, l.Append(EventBlockSubmitted, &BlockSubmission{}), ErrLogIsClosed)
+
+	// reopening continues the chain
+	l, err = Open(path)
+	require.NoError(t, err)
+	seq, reopenedHead := l.Head()
+	require.Equal(t, uint64(2), seq)
+	require.Equal(t, head, reopenedHead)
+	require.NoError(t, l.Append(EventBlockSubmitted, &BlockSubmission{BlockNumber: 10}))
+	require.NoError(t, l.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records, err := Verify(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), records)
+}
+
+func TestAuditLogTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := Open(path)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, l.Append(EventBundleExcluded, &BundleDecision{BlockNumber: uint64(i)}))
+	}
+	require.NoError(t, l.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := bytes.SplitAfter(data, []byte("\n"))
+
+	// modified record contents
+	modified := bytes.Join([][]byte{lines[0], bytes.Replace(lines[1], []byte(`"blockNumber":1`), []byte(`"blockNumber":7`), 1), lines[2]}, nil)
+	_, err = Verify(bytes.NewReader(modified))
+	require.True(t, errors.Is(err, ErrInvalidHash), "unexpected error: %v", err)
+
+	// removed record
+	removed := bytes.Join([][]byte{lines[0], lines[2]}, nil)
+	_, err = Verify(bytes.NewReader(removed))
+	require.True(t, errors.Is(err, ErrInvalidSeq), "unexpected error: %v", err)
+
+	// reordered records
+	reordered := bytes.Join([][]byte{lines[1], lines[0], lines[2]}, nil)
+	_, err = Verify(bytes.NewReader(reordered))
+	require.Error(t, err)
+
+	// tampered log cannot be reopened
+	require.NoError(t, os.WriteFile(path, modified, 0600))
+	_, err = Open(path)
+	require.ErrorIs(t, err, ErrInvalidHash)
+}
+
+func TestAuditLogTornRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := Open(path)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, l.Append(EventBundleExcluded, &BundleDecision{BlockNumber: uint64(i)}))
+	}
+	_, head := l.Head()
+	require.NoError(t, l.Close())
+
+	// a crash while a record was written leaves a partial line at the end of the log
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, append(data, []byte(`{"seq":2,"time":"20`)...), 0600))
+
+	l, err = Open(path)
+	require.NoError(t, err)
+	seq, reopenedHead := l.Head()
+	require.Equal(t, uint64(2), seq)
+	require.Equal(t, head, reopenedHead)
+	require.NoError(t, l.Append(EventBundleExcluded, &BundleDecision{BlockNumber: 2}))
+	require.NoError(t, l.Close())
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	records, err := Verify(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), records)
+}
+
+func TestAuditLogRecordTooLarge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := Open(path)
+	require.NoError(t, err)
+	require.ErrorIs(t, l.Append(EventBundleReceived, &BundleReceived{TxHashes: make([]common.Hash, maxRecordSize/common.HashLength)}), ErrRecordTooLarge)
+	require.NoError(t, l.Append(EventBundleReceived, &BundleReceived{BlockNumber: 10}))
+	require.NoError(t, l.Close())
+
+	// the log stays readable
+	l, err = Open(path)
+	require.NoError(t, err)
+	seq, _ := l.Head()
+	require.Equal(t, uint64(1), seq)
+	require.NoError(t, l.Close())
+}
+
+func TestAuditLogDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := Open(path)
+	require.NoError(t, err)
+	SetDefault(l)
+	defer SetDefault(nil)
+
+	// the records of the process-wide log are written in the background, and on close
+	for i := 0; i < 100; i++ {
+		Append(EventBundleExcluded, &BundleDecision{BlockNumber: uint64(i)})
+	}
+	require.NoError(t, l.Close())
+	seq, _ := l.Head()
+	require.Equal(t, uint64(100), seq)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records, err := Verify(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), records)
+}
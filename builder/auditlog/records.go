@@ -0,0 +1,73 @@
+package auditlog
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+// BundleReceived is recorded for every bundle accepted through the bundle APIs.
+type BundleReceived struct {
+	BundleHash      common.Hash    `json:"bundleHash,omitempty"`
+	BlockNumber     uint64         `json:"blockNumber"`
+	SigningAddress  common.Address `json:"signingAddress"`
+	ReplacementUuid string         `json:"replacementUuid,omitempty"`
+	MinTimestamp    uint64         `json:"minTimestamp,omitempty"`
+	MaxTimestamp    uint64         `json:"maxTimestamp,omitempty"`
+	TxHashes        []common.Hash  `json:"txHashes"`
+}
+
+// NewBundleReceived builds the record of an accepted bundle. The bundle hash matches the one
+// assigned by the transaction pool.
+func NewBundleReceived(txs types.Transactions, blockNumber uint64, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp, maxTimestamp uint64) *BundleReceived {
+	record := &BundleReceived{
+		BlockNumber:    blockNumber,
+		SigningAddress: signingAddress,
+		MinTimestamp:   minTimestamp,
+		MaxTimestamp:   maxTimestamp,
+		TxHashes:       make([]common.Hash, len(txs)),
+	}
+	hashes := make([]byte, 0, len(txs)*common.HashLength)
+	for i, tx := range txs {
+		record.TxHashes[i] = tx.Hash()
+		hashes = append(hashes, record.TxHashes[i].Bytes()...)
+	}
+	record.BundleHash = crypto.Keccak256Hash(hashes)
+	if replacementUuid != uuid.Nil {
+		record.ReplacementUuid = replacementUuid.String()
+	}
+	return record
+}
+
+// BundleDecision is recorded for every simulated bundle considered for a block submitted to a
+// relay, as either EventBundleIncluded or EventBundleExcluded.
+type BundleDecision struct {
+	BlockNumber       uint64       `json:"blockNumber"`
+	ParentHash        common.Hash  `json:"parentHash"`
+	BundleHash        common.Hash  `json:"bundleHash"`
+	SBundle           bool         `json:"sbundle,omitempty"`
+	EthSentToCoinbase *hexutil.Big `json:"ethSentToCoinbase,omitempty"`
+	MevGasPrice       *hexutil.Big `json:"mevGasPrice,omitempty"`
+}
+
+// BlockSubmission is recorded for every block submitted to a relay.
+type BlockSubmission struct {
+	Slot             uint64        `json:"slot"`
+	BlockNumber      uint64        `json:"blockNumber"`
+	BlockHash        common.Hash   `json:"blockHash"`
+	ParentHash       common.Hash   `json:"parentHash"`
+	Value            *hexutil.Big  `json:"value"`
+	CommittedBundles []common.Hash `json:"committedBundles"`
+	Error            string        `json:"error,omitempty"`
+}
+
+// TopOfBlockReservation is recorded as EventReservationUnfilled for every block whose gas reserved
+// at the top of the block was not entirely used by the designated searchers and contracts.
+type TopOfBlockReservation struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	ParentHash  common.Hash `json:"parentHash"`
+	ReservedGas uint64      `json:"reservedGas"`
+	UsedGas     uint64      `json:"usedGas"`
+}
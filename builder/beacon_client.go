@@ -15,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/faultinject"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/r3labs/sse"
 )
@@ -283,6 +284,10 @@ func (b *BeaconClient) SubscribeToPayloadAttributesEvents(payloadAttrC chan type
 	for {
 		client := sse.NewClient(eventsURL)
 		err := client.SubscribeRawWithContext(b.ctx, func(msg *sse.Event) {
+			if faultinject.Trigger(faultinject.BeaconOutage) {
+				log.Debug("dropping payload_attributes event", "err", faultinject.ErrInjected)
+				return
+			}
 			err := json.Unmarshal(msg.Data, payloadAttributesResp)
 			if err != nil {
 				log.Error("could not unmarshal payload_attributes event", "err", err)
@@ -378,6 +383,11 @@ func fetchEpochProposersMap(endpoint string, epoch uint64) (map[uint64]PubkeyHex
 }
 
 func fetchBeacon(url string, dst any) error {
+	if faultinject.Trigger(faultinject.BeaconOutage) {
+		log.Error("client refused", "url", url, "err", faultinject.ErrInjected)
+		return faultinject.ErrInjected
+	}
+
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		log.Error("invalid request", "url", url, "err", err)
@@ -16,7 +16,12 @@ import (
 	"github.com/attestantio/go-eth2-client/spec/capella"
 	"github.com/attestantio/go-eth2-client/spec/phase0"
 	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/builder/auditlog"
+	"github.com/ethereum/go-ethereum/builder/eventexport"
+	"github.com/ethereum/go-ethereum/builder/profiling"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
 	"github.com/ethereum/go-ethereum/flashbotsextra"
@@ -56,12 +61,23 @@ type IRelay interface {
 
 type IBuilder interface {
 	OnPayloadAttribute(attrs *types.BuilderPayloadAttributes) error
+	MissedSlots() []SlotPostmortem
+	ProfitProgression(slot *uint64) []SlotProfitProgression
+	ShadowComparisons() []ShadowComparison
+	ShadowSummary() ShadowSummary
+	ProducerSchedule(number *uint64) ([]ScheduledProducer, error)
+	Prebuild(parent common.Hash) error
+	PauseSubmissions(paused bool)
+	Drain()
+	Resume()
+	Status() BuilderStatus
 	Start() error
 	Stop() error
 }
 
 type Builder struct {
 	ds                          flashbotsextra.IDatabaseService
+	relayMu                     sync.RWMutex // Guards the relay, which can be replaced at runtime
 	relay                       IRelay
 	eth                         IEthereumService
 	dryRun                      bool
@@ -71,12 +87,27 @@ type Builder struct {
 	builderSecretKey            *bls.SecretKey
 	builderPublicKey            phase0.BLSPubKey
 	builderSigningDomain        phase0.Domain
-	builderResubmitInterval     time.Duration
 	discardRevertibleTxOnErr    bool
 
-	limiter                       *rate.Limiter
+	limiter *rate.Limiter
+
+	// timingMu guards the timing settings, which are reloaded from the builder config file
+	timingMu                      sync.RWMutex
+	builderResubmitInterval       time.Duration
 	submissionOffsetFromEndOfSlot time.Duration
 
+	schedule    *sealSchedule       // Bor sealing deadlines of the building jobs, nil to follow the beacon slots
+	producers   *producerSchedule   // Bor producers in turn, nil to build every slot
+	prebuilt    *prebuiltCandidates // Candidates pre-built on the likely parents of a reorg
+	slots       *slotTracker
+	progression *profitProgression
+	candidates  *candidateDump // Debug dump of the candidate blocks, nil if disabled
+	lowProfit   *lowProfitWatch
+	shadow      *shadowTracker // Shadow mode, blocks are compared with the landed blocks instead of submitted, nil if disabled
+	standby     *standby       // Hot standby of another builder, nil if the builder is active
+
+	control builderControl
+
 	slotMu        sync.Mutex
 	slotAttrs     types.BuilderPayloadAttributes
 	slotCtx       context.Context
@@ -99,6 +130,12 @@ type BuilderArgs struct {
 	validator                     *blockvalidation.BlockValidationAPI
 	beaconClient                  IBeaconClient
 	submissionOffsetFromEndOfSlot time.Duration
+	schedule                      *sealSchedule
+	producers                     *producerSchedule
+	lowProfit                     *lowProfitWatch
+	candidateDump                 *candidateDump
+	shadow                        *shadowTracker
+	standby                       *standby
 
 	limiter *rate.Limiter
 }
@@ -125,8 +162,16 @@ func NewBuilder(args BuilderArgs) (*Builder, error) {
 		args.submissionOffsetFromEndOfSlot = SubmissionOffsetFromEndOfSlotSecondsDefault
 	}
 
+	// Slot outcomes persisted to the database survive restarts
+	slots := newSlotTracker()
+	if args.ds != nil {
+		if err := slots.restore(args.ds); err != nil {
+			log.Error("could not restore slot outcomes", "err", err)
+		}
+	}
+
 	slotCtx, slotCtxCancel := context.WithCancel(context.Background())
-	return &Builder{
+	builder := &Builder{
 		ds:                            args.ds,
 		relay:                         args.relay,
 		eth:                           args.eth,
@@ -140,13 +185,28 @@ func NewBuilder(args BuilderArgs) (*Builder, error) {
 		builderResubmitInterval:       args.builderBlockResubmitInterval,
 		discardRevertibleTxOnErr:      args.discardRevertibleTxOnErr,
 		submissionOffsetFromEndOfSlot: args.submissionOffsetFromEndOfSlot,
+		schedule:                      args.schedule,
+		producers:                     args.producers,
+		prebuilt:                      newPrebuiltCandidates(),
+		slots:                         slots,
+		progression:                   newProfitProgression(),
+		candidates:                    args.candidateDump,
+		lowProfit:                     args.lowProfit,
+		shadow:                        args.shadow,
+		standby:                       args.standby,
 
 		limiter:       args.limiter,
 		slotCtx:       slotCtx,
 		slotCtxCancel: slotCtxCancel,
 
 		stop: make(chan struct{}, 1),
-	}, nil
+	}
+	// A standby builds with its submissions paused until it takes over from the active builder
+	if args.standby != nil {
+		builder.control.setSubmissionsPaused(true)
+		args.standby.promote = func() { builder.control.setSubmissionsPaused(false) }
+	}
+	return builder, nil
 }
 
 func (b *Builder) Start() error {
@@ -192,7 +252,7 @@ func (b *Builder) Start() error {
 		}
 	}()
 
-	return b.relay.Start()
+	return b.getRelay().Start()
 }
 
 func (b *Builder) Stop() error {
@@ -200,20 +260,69 @@ func (b *Builder) Stop() error {
 	return nil
 }
 
+// getRelay returns the relay the blocks are submitted to.
+func (b *Builder) getRelay() IRelay {
+	b.relayMu.RLock()
+	defer b.relayMu.RUnlock()
+	return b.relay
+}
+
+// setRelay starts the relay and submits the blocks to it from now on, the previous relay is
+// stopped. The submissions already running complete with the previous relay.
+func (b *Builder) setRelay(relay IRelay) error {
+	if err := relay.Start(); err != nil {
+		return err
+	}
+	b.relayMu.Lock()
+	previous := b.relay
+	b.relay = relay
+	b.relayMu.Unlock()
+
+	previous.Stop()
+	return nil
+}
+
+// timing returns the block resubmit interval and the submission offset from the end of the slot.
+func (b *Builder) timing() (time.Duration, time.Duration) {
+	b.timingMu.RLock()
+	defer b.timingMu.RUnlock()
+	return b.builderResubmitInterval, b.submissionOffsetFromEndOfSlot
+}
+
+// setTiming updates the timing settings, building jobs already running keep their settings.
+func (b *Builder) setTiming(resubmitInterval, submissionOffset time.Duration) {
+	b.timingMu.Lock()
+	defer b.timingMu.Unlock()
+	b.builderResubmitInterval = resubmitInterval
+	b.submissionOffsetFromEndOfSlot = submissionOffset
+}
+
 func (b *Builder) onSealedBlock(block *types.Block, blockValue *big.Int, ordersClosedAt, sealedAt time.Time,
 	commitedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle,
-	proposerPubkey phase0.BLSPubKey, vd ValidatorData, attrs *types.BuilderPayloadAttributes) error {
+	proposerPubkey phase0.BLSPubKey, vd ValidatorData, attrs *types.BuilderPayloadAttributes, round uint64) error {
+	if !b.control.submissionsAllowed() {
+		log.Debug("submissions paused, not submitting block", "round", round, "slot", attrs.Slot, "value", blockValue.String(), "hash", block.Hash())
+		return nil
+	}
+	if b.shadow != nil {
+		b.shadow.candidate(attrs.Slot, block, blockValue, common.Address(vd.FeeRecipient), commitedBundles)
+		log.Info("shadow block", "round", round, "slot", attrs.Slot, "value", blockValue.String(), "parent", block.ParentHash(),
+			"hash", block.Hash(), "#commitedBundles", len(commitedBundles))
+		return nil
+	}
+
 	if b.eth.Config().IsShanghai(block.Time()) {
-		if err := b.submitCapellaBlock(block, blockValue, ordersClosedAt, sealedAt, commitedBundles, allBundles, usedSbundles, proposerPubkey, vd, attrs); err != nil {
+		if err := b.submitCapellaBlock(block, blockValue, ordersClosedAt, sealedAt, commitedBundles, allBundles, usedSbundles, proposerPubkey, vd, attrs, round); err != nil {
 			return err
 		}
 	} else {
-		if err := b.submitBellatrixBlock(block, blockValue, ordersClosedAt, sealedAt, commitedBundles, allBundles, usedSbundles, proposerPubkey, vd, attrs); err != nil {
+		if err := b.submitBellatrixBlock(block, blockValue, ordersClosedAt, sealedAt, commitedBundles, allBundles, usedSbundles, proposerPubkey, vd, attrs, round); err != nil {
 			return err
 		}
 	}
 
-	log.Info("submitted block", "slot", attrs.Slot, "value", blockValue.String(), "parent", block.ParentHash,
+	b.candidates.submitted(attrs.Slot, block.Hash())
+	log.Info("submitted block", "round", round, "slot", attrs.Slot, "value", blockValue.String(), "parent", block.ParentHash,
 		"hash", block.Hash(), "#commitedBundles", len(commitedBundles))
 
 	return nil
@@ -221,7 +330,7 @@ func (b *Builder) onSealedBlock(block *types.Block, blockValue *big.Int, ordersC
 
 func (b *Builder) submitBellatrixBlock(block *types.Block, blockValue *big.Int, ordersClosedAt, sealedAt time.Time,
 	commitedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle,
-	proposerPubkey phase0.BLSPubKey, vd ValidatorData, attrs *types.BuilderPayloadAttributes) error {
+	proposerPubkey phase0.BLSPubKey, vd ValidatorData, attrs *types.BuilderPayloadAttributes, round uint64) error {
 	executableData := engine.BlockToExecutableData(block, blockValue)
 	payload, err := executableDataToExecutionPayload(executableData.ExecutionPayload)
 	if err != nil {
@@ -266,21 +375,27 @@ func (b *Builder) submitBellatrixBlock(block *types.Block, blockValue *big.Int,
 		}
 	} else {
 		go b.ds.ConsumeBuiltBlock(block, blockValue, ordersClosedAt, sealedAt, commitedBundles, allBundles, usedSbundles, &blockBidMsg)
-		err = b.relay.SubmitBlock(&blockSubmitReq, vd)
+		submitStart := time.Now()
+		err = submitBlock(b.getRelay(), &blockSubmitReq, vd, bundleSearchers(commitedBundles))
+		markBlockSubmission(submitStart, blockValue, err)
+		b.slots.submitted(attrs.Slot, block, blockValue, err)
+		auditBlockSubmission(block, blockValue, attrs.Slot, commitedBundles, allBundles, usedSbundles, err)
+		exportBlockSubmission(block, blockValue, attrs.Slot, err)
+		alertBlockSubmission(attrs.Slot, block.Hash(), err)
 		if err != nil {
-			log.Error("could not submit bellatrix block", "err", err, "#commitedBundles", len(commitedBundles))
+			log.Error("could not submit bellatrix block", "round", round, "err", err, "#commitedBundles", len(commitedBundles))
 			return err
 		}
 	}
 
-	log.Info("submitted bellatrix block", "slot", blockBidMsg.Slot, "value", blockBidMsg.Value.String(), "parent", blockBidMsg.ParentHash, "hash", block.Hash(), "#commitedBundles", len(commitedBundles))
+	log.Info("submitted bellatrix block", "round", round, "slot", blockBidMsg.Slot, "value", blockBidMsg.Value.String(), "parent", blockBidMsg.ParentHash, "hash", block.Hash(), "#commitedBundles", len(commitedBundles))
 
 	return nil
 }
 
 func (b *Builder) submitCapellaBlock(block *types.Block, blockValue *big.Int, ordersClosedAt, sealedAt time.Time,
 	commitedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle,
-	proposerPubkey phase0.BLSPubKey, vd ValidatorData, attrs *types.BuilderPayloadAttributes) error {
+	proposerPubkey phase0.BLSPubKey, vd ValidatorData, attrs *types.BuilderPayloadAttributes, round uint64) error {
 	executableData := engine.BlockToExecutableData(block, blockValue)
 	payload, err := executableDataToCapellaExecutionPayload(executableData.ExecutionPayload)
 	if err != nil {
@@ -325,23 +440,116 @@ func (b *Builder) submitCapellaBlock(block *types.Block, blockValue *big.Int, or
 		}
 	} else {
 		go b.ds.ConsumeBuiltBlock(block, blockValue, ordersClosedAt, sealedAt, commitedBundles, allBundles, usedSbundles, &blockBidMsg)
-		err = b.relay.SubmitBlockCapella(&blockSubmitReq, vd)
+		submitStart := time.Now()
+		err = submitBlockCapella(b.getRelay(), &blockSubmitReq, vd, bundleSearchers(commitedBundles))
+		markBlockSubmission(submitStart, blockValue, err)
+		b.slots.submitted(attrs.Slot, block, blockValue, err)
+		auditBlockSubmission(block, blockValue, attrs.Slot, commitedBundles, allBundles, usedSbundles, err)
+		exportBlockSubmission(block, blockValue, attrs.Slot, err)
+		alertBlockSubmission(attrs.Slot, block.Hash(), err)
 		if err != nil {
-			log.Error("could not submit capella block", "err", err, "#commitedBundles", len(commitedBundles))
+			log.Error("could not submit capella block", "round", round, "err", err, "#commitedBundles", len(commitedBundles))
 			return err
 		}
 	}
 
-	log.Info("submitted capella block", "slot", blockBidMsg.Slot, "value", blockBidMsg.Value.String(), "parent", blockBidMsg.ParentHash, "hash", block.Hash(), "#commitedBundles", len(commitedBundles))
+	log.Info("submitted capella block", "round", round, "slot", blockBidMsg.Slot, "value", blockBidMsg.Value.String(), "parent", blockBidMsg.ParentHash, "hash", block.Hash(), "#commitedBundles", len(commitedBundles))
 	return nil
 }
 
+// auditBlockSubmission records a relay submission in the audit log, after the inclusion decision
+// of every bundle and sbundle considered for the block
+func auditBlockSubmission(block *types.Block, blockValue *big.Int, slot uint64, commitedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle, err error) {
+	if !auditlog.Enabled() {
+		return
+	}
+	auditBundleDecisions(block.Header(), commitedBundles, allBundles, usedSbundles)
+
+	record := auditlog.BlockSubmission{
+		Slot:             slot,
+		BlockNumber:      block.NumberU64(),
+		BlockHash:        block.Hash(),
+		ParentHash:       block.ParentHash(),
+		Value:            (*hexutil.Big)(blockValue),
+		CommittedBundles: make([]common.Hash, len(commitedBundles)),
+	}
+	for i, bundle := range commitedBundles {
+		record.CommittedBundles[i] = bundle.OriginalBundle.Hash
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	auditlog.Append(auditlog.EventBlockSubmitted, &record)
+}
+
+// auditBundleDecisions records the inclusion decision for every bundle and sbundle considered for
+// a submitted block
+func auditBundleDecisions(header *types.Header, blockBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle) {
+	included := make(map[common.Hash]struct{}, len(blockBundles))
+	for _, bundle := range blockBundles {
+		included[bundle.OriginalBundle.Hash] = struct{}{}
+	}
+
+	for _, bundle := range allBundles {
+		decision := auditlog.BundleDecision{
+			BlockNumber: header.Number.Uint64(),
+			ParentHash:  header.ParentHash,
+			BundleHash:  bundle.OriginalBundle.Hash,
+		}
+		if bundle.EthSentToCoinbase != nil {
+			decision.EthSentToCoinbase = (*hexutil.Big)(bundle.EthSentToCoinbase)
+		}
+		if bundle.MevGasPrice != nil {
+			decision.MevGasPrice = (*hexutil.Big)(bundle.MevGasPrice)
+		}
+
+		if _, ok := included[bundle.OriginalBundle.Hash]; ok {
+			auditlog.Append(auditlog.EventBundleIncluded, &decision)
+		} else {
+			auditlog.Append(auditlog.EventBundleExcluded, &decision)
+		}
+	}
+
+	for _, sbundle := range usedSbundles {
+		decision := auditlog.BundleDecision{
+			BlockNumber: header.Number.Uint64(),
+			ParentHash:  header.ParentHash,
+			BundleHash:  sbundle.Bundle.Hash(),
+			SBundle:     true,
+		}
+		if sbundle.Success {
+			auditlog.Append(auditlog.EventBundleIncluded, &decision)
+		} else {
+			auditlog.Append(auditlog.EventBundleExcluded, &decision)
+		}
+	}
+}
+
+// exportBlockSubmission publishes a relay submission to the event exporter
+func exportBlockSubmission(block *types.Block, blockValue *big.Int, slot uint64, err error) {
+	if !eventexport.Enabled() {
+		return
+	}
+
+	event := eventexport.BlockSubmitted{
+		Slot:        slot,
+		BlockNumber: block.NumberU64(),
+		BlockHash:   block.Hash(),
+		ParentHash:  block.ParentHash(),
+		Value:       (*hexutil.Big)(blockValue),
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	eventexport.Publish(eventexport.EventBlockSubmitted, &event)
+}
+
 func (b *Builder) OnPayloadAttribute(attrs *types.BuilderPayloadAttributes) error {
 	if attrs == nil {
 		return nil
 	}
 
-	vd, err := b.relay.GetValidatorForSlot(attrs.Slot)
+	vd, err := b.getRelay().GetValidatorForSlot(attrs.Slot)
 	if err != nil {
 		return fmt.Errorf("could not get validator while submitting block for slot %d - %w", attrs.Slot, err)
 	}
@@ -362,10 +570,21 @@ func (b *Builder) OnPayloadAttribute(attrs *types.BuilderPayloadAttributes) erro
 	if parentBlock == nil {
 		return fmt.Errorf("parent block hash not found in block tree given head block hash %s", attrs.HeadHash)
 	}
+	if !b.producers.shouldBuild(parentBlock.NumberU64() + 1) {
+		log.Debug("skipping slot, no producer of ours in turn", "slot", attrs.Slot, "number", parentBlock.NumberU64()+1)
+		return nil
+	}
+	b.slots.resolve(attrs.Slot, parentBlock, b.eth.GetBlockByHash)
+	b.shadow.resolve(attrs.Slot, parentBlock, b.eth.GetBlockByHash)
+	b.slots.attributes(attrs.Slot, parentBlock, common.Address(vd.FeeRecipient))
+	deadline := b.schedule.deadline(parentBlock, uint64(attrs.Timestamp))
 
 	b.slotMu.Lock()
 	defer b.slotMu.Unlock()
 
+	if err := b.control.accept(attrs.Slot); err != nil {
+		return err
+	}
 	if attrs.Equal(&b.slotAttrs) {
 		log.Debug("ignoring known payload attribute", "slot", attrs.Slot, "hash", attrs.HeadHash)
 		return nil
@@ -375,15 +594,70 @@ func (b *Builder) OnPayloadAttribute(attrs *types.BuilderPayloadAttributes) erro
 		b.slotCtxCancel()
 	}
 
-	slotCtx, slotCtxCancel := context.WithTimeout(context.Background(), 12*time.Second)
+	slotCtx, slotCtxCancel := b.schedule.jobContext(context.Background(), deadline)
 	b.slotAttrs = *attrs
 	b.slotCtx = slotCtx
 	b.slotCtxCancel = slotCtxCancel
 
-	go b.runBuildingJob(b.slotCtx, proposerPubkey, vd, attrs)
+	b.control.jobStarted()
+	go b.runBuildingJob(b.slotCtx, proposerPubkey, vd, attrs, deadline)
 	return nil
 }
 
+// MissedSlots returns the postmortems of the recent slots the builder submitted blocks for
+// that landed another block, most recent first.
+func (b *Builder) MissedSlots() []SlotPostmortem {
+	return b.slots.missedSlots()
+}
+
+// ProfitProgression returns the value of the successive candidate blocks built for the given
+// slot, or for the recent slots most recent first if slot is nil.
+func (b *Builder) ProfitProgression(slot *uint64) []SlotProfitProgression {
+	return b.progression.progressions(slot)
+}
+
+// ShadowComparisons returns the comparisons of the recent slots built in shadow mode with the
+// blocks that landed, most recent first.
+func (b *Builder) ShadowComparisons() []ShadowComparison {
+	return b.shadow.list()
+}
+
+// ShadowSummary returns the totals of the slots built in shadow mode since the start.
+func (b *Builder) ShadowSummary() ShadowSummary {
+	return b.shadow.getSummary()
+}
+
+// PauseSubmissions pauses or resumes the block submissions, the blocks are still built.
+func (b *Builder) PauseSubmissions(paused bool) {
+	b.control.setSubmissionsPaused(paused)
+}
+
+// Drain lets the builder finish the slot it is building, and rejects the payload attributes
+// of the later slots until Resume.
+func (b *Builder) Drain() {
+	b.slotMu.Lock()
+	defer b.slotMu.Unlock()
+	b.control.drain(b.slotAttrs.Slot)
+}
+
+// Resume lifts the drain and the submission pause.
+func (b *Builder) Resume() {
+	b.control.resume()
+}
+
+// Status returns the runtime state of the builder.
+func (b *Builder) Status() BuilderStatus {
+	b.slotMu.Lock()
+	status := BuilderStatus{Slot: b.slotAttrs.Slot, DryRun: b.dryRun, Shadow: b.shadow != nil}
+	b.slotMu.Unlock()
+
+	b.control.status(&status)
+	if b.standby != nil {
+		status.Standby = b.standby.status()
+	}
+	return status
+}
+
 type blockQueueEntry struct {
 	block           *types.Block
 	blockValue      *big.Int
@@ -392,10 +666,12 @@ type blockQueueEntry struct {
 	commitedBundles []types.SimulatedBundle
 	allBundles      []types.SimulatedBundle
 	usedSbundles    []types.UsedSBundle
+	round           uint64 // Build round of the block in the logs
 }
 
-func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.BLSPubKey, vd ValidatorData, attrs *types.BuilderPayloadAttributes) {
-	ctx, cancel := context.WithTimeout(slotCtx, 12*time.Second)
+func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.BLSPubKey, vd ValidatorData, attrs *types.BuilderPayloadAttributes, deadline time.Time) {
+	defer b.control.jobDone()
+	ctx, cancel := b.schedule.jobContext(slotCtx, deadline)
 	defer cancel()
 
 	// Submission queue for the given payload attributes
@@ -418,11 +694,14 @@ func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.
 	submitBestBlock := func() {
 		queueMu.Lock()
 		if queueBestEntry.block.Hash() != queueLastSubmittedHash {
-			err := b.onSealedBlock(queueBestEntry.block, queueBestEntry.blockValue, queueBestEntry.ordersCloseTime, queueBestEntry.sealedAt,
-				queueBestEntry.commitedBundles, queueBestEntry.allBundles, queueBestEntry.usedSbundles, proposerPubkey, vd, attrs)
+			var err error
+			profiling.Do(profiling.StageSubmit, func() {
+				err = b.onSealedBlock(queueBestEntry.block, queueBestEntry.blockValue, queueBestEntry.ordersCloseTime, queueBestEntry.sealedAt,
+					queueBestEntry.commitedBundles, queueBestEntry.allBundles, queueBestEntry.usedSbundles, proposerPubkey, vd, attrs, queueBestEntry.round)
+			})
 
 			if err != nil {
-				log.Error("could not run sealed block hook", "err", err)
+				log.Error("could not run sealed block hook", "round", queueBestEntry.round, "err", err)
 			} else {
 				queueLastSubmittedHash = queueBestEntry.block.Hash()
 			}
@@ -431,22 +710,28 @@ func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.
 	}
 
 	// Avoid submitting early into a given slot. For example if slots have 12 second interval, submissions should
-	// not begin until 8 seconds into the slot.
-	slotTime := time.Unix(int64(attrs.Timestamp), 0).UTC()
-	slotSubmitStartTime := slotTime.Add(-b.submissionOffsetFromEndOfSlot)
+	// not begin until 8 seconds into the slot. Scheduled jobs submit the offset before the sealing deadline.
+	resubmitInterval, submissionOffset := b.timing()
+	slotTime := deadline
+	slotSubmitStartTime := slotTime.Add(-submissionOffset)
 
 	// Empties queue, submits the best block for current job with rate limit (global for all jobs)
 	go runResubmitLoop(ctx, b.limiter, queueSignal, submitBestBlock, slotSubmitStartTime)
 
 	// Populates queue with submissions that increase block profit
 	blockHook := func(block *types.Block, blockValue *big.Int, ordersCloseTime time.Time,
-		committedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle,
+		committedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle, round uint64,
 	) {
 		if ctx.Err() != nil {
 			return
 		}
 
 		sealedAt := time.Now()
+		markCandidateBlock(blockValue)
+		b.slots.sealed(attrs.Slot)
+		b.progression.candidate(attrs.Slot, slotTime, block.Hash(), blockValue)
+		b.candidates.candidate(attrs.Slot, block, blockValue, sealedAt, committedBundles, allBundles, usedSbundles)
+		b.lowProfit.built(attrs.Slot, blockValue)
 
 		queueMu.Lock()
 		defer queueMu.Unlock()
@@ -459,6 +744,7 @@ func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.
 				commitedBundles: committedBundles,
 				allBundles:      allBundles,
 				usedSbundles:    usedSbundles,
+				round:           round,
 			}
 
 			select {
@@ -468,17 +754,58 @@ func (b *Builder) runBuildingJob(slotCtx context.Context, proposerPubkey phase0.
 		}
 	}
 
-	// resubmits block builder requests every builderBlockResubmitInterval
-	runRetryLoop(ctx, b.builderResubmitInterval, func() {
+	// a candidate pre-built on the parent of the job is submitted without waiting for a build
+	if entry, ok := b.prebuilt.take(prebuiltKey{slot: attrs.Slot, parent: attrs.HeadHash, timestamp: uint64(attrs.Timestamp)}); ok {
+		log.Debug("using pre-built block", "slot", attrs.Slot, "parent", attrs.HeadHash, "value", entry.blockValue)
+		blockHook(entry.block, entry.blockValue, entry.ordersCloseTime, entry.commitedBundles, entry.allBundles, entry.usedSbundles, entry.round)
+	}
+
+	buildBlock := func() {
 		log.Debug("retrying BuildBlock",
 			"slot", attrs.Slot,
 			"parent", attrs.HeadHash,
-			"resubmit-interval", b.builderResubmitInterval.String())
+			"resubmit-interval", resubmitInterval.String())
 		err := b.eth.BuildBlock(attrs, blockHook)
 		if err != nil {
 			log.Warn("Failed to build block", "err", err)
+			alertBuildFailure(attrs, err)
 		}
-	})
+	}
+
+	// scheduled jobs also re-build as new orderflow arrives, until the sealing deadline
+	if source, ok := b.eth.(orderflowSource); ok && b.schedule != nil {
+		runRebuildLoop(ctx, resubmitInterval, orderflowSignal(ctx, source), buildBlock)
+		return
+	}
+
+	// resubmits block builder requests every builderBlockResubmitInterval
+	runRetryLoop(ctx, resubmitInterval, buildBlock)
+}
+
+// orderflowSignal returns a channel signalled when new transactions arrive, until ctx is done.
+func orderflowSignal(ctx context.Context, source orderflowSource) <-chan struct{} {
+	var (
+		txsCh  = make(chan core.NewTxsEvent, 16)
+		signal = make(chan struct{}, 1)
+		sub    = source.SubscribeOrderflow(txsCh)
+	)
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.Err():
+				return
+			case <-txsCh:
+				select {
+				case signal <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return signal
 }
 
 func executableDataToExecutionPayload(data *engine.ExecutableData) (*bellatrix.ExecutionPayload, error) {
@@ -0,0 +1,178 @@
+// Package buildtrace writes a structured trace of every block building round: the bundles and
+// mempool transactions given to the algorithm, the ranking of the candidates, every commit
+// attempt with the resulting block profit, and the candidates left out with their reason. A
+// round of the trace can be replayed offline to debug profitability regressions.
+//
+// Rounds are written as one JSON object per line to a file. A round holds the raw transactions
+// of its inputs, traces grow quickly and are only meant for debugging sessions.
+package buildtrace
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/builder/decisionlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var ErrTraceIsClosed = errors.New("build trace is closed")
+
+// defaultTrace holds the process-wide *Trace written to by Write
+var defaultTrace atomic.Value
+
+// Bundle is a simulated bundle given to the block building algorithm.
+type Bundle struct {
+	Hash              common.Hash     `json:"hash"`
+	Txs               []hexutil.Bytes `json:"txs"` // Binary encoding of the transactions
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes,omitempty"`
+	SigningAddress    common.Address  `json:"signingAddress"`
+	MevGasPrice       *hexutil.Big    `json:"mevGasPrice,omitempty"`
+	Profit            *hexutil.Big    `json:"profit,omitempty"`   // Simulated coinbase profit, fees included
+	Priority          *hexutil.Big    `json:"priority,omitempty"` // Price the bundle was ordered by, if it differs from its mev gas price
+}
+
+// Step is a commit attempt of a candidate by the algorithm, in the order of the attempts.
+type Step struct {
+	Hash    common.Hash `json:"hash"`
+	SBundle bool        `json:"sbundle,omitempty"`
+	// Reason is decisionlog.ReasonIncluded if the candidate was committed, the error it was
+	// rejected with otherwise
+	Reason      string       `json:"reason"`
+	BlockProfit *hexutil.Big `json:"blockProfit,omitempty"` // Profit of the block after the commit
+}
+
+// Round is the trace of a block building round.
+type Round struct {
+	Time       time.Time     `json:"time"`
+	Algorithm  string        `json:"algorithm"`
+	ParentHash common.Hash   `json:"parentHash"`
+	Header     *types.Header `json:"header"` // Header the block was built with, before finalization
+	BlockHash  common.Hash   `json:"blockHash"`
+
+	Bundles []Bundle `json:"bundles"`
+	// SBundles are the hashes of the sbundles given to the algorithm, they are not replayed
+	SBundles []common.Hash   `json:"sbundles,omitempty"`
+	Mempool  []hexutil.Bytes `json:"mempool"` // Binary encoding of the pending transactions, by sender in nonce order
+
+	Candidates []decisionlog.Candidate `json:"candidates"` // Ranking of the candidates and their outcome
+	Steps      []Step                  `json:"steps"`
+	Profit     *hexutil.Big            `json:"profit"` // Profit of the built block
+}
+
+// Number returns the number of the block built in the round.
+func (r *Round) Number() uint64 {
+	if r.Header == nil || r.Header.Number == nil {
+		return 0
+	}
+	return r.Header.Number.Uint64()
+}
+
+// Trace writes rounds to a file.
+type Trace struct {
+	mu     sync.Mutex
+	file   *os.File
+	closed bool
+}
+
+// Open opens the trace at path, new rounds are appended to an existing trace.
+func Open(path string) (*Trace, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Trace{file: file}, nil
+}
+
+// Write appends the JSON encoding of the round to the trace.
+func (t *Trace) Write(round *Round) error {
+	encoded, err := json.Marshal(round)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return ErrTraceIsClosed
+	}
+	_, err = t.file.Write(append(encoded, '\n'))
+	return err
+}
+
+// Close closes the underlying file.
+func (t *Trace) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+	return t.file.Close()
+}
+
+// Start implements node.Lifecycle.
+func (t *Trace) Start() error {
+	return nil
+}
+
+// Stop implements node.Lifecycle, closing the trace on node shutdown.
+func (t *Trace) Stop() error {
+	return t.Close()
+}
+
+// Read decodes the rounds of a trace, calling fn for each of them until it returns false.
+func Read(r io.Reader, fn func(*Round) bool) error {
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for i := 0; ; i++ {
+		round := new(Round)
+		if err := decoder.Decode(round); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("invalid round %d: %w", i, err)
+		}
+		if !fn(round) {
+			return nil
+		}
+	}
+}
+
+// SetDefault installs the process-wide trace used by Write. Passing nil disables it.
+func SetDefault(t *Trace) {
+	defaultTrace.Store(&t)
+}
+
+// Enabled returns true if a process-wide trace is installed.
+func Enabled() bool {
+	return getDefault() != nil
+}
+
+func getDefault() *Trace {
+	t, ok := defaultTrace.Load().(**Trace)
+	if !ok {
+		return nil
+	}
+	return *t
+}
+
+// Write adds a round to the process-wide trace, if one is installed.
+func Write(round *Round) {
+	t := getDefault()
+	if t == nil {
+		return
+	}
+	if err := t.Write(round); err != nil {
+		log.Error("Failed to write build trace round", "block", round.Number(), "err", err)
+	}
+}
@@ -0,0 +1,171 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/redact"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+)
+
+// bundlePool is the pool of mev bundles managed by the bundle admin API.
+type bundlePool interface {
+	PooledMevBundles() []types.MevBundle
+	EvictMevBundles(hashes []common.Hash) int
+}
+
+// PooledBundle is a mev bundle of the pool.
+type PooledBundle struct {
+	Hash            common.Hash    `json:"hash"`
+	BlockNumber     *hexutil.Big   `json:"blockNumber"`
+	MaxBlockNumber  *hexutil.Big   `json:"maxBlockNumber,omitempty"`
+	MinTimestamp    uint64         `json:"minTimestamp,omitempty"`
+	MaxTimestamp    uint64         `json:"maxTimestamp,omitempty"`
+	ReplacementUuid *uuid.UUID     `json:"replacementUuid,omitempty"`
+	SigningAddress  common.Address `json:"signingAddress"`
+	Txs             int            `json:"txs"`
+}
+
+// PooledBundleTx is a transaction of a mev bundle of the pool.
+type PooledBundleTx struct {
+	Hash      common.Hash     `json:"hash"`
+	From      common.Address  `json:"from"`
+	To        *common.Address `json:"to"`
+	Nonce     hexutil.Uint64  `json:"nonce"`
+	Gas       hexutil.Uint64  `json:"gas"`
+	GasFeeCap *hexutil.Big    `json:"maxFeePerGas"`
+	GasTipCap *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value     *hexutil.Big    `json:"value"`
+	CanRevert bool            `json:"canRevert,omitempty"`
+}
+
+// PooledBundleDetails is a mev bundle of the pool with its transactions. The calldata of the
+// transactions is left out, it is only included in the exported bundles.
+type PooledBundleDetails struct {
+	PooledBundle
+	Transactions []PooledBundleTx `json:"transactions"`
+}
+
+// bundleAdminAPI manages the mev bundle pool from the authenticated builder API, for operators
+// to inspect and clean up the live pool during incidents.
+type bundleAdminAPI struct {
+	pool bundlePool
+}
+
+func newBundleAdminAPI(pool bundlePool) *bundleAdminAPI {
+	return &bundleAdminAPI{pool: pool}
+}
+
+// ListBundles returns the mev bundles of the pool. Bundles resubmitted for several blocks are
+// listed once per block.
+func (api *bundleAdminAPI) ListBundles() []PooledBundle {
+	bundles := api.pool.PooledMevBundles()
+	list := make([]PooledBundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		list = append(list, newPooledBundle(bundle))
+	}
+	return list
+}
+
+// InspectBundle returns the mev bundles of the pool with the given hash and their transactions.
+func (api *bundleAdminAPI) InspectBundle(hash common.Hash) ([]PooledBundleDetails, error) {
+	var details []PooledBundleDetails
+	for _, bundle := range api.pool.PooledMevBundles() {
+		if bundle.Hash != hash {
+			continue
+		}
+		canRevert := make(map[common.Hash]bool, len(bundle.RevertingTxHashes))
+		for _, txHash := range bundle.RevertingTxHashes {
+			canRevert[txHash] = true
+		}
+		txs := make([]PooledBundleTx, 0, len(bundle.Txs))
+		for _, tx := range bundle.Txs {
+			from, _ := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+			txs = append(txs, PooledBundleTx{
+				Hash:      tx.Hash(),
+				From:      from,
+				To:        tx.To(),
+				Nonce:     hexutil.Uint64(tx.Nonce()),
+				Gas:       hexutil.Uint64(tx.Gas()),
+				GasFeeCap: (*hexutil.Big)(tx.GasFeeCap()),
+				GasTipCap: (*hexutil.Big)(tx.GasTipCap()),
+				Value:     (*hexutil.Big)(tx.Value()),
+				CanRevert: canRevert[tx.Hash()],
+			})
+		}
+		details = append(details, PooledBundleDetails{PooledBundle: newPooledBundle(bundle), Transactions: txs})
+	}
+	if len(details) == 0 {
+		return nil, fmt.Errorf("bundle %s not found in the pool", hash)
+	}
+	return details, nil
+}
+
+// EvictBundles removes the mev bundles with the given hashes from the pool, it returns the
+// number of bundles removed.
+func (api *bundleAdminAPI) EvictBundles(hashes []common.Hash) int {
+	evicted := api.pool.EvictMevBundles(hashes)
+	log.Info("Evicted bundles from the pool", "hashes", len(hashes), "evicted", evicted)
+	return evicted
+}
+
+// ExportBundles returns the mev bundles of the pool in the eth_sendBundle format, to be replayed
+// or simulated elsewhere.
+func (api *bundleAdminAPI) ExportBundles() []ethapi.SendBundleArgs {
+	bundles := api.pool.PooledMevBundles()
+	exported := make([]ethapi.SendBundleArgs, 0, len(bundles))
+	for _, bundle := range bundles {
+		args := ethapi.SendBundleArgs{
+			Txs:               make([]hexutil.Bytes, 0, len(bundle.Txs)),
+			BlockNumber:       rpc.BlockNumber(bundle.BlockNumber.Int64()),
+			RevertingTxHashes: bundle.RevertingTxHashes,
+		}
+		if bundle.MaxBlockNumber != nil {
+			args.MaxBlockNumber = rpc.BlockNumber(bundle.MaxBlockNumber.Int64())
+		}
+		for _, tx := range bundle.Txs {
+			raw, err := tx.MarshalBinary()
+			if err != nil {
+				log.Error("could not encode bundle transaction", "bundle", bundle.Hash, "tx", redact.Tx(tx), "err", err)
+				continue
+			}
+			args.Txs = append(args.Txs, raw)
+		}
+		if bundle.Uuid != types.EmptyUUID {
+			replacementUuid, signingAddress := bundle.Uuid, bundle.SigningAddress
+			args.ReplacementUuid, args.SigningAddress = &replacementUuid, &signingAddress
+		}
+		if bundle.MinTimestamp != 0 {
+			minTimestamp := bundle.MinTimestamp
+			args.MinTimestamp = &minTimestamp
+		}
+		if bundle.MaxTimestamp != 0 {
+			maxTimestamp := bundle.MaxTimestamp
+			args.MaxTimestamp = &maxTimestamp
+		}
+		exported = append(exported, args)
+	}
+	return exported
+}
+
+func newPooledBundle(bundle types.MevBundle) PooledBundle {
+	pooled := PooledBundle{
+		Hash:           bundle.Hash,
+		BlockNumber:    (*hexutil.Big)(bundle.BlockNumber),
+		MaxBlockNumber: (*hexutil.Big)(bundle.MaxBlockNumber),
+		MinTimestamp:   bundle.MinTimestamp,
+		MaxTimestamp:   bundle.MaxTimestamp,
+		SigningAddress: bundle.SigningAddress,
+		Txs:            len(bundle.Txs),
+	}
+	if bundle.Uuid != types.EmptyUUID {
+		replacementUuid := bundle.Uuid
+		pooled.ReplacementUuid = &replacementUuid
+	}
+	return pooled
+}
@@ -0,0 +1,87 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type testBundlePool struct {
+	bundles []types.MevBundle
+}
+
+func (p *testBundlePool) PooledMevBundles() []types.MevBundle {
+	return p.bundles
+}
+
+func (p *testBundlePool) EvictMevBundles(hashes []common.Hash) int {
+	var kept []types.MevBundle
+	for _, bundle := range p.bundles {
+		evict := false
+		for _, hash := range hashes {
+			evict = evict || bundle.Hash == hash
+		}
+		if !evict {
+			kept = append(kept, bundle)
+		}
+	}
+	evicted := len(p.bundles) - len(kept)
+	p.bundles = kept
+	return evicted
+}
+
+func TestBundleAdminAPI(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	to := common.Address{0x70}
+	tx1 := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 1, To: &to, Gas: 21000, GasFeeCap: big.NewInt(10), GasTipCap: big.NewInt(2), Value: big.NewInt(5)})
+	tx2 := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 2, To: &to, Gas: 21000, GasFeeCap: big.NewInt(10), GasTipCap: big.NewInt(2)})
+
+	replacementUuid := uuid.New()
+	pool := &testBundlePool{bundles: []types.MevBundle{
+		{Txs: types.Transactions{tx1, tx2}, BlockNumber: big.NewInt(10), MaxTimestamp: 100, RevertingTxHashes: []common.Hash{tx2.Hash()}, Hash: common.Hash{0xb1}},
+		{Txs: types.Transactions{tx1}, BlockNumber: big.NewInt(11), Uuid: replacementUuid, SigningAddress: common.Address{0x51}, Hash: common.Hash{0xb2}},
+	}}
+	api := newBundleAdminAPI(pool)
+
+	list := api.ListBundles()
+	require.Len(t, list, 2)
+	require.Equal(t, PooledBundle{Hash: common.Hash{0xb1}, BlockNumber: (*hexutil.Big)(big.NewInt(10)), MaxTimestamp: 100, Txs: 2}, list[0])
+	require.Equal(t, &replacementUuid, list[1].ReplacementUuid)
+
+	details, err := api.InspectBundle(common.Hash{0xb1})
+	require.NoError(t, err)
+	require.Len(t, details, 1)
+	txs := details[0].Transactions
+	require.Len(t, txs, 2)
+	require.Equal(t, tx1.Hash(), txs[0].Hash)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), txs[0].From)
+	require.Equal(t, &to, txs[0].To)
+	require.Equal(t, big.NewInt(5), txs[0].Value.ToInt())
+	require.False(t, txs[0].CanRevert)
+	require.True(t, txs[1].CanRevert)
+	_, err = api.InspectBundle(common.Hash{0xff})
+	require.Error(t, err)
+
+	exported := api.ExportBundles()
+	require.Len(t, exported, 2)
+	raw, err := tx1.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, raw, []byte(exported[0].Txs[0]))
+	require.Equal(t, rpc.BlockNumber(10), exported[0].BlockNumber)
+	require.Equal(t, uint64(100), *exported[0].MaxTimestamp)
+	require.Nil(t, exported[0].ReplacementUuid)
+	require.Equal(t, []common.Hash{tx2.Hash()}, exported[0].RevertingTxHashes)
+	require.Equal(t, &replacementUuid, exported[1].ReplacementUuid)
+	require.Equal(t, common.Address{0x51}, *exported[1].SigningAddress)
+
+	require.Equal(t, 1, api.EvictBundles([]common.Hash{{0xb1}}))
+	require.Len(t, api.ListBundles(), 1)
+}
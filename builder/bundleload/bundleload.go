@@ -0,0 +1,467 @@
+// Package bundleload generates synthetic bundle traffic against a builder, for capacity
+// planning and soak testing.
+//
+// Three kinds of bundles are generated, each at its own rate:
+//   - arbitrage bundles, a single swap through a pool with a high priority fee
+//   - backrun bundles, a user swap followed by a searcher swap on the same pool
+//   - spam bundles, a low paying call with random data that is allowed to revert
+//
+// The bundles of every kind target the block after the current head and reuse the pending
+// nonces of the accounts at the head, so searchers compete for the same nonces like they do
+// on mainnet.
+package bundleload
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/time/rate"
+)
+
+// Kind is the kind of a generated bundle.
+type Kind int
+
+const (
+	KindArbitrage Kind = iota
+	KindBackrun
+	KindSpam
+
+	numKinds
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindArbitrage:
+		return "arbitrage"
+	case KindBackrun:
+		return "backrun"
+	case KindSpam:
+		return "spam"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+const (
+	// swapSelector is the selector of swap(uint256,uint256,address,bytes) of UniswapV2 pairs
+	swapSelector = "0x022c0d9f"
+	// swapGas is the gas limit of the swap transactions
+	swapGas = 180_000
+	// spamGas is the gas limit of the spam transactions
+	spamGas = 60_000
+)
+
+var ErrNoAccounts = errors.New("no accounts to sign the bundles with")
+
+// Config is the configuration of the load generator.
+type Config struct {
+	// Rates are the bundles per second sent of each kind, a zero rate disables the kind
+	ArbitrageRate float64
+	BackrunRate   float64
+	SpamRate      float64
+
+	// Pools are the contracts the swaps are sent to, random addresses are used if empty
+	Pools []common.Address
+
+	// MaxInFlight bounds the bundles waiting for the builder to answer
+	MaxInFlight int
+	// PollInterval is how often the head is polled for a new block
+	PollInterval time.Duration
+	// Seed of the random contents of the bundles
+	Seed int64
+}
+
+// DefaultConfig is the default load, a few bundles per second of each kind.
+var DefaultConfig = Config{
+	ArbitrageRate: 10,
+	BackrunRate:   5,
+	SpamRate:      20,
+	MaxInFlight:   256,
+	PollInterval:  time.Second,
+}
+
+func (cfg *Config) rate(kind Kind) float64 {
+	switch kind {
+	case KindArbitrage:
+		return cfg.ArbitrageRate
+	case KindBackrun:
+		return cfg.BackrunRate
+	case KindSpam:
+		return cfg.SpamRate
+	default:
+		return 0
+	}
+}
+
+// Bundle is a generated bundle.
+type Bundle struct {
+	Kind              Kind
+	Txs               types.Transactions
+	BlockNumber       uint64
+	RevertingTxHashes []common.Hash
+}
+
+// Backend is the builder the load is generated against.
+type Backend interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SendBundle(ctx context.Context, bundle *Bundle) error
+}
+
+// RPCBackend is a Backend sending the bundles to the eth_sendBundle endpoint of a builder.
+type RPCBackend struct {
+	*ethclient.Client
+	rpc *rpc.Client
+}
+
+// NewRPCBackend returns a Backend using the RPC client.
+func NewRPCBackend(client *rpc.Client) *RPCBackend {
+	return &RPCBackend{Client: ethclient.NewClient(client), rpc: client}
+}
+
+// SendBundle sends the bundle with eth_sendBundle.
+func (b *RPCBackend) SendBundle(ctx context.Context, bundle *Bundle) error {
+	args := ethapi.SendBundleArgs{
+		Txs:               make([]hexutil.Bytes, 0, len(bundle.Txs)),
+		BlockNumber:       rpc.BlockNumber(bundle.BlockNumber),
+		RevertingTxHashes: bundle.RevertingTxHashes,
+	}
+	for _, tx := range bundle.Txs {
+		encoded, err := tx.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		args.Txs = append(args.Txs, encoded)
+	}
+	return b.rpc.CallContext(ctx, nil, "eth_sendBundle", args)
+}
+
+// Accounts derives n deterministic accounts from the seed. The accounts need to be funded
+// for the bundles to be valid.
+func Accounts(seed string, n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, 0, n)
+	for i := 0; i < n; i++ {
+		key, err := crypto.ToECDSA(crypto.Keccak256([]byte(fmt.Sprintf("%s-%d", seed, i))))
+		if err != nil {
+			// a hash is a valid key with overwhelming probability
+			panic(err)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Head is the block the bundles are generated on top of.
+type Head struct {
+	Number  uint64
+	BaseFee *big.Int
+	Nonces  []uint64 // Pending nonces of the accounts, in the order of Addresses
+}
+
+// Generator builds the synthetic bundles.
+type Generator struct {
+	cfg    Config
+	signer types.Signer
+	keys   []*ecdsa.PrivateKey
+	addrs  []common.Address
+
+	mu   sync.Mutex
+	rng  *rand.Rand
+	next int // Index of the next account signing a bundle
+}
+
+// NewGenerator returns a generator signing the bundles with the keys.
+func NewGenerator(cfg Config, chainID *big.Int, keys []*ecdsa.PrivateKey) (*Generator, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoAccounts
+	}
+	g := &Generator{
+		cfg:    cfg,
+		signer: types.LatestSignerForChainID(chainID),
+		keys:   keys,
+		rng:    rand.New(rand.NewSource(cfg.Seed)),
+	}
+	for _, key := range keys {
+		g.addrs = append(g.addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	if len(g.cfg.Pools) == 0 {
+		for i := 0; i < 8; i++ {
+			var pool common.Address
+			g.rng.Read(pool[:])
+			g.cfg.Pools = append(g.cfg.Pools, pool)
+		}
+	}
+	return g, nil
+}
+
+// Addresses returns the addresses of the accounts signing the bundles.
+func (g *Generator) Addresses() []common.Address {
+	return g.addrs
+}
+
+// Bundle generates a bundle of the kind for the block after the head.
+func (g *Generator) Bundle(kind Kind, h *Head) (*Bundle, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	bundle := &Bundle{Kind: kind, BlockNumber: h.Number + 1}
+	switch kind {
+	case KindArbitrage:
+		account := g.account()
+		tx, err := g.sign(account, h.Nonces[account], h, g.pool(), g.swapData(), swapGas, g.tip(2, 20))
+		if err != nil {
+			return nil, err
+		}
+		bundle.Txs = types.Transactions{tx}
+
+	case KindBackrun:
+		pool := g.pool()
+		user, searcher := g.account(), g.account()
+		victim, err := g.sign(user, h.Nonces[user], h, pool, g.swapData(), swapGas, g.tip(1, 3))
+		if err != nil {
+			return nil, err
+		}
+		nonce := h.Nonces[searcher]
+		if searcher == user {
+			nonce++
+		}
+		backrun, err := g.sign(searcher, nonce, h, pool, g.swapData(), swapGas, g.tip(5, 50))
+		if err != nil {
+			return nil, err
+		}
+		bundle.Txs = types.Transactions{victim, backrun}
+
+	case KindSpam:
+		account := g.account()
+		var to common.Address
+		g.rng.Read(to[:])
+		data := make([]byte, 4+g.rng.Intn(128))
+		g.rng.Read(data)
+		tx, err := g.sign(account, h.Nonces[account], h, to, data, spamGas, g.tip(0, 1))
+		if err != nil {
+			return nil, err
+		}
+		bundle.Txs = types.Transactions{tx}
+		bundle.RevertingTxHashes = []common.Hash{tx.Hash()}
+
+	default:
+		return nil, fmt.Errorf("unknown bundle kind %d", kind)
+	}
+	return bundle, nil
+}
+
+// account returns the index of the next account signing a transaction.
+func (g *Generator) account() int {
+	account := g.next
+	g.next = (g.next + 1) % len(g.keys)
+	return account
+}
+
+func (g *Generator) pool() common.Address {
+	return g.cfg.Pools[g.rng.Intn(len(g.cfg.Pools))]
+}
+
+// swapData returns the call data of a swap of random amounts.
+func (g *Generator) swapData() []byte {
+	data := hexutil.MustDecode(swapSelector)
+	amountIn, amountOut := make([]byte, 32), make([]byte, 32)
+	g.rng.Read(amountIn[20:])
+	g.rng.Read(amountOut[20:])
+	to := common.LeftPadBytes(g.addrs[g.rng.Intn(len(g.addrs))].Bytes(), 32)
+	// the callback data is empty, its offset is the fourth word
+	offset := common.LeftPadBytes([]byte{0x80}, 32)
+	empty := make([]byte, 32)
+	for _, word := range [][]byte{amountIn, amountOut, to, offset, empty} {
+		data = append(data, word...)
+	}
+	return data
+}
+
+// tip returns a random priority fee between min and max gwei.
+func (g *Generator) tip(min, max int64) *big.Int {
+	return big.NewInt(min*1e9 + g.rng.Int63n((max-min)*1e9+1))
+}
+
+func (g *Generator) sign(account int, nonce uint64, h *Head, to common.Address, data []byte, gas uint64, tip *big.Int) (*types.Transaction, error) {
+	feeCap := new(big.Int).Mul(h.BaseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+	return types.SignNewTx(g.keys[account], g.signer, &types.DynamicFeeTx{
+		ChainID:   g.signer.ChainID(),
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: feeCap,
+		Gas:       gas,
+		To:        &to,
+		Data:      data,
+	})
+}
+
+// KindStats are the counters of the bundles of a kind.
+type KindStats struct {
+	Sent     uint64 // Bundles accepted by the builder
+	Rejected uint64 // Bundles rejected by the builder
+	Failed   uint64 // Bundles not delivered to the builder
+	Latency  time.Duration
+}
+
+// Stats are the counters of the generated load.
+type Stats struct {
+	Kinds  [numKinds]KindStats
+	Blocks uint64 // New heads seen
+}
+
+type kindCounters struct {
+	sent, rejected, failed uint64
+	latency                int64 // Total latency of the answered bundles, in nanoseconds
+}
+
+// Runner sends the bundles of a generator to a backend at the configured rates.
+type Runner struct {
+	backend   Backend
+	generator *Generator
+	cfg       Config
+
+	head     atomic.Value // *Head
+	counters [numKinds]kindCounters
+	blocks   uint64
+}
+
+// NewRunner returns a runner sending the bundles of the generator to the backend.
+func NewRunner(backend Backend, generator *Generator) *Runner {
+	return &Runner{backend: backend, generator: generator, cfg: generator.cfg}
+}
+
+// Stats returns the counters of the load sent so far.
+func (r *Runner) Stats() Stats {
+	stats := Stats{Blocks: atomic.LoadUint64(&r.blocks)}
+	for kind := range r.counters {
+		counters := &r.counters[kind]
+		stats.Kinds[kind] = KindStats{
+			Sent:     atomic.LoadUint64(&counters.sent),
+			Rejected: atomic.LoadUint64(&counters.rejected),
+			Failed:   atomic.LoadUint64(&counters.failed),
+		}
+		if answered := stats.Kinds[kind].Sent + stats.Kinds[kind].Rejected; answered > 0 {
+			stats.Kinds[kind].Latency = time.Duration(atomic.LoadInt64(&counters.latency) / int64(answered))
+		}
+	}
+	return stats
+}
+
+// Run sends bundles until the context is cancelled, it returns the error of the head polling
+// if the first head can not be retrieved.
+func (r *Runner) Run(ctx context.Context) error {
+	if err := r.poll(ctx); err != nil {
+		return err
+	}
+	var (
+		wg       sync.WaitGroup
+		inFlight = make(chan struct{}, r.cfg.MaxInFlight)
+	)
+	for kind := Kind(0); kind < numKinds; kind++ {
+		if r.cfg.rate(kind) <= 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(kind Kind) {
+			defer wg.Done()
+			r.generate(ctx, kind, inFlight, &wg)
+		}(kind)
+	}
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			// the bundles keep being generated on top of the last known head on errors
+			if err := r.poll(ctx); err != nil && ctx.Err() == nil {
+				log.Warn("Failed to poll the head", "err", err)
+			}
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		}
+	}
+}
+
+// generate sends the bundles of a kind at its rate.
+func (r *Runner) generate(ctx context.Context, kind Kind, inFlight chan struct{}, wg *sync.WaitGroup) {
+	limiter := rate.NewLimiter(rate.Limit(r.cfg.rate(kind)), 1)
+	counters := &r.counters[kind]
+	for {
+		if err := limiter.Wait(ctx); err != nil {
+			return
+		}
+		bundle, err := r.generator.Bundle(kind, r.head.Load().(*Head))
+		if err != nil {
+			atomic.AddUint64(&counters.failed, 1)
+			continue
+		}
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			// the builder does not keep up, the bundle is dropped
+			atomic.AddUint64(&counters.failed, 1)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-inFlight
+				wg.Done()
+			}()
+			start := time.Now()
+			err := r.backend.SendBundle(ctx, bundle)
+			var rpcErr rpc.Error
+			switch {
+			case err == nil:
+				atomic.AddUint64(&counters.sent, 1)
+			case errors.As(err, &rpcErr):
+				atomic.AddUint64(&counters.rejected, 1)
+			default:
+				atomic.AddUint64(&counters.failed, 1)
+				return
+			}
+			atomic.AddInt64(&counters.latency, int64(time.Since(start)))
+		}()
+	}
+}
+
+// poll updates the head and the pending nonces of the accounts when a new block arrives.
+func (r *Runner) poll(ctx context.Context) error {
+	header, err := r.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if current, ok := r.head.Load().(*Head); ok && current.Number == header.Number.Uint64() {
+		return nil
+	}
+	h := &Head{Number: header.Number.Uint64(), BaseFee: new(big.Int), Nonces: make([]uint64, len(r.generator.addrs))}
+	if header.BaseFee != nil {
+		h.BaseFee.Set(header.BaseFee)
+	}
+	for i, addr := range r.generator.addrs {
+		if h.Nonces[i], err = r.backend.PendingNonceAt(ctx, addr); err != nil {
+			return err
+		}
+	}
+	r.head.Store(h)
+	atomic.AddUint64(&r.blocks, 1)
+	return nil
+}
@@ -0,0 +1,124 @@
+package bundleload
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+type testBackend struct {
+	mu      sync.Mutex
+	number  uint64
+	bundles []*Bundle
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return &types.Header{Number: new(big.Int).SetUint64(b.number), BaseFee: big.NewInt(1e9)}, nil
+}
+
+func (b *testBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return 7, nil
+}
+
+func (b *testBackend) SendBundle(ctx context.Context, bundle *Bundle) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bundles = append(b.bundles, bundle)
+	if bundle.Kind == KindSpam {
+		return &rpcError{}
+	}
+	return nil
+}
+
+type rpcError struct{}
+
+func (e *rpcError) Error() string  { return "bundle rejected" }
+func (e *rpcError) ErrorCode() int { return -32000 }
+
+var _ rpc.Error = (*rpcError)(nil)
+
+func TestGeneratorBundles(t *testing.T) {
+	keys := Accounts("test", 2)
+	generator, err := NewGenerator(DefaultConfig, big.NewInt(1), keys)
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	head := &Head{Number: 10, BaseFee: big.NewInt(1e9), Nonces: []uint64{3, 5}}
+
+	arbitrage, err := generator.Bundle(KindArbitrage, head)
+	require.NoError(t, err)
+	require.Equal(t, uint64(11), arbitrage.BlockNumber)
+	require.Len(t, arbitrage.Txs, 1)
+	require.Equal(t, swapSelector, "0x"+common.Bytes2Hex(arbitrage.Txs[0].Data()[:4]))
+	require.Empty(t, arbitrage.RevertingTxHashes)
+
+	backrun, err := generator.Bundle(KindBackrun, head)
+	require.NoError(t, err)
+	require.Len(t, backrun.Txs, 2)
+	require.Equal(t, *backrun.Txs[0].To(), *backrun.Txs[1].To())
+	require.Greater(t, backrun.Txs[1].GasTipCap().Cmp(backrun.Txs[0].GasTipCap()), 0)
+
+	spam, err := generator.Bundle(KindSpam, head)
+	require.NoError(t, err)
+	require.Len(t, spam.Txs, 1)
+	require.Equal(t, []common.Hash{spam.Txs[0].Hash()}, spam.RevertingTxHashes)
+
+	// accounts are used in turn with their pending nonces
+	for i, tx := range []*types.Transaction{arbitrage.Txs[0], backrun.Txs[0], backrun.Txs[1], spam.Txs[0]} {
+		from, err := types.Sender(signer, tx)
+		require.NoError(t, err)
+		require.Equal(t, generator.Addresses()[i%2], from)
+		require.Equal(t, head.Nonces[i%2], tx.Nonce())
+		require.GreaterOrEqual(t, tx.GasFeeCap().Cmp(new(big.Int).Mul(head.BaseFee, big.NewInt(2))), 0)
+	}
+
+	// a single account backruns its own swap with the next nonce
+	generator, err = NewGenerator(DefaultConfig, big.NewInt(1), keys[:1])
+	require.NoError(t, err)
+	backrun, err = generator.Bundle(KindBackrun, head)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), backrun.Txs[0].Nonce())
+	require.Equal(t, uint64(4), backrun.Txs[1].Nonce())
+
+	_, err = NewGenerator(DefaultConfig, big.NewInt(1), nil)
+	require.ErrorIs(t, err, ErrNoAccounts)
+}
+
+func TestRunner(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.ArbitrageRate, cfg.BackrunRate, cfg.SpamRate = 100, 0, 100
+	cfg.PollInterval = 10 * time.Millisecond
+	generator, err := NewGenerator(cfg, big.NewInt(1), Accounts("test", 4))
+	require.NoError(t, err)
+
+	backend := &testBackend{number: 5}
+	runner := NewRunner(backend, generator)
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	require.NoError(t, runner.Run(ctx))
+
+	stats := runner.Stats()
+	require.NotZero(t, stats.Kinds[KindArbitrage].Sent)
+	require.Zero(t, stats.Kinds[KindArbitrage].Rejected)
+	require.NotZero(t, stats.Kinds[KindSpam].Rejected)
+	require.Zero(t, stats.Kinds[KindSpam].Sent)
+	require.Zero(t, stats.Kinds[KindBackrun].Sent+stats.Kinds[KindBackrun].Rejected)
+	require.Equal(t, uint64(1), stats.Blocks)
+	// the rate limits the number of bundles sent, 100/s for 300ms
+	require.Less(t, stats.Kinds[KindArbitrage].Sent, uint64(60))
+
+	for _, bundle := range backend.bundles {
+		require.Equal(t, uint64(6), bundle.BlockNumber)
+		for _, tx := range bundle.Txs {
+			require.Equal(t, uint64(7), tx.Nonce())
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// Package bundlerecord records the bundles received by the builder with their arrival time,
+// and replays a recording with the original timing.
+//
+// A recording starts with a magic header, followed by RLP encoded records each prefixed
+// with their length as an unsigned varint.
+package bundlerecord
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/google/uuid"
+)
+
+// Kind is the kind of a recorded bundle.
+type Kind uint8
+
+const (
+	KindBundle  Kind = iota // eth_sendBundle
+	KindSBundle             // mev_sendBundle
+)
+
+const (
+	// flushInterval is how often the recorded bundles are flushed to the file
+	flushInterval = time.Second
+	// maxRecordSize bounds a single record read back from a recording
+	maxRecordSize = 16 * 1024 * 1024
+)
+
+// magic is the header of a recording, it changes with the record format
+var magic = []byte("bndlrec1")
+
+var (
+	ErrInvalidRecording = errors.New("not a bundle recording")
+	ErrRecorderIsClosed = errors.New("bundle recorder is closed")
+)
+
+// defaultRecorder holds the process-wide *Recorder written to by RecordBundle
+var defaultRecorder atomic.Value
+
+// Record is a bundle received by the builder.
+type Record struct {
+	Time uint64 // Arrival time, in nanoseconds since the unix epoch
+	Kind Kind
+
+	// Bundle fields
+	Txs               types.Transactions
+	BlockNumber       uint64
+	ReplacementUuid   [16]byte
+	SigningAddress    common.Address
+	MinTimestamp      uint64
+	MaxTimestamp      uint64
+	RevertingTxHashes []common.Hash
+
+	// SBundle is the JSON encoding of the mev_sendBundle arguments of a sbundle
+	SBundle []byte
+}
+
+// NewBundle returns the record of a bundle arriving now.
+func NewBundle(txs types.Transactions, blockNumber uint64, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp, maxTimestamp uint64, revertingTxHashes []common.Hash) *Record {
+	return &Record{
+		Time:              uint64(time.Now().UnixNano()),
+		Kind:              KindBundle,
+		Txs:               txs,
+		BlockNumber:       blockNumber,
+		ReplacementUuid:   replacementUuid,
+		SigningAddress:    signingAddress,
+		MinTimestamp:      minTimestamp,
+		MaxTimestamp:      maxTimestamp,
+		RevertingTxHashes: revertingTxHashes,
+	}
+}
+
+// NewSBundle returns the record of a sbundle arriving now, given the JSON encoding of its
+// mev_sendBundle arguments.
+func NewSBundle(blockNumber uint64, args []byte) *Record {
+	return &Record{
+		Time:        uint64(time.Now().UnixNano()),
+		Kind:        KindSBundle,
+		BlockNumber: blockNumber,
+		SBundle:     args,
+	}
+}
+
+// Arrival returns the arrival time of the bundle.
+func (r *Record) Arrival() time.Time {
+	return time.Unix(0, int64(r.Time))
+}
+
+// Recorder appends the received bundles to a recording file.
+type Recorder struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	closed bool
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Open opens the recording at path, creating it if necessary. New records are appended to
+// an existing recording.
+func Open(path string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.Size() == 0 {
+		if _, err := file.Write(magic); err != nil {
+			file.Close()
+			return nil, err
+		}
+	} else {
+		header := make([]byte, len(magic))
+		if _, err := file.ReadAt(header, 0); err != nil || !bytes.Equal(header, magic) {
+			file.Close()
+			return nil, fmt.Errorf("existing file %s: %w", path, ErrInvalidRecording)
+		}
+	}
+	return &Recorder{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		quit:   make(chan struct{}),
+	}, nil
+}
+
+// Record appends a record to the recording.
+func (r *Recorder) Record(record *Record) error {
+	encoded, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		return err
+	}
+	var size [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(size[:], uint64(len(encoded)))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrRecorderIsClosed
+	}
+	if _, err := r.writer.Write(size[:n]); err != nil {
+		return err
+	}
+	_, err = r.writer.Write(encoded)
+	return err
+}
+
+// Flush writes the buffered records to the file.
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	return r.writer.Flush()
+}
+
+// Close flushes and closes the recording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	if err := r.writer.Flush(); err != nil {
+		r.file.Close()
+		return err
+	}
+	if err := r.file.Sync(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// Start implements node.Lifecycle, flushing the recording periodically.
+func (r *Recorder) Start() error {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.Flush(); err != nil {
+					log.Error("Failed to flush bundle recording", "err", err)
+				}
+			case <-r.quit:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements node.Lifecycle, closing the recording on node shutdown.
+func (r *Recorder) Stop() error {
+	close(r.quit)
+	r.wg.Wait()
+	return r.Close()
+}
+
+// Reader reads the records of a recording.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader returns a reader of the recording read from r.
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(reader, header); err != nil || !bytes.Equal(header, magic) {
+		return nil, ErrInvalidRecording
+	}
+	return &Reader{r: reader}, nil
+}
+
+// Next returns the next record, or io.EOF at the end of the recording.
+func (r *Reader) Next() (*Record, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("record of %d bytes exceeds the limit", size)
+	}
+	encoded := make([]byte, size)
+	if _, err := io.ReadFull(r.r, encoded); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	record := new(Record)
+	if err := rlp.DecodeBytes(encoded, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Replay feeds the records of the recording to send, with the original time between their
+// arrivals divided by speed. A speed of 0 replays the records without waiting. It returns the
+// number of records sent, replay stops at the first error of send.
+func Replay(ctx context.Context, r *Reader, speed float64, send func(*Record) error) (int, error) {
+	var (
+		start = time.Now()
+		first uint64
+		sent  int
+	)
+	for {
+		record, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, err
+		}
+		if sent == 0 {
+			first = record.Time
+		}
+		if speed > 0 && record.Time > first {
+			offset := time.Duration(float64(record.Time-first) / speed)
+			if wait := time.Until(start.Add(offset)); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return sent, ctx.Err()
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return sent, err
+		}
+		if err := send(record); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+}
+
+// SetDefault installs the process-wide recorder used by RecordBundle. Passing nil disables
+// the recording.
+func SetDefault(r *Recorder) {
+	defaultRecorder.Store(&r)
+}
+
+// Enabled returns true if a process-wide recorder is installed.
+func Enabled() bool {
+	return getDefault() != nil
+}
+
+func getDefault() *Recorder {
+	r, ok := defaultRecorder.Load().(**Recorder)
+	if !ok {
+		return nil
+	}
+	return *r
+}
+
+// RecordBundle appends a record to the process-wide recording, if one is installed.
+func RecordBundle(record *Record) {
+	r := getDefault()
+	if r == nil {
+		return
+	}
+	if err := r.Record(record); err != nil {
+		log.Error("Failed to record bundle", "kind", record.Kind, "err", err)
+	}
+}
@@ -0,0 +1,104 @@
+package bundlerecord
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundles.rec")
+	recorder, err := Open(path)
+	require.NoError(t, err)
+
+	tx := types.NewTransaction(1, common.Address{0x1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	replacement := uuid.New()
+	bundle := NewBundle(types.Transactions{tx}, 10, replacement, common.Address{0xa}, 1, 2, []common.Hash{tx.Hash()})
+	require.NoError(t, recorder.Record(bundle))
+	require.NoError(t, recorder.Close())
+	require.ErrorIs(t, recorder.Record(bundle), ErrRecorderIsClosed)
+
+	// new records are appended to the existing recording
+	recorder, err = Open(path)
+	require.NoError(t, err)
+	sbundle := NewSBundle(11, []byte(`{"version":"v0.1"}`))
+	sbundle.Time = bundle.Time + uint64(50*time.Millisecond)
+	require.NoError(t, recorder.Record(sbundle))
+	require.NoError(t, recorder.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	reader, err := NewReader(file)
+	require.NoError(t, err)
+
+	var replayed []*Record
+	start := time.Now()
+	sent, err := Replay(context.Background(), reader, 1, func(record *Record) error {
+		replayed = append(replayed, record)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, sent)
+	// the replay keeps the time between the arrivals
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	require.Equal(t, KindBundle, replayed[0].Kind)
+	require.Equal(t, bundle.Time, replayed[0].Time)
+	require.Len(t, replayed[0].Txs, 1)
+	require.Equal(t, tx.Hash(), replayed[0].Txs[0].Hash())
+	require.Equal(t, uint64(10), replayed[0].BlockNumber)
+	require.Equal(t, uuid.UUID(replayed[0].ReplacementUuid), replacement)
+	require.Equal(t, common.Address{0xa}, replayed[0].SigningAddress)
+	require.Equal(t, []common.Hash{tx.Hash()}, replayed[0].RevertingTxHashes)
+
+	require.Equal(t, KindSBundle, replayed[1].Kind)
+	require.Equal(t, uint64(11), replayed[1].BlockNumber)
+	require.Equal(t, `{"version":"v0.1"}`, string(replayed[1].SBundle))
+}
+
+func TestReplayStopsOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundles.rec")
+	recorder, err := Open(path)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, recorder.Record(NewSBundle(uint64(i), nil)))
+	}
+	require.NoError(t, recorder.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	reader, err := NewReader(file)
+	require.NoError(t, err)
+
+	failure := errors.New("failure")
+	sent, err := Replay(context.Background(), reader, 0, func(record *Record) error {
+		if record.BlockNumber == 1 {
+			return failure
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, failure)
+	require.Equal(t, 1, sent)
+}
+
+func TestInvalidRecording(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte("not a recording")))
+	require.ErrorIs(t, err, ErrInvalidRecording)
+
+	path := filepath.Join(t.TempDir(), "other")
+	require.NoError(t, os.WriteFile(path, []byte("not a recording"), 0600))
+	_, err = Open(path)
+	require.ErrorIs(t, err, ErrInvalidRecording)
+}
@@ -0,0 +1,197 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxCandidateDumpSlots is the number of slots the candidate dumps are kept on disk for
+const maxCandidateDumpSlots = 16
+
+// CandidateDump is a sealed candidate block written to disk for debugging.
+type CandidateDump struct {
+	Slot     uint64        `json:"slot"`
+	Index    int           `json:"index"` // Order of the candidate within the slot
+	SealedAt time.Time     `json:"sealedAt"`
+	Header   *types.Header `json:"header"`
+
+	// Profit breakdown, the bundles profit is included in the block value
+	Value          *hexutil.Big           `json:"value"`
+	BundlesProfit  *hexutil.Big           `json:"bundlesProfit"`
+	MempoolProfit  *hexutil.Big           `json:"mempoolProfit"`
+	Bundles        []CandidateDumpBundle  `json:"bundles"`
+	SBundles       []CandidateDumpSBundle `json:"sbundles"`
+	SimulatedCount int                    `json:"simulatedBundles"`
+
+	Txs []CandidateDumpTx `json:"txs"`
+}
+
+// CandidateDumpBundle is a bundle committed in a candidate block.
+type CandidateDumpBundle struct {
+	Hash              common.Hash  `json:"hash"`
+	Txs               int          `json:"txs"`
+	GasUsed           uint64       `json:"gasUsed"`
+	Profit            *hexutil.Big `json:"profit"`
+	EthSentToCoinbase *hexutil.Big `json:"ethSentToCoinbase"`
+	MevGasPrice       *hexutil.Big `json:"mevGasPrice"`
+}
+
+// CandidateDumpSBundle is a sbundle tried in a candidate block.
+type CandidateDumpSBundle struct {
+	Hash     common.Hash `json:"hash"`
+	Included bool        `json:"included"`
+}
+
+// CandidateDumpTx is a transaction of a candidate block.
+type CandidateDumpTx struct {
+	Hash     common.Hash     `json:"hash"`
+	To       *common.Address `json:"to"`
+	Nonce    uint64          `json:"nonce"`
+	Gas      uint64          `json:"gas"`
+	GasTip   *hexutil.Big    `json:"gasTipCap"`
+	GasPrice *hexutil.Big    `json:"gasFeeCap"`
+	Value    *hexutil.Big    `json:"value"`
+}
+
+// candidateDump writes every sealed candidate block to disk, in one directory per slot. Only
+// the directories of the recent slots are kept.
+type candidateDump struct {
+	dir string
+
+	mu      sync.Mutex
+	slots   []uint64 // Slots with a directory, oldest first
+	indexes map[uint64]int
+}
+
+func newCandidateDump(dir string) (*candidateDump, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &candidateDump{dir: dir, indexes: make(map[uint64]int)}, nil
+}
+
+func (d *candidateDump) slotDir(slot uint64) string {
+	return filepath.Join(d.dir, strconv.FormatUint(slot, 10))
+}
+
+// next returns the index of the next candidate of the slot, creating the slot directory
+// for its first candidate.
+func (d *candidateDump) next(slot uint64) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	index, ok := d.indexes[slot]
+	if !ok {
+		if err := os.MkdirAll(d.slotDir(slot), 0700); err != nil {
+			return 0, err
+		}
+		d.slots = append(d.slots, slot)
+		for len(d.slots) > maxCandidateDumpSlots {
+			os.RemoveAll(d.slotDir(d.slots[0]))
+			delete(d.indexes, d.slots[0])
+			d.slots = d.slots[1:]
+		}
+	}
+	d.indexes[slot] = index + 1
+	return index, nil
+}
+
+// candidate writes a sealed candidate block of the slot, the file is written in the background.
+func (d *candidateDump) candidate(slot uint64, block *types.Block, value *big.Int, sealedAt time.Time,
+	committedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle,
+) {
+	if d == nil {
+		return
+	}
+	index, err := d.next(slot)
+	if err != nil {
+		log.Error("could not dump candidate block", "slot", slot, "err", err)
+		return
+	}
+	go d.write(slot, index, block, new(big.Int).Set(value), sealedAt, committedBundles, allBundles, usedSbundles)
+}
+
+func (d *candidateDump) write(slot uint64, index int, block *types.Block, value *big.Int, sealedAt time.Time,
+	committedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle,
+) {
+	bundlesProfit := new(big.Int)
+	dump := CandidateDump{
+		Slot:           slot,
+		Index:          index,
+		SealedAt:       sealedAt,
+		Header:         block.Header(),
+		Value:          (*hexutil.Big)(value),
+		Bundles:        make([]CandidateDumpBundle, 0, len(committedBundles)),
+		SBundles:       make([]CandidateDumpSBundle, 0, len(usedSbundles)),
+		SimulatedCount: len(allBundles),
+		Txs:            make([]CandidateDumpTx, 0, len(block.Transactions())),
+	}
+	for _, bundle := range committedBundles {
+		bundlesProfit.Add(bundlesProfit, bundle.TotalEth)
+		dump.Bundles = append(dump.Bundles, CandidateDumpBundle{
+			Hash:              bundle.OriginalBundle.Hash,
+			Txs:               len(bundle.OriginalBundle.Txs),
+			GasUsed:           bundle.TotalGasUsed,
+			Profit:            (*hexutil.Big)(bundle.TotalEth),
+			EthSentToCoinbase: (*hexutil.Big)(bundle.EthSentToCoinbase),
+			MevGasPrice:       (*hexutil.Big)(bundle.MevGasPrice),
+		})
+	}
+	for _, sbundle := range usedSbundles {
+		dump.SBundles = append(dump.SBundles, CandidateDumpSBundle{Hash: sbundle.Bundle.Hash(), Included: sbundle.Success})
+	}
+	dump.BundlesProfit = (*hexutil.Big)(bundlesProfit)
+	dump.MempoolProfit = (*hexutil.Big)(new(big.Int).Sub(value, bundlesProfit))
+	for _, tx := range block.Transactions() {
+		dump.Txs = append(dump.Txs, CandidateDumpTx{
+			Hash:     tx.Hash(),
+			To:       tx.To(),
+			Nonce:    tx.Nonce(),
+			Gas:      tx.Gas(),
+			GasTip:   (*hexutil.Big)(tx.GasTipCap()),
+			GasPrice: (*hexutil.Big)(tx.GasFeeCap()),
+			Value:    (*hexutil.Big)(tx.Value()),
+		})
+	}
+
+	encoded, err := json.MarshalIndent(&dump, "", "  ")
+	if err != nil {
+		log.Error("could not encode candidate block", "slot", slot, "err", err)
+		return
+	}
+	path := filepath.Join(d.slotDir(slot), fmt.Sprintf("%05d-%s.json", index, block.Hash().Hex()))
+	if err := os.WriteFile(path, encoded, 0600); err != nil {
+		log.Error("could not dump candidate block", "slot", slot, "err", err)
+	}
+}
+
+// submitted records the candidate block of the slot submitted to the relay.
+func (d *candidateDump) submitted(slot uint64, blockHash common.Hash) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.indexes[slot]; !ok {
+		return
+	}
+	f, err := os.OpenFile(filepath.Join(d.slotDir(slot), "submitted"), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		log.Error("could not record submitted candidate", "slot", slot, "err", err)
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s %s\n", time.Now().UTC().Format(time.RFC3339Nano), blockHash.Hex())
+}
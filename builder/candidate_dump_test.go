@@ -0,0 +1,74 @@
+package builder
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCandidateDump(t *testing.T) {
+	dir := t.TempDir()
+	dump, err := newCandidateDump(dir)
+	require.NoError(t, err)
+
+	tx := types.NewTransaction(1, common.Address{0x1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	block := types.NewBlock(&types.Header{Number: big.NewInt(10)}, []*types.Transaction{tx}, nil, nil, trie.NewStackTrie(nil))
+	bundle := types.SimulatedBundle{
+		MevGasPrice:       big.NewInt(2),
+		TotalEth:          big.NewInt(40),
+		EthSentToCoinbase: big.NewInt(30),
+		TotalGasUsed:      21000,
+		OriginalBundle:    types.MevBundle{Txs: types.Transactions{tx}, Hash: common.Hash{0xb}},
+	}
+
+	dump.candidate(1, block, big.NewInt(100), time.Now(), []types.SimulatedBundle{bundle}, []types.SimulatedBundle{bundle, bundle}, nil)
+	dump.submitted(1, block.Hash())
+	// candidates of slots without a dump are not recorded
+	dump.submitted(2, block.Hash())
+
+	path := filepath.Join(dir, "1", "00000-"+block.Hash().Hex()+".json")
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	// the file may still be being written
+	var candidate CandidateDump
+	require.Eventually(t, func() bool {
+		content, err := os.ReadFile(path)
+		return err == nil && json.Unmarshal(content, &candidate) == nil
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, uint64(1), candidate.Slot)
+	require.Equal(t, block.Hash(), candidate.Header.Hash())
+	require.Equal(t, int64(100), candidate.Value.ToInt().Int64())
+	require.Equal(t, int64(40), candidate.BundlesProfit.ToInt().Int64())
+	require.Equal(t, int64(60), candidate.MempoolProfit.ToInt().Int64())
+	require.Equal(t, 2, candidate.SimulatedCount)
+	require.Len(t, candidate.Bundles, 1)
+	require.Equal(t, common.Hash{0xb}, candidate.Bundles[0].Hash)
+	require.Len(t, candidate.Txs, 1)
+	require.Equal(t, tx.Hash(), candidate.Txs[0].Hash)
+
+	submitted, err := os.ReadFile(filepath.Join(dir, "1", "submitted"))
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(strings.TrimSpace(string(submitted)), block.Hash().Hex()))
+	require.NoDirExists(t, filepath.Join(dir, "2"))
+
+	// only the recent slots are kept
+	for slot := uint64(2); slot <= maxCandidateDumpSlots+1; slot++ {
+		_, err := dump.next(slot)
+		require.NoError(t, err)
+	}
+	require.NoDirExists(t, filepath.Join(dir, "1"))
+	require.DirExists(t, filepath.Join(dir, strconv.Itoa(maxCandidateDumpSlots+1)))
+}
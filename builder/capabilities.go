@@ -0,0 +1,163 @@
+package builder
+
+import (
+	"sort"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// capabilitiesVersion is the version of the Capabilities format, increased on incompatible changes.
+const capabilitiesVersion = 1
+
+// Capabilities are the features supported by the builder, for searcher clients to negotiate
+// features instead of probing them with trial requests.
+type Capabilities struct {
+	Version       int                    `json:"version"`
+	ClientVersion string                 `json:"clientVersion"`
+	APIs          map[string]string      `json:"apis"` // Version of the RPC namespaces
+	BundleFormats []BundleFormat         `json:"bundleFormats"`
+	Simulation    SimulationCapabilities `json:"simulation"`
+	Relays        RelayCapabilities      `json:"relays"`
+	// Forks are the forks active at the head of the chain, the blocks are built with their rules
+	Forks []string `json:"forks"`
+}
+
+// BundleFormat is a bundle submission method and the optional fields it supports.
+type BundleFormat struct {
+	Method   string   `json:"method"`
+	Versions []string `json:"versions,omitempty"`
+	Fields   []string `json:"fields"`
+}
+
+// SimulationCapabilities are the bundle simulation methods and their options.
+type SimulationCapabilities struct {
+	Methods []string `json:"methods"`
+	// BlockOverrides are the header fields the simulated block can override
+	BlockOverrides []string `json:"blockOverrides"`
+	StateOverrides bool     `json:"stateOverrides"`
+	Tracers        []string `json:"tracers"`
+}
+
+// RelayCapabilities are the relay protocols the builder submits blocks with.
+type RelayCapabilities struct {
+	Forks         []string `json:"forks"`     // Forks of the submission requests
+	Encodings     []string `json:"encodings"` // Encodings of the configured relays
+	Cancellations bool     `json:"cancellations"`
+	Remote        int      `json:"remote"` // Number of remote relays
+	Local         bool     `json:"local"`
+}
+
+// capabilitiesAPI serves the builder capabilities on the public RPC.
+type capabilitiesAPI struct {
+	chainConfig *params.ChainConfig
+	head        func() *types.Header
+	encryption  func() bool // Whether encrypted bundles are accepted
+	relays      RelayCapabilities
+}
+
+func newCapabilitiesAPI(chainConfig *params.ChainConfig, head func() *types.Header, encryption func() bool, relays RelayCapabilities) *capabilitiesAPI {
+	return &capabilitiesAPI{
+		chainConfig: chainConfig,
+		head:        head,
+		encryption:  encryption,
+		relays:      relays,
+	}
+}
+
+// GetCapabilities returns the features supported by the builder.
+func (api *capabilitiesAPI) GetCapabilities() Capabilities {
+	formats := []BundleFormat{
+		{Method: "eth_sendBundle", Fields: []string{"txs", "blockNumber", "minTimestamp", "maxTimestamp", "revertingTxHashes", "replacementUuid", "signingAddress"}},
+		{Method: "mev_sendBundle", Versions: []string{"v0.1"}, Fields: []string{"inclusion", "body", "validity"}},
+	}
+	if api.encryption() {
+		formats = append(formats, BundleFormat{Method: "eth_sendEncryptedBundle", Fields: []string{"ciphertext", "blockNumber", "minTimestamp", "maxTimestamp", "signingAddress"}})
+	}
+	return Capabilities{
+		Version:       capabilitiesVersion,
+		ClientVersion: params.VersionWithMeta,
+		APIs:          map[string]string{"eth": "1.0", "mev": "1.0", "builder": "1.0"},
+		BundleFormats: formats,
+		Simulation: SimulationCapabilities{
+			Methods:        []string{"eth_callBundle", "eth_estimateGasBundle", "mev_simBundle"},
+			BlockOverrides: []string{"baseFee", "blockNumber", "coinbase", "difficulty", "gasLimit", "timestamp"},
+			StateOverrides: false,
+			Tracers:        []string{},
+		},
+		Relays: api.relays,
+		Forks:  activeForks(api.chainConfig, api.head()),
+	}
+}
+
+// activeForks returns the names of the forks active at the header.
+func activeForks(config *params.ChainConfig, head *types.Header) []string {
+	if head == nil {
+		return []string{}
+	}
+	// the chain is merged once the blocks have no difficulty
+	merged := head.Difficulty == nil || head.Difficulty.Sign() == 0
+	rules := config.Rules(head.Number, merged, head.Time)
+	forks := []struct {
+		name   string
+		active bool
+	}{
+		{"homestead", rules.IsHomestead},
+		{"tangerineWhistle", rules.IsEIP150},
+		{"spuriousDragon", rules.IsEIP158},
+		{"byzantium", rules.IsByzantium},
+		{"constantinople", rules.IsConstantinople},
+		{"petersburg", rules.IsPetersburg},
+		{"istanbul", rules.IsIstanbul},
+		{"berlin", rules.IsBerlin},
+		{"london", rules.IsLondon},
+		{"paris", rules.IsMerge},
+		{"shanghai", rules.IsShanghai},
+		{"cancun", config.IsCancun(head.Time)},
+	}
+	active := []string{}
+	for _, fork := range forks {
+		if fork.active {
+			active = append(active, fork.name)
+		}
+	}
+	return active
+}
+
+// newRelayCapabilities returns the relay protocols of the builder config.
+func newRelayCapabilities(cfg *Config, local bool) RelayCapabilities {
+	capabilities := RelayCapabilities{
+		Forks:         []string{"bellatrix", "capella"},
+		Encodings:     []string{},
+		Cancellations: cfg.EnableCancellations,
+		Local:         local,
+	}
+	encodings := make(map[string]struct{})
+	endpoints := append([]string{cfg.RemoteRelayEndpoint}, cfg.SecondaryRemoteRelayEndpoints...)
+	for _, endpoint := range endpoints {
+		if endpoint == "" {
+			continue
+		}
+		relayConfig, err := getRelayConfig(endpoint)
+		if err != nil {
+			continue
+		}
+		capabilities.Remote++
+		switch {
+		case relayConfig.SszEnabled && relayConfig.GzipEnabled:
+			encodings["ssz+gzip"] = struct{}{}
+		case relayConfig.SszEnabled:
+			encodings["ssz"] = struct{}{}
+		default:
+			encodings["json"] = struct{}{}
+		}
+	}
+	if local {
+		encodings["json"] = struct{}{}
+	}
+	for encoding := range encodings {
+		capabilities.Encodings = append(capabilities.Encodings, encoding)
+	}
+	sort.Strings(capabilities.Encodings)
+	return capabilities
+}
@@ -0,0 +1,51 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	shanghai := uint64(100)
+	config := *params.AllEthashProtocolChanges
+	config.TerminalTotalDifficulty = common.Big0
+	config.ShanghaiTime = &shanghai
+
+	head := &types.Header{Number: big.NewInt(10), Difficulty: new(big.Int), Time: 50}
+	encryption := false
+	cfg := &Config{
+		RemoteRelayEndpoint:           "http://relay;ssz=true",
+		SecondaryRemoteRelayEndpoints: []string{"http://a.relay;ssz=true;gzip=true", "http://b.relay"},
+		EnableCancellations:           true,
+	}
+	api := newCapabilitiesAPI(&config, func() *types.Header { return head }, func() bool { return encryption }, newRelayCapabilities(cfg, false))
+
+	capabilities := api.GetCapabilities()
+	require.Equal(t, capabilitiesVersion, capabilities.Version)
+	require.Len(t, capabilities.BundleFormats, 2)
+	require.Equal(t, []string{"homestead", "tangerineWhistle", "spuriousDragon", "byzantium", "constantinople", "petersburg", "istanbul", "berlin", "london", "paris"}, capabilities.Forks)
+	require.Equal(t, RelayCapabilities{
+		Forks:         []string{"bellatrix", "capella"},
+		Encodings:     []string{"json", "ssz", "ssz+gzip"},
+		Cancellations: true,
+		Remote:        3,
+	}, capabilities.Relays)
+
+	// features enabled at runtime are reported
+	head = &types.Header{Number: big.NewInt(11), Difficulty: new(big.Int), Time: 100}
+	encryption = true
+	capabilities = api.GetCapabilities()
+	require.Len(t, capabilities.BundleFormats, 3)
+	require.Equal(t, "eth_sendEncryptedBundle", capabilities.BundleFormats[2].Method)
+	require.Contains(t, capabilities.Forks, "shanghai")
+
+	local := newRelayCapabilities(&Config{}, true)
+	require.Equal(t, []string{"json"}, local.Encodings)
+	require.Zero(t, local.Remote)
+	require.True(t, local.Local)
+}
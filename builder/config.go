@@ -1,6 +1,11 @@
 package builder
 
-import "time"
+import (
+	"crypto/ecdsa"
+	"time"
+
+	"github.com/ethereum/go-ethereum/builder/alerting"
+)
 
 type Config struct {
 	Enabled                          bool          `toml:",omitempty"`
@@ -10,6 +15,7 @@ type Config struct {
 	SecondsInSlot                    uint64        `toml:",omitempty"`
 	DisableBundleFetcher             bool          `toml:",omitempty"`
 	DryRun                           bool          `toml:",omitempty"`
+	Shadow                           bool          `toml:",omitempty"`
 	IgnoreLatePayloadAttributes      bool          `toml:",omitempty"`
 	BuilderSecretKey                 string        `toml:",omitempty"`
 	RelaySecretKey                   string        `toml:",omitempty"`
@@ -20,14 +26,54 @@ type Config struct {
 	BeaconEndpoints                  []string      `toml:",omitempty"`
 	RemoteRelayEndpoint              string        `toml:",omitempty"`
 	SecondaryRemoteRelayEndpoints    []string      `toml:",omitempty"`
+	RelayPolicies                    []RelayPolicy `toml:",omitempty"`
 	ValidationBlocklist              string        `toml:",omitempty"`
 	ValidationUseCoinbaseDiff        bool          `toml:",omitempty"`
 	BuilderRateLimitDuration         string        `toml:",omitempty"`
 	BuilderRateLimitMaxBurst         int           `toml:",omitempty"`
 	BuilderRateLimitResubmitInterval string        `toml:",omitempty"`
 	BuilderSubmissionOffset          time.Duration `toml:",omitempty"`
+	BorPeriod                        time.Duration `toml:",omitempty"`
+	BorProducerDelay                 time.Duration `toml:",omitempty"`
+	BorSprint                        uint64        `toml:",omitempty"`
+	BorBackupDelay                   time.Duration `toml:",omitempty"`
+	Producers                        []string      `toml:",omitempty"`
+	AlwaysBuild                      bool          `toml:",omitempty"`
+	RelaySubmissionTimeout           time.Duration `toml:",omitempty"`
+	RelaySubmissionRetries           int           `toml:",omitempty"`
+	RelaySigningKey                  string        `toml:",omitempty"`
 	DiscardRevertibleTxOnErr         bool          `toml:",omitempty"`
 	EnableCancellations              bool          `toml:",omitempty"`
+	BundleEncryptionKeyFile          string        `toml:",omitempty"`
+	AuditLogPath                     string        `toml:",omitempty"`
+	BundleRecordPath                 string        `toml:",omitempty"`
+	ConfigFile                       string        `toml:",omitempty"`
+	CandidateDumpDir                 string        `toml:",omitempty"`
+	DepositGateContract              string        `toml:",omitempty"`
+	DepositGateUnit                  string        `toml:",omitempty"`
+	DepositGateBundlesPerUnit        uint64        `toml:",omitempty"`
+	DepositGateAllowlist             []string      `toml:",omitempty"`
+	SearcherAuthRequired             bool          `toml:",omitempty"`
+	SearcherRateLimit                float64       `toml:",omitempty"`
+	SearcherRateBurst                int           `toml:",omitempty"`
+	SearcherMaxBundles               int           `toml:",omitempty"`
+	SearcherAllowlist                []string      `toml:",omitempty"`
+	SearcherDenylist                 []string      `toml:",omitempty"`
+	LogBundleContent                 time.Duration `toml:",omitempty"`
+	DecisionLog                      string        `toml:",omitempty"`
+	BuildTrace                       string        `toml:",omitempty"`
+	EventsURL                        string        `toml:",omitempty"`
+	EventsTopicPrefix                string        `toml:",omitempty"`
+	ProfileDir                       string        `toml:",omitempty"`
+	AlertWebhooks                    []string      `toml:",omitempty"`
+	AlertTemplate                    string        `toml:",omitempty"`
+	AlertMinInterval                 time.Duration `toml:",omitempty"`
+	AlertMinProfit                   string        `toml:",omitempty"`
+	AlertLowProfitSlots              int           `toml:",omitempty"`
+	Faults                           string        `toml:",omitempty"`
+	Standby                          string        `toml:",omitempty"`
+	StandbyJWTSecret                 string        `toml:",omitempty"`
+	StandbyTimeout                   time.Duration `toml:",omitempty"`
 }
 
 // DefaultConfig is the default config for the builder.
@@ -39,6 +85,7 @@ var DefaultConfig = Config{
 	SecondsInSlot:                 12,
 	DisableBundleFetcher:          false,
 	DryRun:                        false,
+	Shadow:                        false,
 	IgnoreLatePayloadAttributes:   false,
 	BuilderSecretKey:              "0x2fc12ae741f29701f8e30f5de6350766c020cb80768a0ff01e6838ffd2431e11",
 	RelaySecretKey:                "0x2fc12ae741f29701f8e30f5de6350766c020cb80768a0ff01e6838ffd2431e11",
@@ -53,8 +100,16 @@ var DefaultConfig = Config{
 	ValidationUseCoinbaseDiff:     false,
 	BuilderRateLimitDuration:      RateLimitIntervalDefault.String(),
 	BuilderRateLimitMaxBurst:      RateLimitBurstDefault,
+	RelaySubmissionTimeout:        2 * time.Second,
+	RelaySubmissionRetries:        2,
 	DiscardRevertibleTxOnErr:      false,
 	EnableCancellations:           false,
+	DepositGateUnit:               "1000000000000000000",
+	DepositGateBundlesPerUnit:     60,
+	SearcherRateBurst:             10,
+	AlertMinInterval:              alerting.DefaultMinInterval,
+	AlertLowProfitSlots:           3,
+	StandbyTimeout:                4 * time.Second,
 }
 
 // RelayConfig is the config for a single remote relay.
@@ -62,4 +117,8 @@ type RelayConfig struct {
 	Endpoint    string
 	SszEnabled  bool
 	GzipEnabled bool
+
+	SubmissionTimeout time.Duration     // Timeout of every block submission attempt
+	SubmissionRetries int               // Retries of a block submission failing with an error, a timeout or a 5xx response
+	SigningKey        *ecdsa.PrivateKey // Key signing the block submissions in the signature header, nil if unsigned
 }
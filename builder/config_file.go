@@ -0,0 +1,391 @@
+package builder
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/naoina/toml"
+	"golang.org/x/time/rate"
+)
+
+// fileTomlSettings use the Go struct field names as TOML keys, like the geth config file.
+var fileTomlSettings = toml.Config{
+	NormFieldName: func(rt reflect.Type, key string) string {
+		return key
+	},
+	FieldToKey: func(rt reflect.Type, field string) string {
+		return field
+	},
+	MissingField: func(rt reflect.Type, field string) error {
+		return fmt.Errorf("field '%s' is not defined in %s", field, rt.String())
+	},
+}
+
+// FileConfig is the builder configuration file set with --builder.config. Its values take
+// precedence over the flags, values missing from the file keep the flag values.
+//
+// On SIGHUP the file is reloaded, the limits, timing, blocklists and the settings of the relays
+// are applied to the running builder. Relay endpoints and algorithm changes require a restart.
+// Values removed from the file keep their current value until a restart.
+type FileConfig struct {
+	Relays     RelaysFileConfig
+	Algorithm  AlgorithmFileConfig
+	Limits     LimitsFileConfig
+	Blocklists BlocklistsFileConfig
+	Timing     TimingFileConfig
+}
+
+// RelaysFileConfig is the [Relays] section of the builder config file.
+type RelaysFileConfig struct {
+	Remote        string   `toml:",omitempty"` // Remote relay endpoint, URL;ssz=<bool>;gzip=<bool>
+	Secondary     []string `toml:",omitempty"` // Secondary remote relay endpoints
+	Cancellations *bool    `toml:",omitempty"` // Enable cancellations with the remote relays
+
+	// Relays the blocks are fanned out to with their own settings, replacing Remote and
+	// Secondary. The first one is the primary relay.
+	Endpoints []RelayFileConfig `toml:",omitempty"`
+}
+
+// RelayFileConfig is a [[Relays.Endpoints]] entry of the builder config file.
+type RelayFileConfig struct {
+	Endpoint         string   // Relay endpoint, URL;ssz=<bool>;gzip=<bool>
+	RateLimit        string   `toml:",omitempty"` // Minimum time between block submissions to the relay
+	RateLimitBurst   int      `toml:",omitempty"` // Maximum burst of block submissions to the relay
+	Cancellations    *bool    `toml:",omitempty"` // Enable cancellations with the relay, [Relays] Cancellations if unset
+	ExcludeSearchers []string `toml:",omitempty"` // Signing addresses of the searchers whose bundles are not sent to the relay
+}
+
+// AlgorithmFileConfig is the [Algorithm] section of the builder config file.
+type AlgorithmFileConfig struct {
+	Type                     string `toml:",omitempty"` // Block building algorithm, see --builder.algotype
+	PriceCutoffPercent       *int   `toml:",omitempty"` // Gas price cutoff % of the greedy-buckets algorithms
+	DiscardRevertibleTxOnErr *bool  `toml:",omitempty"`
+}
+
+// LimitsFileConfig is the [Limits] section of the builder config file.
+type LimitsFileConfig struct {
+	RateLimitDuration         string `toml:",omitempty"` // Minimum time between block submissions
+	RateLimitMaxBurst         int    `toml:",omitempty"` // Maximum burst of block submissions
+	RateLimitResubmitInterval string `toml:",omitempty"` // Interval the block building is retried at
+}
+
+// BlocklistsFileConfig is the [Blocklists] section of the builder config file.
+type BlocklistsFileConfig struct {
+	Validation string `toml:",omitempty"` // Blocklist file the submitted blocks are validated against
+}
+
+// TimingFileConfig is the [Timing] section of the builder config file.
+type TimingFileConfig struct {
+	SubmissionOffset string `toml:",omitempty"` // Time before the end of the slot the submissions start
+}
+
+// LoadFileConfig reads the builder config file at path.
+func LoadFileConfig(path string) (*FileConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg := new(FileConfig)
+	err = fileTomlSettings.NewDecoder(bufio.NewReader(f)).Decode(cfg)
+	// Add file name to errors that have a line number.
+	if _, ok := err.(*toml.LineError); ok {
+		err = errors.New(path + ", " + err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Marshal encodes the config file in TOML.
+func (f *FileConfig) Marshal() ([]byte, error) {
+	return fileTomlSettings.Marshal(f)
+}
+
+// Apply applies the config file to the builder and miner configs. All the values are
+// validated first, the configs are left unchanged if any of them is invalid.
+func (f *FileConfig) Apply(cfg *Config, minerCfg *miner.Config) error {
+	next, nextMiner := *cfg, *minerCfg
+
+	if f.Relays.Remote != "" {
+		next.RemoteRelayEndpoint = f.Relays.Remote
+	}
+	if f.Relays.Secondary != nil {
+		next.SecondaryRemoteRelayEndpoints = f.Relays.Secondary
+	}
+	if f.Relays.Cancellations != nil {
+		next.EnableCancellations = *f.Relays.Cancellations
+	}
+	if len(f.Relays.Endpoints) > 0 {
+		if f.Relays.Remote != "" || f.Relays.Secondary != nil {
+			return errors.New("relay endpoints can't be set with the remote and secondary relays")
+		}
+		policies, err := f.Relays.policies()
+		if err != nil {
+			return err
+		}
+		next.RemoteRelayEndpoint = policies[0].Endpoint
+		next.SecondaryRemoteRelayEndpoints = nil
+		for _, policy := range policies[1:] {
+			next.SecondaryRemoteRelayEndpoints = append(next.SecondaryRemoteRelayEndpoints, policy.Endpoint)
+		}
+		next.RelayPolicies = policies
+	}
+	if f.Algorithm.Type != "" {
+		algo, err := miner.AlgoTypeFlagToEnum(f.Algorithm.Type)
+		if err != nil {
+			return fmt.Errorf("invalid algorithm type %q: %w", f.Algorithm.Type, err)
+		}
+		nextMiner.AlgoType = algo
+	}
+	if f.Algorithm.PriceCutoffPercent != nil {
+		if percent := *f.Algorithm.PriceCutoffPercent; percent < 0 || percent > 100 {
+			return fmt.Errorf("invalid price cutoff percent %d, expected 0 to 100", percent)
+		}
+		nextMiner.PriceCutoffPercent = *f.Algorithm.PriceCutoffPercent
+	}
+	if f.Algorithm.DiscardRevertibleTxOnErr != nil {
+		next.DiscardRevertibleTxOnErr = *f.Algorithm.DiscardRevertibleTxOnErr
+		nextMiner.DiscardRevertibleTxOnErr = *f.Algorithm.DiscardRevertibleTxOnErr
+	}
+	if f.Limits.RateLimitDuration != "" {
+		next.BuilderRateLimitDuration = f.Limits.RateLimitDuration
+	}
+	if f.Limits.RateLimitMaxBurst != 0 {
+		next.BuilderRateLimitMaxBurst = f.Limits.RateLimitMaxBurst
+	}
+	if f.Limits.RateLimitResubmitInterval != "" {
+		next.BuilderRateLimitResubmitInterval = f.Limits.RateLimitResubmitInterval
+	}
+	if f.Blocklists.Validation != "" {
+		next.ValidationBlocklist = f.Blocklists.Validation
+	}
+	if f.Timing.SubmissionOffset != "" {
+		offset, err := time.ParseDuration(f.Timing.SubmissionOffset)
+		if err != nil {
+			return fmt.Errorf("invalid submission offset: %w", err)
+		}
+		next.BuilderSubmissionOffset = offset
+	}
+
+	if next.RemoteRelayEndpoint != "" {
+		if _, err := getRelayConfig(next.RemoteRelayEndpoint); err != nil {
+			return fmt.Errorf("invalid remote relay endpoint: %w", err)
+		}
+	}
+	for _, endpoint := range next.SecondaryRemoteRelayEndpoints {
+		if _, err := getRelayConfig(endpoint); err != nil {
+			return fmt.Errorf("invalid secondary remote relay endpoint: %w", err)
+		}
+	}
+	if _, err := parseRuntimeSettings(&next); err != nil {
+		return err
+	}
+
+	*cfg, *minerCfg = next, nextMiner
+	return nil
+}
+
+// policies returns the policies of the relay endpoints.
+func (f *RelaysFileConfig) policies() ([]RelayPolicy, error) {
+	policies := make([]RelayPolicy, len(f.Endpoints))
+	for i, relay := range f.Endpoints {
+		if relay.Endpoint == "" {
+			return nil, fmt.Errorf("relay endpoint %d is empty", i)
+		}
+		policy := RelayPolicy{Endpoint: relay.Endpoint, Cancellations: relay.Cancellations, RateLimitBurst: relay.RateLimitBurst}
+		if relay.RateLimit != "" {
+			limit, err := time.ParseDuration(relay.RateLimit)
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("invalid rate limit %q of relay %s", relay.RateLimit, relay.Endpoint)
+			}
+			policy.RateLimit = limit
+		}
+		if relay.RateLimitBurst < 0 {
+			return nil, fmt.Errorf("rate limit burst of relay %s must be positive", relay.Endpoint)
+		}
+		for _, searcher := range relay.ExcludeSearchers {
+			if !common.IsHexAddress(searcher) {
+				return nil, fmt.Errorf("invalid excluded searcher %q of relay %s", searcher, relay.Endpoint)
+			}
+			policy.ExcludedSearchers = append(policy.ExcludedSearchers, common.HexToAddress(searcher))
+		}
+		policies[i] = policy
+	}
+	return policies, nil
+}
+
+// relayEndpoints returns the endpoints of the relays set in the file.
+func (f *RelaysFileConfig) relayEndpoints() []string {
+	if len(f.Endpoints) == 0 {
+		return append([]string{f.Remote}, f.Secondary...)
+	}
+	endpoints := make([]string, len(f.Endpoints))
+	for i, relay := range f.Endpoints {
+		endpoints[i] = relay.Endpoint
+	}
+	return endpoints
+}
+
+// runtimeSettings are the builder settings that can be changed while the builder runs.
+type runtimeSettings struct {
+	rateLimitInterval time.Duration
+	rateLimitBurst    int
+	resubmitInterval  time.Duration
+	submissionOffset  time.Duration
+	accessVerifier    *blockvalidation.AccessVerifier // Validation blocklist, only loaded in dry run
+}
+
+func parseRuntimeSettings(cfg *Config) (*runtimeSettings, error) {
+	settings := new(runtimeSettings)
+
+	// Builder rate limit parameters are flags.BuilderRateLimitDuration and flags.BuilderRateLimitMaxBurst
+	duration, err := time.ParseDuration(cfg.BuilderRateLimitDuration)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing builder rate limit duration - %w", err)
+	}
+	if cfg.BuilderRateLimitMaxBurst < 0 {
+		return nil, fmt.Errorf("builder rate limit max burst must be positive")
+	}
+	settings.rateLimitInterval = duration
+	// BuilderRateLimitMaxBurst is set to builder.RateLimitBurstDefault by default if not specified
+	settings.rateLimitBurst = cfg.BuilderRateLimitMaxBurst
+
+	if cfg.BuilderRateLimitResubmitInterval != "" {
+		d, err := time.ParseDuration(cfg.BuilderRateLimitResubmitInterval)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing builder rate limit resubmit interval - %v", err)
+		}
+		settings.resubmitInterval = d
+	} else {
+		settings.resubmitInterval = RateLimitIntervalDefault
+	}
+
+	if offset := cfg.BuilderSubmissionOffset; offset != 0 {
+		if offset < 0 {
+			return nil, fmt.Errorf("builder submission offset must be positive")
+		} else if uint64(offset.Seconds()) > cfg.SecondsInSlot {
+			return nil, fmt.Errorf("builder submission offset must be less than seconds in slot")
+		}
+		settings.submissionOffset = offset
+	} else {
+		settings.submissionOffset = SubmissionOffsetFromEndOfSlotSecondsDefault
+	}
+
+	if cfg.DryRun && cfg.ValidationBlocklist != "" {
+		settings.accessVerifier, err = blockvalidation.NewAccessVerifierFromFile(cfg.ValidationBlocklist)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load validation blocklist %w", err)
+		}
+	}
+	return settings, nil
+}
+
+// configReloader reloads the builder config file on SIGHUP.
+type configReloader struct {
+	path    string
+	cfg     Config      // Builder config the running builder was set up with
+	file    *FileConfig // Config file last applied
+	builder *Builder
+
+	sigs chan os.Signal
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newConfigReloader(path string, cfg *Config, file *FileConfig, builder *Builder) *configReloader {
+	return &configReloader{
+		path:    path,
+		cfg:     *cfg,
+		file:    file,
+		builder: builder,
+		sigs:    make(chan os.Signal, 1),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, listening for SIGHUP.
+func (r *configReloader) Start() error {
+	signal.Notify(r.sigs, syscall.SIGHUP)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			select {
+			case <-r.sigs:
+				if err := r.reload(); err != nil {
+					log.Error("Failed to reload builder config, keeping the current config", "path", r.path, "err", err)
+				}
+			case <-r.quit:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop implements node.Lifecycle.
+func (r *configReloader) Stop() error {
+	signal.Stop(r.sigs)
+	close(r.quit)
+	r.wg.Wait()
+	return nil
+}
+
+// reload reads and validates the config file, then applies the reloadable settings.
+func (r *configReloader) reload() error {
+	file, err := LoadFileConfig(r.path)
+	if err != nil {
+		return err
+	}
+	// the algorithm is only validated, the miner config is not reloaded
+	next, nextMiner := r.cfg, miner.Config{}
+	if err := file.Apply(&next, &nextMiner); err != nil {
+		return err
+	}
+	settings, err := parseRuntimeSettings(&next)
+	if err != nil {
+		return err
+	}
+
+	relaysChanged := !reflect.DeepEqual(file.Relays.relayEndpoints(), r.file.Relays.relayEndpoints())
+	if relaysChanged {
+		log.Warn("Builder relays changed in the config file, restart to apply them", "path", r.path)
+	}
+	if !reflect.DeepEqual(file.Algorithm, r.file.Algorithm) {
+		log.Warn("Builder algorithm changed in the config file, restart to apply it", "path", r.path)
+	}
+
+	r.builder.limiter.SetLimit(rate.Every(settings.rateLimitInterval))
+	r.builder.limiter.SetBurst(settings.rateLimitBurst)
+	r.builder.setTiming(settings.resubmitInterval, settings.submissionOffset)
+	if r.builder.validator != nil {
+		r.builder.validator.SetAccessVerifier(settings.accessVerifier)
+	}
+	if !relaysChanged {
+		switch relay := r.builder.getRelay().(type) {
+		case *RemoteRelay:
+			relay.SetPolicy(&next)
+		case *RemoteRelayAggregator:
+			relay.SetPolicies(&next)
+		}
+	}
+	r.cfg, r.file = next, file
+
+	log.Info("Reloaded builder config", "path", r.path, "rateLimit", settings.rateLimitInterval, "burst", settings.rateLimitBurst,
+		"resubmitInterval", settings.resubmitInterval, "submissionOffset", settings.submissionOffset, "validationBlocklist", next.ValidationBlocklist)
+	return nil
+}
@@ -0,0 +1,126 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func writeFileConfig(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestFileConfigApply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "builder.toml")
+	writeFileConfig(t, path, `
+[Relays]
+Remote = "http://relay.example;ssz=true"
+Cancellations = true
+
+[Algorithm]
+Type = "greedy-buckets"
+PriceCutoffPercent = 30
+
+[Limits]
+RateLimitDuration = "250ms"
+RateLimitMaxBurst = 4
+
+[Timing]
+SubmissionOffset = "2s"
+`)
+	file, err := LoadFileConfig(path)
+	require.NoError(t, err)
+
+	cfg, minerCfg := DefaultConfig, miner.DefaultConfig
+	cfg.RemoteRelayEndpoint = "http://flag.example"
+	require.NoError(t, file.Apply(&cfg, &minerCfg))
+
+	require.Equal(t, "http://relay.example;ssz=true", cfg.RemoteRelayEndpoint)
+	require.True(t, cfg.EnableCancellations)
+	require.Equal(t, miner.ALGO_GREEDY_BUCKETS, minerCfg.AlgoType)
+	require.Equal(t, 30, minerCfg.PriceCutoffPercent)
+	require.Equal(t, "250ms", cfg.BuilderRateLimitDuration)
+	require.Equal(t, 4, cfg.BuilderRateLimitMaxBurst)
+	require.Equal(t, 2*time.Second, cfg.BuilderSubmissionOffset)
+	// values missing from the file keep the flag values
+	require.Equal(t, DefaultConfig.ListenAddr, cfg.ListenAddr)
+	require.Equal(t, miner.DefaultConfig.GasCeil, minerCfg.GasCeil)
+}
+
+func TestFileConfigApplyInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"algorithm":   "[Algorithm]\nType = \"unknown\"",
+		"cutoff":      "[Algorithm]\nPriceCutoffPercent = 101",
+		"rate limit":  "[Limits]\nRateLimitDuration = \"fast\"",
+		"offset":      "[Timing]\nSubmissionOffset = \"13s\"",
+		"blocklist":   "[Blocklists]\nValidation = \"/nonexistent/blocklist.json\"",
+		"mixed valid": "[Limits]\nRateLimitMaxBurst = 4\n[Timing]\nSubmissionOffset = \"-1s\"",
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "builder.toml")
+			writeFileConfig(t, path, content)
+			file, err := LoadFileConfig(path)
+			require.NoError(t, err)
+
+			cfg, minerCfg := DefaultConfig, miner.DefaultConfig
+			cfg.DryRun = true
+			require.Error(t, file.Apply(&cfg, &minerCfg))
+
+			// no value is applied when the file is invalid
+			expected := DefaultConfig
+			expected.DryRun = true
+			require.Equal(t, expected, cfg)
+			require.Equal(t, miner.DefaultConfig, minerCfg)
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "builder.toml")
+	writeFileConfig(t, path, "[Limits]\nUnknown = 1")
+	_, err := LoadFileConfig(path)
+	require.Error(t, err)
+}
+
+func TestConfigReloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "builder.toml")
+	writeFileConfig(t, path, "[Limits]\nRateLimitDuration = \"500ms\"")
+	file, err := LoadFileConfig(path)
+	require.NoError(t, err)
+
+	cfg := DefaultConfig
+	require.NoError(t, file.Apply(&cfg, &miner.Config{}))
+	builder := &Builder{
+		limiter:                       rate.NewLimiter(rate.Every(500*time.Millisecond), 10),
+		builderResubmitInterval:       BlockResubmitIntervalDefault,
+		submissionOffsetFromEndOfSlot: SubmissionOffsetFromEndOfSlotSecondsDefault,
+	}
+	reloader := newConfigReloader(path, &cfg, file, builder)
+
+	writeFileConfig(t, path, `
+[Limits]
+RateLimitDuration = "100ms"
+RateLimitMaxBurst = 2
+RateLimitResubmitInterval = "1s"
+
+[Timing]
+SubmissionOffset = "4s"
+`)
+	require.NoError(t, reloader.reload())
+	require.Equal(t, rate.Every(100*time.Millisecond), builder.limiter.Limit())
+	require.Equal(t, 2, builder.limiter.Burst())
+	resubmitInterval, submissionOffset := builder.timing()
+	require.Equal(t, time.Second, resubmitInterval)
+	require.Equal(t, 4*time.Second, submissionOffset)
+
+	// an invalid file is not applied
+	writeFileConfig(t, path, "[Limits]\nRateLimitMaxBurst = 5\n[Timing]\nSubmissionOffset = \"1h\"")
+	require.Error(t, reloader.reload())
+	require.Equal(t, 2, builder.limiter.Burst())
+	_, submissionOffset = builder.timing()
+	require.Equal(t, 4*time.Second, submissionOffset)
+}
@@ -0,0 +1,97 @@
+package builder
+
+import (
+	"errors"
+	"sync"
+)
+
+var errBuilderDraining = errors.New("builder is draining, not building new slots")
+
+// BuilderStatus is the runtime state of the builder, as set by the operator controls.
+type BuilderStatus struct {
+	Slot              uint64 `json:"slot"` // Slot of the last payload attributes accepted
+	IngestionPaused   bool   `json:"ingestionPaused"`
+	SubmissionsPaused bool   `json:"submissionsPaused"`
+	// Draining is true once a drain was requested, the builder finishes the slot it was building
+	// and rejects the payload attributes of the later slots
+	Draining bool `json:"draining"`
+	// Drained is true when the builder is draining and the building jobs have all ended
+	Drained    bool   `json:"drained"`
+	DrainSlot  uint64 `json:"drainSlot,omitempty"`
+	ActiveJobs int    `json:"activeJobs"`
+	DryRun     bool   `json:"dryRun"`
+	Shadow     bool   `json:"shadow"`
+	// Standby is the failover state when the builder runs as the standby of another builder
+	Standby *StandbyStatus `json:"standby,omitempty"`
+}
+
+// builderControl is the state of the operator controls of the builder.
+type builderControl struct {
+	mu                sync.Mutex
+	submissionsPaused bool
+	draining          bool
+	drainSlot         uint64
+	jobs              int // Building jobs running
+}
+
+func (c *builderControl) setSubmissionsPaused(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.submissionsPaused = paused
+}
+
+func (c *builderControl) submissionsAllowed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.submissionsPaused
+}
+
+// drain stops accepting the slots after the given one.
+func (c *builderControl) drain(slot uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.draining {
+		c.draining, c.drainSlot = true, slot
+	}
+}
+
+// resume lifts the drain and the submission pause.
+func (c *builderControl) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.submissionsPaused, c.draining, c.drainSlot = false, false, 0
+}
+
+// accept checks whether building the slot is allowed, only the slot being built when the drain
+// was requested is while draining.
+func (c *builderControl) accept(slot uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.draining && slot != c.drainSlot {
+		return errBuilderDraining
+	}
+	return nil
+}
+
+func (c *builderControl) jobStarted() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.jobs++
+}
+
+func (c *builderControl) jobDone() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.jobs--
+}
+
+// status fills the state of the controls in the builder status.
+func (c *builderControl) status(status *BuilderStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status.SubmissionsPaused = c.submissionsPaused
+	status.Draining = c.draining
+	status.Drained = c.draining && c.jobs == 0
+	status.DrainSlot = c.drainSlot
+	status.ActiveJobs = c.jobs
+}
@@ -0,0 +1,50 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+type testBundleIngestion struct {
+	paused bool
+}
+
+func (i *testBundleIngestion) SetBundleIngestionPaused(paused bool) { i.paused = paused }
+func (i *testBundleIngestion) BundleIngestionPaused() bool          { return i.paused }
+
+func TestBuilderControl(t *testing.T) {
+	builder := &Builder{slotAttrs: types.BuilderPayloadAttributes{Slot: 10}}
+	ingestion := new(testBundleIngestion)
+	service := &Service{builder: builder, ingestion: ingestion}
+
+	require.Equal(t, BuilderStatus{Slot: 10}, service.Status())
+
+	status := service.PauseIngestion()
+	require.True(t, status.IngestionPaused)
+	require.True(t, ingestion.paused)
+	require.False(t, service.ResumeIngestion().IngestionPaused)
+
+	status = service.PauseSubmissions()
+	require.True(t, status.SubmissionsPaused)
+	require.False(t, builder.control.submissionsAllowed())
+	require.False(t, service.ResumeSubmissions().SubmissionsPaused)
+
+	// the slot being built is finished, the later slots are rejected
+	builder.control.jobStarted()
+	status = service.Drain()
+	require.True(t, status.IngestionPaused)
+	require.True(t, status.Draining)
+	require.False(t, status.Drained)
+	require.Equal(t, uint64(10), status.DrainSlot)
+	require.NoError(t, builder.control.accept(10))
+	require.ErrorIs(t, builder.control.accept(11), errBuilderDraining)
+
+	builder.control.jobDone()
+	require.True(t, service.Status().Drained)
+
+	status = service.Resume()
+	require.Equal(t, BuilderStatus{Slot: 10}, status)
+	require.NoError(t, builder.control.accept(11))
+}
@@ -0,0 +1,151 @@
+// Package decisionlog writes a machine-readable record of the inclusion decisions taken for
+// every built block, for offline analysis of the block building algorithms.
+//
+// Records are written as one JSON object per line to a file, or to the node log.
+package decisionlog
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// LogTarget is the target that writes records to the node log instead of a file.
+const LogTarget = "log"
+
+// Reasons recorded for candidates that were not rejected with an error.
+const (
+	ReasonIncluded   = "included"
+	ReasonNotReached = "not reached"
+)
+
+var ErrLogIsClosed = errors.New("decision log is closed")
+
+// defaultDecisionLog holds the process-wide *Log written to by Write
+var defaultDecisionLog atomic.Value
+
+// Candidate is a bundle or sbundle considered for a block, in the order of the candidate ranking.
+type Candidate struct {
+	Rank            int          `json:"rank"`
+	Hash            common.Hash  `json:"hash"`
+	SBundle         bool         `json:"sbundle,omitempty"`
+	MevGasPrice     *hexutil.Big `json:"mevGasPrice,omitempty"`
+	SimulatedProfit *hexutil.Big `json:"simulatedProfit,omitempty"`
+	Included        bool         `json:"included"`
+	Reason          string       `json:"reason"`
+	// BlockProfit is the profit of the block after the candidate was committed
+	BlockProfit *hexutil.Big `json:"blockProfit,omitempty"`
+}
+
+// BlockDecision is recorded for every block built by the block building algorithms.
+type BlockDecision struct {
+	Time        time.Time    `json:"time"`
+	Algorithm   string       `json:"algorithm"`
+	BlockNumber uint64       `json:"blockNumber"`
+	BlockHash   common.Hash  `json:"blockHash"`
+	ParentHash  common.Hash  `json:"parentHash"`
+	Candidates  []Candidate  `json:"candidates"`
+	Bid         *hexutil.Big `json:"bid"`
+}
+
+// Log writes decision records to a file, or to the node log if it has no file.
+type Log struct {
+	mu     sync.Mutex
+	file   *os.File
+	closed bool
+}
+
+// Open opens the decision log at target. Records are appended to the file at target,
+// or written to the node log if target is LogTarget.
+func Open(target string) (*Log, error) {
+	if target == LogTarget {
+		return &Log{}, nil
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{file: file}, nil
+}
+
+// Write appends the JSON encoding of the record to the log.
+func (l *Log) Write(record *BlockDecision) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrLogIsClosed
+	}
+	if l.file == nil {
+		log.Info("Block inclusion decision", "number", record.BlockNumber, "hash", record.BlockHash, "record", string(encoded))
+		return nil
+	}
+	_, err = l.file.Write(append(encoded, '\n'))
+	return err
+}
+
+// Close closes the underlying file.
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// Start implements node.Lifecycle.
+func (l *Log) Start() error {
+	return nil
+}
+
+// Stop implements node.Lifecycle, closing the log on node shutdown.
+func (l *Log) Stop() error {
+	return l.Close()
+}
+
+// SetDefault installs the process-wide decision log used by Write. Passing nil disables it.
+func SetDefault(l *Log) {
+	defaultDecisionLog.Store(&l)
+}
+
+// Enabled returns true if a process-wide decision log is installed.
+func Enabled() bool {
+	return getDefault() != nil
+}
+
+func getDefault() *Log {
+	l, ok := defaultDecisionLog.Load().(**Log)
+	if !ok {
+		return nil
+	}
+	return *l
+}
+
+// Write adds a record to the process-wide decision log, if one is installed.
+func Write(record *BlockDecision) {
+	l := getDefault()
+	if l == nil {
+		return
+	}
+	if err := l.Write(record); err != nil {
+		log.Error("Failed to write decision log record", "block", record.BlockNumber, "err", err)
+	}
+}
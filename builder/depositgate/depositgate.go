@@ -0,0 +1,188 @@
+// Package depositgate implements a deposit-gated access tier for bundle submission.
+//
+// Searchers that are not allowlisted earn a submission quota proportional to the deposit
+// they hold in a configured on-chain contract, so unknown searchers can submit bundles
+// without manual allowlisting while anonymous spam stays bounded.
+package depositgate
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// quotaWindow is the period over which the submission quota is granted
+	quotaWindow = time.Minute
+	// depositCallGas bounds the gas of the deposit lookup call
+	depositCallGas = 100_000
+)
+
+var (
+	ErrNoSearcher     = errors.New("bundle searcher could not be identified")
+	ErrNoDeposit      = errors.New("searcher has no sufficient deposit")
+	ErrQuotaExceeded  = errors.New("searcher bundle quota exceeded")
+	ErrInvalidDeposit = errors.New("invalid deposit contract response")
+
+	// depositsSelector is the selector of deposits(address) returns (uint256)
+	depositsSelector = crypto.Keccak256([]byte("deposits(address)"))[:4]
+)
+
+type Config struct {
+	Contract       common.Address   // Contract exposing deposits(address) returns (uint256)
+	DepositUnit    *big.Int         // Deposit in wei granting one unit of quota
+	BundlesPerUnit uint64           // Bundle submissions per minute granted by each deposit unit
+	Allowlist      []common.Address // Searchers exempt from the deposit requirement
+}
+
+// DepositReader returns the current deposit of a searcher.
+type DepositReader func(searcher common.Address) (*big.Int, error)
+
+type searcherQuota struct {
+	quota       uint64    // Submissions allowed in the window, derived from the deposit
+	windowStart time.Time // Start of the current window, the deposit is refreshed with every window
+	used        uint64    // Submissions in the current window
+}
+
+// Gate enforces the per searcher submission quota. Searchers are identified by the address
+// that signed their request, the deposits are read without holding the lock of the quotas.
+type Gate struct {
+	config    Config
+	reader    DepositReader
+	allowlist map[common.Address]struct{}
+	reads     singleflight.Group // Deduplicates the concurrent deposit reads of a searcher
+
+	mu        sync.Mutex
+	searchers map[common.Address]*searcherQuota
+	now       func() time.Time
+}
+
+func New(config Config, reader DepositReader) (*Gate, error) {
+	if config.DepositUnit == nil || config.DepositUnit.Sign() <= 0 {
+		return nil, errors.New("deposit unit must be positive")
+	}
+	if config.BundlesPerUnit == 0 {
+		return nil, errors.New("bundles per deposit unit must be positive")
+	}
+
+	allowlist := make(map[common.Address]struct{}, len(config.Allowlist))
+	for _, searcher := range config.Allowlist {
+		allowlist[searcher] = struct{}{}
+	}
+	return &Gate{
+		config:    config,
+		reader:    reader,
+		allowlist: allowlist,
+		searchers: make(map[common.Address]*searcherQuota),
+		now:       time.Now,
+	}, nil
+}
+
+// Allow consumes one submission of the searcher quota, returning an error if none is left.
+func (g *Gate) Allow(searcher common.Address) error {
+	if searcher == (common.Address{}) {
+		return ErrNoSearcher
+	}
+	if _, ok := g.allowlist[searcher]; ok {
+		return nil
+	}
+
+	g.mu.Lock()
+	now := g.now()
+	sq := g.current(searcher, now)
+	if sq == nil {
+		g.mu.Unlock()
+		quota, err := g.readQuota(searcher)
+		if err != nil {
+			return err
+		}
+		g.mu.Lock()
+		// the quota may have been refreshed by a concurrent submission while reading
+		if sq = g.current(searcher, now); sq == nil {
+			sq = &searcherQuota{quota: quota, windowStart: now}
+			g.searchers[searcher] = sq
+			g.prune(now)
+		}
+	}
+	defer g.mu.Unlock()
+
+	if sq.quota == 0 {
+		return ErrNoDeposit
+	}
+	if sq.used >= sq.quota {
+		return ErrQuotaExceeded
+	}
+	sq.used++
+	return nil
+}
+
+// current returns the quota of the searcher if its window has not expired.
+func (g *Gate) current(searcher common.Address, now time.Time) *searcherQuota {
+	if sq, ok := g.searchers[searcher]; ok && now.Sub(sq.windowStart) < quotaWindow {
+		return sq
+	}
+	return nil
+}
+
+// readQuota reads the deposit of the searcher and returns the quota it grants, the concurrent
+// reads of a searcher share a single deposit lookup.
+func (g *Gate) readQuota(searcher common.Address) (uint64, error) {
+	quota, err, _ := g.reads.Do(searcher.Hex(), func() (interface{}, error) {
+		deposit, err := g.reader(searcher)
+		if err != nil {
+			return nil, err
+		}
+		units := new(big.Int).Div(deposit, g.config.DepositUnit)
+		quota := g.config.BundlesPerUnit
+		if units.IsUint64() && units.Uint64() <= ^uint64(0)/quota {
+			quota *= units.Uint64()
+		} else {
+			quota = ^uint64(0)
+		}
+		return quota, nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read searcher deposit: %w", err)
+	}
+	return quota.(uint64), nil
+}
+
+// prune drops the searchers whose window has expired
+func (g *Gate) prune(now time.Time) {
+	for searcher, sq := range g.searchers {
+		if now.Sub(sq.windowStart) >= quotaWindow {
+			delete(g.searchers, searcher)
+		}
+	}
+}
+
+// ContractDepositReader reads deposits by calling deposits(address) on the contract at the chain head.
+func ContractDepositReader(chain *core.BlockChain, contract common.Address) DepositReader {
+	return func(searcher common.Address) (*big.Int, error) {
+		header := chain.CurrentBlock()
+		statedb, err := chain.StateAt(header.Root)
+		if err != nil {
+			return nil, err
+		}
+		blockCtx := core.NewEVMBlockContext(header, chain, nil)
+		evm := vm.NewEVM(blockCtx, vm.TxContext{}, statedb, chain.Config(), vm.Config{NoBaseFee: true})
+
+		input := append(append([]byte{}, depositsSelector...), common.LeftPadBytes(searcher.Bytes(), 32)...)
+		ret, _, err := evm.StaticCall(vm.AccountRef(common.Address{}), contract, input, depositCallGas)
+		if err != nil {
+			return nil, err
+		}
+		if len(ret) < 32 {
+			return nil, ErrInvalidDeposit
+		}
+		return new(big.Int).SetBytes(ret[:32]), nil
+	}
+}
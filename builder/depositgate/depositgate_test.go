@@ -0,0 +1,105 @@
+package depositgate
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGate(t *testing.T) {
+	var (
+		allowlisted = common.HexToAddress("0x01")
+		depositor   = common.HexToAddress("0x02")
+		unknown     = common.HexToAddress("0x03")
+	)
+	deposits := map[common.Address]*big.Int{
+		depositor: new(big.Int).Mul(big.NewInt(2), big.NewInt(params.Ether)),
+	}
+	reads := 0
+	reader := func(searcher common.Address) (*big.Int, error) {
+		reads++
+		if deposit, ok := deposits[searcher]; ok {
+			return deposit, nil
+		}
+		return new(big.Int), nil
+	}
+
+	gate, err := New(Config{
+		DepositUnit:    big.NewInt(params.Ether),
+		BundlesPerUnit: 2,
+		Allowlist:      []common.Address{allowlisted},
+	}, reader)
+	require.NoError(t, err)
+	now := time.Unix(1000, 0)
+	gate.now = func() time.Time { return now }
+
+	require.ErrorIs(t, gate.Allow(common.Address{}), ErrNoSearcher)
+	require.ErrorIs(t, gate.Allow(unknown), ErrNoDeposit)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, gate.Allow(allowlisted))
+	}
+
+	// 2 units of deposit grant 4 bundles per window
+	for i := 0; i < 4; i++ {
+		require.NoError(t, gate.Allow(depositor))
+	}
+	require.ErrorIs(t, gate.Allow(depositor), ErrQuotaExceeded)
+	require.Equal(t, 2, reads)
+
+	// quota and deposit are refreshed with the next window
+	deposits[depositor] = big.NewInt(params.Ether)
+	now = now.Add(quotaWindow)
+	require.NoError(t, gate.Allow(depositor))
+	require.NoError(t, gate.Allow(depositor))
+	require.ErrorIs(t, gate.Allow(depositor), ErrQuotaExceeded)
+	require.Equal(t, 3, reads)
+
+	_, err = New(Config{DepositUnit: new(big.Int), BundlesPerUnit: 1}, reader)
+	require.Error(t, err)
+}
+
+func TestGateConcurrentReads(t *testing.T) {
+	var (
+		slow    = common.HexToAddress("0x01")
+		fast    = common.HexToAddress("0x02")
+		reads   atomic.Int32
+		release = make(chan struct{})
+	)
+	reader := func(searcher common.Address) (*big.Int, error) {
+		reads.Add(1)
+		if searcher == slow {
+			<-release
+		}
+		return big.NewInt(params.Ether), nil
+	}
+	gate, err := New(Config{DepositUnit: big.NewInt(params.Ether), BundlesPerUnit: 10}, reader)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, gate.Allow(slow))
+		}()
+	}
+
+	// a slow deposit read does not block the other searchers
+	require.Eventually(t, func() bool { return reads.Load() >= 1 }, time.Second, time.Millisecond)
+	require.NoError(t, gate.Allow(fast))
+
+	// the concurrent submissions of a searcher share a single quota
+	close(release)
+	wg.Wait()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, gate.Allow(slow))
+	}
+	require.ErrorIs(t, gate.Allow(slow), ErrQuotaExceeded)
+}
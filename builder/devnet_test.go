@@ -0,0 +1,306 @@
+//go:build devnet
+
+// Devnet tests of the builder pipeline, run with
+//
+//	go test -tags devnet ./builder -run Devnet
+//
+// The tests run an in-process devnet node, and drive the builder against a mock relay: bundles
+// are submitted, blocks are built and submitted to the relay, and the submitted blocks are
+// imported through the engine API to check their inclusion and the proposer payment. The devnet
+// runs ethash past the merge, not Bor: the Bor sealing path and the Heimdall spans and
+// validators are not covered.
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	bellatrixapi "github.com/attestantio/go-builder-client/api/bellatrix"
+	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/catalyst"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/flashbotsextra"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/flashbots/go-boost-utils/bls"
+	"github.com/flashbots/go-boost-utils/ssz"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	devnetBankKey, _    = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	devnetBankAddress   = crypto.PubkeyToAddress(devnetBankKey.PublicKey)
+	devnetBuilderKey, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+	devnetBuilderAddr   = crypto.PubkeyToAddress(devnetBuilderKey.PublicKey)
+	devnetFeeRecipient  = common.HexToAddress("0xabcf8e0d4e9587369b2301d0790347320302cc00")
+	devnetProposer      = "0xb67d2c11bcab8c4394fc2faa9601d0b99c7f4b37e14911101da7d97077917862eed4563203d34b91b5cf0aa44d6cfa05"
+	devnetFunds         = new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	devnetGasPrice      = big.NewInt(10 * params.GWei)
+)
+
+// devnetRelay is a mock relay serving the proposer of every slot, and recording the blocks
+// submitted by the builder.
+type devnetRelay struct {
+	server *httptest.Server
+
+	mu          sync.Mutex
+	submissions []*bellatrixapi.SubmitBlockRequest
+	submitted   chan struct{}
+}
+
+func newDevnetRelay(t *testing.T, slots uint64) *devnetRelay {
+	relay := &devnetRelay{submitted: make(chan struct{}, 1)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/relay/v1/builder/validators", func(w http.ResponseWriter, req *http.Request) {
+		entries := make([]json.RawMessage, 0, slots)
+		for slot := uint64(1); slot <= slots; slot++ {
+			entries = append(entries, json.RawMessage(fmt.Sprintf(
+				`{"slot":"%d","entry":{"message":{"fee_recipient":"%s","gas_limit":"30000000","timestamp":"0","pubkey":"%s"},"signature":"0x"}}`,
+				slot, devnetFeeRecipient.Hex(), devnetProposer)))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entries)
+	})
+	mux.HandleFunc("/relay/v1/builder/blocks", func(w http.ResponseWriter, req *http.Request) {
+		msg := new(bellatrixapi.SubmitBlockRequest)
+		if err := json.NewDecoder(req.Body).Decode(msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		relay.mu.Lock()
+		relay.submissions = append(relay.submissions, msg)
+		relay.mu.Unlock()
+		select {
+		case relay.submitted <- struct{}{}:
+		default:
+		}
+	})
+	relay.server = httptest.NewServer(mux)
+	t.Cleanup(relay.server.Close)
+	return relay
+}
+
+// waitSubmission waits for a block submitted for the slot matching the predicate.
+func (r *devnetRelay) waitSubmission(t *testing.T, slot uint64, match func(*types.Block) bool) (*bellatrixapi.SubmitBlockRequest, *types.Block) {
+	t.Helper()
+
+	timeout := time.After(15 * time.Second)
+	for {
+		r.mu.Lock()
+		for i := len(r.submissions) - 1; i >= 0; i-- {
+			msg := r.submissions[i]
+			if msg.Message.Slot != slot {
+				continue
+			}
+			block, err := engine.ExecutionPayloadToBlock(msg.ExecutionPayload)
+			require.NoError(t, err)
+			if match(block) {
+				r.mu.Unlock()
+				return msg, block
+			}
+		}
+		r.mu.Unlock()
+
+		select {
+		case <-r.submitted:
+		case <-time.After(100 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("no matching block submitted for slot %d", slot)
+		}
+	}
+}
+
+// testDevnet is a single node devnet past the merge with a builder submitting to a mock relay.
+type testDevnet struct {
+	node      *node.Node
+	eth       *eth.Ethereum
+	consensus *catalyst.ConsensusAPI
+	relay     *devnetRelay
+	builder   *Builder
+	signer    types.Signer
+}
+
+func newTestDevnet(t *testing.T) *testDevnet {
+	t.Helper()
+
+	// the chain reaches the terminal total difficulty on its last pre-merge block
+	config := *params.AllEthashProtocolChanges
+	genesis := &core.Genesis{
+		Config:     &config,
+		Alloc:      core.GenesisAlloc{devnetBankAddress: {Balance: devnetFunds}, devnetBuilderAddr: {Balance: devnetFunds}},
+		ExtraData:  []byte("devnet genesis"),
+		Timestamp:  9000,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		Difficulty: big.NewInt(0),
+	}
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, ethash.NewFaker(), 5, nil)
+	totalDifficulty := new(big.Int)
+	for _, block := range blocks {
+		totalDifficulty.Add(totalDifficulty, block.Difficulty())
+	}
+	config.TerminalTotalDifficulty = totalDifficulty
+
+	stack, err := node.New(&node.Config{P2P: p2p.Config{ListenAddr: "0.0.0.0:0", NoDiscovery: true, MaxPeers: 25}})
+	require.NoError(t, err)
+	t.Cleanup(func() { stack.Close() })
+
+	ethcfg := ethconfig.Defaults
+	ethcfg.Genesis = genesis
+	ethcfg.Ethash = ethash.Config{PowMode: ethash.ModeFake}
+	ethcfg.SyncMode = downloader.FullSync
+	ethcfg.Miner.Etherbase = devnetBuilderAddr
+	ethcfg.Miner.BuilderTxSigningKey = devnetBuilderKey
+	backend, err := eth.New(stack, &ethcfg)
+	require.NoError(t, err)
+	require.NoError(t, stack.Start())
+	_, err = backend.BlockChain().InsertChain(blocks)
+	require.NoError(t, err)
+	time.Sleep(500 * time.Millisecond) // give txpool enough time to consume head event
+	backend.SetSynced()
+
+	relay := newDevnetRelay(t, 64)
+	sk, err := bls.SecretKeyFromBytes(hexutil.MustDecode("0x31ee185dad1220a8c88ca5275e64cf5a5cb09cb621cb30df52c9bee8fbaaf8d7"))
+	require.NoError(t, err)
+	builder, err := NewBuilder(BuilderArgs{
+		sk:                   sk,
+		ds:                   flashbotsextra.NilDbService{},
+		relay:                NewRemoteRelay(RelayConfig{Endpoint: relay.server.URL}, nil, false),
+		builderSigningDomain: ssz.ComputeDomain(ssz.DomainTypeAppBuilder, [4]byte{0x02, 0x0, 0x0, 0x0}, phase0.Root{}),
+		eth:                  NewEthereumService(backend),
+		beaconClient:         &NilBeaconClient{},
+	})
+	require.NoError(t, err)
+	require.NoError(t, builder.Start())
+	t.Cleanup(func() { builder.Stop() })
+
+	return &testDevnet{
+		node:      stack,
+		eth:       backend,
+		consensus: catalyst.NewConsensusAPI(backend),
+		relay:     relay,
+		builder:   builder,
+		signer:    types.LatestSigner(&config),
+	}
+}
+
+// transfer returns a signed transfer from the bank account.
+func (d *testDevnet) transfer(nonce uint64, to common.Address, value *big.Int) *types.Transaction {
+	return types.MustSignNewTx(devnetBankKey, d.signer, &types.LegacyTx{
+		Nonce:    nonce,
+		To:       &to,
+		Value:    value,
+		Gas:      params.TxGas,
+		GasPrice: devnetGasPrice,
+	})
+}
+
+// buildSlot sends the payload attributes of the slot on top of the head to the builder.
+func (d *testDevnet) buildSlot(t *testing.T, slot uint64) {
+	t.Helper()
+
+	head := d.eth.BlockChain().CurrentBlock()
+	require.NoError(t, d.builder.OnPayloadAttribute(&types.BuilderPayloadAttributes{
+		Timestamp: hexutil.Uint64(head.Time + 12),
+		Random:    common.Hash{byte(slot)},
+		HeadHash:  head.Hash(),
+		Slot:      slot,
+	}))
+}
+
+// land imports a submitted block through the engine API and makes it the head.
+func (d *testDevnet) land(t *testing.T, block *types.Block) {
+	t.Helper()
+
+	payload := engine.BlockToExecutableData(block, nil).ExecutionPayload
+	status, err := d.consensus.NewPayloadV1(*payload)
+	require.NoError(t, err)
+	require.Equal(t, engine.VALID, status.Status)
+
+	_, err = d.consensus.ForkchoiceUpdatedV1(engine.ForkchoiceStateV1{HeadBlockHash: block.Hash(), SafeBlockHash: block.Hash(), FinalizedBlockHash: block.Hash()}, nil)
+	require.NoError(t, err)
+	require.Equal(t, block.Hash(), d.eth.BlockChain().CurrentBlock().Hash())
+}
+
+func TestDevnetBundleInclusion(t *testing.T) {
+	devnet := newTestDevnet(t)
+
+	recipient := common.Address{0x42}
+	tx := devnet.transfer(0, recipient, big.NewInt(params.Ether))
+	next := devnet.eth.BlockChain().CurrentBlock().Number.Uint64() + 1
+	require.NoError(t, devnet.eth.APIBackend.SendBundle(context.Background(), types.Transactions{tx}, rpc.BlockNumber(next), 0, uuid.UUID{}, common.Address{}, 0, 0, nil))
+
+	devnet.buildSlot(t, 1)
+	msg, block := devnet.relay.waitSubmission(t, 1, func(block *types.Block) bool {
+		return block.Transaction(tx.Hash()) != nil
+	})
+	require.Equal(t, devnetFeeRecipient, common.Address(msg.Message.ProposerFeeRecipient))
+	require.Equal(t, block.Hash(), common.Hash(msg.Message.BlockHash))
+
+	state, err := devnet.eth.BlockChain().State()
+	require.NoError(t, err)
+	balanceBefore := state.GetBalance(devnetFeeRecipient)
+
+	devnet.land(t, block)
+
+	// the bundle landed on chain
+	receipt, _, _, _ := rawdb.ReadReceipt(devnet.eth.ChainDb(), tx.Hash(), devnet.eth.BlockChain().Config())
+	require.NotNil(t, receipt)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	require.Equal(t, block.Hash(), receipt.BlockHash)
+
+	// the proposer is paid the bid by the last transaction of the block
+	state, err = devnet.eth.BlockChain().State()
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(params.Ether), state.GetBalance(recipient))
+	bid := msg.Message.Value.ToBig()
+	require.Positive(t, bid.Sign())
+	require.Equal(t, bid, new(big.Int).Sub(state.GetBalance(devnetFeeRecipient), balanceBefore))
+	payout := block.Transactions()[len(block.Transactions())-1]
+	require.Equal(t, devnetFeeRecipient, *payout.To())
+
+	// the builder settles the slot as won once the chain moved past it
+	devnet.buildSlot(t, 2)
+	won, lost := devnet.builder.slots.outcomes()
+	require.Equal(t, uint64(1), won)
+	require.Equal(t, uint64(0), lost)
+}
+
+func TestDevnetMempoolBlocks(t *testing.T) {
+	devnet := newTestDevnet(t)
+
+	// successive slots build on top of the landed blocks, with the mempool transactions
+	for slot, nonce := uint64(1), uint64(0); slot <= 3; slot, nonce = slot+1, nonce+1 {
+		tx := devnet.transfer(nonce, common.Address{0x43}, big.NewInt(1))
+		require.NoError(t, devnet.eth.TxPool().AddLocal(tx))
+
+		devnet.buildSlot(t, slot)
+		_, block := devnet.relay.waitSubmission(t, slot, func(block *types.Block) bool {
+			return block.Transaction(tx.Hash()) != nil
+		})
+		devnet.land(t, block)
+	}
+	state, err := devnet.eth.BlockChain().State()
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(3), state.GetBalance(common.Address{0x43}))
+	require.Equal(t, uint64(3), state.GetNonce(devnetBankAddress))
+}
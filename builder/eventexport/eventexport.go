@@ -0,0 +1,243 @@
+// Package eventexport publishes builder lifecycle events to a message broker, so downstream
+// analytics can consume them without polling the RPC APIs.
+//
+// Every event is a JSON encoded Event published to the topic "<prefix>.<type>". Events are
+// sent to NATS over its client protocol, or to Kafka through a Kafka REST proxy. Publishing
+// never blocks the builder: events are queued and dropped if the broker falls behind.
+package eventexport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+type EventType string
+
+const (
+	EventBundleReceived  EventType = "bundle_received"
+	EventBundleSimulated EventType = "bundle_simulated"
+	EventBlockBuilt      EventType = "block_built"
+	EventBlockSubmitted  EventType = "block_submitted"
+	EventBlockLanded     EventType = "block_landed"
+)
+
+// SchemaVersion is the version of the event schema. It is only increased on changes
+// that break existing consumers, fields may be added without a version change.
+const SchemaVersion = 1
+
+// DefaultTopicPrefix is the prefix of the topics events are published to if none is configured
+const DefaultTopicPrefix = "builder"
+
+// queueSize bounds the events waiting to be published
+const queueSize = 4096
+
+var ErrUnsupportedBroker = errors.New("unsupported event broker, expected a nats:// or http(s):// Kafka REST proxy url")
+
+var (
+	publishedMeter = metrics.NewRegisteredMeter("builder/events/published", nil)
+	failedMeter    = metrics.NewRegisteredMeter("builder/events/failed", nil)
+	droppedMeter   = metrics.NewRegisteredMeter("builder/events/dropped", nil)
+)
+
+// defaultExporter holds the process-wide *Exporter published to by Publish
+var defaultExporter atomic.Value
+
+// Event is the envelope of every exported event.
+type Event struct {
+	Version int             `json:"version"`
+	Type    EventType       `json:"type"`
+	Time    time.Time       `json:"time"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// Publisher delivers encoded events to a broker topic.
+type Publisher interface {
+	Publish(topic string, payload []byte) error
+	Close() error
+}
+
+type message struct {
+	topic   string
+	payload []byte
+}
+
+// Exporter queues events and publishes them from a background goroutine.
+type Exporter struct {
+	publisher Publisher
+	broker    string
+	prefix    string
+
+	queue   chan message
+	quit    chan struct{}
+	wg      sync.WaitGroup
+	failing bool // Whether the last publish failed, to only log the first of a series of failures
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Open creates an exporter for the broker at rawURL: nats://[user:password@]host:port for NATS,
+// or the http(s) url of a Kafka REST proxy.
+func Open(rawURL string, topicPrefix string) (*Exporter, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event broker url: %w", err)
+	}
+	var publisher Publisher
+	switch u.Scheme {
+	case "nats":
+		publisher = newNATSPublisher(u)
+	case "http", "https":
+		publisher = newKafkaRESTPublisher(u)
+	default:
+		return nil, ErrUnsupportedBroker
+	}
+	exporter := NewExporter(publisher, topicPrefix)
+	exporter.broker = u.Redacted()
+	return exporter, nil
+}
+
+// NewExporter creates an exporter publishing events to the given publisher.
+func NewExporter(publisher Publisher, topicPrefix string) *Exporter {
+	if topicPrefix == "" {
+		topicPrefix = DefaultTopicPrefix
+	}
+	return &Exporter{
+		publisher: publisher,
+		prefix:    topicPrefix,
+		queue:     make(chan message, queueSize),
+		quit:      make(chan struct{}),
+	}
+}
+
+// Broker returns the url of the broker, without password.
+func (e *Exporter) Broker() string {
+	return e.broker
+}
+
+// Publish queues an event, dropping it if the queue is full or the exporter is closed.
+func (e *Exporter) Publish(typ EventType, data interface{}) error {
+	encodedData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(&Event{
+		Version: SchemaVersion,
+		Type:    typ,
+		Time:    time.Now().UTC(),
+		Data:    encodedData,
+	})
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return nil
+	}
+	select {
+	case e.queue <- message{topic: e.prefix + "." + string(typ), payload: payload}:
+	default:
+		droppedMeter.Mark(1)
+	}
+	return nil
+}
+
+func (e *Exporter) loop() {
+	defer e.wg.Done()
+
+	for {
+		select {
+		case msg := <-e.queue:
+			e.send(msg)
+		case <-e.quit:
+			// deliver what was queued before shutdown
+			for {
+				select {
+				case msg := <-e.queue:
+					e.send(msg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *Exporter) send(msg message) {
+	err := e.publisher.Publish(msg.topic, msg.payload)
+	if err != nil {
+		failedMeter.Mark(1)
+		if !e.failing {
+			log.Warn("Failed to export builder event", "topic", msg.topic, "err", err)
+		}
+		e.failing = true
+		return
+	}
+	publishedMeter.Mark(1)
+	if e.failing {
+		log.Info("Builder event export recovered", "topic", msg.topic)
+	}
+	e.failing = false
+}
+
+// Start implements node.Lifecycle, starting the publishing goroutine.
+func (e *Exporter) Start() error {
+	e.wg.Add(1)
+	go e.loop()
+	return nil
+}
+
+// Stop implements node.Lifecycle, publishing the queued events and closing the publisher.
+func (e *Exporter) Stop() error {
+	e.mu.Lock()
+	if e.closed {
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	e.mu.Unlock()
+
+	close(e.quit)
+	e.wg.Wait()
+	return e.publisher.Close()
+}
+
+// SetDefault installs the process-wide exporter used by Publish. Passing nil disables it.
+func SetDefault(e *Exporter) {
+	defaultExporter.Store(&e)
+}
+
+// Enabled returns true if a process-wide exporter is installed.
+func Enabled() bool {
+	return getDefault() != nil
+}
+
+func getDefault() *Exporter {
+	e, ok := defaultExporter.Load().(**Exporter)
+	if !ok {
+		return nil
+	}
+	return *e
+}
+
+// Publish exports an event through the process-wide exporter, if one is installed.
+func Publish(typ EventType, data interface{}) {
+	e := getDefault()
+	if e == nil {
+		return
+	}
+	if err := e.Publish(typ, data); err != nil {
+		log.Error("Failed to encode builder event", "type", typ, "err", err)
+	}
+}
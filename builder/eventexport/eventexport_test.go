@@ -0,0 +1,135 @@
+package eventexport
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type natsPub struct {
+	subject string
+	payload []byte
+}
+
+// serveNATS accepts a single client, pings it and forwards the published messages.
+func serveNATS(t *testing.T, listener net.Listener, pubs chan<- natsPub, pongs chan<- struct{}) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "INFO {\"server_id\":\"test\"}\r\n")
+	reader := bufio.NewReader(conn)
+	connect, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(connect, "CONNECT {") {
+		t.Errorf("unexpected connect %q: %v", connect, err)
+		return
+	}
+	fmt.Fprintf(conn, "PING\r\n")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if strings.HasPrefix(line, "PONG") {
+			pongs <- struct{}{}
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "PUB" {
+			t.Errorf("unexpected message %q", line)
+			return
+		}
+		subject := fields[1]
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			t.Errorf("invalid message size %q: %v", line, err)
+			return
+		}
+		payload := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return
+		}
+		pubs <- natsPub{subject: subject, payload: payload[:size]}
+	}
+}
+
+func TestNATSExport(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	pubs := make(chan natsPub, 1)
+	pongs := make(chan struct{}, 1)
+	go serveNATS(t, listener, pubs, pongs)
+
+	exporter, err := Open("nats://"+listener.Addr().String(), "")
+	require.NoError(t, err)
+	require.NoError(t, exporter.Start())
+
+	received := &BundleReceived{BundleHash: common.Hash{1}, BlockNumber: 10, Searcher: common.Address{2}}
+	require.NoError(t, exporter.Publish(EventBundleReceived, received))
+
+	pub := <-pubs
+	require.Equal(t, "builder.bundle_received", pub.subject)
+	var event Event
+	require.NoError(t, json.Unmarshal(pub.payload, &event))
+	require.Equal(t, SchemaVersion, event.Version)
+	require.Equal(t, EventBundleReceived, event.Type)
+	var data BundleReceived
+	require.NoError(t, json.Unmarshal(event.Data, &data))
+	require.Equal(t, *received, data)
+
+	<-pongs
+	require.NoError(t, exporter.Stop())
+}
+
+func TestKafkaRESTExport(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	exporter, err := Open(server.URL, "polygon")
+	require.NoError(t, err)
+	require.NoError(t, exporter.Start())
+	require.NoError(t, exporter.Publish(EventBlockSubmitted, &BlockSubmitted{Slot: 5, BlockNumber: 10}))
+
+	req := <-requests
+	require.Equal(t, "/topics/polygon.block_submitted", req.URL.Path)
+	require.Equal(t, kafkaRESTContentType, req.Header.Get("Content-Type"))
+
+	var produce struct {
+		Records []struct {
+			Value Event `json:"value"`
+		} `json:"records"`
+	}
+	require.NoError(t, json.Unmarshal(<-bodies, &produce))
+	require.Len(t, produce.Records, 1)
+	require.Equal(t, EventBlockSubmitted, produce.Records[0].Value.Type)
+
+	require.NoError(t, exporter.Stop())
+	// events published after shutdown are dropped
+	require.NoError(t, exporter.Publish(EventBlockSubmitted, &BlockSubmitted{Slot: 6}))
+}
+
+func TestOpenUnsupportedBroker(t *testing.T) {
+	_, err := Open("kafka://localhost:9092", "")
+	require.ErrorIs(t, err, ErrUnsupportedBroker)
+}
@@ -0,0 +1,68 @@
+package eventexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	kafkaRESTTimeout     = 5 * time.Second
+	kafkaRESTContentType = "application/vnd.kafka.json.v2+json"
+)
+
+// kafkaRESTPublisher produces events to Kafka topics through a Kafka REST proxy (v2 API).
+type kafkaRESTPublisher struct {
+	base   string
+	client *http.Client
+}
+
+func newKafkaRESTPublisher(u *url.URL) *kafkaRESTPublisher {
+	return &kafkaRESTPublisher{
+		base:   strings.TrimSuffix(u.String(), "/"),
+		client: &http.Client{Timeout: kafkaRESTTimeout},
+	}
+}
+
+type kafkaRecord struct {
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+func (p *kafkaRESTPublisher) Publish(topic string, payload []byte) error {
+	body, err := json.Marshal(&kafkaProduceRequest{Records: []kafkaRecord{{Value: payload}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.base+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaRESTContentType)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("kafka rest proxy returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (p *kafkaRESTPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
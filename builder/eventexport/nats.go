@@ -0,0 +1,153 @@
+package eventexport
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const natsDialTimeout = 5 * time.Second
+
+// natsPublisher publishes events to a NATS server with the core NATS client protocol. The
+// connection is established on the first publish and re-established after failures.
+type natsPublisher struct {
+	addr string
+	user string
+	pass string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newNATSPublisher(u *url.URL) *natsPublisher {
+	p := &natsPublisher{addr: u.Host}
+	if u.User != nil {
+		p.user = u.User.Username()
+		p.pass, _ = u.User.Password()
+	}
+	return p
+}
+
+// natsConnect are the options of the CONNECT message sent after the server INFO.
+type natsConnect struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+}
+
+func (p *natsPublisher) connect() error {
+	conn, err := net.DialTimeout("tcp", p.addr, natsDialTimeout)
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(natsDialTimeout))
+	info, err := reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(info, "INFO") {
+		conn.Close()
+		return fmt.Errorf("unexpected nats greeting: %q", strings.TrimSpace(info))
+	}
+	conn.SetReadDeadline(time.Time{})
+
+	options, err := json.Marshal(&natsConnect{Name: "builder", Lang: "go", User: p.user, Pass: p.pass})
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", options); err != nil {
+		conn.Close()
+		return err
+	}
+	p.conn = conn
+	go p.read(conn, reader)
+	return nil
+}
+
+// read answers the keepalive pings of the server and drops the connection on protocol errors.
+func (p *natsPublisher) read(conn net.Conn, reader *bufio.Reader) {
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			p.drop(conn)
+			return
+		}
+		switch {
+		case strings.HasPrefix(line, "PING"):
+			p.mu.Lock()
+			if p.conn == conn {
+				_, err = conn.Write([]byte("PONG\r\n"))
+			}
+			p.mu.Unlock()
+			if err != nil {
+				p.drop(conn)
+				return
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			log.Warn("NATS server error", "addr", p.addr, "err", strings.TrimSpace(line))
+			p.drop(conn)
+			return
+		}
+	}
+}
+
+// drop closes the connection, and forgets it if it is still the current one.
+func (p *natsPublisher) drop(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == conn {
+		p.conn = nil
+	}
+	conn.Close()
+}
+
+func (p *natsPublisher) Publish(topic string, payload []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		if err := p.connect(); err != nil {
+			return err
+		}
+	}
+	msg := make([]byte, 0, len(topic)+len(payload)+32)
+	msg = append(msg, fmt.Sprintf("PUB %s %d\r\n", topic, len(payload))...)
+	msg = append(msg, payload...)
+	msg = append(msg, "\r\n"...)
+	if _, err := p.conn.Write(msg); err != nil {
+		p.conn.Close()
+		p.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package eventexport
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BundleReceived is published for every bundle accepted through the bundle APIs.
+type BundleReceived struct {
+	BundleHash  common.Hash    `json:"bundleHash"`
+	SBundle     bool           `json:"sbundle,omitempty"`
+	Encrypted   bool           `json:"encrypted,omitempty"`
+	BlockNumber uint64         `json:"blockNumber"`
+	Searcher    common.Address `json:"searcher"`
+}
+
+// NewBundleReceived builds the event of an accepted bundle. The bundle hash matches the one
+// assigned by the transaction pool.
+func NewBundleReceived(txs types.Transactions, blockNumber uint64, signingAddress common.Address) *BundleReceived {
+	hashes := make([]byte, 0, len(txs)*common.HashLength)
+	for _, tx := range txs {
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	return &BundleReceived{
+		BundleHash:  crypto.Keccak256Hash(hashes),
+		BlockNumber: blockNumber,
+		Searcher:    signingAddress,
+	}
+}
+
+// BundleSimulated is published for every bundle simulation on top of a block being built.
+type BundleSimulated struct {
+	BundleHash  common.Hash    `json:"bundleHash"`
+	SBundle     bool           `json:"sbundle,omitempty"`
+	Searcher    common.Address `json:"searcher"`
+	BlockNumber uint64         `json:"blockNumber"`
+	ParentHash  common.Hash    `json:"parentHash"`
+	Success     bool           `json:"success"`
+	Error       string         `json:"error,omitempty"`
+	MevGasPrice *hexutil.Big   `json:"mevGasPrice,omitempty"`
+	Profit      *hexutil.Big   `json:"profit,omitempty"`
+}
+
+// BlockBuilt is published for every block built by the block building algorithms.
+type BlockBuilt struct {
+	BlockNumber  uint64        `json:"blockNumber"`
+	BlockHash    common.Hash   `json:"blockHash"`
+	ParentHash   common.Hash   `json:"parentHash"`
+	GasUsed      uint64        `json:"gasUsed"`
+	Transactions int           `json:"transactions"`
+	Bundles      []common.Hash `json:"bundles"`
+	Profit       *hexutil.Big  `json:"profit"`
+}
+
+// BlockSubmitted is published for every block submitted to a relay.
+type BlockSubmitted struct {
+	Slot        uint64       `json:"slot"`
+	BlockNumber uint64       `json:"blockNumber"`
+	BlockHash   common.Hash  `json:"blockHash"`
+	ParentHash  common.Hash  `json:"parentHash"`
+	Value       *hexutil.Big `json:"value"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// BlockLanded is published when a block built by the node becomes the chain head.
+type BlockLanded struct {
+	BlockNumber uint64       `json:"blockNumber"`
+	BlockHash   common.Hash  `json:"blockHash"`
+	Payout      *hexutil.Big `json:"payout"`
+	Margin      *hexutil.Big `json:"margin"`
+}
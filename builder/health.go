@@ -0,0 +1,340 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// healthCheckInterval is the period of the dependency health checks
+	healthCheckInterval = 10 * time.Second
+	// healthCheckTimeout bounds the duration of a single health check
+	healthCheckTimeout = 5 * time.Second
+	// healthStaleAfter is the age after which a check result is not trusted anymore
+	healthStaleAfter = 3 * healthCheckInterval
+
+	// HealthPath is the path of the health endpoint on the node HTTP server
+	HealthPath = "/builder/health"
+)
+
+var (
+	errHealthNotChecked = errors.New("not checked yet")
+	errHealthStale      = errors.New("check result is stale")
+	errNotSynced        = errors.New("node is not synced")
+)
+
+// HealthCheck is the latest result of a dependency check.
+type HealthCheck struct {
+	Name      string     `json:"name"`
+	Healthy   bool       `json:"healthy"`
+	Error     string     `json:"error,omitempty"`
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+	// LastHealthy is the last time the check succeeded
+	LastHealthy *time.Time `json:"lastHealthy,omitempty"`
+}
+
+// HealthReport is the health of the builder dependencies, healthy if all checks are.
+type HealthReport struct {
+	Healthy bool          `json:"healthy"`
+	Checks  []HealthCheck `json:"checks"`
+}
+
+type healthCheckFn func(ctx context.Context) error
+
+type healthResult struct {
+	err         error
+	checkedAt   time.Time
+	lastHealthy time.Time
+}
+
+// healthMonitor periodically checks the builder dependencies and serves the latest results,
+// so probes never wait on a slow dependency.
+type healthMonitor struct {
+	checks map[string]healthCheckFn
+
+	mu      sync.Mutex
+	results map[string]*healthResult
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newHealthMonitor() *healthMonitor {
+	return &healthMonitor{
+		checks:  make(map[string]healthCheckFn),
+		results: make(map[string]*healthResult),
+		quit:    make(chan struct{}),
+	}
+}
+
+// register adds a named check, must be called before Start.
+func (m *healthMonitor) register(name string, check healthCheckFn) {
+	m.checks[name] = check
+}
+
+// run executes all checks concurrently and records the results.
+func (m *healthMonitor) run() {
+	var wg sync.WaitGroup
+	for name, check := range m.checks {
+		wg.Add(1)
+		go func(name string, check healthCheckFn) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+			defer cancel()
+			err := check(ctx)
+			now := time.Now()
+
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			result, ok := m.results[name]
+			if !ok {
+				result = new(healthResult)
+				m.results[name] = result
+			}
+			if err != nil && (result.err == nil || result.err.Error() != err.Error()) {
+				log.Warn("Builder health check failed", "check", name, "err", err)
+			}
+			if err == nil && result.err != nil {
+				log.Info("Builder health check recovered", "check", name)
+			}
+			result.err = err
+			result.checkedAt = now
+			if err == nil {
+				result.lastHealthy = now
+			}
+		}(name, check)
+	}
+	wg.Wait()
+}
+
+func (m *healthMonitor) loop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+
+	m.run()
+	for {
+		select {
+		case <-ticker.C:
+			m.run()
+		case <-m.quit:
+			return
+		}
+	}
+}
+
+// Start implements node.Lifecycle, starting the periodic checks.
+func (m *healthMonitor) Start() error {
+	m.wg.Add(1)
+	go m.loop()
+	return nil
+}
+
+// Stop implements node.Lifecycle.
+func (m *healthMonitor) Stop() error {
+	close(m.quit)
+	m.wg.Wait()
+	return nil
+}
+
+// report returns the latest results of the given checks, all checks if none is given.
+func (m *healthMonitor) report(names ...string) (HealthReport, error) {
+	if len(names) == 0 {
+		for name := range m.checks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	report := HealthReport{Healthy: true, Checks: make([]HealthCheck, 0, len(names))}
+	now := time.Now()
+	for _, name := range names {
+		if _, ok := m.checks[name]; !ok {
+			return HealthReport{}, fmt.Errorf("unknown health check %q", name)
+		}
+		check := HealthCheck{Name: name}
+		result, ok := m.results[name]
+		switch {
+		case !ok:
+			check.Error = errHealthNotChecked.Error()
+		case now.Sub(result.checkedAt) > healthStaleAfter:
+			check.Error = errHealthStale.Error()
+		case result.err != nil:
+			check.Error = result.err.Error()
+		default:
+			check.Healthy = true
+		}
+		if ok {
+			checkedAt := result.checkedAt
+			check.CheckedAt = &checkedAt
+			if !result.lastHealthy.IsZero() {
+				lastHealthy := result.lastHealthy
+				check.LastHealthy = &lastHealthy
+			}
+		}
+		report.Healthy = report.Healthy && check.Healthy
+		report.Checks = append(report.Checks, check)
+	}
+	return report, nil
+}
+
+// ServeHTTP serves the health report, with status 200 if healthy and 503 otherwise. The report
+// can be restricted to some checks with the check query parameter, e.g. ?check=sync&check=signer.
+func (m *healthMonitor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report, err := m.report(r.URL.Query()["check"]...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !report.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// registerHealthChecks adds the checks of the builder dependencies: the consensus client,
+// the remote relays, the chain sync, the bundle pool and the builder tx signer.
+func registerHealthChecks(m *healthMonitor, backend *eth.Ethereum, cfg *Config) {
+	client := &http.Client{Timeout: healthCheckTimeout}
+
+	if len(cfg.BeaconEndpoints) > 0 {
+		endpoints := cfg.BeaconEndpoints
+		m.register("consensus", func(ctx context.Context) error {
+			// the builder only needs one of the consensus clients to follow the chain
+			var errs []string
+			for _, endpoint := range endpoints {
+				err := checkEndpoint(ctx, client, endpoint, "/eth/v1/node/health")
+				if err == nil {
+					return nil
+				}
+				errs = append(errs, err.Error())
+			}
+			return errors.New(strings.Join(errs, "; "))
+		})
+	}
+
+	relays := make([]string, 0, 1+len(cfg.SecondaryRemoteRelayEndpoints))
+	if cfg.RemoteRelayEndpoint != "" {
+		relays = append(relays, cfg.RemoteRelayEndpoint)
+	}
+	for _, endpoint := range cfg.SecondaryRemoteRelayEndpoints {
+		if endpoint != "" {
+			relays = append(relays, endpoint)
+		}
+	}
+	for _, endpoint := range relays {
+		relayConfig, err := getRelayConfig(endpoint)
+		if err != nil {
+			continue
+		}
+		m.register("relay/"+relayHost(relayConfig.Endpoint), func(ctx context.Context) error {
+			return checkEndpoint(ctx, client, relayConfig.Endpoint, _PathStatus)
+		})
+	}
+
+	if heimdall := backend.Miner().Heimdall(); heimdall != nil {
+		m.register("heimdall", func(ctx context.Context) error {
+			_, err := heimdall.LatestCheckpoint(ctx)
+			return err
+		})
+	}
+
+	maxHeadAge := 4 * time.Duration(cfg.SecondsInSlot) * time.Second
+	m.register("sync", func(ctx context.Context) error {
+		if !backend.Synced() {
+			return errNotSynced
+		}
+		head := backend.BlockChain().CurrentHeader()
+		if age := time.Since(time.Unix(int64(head.Time), 0)); maxHeadAge > 0 && age > maxHeadAge {
+			return fmt.Errorf("head block %d is %v old", head.Number.Uint64(), age.Truncate(time.Second))
+		}
+		return nil
+	})
+
+	m.register("bundlepool", func(ctx context.Context) error {
+		// the pool lock also guards the bundles, a stuck pool does not answer in time
+		done := make(chan struct{})
+		go func() {
+			backend.TxPool().Stats()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return errors.New("bundle pool is not responding")
+		}
+	})
+
+	m.register("signer", func(ctx context.Context) error {
+		done := make(chan error, 1)
+		go func() {
+			done <- backend.Miner().CheckTxSigner()
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return errors.New("tx signer is not responding")
+		}
+	})
+}
+
+// checkEndpoint returns an error unless a GET of path on the endpoint returns a 2xx status.
+func checkEndpoint(ctx context.Context, client *http.Client, endpoint, path string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	u.User = nil
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", u.Host, unwrapURLError(err))
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s: %s", u.Host, resp.Status)
+	}
+	return nil
+}
+
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// relayHost returns the host of a relay endpoint, without the relay pubkey.
+func relayHost(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return endpoint
+	}
+	return u.Host
+}
@@ -0,0 +1,82 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthMonitor(t *testing.T) {
+	monitor := newHealthMonitor()
+	monitor.register("relay/a", func(ctx context.Context) error { return nil })
+	monitor.register("sync", func(ctx context.Context) error { return errNotSynced })
+
+	report, err := monitor.report()
+	require.NoError(t, err)
+	require.False(t, report.Healthy)
+	require.Equal(t, errHealthNotChecked.Error(), report.Checks[0].Error)
+
+	monitor.run()
+	report, err = monitor.report()
+	require.NoError(t, err)
+	require.False(t, report.Healthy)
+	require.Len(t, report.Checks, 2)
+	require.Equal(t, "relay/a", report.Checks[0].Name)
+	require.True(t, report.Checks[0].Healthy)
+	require.NotNil(t, report.Checks[0].LastHealthy)
+	require.Equal(t, "sync", report.Checks[1].Name)
+	require.Equal(t, errNotSynced.Error(), report.Checks[1].Error)
+	require.NotNil(t, report.Checks[1].CheckedAt)
+	require.Nil(t, report.Checks[1].LastHealthy)
+
+	// probes can target a single check
+	rec := httptest.NewRecorder()
+	monitor.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, HealthPath+"?check=relay/a", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	monitor.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, HealthPath, nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	var served HealthReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	require.Len(t, served.Checks, 2)
+
+	rec = httptest.NewRecorder()
+	monitor.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, HealthPath+"?check=heimdall", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// results that were not refreshed are not trusted
+	monitor.results["relay/a"].checkedAt = time.Now().Add(-2 * healthStaleAfter)
+	report, err = monitor.report("relay/a")
+	require.NoError(t, err)
+	require.False(t, report.Healthy)
+	require.Equal(t, errHealthStale.Error(), report.Checks[0].Error)
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	var unhealthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, _PathStatus, r.URL.Path)
+		if unhealthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Timeout: time.Second}
+	require.NoError(t, checkEndpoint(context.Background(), client, server.URL, _PathStatus))
+
+	unhealthy.Store(true)
+	err := checkEndpoint(context.Background(), client, server.URL, _PathStatus)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "500")
+
+	server.Close()
+	require.Error(t, checkEndpoint(context.Background(), client, server.URL, _PathStatus))
+}
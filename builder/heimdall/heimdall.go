@@ -0,0 +1,350 @@
+// Package heimdall is a client of the Heimdall REST API serving the Bor spans, checkpoints and
+// state-sync events to the builder.
+//
+// The client fails over between several Heimdall endpoints: every request is bounded by a
+// timeout, a failing endpoint is skipped for a cooldown and the next one is tried, so that a
+// single slow endpoint does not stall block production. The immutable responses are cached,
+// the latest span and checkpoint for a short time.
+package heimdall
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// spanCacheSize is the number of spans kept
+	spanCacheSize = 16
+	// eventCacheSize is the number of state-sync event pages kept
+	eventCacheSize = 64
+)
+
+var (
+	requestTimer  = metrics.NewRegisteredTimer("builder/heimdall/request", nil)
+	attemptTimer  = metrics.NewRegisteredTimer("builder/heimdall/attempt", nil)
+	failureMeter  = metrics.NewRegisteredMeter("builder/heimdall/failures", nil)
+	failoverMeter = metrics.NewRegisteredMeter("builder/heimdall/failovers", nil)
+
+	// latency of the requests by call, including the failovers
+	spanTimer       = metrics.NewRegisteredTimer("builder/heimdall/request/span", nil)
+	latestSpanTimer = metrics.NewRegisteredTimer("builder/heimdall/request/latestspan", nil)
+	checkpointTimer = metrics.NewRegisteredTimer("builder/heimdall/request/checkpoint", nil)
+	eventsTimer     = metrics.NewRegisteredTimer("builder/heimdall/request/events", nil)
+
+	errNoEndpoint = errors.New("no heimdall endpoint")
+)
+
+// statusError is a Heimdall response with an error status.
+type statusError int
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("status %d", int(e))
+}
+
+// definitive reports whether the status is an answer of a healthy endpoint, such as a missing
+// span, that the other endpoints would give as well.
+func (e statusError) definitive() bool {
+	return e >= 400 && e < 500 && e != http.StatusTooManyRequests
+}
+
+// DefaultConfig is the default config of the Heimdall client.
+var DefaultConfig = Config{
+	Timeout:  time.Second,
+	Cooldown: 30 * time.Second,
+	CacheTTL: 5 * time.Second,
+}
+
+// Config configures the Heimdall client.
+type Config struct {
+	Endpoints []string      `toml:",omitempty"` // REST endpoints, in order of preference
+	Timeout   time.Duration // Timeout of a request to an endpoint
+	Cooldown  time.Duration // Time a failing endpoint is skipped
+	CacheTTL  time.Duration // Time the latest span and checkpoint are cached
+}
+
+// Validator is a Bor validator of a span.
+type Validator struct {
+	ID       uint64         `json:"ID"`
+	Signer   common.Address `json:"signer"`
+	Power    int64          `json:"power"`
+	Priority int64          `json:"accum"`
+}
+
+// Span is a range of Bor blocks produced by the same selected producers.
+type Span struct {
+	ID                uint64      `json:"span_id"`
+	StartBlock        uint64      `json:"start_block"`
+	EndBlock          uint64      `json:"end_block"`
+	SelectedProducers []Validator `json:"selected_producers"`
+	ChainID           string      `json:"bor_chain_id"`
+}
+
+// Checkpoint is a range of Bor blocks checkpointed to the root chain.
+type Checkpoint struct {
+	Proposer   common.Address `json:"proposer"`
+	StartBlock uint64         `json:"start_block"`
+	EndBlock   uint64         `json:"end_block"`
+	RootHash   common.Hash    `json:"root_hash"`
+	ChainID    string         `json:"bor_chain_id"`
+	Timestamp  uint64         `json:"timestamp"`
+}
+
+// EventRecord is a state-sync event committed to Bor.
+type EventRecord struct {
+	ID         uint64         `json:"id"`
+	Contract   common.Address `json:"contract"`
+	Data       hexutil.Bytes  `json:"data"`
+	TxHash     common.Hash    `json:"tx_hash"`
+	LogIndex   uint64         `json:"log_index"`
+	ChainID    string         `json:"bor_chain_id"`
+	RecordTime time.Time      `json:"record_time"`
+}
+
+// endpoint is a Heimdall endpoint and its failures.
+type endpoint struct {
+	url         string
+	failedUntil time.Time // The endpoint is skipped until then
+}
+
+// eventQuery selects a page of state-sync events.
+type eventQuery struct {
+	fromID uint64
+	toTime int64
+	limit  int
+}
+
+// cachedResult is a response cached until expiry.
+type cachedResult[T any] struct {
+	value  *T
+	expiry time.Time
+}
+
+// Client is a Heimdall client failing over between several endpoints, safe for concurrent use.
+type Client struct {
+	config Config
+	client *http.Client
+
+	mu        sync.Mutex
+	endpoints []*endpoint
+	preferred int // Endpoint of the last successful request
+
+	spans            *lru.Cache[uint64, *Span]
+	events           *lru.Cache[eventQuery, []*EventRecord]
+	latestSpan       cachedResult[Span]
+	latestCheckpoint cachedResult[Checkpoint]
+}
+
+// New returns a client of the configured endpoints, nil if there is none.
+func New(config Config) (*Client, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultConfig.Timeout
+	}
+	c := &Client{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		spans:  lru.NewCache[uint64, *Span](spanCacheSize),
+		events: lru.NewCache[eventQuery, []*EventRecord](eventCacheSize),
+	}
+	for _, rawURL := range config.Endpoints {
+		if rawURL == "" {
+			continue
+		}
+		if _, err := url.ParseRequestURI(rawURL); err != nil {
+			return nil, fmt.Errorf("invalid heimdall endpoint %s: %w", rawURL, err)
+		}
+		c.endpoints = append(c.endpoints, &endpoint{url: strings.TrimSuffix(rawURL, "/")})
+	}
+	if len(c.endpoints) == 0 {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// Span returns the span of the given id.
+func (c *Client) Span(ctx context.Context, id uint64) (*Span, error) {
+	if span, ok := c.spans.Get(id); ok {
+		return span, nil
+	}
+	span := new(Span)
+	if err := c.get(ctx, spanTimer, "bor/span/"+strconv.FormatUint(id, 10), nil, span); err != nil {
+		return nil, err
+	}
+	c.spans.Add(id, span)
+	return span, nil
+}
+
+// LatestSpan returns the latest span.
+func (c *Client) LatestSpan(ctx context.Context) (*Span, error) {
+	c.mu.Lock()
+	cached := c.latestSpan
+	c.mu.Unlock()
+	if cached.value != nil && time.Now().Before(cached.expiry) {
+		return cached.value, nil
+	}
+	span := new(Span)
+	if err := c.get(ctx, latestSpanTimer, "bor/latest-span", nil, span); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.latestSpan = cachedResult[Span]{value: span, expiry: time.Now().Add(c.config.CacheTTL)}
+	c.mu.Unlock()
+	return span, nil
+}
+
+// LatestCheckpoint returns the latest checkpoint.
+func (c *Client) LatestCheckpoint(ctx context.Context) (*Checkpoint, error) {
+	c.mu.Lock()
+	cached := c.latestCheckpoint
+	c.mu.Unlock()
+	if cached.value != nil && time.Now().Before(cached.expiry) {
+		return cached.value, nil
+	}
+	checkpoint := new(Checkpoint)
+	if err := c.get(ctx, checkpointTimer, "checkpoints/latest", nil, checkpoint); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.latestCheckpoint = cachedResult[Checkpoint]{value: checkpoint, expiry: time.Now().Add(c.config.CacheTTL)}
+	c.mu.Unlock()
+	return checkpoint, nil
+}
+
+// StateSyncEvents returns up to limit state-sync events from the given id, recorded before toTime.
+func (c *Client) StateSyncEvents(ctx context.Context, fromID uint64, toTime int64, limit int) ([]*EventRecord, error) {
+	query := eventQuery{fromID: fromID, toTime: toTime, limit: limit}
+	if events, ok := c.events.Get(query); ok {
+		return events, nil
+	}
+	params := url.Values{}
+	params.Set("from-id", strconv.FormatUint(fromID, 10))
+	params.Set("to-time", strconv.FormatInt(toTime, 10))
+	params.Set("limit", strconv.Itoa(limit))
+
+	var events []*EventRecord
+	if err := c.get(ctx, eventsTimer, "clerk/event-record/list", params, &events); err != nil {
+		return nil, err
+	}
+	c.events.Add(query, events)
+	return events, nil
+}
+
+// get requests the result of a Heimdall path, failing over to the next endpoints. The latency
+// of the request is recorded by the timer of the call.
+func (c *Client) get(ctx context.Context, timer metrics.Timer, path string, params url.Values, result interface{}) error {
+	start := time.Now()
+	defer func() {
+		requestTimer.UpdateSince(start)
+		timer.UpdateSince(start)
+	}()
+
+	var errs []string
+	for attempt, i := range c.order() {
+		if attempt > 0 {
+			failoverMeter.Mark(1)
+		}
+		attemptStart := time.Now()
+		err := c.request(ctx, c.endpoints[i].url, path, params, result)
+		attemptTimer.UpdateSince(attemptStart)
+		var status statusError
+		if err == nil || (errors.As(err, &status) && status.definitive()) {
+			c.succeeded(i)
+			if err != nil {
+				return fmt.Errorf("heimdall %s: %w", path, err)
+			}
+			return nil
+		}
+		failureMeter.Mark(1)
+		c.failed(i)
+		log.Debug("Heimdall request failed", "endpoint", c.endpoints[i].url, "path", path, "err", err)
+		errs = append(errs, err.Error())
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return errNoEndpoint
+	}
+	return fmt.Errorf("heimdall %s: %s", path, strings.Join(errs, "; "))
+}
+
+// order returns the endpoints to try, from the preferred one, the endpoints cooling down after
+// a failure last.
+func (c *Client) order() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		now     = time.Now()
+		healthy []int
+		failing []int
+	)
+	for n := 0; n < len(c.endpoints); n++ {
+		i := (c.preferred + n) % len(c.endpoints)
+		if now.Before(c.endpoints[i].failedUntil) {
+			failing = append(failing, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	return append(healthy, failing...)
+}
+
+func (c *Client) succeeded(i int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endpoints[i].failedUntil = time.Time{}
+	c.preferred = i
+}
+
+func (c *Client) failed(i int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endpoints[i].failedUntil = time.Now().Add(c.config.Cooldown)
+}
+
+// request gets the result of a path from an endpoint.
+func (c *Client) request(ctx context.Context, endpoint, path string, params url.Values, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	target := endpoint + "/" + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp.StatusCode)
+	}
+	var response struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	if len(response.Result) == 0 {
+		return errors.New("invalid response: no result")
+	}
+	return json.Unmarshal(response.Result, result)
+}
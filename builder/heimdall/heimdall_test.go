@@ -0,0 +1,90 @@
+package heimdall
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func serveHeimdall(t *testing.T, delay time.Duration, requests *atomic.Int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(delay)
+		var result interface{}
+		switch r.URL.Path {
+		case "/bor/span/3":
+			result = &Span{ID: 3, StartBlock: 100, EndBlock: 199}
+		case "/bor/latest-span":
+			result = &Span{ID: 4, StartBlock: 200, EndBlock: 299}
+		case "/checkpoints/latest":
+			result = &Checkpoint{StartBlock: 10, EndBlock: 20}
+		case "/clerk/event-record/list":
+			require.Equal(t, "7", r.URL.Query().Get("from-id"))
+			result = []*EventRecord{{ID: 7}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"height": "1", "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClientFailover(t *testing.T) {
+	var slowRequests, requests atomic.Int32
+	slow := serveHeimdall(t, time.Second, &slowRequests)
+	fast := serveHeimdall(t, 0, &requests)
+
+	client, err := New(Config{Endpoints: []string{slow.URL, fast.URL}, Timeout: 50 * time.Millisecond, Cooldown: time.Minute, CacheTTL: time.Minute})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// the slow endpoint times out and the request fails over to the next one
+	span, err := client.Span(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), span.StartBlock)
+	require.Equal(t, int32(1), slowRequests.Load())
+
+	// the failing endpoint is skipped during its cooldown
+	latest, err := client.LatestSpan(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), latest.ID)
+	checkpoint, err := client.LatestCheckpoint(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(20), checkpoint.EndBlock)
+	events, err := client.StateSyncEvents(ctx, 7, 1000, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, int32(1), slowRequests.Load())
+	require.Equal(t, int32(4), requests.Load())
+
+	// the responses are cached
+	_, err = client.Span(ctx, 3)
+	require.NoError(t, err)
+	_, err = client.LatestSpan(ctx)
+	require.NoError(t, err)
+	_, err = client.LatestCheckpoint(ctx)
+	require.NoError(t, err)
+	_, err = client.StateSyncEvents(ctx, 7, 1000, 10)
+	require.NoError(t, err)
+	require.Equal(t, int32(4), requests.Load())
+
+	// a missing span is not failed over
+	_, err = client.Span(ctx, 5)
+	require.Error(t, err)
+	require.Equal(t, int32(5), requests.Load())
+	require.Equal(t, int32(1), slowRequests.Load())
+
+	client, err = New(Config{})
+	require.NoError(t, err)
+	require.Nil(t, client)
+	_, err = New(Config{Endpoints: []string{"heimdall"}})
+	require.Error(t, err)
+}
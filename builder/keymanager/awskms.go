@@ -0,0 +1,251 @@
+package keymanager
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	kmsService        = "kms"
+	kmsTimeout        = 2 * time.Second
+	kmsSigningAlgo    = "ECDSA_SHA_256"
+	kmsAmzDateFormat  = "20060102T150405Z"
+	kmsAmzShortFormat = "20060102"
+)
+
+var (
+	ErrKMSInvalidSignature = errors.New("kms returned an invalid signature")
+	ErrKMSInvalidPublicKey = errors.New("kms returned an invalid secp256k1 public key")
+	ErrKMSMissingRegion    = errors.New("kms region is not set")
+
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+type kmsCredentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// kmsSigner signs transactions with an asymmetric ECC_SECG_P256K1 key held in AWS KMS.
+type kmsSigner struct {
+	keyID    string
+	region   string
+	endpoint string
+	creds    kmsCredentials
+	client   *http.Client
+
+	pubkey  []byte // Uncompressed public key of the KMS key
+	address common.Address
+}
+
+// newKMSSignerFromURL creates a signer for awskms://<key id>?region=<region>[&endpoint=<url>].
+func newKMSSignerFromURL(ref *url.URL) (*kmsSigner, error) {
+	query := ref.Query()
+	region := query.Get("region")
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		return nil, ErrKMSMissingRegion
+	}
+	endpoint := query.Get("endpoint")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com", region)
+	}
+	creds := kmsCredentials{
+		accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	return newKMSSigner(strings.Trim(ref.Host+ref.Path, "/"), region, endpoint, creds)
+}
+
+func newKMSSigner(keyID, region, endpoint string, creds kmsCredentials) (*kmsSigner, error) {
+	s := &kmsSigner{
+		keyID:    keyID,
+		region:   region,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		creds:    creds,
+		client:   &http.Client{Timeout: kmsTimeout},
+	}
+
+	var resp struct {
+		PublicKey []byte `json:"PublicKey"`
+	}
+	if err := s.call("GetPublicKey", map[string]interface{}{"KeyId": keyID}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to get kms public key: %w", err)
+	}
+
+	// PublicKey is a DER encoded SubjectPublicKeyInfo
+	var spki struct {
+		Algorithm struct {
+			Algorithm  asn1.ObjectIdentifier
+			Parameters asn1.ObjectIdentifier
+		}
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(resp.PublicKey, &spki); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKMSInvalidPublicKey, err)
+	}
+	pubkey, err := crypto.UnmarshalPubkey(spki.PublicKey.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKMSInvalidPublicKey, err)
+	}
+	s.pubkey = spki.PublicKey.Bytes
+	s.address = crypto.PubkeyToAddress(*pubkey)
+	return s, nil
+}
+
+func (s *kmsSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *kmsSigner) SignTx(tx *types.Transaction, signer types.Signer) (*types.Transaction, error) {
+	sig, err := s.sign(signer.Hash(tx))
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// sign returns the [R || S || V] signature of hash, as produced by crypto.Sign.
+func (s *kmsSigner) sign(hash common.Hash) ([]byte, error) {
+	var resp struct {
+		Signature []byte `json:"Signature"`
+	}
+	err := s.call("Sign", map[string]interface{}{
+		"KeyId":            s.keyID,
+		"Message":          hash.Bytes(),
+		"MessageType":      "DIGEST",
+		"SigningAlgorithm": kmsSigningAlgo,
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("kms signing failed: %w", err)
+	}
+
+	var der struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(resp.Signature, &der); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKMSInvalidSignature, err)
+	}
+	// Ethereum only accepts signatures in the lower half of the curve order
+	if der.S.Cmp(secp256k1HalfN) > 0 {
+		der.S = new(big.Int).Sub(secp256k1N, der.S)
+	}
+
+	sig := make([]byte, crypto.SignatureLength)
+	der.R.FillBytes(sig[:32])
+	der.S.FillBytes(sig[32:64])
+	// KMS does not return the recovery id, find the one matching our key
+	for v := byte(0); v < 2; v++ {
+		sig[64] = v
+		if pubkey, err := crypto.Ecrecover(hash.Bytes(), sig); err == nil && bytes.Equal(pubkey, s.pubkey) {
+			return sig, nil
+		}
+	}
+	return nil, ErrKMSInvalidSignature
+}
+
+// call invokes a KMS API action, signing the request with AWS signature version 4.
+func (s *kmsSigner) call(action string, params interface{}, result interface{}) error {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "TrentService."+action)
+	s.signRequest(req, payload, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kms returned status %d: %s", resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, result)
+}
+
+func (s *kmsSigner) signRequest(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format(kmsAmzDateFormat)
+	shortDate := now.Format(kmsAmzShortFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.creds.sessionToken)
+	}
+
+	headers := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	if s.creds.sessionToken != "" {
+		headers = append(headers, "x-amz-security-token")
+	}
+	sort.Strings(headers)
+	var canonicalHeaders strings.Builder
+	for _, h := range headers {
+		value := req.Header.Get(h)
+		if h == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		"/",
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := shortDate + "/" + s.region + "/" + kmsService + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.creds.secretAccessKey), shortDate)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, kmsService)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.creds.accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,154 @@
+package keymanager
+
+import (
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeyHex = "b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291"
+
+func TestResolveSecret(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/builder":
+			w.Write([]byte(`{"data":{"data":{"tx_key":"0x` + testKeyHex + `"},"metadata":{"version":1}}}`))
+		case "/v1/kv/builder":
+			w.Write([]byte(`{"data":{"key":"0x` + testKeyHex + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "test-token")
+
+	secret, err := ResolveSecret("0x" + testKeyHex)
+	require.NoError(t, err)
+	require.Equal(t, "0x"+testKeyHex, secret)
+
+	secret, err = ResolveSecret("vault://secret/data/builder?field=tx_key")
+	require.NoError(t, err)
+	require.Equal(t, "0x"+testKeyHex, secret)
+
+	secret, err = ResolveSecret("vault://kv/builder")
+	require.NoError(t, err)
+	require.Equal(t, "0x"+testKeyHex, secret)
+
+	_, err = ResolveSecret("vault://kv/builder?field=missing")
+	require.ErrorIs(t, err, ErrVaultFieldNotFound)
+
+	_, err = ResolveSecret("vault://kv/unknown")
+	require.Error(t, err)
+
+	_, err = ResolveSecret("awskms://key-id?region=us-east-1")
+	require.ErrorIs(t, err, ErrNotSigningKey)
+
+	_, err = ResolveSecret("pkcs11://token/key")
+	require.ErrorIs(t, err, ErrUnsupportedScheme)
+
+	signer, err := NewTxSigner("vault://secret/data/builder?field=tx_key")
+	require.NoError(t, err)
+	key, _ := crypto.HexToECDSA(testKeyHex)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer.Address())
+}
+
+// kmsPublicKeyInfo returns the DER encoded SubjectPublicKeyInfo of key, as returned by KMS GetPublicKey.
+func kmsPublicKeyInfo(t *testing.T, key *ecdsa.PublicKey) []byte {
+	var spki struct {
+		Algorithm struct {
+			Algorithm  asn1.ObjectIdentifier
+			Parameters asn1.ObjectIdentifier
+		}
+		PublicKey asn1.BitString
+	}
+	spki.Algorithm.Algorithm = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	spki.Algorithm.Parameters = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	pub := crypto.FromECDSAPub(key)
+	spki.PublicKey = asn1.BitString{Bytes: pub, BitLength: len(pub) * 8}
+	encoded, err := asn1.Marshal(spki)
+	require.NoError(t, err)
+	return encoded
+}
+
+func newFakeKMS(t *testing.T, key *ecdsa.PrivateKey) *httptest.Server {
+	var signatures int
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=test-access-key/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.GetPublicKey":
+			json.NewEncoder(w).Encode(map[string][]byte{"PublicKey": kmsPublicKeyInfo(t, &key.PublicKey)})
+		case "TrentService.Sign":
+			var req struct {
+				Message []byte
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			sig, err := crypto.Sign(req.Message, key)
+			require.NoError(t, err)
+
+			der := struct{ R, S *big.Int }{new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])}
+			// KMS signatures are not normalized, return every other one in the upper half of the curve order
+			if signatures%2 == 1 {
+				der.S.Sub(secp256k1N, der.S)
+			}
+			signatures++
+			encoded, err := asn1.Marshal(der)
+			require.NoError(t, err)
+			json.NewEncoder(w).Encode(map[string][]byte{"Signature": encoded})
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestKMSSigner(t *testing.T) {
+	key, _ := crypto.HexToECDSA(testKeyHex)
+	srv := newFakeKMS(t, key)
+	defer srv.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "test-access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test-secret-key")
+
+	_, err := NewTxSigner("awskms://test-key?endpoint=" + srv.URL)
+	require.ErrorIs(t, err, ErrKMSMissingRegion)
+
+	signer, err := NewTxSigner("awskms://test-key?region=us-east-1&endpoint=" + srv.URL)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), signer.Address())
+
+	chainSigner := types.LatestSignerForChainID(big.NewInt(1))
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		tx, err := signer.SignTx(types.NewTx(&types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     nonce,
+			GasTipCap: new(big.Int),
+			GasFeeCap: big.NewInt(1),
+			Gas:       21000,
+			To:        &common.Address{},
+			Value:     big.NewInt(1),
+		}), chainSigner)
+		require.NoError(t, err)
+
+		sender, err := types.Sender(chainSigner, tx)
+		require.NoError(t, err)
+		require.Equal(t, signer.Address(), sender)
+	}
+}
@@ -0,0 +1,96 @@
+package keymanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultVaultAddr  = "http://127.0.0.1:8200"
+	defaultVaultField = "key"
+	vaultTimeout      = 10 * time.Second
+)
+
+var ErrVaultFieldNotFound = errors.New("field not found in vault secret")
+
+type vaultClient struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+func newVaultClientFromEnv() *vaultClient {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		addr = defaultVaultAddr
+	}
+	return &vaultClient{
+		addr:   strings.TrimSuffix(addr, "/"),
+		token:  os.Getenv("VAULT_TOKEN"),
+		client: &http.Client{Timeout: vaultTimeout},
+	}
+}
+
+// readSecret reads a field of a KV secret referenced as vault://<secret path>?field=<name>.
+// Both KV version 1 and version 2 (path including "/data/") secrets are supported.
+func (c *vaultClient) readSecret(ref *url.URL) (string, error) {
+	path := strings.Trim(ref.Host+ref.Path, "/")
+	field := ref.Query().Get("field")
+	if field == "" {
+		field = defaultVaultField
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.addr+"/v1/"+path, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned status %d for secret %s", resp.StatusCode, path)
+	}
+
+	var secret struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	data := secret.Data
+	// KV version 2 nests the secret data and its metadata
+	if nested, ok := data["data"]; ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return "", fmt.Errorf("invalid vault response: %w", err)
+			}
+		}
+	}
+
+	raw, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrVaultFieldNotFound, field)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("vault secret field %s is not a string", field)
+	}
+	return value, nil
+}
@@ -0,0 +1,49 @@
+package builder
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	candidateBlockMeter      = metrics.NewRegisteredMeter("builder/candidate/blocks", nil)
+	candidateProfitHistogram = metrics.NewRegisteredHistogram("builder/candidate/profit", nil, metrics.NewExpDecaySample(1028, 0.015))
+
+	submissionTimer          = metrics.NewRegisteredTimer("builder/submission/duration", nil)
+	submissionSuccessMeter   = metrics.NewRegisteredMeter("builder/submission/success", nil)
+	submissionFailureMeter   = metrics.NewRegisteredMeter("builder/submission/failure", nil)
+	submissionValueHistogram = metrics.NewRegisteredHistogram("builder/submission/value", nil, metrics.NewExpDecaySample(1028, 0.015))
+
+	slotWonMeter     = metrics.NewRegisteredMeter("builder/slot/won", nil)
+	slotLostMeter    = metrics.NewRegisteredMeter("builder/slot/lost", nil)
+	slotWinRateGauge = metrics.NewRegisteredGaugeFloat64("builder/slot/winrate", nil)
+)
+
+// weiToGwei converts a block value to gwei so it fits the int64 metric samples.
+func weiToGwei(value *big.Int) int64 {
+	return new(big.Int).Div(value, big.NewInt(params.GWei)).Int64()
+}
+
+func markCandidateBlock(blockValue *big.Int) {
+	if !metrics.EnabledBuilder {
+		return
+	}
+	candidateBlockMeter.Mark(1)
+	candidateProfitHistogram.Update(weiToGwei(blockValue))
+}
+
+func markBlockSubmission(start time.Time, blockValue *big.Int, err error) {
+	if !metrics.EnabledBuilder {
+		return
+	}
+	submissionTimer.UpdateSince(start)
+	if err != nil {
+		submissionFailureMeter.Mark(1)
+		return
+	}
+	submissionSuccessMeter.Mark(1)
+	submissionValueHistogram.Update(weiToGwei(blockValue))
+}
@@ -0,0 +1,130 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxPrebuiltCandidates is the number of pre-built candidate blocks kept
+const maxPrebuiltCandidates = 16
+
+// prebuiltKey identifies the building job a candidate was pre-built for.
+type prebuiltKey struct {
+	slot      uint64
+	parent    common.Hash
+	timestamp uint64
+}
+
+// prebuiltCandidates keeps the most valuable blocks pre-built on top of the likely parents of a
+// reorg, such as the competing blocks near a Bor sprint boundary. The job of the slot seeds its
+// submission queue with the candidate of its parent, if any, instead of waiting for a build.
+type prebuiltCandidates struct {
+	mu      sync.Mutex
+	entries map[prebuiltKey]blockQueueEntry
+	order   []prebuiltKey // Oldest first
+}
+
+func newPrebuiltCandidates() *prebuiltCandidates {
+	return &prebuiltCandidates{entries: make(map[prebuiltKey]blockQueueEntry)}
+}
+
+// add keeps the candidate if it is more valuable than the one pre-built for the same job.
+func (c *prebuiltCandidates) add(key prebuiltKey, entry blockQueueEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if best, ok := c.entries[key]; ok {
+		if best.blockValue.Cmp(entry.blockValue) >= 0 {
+			return
+		}
+	} else {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = entry
+	for len(c.order) > maxPrebuiltCandidates {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// take removes and returns the candidate pre-built for the job.
+func (c *prebuiltCandidates) take(key prebuiltKey) (blockQueueEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return blockQueueEntry{}, false
+	}
+	delete(c.entries, key)
+	for i, k := range c.order {
+		if k == key {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	return entry, true
+}
+
+// Prebuild builds a candidate block of the current slot on top of the given parent, a recent
+// block that may not be canonical. The candidate is submitted at once if the payload attributes
+// of the slot later name that parent.
+func (b *Builder) Prebuild(parent common.Hash) error {
+	b.slotMu.Lock()
+	attrs := b.slotAttrs
+	b.slotMu.Unlock()
+	if attrs.Slot == 0 {
+		return errors.New("no slot being built")
+	}
+	if attrs.HeadHash == parent {
+		return errors.New("parent is the head of the slot being built")
+	}
+	parentBlock := b.eth.GetBlockByHash(parent)
+	if parentBlock == nil {
+		return fmt.Errorf("parent block %s not found", parent)
+	}
+	attrs.HeadHash = parent
+	if b.schedule != nil {
+		attrs.Timestamp = hexutil.Uint64(parentBlock.Time() + uint64(b.schedule.delay(parentBlock.NumberU64()+1)/time.Second))
+	}
+	if uint64(attrs.Timestamp) <= parentBlock.Time() {
+		return fmt.Errorf("parent block %s not older than the slot", parent)
+	}
+
+	key := prebuiltKey{slot: attrs.Slot, parent: parent, timestamp: uint64(attrs.Timestamp)}
+	ctx, cancel := context.WithTimeout(context.Background(), slotJobTimeout)
+	go func() {
+		defer cancel()
+		blockHook := func(block *types.Block, blockValue *big.Int, ordersCloseTime time.Time,
+			committedBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle, round uint64,
+		) {
+			if ctx.Err() != nil {
+				return
+			}
+			b.prebuilt.add(key, blockQueueEntry{
+				block:           block,
+				blockValue:      new(big.Int).Set(blockValue),
+				ordersCloseTime: ordersCloseTime,
+				sealedAt:        time.Now(),
+				commitedBundles: committedBundles,
+				allBundles:      allBundles,
+				usedSbundles:    usedSbundles,
+				round:           round,
+			})
+		}
+		log.Debug("pre-building block", "slot", attrs.Slot, "parent", parent, "number", parentBlock.NumberU64()+1)
+		if err := b.eth.BuildBlock(&attrs, blockHook); err != nil {
+			log.Warn("Failed to pre-build block", "slot", attrs.Slot, "parent", parent, "err", err)
+		}
+	}()
+	return nil
+}
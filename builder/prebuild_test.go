@@ -0,0 +1,43 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrebuiltCandidates(t *testing.T) {
+	candidate := func(number, value int64) blockQueueEntry {
+		return blockQueueEntry{
+			block:      types.NewBlockWithHeader(&types.Header{Number: big.NewInt(number)}),
+			blockValue: big.NewInt(value),
+		}
+	}
+	c := newPrebuiltCandidates()
+	key := prebuiltKey{slot: 1, parent: common.Hash{0x1}, timestamp: 12}
+
+	// the most valuable candidate of a job is kept
+	c.add(key, candidate(1, 10))
+	c.add(key, candidate(2, 5))
+	c.add(key, candidate(3, 20))
+	_, ok := c.take(prebuiltKey{slot: 1, parent: common.Hash{0x2}, timestamp: 12})
+	require.False(t, ok)
+	entry, ok := c.take(key)
+	require.True(t, ok)
+	require.Equal(t, int64(20), entry.blockValue.Int64())
+	_, ok = c.take(key)
+	require.False(t, ok)
+
+	// the oldest candidates are evicted
+	for slot := uint64(0); slot <= maxPrebuiltCandidates; slot++ {
+		c.add(prebuiltKey{slot: slot}, candidate(int64(slot), 1))
+	}
+	_, ok = c.take(prebuiltKey{slot: 0})
+	require.False(t, ok)
+	_, ok = c.take(prebuiltKey{slot: maxPrebuiltCandidates})
+	require.True(t, ok)
+	require.Len(t, c.order, maxPrebuiltCandidates-1)
+}
@@ -0,0 +1,201 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/builder/heimdall"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxCachedSpans is the number of Heimdall spans kept
+const maxCachedSpans = 4
+
+// ScheduledProducer is the block producer in turn for a Bor sprint.
+type ScheduledProducer struct {
+	Span       uint64         `json:"span"`
+	StartBlock uint64         `json:"startBlock"`
+	EndBlock   uint64         `json:"endBlock"`
+	Producer   common.Address `json:"producer"`
+	Ours       bool           `json:"ours"` // The producer is our validator or a connected proposer
+}
+
+// producerSchedule follows the Heimdall spans to tell which validator produces each Bor sprint,
+// so that the builder only builds when one of its producers is in turn. A nil instance builds
+// every slot.
+type producerSchedule struct {
+	client      *heimdall.Client
+	sprint      uint64
+	producers   map[common.Address]struct{} // Our validator and the connected proposers
+	alwaysBuild bool
+
+	mu    sync.Mutex
+	spans []*heimdall.Span // Recently used spans, oldest first
+}
+
+// newProducerSchedule returns the producer schedule of the config, nil unless the Heimdall
+// client and the Bor sprint are known.
+func newProducerSchedule(cfg *Config, client *heimdall.Client) (*producerSchedule, error) {
+	if client == nil || cfg.BorSprint == 0 {
+		return nil, nil
+	}
+	schedule := &producerSchedule{
+		client:      client,
+		sprint:      cfg.BorSprint,
+		producers:   make(map[common.Address]struct{}),
+		alwaysBuild: cfg.AlwaysBuild,
+	}
+	for _, producer := range cfg.Producers {
+		if producer == "" {
+			continue
+		}
+		if !common.IsHexAddress(producer) {
+			return nil, fmt.Errorf("invalid producer address %s", producer)
+		}
+		schedule.producers[common.HexToAddress(producer)] = struct{}{}
+	}
+	if len(schedule.producers) == 0 && !schedule.alwaysBuild {
+		log.Warn("No producer configured, building every slot")
+		schedule.alwaysBuild = true
+	}
+	return schedule, nil
+}
+
+// shouldBuild reports whether the block is worth building, because one of our producers is in
+// turn or the builder always builds. The block is built if its producer cannot be known.
+func (s *producerSchedule) shouldBuild(number uint64) bool {
+	if s == nil || s.alwaysBuild {
+		return true
+	}
+	producer, err := s.producer(number)
+	if err != nil {
+		log.Warn("Could not resolve the block producer, building", "number", number, "err", err)
+		return true
+	}
+	return producer.Ours
+}
+
+// producer returns the producer in turn for the sprint of the block.
+func (s *producerSchedule) producer(number uint64) (*ScheduledProducer, error) {
+	span, err := s.span(number)
+	if err != nil {
+		return nil, err
+	}
+	return s.sprintProducer(span, number)
+}
+
+// schedule returns the producers of the sprints from the one of the block to the end of its span.
+func (s *producerSchedule) schedule(number uint64) ([]ScheduledProducer, error) {
+	span, err := s.span(number)
+	if err != nil {
+		return nil, err
+	}
+	var producers []ScheduledProducer
+	for start := number - (number-span.StartBlock)%s.sprint; start <= span.EndBlock; start += s.sprint {
+		producer, err := s.sprintProducer(span, start)
+		if err != nil {
+			return nil, err
+		}
+		producers = append(producers, *producer)
+	}
+	return producers, nil
+}
+
+// sprintProducer returns the producer of the sprint of the block in the span. Bor elects the
+// producer of each sprint by proposer priority among the selected producers of the span.
+func (s *producerSchedule) sprintProducer(span *heimdall.Span, number uint64) (*ScheduledProducer, error) {
+	if len(span.SelectedProducers) == 0 {
+		return nil, fmt.Errorf("span %d has no selected producers", span.ID)
+	}
+	sprint := (number - span.StartBlock) / s.sprint
+	producer := electProducer(span.SelectedProducers, sprint+1)
+
+	start := span.StartBlock + sprint*s.sprint
+	end := start + s.sprint - 1
+	if end > span.EndBlock {
+		end = span.EndBlock
+	}
+	_, ours := s.producers[producer]
+	return &ScheduledProducer{Span: span.ID, StartBlock: start, EndBlock: end, Producer: producer, Ours: ours}, nil
+}
+
+// electProducer returns the proposer of the validators after rounds of proposer priority
+// increments: every round the validators gain their power and the one with the highest
+// priority, the lowest address on ties, is elected and pays the total power.
+func electProducer(validators []heimdall.Validator, rounds uint64) common.Address {
+	var (
+		priorities = make([]int64, len(validators))
+		total      int64
+	)
+	for i, validator := range validators {
+		priorities[i] = validator.Priority
+		total += validator.Power
+	}
+	var elected int
+	for round := uint64(0); round < rounds; round++ {
+		elected = 0
+		for i, validator := range validators {
+			priorities[i] += validator.Power
+			if priorities[i] > priorities[elected] ||
+				(priorities[i] == priorities[elected] && bytes.Compare(validator.Signer[:], validators[elected].Signer[:]) < 0) {
+				elected = i
+			}
+		}
+		priorities[elected] -= total
+	}
+	return validators[elected].Signer
+}
+
+// span returns the Heimdall span of the block, following the spans back from the latest one.
+func (s *producerSchedule) span(number uint64) (*heimdall.Span, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, span := range s.spans {
+		if span.StartBlock <= number && number <= span.EndBlock {
+			return span, nil
+		}
+	}
+	ctx := context.Background()
+	span, err := s.client.LatestSpan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for number < span.StartBlock && span.ID > 0 {
+		if span, err = s.client.Span(ctx, span.ID-1); err != nil {
+			return nil, err
+		}
+	}
+	if number < span.StartBlock || number > span.EndBlock {
+		return nil, fmt.Errorf("no span of block %d", number)
+	}
+	s.spans = append(s.spans, span)
+	if len(s.spans) > maxCachedSpans {
+		s.spans = s.spans[len(s.spans)-maxCachedSpans:]
+	}
+	return span, nil
+}
+
+// ProducerSchedule returns the producers in turn for the sprints from the one of the block, the
+// block of the current slot if nil, to the end of its span.
+func (b *Builder) ProducerSchedule(number *uint64) ([]ScheduledProducer, error) {
+	if b.producers == nil {
+		return nil, errors.New("producer schedule not enabled")
+	}
+	if number == nil {
+		b.slotMu.Lock()
+		head := b.slotAttrs.HeadHash
+		b.slotMu.Unlock()
+		parent := b.eth.GetBlockByHash(head)
+		if parent == nil {
+			return nil, errors.New("no block being built, block number required")
+		}
+		next := parent.NumberU64() + 1
+		number = &next
+	}
+	return b.producers.schedule(*number)
+}
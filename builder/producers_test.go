@@ -0,0 +1,70 @@
+package builder
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/builder/heimdall"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProducerSchedule(t *testing.T) {
+	var (
+		a, b, c    = common.Address{0xa}, common.Address{0xb}, common.Address{0xc}
+		validators = []heimdall.Validator{{Signer: c, Power: 1}, {Signer: a, Power: 1}, {Signer: b, Power: 1}}
+		spans      = map[string]*heimdall.Span{
+			"/bor/span/1":      {ID: 1, StartBlock: 256, EndBlock: 319, SelectedProducers: validators},
+			"/bor/latest-span": {ID: 2, StartBlock: 320, EndBlock: 383, SelectedProducers: []heimdall.Validator{{Signer: c, Power: 1}}},
+		}
+		requests int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		span, ok := spans[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"height": "1", "result": span})
+	}))
+	defer srv.Close()
+
+	client, err := heimdall.New(heimdall.Config{Endpoints: []string{srv.URL}})
+	require.NoError(t, err)
+	cfg := DefaultConfig
+	cfg.BorSprint, cfg.Producers = 16, []string{b.Hex()}
+	schedule, err := newProducerSchedule(&cfg, client)
+	require.NoError(t, err)
+
+	// the producers of equal power take turns every sprint, from the lowest address
+	producers, err := schedule.schedule(260)
+	require.NoError(t, err)
+	require.Equal(t, []ScheduledProducer{
+		{Span: 1, StartBlock: 256, EndBlock: 271, Producer: a},
+		{Span: 1, StartBlock: 272, EndBlock: 287, Producer: b, Ours: true},
+		{Span: 1, StartBlock: 288, EndBlock: 303, Producer: c},
+		{Span: 1, StartBlock: 304, EndBlock: 319, Producer: a},
+	}, producers)
+	require.Equal(t, 2, requests)
+
+	// only the sprints of our producers are built, the spans are cached
+	require.False(t, schedule.shouldBuild(270))
+	require.True(t, schedule.shouldBuild(280))
+	require.False(t, schedule.shouldBuild(330))
+	require.Equal(t, 3, requests)
+
+	// the blocks of unknown producers are built
+	require.True(t, schedule.shouldBuild(400))
+
+	cfg.AlwaysBuild = true
+	schedule, err = newProducerSchedule(&cfg, client)
+	require.NoError(t, err)
+	require.True(t, schedule.shouldBuild(270))
+
+	cfg.Producers = []string{"producer"}
+	_, err = newProducerSchedule(&cfg, client)
+	require.Error(t, err)
+}
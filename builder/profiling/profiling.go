@@ -0,0 +1,212 @@
+// Package profiling labels the builder pipeline stages for the Go profiler and exposes
+// operator endpoints capturing runtime profiles.
+//
+// The work of every stage runs with the pprof label StageLabel set to the stage name, so CPU
+// and goroutine profiles can be narrowed to a stage, e.g. with
+// go tool pprof -tagfocus=builder_stage=simulate.
+package profiling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// StageLabel is the pprof label holding the builder stage of the profiled goroutine
+const StageLabel = "builder_stage"
+
+// Builder pipeline stages
+const (
+	StageSimulate = "simulate" // Bundle and sbundle simulation
+	StagePack     = "pack"     // Block building algorithms
+	StageFinalize = "finalize" // Block finalization
+	StageSubmit   = "submit"   // Relay submission
+)
+
+// maxProfileDuration bounds the sampling period of the cpu, block and mutex profiles
+const maxProfileDuration = 5 * time.Minute
+
+var (
+	ErrInvalidProfileName = errors.New("invalid profile name, expected a file name without directory")
+	ErrProfileDuration    = fmt.Errorf("profile duration must be between 1 and %d seconds", int(maxProfileDuration/time.Second))
+	ErrProfileInProgress  = errors.New("profile already in progress")
+)
+
+// Do runs f with the pprof stage label set, goroutines started by f inherit the label.
+func Do(stage string, f func()) {
+	pprof.Do(context.Background(), pprof.Labels(StageLabel, stage), func(context.Context) {
+		f()
+	})
+}
+
+// API captures runtime profiles into a directory, exposed in the builder RPC namespace.
+type API struct {
+	dir string
+
+	mu        sync.Mutex
+	blockRate int  // Block profile rate last set through the API, runtime offers no getter
+	sampling  bool // Whether a timed profile is in progress
+}
+
+// NewAPI creates the profiling API writing profiles to dir.
+func NewAPI(dir string) *API {
+	return &API{dir: dir}
+}
+
+// path returns the path of the profile with the given name, creating the profile directory.
+func (api *API) path(name string) (string, error) {
+	if name == "" || name == "." || name == ".." || filepath.Base(name) != name {
+		return "", ErrInvalidProfileName
+	}
+	if err := os.MkdirAll(api.dir, 0700); err != nil {
+		return "", err
+	}
+	return filepath.Join(api.dir, name), nil
+}
+
+// startSampling reserves the timed profile slot, only one timed profile runs at a time.
+func (api *API) startSampling(nsec uint) error {
+	if nsec == 0 || time.Duration(nsec)*time.Second > maxProfileDuration {
+		return ErrProfileDuration
+	}
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.sampling {
+		return ErrProfileInProgress
+	}
+	api.sampling = true
+	return nil
+}
+
+func (api *API) stopSampling() {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	api.sampling = false
+}
+
+// CpuProfile records a CPU profile for nsec seconds into the profile directory and returns its path.
+// Samples carry the builder stage label.
+func (api *API) CpuProfile(name string, nsec uint) (string, error) {
+	path, err := api.path(name)
+	if err != nil {
+		return "", err
+	}
+	if err := api.startSampling(nsec); err != nil {
+		return "", err
+	}
+	defer api.stopSampling()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := pprof.StartCPUProfile(f); err != nil {
+		return "", err
+	}
+	log.Info("Builder CPU profiling started", "dump", path, "duration", time.Duration(nsec)*time.Second)
+	time.Sleep(time.Duration(nsec) * time.Second)
+	pprof.StopCPUProfile()
+	log.Info("Done writing builder CPU profile", "dump", path)
+	return path, nil
+}
+
+// BlockProfile records the goroutine blocking events of nsec seconds into the profile directory,
+// and returns its path. The block profile rate is set to 1 for the period and restored afterwards.
+func (api *API) BlockProfile(name string, nsec uint) (string, error) {
+	path, err := api.path(name)
+	if err != nil {
+		return "", err
+	}
+	if err := api.startSampling(nsec); err != nil {
+		return "", err
+	}
+	defer api.stopSampling()
+
+	runtime.SetBlockProfileRate(1)
+	time.Sleep(time.Duration(nsec) * time.Second)
+
+	api.mu.Lock()
+	runtime.SetBlockProfileRate(api.blockRate)
+	api.mu.Unlock()
+	return path, writeProfile("block", path)
+}
+
+// MutexProfile records the mutex contention of nsec seconds into the profile directory, and
+// returns its path. The mutex profile fraction is set to 1 for the period and restored afterwards.
+func (api *API) MutexProfile(name string, nsec uint) (string, error) {
+	path, err := api.path(name)
+	if err != nil {
+		return "", err
+	}
+	if err := api.startSampling(nsec); err != nil {
+		return "", err
+	}
+	defer api.stopSampling()
+
+	previous := runtime.SetMutexProfileFraction(1)
+	time.Sleep(time.Duration(nsec) * time.Second)
+	runtime.SetMutexProfileFraction(previous)
+	return path, writeProfile("mutex", path)
+}
+
+// HeapProfile writes a heap profile into the profile directory and returns its path.
+func (api *API) HeapProfile(name string) (string, error) {
+	path, err := api.path(name)
+	if err != nil {
+		return "", err
+	}
+	runtime.GC()
+	return path, writeProfile("heap", path)
+}
+
+// GoroutineProfile writes the stacks of all goroutines, with their builder stage labels, into the
+// profile directory and returns its path.
+func (api *API) GoroutineProfile(name string) (string, error) {
+	path, err := api.path(name)
+	if err != nil {
+		return "", err
+	}
+	return path, writeProfile("goroutine", path)
+}
+
+// SetBlockProfileRate sets the rate of goroutine block profile data collection, 0 disables
+// block profiling.
+func (api *API) SetBlockProfileRate(rate int) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	api.blockRate = rate
+	runtime.SetBlockProfileRate(rate)
+	log.Info("Block profile rate updated", "rate", rate)
+}
+
+// SetMutexProfileFraction sets the rate of mutex profiling, 0 disables mutex profiling. It
+// returns the previous rate.
+func (api *API) SetMutexProfileFraction(rate int) int {
+	previous := runtime.SetMutexProfileFraction(rate)
+	log.Info("Mutex profile fraction updated", "rate", rate, "previous", previous)
+	return previous
+}
+
+func writeProfile(name, path string) error {
+	p := pprof.Lookup(name)
+	log.Info("Writing builder profile", "type", name, "count", p.Count(), "dump", path)
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return p.WriteTo(f, 0)
+}
@@ -0,0 +1,45 @@
+package profiling
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfilePath(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+	api := NewAPI(dir)
+
+	for _, name := range []string{"", ".", "..", "../heap.prof", "/tmp/heap.prof", "sub/heap.prof"} {
+		_, err := api.path(name)
+		require.ErrorIs(t, err, ErrInvalidProfileName, name)
+	}
+
+	path, err := api.HeapProfile("heap.prof")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "heap.prof"), path)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NotZero(t, info.Size())
+}
+
+func TestProfileSampling(t *testing.T) {
+	api := NewAPI(t.TempDir())
+
+	_, err := api.CpuProfile("cpu.prof", 0)
+	require.ErrorIs(t, err, ErrProfileDuration)
+	_, err = api.MutexProfile("mutex.prof", 3600)
+	require.ErrorIs(t, err, ErrProfileDuration)
+
+	// only one timed profile runs at a time
+	require.NoError(t, api.startSampling(1))
+	_, err = api.BlockProfile("block.prof", 1)
+	require.ErrorIs(t, err, ErrProfileInProgress)
+	api.stopSampling()
+
+	path, err := api.BlockProfile("block.prof", 1)
+	require.NoError(t, err)
+	require.FileExists(t, path)
+}
@@ -0,0 +1,33 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/miner"
+)
+
+// profitBreakdownSource is the miner keeping the profit breakdown of the built blocks.
+type profitBreakdownSource interface {
+	BlockProfitBreakdown(hash common.Hash) *miner.BlockProfitBreakdown
+}
+
+// profitBreakdownAPI serves the attribution of the profit of the built blocks to their transactions
+// and orders from the authenticated builder API, to tell which orderflow paid for a block.
+type profitBreakdownAPI struct {
+	miner profitBreakdownSource
+}
+
+func newProfitBreakdownAPI(miner profitBreakdownSource) *profitBreakdownAPI {
+	return &profitBreakdownAPI{miner: miner}
+}
+
+// GetBlockProfitBreakdown returns the coinbase balance change of every transaction of a recent
+// block built by the node, and its aggregate by bundle, sbundle, mempool and builder transactions.
+func (api *profitBreakdownAPI) GetBlockProfitBreakdown(hash common.Hash) (*miner.BlockProfitBreakdown, error) {
+	breakdown := api.miner.BlockProfitBreakdown(hash)
+	if breakdown == nil {
+		return nil, fmt.Errorf("no recent block built with hash %s", hash)
+	}
+	return breakdown, nil
+}
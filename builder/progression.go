@@ -0,0 +1,138 @@
+package builder
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// maxProgressionSlots is the number of slots the candidate profit progression is kept for
+	maxProgressionSlots = 64
+	// maxProgressionCandidates is the number of candidates kept per slot, later candidates are
+	// only kept if they improve the best value
+	maxProgressionCandidates = 1024
+)
+
+// CandidateProfit is a candidate block built within a slot.
+type CandidateProfit struct {
+	Time      time.Time    `json:"time"`
+	BlockHash common.Hash  `json:"blockHash"`
+	Value     *hexutil.Big `json:"value"`
+	// Improved is true if the candidate is worth more than all the previous candidates of the slot
+	Improved bool `json:"improved"`
+}
+
+// SlotProfitProgression is the value of the successive candidate blocks built for a slot.
+type SlotProfitProgression struct {
+	Slot     uint64    `json:"slot"`
+	SlotTime time.Time `json:"slotTime"`
+
+	Candidates []CandidateProfit `json:"candidates"`
+	// Dropped is the number of candidates not kept once the slot reached maxProgressionCandidates
+	Dropped int `json:"dropped,omitempty"`
+
+	FirstValue *hexutil.Big `json:"firstValue"`
+	BestValue  *hexutil.Big `json:"bestValue"`
+	// BestAfter is the time between the first candidate and the best one, packing time after it
+	// did not increase the block value
+	BestAfter time.Duration `json:"bestAfter"`
+}
+
+var (
+	progressionCandidatesHistogram = metrics.NewRegisteredHistogram("builder/slot/candidates", nil, metrics.NewExpDecaySample(1028, 0.015))
+	progressionGainHistogram       = metrics.NewRegisteredHistogram("builder/slot/profit_gain", nil, metrics.NewExpDecaySample(1028, 0.015))
+	progressionBestAfterTimer      = metrics.NewRegisteredTimer("builder/slot/best_after", nil)
+)
+
+// profitProgression records the candidate blocks of the recent slots.
+type profitProgression struct {
+	mu    sync.Mutex
+	slots []*SlotProfitProgression // Oldest first
+}
+
+func newProfitProgression() *profitProgression {
+	return &profitProgression{}
+}
+
+// candidate records a candidate block built for the slot.
+func (p *profitProgression) candidate(slot uint64, slotTime time.Time, blockHash common.Hash, value *big.Int) {
+	now := time.Now()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var progression *SlotProfitProgression
+	if n := len(p.slots); n > 0 {
+		progression = p.slots[n-1]
+		if slot < progression.Slot {
+			return
+		}
+	}
+	if progression == nil || slot > progression.Slot {
+		if progression != nil {
+			markProfitProgression(progression)
+		}
+		progression = &SlotProfitProgression{
+			Slot:       slot,
+			SlotTime:   slotTime,
+			Candidates: make([]CandidateProfit, 0),
+			FirstValue: (*hexutil.Big)(new(big.Int).Set(value)),
+		}
+		p.slots = append(p.slots, progression)
+		if len(p.slots) > maxProgressionSlots {
+			p.slots = p.slots[len(p.slots)-maxProgressionSlots:]
+		}
+	}
+
+	improved := progression.BestValue == nil || value.Cmp(progression.BestValue.ToInt()) > 0
+	if improved {
+		progression.BestValue = (*hexutil.Big)(new(big.Int).Set(value))
+		if len(progression.Candidates) > 0 {
+			progression.BestAfter = now.Sub(progression.Candidates[0].Time)
+		}
+	}
+	if len(progression.Candidates) >= maxProgressionCandidates && !improved {
+		progression.Dropped++
+		return
+	}
+	progression.Candidates = append(progression.Candidates, CandidateProfit{
+		Time:      now,
+		BlockHash: blockHash,
+		Value:     (*hexutil.Big)(new(big.Int).Set(value)),
+		Improved:  improved,
+	})
+}
+
+// markProfitProgression updates the progression metrics of a slot that is done building
+func markProfitProgression(progression *SlotProfitProgression) {
+	if !metrics.EnabledBuilder {
+		return
+	}
+	progressionCandidatesHistogram.Update(int64(len(progression.Candidates) + progression.Dropped))
+	gain := new(big.Int).Sub(progression.BestValue.ToInt(), progression.FirstValue.ToInt())
+	progressionGainHistogram.Update(weiToGwei(gain))
+	progressionBestAfterTimer.Update(progression.BestAfter)
+}
+
+// progressions returns the profit progression of the given slot, or of the recent slots most
+// recent first if slot is nil.
+func (p *profitProgression) progressions(slot *uint64) []SlotProfitProgression {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	progressions := make([]SlotProfitProgression, 0, len(p.slots))
+	for i := len(p.slots) - 1; i >= 0; i-- {
+		progression := *p.slots[i]
+		if slot != nil && progression.Slot != *slot {
+			continue
+		}
+		progression.Candidates = append([]CandidateProfit(nil), progression.Candidates...)
+		progressions = append(progressions, progression)
+	}
+	return progressions
+}
@@ -0,0 +1,51 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfitProgression(t *testing.T) {
+	progression := newProfitProgression()
+	slotTime := time.Now()
+
+	progression.candidate(1, slotTime, common.Hash{1}, big.NewInt(10))
+	progression.candidate(1, slotTime, common.Hash{2}, big.NewInt(30))
+	progression.candidate(1, slotTime, common.Hash{3}, big.NewInt(20))
+	progression.candidate(2, slotTime.Add(12*time.Second), common.Hash{4}, big.NewInt(5))
+	// candidates of past slots are ignored
+	progression.candidate(1, slotTime, common.Hash{5}, big.NewInt(100))
+
+	slots := progression.progressions(nil)
+	require.Len(t, slots, 2)
+	require.Equal(t, uint64(2), slots[0].Slot)
+
+	slot := slots[1]
+	require.Equal(t, uint64(1), slot.Slot)
+	require.Len(t, slot.Candidates, 3)
+	require.True(t, slot.Candidates[0].Improved)
+	require.True(t, slot.Candidates[1].Improved)
+	require.False(t, slot.Candidates[2].Improved)
+	require.Equal(t, int64(10), slot.FirstValue.ToInt().Int64())
+	require.Equal(t, int64(30), slot.BestValue.ToInt().Int64())
+	require.Equal(t, slot.Candidates[1].Time.Sub(slot.Candidates[0].Time), slot.BestAfter)
+
+	one := uint64(1)
+	require.Equal(t, []SlotProfitProgression{slot}, progression.progressions(&one))
+
+	// past the candidate limit only improvements are kept
+	for i := 0; i < maxProgressionCandidates; i++ {
+		progression.candidate(3, slotTime, common.Hash{}, big.NewInt(1))
+	}
+	progression.candidate(3, slotTime, common.Hash{6}, big.NewInt(1))
+	progression.candidate(3, slotTime, common.Hash{7}, big.NewInt(2))
+	three := uint64(3)
+	slot = progression.progressions(&three)[0]
+	require.Len(t, slot.Candidates, maxProgressionCandidates+1)
+	require.Equal(t, 1, slot.Dropped)
+	require.Equal(t, common.Hash{7}, slot.Candidates[maxProgressionCandidates].BlockHash)
+}
@@ -2,15 +2,18 @@ package builder
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/attestantio/go-builder-client/api/bellatrix"
 	"github.com/attestantio/go-builder-client/api/capella"
+	"github.com/ethereum/go-ethereum/internal/faultinject"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/flashbots/go-boost-utils/utils"
 )
@@ -22,8 +25,9 @@ type RemoteRelay struct {
 	config RelayConfig
 
 	localRelay *LocalRelay
+	metrics    *relayMetrics
 
-	cancellationsEnabled bool
+	policy atomic.Pointer[relayPolicy]
 
 	validatorsLock       sync.RWMutex
 	validatorSyncOngoing bool
@@ -32,15 +36,19 @@ type RemoteRelay struct {
 }
 
 func NewRemoteRelay(config RelayConfig, localRelay *LocalRelay, cancellationsEnabled bool) *RemoteRelay {
+	if config.SubmissionTimeout <= 0 {
+		config.SubmissionTimeout = DefaultConfig.RelaySubmissionTimeout
+	}
 	r := &RemoteRelay{
 		client:               http.Client{Timeout: time.Second},
 		localRelay:           localRelay,
-		cancellationsEnabled: cancellationsEnabled,
+		metrics:              newRelayMetrics(config.Endpoint),
 		validatorSyncOngoing: false,
 		lastRequestedSlot:    0,
 		validatorSlotMap:     make(map[uint64]ValidatorData),
 		config:               config,
 	}
+	r.policy.Store(&relayPolicy{cancellations: cancellationsEnabled})
 
 	err := r.updateValidatorsMap(0, 3)
 	if err != nil {
@@ -136,16 +144,23 @@ func (r *RemoteRelay) Stop() {}
 
 func (r *RemoteRelay) SubmitBlock(msg *bellatrix.SubmitBlockRequest, _ ValidatorData) error {
 	log.Info("submitting block to remote relay", "endpoint", r.config.Endpoint)
+	if faultinject.Trigger(faultinject.RelayTimeout) {
+		return fmt.Errorf("error sending http request to relay %s. err: %w", r.config.Endpoint, faultinject.ErrTimeout)
+	}
+	policy := r.policy.Load()
+	if !policy.allow() {
+		return errRelayRateLimited
+	}
 	endpoint := r.config.Endpoint + "/relay/v1/builder/blocks"
-	if r.cancellationsEnabled {
+	if policy.cancellations {
 		endpoint = endpoint + "?cancellations=true"
 	}
-	code, err := SendHTTPRequest(context.TODO(), *http.DefaultClient, http.MethodPost, endpoint, msg, nil)
+	body, err := json.Marshal(msg)
 	if err != nil {
-		return fmt.Errorf("error sending http request to relay %s. err: %w", r.config.Endpoint, err)
+		return fmt.Errorf("could not marshal request: %w", err)
 	}
-	if code > 299 {
-		return fmt.Errorf("non-ok response code %d from relay %s", code, r.config.Endpoint)
+	if err := r.submitPayload(endpoint, body, jsonContentType); err != nil {
+		return err
 	}
 
 	if r.localRelay != nil {
@@ -157,33 +172,35 @@ func (r *RemoteRelay) SubmitBlock(msg *bellatrix.SubmitBlockRequest, _ Validator
 
 func (r *RemoteRelay) SubmitBlockCapella(msg *capella.SubmitBlockRequest, _ ValidatorData) error {
 	log.Info("submitting block to remote relay", "endpoint", r.config.Endpoint)
+	if faultinject.Trigger(faultinject.RelayTimeout) {
+		return fmt.Errorf("error sending http request to relay %s. err: %w", r.config.Endpoint, faultinject.ErrTimeout)
+	}
+	policy := r.policy.Load()
+	if !policy.allow() {
+		return errRelayRateLimited
+	}
 
 	endpoint := r.config.Endpoint + "/relay/v1/builder/blocks"
-	if r.cancellationsEnabled {
+	if policy.cancellations {
 		endpoint = endpoint + "?cancellations=true"
 	}
 
+	var (
+		body        []byte
+		contentType = jsonContentType
+		err         error
+	)
 	if r.config.SszEnabled {
-		bodyBytes, err := msg.MarshalSSZ()
+		body, err = msg.MarshalSSZ()
 		if err != nil {
 			return fmt.Errorf("error marshaling ssz: %w", err)
 		}
-		log.Debug("submitting block to remote relay", "endpoint", r.config.Endpoint)
-		code, err := SendSSZRequest(context.TODO(), *http.DefaultClient, http.MethodPost, endpoint, bodyBytes, r.config.GzipEnabled)
-		if err != nil {
-			return fmt.Errorf("error sending http request to relay %s. err: %w", r.config.Endpoint, err)
-		}
-		if code > 299 {
-			return fmt.Errorf("non-ok response code %d from relay %s", code, r.config.Endpoint)
-		}
-	} else {
-		code, err := SendHTTPRequest(context.TODO(), *http.DefaultClient, http.MethodPost, endpoint, msg, nil)
-		if err != nil {
-			return fmt.Errorf("error sending http request to relay %s. err: %w", r.config.Endpoint, err)
-		}
-		if code > 299 {
-			return fmt.Errorf("non-ok response code %d from relay %s", code, r.config.Endpoint)
-		}
+		contentType = sszContentType
+	} else if body, err = json.Marshal(msg); err != nil {
+		return fmt.Errorf("could not marshal request: %w", err)
+	}
+	if err := r.submitPayload(endpoint, body, contentType); err != nil {
+		return err
 	}
 
 	if r.localRelay != nil {
@@ -195,6 +212,9 @@ func (r *RemoteRelay) SubmitBlockCapella(msg *capella.SubmitBlockRequest, _ Vali
 
 func (r *RemoteRelay) getSlotValidatorMapFromRelay() (map[uint64]ValidatorData, error) {
 	var dst GetValidatorRelayResponse
+	if faultinject.Trigger(faultinject.RelayTimeout) {
+		return nil, faultinject.ErrTimeout
+	}
 	code, err := SendHTTPRequest(context.TODO(), *http.DefaultClient, http.MethodGet, r.config.Endpoint+"/relay/v1/builder/validators", nil, &dst)
 	if err != nil {
 		return nil, err
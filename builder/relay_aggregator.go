@@ -7,6 +7,7 @@ import (
 
 	"github.com/attestantio/go-builder-client/api/bellatrix"
 	"github.com/attestantio/go-builder-client/api/capella"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -42,26 +43,16 @@ func (r *RemoteRelayAggregator) Stop() {
 }
 
 func (r *RemoteRelayAggregator) SubmitBlock(msg *bellatrix.SubmitBlockRequest, registration ValidatorData) error {
-	r.registrationsCacheLock.RLock()
-	defer r.registrationsCacheLock.RUnlock()
-
-	relays, found := r.registrationsCache[registration]
-	if !found {
-		return fmt.Errorf("no relays for registration %s", registration.Pubkey)
-	}
-	for _, relay := range relays {
-		go func(relay IRelay) {
-			err := relay.SubmitBlock(msg, registration)
-			if err != nil {
-				log.Error("could not submit block", "err", err)
-			}
-		}(relay)
-	}
-
-	return nil
+	return r.submit(registration, nil, func(relay IRelay) error { return relay.SubmitBlock(msg, registration) })
 }
 
 func (r *RemoteRelayAggregator) SubmitBlockCapella(msg *capella.SubmitBlockRequest, registration ValidatorData) error {
+	return r.submit(registration, nil, func(relay IRelay) error { return relay.SubmitBlockCapella(msg, registration) })
+}
+
+// submit fans a block out to the relays the registration was received from, except the relays
+// excluding the searchers of the block bundles.
+func (r *RemoteRelayAggregator) submit(registration ValidatorData, searchers []common.Address, submit func(IRelay) error) error {
 	r.registrationsCacheLock.RLock()
 	defer r.registrationsCacheLock.RUnlock()
 
@@ -70,9 +61,15 @@ func (r *RemoteRelayAggregator) SubmitBlockCapella(msg *capella.SubmitBlockReque
 		return fmt.Errorf("no relays for registration %s", registration.Pubkey)
 	}
 	for _, relay := range relays {
+		if filter, ok := relay.(searcherFilter); ok && filter.excludesSearchers(searchers) {
+			log.Debug("not submitting block with bundles of excluded searchers", "endpoint", relay.Config().Endpoint)
+			continue
+		}
 		go func(relay IRelay) {
-			err := relay.SubmitBlockCapella(msg, registration)
-			if err != nil {
+			err := submit(relay)
+			if errors.Is(err, errRelayRateLimited) {
+				log.Debug("not submitting block over the relay rate limit", "endpoint", relay.Config().Endpoint)
+			} else if err != nil {
 				log.Error("could not submit block", "err", err)
 			}
 		}(relay)
@@ -0,0 +1,133 @@
+package builder
+
+import (
+	"errors"
+	"time"
+
+	bellatrixapi "github.com/attestantio/go-builder-client/api/bellatrix"
+	capellaapi "github.com/attestantio/go-builder-client/api/capella"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"golang.org/x/time/rate"
+)
+
+// errRelayRateLimited is returned by the submissions over the rate limit of a relay.
+var errRelayRateLimited = errors.New("relay submission rate limited")
+
+// RelayPolicy is the submission settings of one of the relays the blocks are fanned out to.
+type RelayPolicy struct {
+	Endpoint          string           // Relay endpoint, URL;ssz=<bool>;gzip=<bool>
+	RateLimit         time.Duration    // Minimum time between the block submissions to the relay, 0 for no limit
+	RateLimitBurst    int              // Submissions allowed in a burst over the rate limit
+	Cancellations     *bool            // Enable cancellations with the relay, nil for Config.EnableCancellations
+	ExcludedSearchers []common.Address // Searchers whose bundles are never sent to the relay
+}
+
+// relayPolicy is the policy of a running relay.
+type relayPolicy struct {
+	limiter       *rate.Limiter // nil if the submissions are not rate limited
+	cancellations bool
+	excluded      map[common.Address]struct{}
+}
+
+// relayPolicyFor returns the policy of the relay at endpoint, the relay URL without its options.
+func relayPolicyFor(cfg *Config, endpoint string) *relayPolicy {
+	policy := &relayPolicy{cancellations: cfg.EnableCancellations}
+	for _, p := range cfg.RelayPolicies {
+		if relayConfig, err := getRelayConfig(p.Endpoint); err != nil || relayConfig.Endpoint != endpoint {
+			continue
+		}
+		if p.Cancellations != nil {
+			policy.cancellations = *p.Cancellations
+		}
+		if p.RateLimit > 0 {
+			burst := p.RateLimitBurst
+			if burst < 1 {
+				burst = 1
+			}
+			policy.limiter = rate.NewLimiter(rate.Every(p.RateLimit), burst)
+		}
+		if len(p.ExcludedSearchers) > 0 {
+			policy.excluded = make(map[common.Address]struct{}, len(p.ExcludedSearchers))
+			for _, searcher := range p.ExcludedSearchers {
+				policy.excluded[searcher] = struct{}{}
+			}
+		}
+		break
+	}
+	return policy
+}
+
+// allow reports whether a submission is within the rate limit.
+func (p *relayPolicy) allow() bool {
+	return p.limiter == nil || p.limiter.Allow()
+}
+
+// excludes reports whether a block with bundles of the searchers must not be sent to the relay.
+func (p *relayPolicy) excludes(searchers []common.Address) bool {
+	for _, searcher := range searchers {
+		if _, ok := p.excluded[searcher]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// searcherFilter is implemented by the relays excluding the blocks with bundles of some searchers.
+type searcherFilter interface {
+	excludesSearchers(searchers []common.Address) bool
+}
+
+// SetPolicy replaces the submission policy of the relay, the running submissions keep the previous one.
+func (r *RemoteRelay) SetPolicy(cfg *Config) {
+	r.policy.Store(relayPolicyFor(cfg, r.config.Endpoint))
+}
+
+func (r *RemoteRelay) excludesSearchers(searchers []common.Address) bool {
+	return r.policy.Load().excludes(searchers)
+}
+
+// SetPolicies replaces the submission policy of the aggregated remote relays.
+func (r *RemoteRelayAggregator) SetPolicies(cfg *Config) {
+	for _, relay := range r.relays {
+		if remote, ok := relay.(*RemoteRelay); ok {
+			remote.SetPolicy(cfg)
+		}
+	}
+}
+
+// bundleSearchers returns the signing addresses of the bundles committed to a block. The share
+// bundles are not signed by a searcher and are sent to every relay.
+func bundleSearchers(bundles []types.SimulatedBundle) []common.Address {
+	var (
+		searchers []common.Address
+		seen      = make(map[common.Address]struct{})
+	)
+	for _, bundle := range bundles {
+		searcher := bundle.OriginalBundle.SigningAddress
+		if _, ok := seen[searcher]; ok || searcher == (common.Address{}) {
+			continue
+		}
+		seen[searcher] = struct{}{}
+		searchers = append(searchers, searcher)
+	}
+	return searchers
+}
+
+// submitBlock submits a bellatrix block to the relay, the aggregated relays excluding the
+// searchers of the committed bundles are skipped.
+func submitBlock(relay IRelay, msg *bellatrixapi.SubmitBlockRequest, vd ValidatorData, searchers []common.Address) error {
+	if aggregator, ok := relay.(*RemoteRelayAggregator); ok {
+		return aggregator.submit(vd, searchers, func(relay IRelay) error { return relay.SubmitBlock(msg, vd) })
+	}
+	return relay.SubmitBlock(msg, vd)
+}
+
+// submitBlockCapella submits a capella block to the relay, the aggregated relays excluding the
+// searchers of the committed bundles are skipped.
+func submitBlockCapella(relay IRelay, msg *capellaapi.SubmitBlockRequest, vd ValidatorData, searchers []common.Address) error {
+	if aggregator, ok := relay.(*RemoteRelayAggregator); ok {
+		return aggregator.submit(vd, searchers, func(relay IRelay) error { return relay.SubmitBlockCapella(msg, vd) })
+	}
+	return relay.SubmitBlockCapella(msg, vd)
+}
@@ -0,0 +1,80 @@
+package builder
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayFanout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "builder.toml")
+	writeFileConfig(t, path, `
+[Relays]
+Cancellations = true
+
+[[Relays.Endpoints]]
+Endpoint = "http://primary.example;ssz=true"
+RateLimit = "1h"
+
+[[Relays.Endpoints]]
+Endpoint = "http://secondary.example"
+Cancellations = false
+ExcludeSearchers = ["0x000000000000000000000000000000000000000a"]
+`)
+	file, err := LoadFileConfig(path)
+	require.NoError(t, err)
+	cfg := DefaultConfig
+	require.NoError(t, file.Apply(&cfg, &miner.Config{}))
+	require.Equal(t, "http://primary.example;ssz=true", cfg.RemoteRelayEndpoint)
+	require.Equal(t, []string{"http://secondary.example"}, cfg.SecondaryRemoteRelayEndpoints)
+	require.Len(t, cfg.RelayPolicies, 2)
+
+	primary := &RemoteRelay{config: RelayConfig{Endpoint: "http://primary.example"}}
+	secondary := &RemoteRelay{config: RelayConfig{Endpoint: "http://secondary.example"}}
+	primary.SetPolicy(&cfg)
+	secondary.SetPolicy(&cfg)
+	require.True(t, primary.policy.Load().cancellations)
+	require.False(t, secondary.policy.Load().cancellations)
+
+	// the submissions over the rate limit of a relay are dropped
+	require.True(t, primary.policy.Load().allow())
+	require.False(t, primary.policy.Load().allow())
+	require.True(t, secondary.policy.Load().allow())
+
+	// the blocks with bundles of an excluded searcher are not fanned out to the relay
+	vd := ValidatorData{GasLimit: 10}
+	ragg := NewRemoteRelayAggregator(primary, []IRelay{secondary})
+	ragg.registrationsCache = map[ValidatorData][]IRelay{vd: {primary, secondary}}
+
+	submit := func(relays int, searchers ...common.Address) []IRelay {
+		submitted := make(chan IRelay, 2)
+		require.NoError(t, ragg.submit(vd, searchers, func(relay IRelay) error {
+			submitted <- relay
+			return nil
+		}))
+		var result []IRelay
+		for i := 0; i < relays; i++ {
+			select {
+			case relay := <-submitted:
+				result = append(result, relay)
+			case <-time.After(time.Second):
+				t.Fatal("block not submitted")
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		require.Empty(t, submitted)
+		return result
+	}
+	require.ElementsMatch(t, []IRelay{primary, secondary}, submit(2, common.Address{0xb}))
+	require.ElementsMatch(t, []IRelay{primary}, submit(1, common.Address{0xb}, common.HexToAddress("0x0a")))
+
+	// an invalid relay entry is not applied
+	writeFileConfig(t, path, "[[Relays.Endpoints]]\nEndpoint = \"http://relay.example\"\nExcludeSearchers = [\"searcher\"]")
+	file, err = LoadFileConfig(path)
+	require.NoError(t, err)
+	require.Error(t, file.Apply(&cfg, &miner.Config{}))
+}
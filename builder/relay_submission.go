@@ -0,0 +1,193 @@
+package builder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/builder/keymanager"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	relaySubmissionBackoff = 50 * time.Millisecond // Delay before the first retry of a submission, doubled for every retry
+	relayErrorBodyLimit    = 1024                  // Bytes of the relay error response kept in the submission error
+
+	sszContentType  = "application/octet-stream"
+	jsonContentType = "application/json"
+)
+
+// relayMetrics are the outcomes of the block submissions to a relay, labelled by the relay host.
+type relayMetrics struct {
+	accepted metrics.Meter // Submissions acknowledged with a 2xx response
+	rejected metrics.Meter // Submissions refused by the relay with a 4xx response
+	failed   metrics.Meter // Submissions failing after all retries, on errors, timeouts or 5xx responses
+	retries  metrics.Meter
+	duration metrics.Timer // Time to submit a block including the retries
+}
+
+func newRelayMetrics(endpoint string) *relayMetrics {
+	name := "builder/relay/" + relayMetricsName(endpoint)
+	return &relayMetrics{
+		accepted: metrics.GetOrRegisterMeter(name+"/accepted", nil),
+		rejected: metrics.GetOrRegisterMeter(name+"/rejected", nil),
+		failed:   metrics.GetOrRegisterMeter(name+"/failed", nil),
+		retries:  metrics.GetOrRegisterMeter(name+"/retries", nil),
+		duration: metrics.GetOrRegisterTimer(name+"/duration", nil),
+	}
+}
+
+// relayMetricsName returns the host of the relay endpoint without the credentials, with the
+// characters the metrics backends treat as separators replaced.
+func relayMetricsName(endpoint string) string {
+	host := endpoint
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return strings.NewReplacer(".", "_", ":", "_", "/", "_").Replace(host)
+}
+
+// relaySigningKey returns the key signing the relay submissions, either hex encoded or a vault path,
+// nil if the submissions are not signed.
+func relaySigningKey(secret string) (*ecdsa.PrivateKey, error) {
+	if secret == "" {
+		return nil, nil
+	}
+	resolved, err := keymanager.ResolveSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.HexToECDSA(strings.TrimPrefix(resolved, "0x"))
+}
+
+// withSubmissionSettings returns the relay config with the submission settings of the builder.
+func (c RelayConfig) withSubmissionSettings(cfg *Config, key *ecdsa.PrivateKey) RelayConfig {
+	c.SubmissionTimeout = cfg.RelaySubmissionTimeout
+	c.SubmissionRetries = cfg.RelaySubmissionRetries
+	c.SigningKey = key
+	return c
+}
+
+// relayRequestSignature returns the signature header value authenticating the builder to the relay,
+// as <address>:<signature> with the EIP-191 signature of the hex encoded keccak256 hash of the body.
+func relayRequestSignature(key *ecdsa.PrivateKey, body []byte) (string, error) {
+	message := hexutil.Encode(crypto.Keccak256(body))
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	if err != nil {
+		return "", err
+	}
+	return crypto.PubkeyToAddress(key.PublicKey).Hex() + ":" + hexutil.Encode(sig), nil
+}
+
+// relayRejectionError is a submission refused by the relay, it is not retried.
+type relayRejectionError struct {
+	code int
+	body string
+}
+
+func (e *relayRejectionError) Error() string {
+	return fmt.Sprintf("non-ok response code %d: %s", e.code, e.body)
+}
+
+// submitPayload posts an encoded block submission to the relay. Every attempt is bounded by the
+// submission timeout, the attempts failing with an error, a timeout, a 429 or 5xx response are
+// retried with an exponential backoff up to the configured number of retries.
+func (r *RemoteRelay) submitPayload(endpoint string, body []byte, contentType string) error {
+	start := time.Now()
+	// only the ssz submissions are compressed
+	compressed := r.config.GzipEnabled && contentType == sszContentType
+	if compressed {
+		var buf bytes.Buffer
+		gzipWriter := gzip.NewWriter(&buf)
+		if _, err := gzipWriter.Write(body); err != nil {
+			return fmt.Errorf("error writing payload to gzip writer: %w", err)
+		}
+		if err := gzipWriter.Close(); err != nil {
+			return fmt.Errorf("error closing gzip writer: %w", err)
+		}
+		body = buf.Bytes()
+	}
+	var signature string
+	if r.config.SigningKey != nil {
+		var err error
+		if signature, err = relayRequestSignature(r.config.SigningKey, body); err != nil {
+			return fmt.Errorf("error signing relay submission: %w", err)
+		}
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = r.postPayload(endpoint, body, contentType, compressed, signature)
+		var rejection *relayRejectionError
+		if err == nil || errors.As(err, &rejection) || attempt >= r.config.SubmissionRetries {
+			break
+		}
+		log.Debug("Retrying relay submission", "endpoint", r.config.Endpoint, "attempt", attempt+1, "err", err)
+		if metrics.EnabledBuilder {
+			r.metrics.retries.Mark(1)
+		}
+		time.Sleep(relaySubmissionBackoff << attempt)
+	}
+
+	if metrics.EnabledBuilder {
+		r.metrics.duration.UpdateSince(start)
+		var rejection *relayRejectionError
+		switch {
+		case err == nil:
+			r.metrics.accepted.Mark(1)
+		case errors.As(err, &rejection):
+			r.metrics.rejected.Mark(1)
+		default:
+			r.metrics.failed.Mark(1)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error sending http request to relay %s. err: %w", r.config.Endpoint, err)
+	}
+	return nil
+}
+
+// postPayload makes a single submission attempt.
+func (r *RemoteRelay) postPayload(endpoint string, body []byte, contentType string, compressed bool, signature string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.SubmissionTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if signature != "" {
+		req.Header.Set(rpc.SignatureHeader, signature)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, relayErrorBodyLimit))
+	if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return &relayRejectionError{code: resp.StatusCode, body: string(data)}
+	}
+	return fmt.Errorf("%w: %d / %s", errHTTPErrorResponse, resp.StatusCode, string(data))
+}
@@ -0,0 +1,74 @@
+package builder
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelaySubmission(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	var (
+		attempts  atomic.Int32
+		failures  int32 // Attempts answered with status before accepting
+		status    int
+		signature string
+		body      []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(rpc.SignatureHeader)
+		if attempts.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	relay := &RemoteRelay{
+		config:  RelayConfig{Endpoint: srv.URL, SubmissionTimeout: time.Second, SubmissionRetries: 2, SigningKey: key},
+		metrics: newRelayMetrics(srv.URL),
+	}
+
+	// a 5xx response is retried, the submission is signed by the relay signing key
+	failures, status = 1, http.StatusServiceUnavailable
+	require.NoError(t, relay.submitPayload(srv.URL, []byte(`{"block":1}`), jsonContentType))
+	require.Equal(t, int32(2), attempts.Load())
+	require.Equal(t, `{"block":1}`, string(body))
+
+	claimed, encodedSig, ok := strings.Cut(signature, ":")
+	require.True(t, ok)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey).Hex(), claimed)
+	sig, err := hexutil.Decode(encodedSig)
+	require.NoError(t, err)
+	pubkey, err := crypto.SigToPub(accounts.TextHash([]byte(hexutil.Encode(crypto.Keccak256(body)))), sig)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(key.PublicKey), crypto.PubkeyToAddress(*pubkey))
+
+	// a 4xx response is a rejection and is not retried
+	attempts.Store(0)
+	status = http.StatusBadRequest
+	err = relay.submitPayload(srv.URL, []byte(`{"block":2}`), jsonContentType)
+	var rejection *relayRejectionError
+	require.ErrorAs(t, err, &rejection)
+	require.Equal(t, int32(1), attempts.Load())
+
+	// the retries are bounded
+	attempts.Store(0)
+	failures, status = 10, http.StatusInternalServerError
+	require.Error(t, relay.submitPayload(srv.URL, []byte(`{"block":3}`), jsonContentType))
+	require.Equal(t, int32(3), attempts.Load())
+}
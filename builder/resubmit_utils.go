@@ -92,3 +92,21 @@ func runRetryLoop(ctx context.Context, interval time.Duration, retry func()) {
 		}
 	}
 }
+
+// runRebuildLoop calls rebuild periodically with the provided interval and on every rebuild signal,
+// the signals received while rebuilding are coalesced into a single rebuild
+func runRebuildLoop(ctx context.Context, interval time.Duration, rebuildSignal <-chan struct{}, rebuild func()) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			rebuild()
+		case <-rebuildSignal:
+			rebuild()
+			t.Reset(interval)
+		}
+	}
+}
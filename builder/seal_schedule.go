@@ -0,0 +1,89 @@
+package builder
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// slotJobTimeout is the lifetime of the building jobs of the beacon slots.
+const slotJobTimeout = 12 * time.Second
+
+// sealSchedule times the building jobs to the sealing deadline of the Bor block producers. A Bor
+// block is sealed a period after its parent, or the producer delay at the first block of a sprint,
+// and the backup producers seal a backup delay later per position in the producer succession.
+type sealSchedule struct {
+	period        time.Duration // Time between the blocks of the in-turn producer
+	producerDelay time.Duration // Time before the first block of a sprint, the period if zero
+	sprint        uint64        // Blocks produced in a row by the same producer
+	backupDelay   time.Duration // Extra time given to each backup producer
+}
+
+// newSealSchedule returns the seal schedule of the config, nil if the builder follows the beacon slots.
+func newSealSchedule(cfg *Config) *sealSchedule {
+	if cfg.BorPeriod <= 0 {
+		return nil
+	}
+	return &sealSchedule{
+		period:        cfg.BorPeriod,
+		producerDelay: cfg.BorProducerDelay,
+		sprint:        cfg.BorSprint,
+		backupDelay:   cfg.BorBackupDelay,
+	}
+}
+
+// delay returns the time between the parent and the block of the in-turn producer.
+func (s *sealSchedule) delay(number uint64) time.Duration {
+	if s.sprint > 0 && s.producerDelay > 0 && number%s.sprint == 0 {
+		return s.producerDelay
+	}
+	return s.period
+}
+
+// succession returns the position in the producer succession of the block sealed at timestamp,
+// 0 for the in-turn producer.
+func (s *sealSchedule) succession(parent *types.Block, timestamp uint64) uint64 {
+	inTurn := parent.Time() + uint64(s.delay(parent.NumberU64()+1)/time.Second)
+	if timestamp <= inTurn || s.backupDelay < time.Second {
+		return 0
+	}
+	backup := uint64(s.backupDelay / time.Second)
+	return (timestamp - inTurn + backup - 1) / backup
+}
+
+// deadline returns the sealing deadline of the block built on parent for the payload timestamp.
+// The builder follows the beacon slots without a schedule, the deadline is the slot time.
+func (s *sealSchedule) deadline(parent *types.Block, timestamp uint64) time.Time {
+	if s == nil {
+		return time.Unix(int64(timestamp), 0).UTC()
+	}
+	succession := s.succession(parent, timestamp)
+	delay := s.delay(parent.NumberU64()+1) + time.Duration(succession)*s.backupDelay
+	deadline := time.Unix(int64(parent.Time()), 0).Add(delay).UTC()
+	log.Debug("Scheduled block sealing", "number", parent.NumberU64()+1, "succession", succession, "deadline", deadline)
+	return deadline
+}
+
+// jobContext returns the context of a building job sealing at the deadline. The jobs of the
+// beacon slots live for a slot, the scheduled jobs are retired at the sealing deadline.
+func (s *sealSchedule) jobContext(parent context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if s == nil {
+		return context.WithTimeout(parent, slotJobTimeout)
+	}
+	return context.WithDeadline(parent, deadline)
+}
+
+// orderflowSource is implemented by the backends notifying the new pending transactions, the
+// scheduled jobs re-build their block as the orderflow arrives.
+type orderflowSource interface {
+	SubscribeOrderflow(ch chan<- core.NewTxsEvent) event.Subscription
+}
+
+// SubscribeOrderflow subscribes to the transactions added to the pending pool.
+func (s *EthereumService) SubscribeOrderflow(ch chan<- core.NewTxsEvent) event.Subscription {
+	return s.eth.TxPool().SubscribeNewTxsEvent(ch)
+}
@@ -0,0 +1,72 @@
+package builder
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealSchedule(t *testing.T) {
+	parent := func(number, timestamp uint64) *types.Block {
+		return types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(number), Time: timestamp})
+	}
+
+	// without a schedule the jobs follow the beacon slots
+	var schedule *sealSchedule
+	require.Equal(t, time.Unix(112, 0).UTC(), schedule.deadline(parent(1, 100), 112))
+	require.Nil(t, newSealSchedule(&DefaultConfig))
+
+	cfg := DefaultConfig
+	cfg.BorPeriod, cfg.BorProducerDelay, cfg.BorSprint, cfg.BorBackupDelay = 2*time.Second, 6*time.Second, 16, 2*time.Second
+	schedule = newSealSchedule(&cfg)
+
+	// the in-turn producer seals a period after the parent, the producer delay at the start of a sprint
+	require.Equal(t, time.Unix(102, 0).UTC(), schedule.deadline(parent(20, 100), 102))
+	require.Equal(t, time.Unix(106, 0).UTC(), schedule.deadline(parent(31, 100), 106))
+
+	// the backup producers seal a backup delay later per position in the succession
+	require.Equal(t, uint64(2), schedule.succession(parent(20, 100), 106))
+	require.Equal(t, time.Unix(106, 0).UTC(), schedule.deadline(parent(20, 100), 106))
+	require.Equal(t, time.Unix(108, 0).UTC(), schedule.deadline(parent(20, 100), 107))
+
+	// the scheduled jobs are retired at the sealing deadline
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := schedule.jobContext(context.Background(), deadline)
+	defer cancel()
+	jobDeadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.Equal(t, deadline, jobDeadline)
+}
+
+func TestRebuildLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		builds atomic.Int32
+		signal = make(chan struct{}, 1)
+		done   = make(chan struct{})
+	)
+	go func() {
+		runRebuildLoop(ctx, time.Hour, signal, func() { builds.Add(1) })
+		close(done)
+	}()
+
+	// the block is re-built on new orderflow without waiting for the interval
+	signal <- struct{}{}
+	require.Eventually(t, func() bool { return builds.Load() == 1 }, time.Second, time.Millisecond)
+	signal <- struct{}{}
+	require.Eventually(t, func() bool { return builds.Load() == 2 }, time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("rebuild loop not stopped")
+	}
+}
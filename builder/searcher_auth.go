@@ -0,0 +1,80 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/builder/searcherauth"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// searcherAuthEnabled returns whether the bundle signers are authenticated or limited.
+func searcherAuthEnabled(cfg *Config) bool {
+	return cfg.SearcherAuthRequired || cfg.SearcherRateLimit > 0 || len(cfg.SearcherAllowlist) != 0 || len(cfg.SearcherDenylist) != 0
+}
+
+func newSearcherAuth(cfg *Config) (*searcherauth.Authenticator, error) {
+	if cfg.SearcherRateLimit < 0 {
+		return nil, fmt.Errorf("invalid searcher rate limit %v", cfg.SearcherRateLimit)
+	}
+	if cfg.SearcherRateLimit > 0 && cfg.SearcherRateBurst <= 0 {
+		return nil, fmt.Errorf("invalid searcher rate burst %d", cfg.SearcherRateBurst)
+	}
+	allowlist, err := parseSearcherList(cfg.SearcherAllowlist)
+	if err != nil {
+		return nil, err
+	}
+	denylist, err := parseSearcherList(cfg.SearcherDenylist)
+	if err != nil {
+		return nil, err
+	}
+	return searcherauth.New(searcherauth.Config{
+		Required:  cfg.SearcherAuthRequired,
+		Rate:      cfg.SearcherRateLimit,
+		Burst:     cfg.SearcherRateBurst,
+		Allowlist: allowlist,
+		Denylist:  denylist,
+	}), nil
+}
+
+func parseSearcherList(list []string) ([]common.Address, error) {
+	var searchers []common.Address
+	for _, searcher := range list {
+		if searcher == "" {
+			continue
+		}
+		if !common.IsHexAddress(searcher) {
+			return nil, fmt.Errorf("invalid searcher address %s", searcher)
+		}
+		searchers = append(searchers, common.HexToAddress(searcher))
+	}
+	return searchers, nil
+}
+
+// SearcherLists are the request signers exempt from the rate limit and those rejected.
+type SearcherLists struct {
+	Allowlist []common.Address `json:"allowlist"`
+	Denylist  []common.Address `json:"denylist"`
+}
+
+// searcherAuthAPI manages the lists of the bundle signers from the authenticated builder API,
+// for operators to block or exempt searchers without restarting.
+type searcherAuthAPI struct {
+	auth *searcherauth.Authenticator
+}
+
+func newSearcherAuthAPI(auth *searcherauth.Authenticator) *searcherAuthAPI {
+	return &searcherAuthAPI{auth: auth}
+}
+
+// GetSearcherLists returns the allowlist and the denylist of the request signers.
+func (api *searcherAuthAPI) GetSearcherLists() SearcherLists {
+	allowlist, denylist := api.auth.Lists()
+	return SearcherLists{Allowlist: allowlist, Denylist: denylist}
+}
+
+// SetSearcherLists replaces the allowlist and the denylist of the request signers.
+func (api *searcherAuthAPI) SetSearcherLists(lists SearcherLists) {
+	api.auth.SetLists(lists.Allowlist, lists.Denylist)
+	log.Info("Replaced searcher lists", "allowlisted", len(lists.Allowlist), "denylisted", len(lists.Denylist))
+}
@@ -0,0 +1,33 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearcherAuthAPI(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.SearcherRateLimit = 1
+	cfg.SearcherAllowlist = []string{"0x0000000000000000000000000000000000000001", ""}
+	auth, err := newSearcherAuth(&cfg)
+	require.NoError(t, err)
+
+	api := newSearcherAuthAPI(auth)
+	require.Equal(t, SearcherLists{
+		Allowlist: []common.Address{common.HexToAddress("0x01")},
+		Denylist:  []common.Address{},
+	}, api.GetSearcherLists())
+
+	lists := SearcherLists{
+		Allowlist: []common.Address{common.HexToAddress("0x02")},
+		Denylist:  []common.Address{common.HexToAddress("0x01")},
+	}
+	api.SetSearcherLists(lists)
+	require.Equal(t, lists, api.GetSearcherLists())
+
+	cfg.SearcherDenylist = []string{"0xinvalid"}
+	_, err = newSearcherAuth(&cfg)
+	require.Error(t, err)
+}
@@ -0,0 +1,156 @@
+// Package searcherauth authenticates the searchers submitting bundles and limits their rate.
+//
+// Searchers are identified by the address signing their requests, see rpc.SignatureHeader. The
+// signing address claimed by a request is only trusted when the request is signed by it, an
+// unsigned request has no identity. Every signer gets its own token bucket, denylisted signers are rejected and allowlisted ones
+// are exempt from the rate limit. The lists can be replaced at runtime.
+package searcherauth
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/time/rate"
+)
+
+// idleTimeout is the time after which the bucket of a signer not submitting is dropped, it is
+// full again by then for any sensible rate.
+const idleTimeout = 10 * time.Minute
+
+var (
+	ErrSignatureRequired = errors.New("bundle submissions must be signed")
+	ErrSignerDenied      = errors.New("bundle signer denied")
+	ErrRateLimited       = errors.New("bundle signer rate limited")
+	ErrSignerMismatch    = errors.New("bundle signingAddress does not match the request signer")
+)
+
+// Identity returns the identity of a submission signed by signer, the zero address if the
+// submission is not signed. The signing address claimed by the submission must be the signer of
+// a signed submission, and is ignored if the submission is not signed as anyone can claim it.
+func Identity(signer common.Address, claimed *common.Address) (common.Address, error) {
+	if signer != (common.Address{}) && claimed != nil && *claimed != signer {
+		return common.Address{}, ErrSignerMismatch
+	}
+	return signer, nil
+}
+
+type Config struct {
+	Required  bool             // Reject the submissions which are not signed
+	Rate      float64          // Submissions per second allowed to every signer, 0 = unlimited
+	Burst     int              // Submissions a signer can make at once
+	Allowlist []common.Address // Signers exempt from the rate limit
+	Denylist  []common.Address // Signers rejected
+}
+
+type signerLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Authenticator checks the signers of the bundle submissions.
+type Authenticator struct {
+	config Config
+
+	mu        sync.Mutex
+	allowlist map[common.Address]struct{}
+	denylist  map[common.Address]struct{}
+	limiters  map[common.Address]*signerLimiter
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func New(config Config) *Authenticator {
+	a := &Authenticator{
+		config:   config,
+		limiters: make(map[common.Address]*signerLimiter),
+		now:      time.Now,
+	}
+	a.SetLists(config.Allowlist, config.Denylist)
+	return a
+}
+
+// Authenticate checks a submission of the signer, the zero address if the submission is not
+// signed, returning an error if the submission is rejected.
+func (a *Authenticator) Authenticate(signer common.Address) error {
+	if signer == (common.Address{}) {
+		if a.config.Required {
+			return ErrSignatureRequired
+		}
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.denylist[signer]; ok {
+		return ErrSignerDenied
+	}
+	if _, ok := a.allowlist[signer]; ok || a.config.Rate <= 0 {
+		return nil
+	}
+
+	now := a.now()
+	sl, ok := a.limiters[signer]
+	if !ok {
+		sl = &signerLimiter{limiter: rate.NewLimiter(rate.Limit(a.config.Rate), a.config.Burst)}
+		a.limiters[signer] = sl
+	}
+	sl.lastSeen = now
+	if now.Sub(a.lastPrune) >= idleTimeout {
+		a.prune(now)
+	}
+	if !sl.limiter.AllowN(now, 1) {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// SetLists replaces the allowlist and the denylist.
+func (a *Authenticator) SetLists(allowlist, denylist []common.Address) {
+	allow := make(map[common.Address]struct{}, len(allowlist))
+	for _, signer := range allowlist {
+		allow[signer] = struct{}{}
+	}
+	deny := make(map[common.Address]struct{}, len(denylist))
+	for _, signer := range denylist {
+		deny[signer] = struct{}{}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.allowlist, a.denylist = allow, deny
+}
+
+// Lists returns the allowlist and the denylist, sorted.
+func (a *Authenticator) Lists() (allowlist, denylist []common.Address) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return sortedSigners(a.allowlist), sortedSigners(a.denylist)
+}
+
+func sortedSigners(set map[common.Address]struct{}) []common.Address {
+	signers := make([]common.Address, 0, len(set))
+	for signer := range set {
+		signers = append(signers, signer)
+	}
+	sort.Slice(signers, func(i, j int) bool {
+		return bytes.Compare(signers[i][:], signers[j][:]) < 0
+	})
+	return signers
+}
+
+// prune drops the limiters of the signers idle for idleTimeout.
+func (a *Authenticator) prune(now time.Time) {
+	for signer, sl := range a.limiters {
+		if now.Sub(sl.lastSeen) >= idleTimeout {
+			delete(a.limiters, signer)
+		}
+	}
+	a.lastPrune = now
+}
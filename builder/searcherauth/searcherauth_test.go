@@ -0,0 +1,80 @@
+package searcherauth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticator(t *testing.T) {
+	var (
+		allowlisted = common.HexToAddress("0x01")
+		denylisted  = common.HexToAddress("0x02")
+		searcher    = common.HexToAddress("0x03")
+	)
+	auth := New(Config{
+		Required:  true,
+		Rate:      1,
+		Burst:     2,
+		Allowlist: []common.Address{allowlisted},
+		Denylist:  []common.Address{denylisted},
+	})
+	now := time.Unix(1000, 0)
+	auth.now = func() time.Time { return now }
+
+	require.ErrorIs(t, auth.Authenticate(common.Address{}), ErrSignatureRequired)
+	require.ErrorIs(t, auth.Authenticate(denylisted), ErrSignerDenied)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, auth.Authenticate(allowlisted))
+	}
+
+	// the burst is consumed, a token is added every second
+	require.NoError(t, auth.Authenticate(searcher))
+	require.NoError(t, auth.Authenticate(searcher))
+	require.ErrorIs(t, auth.Authenticate(searcher), ErrRateLimited)
+	now = now.Add(time.Second)
+	require.NoError(t, auth.Authenticate(searcher))
+	require.ErrorIs(t, auth.Authenticate(searcher), ErrRateLimited)
+
+	// the lists are replaced at runtime
+	auth.SetLists([]common.Address{searcher}, []common.Address{allowlisted})
+	require.NoError(t, auth.Authenticate(searcher))
+	require.ErrorIs(t, auth.Authenticate(allowlisted), ErrSignerDenied)
+	allowlist, denylist := auth.Lists()
+	require.Equal(t, []common.Address{searcher}, allowlist)
+	require.Equal(t, []common.Address{allowlisted}, denylist)
+
+	// idle signers are pruned
+	auth.SetLists(nil, nil)
+	now = now.Add(idleTimeout)
+	require.NoError(t, auth.Authenticate(denylisted))
+	require.Len(t, auth.limiters, 1)
+}
+
+func TestIdentity(t *testing.T) {
+	var (
+		signer = common.HexToAddress("0x01")
+		victim = common.HexToAddress("0x02")
+	)
+	identity, err := Identity(signer, nil)
+	require.NoError(t, err)
+	require.Equal(t, signer, identity)
+	identity, err = Identity(signer, &signer)
+	require.NoError(t, err)
+	require.Equal(t, signer, identity)
+	_, err = Identity(signer, &victim)
+	require.ErrorIs(t, err, ErrSignerMismatch)
+
+	// the signing address claimed by an unsigned submission is not trusted
+	identity, err = Identity(common.Address{}, &victim)
+	require.NoError(t, err)
+	require.Equal(t, common.Address{}, identity)
+}
+
+func TestAuthenticatorOptionalSignature(t *testing.T) {
+	auth := New(Config{})
+	require.NoError(t, auth.Authenticate(common.Address{}))
+	require.NoError(t, auth.Authenticate(common.HexToAddress("0x01")))
+}
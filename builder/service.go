@@ -1,8 +1,10 @@
 package builder
 
 import (
+	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 	"os"
 	"strconv"
@@ -10,12 +12,25 @@ import (
 	"time"
 
 	"github.com/attestantio/go-eth2-client/spec/phase0"
+	"github.com/ethereum/go-ethereum/builder/alerting"
+	"github.com/ethereum/go-ethereum/builder/auditlog"
+	"github.com/ethereum/go-ethereum/builder/buildtrace"
+	"github.com/ethereum/go-ethereum/builder/bundlerecord"
+	"github.com/ethereum/go-ethereum/builder/decisionlog"
+	"github.com/ethereum/go-ethereum/builder/depositgate"
+	"github.com/ethereum/go-ethereum/builder/eventexport"
+	"github.com/ethereum/go-ethereum/builder/keymanager"
+	"github.com/ethereum/go-ethereum/builder/profiling"
+	"github.com/ethereum/go-ethereum/builder/searcherauth"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/ethereum/go-ethereum/eth"
 	blockvalidation "github.com/ethereum/go-ethereum/eth/block-validation"
 	"github.com/ethereum/go-ethereum/flashbotsextra"
+	"github.com/ethereum/go-ethereum/internal/redact"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -34,8 +49,16 @@ const (
 )
 
 type Service struct {
-	srv     *http.Server
-	builder IBuilder
+	srv       *http.Server
+	builder   IBuilder
+	health    *healthMonitor
+	ingestion bundleIngestion
+}
+
+// bundleIngestion is the bundle pool whose ingestion the operator can pause.
+type bundleIngestion interface {
+	SetBundleIngestionPaused(paused bool)
+	BundleIngestionPaused() bool
 }
 
 func (s *Service) Start() error {
@@ -61,6 +84,117 @@ func (s *Service) PayloadAttributes(payloadAttributes *types.BuilderPayloadAttri
 	return s.builder.OnPayloadAttribute(payloadAttributes)
 }
 
+// Health returns the latest health checks of the builder dependencies.
+func (s *Service) Health() (HealthReport, error) {
+	if s.health == nil {
+		return HealthReport{Healthy: true, Checks: []HealthCheck{}}, nil
+	}
+	return s.health.report()
+}
+
+// ProducerSchedule returns the Bor producers in turn for the sprints from the one of the given
+// block, the block being built if none is given, to the end of its span.
+func (s *Service) ProducerSchedule(number *uint64) ([]ScheduledProducer, error) {
+	return s.builder.ProducerSchedule(number)
+}
+
+// Prebuild builds a candidate block of the current slot on top of the given recent parent, which
+// may not be canonical, so that it can be submitted at once if the chain reorgs to that parent.
+func (s *Service) Prebuild(parent common.Hash) error {
+	return s.builder.Prebuild(parent)
+}
+
+// MissedSlots returns the postmortems of the recent slots the builder submitted blocks for
+// that landed another block.
+func (s *Service) MissedSlots() []SlotPostmortem {
+	return s.builder.MissedSlots()
+}
+
+// ProfitProgression returns the value of the successive candidate blocks built within the
+// given slot, or within the recent slots if no slot is given, to show whether packing time
+// increases the block value.
+func (s *Service) ProfitProgression(slot *uint64) []SlotProfitProgression {
+	return s.builder.ProfitProgression(slot)
+}
+
+// Status returns the runtime state of the builder: the paused bundle ingestion and
+// submissions, and the progress of a drain.
+func (s *Service) Status() BuilderStatus {
+	status := s.builder.Status()
+	if s.ingestion != nil {
+		status.IngestionPaused = s.ingestion.BundleIngestionPaused()
+	}
+	return status
+}
+
+// PauseIngestion rejects the new bundles, the bundles already in the pool are still built.
+func (s *Service) PauseIngestion() BuilderStatus {
+	s.setIngestionPaused(true)
+	return s.Status()
+}
+
+// ResumeIngestion accepts new bundles again.
+func (s *Service) ResumeIngestion() BuilderStatus {
+	s.setIngestionPaused(false)
+	return s.Status()
+}
+
+// PauseSubmissions stops submitting blocks to the relays, the blocks are still built.
+func (s *Service) PauseSubmissions() BuilderStatus {
+	s.builder.PauseSubmissions(true)
+	log.Warn("Builder submissions paused")
+	return s.Status()
+}
+
+// ResumeSubmissions submits blocks to the relays again.
+func (s *Service) ResumeSubmissions() BuilderStatus {
+	s.builder.PauseSubmissions(false)
+	log.Info("Builder submissions resumed")
+	return s.Status()
+}
+
+// Drain drains the pipeline for maintenance: new bundles are rejected, the slot being built
+// is finished and the later slots are not built. The status reports when it is drained.
+func (s *Service) Drain() BuilderStatus {
+	s.setIngestionPaused(true)
+	s.builder.Drain()
+	status := s.Status()
+	log.Warn("Builder draining", "slot", status.DrainSlot)
+	return status
+}
+
+// Resume lifts a drain and the pauses of the bundle ingestion and submissions.
+func (s *Service) Resume() BuilderStatus {
+	s.setIngestionPaused(false)
+	s.builder.Resume()
+	log.Info("Builder resumed")
+	return s.Status()
+}
+
+func (s *Service) setIngestionPaused(paused bool) {
+	if s.ingestion == nil {
+		return
+	}
+	s.ingestion.SetBundleIngestionPaused(paused)
+	if paused {
+		log.Warn("Bundle ingestion paused")
+	} else {
+		log.Info("Bundle ingestion resumed")
+	}
+}
+
+// ShadowComparisons returns the comparisons of the recent slots built in shadow mode with the
+// blocks that landed in them, the value and the transactions and bundles they share.
+func (s *Service) ShadowComparisons() []ShadowComparison {
+	return s.builder.ShadowComparisons()
+}
+
+// ShadowSummary returns the totals of the slots built in shadow mode since the start: the slots
+// the builder would have won and the value it would have paid the proposers.
+func (s *Service) ShadowSummary() ShadowSummary {
+	return s.builder.ShadowSummary()
+}
+
 func getRouter(localRelay *LocalRelay) http.Handler {
 	router := mux.NewRouter()
 
@@ -129,7 +263,11 @@ func NewService(listenAddr string, localRelay *LocalRelay, builder IBuilder) *Se
 }
 
 func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
-	envBuilderSkBytes, err := hexutil.Decode(cfg.BuilderSecretKey)
+	builderSecretKey, err := keymanager.ResolveSecret(cfg.BuilderSecretKey)
+	if err != nil {
+		return fmt.Errorf("failed to resolve builder secret key: %w", err)
+	}
+	envBuilderSkBytes, err := hexutil.Decode(builderSecretKey)
 	if err != nil {
 		return errors.New("incorrect builder API secret key provided")
 	}
@@ -180,88 +318,157 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
 		}
 	}
 
-	var relay IRelay
-	if cfg.RemoteRelayEndpoint != "" {
-		relayConfig, err := getRelayConfig(cfg.RemoteRelayEndpoint)
+	relayKey, err := relaySigningKey(cfg.RelaySigningKey)
+	if err != nil {
+		return fmt.Errorf("invalid relay signing key: %w", err)
+	}
+	relay, err := newRelay(cfg, localRelay, relayKey)
+	if err != nil {
+		return err
+	}
+
+	// Set up the settings that can be reloaded from the builder config file
+	settings, err := parseRuntimeSettings(cfg)
+	if err != nil {
+		return err
+	}
+
+	var validator *blockvalidation.BlockValidationAPI
+	if cfg.DryRun {
+		validator = blockvalidation.NewBlockValidationAPI(backend, settings.accessVerifier, cfg.ValidationUseCoinbaseDiff)
+	}
+
+	// Set up builder rate limiter based on environment variables or CLI flags.
+	limiter := rate.NewLimiter(rate.Every(settings.rateLimitInterval), settings.rateLimitBurst)
+
+	// TODO: move to proper flags
+	var ds flashbotsextra.IDatabaseService
+	dbDSN := os.Getenv("FLASHBOTS_POSTGRES_DSN")
+	if dbDSN != "" {
+		ds, err = flashbotsextra.NewDatabaseService(dbDSN)
 		if err != nil {
-			return fmt.Errorf("invalid remote relay endpoint: %w", err)
+			log.Error("could not connect to the DB", "err", err)
+			ds = flashbotsextra.NilDbService{}
 		}
-		relay = NewRemoteRelay(relayConfig, localRelay, cfg.EnableCancellations)
-	} else if localRelay != nil {
-		relay = localRelay
 	} else {
-		return errors.New("neither local nor remote relay specified")
+		log.Info("db dsn is not provided, starting nil db svc")
+		ds = flashbotsextra.NilDbService{}
 	}
 
-	if len(cfg.SecondaryRemoteRelayEndpoints) > 0 && !(len(cfg.SecondaryRemoteRelayEndpoints) == 1 && cfg.SecondaryRemoteRelayEndpoints[0] == "") {
-		secondaryRelays := make([]IRelay, len(cfg.SecondaryRemoteRelayEndpoints))
-		for i, endpoint := range cfg.SecondaryRemoteRelayEndpoints {
-			relayConfig, err := getRelayConfig(endpoint)
-			if err != nil {
-				return fmt.Errorf("invalid secondary remote relay endpoint: %w", err)
-			}
-			secondaryRelays[i] = NewRemoteRelay(relayConfig, nil, cfg.EnableCancellations)
+	if cfg.BundleEncryptionKeyFile != "" {
+		bundleKey, err := crypto.LoadECDSA(cfg.BundleEncryptionKeyFile)
+		if err != nil {
+			return fmt.Errorf("invalid bundle encryption key file: %w", err)
 		}
-		relay = NewRemoteRelayAggregator(relay, secondaryRelays)
+		backend.TxPool().SetBundleEncryptionKey(ecies.ImportECDSA(bundleKey))
+		log.Info("Encrypted bundle submission enabled", "pubkey", hexutil.Encode(crypto.FromECDSAPub(&bundleKey.PublicKey)))
 	}
 
-	var validator *blockvalidation.BlockValidationAPI
-	if cfg.DryRun {
-		var accessVerifier *blockvalidation.AccessVerifier
-		if cfg.ValidationBlocklist != "" {
-			accessVerifier, err = blockvalidation.NewAccessVerifierFromFile(cfg.ValidationBlocklist)
-			if err != nil {
-				return fmt.Errorf("failed to load validation blocklist %w", err)
-			}
+	if cfg.LogBundleContent > 0 {
+		redact.EnableFullContent(cfg.LogBundleContent)
+		log.Warn("Logging full bundle contents, bundles are private until included in a block", "period", cfg.LogBundleContent)
+	}
+
+	if cfg.AuditLogPath != "" {
+		auditLog, err := auditlog.Open(cfg.AuditLogPath)
+		if err != nil {
+			return fmt.Errorf("failed to open audit log: %w", err)
 		}
-		validator = blockvalidation.NewBlockValidationAPI(backend, accessVerifier, cfg.ValidationUseCoinbaseDiff)
+		auditlog.SetDefault(auditLog)
+		stack.RegisterLifecycle(auditLog)
+		seq, head := auditLog.Head()
+		log.Info("Builder audit log enabled", "path", cfg.AuditLogPath, "records", seq, "head", head)
 	}
 
-	// Set up builder rate limiter based on environment variables or CLI flags.
-	// Builder rate limit parameters are flags.BuilderRateLimitDuration and flags.BuilderRateLimitMaxBurst
-	duration, err := time.ParseDuration(cfg.BuilderRateLimitDuration)
-	if err != nil {
-		return fmt.Errorf("error parsing builder rate limit duration - %w", err)
+	if cfg.BundleRecordPath != "" {
+		recorder, err := bundlerecord.Open(cfg.BundleRecordPath)
+		if err != nil {
+			return fmt.Errorf("failed to open bundle recording: %w", err)
+		}
+		bundlerecord.SetDefault(recorder)
+		stack.RegisterLifecycle(recorder)
+		log.Info("Bundle recording enabled", "path", cfg.BundleRecordPath)
 	}
 
-	// BuilderRateLimitMaxBurst is set to builder.RateLimitBurstDefault by default if not specified
-	limiter := rate.NewLimiter(rate.Every(duration), cfg.BuilderRateLimitMaxBurst)
+	if cfg.DecisionLog != "" {
+		decisionLog, err := decisionlog.Open(cfg.DecisionLog)
+		if err != nil {
+			return fmt.Errorf("failed to open decision log: %w", err)
+		}
+		decisionlog.SetDefault(decisionLog)
+		stack.RegisterLifecycle(decisionLog)
+		log.Info("Block inclusion decision log enabled", "target", cfg.DecisionLog)
+	}
 
-	var builderRateLimitInterval time.Duration
-	if cfg.BuilderRateLimitResubmitInterval != "" {
-		d, err := time.ParseDuration(cfg.BuilderRateLimitResubmitInterval)
+	if cfg.BuildTrace != "" {
+		trace, err := buildtrace.Open(cfg.BuildTrace)
 		if err != nil {
-			return fmt.Errorf("error parsing builder rate limit resubmit interval - %v", err)
+			return fmt.Errorf("failed to open build trace: %w", err)
 		}
-		builderRateLimitInterval = d
-	} else {
-		builderRateLimitInterval = RateLimitIntervalDefault
+		buildtrace.SetDefault(trace)
+		stack.RegisterLifecycle(trace)
+		log.Warn("Block build tracing enabled, the trace grows quickly", "path", cfg.BuildTrace)
 	}
 
-	var submissionOffset time.Duration
-	if offset := cfg.BuilderSubmissionOffset; offset != 0 {
-		if offset < 0 {
-			return fmt.Errorf("builder submission offset must be positive")
-		} else if uint64(offset.Seconds()) > cfg.SecondsInSlot {
-			return fmt.Errorf("builder submission offset must be less than seconds in slot")
+	if cfg.EventsURL != "" {
+		exporter, err := eventexport.Open(cfg.EventsURL, cfg.EventsTopicPrefix)
+		if err != nil {
+			return fmt.Errorf("failed to set up event export: %w", err)
 		}
-		submissionOffset = offset
-	} else {
-		submissionOffset = SubmissionOffsetFromEndOfSlotSecondsDefault
+		eventexport.SetDefault(exporter)
+		stack.RegisterLifecycle(exporter)
+		log.Info("Builder event export enabled", "broker", exporter.Broker(), "topicPrefix", cfg.EventsTopicPrefix)
 	}
 
-	// TODO: move to proper flags
-	var ds flashbotsextra.IDatabaseService
-	dbDSN := os.Getenv("FLASHBOTS_POSTGRES_DSN")
-	if dbDSN != "" {
-		ds, err = flashbotsextra.NewDatabaseService(dbDSN)
+	var lowProfit *lowProfitWatch
+	if len(cfg.AlertWebhooks) > 0 {
+		var payloadTemplate string
+		if cfg.AlertTemplate != "" {
+			data, err := os.ReadFile(cfg.AlertTemplate)
+			if err != nil {
+				return fmt.Errorf("failed to read alert payload template: %w", err)
+			}
+			payloadTemplate = string(data)
+		}
+		notifier, err := alerting.New(cfg.AlertWebhooks, payloadTemplate, cfg.AlertMinInterval)
 		if err != nil {
-			log.Error("could not connect to the DB", "err", err)
-			ds = flashbotsextra.NilDbService{}
+			return fmt.Errorf("failed to set up alert webhooks: %w", err)
 		}
-	} else {
-		log.Info("db dsn is not provided, starting nil db svc")
-		ds = flashbotsextra.NilDbService{}
+		alerting.SetDefault(notifier)
+		stack.RegisterLifecycle(notifier)
+
+		if cfg.AlertMinProfit != "" {
+			threshold, ok := new(big.Int).SetString(cfg.AlertMinProfit, 10)
+			if !ok || threshold.Sign() <= 0 {
+				return fmt.Errorf("invalid alert min profit %q, expected a positive amount of wei", cfg.AlertMinProfit)
+			}
+			lowProfit = newLowProfitWatch(threshold, cfg.AlertLowProfitSlots)
+		}
+		// webhook urls often embed a token, they are not logged
+		log.Info("Builder alert webhooks enabled", "webhooks", len(cfg.AlertWebhooks), "minInterval", cfg.AlertMinInterval,
+			"minProfit", cfg.AlertMinProfit, "lowProfitSlots", cfg.AlertLowProfitSlots)
+	}
+
+	if cfg.DepositGateContract != "" {
+		gate, err := newDepositGate(backend, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to set up deposit gate: %w", err)
+		}
+		backend.APIBackend.SetDepositGate(gate)
+		log.Info("Deposit-gated bundle submission enabled", "contract", cfg.DepositGateContract, "unit", cfg.DepositGateUnit, "bundlesPerUnit", cfg.DepositGateBundlesPerUnit)
+	}
+
+	var searcherAuth *searcherauth.Authenticator
+	if searcherAuthEnabled(cfg) {
+		searcherAuth, err = newSearcherAuth(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to set up searcher authentication: %w", err)
+		}
+		backend.APIBackend.SetSearcherAuth(searcherAuth)
+		log.Info("Bundle signer authentication enabled", "required", cfg.SearcherAuthRequired, "rate", cfg.SearcherRateLimit, "burst", cfg.SearcherRateBurst)
+	}
+	if cfg.SearcherMaxBundles > 0 {
+		backend.TxPool().SetMaxBundlesPerSigner(cfg.SearcherMaxBundles)
 	}
 
 	// Bundle fetcher
@@ -273,8 +480,47 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
 		go bundleFetcher.Run()
 	}
 
+	var candidates *candidateDump
+	if cfg.CandidateDumpDir != "" {
+		candidates, err = newCandidateDump(cfg.CandidateDumpDir)
+		if err != nil {
+			return fmt.Errorf("failed to set up candidate block dump: %w", err)
+		}
+		log.Warn("Dumping every candidate block to disk, only enable for debugging", "dir", cfg.CandidateDumpDir, "slots", maxCandidateDumpSlots)
+	}
+
+	var shadow *shadowTracker
+	if cfg.Shadow {
+		shadow = newShadowTracker()
+		log.Warn("Builder running in shadow mode, blocks are built and compared with the landed blocks but never sealed or submitted")
+	}
+
+	var hotStandby *standby
+	if cfg.Standby != "" {
+		jwtSecret := cfg.StandbyJWTSecret
+		if jwtSecret == "" {
+			jwtSecret = stack.Config().JWTSecret
+		}
+		if jwtSecret == "" {
+			jwtSecret = stack.ResolvePath("jwtsecret")
+		}
+		active, err := dialActiveBuilder(cfg.Standby, jwtSecret)
+		if err != nil {
+			return fmt.Errorf("failed to connect to the active builder: %w", err)
+		}
+		if cfg.StandbyTimeout >= time.Duration(cfg.SecondsInSlot)*time.Second {
+			log.Warn("Standby timeout is longer than a slot, the takeover misses slots", "timeout", cfg.StandbyTimeout)
+		}
+		hotStandby = newStandby(active, cfg.Standby, backend.TxPool(), cfg.StandbyTimeout)
+	}
+
 	ethereumService := NewEthereumService(backend)
 
+	producers, err := newProducerSchedule(cfg, backend.Miner().Heimdall())
+	if err != nil {
+		return err
+	}
+
 	builderSk, err := bls.SecretKeyFromBytes(envBuilderSkBytes[:])
 	if err != nil {
 		return errors.New("incorrect builder API secret key provided")
@@ -287,13 +533,19 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
 		eth:                           ethereumService,
 		relay:                         relay,
 		builderSigningDomain:          builderSigningDomain,
-		builderBlockResubmitInterval:  builderRateLimitInterval,
-		submissionOffsetFromEndOfSlot: submissionOffset,
+		builderBlockResubmitInterval:  settings.resubmitInterval,
+		submissionOffsetFromEndOfSlot: settings.submissionOffset,
+		schedule:                      newSealSchedule(cfg),
+		producers:                     producers,
 		discardRevertibleTxOnErr:      cfg.DiscardRevertibleTxOnErr,
 		ignoreLatePayloadAttributes:   cfg.IgnoreLatePayloadAttributes,
 		validator:                     validator,
 		beaconClient:                  beaconClient,
 		limiter:                       limiter,
+		lowProfit:                     lowProfit,
+		candidateDump:                 candidates,
+		shadow:                        shadow,
+		standby:                       hotStandby,
 	}
 
 	builderBackend, err := NewBuilder(builderArgs)
@@ -301,8 +553,24 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
 		return fmt.Errorf("failed to create builder backend: %w", err)
 	}
 	builderService := NewService(cfg.ListenAddr, localRelay, builderBackend)
+	builderService.ingestion = backend.TxPool()
+
+	if cfg.ConfigFile != "" {
+		file, err := LoadFileConfig(cfg.ConfigFile)
+		if err != nil {
+			return fmt.Errorf("failed to load builder config file: %w", err)
+		}
+		stack.RegisterLifecycle(newConfigReloader(cfg.ConfigFile, cfg, file, builderBackend))
+		log.Info("Builder config file enabled, reload it with SIGHUP", "path", cfg.ConfigFile)
+	}
 
-	stack.RegisterAPIs([]rpc.API{
+	health := newHealthMonitor()
+	registerHealthChecks(health, backend, cfg)
+	builderService.health = health
+	stack.RegisterHandler("Builder health", HealthPath, health)
+	stack.RegisterLifecycle(health)
+
+	apis := []rpc.API{
 		{
 			Namespace:     "builder",
 			Version:       "1.0",
@@ -310,9 +578,129 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg *Config) error {
 			Public:        true,
 			Authenticated: true,
 		},
+		{
+			Namespace:     "builder",
+			Version:       "1.0",
+			Service:       newBundleAdminAPI(backend.TxPool()),
+			Public:        true,
+			Authenticated: true,
+		},
+		{
+			Namespace:     "builder",
+			Version:       "1.0",
+			Service:       newProfitBreakdownAPI(backend.Miner()),
+			Public:        true,
+			Authenticated: true,
+		},
+		{
+			Namespace:     "builderadmin",
+			Version:       "1.0",
+			Service:       newBuilderAdminAPI(backend.Miner(), builderBackend, searcherAuth, cfg, localRelay, relayKey),
+			Public:        true,
+			Authenticated: true,
+		},
+	}
+	if searcherAuth != nil {
+		apis = append(apis, rpc.API{
+			Namespace:     "builder",
+			Version:       "1.0",
+			Service:       newSearcherAuthAPI(searcherAuth),
+			Public:        true,
+			Authenticated: true,
+		})
+	}
+	// the capabilities are public, for the searcher clients to negotiate features
+	chain := backend.BlockChain()
+	apis = append(apis, rpc.API{
+		Namespace: "builder",
+		Version:   "1.0",
+		Service: newCapabilitiesAPI(chain.Config(), chain.CurrentBlock, func() bool {
+			return backend.TxPool().BundleEncryptionKey() != nil
+		}, newRelayCapabilities(cfg, localRelay != nil)),
 	})
+	if cfg.ProfileDir != "" {
+		apis = append(apis, rpc.API{
+			Namespace:     "builder",
+			Version:       "1.0",
+			Service:       profiling.NewAPI(cfg.ProfileDir),
+			Public:        true,
+			Authenticated: true,
+		})
+		log.Info("Builder profiling endpoints enabled", "dir", cfg.ProfileDir)
+	}
+	stack.RegisterAPIs(apis)
 
 	stack.RegisterLifecycle(builderService)
+	if hotStandby != nil {
+		stack.RegisterLifecycle(hotStandby)
+	}
 
 	return nil
 }
+
+// newRelay returns the relay the blocks are submitted to: the remote relays of the config, with
+// the local relay as fallback of the primary one, or the local relay alone.
+func newRelay(cfg *Config, localRelay *LocalRelay, relayKey *ecdsa.PrivateKey) (IRelay, error) {
+	var relay IRelay
+	if cfg.RemoteRelayEndpoint != "" {
+		relayConfig, err := getRelayConfig(cfg.RemoteRelayEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote relay endpoint: %w", err)
+		}
+		remote := NewRemoteRelay(relayConfig.withSubmissionSettings(cfg, relayKey), localRelay, cfg.EnableCancellations)
+		remote.SetPolicy(cfg)
+		relay = remote
+	} else if localRelay != nil {
+		relay = localRelay
+	} else {
+		return nil, errors.New("neither local nor remote relay specified")
+	}
+
+	if len(cfg.SecondaryRemoteRelayEndpoints) > 0 && !(len(cfg.SecondaryRemoteRelayEndpoints) == 1 && cfg.SecondaryRemoteRelayEndpoints[0] == "") {
+		secondaryRelays := make([]IRelay, len(cfg.SecondaryRemoteRelayEndpoints))
+		for i, endpoint := range cfg.SecondaryRemoteRelayEndpoints {
+			relayConfig, err := getRelayConfig(endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("invalid secondary remote relay endpoint: %w", err)
+			}
+			remote := NewRemoteRelay(relayConfig.withSubmissionSettings(cfg, relayKey), nil, cfg.EnableCancellations)
+			remote.SetPolicy(cfg)
+			secondaryRelays[i] = remote
+		}
+		relay = NewRemoteRelayAggregator(relay, secondaryRelays)
+	} else if _, ok := relay.(*RemoteRelay); ok && len(cfg.RelayPolicies) > 0 {
+		// the relay policies are applied by the aggregator fanning out the blocks
+		relay = NewRemoteRelayAggregator(relay, nil)
+	}
+	return relay, nil
+}
+
+func newDepositGate(backend *eth.Ethereum, cfg *Config) (*depositgate.Gate, error) {
+	if !common.IsHexAddress(cfg.DepositGateContract) {
+		return nil, fmt.Errorf("invalid deposit contract address %s", cfg.DepositGateContract)
+	}
+	contract := common.HexToAddress(cfg.DepositGateContract)
+
+	unit, ok := new(big.Int).SetString(cfg.DepositGateUnit, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid deposit unit %s", cfg.DepositGateUnit)
+	}
+
+	var allowlist []common.Address
+	for _, searcher := range cfg.DepositGateAllowlist {
+		if searcher == "" {
+			continue
+		}
+		if !common.IsHexAddress(searcher) {
+			return nil, fmt.Errorf("invalid allowlisted searcher address %s", searcher)
+		}
+		allowlist = append(allowlist, common.HexToAddress(searcher))
+	}
+
+	return depositgate.New(depositgate.Config{
+		Contract:       contract,
+		DepositUnit:    unit,
+		BundlesPerUnit: cfg.DepositGateBundlesPerUnit,
+		Allowlist:      allowlist,
+	}, depositgate.ContractDepositReader(backend.BlockChain(), contract))
+}
@@ -0,0 +1,319 @@
+package builder
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// maxShadowComparisons is the number of shadow comparisons kept
+	maxShadowComparisons = 64
+	// maxShadowMissingTxs is the number of landed transactions missing from our candidate listed in a comparison
+	maxShadowMissingTxs = 256
+)
+
+var (
+	shadowOutbidMeter        = metrics.NewRegisteredMeter("builder/shadow/outbid", nil)
+	shadowOutbiddenMeter     = metrics.NewRegisteredMeter("builder/shadow/outbidden", nil)
+	shadowValueDiffHistogram = metrics.NewRegisteredHistogram("builder/shadow/value_diff", nil, metrics.NewExpDecaySample(1028, 0.015))
+	shadowTxOverlapHistogram = metrics.NewRegisteredHistogram("builder/shadow/tx_overlap", nil, metrics.NewExpDecaySample(1028, 0.015))
+	shadowBundleHistogram    = metrics.NewRegisteredHistogram("builder/shadow/bundle_overlap", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// ShadowComparison compares the best candidate built for a slot in shadow mode with the block
+// that landed in the slot.
+type ShadowComparison struct {
+	Slot        uint64      `json:"slot"`
+	BlockNumber uint64      `json:"blockNumber"`
+	ParentHash  common.Hash `json:"parentHash"`
+	Candidates  int         `json:"candidates"` // Candidates built for the slot
+
+	CandidateHash  common.Hash    `json:"candidateHash"`
+	CandidateValue *hexutil.Big   `json:"candidateValue"`
+	LandedHash     common.Hash    `json:"landedHash"`
+	LandedCoinbase common.Address `json:"landedCoinbase"`
+	// LandedValue is the payment to the proposer in the landed block, unknown if the block has no
+	// payment transaction to the proposer fee recipient
+	LandedValue *hexutil.Big `json:"landedValue,omitempty"`
+	// ValueDiff is our candidate value minus the landed value, if the landed value is known
+	ValueDiff *hexutil.Big `json:"valueDiff,omitempty"`
+	// Outbid is true if our candidate would have paid the proposer more than the landed block
+	Outbid bool `json:"outbid"`
+
+	CandidateTxs int `json:"candidateTxs"`
+	LandedTxs    int `json:"landedTxs"`
+	SharedTxs    int `json:"sharedTxs"`
+	// MissingTxs are the landed transactions not included in our candidate
+	MissingTxs []common.Hash `json:"missingTxs"`
+
+	CandidateBundles int `json:"candidateBundles"`
+	// LandedBundles is the number of bundles of our candidate with all their transactions landed
+	LandedBundles int `json:"landedBundles"`
+}
+
+// ShadowSummary totals the shadow comparisons since the builder started.
+type ShadowSummary struct {
+	Slots int `json:"slots"` // Slots compared with the block that landed
+	// Priced is the number of compared slots whose landed value is known, the values below
+	// total these slots
+	Priced         int          `json:"priced"`
+	Outbid         int          `json:"outbid"` // Slots our candidate would have won
+	CandidateValue *hexutil.Big `json:"candidateValue"`
+	LandedValue    *hexutil.Big `json:"landedValue"`
+	// WonValue is the value our candidates would have paid in the slots they would have won
+	WonValue *hexutil.Big `json:"wonValue"`
+
+	SharedTxs int `json:"sharedTxs"`
+	LandedTxs int `json:"landedTxs"`
+}
+
+// add adds a compared slot to the summary.
+func (s *ShadowSummary) add(comparison *ShadowComparison) {
+	s.Slots++
+	s.SharedTxs += comparison.SharedTxs
+	s.LandedTxs += comparison.LandedTxs
+	if comparison.LandedValue == nil {
+		return
+	}
+	s.Priced++
+	s.CandidateValue = addBig(s.CandidateValue, comparison.CandidateValue)
+	s.LandedValue = addBig(s.LandedValue, comparison.LandedValue)
+	if comparison.Outbid {
+		s.Outbid++
+		s.WonValue = addBig(s.WonValue, comparison.CandidateValue)
+	}
+}
+
+// addBig returns a new total of a and b, nil totals are 0.
+func addBig(a, b *hexutil.Big) *hexutil.Big {
+	total := new(big.Int)
+	if a != nil {
+		total.Set(a.ToInt())
+	}
+	if b != nil {
+		total.Add(total, b.ToInt())
+	}
+	return (*hexutil.Big)(total)
+}
+
+// shadowCandidate is the best candidate built for a slot in shadow mode.
+type shadowCandidate struct {
+	number       uint64
+	parent       common.Hash
+	feeRecipient common.Address
+	candidates   int
+
+	hash    common.Hash
+	value   *big.Int
+	txs     map[common.Hash]struct{}
+	bundles [][]common.Hash // Transactions of the committed bundles
+}
+
+// shadowTracker runs the builder in shadow mode, it keeps the best candidate built for each
+// slot instead of submitting it, and compares it with the block that landed once the chain has
+// moved past the slot.
+type shadowTracker struct {
+	mu          sync.Mutex
+	slots       map[uint64]*shadowCandidate
+	comparisons []ShadowComparison // Oldest first
+	summary     ShadowSummary
+}
+
+// newShadowTracker returns the tracker of the shadow mode, the builder is in shadow mode if it is not nil.
+func newShadowTracker() *shadowTracker {
+	return &shadowTracker{slots: make(map[uint64]*shadowCandidate)}
+}
+
+// candidate records a block that would have been submitted for the slot.
+func (t *shadowTracker) candidate(slot uint64, block *types.Block, value *big.Int, feeRecipient common.Address, committedBundles []types.SimulatedBundle) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	candidate, ok := t.slots[slot]
+	if !ok {
+		candidate = new(shadowCandidate)
+		t.slots[slot] = candidate
+	}
+	candidate.candidates++
+	if candidate.value != nil && value.Cmp(candidate.value) <= 0 {
+		return
+	}
+	candidate.number = block.NumberU64()
+	candidate.parent = block.ParentHash()
+	candidate.feeRecipient = feeRecipient
+	candidate.hash = block.Hash()
+	candidate.value = new(big.Int).Set(value)
+	candidate.txs = make(map[common.Hash]struct{}, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		candidate.txs[tx.Hash()] = struct{}{}
+	}
+	candidate.bundles = make([][]common.Hash, 0, len(committedBundles))
+	for _, bundle := range committedBundles {
+		hashes := make([]common.Hash, 0, len(bundle.OriginalBundle.Txs))
+		for _, tx := range bundle.OriginalBundle.Txs {
+			hashes = append(hashes, tx.Hash())
+		}
+		candidate.bundles = append(candidate.bundles, hashes)
+	}
+}
+
+// resolve compares the candidates of the slots before the given one with the blocks of the
+// chain ending at head.
+func (t *shadowTracker) resolve(slot uint64, head *types.Block, getBlock func(common.Hash) *types.Block) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.slots) == 0 {
+		return
+	}
+	canonical := make(map[uint64]*types.Block)
+	for block, depth := head, 0; block != nil && depth <= maxSlotWinDepth; depth++ {
+		canonical[block.NumberU64()] = block
+		if block.NumberU64() == 0 {
+			break
+		}
+		block = getBlock(block.ParentHash())
+	}
+
+	// slots are compared in order to keep the comparisons sorted
+	settled := make([]uint64, 0, len(t.slots))
+	for s := range t.slots {
+		if s < slot {
+			settled = append(settled, s)
+		}
+	}
+	sort.Slice(settled, func(i, j int) bool { return settled[i] < settled[j] })
+	for _, s := range settled {
+		candidate := t.slots[s]
+		delete(t.slots, s)
+		landed, ok := canonical[candidate.number]
+		if !ok {
+			// The block at the candidate height is too deep or the head did not reach it
+			continue
+		}
+		comparison := candidate.compare(s, landed)
+		markShadowComparison(&comparison)
+		t.summary.add(&comparison)
+		log.Info("Shadow slot", "slot", s, "number", comparison.BlockNumber, "landed", landed.Hash(), "coinbase", landed.Coinbase(),
+			"value", comparison.CandidateValue, "landedValue", comparison.LandedValue, "outbid", comparison.Outbid,
+			"sharedTxs", comparison.SharedTxs, "landedTxs", comparison.LandedTxs,
+			"landedBundles", comparison.LandedBundles, "bundles", comparison.CandidateBundles,
+			"won", t.summary.Outbid, "priced", t.summary.Priced)
+		t.comparisons = append(t.comparisons, comparison)
+		if len(t.comparisons) > maxShadowComparisons {
+			t.comparisons = t.comparisons[len(t.comparisons)-maxShadowComparisons:]
+		}
+	}
+}
+
+// compare compares the candidate with the block that landed in the slot.
+func (c *shadowCandidate) compare(slot uint64, landed *types.Block) ShadowComparison {
+	txs := landed.Transactions()
+	comparison := ShadowComparison{
+		Slot:             slot,
+		BlockNumber:      c.number,
+		ParentHash:       c.parent,
+		Candidates:       c.candidates,
+		CandidateHash:    c.hash,
+		CandidateValue:   (*hexutil.Big)(c.value),
+		LandedHash:       landed.Hash(),
+		LandedCoinbase:   landed.Coinbase(),
+		CandidateTxs:     len(c.txs),
+		LandedTxs:        len(txs),
+		MissingTxs:       make([]common.Hash, 0),
+		CandidateBundles: len(c.bundles),
+	}
+	// Blocks of other builders pay the proposer with their last transaction
+	if n := len(txs); n > 0 {
+		if to := txs[n-1].To(); to != nil && *to == c.feeRecipient {
+			landedValue := new(big.Int).Set(txs[n-1].Value())
+			comparison.LandedValue = (*hexutil.Big)(landedValue)
+			comparison.ValueDiff = (*hexutil.Big)(new(big.Int).Sub(c.value, landedValue))
+			comparison.Outbid = c.value.Cmp(landedValue) > 0
+		}
+	}
+
+	landedTxs := make(map[common.Hash]struct{}, len(txs))
+	for _, tx := range txs {
+		landedTxs[tx.Hash()] = struct{}{}
+		if _, ok := c.txs[tx.Hash()]; ok {
+			comparison.SharedTxs++
+		} else if len(comparison.MissingTxs) < maxShadowMissingTxs {
+			comparison.MissingTxs = append(comparison.MissingTxs, tx.Hash())
+		}
+	}
+	for _, bundle := range c.bundles {
+		included := true
+		for _, hash := range bundle {
+			if _, ok := landedTxs[hash]; !ok {
+				included = false
+				break
+			}
+		}
+		if included {
+			comparison.LandedBundles++
+		}
+	}
+	return comparison
+}
+
+// list returns the recent shadow comparisons, most recent first, empty if shadow mode is disabled.
+func (t *shadowTracker) list() []ShadowComparison {
+	if t == nil {
+		return []ShadowComparison{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	comparisons := make([]ShadowComparison, 0, len(t.comparisons))
+	for i := len(t.comparisons) - 1; i >= 0; i-- {
+		comparisons = append(comparisons, t.comparisons[i])
+	}
+	return comparisons
+}
+
+// getSummary returns the totals of the shadow comparisons, empty if shadow mode is disabled.
+func (t *shadowTracker) getSummary() ShadowSummary {
+	if t == nil {
+		return ShadowSummary{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.summary
+}
+
+// markShadowComparison updates the shadow metrics of a compared slot
+func markShadowComparison(comparison *ShadowComparison) {
+	if !metrics.EnabledBuilder {
+		return
+	}
+	if comparison.LandedTxs > 0 {
+		shadowTxOverlapHistogram.Update(int64(comparison.SharedTxs * 100 / comparison.LandedTxs))
+	}
+	if comparison.CandidateBundles > 0 {
+		shadowBundleHistogram.Update(int64(comparison.LandedBundles * 100 / comparison.CandidateBundles))
+	}
+	if comparison.ValueDiff == nil {
+		return
+	}
+	shadowValueDiffHistogram.Update(weiToGwei(comparison.ValueDiff.ToInt()))
+	if comparison.Outbid {
+		shadowOutbidMeter.Mark(1)
+	} else {
+		shadowOutbiddenMeter.Mark(1)
+	}
+}
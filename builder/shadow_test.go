@@ -0,0 +1,112 @@
+package builder
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShadowTracker(t *testing.T) {
+	var (
+		feeRecipient = common.Address{0xfe}
+		shared       = types.NewTx(&types.LegacyTx{Nonce: 1})
+		bundled      = types.NewTx(&types.LegacyTx{Nonce: 2})
+		missing      = types.NewTx(&types.LegacyTx{Nonce: 3})
+		payment      = types.NewTx(&types.LegacyTx{Nonce: 4, To: &feeRecipient, Value: big.NewInt(70)})
+	)
+
+	chain := make(map[common.Hash]*types.Block)
+	parent := common.Hash{}
+	var blocks []*types.Block
+	for i := int64(0); i < 5; i++ {
+		header := &types.Header{Number: big.NewInt(i), ParentHash: parent}
+		var txs []*types.Transaction
+		switch i {
+		case 3:
+			header.Coinbase = common.Address{0xcb}
+			txs = []*types.Transaction{shared, bundled, missing, payment}
+		case 4:
+			txs = []*types.Transaction{shared}
+		}
+		block := types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+		chain[block.Hash()] = block
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	getBlock := func(hash common.Hash) *types.Block { return chain[hash] }
+
+	newCandidate := func(number int64, value int64, txs ...*types.Transaction) *types.Block {
+		header := &types.Header{Number: big.NewInt(number), ParentHash: blocks[number-1].Hash(), Extra: []byte{byte(value)}}
+		return types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+	}
+	bundles := []types.SimulatedBundle{
+		{OriginalBundle: types.MevBundle{Txs: types.Transactions{bundled}}},
+		{OriginalBundle: types.MevBundle{Txs: types.Transactions{bundled, types.NewTx(&types.LegacyTx{Nonce: 5})}}},
+	}
+
+	tracker := newShadowTracker()
+	best := newCandidate(3, 100, shared, bundled)
+	tracker.candidate(11, newCandidate(3, 50, shared), big.NewInt(50), feeRecipient, nil)
+	tracker.candidate(11, best, big.NewInt(100), feeRecipient, bundles)
+	tracker.candidate(11, newCandidate(3, 60, shared), big.NewInt(60), feeRecipient, nil)
+	tracker.candidate(12, newCandidate(4, 10), big.NewInt(10), common.Address{0xaa}, nil)
+
+	// slots are only compared once the chain moved past them
+	tracker.resolve(11, blocks[4], getBlock)
+	require.Empty(t, tracker.list())
+
+	tracker.resolve(13, blocks[4], getBlock)
+	require.Empty(t, tracker.slots)
+	comparisons := tracker.list()
+	require.Len(t, comparisons, 2)
+
+	// the landed block has no payment to the fee recipient, its value is unknown
+	unknown := comparisons[0]
+	require.Equal(t, uint64(12), unknown.Slot)
+	require.Equal(t, blocks[4].Hash(), unknown.LandedHash)
+	require.Nil(t, unknown.LandedValue)
+	require.Nil(t, unknown.ValueDiff)
+	require.False(t, unknown.Outbid)
+	require.Equal(t, []common.Hash{shared.Hash()}, unknown.MissingTxs)
+
+	comparison := comparisons[1]
+	require.Equal(t, uint64(11), comparison.Slot)
+	require.Equal(t, uint64(3), comparison.BlockNumber)
+	require.Equal(t, blocks[2].Hash(), comparison.ParentHash)
+	require.Equal(t, 3, comparison.Candidates)
+	require.Equal(t, best.Hash(), comparison.CandidateHash)
+	require.Equal(t, big.NewInt(100), comparison.CandidateValue.ToInt())
+	require.Equal(t, blocks[3].Hash(), comparison.LandedHash)
+	require.Equal(t, common.Address{0xcb}, comparison.LandedCoinbase)
+	require.Equal(t, big.NewInt(70), comparison.LandedValue.ToInt())
+	require.Equal(t, big.NewInt(30), comparison.ValueDiff.ToInt())
+	require.True(t, comparison.Outbid)
+	require.Equal(t, 2, comparison.CandidateTxs)
+	require.Equal(t, 4, comparison.LandedTxs)
+	require.Equal(t, 2, comparison.SharedTxs)
+	require.Equal(t, []common.Hash{missing.Hash(), payment.Hash()}, comparison.MissingTxs)
+	require.Equal(t, 2, comparison.CandidateBundles)
+	require.Equal(t, 1, comparison.LandedBundles)
+
+	// only the slot with a known landed value is priced
+	summary := tracker.getSummary()
+	require.Equal(t, 2, summary.Slots)
+	require.Equal(t, 1, summary.Priced)
+	require.Equal(t, 1, summary.Outbid)
+	require.Equal(t, big.NewInt(100), summary.CandidateValue.ToInt())
+	require.Equal(t, big.NewInt(70), summary.LandedValue.ToInt())
+	require.Equal(t, big.NewInt(100), summary.WonValue.ToInt())
+	require.Equal(t, 2, summary.SharedTxs)
+	require.Equal(t, 5, summary.LandedTxs)
+
+	// shadow mode disabled
+	var disabled *shadowTracker
+	disabled.candidate(11, best, big.NewInt(100), feeRecipient, bundles)
+	disabled.resolve(12, blocks[4], getBlock)
+	require.Empty(t, disabled.list())
+	require.Equal(t, ShadowSummary{}, disabled.getSummary())
+}
@@ -0,0 +1,366 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/flashbotsextra"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// maxSlotWinDepth is the number of blocks walked back from the head to find the blocks of past slots
+	maxSlotWinDepth = 64
+	// maxPostmortems is the number of missed slot postmortems kept
+	maxPostmortems = 64
+	// maxPostmortemErrors is the number of distinct relay errors kept per slot
+	maxPostmortemErrors = 8
+	// maxPostmortemTxs is the number of competitor transactions missing from our block listed in a postmortem
+	maxPostmortemTxs = 256
+)
+
+// SlotPostmortem describes a slot the builder submitted blocks for that landed another block.
+type SlotPostmortem struct {
+	Slot                 uint64         `json:"slot"`
+	BlockNumber          uint64         `json:"blockNumber"`
+	ParentHash           common.Hash    `json:"parentHash"`
+	ProposerFeeRecipient common.Address `json:"proposerFeeRecipient"`
+
+	// Pipeline stage timings
+	AttributesReceived time.Time `json:"attributesReceived"`
+	FirstBlockSealed   time.Time `json:"firstBlockSealed"`
+	FirstSubmission    time.Time `json:"firstSubmission"`
+	LastSubmission     time.Time `json:"lastSubmission"`
+
+	// Relay responses
+	Submissions       int      `json:"submissions"`
+	FailedSubmissions int      `json:"failedSubmissions"`
+	RelayErrors       []string `json:"relayErrors,omitempty"`
+
+	BestBlockHash common.Hash  `json:"bestBlockHash"`
+	BestBid       *hexutil.Big `json:"bestBid,omitempty"`
+
+	Competitor *CompetitorBlock `json:"competitor,omitempty"`
+}
+
+// CompetitorBlock is the block that landed in a missed slot.
+type CompetitorBlock struct {
+	Hash            common.Hash    `json:"hash"`
+	Coinbase        common.Address `json:"coinbase"`
+	ExtraData       hexutil.Bytes  `json:"extraData"`
+	GasUsed         uint64         `json:"gasUsed"`
+	Transactions    int            `json:"transactions"`
+	ProposerPayment *hexutil.Big   `json:"proposerPayment,omitempty"`
+	// SharedTransactions is the number of transactions also included in our best block
+	SharedTransactions int `json:"sharedTransactions"`
+	// MissingTransactions are the transactions not included in our best block
+	MissingTransactions []common.Hash `json:"missingTransactions"`
+}
+
+// slotRecord is the activity of the builder in a slot.
+type slotRecord struct {
+	number       uint64
+	parent       common.Hash
+	feeRecipient common.Address
+
+	attributesAt      time.Time
+	firstSealedAt     time.Time
+	firstSubmissionAt time.Time
+	lastSubmissionAt  time.Time
+
+	submissions int
+	failed      int
+	relayErrors []string
+
+	hashes    map[common.Hash]struct{} // Blocks successfully submitted for the slot
+	bestHash  common.Hash
+	bestValue *big.Int
+	bestTxs   map[common.Hash]struct{}
+}
+
+// slotTracker records the blocks submitted for each slot and checks whether one of them was
+// included once the chain has moved past the slot. Slots that landed another block get a
+// postmortem.
+type slotTracker struct {
+	mu          sync.Mutex
+	slots       map[uint64]*slotRecord
+	postmortems []SlotPostmortem
+	won, lost   uint64
+
+	ds flashbotsextra.IDatabaseService // Persists the slot outcomes, nil if not persisted
+}
+
+func newSlotTracker() *slotTracker {
+	return &slotTracker{slots: make(map[uint64]*slotRecord)}
+}
+
+// restore sets the database the slot outcomes are persisted to, and loads the outcome
+// counts and missed slot postmortems persisted before a restart.
+func (t *slotTracker) restore(ds flashbotsextra.IDatabaseService) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 12*time.Second)
+	defer cancel()
+
+	won, lost, err := ds.GetSlotOutcomeCounts(ctx)
+	if err != nil {
+		return err
+	}
+	missed, err := ds.GetMissedSlots(ctx, maxPostmortems)
+	if err != nil {
+		return err
+	}
+	postmortems := make([]SlotPostmortem, 0, len(missed))
+	for i := len(missed) - 1; i >= 0; i-- {
+		if missed[i].Postmortem == nil {
+			continue
+		}
+		var postmortem SlotPostmortem
+		if err := json.Unmarshal([]byte(*missed[i].Postmortem), &postmortem); err != nil {
+			return fmt.Errorf("invalid postmortem of slot %d: %w", missed[i].Slot, err)
+		}
+		postmortems = append(postmortems, postmortem)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.ds = ds
+	t.won, t.lost = t.won+won, t.lost+lost
+	t.postmortems = append(postmortems, t.postmortems...)
+	if t.won+t.lost > 0 {
+		slotWinRateGauge.Update(float64(t.won) / float64(t.won+t.lost))
+	}
+	return nil
+}
+
+func (t *slotTracker) record(slot uint64) *slotRecord {
+	record, ok := t.slots[slot]
+	if !ok {
+		record = &slotRecord{hashes: make(map[common.Hash]struct{})}
+		t.slots[slot] = record
+	}
+	return record
+}
+
+// attributes records the payload attributes of the slot being built on top of parent.
+func (t *slotTracker) attributes(slot uint64, parent *types.Block, feeRecipient common.Address) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record := t.record(slot)
+	if record.attributesAt.IsZero() {
+		record.attributesAt = time.Now()
+	}
+	record.number = parent.NumberU64() + 1
+	record.parent = parent.Hash()
+	record.feeRecipient = feeRecipient
+}
+
+// sealed records a block sealed for the slot.
+func (t *slotTracker) sealed(slot uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record := t.record(slot)
+	if record.firstSealedAt.IsZero() {
+		record.firstSealedAt = time.Now()
+	}
+}
+
+// submitted records the relay response to a block submitted for the slot.
+func (t *slotTracker) submitted(slot uint64, block *types.Block, value *big.Int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record := t.record(slot)
+	now := time.Now()
+	if record.firstSubmissionAt.IsZero() {
+		record.firstSubmissionAt = now
+	}
+	record.lastSubmissionAt = now
+	record.submissions++
+	record.number = block.NumberU64()
+
+	if err != nil {
+		record.failed++
+		msg := err.Error()
+		for _, known := range record.relayErrors {
+			if known == msg {
+				return
+			}
+		}
+		if len(record.relayErrors) < maxPostmortemErrors {
+			record.relayErrors = append(record.relayErrors, msg)
+		}
+		return
+	}
+
+	record.hashes[block.Hash()] = struct{}{}
+	if record.bestValue == nil || value.Cmp(record.bestValue) > 0 {
+		record.bestHash = block.Hash()
+		record.bestValue = new(big.Int).Set(value)
+		record.bestTxs = make(map[common.Hash]struct{}, len(block.Transactions()))
+		for _, tx := range block.Transactions() {
+			record.bestTxs[tx.Hash()] = struct{}{}
+		}
+	}
+}
+
+// resolve settles the slots before the given one using the chain ending at head. A slot
+// is won if the canonical block at the submitted height is one of the submitted blocks.
+func (t *slotTracker) resolve(slot uint64, head *types.Block, getBlock func(common.Hash) *types.Block) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	canonical := make(map[uint64]*types.Block)
+	for block, depth := head, 0; block != nil && depth <= maxSlotWinDepth; depth++ {
+		canonical[block.NumberU64()] = block
+		if block.NumberU64() == 0 {
+			break
+		}
+		block = getBlock(block.ParentHash())
+	}
+
+	for s, record := range t.slots {
+		if s >= slot {
+			continue
+		}
+		delete(t.slots, s)
+		if record.submissions == 0 {
+			continue
+		}
+		landed, ok := canonical[record.number]
+		if !ok {
+			// The block at the submitted height is too deep or the head did not reach it
+			continue
+		}
+		if _, won := record.hashes[landed.Hash()]; won {
+			t.won++
+			slotWonMeter.Mark(1)
+			t.persist(record.outcome(s, landed, nil))
+			continue
+		}
+		if len(record.hashes) > 0 {
+			t.lost++
+			slotLostMeter.Mark(1)
+		}
+
+		postmortem := record.postmortem(s, landed)
+		t.persist(record.outcome(s, landed, &postmortem))
+		log.Info("Missed slot", "slot", s, "number", record.number, "landed", landed.Hash(), "coinbase", landed.Coinbase(),
+			"submissions", record.submissions, "failed", record.failed)
+		t.postmortems = append(t.postmortems, postmortem)
+		if len(t.postmortems) > maxPostmortems {
+			t.postmortems = t.postmortems[len(t.postmortems)-maxPostmortems:]
+		}
+	}
+	if t.won+t.lost > 0 {
+		slotWinRateGauge.Update(float64(t.won) / float64(t.won+t.lost))
+	}
+}
+
+// persist writes the outcome of a slot to the database in the background.
+func (t *slotTracker) persist(outcome *flashbotsextra.SlotOutcome) {
+	if t.ds != nil {
+		go t.ds.ConsumeSlotOutcome(outcome)
+	}
+}
+
+// outcome returns the outcome of the slot given the block that landed in it, and the
+// postmortem if the slot was missed.
+func (r *slotRecord) outcome(slot uint64, landed *types.Block, postmortem *SlotPostmortem) *flashbotsextra.SlotOutcome {
+	outcome := &flashbotsextra.SlotOutcome{
+		Slot:                 slot,
+		BlockNumber:          r.number,
+		ParentHash:           r.parent.String(),
+		ProposerFeeRecipient: r.feeRecipient.String(),
+		Submissions:          r.submissions,
+		FailedSubmissions:    r.failed,
+		SubmittedBlocks:      len(r.hashes),
+		BestBlockHash:        r.bestHash.String(),
+		BestBid:              "0",
+		LandedBlockHash:      landed.Hash().String(),
+		Won:                  postmortem == nil,
+		SettledAt:            time.Now().UTC(),
+	}
+	if r.bestValue != nil {
+		outcome.BestBid = new(big.Rat).SetFrac(r.bestValue, big.NewInt(1e18)).FloatString(18)
+	}
+	if postmortem != nil {
+		if encoded, err := json.Marshal(postmortem); err == nil {
+			content := string(encoded)
+			outcome.Postmortem = &content
+		}
+	}
+	return outcome
+}
+
+// postmortem assembles the postmortem of the slot, given the block that landed instead of ours.
+func (r *slotRecord) postmortem(slot uint64, landed *types.Block) SlotPostmortem {
+	postmortem := SlotPostmortem{
+		Slot:                 slot,
+		BlockNumber:          r.number,
+		ParentHash:           r.parent,
+		ProposerFeeRecipient: r.feeRecipient,
+		AttributesReceived:   r.attributesAt,
+		FirstBlockSealed:     r.firstSealedAt,
+		FirstSubmission:      r.firstSubmissionAt,
+		LastSubmission:       r.lastSubmissionAt,
+		Submissions:          r.submissions,
+		FailedSubmissions:    r.failed,
+		RelayErrors:          r.relayErrors,
+		BestBlockHash:        r.bestHash,
+	}
+	if r.bestValue != nil {
+		postmortem.BestBid = (*hexutil.Big)(r.bestValue)
+	}
+
+	txs := landed.Transactions()
+	competitor := &CompetitorBlock{
+		Hash:                landed.Hash(),
+		Coinbase:            landed.Coinbase(),
+		ExtraData:           landed.Extra(),
+		GasUsed:             landed.GasUsed(),
+		Transactions:        len(txs),
+		MissingTransactions: make([]common.Hash, 0),
+	}
+	if n := len(txs); n > 0 {
+		if to := txs[n-1].To(); to != nil && *to == r.feeRecipient {
+			competitor.ProposerPayment = (*hexutil.Big)(new(big.Int).Set(txs[n-1].Value()))
+		}
+	}
+	for _, tx := range txs {
+		if _, ok := r.bestTxs[tx.Hash()]; ok {
+			competitor.SharedTransactions++
+		} else if len(competitor.MissingTransactions) < maxPostmortemTxs {
+			competitor.MissingTransactions = append(competitor.MissingTransactions, tx.Hash())
+		}
+	}
+	postmortem.Competitor = competitor
+	return postmortem
+}
+
+// outcomes returns the number of won and lost slots.
+func (t *slotTracker) outcomes() (won, lost uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.won, t.lost
+}
+
+// missedSlots returns the postmortems of the recent missed slots, most recent first.
+func (t *slotTracker) missedSlots() []SlotPostmortem {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	postmortems := make([]SlotPostmortem, 0, len(t.postmortems))
+	for i := len(t.postmortems) - 1; i >= 0; i-- {
+		postmortems = append(postmortems, t.postmortems[i])
+	}
+	return postmortems
+}
@@ -0,0 +1,158 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/flashbotsextra"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlotTracker(t *testing.T) {
+	var (
+		feeRecipient = common.Address{0xfe}
+		shared       = types.NewTx(&types.LegacyTx{Nonce: 1})
+		exclusive    = types.NewTx(&types.LegacyTx{Nonce: 2})
+		payment      = types.NewTx(&types.LegacyTx{Nonce: 3, To: &feeRecipient, Value: big.NewInt(77)})
+	)
+
+	chain := make(map[common.Hash]*types.Block)
+	parent := common.Hash{}
+	var blocks []*types.Block
+	for i := int64(0); i < 5; i++ {
+		header := &types.Header{Number: big.NewInt(i), ParentHash: parent}
+		var txs []*types.Transaction
+		if i == 3 {
+			header.Coinbase = common.Address{0xcb}
+			txs = []*types.Transaction{shared, exclusive, payment}
+		}
+		block := types.NewBlock(header, txs, nil, nil, trie.NewStackTrie(nil))
+		chain[block.Hash()] = block
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	getBlock := func(hash common.Hash) *types.Block { return chain[hash] }
+	ours := types.NewBlock(&types.Header{Number: big.NewInt(3), ParentHash: blocks[2].Hash(), Extra: []byte{1}}, []*types.Transaction{shared}, nil, nil, trie.NewStackTrie(nil))
+
+	tracker := newSlotTracker()
+	tracker.attributes(10, blocks[1], feeRecipient)
+	tracker.submitted(10, blocks[2], big.NewInt(1), nil)
+
+	tracker.attributes(11, blocks[2], feeRecipient)
+	tracker.sealed(11)
+	tracker.submitted(11, ours, big.NewInt(5), errors.New("relay timeout"))
+	tracker.submitted(11, ours, big.NewInt(5), nil)
+
+	tracker.attributes(12, blocks[3], feeRecipient)
+	tracker.submitted(12, blocks[4], big.NewInt(1), nil)
+
+	// slots are only settled once the chain moved past them
+	tracker.resolve(12, blocks[4], getBlock)
+	won, lost := tracker.outcomes()
+	require.Equal(t, uint64(1), won)
+	require.Equal(t, uint64(1), lost)
+
+	tracker.resolve(13, blocks[4], getBlock)
+	won, lost = tracker.outcomes()
+	require.Equal(t, uint64(2), won)
+	require.Equal(t, uint64(1), lost)
+	require.Empty(t, tracker.slots)
+
+	missed := tracker.missedSlots()
+	require.Len(t, missed, 1)
+	postmortem := missed[0]
+	require.Equal(t, uint64(11), postmortem.Slot)
+	require.Equal(t, uint64(3), postmortem.BlockNumber)
+	require.Equal(t, 2, postmortem.Submissions)
+	require.Equal(t, 1, postmortem.FailedSubmissions)
+	require.Equal(t, []string{"relay timeout"}, postmortem.RelayErrors)
+	require.Equal(t, ours.Hash(), postmortem.BestBlockHash)
+	require.False(t, postmortem.FirstBlockSealed.IsZero())
+
+	competitor := postmortem.Competitor
+	require.NotNil(t, competitor)
+	require.Equal(t, blocks[3].Hash(), competitor.Hash)
+	require.Equal(t, common.Address{0xcb}, competitor.Coinbase)
+	require.Equal(t, int64(77), competitor.ProposerPayment.ToInt().Int64())
+	require.Equal(t, 1, competitor.SharedTransactions)
+	require.Equal(t, []common.Hash{exclusive.Hash(), payment.Hash()}, competitor.MissingTransactions)
+}
+
+// slotOutcomeStore keeps the persisted slot outcomes in memory.
+type slotOutcomeStore struct {
+	flashbotsextra.NilDbService
+	outcomes chan *flashbotsextra.SlotOutcome
+	stored   []flashbotsextra.SlotOutcome
+}
+
+func (s *slotOutcomeStore) ConsumeSlotOutcome(outcome *flashbotsextra.SlotOutcome) {
+	s.outcomes <- outcome
+}
+
+func (s *slotOutcomeStore) GetSlotOutcomeCounts(ctx context.Context) (won uint64, lost uint64, err error) {
+	for _, outcome := range s.stored {
+		if outcome.Won {
+			won++
+		} else if outcome.SubmittedBlocks > 0 {
+			lost++
+		}
+	}
+	return won, lost, nil
+}
+
+func (s *slotOutcomeStore) GetMissedSlots(ctx context.Context, limit int) ([]flashbotsextra.SlotOutcome, error) {
+	var missed []flashbotsextra.SlotOutcome
+	for i := len(s.stored) - 1; i >= 0 && len(missed) < limit; i-- {
+		if !s.stored[i].Won {
+			missed = append(missed, s.stored[i])
+		}
+	}
+	return missed, nil
+}
+
+func TestSlotTrackerRestore(t *testing.T) {
+	store := &slotOutcomeStore{outcomes: make(chan *flashbotsextra.SlotOutcome, 2)}
+
+	parent := types.NewBlock(&types.Header{Number: big.NewInt(1)}, nil, nil, nil, trie.NewStackTrie(nil))
+	ours := types.NewBlock(&types.Header{Number: big.NewInt(2), ParentHash: parent.Hash(), Extra: []byte{1}}, nil, nil, nil, trie.NewStackTrie(nil))
+	landed := types.NewBlock(&types.Header{Number: big.NewInt(2), ParentHash: parent.Hash()}, nil, nil, nil, trie.NewStackTrie(nil))
+	getBlock := func(hash common.Hash) *types.Block {
+		if hash == parent.Hash() {
+			return parent
+		}
+		return nil
+	}
+
+	tracker := newSlotTracker()
+	require.NoError(t, tracker.restore(store))
+	tracker.attributes(5, parent, common.Address{0xfe})
+	tracker.submitted(5, ours, big.NewInt(1e18), nil)
+	tracker.resolve(6, landed, getBlock)
+
+	outcome := <-store.outcomes
+	require.Equal(t, uint64(5), outcome.Slot)
+	require.False(t, outcome.Won)
+	require.Equal(t, 1, outcome.SubmittedBlocks)
+	require.Equal(t, "1.000000000000000000", outcome.BestBid)
+	require.Equal(t, landed.Hash().String(), outcome.LandedBlockHash)
+	require.NotNil(t, outcome.Postmortem)
+
+	// the outcomes survive a restart
+	store.stored = append(store.stored, *outcome, flashbotsextra.SlotOutcome{Slot: 4, Won: true, SubmittedBlocks: 1})
+	restored := newSlotTracker()
+	require.NoError(t, restored.restore(store))
+	won, lost := restored.outcomes()
+	require.Equal(t, uint64(1), won)
+	require.Equal(t, uint64(1), lost)
+	missed := restored.missedSlots()
+	require.Len(t, missed, 1)
+	require.Equal(t, uint64(5), missed[0].Slot)
+	require.Equal(t, ours.Hash(), missed[0].BestBlockHash)
+	require.Equal(t, landed.Hash(), missed[0].Competitor.Hash)
+	require.True(t, tracker.missedSlots()[0].AttributesReceived.Equal(missed[0].AttributesReceived))
+}
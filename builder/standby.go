@@ -0,0 +1,292 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+)
+
+// standbyPollInterval is the interval the standby checks the heartbeat of the active builder
+// and mirrors its bundle pool at
+const standbyPollInterval = time.Second
+
+var (
+	standbyPromotedGauge = metrics.NewRegisteredGauge("builder/standby/promoted", nil)
+	standbyMirroredMeter = metrics.NewRegisteredMeter("builder/standby/mirrored", nil)
+	standbyMissedMeter   = metrics.NewRegisteredMeter("builder/standby/missed", nil)
+)
+
+// StandbyStatus is the state of a standby builder.
+type StandbyStatus struct {
+	Active        string     `json:"active"` // Authenticated RPC of the active builder
+	Promoted      bool       `json:"promoted"`
+	PromotedAt    *time.Time `json:"promotedAt,omitempty"`
+	LastHeartbeat *time.Time `json:"lastHeartbeat,omitempty"`
+	Mirrored      uint64     `json:"mirrored"` // Bundles mirrored from the active builder
+}
+
+// activeBuilder is the builder a standby takes over from.
+type activeBuilder interface {
+	Status(ctx context.Context) (BuilderStatus, error)
+	ExportBundles(ctx context.Context) ([]ethapi.SendBundleArgs, error)
+}
+
+// standbyPool is the bundle pool the standby mirrors the bundles of the active builder into.
+type standbyPool interface {
+	PooledMevBundles() []types.MevBundle
+	AddMevBundle(txs types.Transactions, blockNumber, maxBlockNumber *big.Int, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp, maxTimestamp uint64, revertingTxHashes []common.Hash) error
+}
+
+// standby runs the builder as the hot standby of an active builder. The standby builds every
+// slot with its submissions paused, keeping its state and caches warm, and mirrors the bundle
+// pool of the active builder through its authenticated RPC. It takes over the submissions when
+// the heartbeat of the active builder stops for longer than the timeout, or when the active
+// builder is drained. A promoted standby stays active: the former active builder must be brought
+// back as the standby of the new one.
+type standby struct {
+	active  activeBuilder
+	url     string
+	pool    standbyPool
+	timeout time.Duration
+	promote func() // Lifts the submission pause of the builder, set by the builder
+
+	mu            sync.Mutex
+	lastHeartbeat time.Time
+	promotedAt    time.Time
+	mirrored      uint64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newStandby(active activeBuilder, url string, pool standbyPool, timeout time.Duration) *standby {
+	return &standby{
+		active:  active,
+		url:     url,
+		pool:    pool,
+		timeout: timeout,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start implements node.Lifecycle, starting the heartbeat checks. The timeout starts when the
+// standby starts, so an active builder that is already down is taken over after the timeout.
+func (s *standby) Start() error {
+	s.mu.Lock()
+	s.lastHeartbeat = time.Now()
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.loop()
+	log.Info("Builder running as standby, submissions are paused until the active builder is down", "active", s.url, "timeout", s.timeout)
+	return nil
+}
+
+// Stop implements node.Lifecycle.
+func (s *standby) Stop() error {
+	close(s.stop)
+	s.wg.Wait()
+	return nil
+}
+
+func (s *standby) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(standbyPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if s.poll(time.Now()) {
+				return
+			}
+		}
+	}
+}
+
+// poll checks the heartbeat of the active builder and mirrors its bundles, it returns true once
+// the standby is promoted.
+func (s *standby) poll(now time.Time) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), standbyPollInterval)
+	defer cancel()
+
+	status, err := s.active.Status(ctx)
+	if err != nil {
+		standbyMissedMeter.Mark(1)
+		log.Debug("Active builder heartbeat missed", "active", s.url, "err", err)
+	} else {
+		s.mu.Lock()
+		s.lastHeartbeat = now
+		s.mu.Unlock()
+		if status.Drained {
+			s.takeOver(now, "active builder drained")
+			return true
+		}
+		if err := s.mirror(ctx); err != nil {
+			log.Debug("Failed to mirror the bundles of the active builder", "active", s.url, "err", err)
+		}
+	}
+
+	s.mu.Lock()
+	silent := now.Sub(s.lastHeartbeat)
+	s.mu.Unlock()
+	if silent >= s.timeout {
+		s.takeOver(now, fmt.Sprintf("no heartbeat for %v", silent.Round(time.Millisecond)))
+		return true
+	}
+	return false
+}
+
+// mirror adds the bundles of the active builder missing from the local pool.
+func (s *standby) mirror(ctx context.Context) error {
+	bundles, err := s.active.ExportBundles(ctx)
+	if err != nil {
+		return err
+	}
+	type bundleKey struct {
+		hash        common.Hash
+		blockNumber uint64
+	}
+	pooled := make(map[bundleKey]struct{})
+	for _, bundle := range s.pool.PooledMevBundles() {
+		pooled[bundleKey{bundle.Hash, bundle.BlockNumber.Uint64()}] = struct{}{}
+	}
+
+	var mirrored uint64
+	for _, args := range bundles {
+		txs := make(types.Transactions, 0, len(args.Txs))
+		for _, encodedTx := range args.Txs {
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(encodedTx); err != nil {
+				return err
+			}
+			txs = append(txs, tx)
+		}
+		if len(txs) == 0 || args.BlockNumber < 0 {
+			continue
+		}
+		key := bundleKey{txpool.MevBundleHash(txs), uint64(args.BlockNumber)}
+		if _, ok := pooled[key]; ok {
+			continue
+		}
+		var (
+			maxBlockNumber             *big.Int
+			replacementUuid            uuid.UUID
+			signingAddress             common.Address
+			minTimestamp, maxTimestamp uint64
+		)
+		if args.MaxBlockNumber > 0 {
+			maxBlockNumber = big.NewInt(args.MaxBlockNumber.Int64())
+		}
+		if args.ReplacementUuid != nil {
+			replacementUuid = *args.ReplacementUuid
+		}
+		if args.SigningAddress != nil {
+			signingAddress = *args.SigningAddress
+		}
+		if args.MinTimestamp != nil {
+			minTimestamp = *args.MinTimestamp
+		}
+		if args.MaxTimestamp != nil {
+			maxTimestamp = *args.MaxTimestamp
+		}
+		if err := s.pool.AddMevBundle(txs, big.NewInt(args.BlockNumber.Int64()), maxBlockNumber, replacementUuid, signingAddress, minTimestamp, maxTimestamp, args.RevertingTxHashes); err != nil {
+			return err
+		}
+		pooled[key] = struct{}{}
+		mirrored++
+	}
+	standbyMirroredMeter.Mark(int64(mirrored))
+
+	s.mu.Lock()
+	s.mirrored += mirrored
+	s.mu.Unlock()
+	return nil
+}
+
+// takeOver promotes the standby to active builder.
+func (s *standby) takeOver(now time.Time, reason string) {
+	s.mu.Lock()
+	s.promotedAt = now
+	s.mu.Unlock()
+
+	standbyPromotedGauge.Update(1)
+	log.Warn("Standby builder taking over the block submissions", "active", s.url, "reason", reason)
+	if s.promote != nil {
+		s.promote()
+	}
+}
+
+func (s *standby) status() *StandbyStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := &StandbyStatus{
+		Active:   s.url,
+		Promoted: !s.promotedAt.IsZero(),
+		Mirrored: s.mirrored,
+	}
+	if status.Promoted {
+		promotedAt := s.promotedAt
+		status.PromotedAt = &promotedAt
+	}
+	if !s.lastHeartbeat.IsZero() {
+		lastHeartbeat := s.lastHeartbeat
+		status.LastHeartbeat = &lastHeartbeat
+	}
+	return status
+}
+
+// rpcActiveBuilder is an active builder reached through its authenticated RPC.
+type rpcActiveBuilder struct {
+	client *rpc.Client
+}
+
+// dialActiveBuilder connects to the authenticated RPC of the active builder with the JWT secret
+// at the given path.
+func dialActiveBuilder(url, jwtSecretPath string) (*rpcActiveBuilder, error) {
+	data, err := os.ReadFile(jwtSecretPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the JWT secret of the active builder: %w", err)
+	}
+	secret := common.FromHex(strings.TrimSpace(string(data)))
+	if len(secret) != 32 {
+		return nil, errors.New("invalid JWT secret of the active builder, expected 32 hex encoded bytes")
+	}
+	var key [32]byte
+	copy(key[:], secret)
+	client, err := rpc.DialOptions(context.Background(), url, rpc.WithHTTPAuth(node.NewJWTAuth(key)))
+	if err != nil {
+		return nil, err
+	}
+	return &rpcActiveBuilder{client: client}, nil
+}
+
+func (a *rpcActiveBuilder) Status(ctx context.Context) (BuilderStatus, error) {
+	var status BuilderStatus
+	err := a.client.CallContext(ctx, &status, "builder_status")
+	return status, err
+}
+
+func (a *rpcActiveBuilder) ExportBundles(ctx context.Context) ([]ethapi.SendBundleArgs, error) {
+	var bundles []ethapi.SendBundleArgs
+	err := a.client.CallContext(ctx, &bundles, "builder_exportBundles")
+	return bundles, err
+}
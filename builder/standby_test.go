@@ -0,0 +1,131 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type testActiveBuilder struct {
+	status  BuilderStatus
+	bundles []ethapi.SendBundleArgs
+	down    bool
+}
+
+func (a *testActiveBuilder) Status(ctx context.Context) (BuilderStatus, error) {
+	if a.down {
+		return BuilderStatus{}, errors.New("connection refused")
+	}
+	return a.status, nil
+}
+
+func (a *testActiveBuilder) ExportBundles(ctx context.Context) ([]ethapi.SendBundleArgs, error) {
+	if a.down {
+		return nil, errors.New("connection refused")
+	}
+	return a.bundles, nil
+}
+
+type testStandbyPool struct {
+	bundles []types.MevBundle
+}
+
+func (p *testStandbyPool) PooledMevBundles() []types.MevBundle { return p.bundles }
+
+func (p *testStandbyPool) AddMevBundle(txs types.Transactions, blockNumber, maxBlockNumber *big.Int, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
+	p.bundles = append(p.bundles, types.MevBundle{
+		Txs:               txs,
+		BlockNumber:       blockNumber,
+		MaxBlockNumber:    maxBlockNumber,
+		Uuid:              replacementUuid,
+		SigningAddress:    signingAddress,
+		MinTimestamp:      minTimestamp,
+		MaxTimestamp:      maxTimestamp,
+		RevertingTxHashes: revertingTxHashes,
+		Hash:              txpool.MevBundleHash(txs),
+	})
+	return nil
+}
+
+func testStandbyBundle(t *testing.T, nonce uint64, blockNumber int64) ethapi.SendBundleArgs {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	require.NoError(t, err)
+	encodedTx, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	return ethapi.SendBundleArgs{Txs: []hexutil.Bytes{encodedTx}, BlockNumber: rpc.BlockNumber(blockNumber)}
+}
+
+func TestStandbyMirror(t *testing.T) {
+	active := &testActiveBuilder{bundles: []ethapi.SendBundleArgs{testStandbyBundle(t, 0, 10), testStandbyBundle(t, 1, 11)}}
+	pool := new(testStandbyPool)
+	standby := newStandby(active, "http://active:8551", pool, 4*time.Second)
+
+	now := time.Now()
+	standby.lastHeartbeat = now
+	require.False(t, standby.poll(now.Add(time.Second)))
+	require.Len(t, pool.bundles, 2)
+	require.Equal(t, big.NewInt(10), pool.bundles[0].BlockNumber)
+
+	// the bundles already in the pool are not added again
+	active.bundles = append(active.bundles, testStandbyBundle(t, 2, 11))
+	require.False(t, standby.poll(now.Add(2*time.Second)))
+	require.Len(t, pool.bundles, 3)
+
+	status := standby.status()
+	require.Equal(t, "http://active:8551", status.Active)
+	require.False(t, status.Promoted)
+	require.Equal(t, uint64(3), status.Mirrored)
+	require.Equal(t, now.Add(2*time.Second), *status.LastHeartbeat)
+}
+
+func TestStandbyTakeOver(t *testing.T) {
+	active := new(testActiveBuilder)
+	builder := &Builder{}
+	standby := newStandby(active, "http://active:8551", new(testStandbyPool), 4*time.Second)
+	builder.standby = standby
+	builder.control.setSubmissionsPaused(true)
+	standby.promote = func() { builder.control.setSubmissionsPaused(false) }
+
+	now := time.Now()
+	standby.lastHeartbeat = now
+	require.False(t, standby.poll(now.Add(time.Second)))
+
+	// missed heartbeats within the timeout keep the standby passive
+	active.down = true
+	require.False(t, standby.poll(now.Add(3*time.Second)))
+	require.False(t, builder.control.submissionsAllowed())
+
+	require.True(t, standby.poll(now.Add(5*time.Second)))
+	require.True(t, builder.control.submissionsAllowed())
+
+	status := builder.Status()
+	require.False(t, status.SubmissionsPaused)
+	require.True(t, status.Standby.Promoted)
+	require.Equal(t, now.Add(5*time.Second), *status.Standby.PromotedAt)
+}
+
+func TestStandbyTakeOverDrained(t *testing.T) {
+	active := &testActiveBuilder{status: BuilderStatus{Draining: true, Drained: true}}
+	promoted := false
+	standby := newStandby(active, "http://active:8551", new(testStandbyPool), 4*time.Second)
+	standby.promote = func() { promoted = true }
+
+	now := time.Now()
+	standby.lastHeartbeat = now
+	require.True(t, standby.poll(now.Add(time.Second)))
+	require.True(t, promoted)
+}
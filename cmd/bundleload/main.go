@@ -0,0 +1,146 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// bundleload sends synthetic bundle traffic to a builder.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/builder/bundleload"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	endpoint      = flag.String("rpc", "http://127.0.0.1:8545", "RPC endpoint of the builder the bundles are sent to")
+	arbitrageRate = flag.Float64("arbitrage-rate", bundleload.DefaultConfig.ArbitrageRate, "Arbitrage bundles sent per second")
+	backrunRate   = flag.Float64("backrun-rate", bundleload.DefaultConfig.BackrunRate, "Backrun bundles sent per second")
+	spamRate      = flag.Float64("spam-rate", bundleload.DefaultConfig.SpamRate, "Spam bundles sent per second")
+	pools         = flag.String("pools", "", "Comma separated addresses of the pools the swaps are sent to (default: random addresses)")
+	accounts      = flag.Int("accounts", 16, "Number of accounts signing the bundles")
+	accountSeed   = flag.String("account-seed", "bundleload", "Seed the accounts are derived from")
+	printAccounts = flag.Bool("print-accounts", false, "Print the addresses of the accounts to fund and exit")
+	maxInFlight   = flag.Int("max-inflight", bundleload.DefaultConfig.MaxInFlight, "Maximum number of bundles waiting for an answer, bundles above it are dropped")
+	duration      = flag.Duration("duration", 0, "Duration of the load (0 = until interrupted)")
+	report        = flag.Duration("report", 10*time.Second, "Interval of the progress reports")
+	seed          = flag.Int64("seed", time.Now().UnixNano(), "Seed of the random bundle contents")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "[options]")
+		fmt.Fprintln(os.Stderr, `
+Sends synthetic arbitrage, backrun and spam bundles to a builder with
+eth_sendBundle at the given rates, for capacity planning and soak testing.
+The bundles are signed by accounts derived from --account-seed, which need
+to be funded for the bundles to be valid (see --print-accounts).`)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Error: too many arguments")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *arbitrageRate < 0 || *backrunRate < 0 || *spamRate < 0 {
+		die("invalid negative rate")
+	}
+	if *maxInFlight <= 0 {
+		die("invalid max in-flight bundles", *maxInFlight)
+	}
+
+	keys := bundleload.Accounts(*accountSeed, *accounts)
+	if *printAccounts {
+		for _, key := range keys {
+			fmt.Println(crypto.PubkeyToAddress(key.PublicKey).Hex())
+		}
+		return
+	}
+
+	cfg := bundleload.DefaultConfig
+	cfg.ArbitrageRate, cfg.BackrunRate, cfg.SpamRate = *arbitrageRate, *backrunRate, *spamRate
+	cfg.MaxInFlight = *maxInFlight
+	cfg.Seed = *seed
+	if *pools != "" {
+		for _, pool := range strings.Split(*pools, ",") {
+			if !common.IsHexAddress(pool) {
+				die("invalid pool address", pool)
+			}
+			cfg.Pools = append(cfg.Pools, common.HexToAddress(pool))
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if *duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	client, err := rpc.DialContext(ctx, *endpoint)
+	if err != nil {
+		die(err)
+	}
+	defer client.Close()
+	backend := bundleload.NewRPCBackend(client)
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		die(err)
+	}
+	generator, err := bundleload.NewGenerator(cfg, chainID, keys)
+	if err != nil {
+		die(err)
+	}
+	runner := bundleload.NewRunner(backend, generator)
+
+	go func() {
+		ticker := time.NewTicker(*report)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				printStats(runner.Stats())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	if err := runner.Run(ctx); err != nil {
+		die(err)
+	}
+	printStats(runner.Stats())
+}
+
+func printStats(stats bundleload.Stats) {
+	fmt.Printf("Blocks: %d\n", stats.Blocks)
+	for kind, kindStats := range stats.Kinds {
+		fmt.Printf("  %-9s sent: %d, rejected: %d, failed: %d, latency: %v\n",
+			bundleload.Kind(kind), kindStats.Sent, kindStats.Rejected, kindStats.Failed, kindStats.Latency)
+	}
+}
+
+func die(args ...interface{}) {
+	fmt.Fprintln(os.Stderr, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// bundlereplay replays a bundle recording against a builder.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/builder/bundlerecord"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/google/uuid"
+)
+
+var (
+	endpoint    = flag.String("rpc", "http://127.0.0.1:8545", "RPC endpoint of the builder the bundles are sent to")
+	speed       = flag.Float64("speed", 1, "Replay speed relative to the original timing (0 = send without waiting)")
+	blockOffset = flag.Int64("block-offset", 0, "Offset added to the block numbers targeted by the bundles")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage:", os.Args[0], "[options] [recording-file]")
+		fmt.Fprintln(os.Stderr, `
+Replays a bundle recording (--builder.bundle_record) against a builder, sending
+the bundles with eth_sendBundle and mev_sendBundle with their original timing.
+If the filename is omitted, data is read from stdin.`)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var r io.Reader
+	switch {
+	case flag.NArg() == 1:
+		fd, err := os.Open(flag.Arg(0))
+		if err != nil {
+			die(err)
+		}
+		defer fd.Close()
+		r = fd
+	case flag.NArg() == 0:
+		r = os.Stdin
+	default:
+		fmt.Fprintln(os.Stderr, "Error: too many arguments")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *speed < 0 {
+		die("invalid negative speed")
+	}
+
+	recording, err := bundlerecord.NewReader(r)
+	if err != nil {
+		die(err)
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	client, err := rpc.DialContext(ctx, *endpoint)
+	if err != nil {
+		die(err)
+	}
+	defer client.Close()
+
+	var rejected int
+	sent, err := bundlerecord.Replay(ctx, recording, *speed, func(record *bundlerecord.Record) error {
+		err := send(ctx, client, record)
+		// bundles rejected by the builder do not stop the replay
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			rejected++
+			fmt.Fprintf(os.Stderr, "Bundle for block %d rejected: %v\n", shift(record.BlockNumber), err)
+			return nil
+		}
+		return err
+	})
+	fmt.Printf("Replayed %d bundles, %d rejected\n", sent, rejected)
+	if err != nil {
+		die(err)
+	}
+}
+
+// send sends a recorded bundle to the builder.
+func send(ctx context.Context, client *rpc.Client, record *bundlerecord.Record) error {
+	switch record.Kind {
+	case bundlerecord.KindBundle:
+		args := ethapi.SendBundleArgs{
+			Txs:               make([]hexutil.Bytes, 0, len(record.Txs)),
+			BlockNumber:       rpc.BlockNumber(shift(record.BlockNumber)),
+			RevertingTxHashes: record.RevertingTxHashes,
+		}
+		for _, tx := range record.Txs {
+			encoded, err := tx.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			args.Txs = append(args.Txs, encoded)
+		}
+		if replacementUuid := uuid.UUID(record.ReplacementUuid); replacementUuid != uuid.Nil {
+			args.ReplacementUuid = &replacementUuid
+		}
+		if record.SigningAddress != (common.Address{}) {
+			args.SigningAddress = &record.SigningAddress
+		}
+		if record.MinTimestamp != 0 {
+			args.MinTimestamp = &record.MinTimestamp
+		}
+		if record.MaxTimestamp != 0 {
+			args.MaxTimestamp = &record.MaxTimestamp
+		}
+		return client.CallContext(ctx, nil, "eth_sendBundle", args)
+
+	case bundlerecord.KindSBundle:
+		var args ethapi.SendMevBundleArgs
+		if err := json.Unmarshal(record.SBundle, &args); err != nil {
+			return err
+		}
+		args.Inclusion.BlockNumber = hexutil.Uint64(shift(uint64(args.Inclusion.BlockNumber)))
+		if args.Inclusion.MaxBlock != 0 {
+			args.Inclusion.MaxBlock = hexutil.Uint64(shift(uint64(args.Inclusion.MaxBlock)))
+		}
+		return client.CallContext(ctx, nil, "mev_sendBundle", args)
+
+	default:
+		return fmt.Errorf("unknown bundle kind %d", record.Kind)
+	}
+}
+
+// shift applies the block offset to a recorded block number.
+func shift(number uint64) uint64 {
+	return uint64(int64(number) + *blockOffset)
+}
+
+func die(args ...interface{}) {
+	fmt.Fprintln(os.Stderr, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,223 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	benchAlgoFlag = &cli.StringFlag{
+		Name:  "algo",
+		Usage: "Comma separated block building algorithms to benchmark",
+		Value: strings.Join([]string{
+			miner.ALGO_GREEDY.String(),
+			miner.ALGO_GREEDY_BUCKETS.String(),
+			miner.ALGO_GREEDY_MULTISNAP.String(),
+			miner.ALGO_GREEDY_BUCKETS_MULTISNAP.String(),
+		}, ","),
+	}
+	benchRoundsFlag = &cli.IntFlag{
+		Name:  "rounds",
+		Usage: "Number of blocks built with every algorithm",
+		Value: 20,
+	}
+	benchGasLimitFlag = &cli.Uint64Flag{
+		Name:  "gaslimit",
+		Usage: "Gas limit of the built blocks",
+		Value: 30_000_000,
+	}
+	benchWorkloadFlag = &cli.StringFlag{
+		Name:  "workload",
+		Usage: "Path of a recorded workload, a JSON array of bundles in the eth_sendBundle format as written by geth bundles export (default = canned workload)",
+	}
+	benchSeedFlag = &cli.Int64Flag{
+		Name:  "seed",
+		Usage: "Seed of the canned workload",
+		Value: 1,
+	}
+	benchTxsFlag = &cli.IntFlag{
+		Name:  "txs",
+		Usage: "Number of mempool transactions of the canned workload",
+		Value: 2000,
+	}
+	benchBundlesFlag = &cli.IntFlag{
+		Name:  "bundles",
+		Usage: "Number of bundles of the canned workload",
+		Value: 200,
+	}
+	benchJSONFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the report as JSON",
+	}
+
+	benchCommand = &cli.Command{
+		Action:    benchBuilder,
+		Name:      "bench",
+		Usage:     "Benchmark the block building algorithms",
+		ArgsUsage: "",
+		Flags: []cli.Flag{
+			benchAlgoFlag,
+			benchRoundsFlag,
+			benchGasLimitFlag,
+			benchWorkloadFlag,
+			benchSeedFlag,
+			benchTxsFlag,
+			benchBundlesFlag,
+			benchJSONFlag,
+		},
+		Description: `
+geth bench [--algo greedy,greedy-buckets] [--workload bundles.json]
+
+The bench command builds blocks with the block building algorithms over a
+workload on the local machine, and reports the profit of the blocks, the p50
+and p99 latencies of the simulation, packing and finalization stages, and the
+memory allocated per block. The same workload and flags give comparable reports
+across releases and hardware.
+
+The canned workload is generated from --seed, with --txs mempool transfers and
+--bundles bundles paying the coinbase. A recorded workload is replayed with
+--workload; its senders are funded in an in-memory genesis, calls to contracts
+do not find the contracts of the original chain.`,
+	}
+)
+
+func benchBuilder(ctx *cli.Context) error {
+	if ctx.NArg() != 0 {
+		return errors.New("too many arguments")
+	}
+	var algos []miner.AlgoType
+	for _, name := range strings.Split(ctx.String(benchAlgoFlag.Name), ",") {
+		algo, err := miner.AlgoTypeFlagToEnum(strings.TrimSpace(name))
+		if err != nil {
+			return fmt.Errorf("invalid algorithm %q: %w", name, err)
+		}
+		algos = append(algos, algo)
+	}
+
+	var (
+		workload *miner.BenchWorkload
+		err      error
+	)
+	if path := ctx.String(benchWorkloadFlag.Name); path != "" {
+		workload, err = loadBenchWorkload(path)
+	} else {
+		workload, err = miner.NewBenchWorkload(ctx.Int64(benchSeedFlag.Name), ctx.Int(benchTxsFlag.Name), ctx.Int(benchBundlesFlag.Name))
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := miner.RunBench(miner.BenchConfig{
+		Algos:    algos,
+		Rounds:   ctx.Int(benchRoundsFlag.Name),
+		GasLimit: ctx.Uint64(benchGasLimitFlag.Name),
+	}, workload)
+	if err != nil {
+		return err
+	}
+	if ctx.Bool(benchJSONFlag.Name) {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	printBenchReport(report)
+	return nil
+}
+
+// loadBenchWorkload reads a recorded workload of bundles in the eth_sendBundle format.
+func loadBenchWorkload(path string) (*miner.BenchWorkload, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var bundles []ethapi.SendBundleArgs
+	if err := json.Unmarshal(content, &bundles); err != nil {
+		return nil, fmt.Errorf("invalid workload: %w", err)
+	}
+	workload := &miner.BenchWorkload{Bundles: make([]types.MevBundle, 0, len(bundles))}
+	for i, args := range bundles {
+		txs := make(types.Transactions, 0, len(args.Txs))
+		for j, encodedTx := range args.Txs {
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(encodedTx); err != nil {
+				return nil, fmt.Errorf("invalid tx %d of bundle %d: %w", j, i, err)
+			}
+			txs = append(txs, tx)
+		}
+		if len(txs) == 0 {
+			continue
+		}
+		workload.Bundles = append(workload.Bundles, types.MevBundle{
+			Txs:               txs,
+			BlockNumber:       big.NewInt(args.BlockNumber.Int64()),
+			RevertingTxHashes: args.RevertingTxHashes,
+			Hash:              txpool.MevBundleHash(txs),
+		})
+	}
+	if len(workload.Bundles) == 0 {
+		return nil, errors.New("workload has no bundles")
+	}
+	return workload, nil
+}
+
+func printBenchReport(report *miner.BenchReport) {
+	fmt.Printf("geth %s, %s, %s, %d CPUs\n", report.Version, report.GoVersion, report.Platform, report.CPUs)
+	fmt.Printf("Workload: %d txs, %d bundles, %d senders, gas limit %d, %d rounds\n\n", report.Txs, report.Bundles, report.Senders, report.GasLimit, report.Rounds)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ALGO\tPROFIT (ETH)\tGAS USED\tTXS\tBUNDLES IN/SIM\tSTAGE\tP50\tP99\tMAX\tALLOC/BLOCK\tALLOCS/BLOCK")
+	for _, result := range report.Algos {
+		profit := new(big.Float).Quo(new(big.Float).SetInt(result.Profit.ToInt()), big.NewFloat(params.Ether))
+		for i, latency := range result.Latencies {
+			if i == 0 {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d/%d\t", result.Algo, profit.Text('f', 6), result.GasUsed, result.Txs, result.Bundles, result.SimulatedBundles)
+			} else {
+				fmt.Fprint(w, "\t\t\t\t\t")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t", latency.Stage, benchDuration(latency.P50), benchDuration(latency.P99), benchDuration(latency.Max))
+			if i == 0 {
+				fmt.Fprintf(w, "%s\t%d\n", common.StorageSize(result.AllocBytes), result.Allocs)
+			} else {
+				fmt.Fprint(w, "\t\n")
+			}
+		}
+	}
+	w.Flush()
+}
+
+func benchDuration(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/builder/buildtrace"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	replayTraceFlag = &cli.StringFlag{
+		Name:     "trace",
+		Usage:    "Path of the build trace, as written with --builder.build_trace",
+		Required: true,
+	}
+	replayBlockFlag = &cli.Uint64Flag{
+		Name:  "block",
+		Usage: "Only replay the rounds building the block with this number",
+	}
+	replayHashFlag = &cli.StringFlag{
+		Name:  "hash",
+		Usage: "Only replay the round which built the block with this hash",
+	}
+	replayAlgoFlag = &cli.StringFlag{
+		Name:  "algo",
+		Usage: "Block building algorithm of the replay (default = algorithm of the traced round)",
+	}
+	replayJSONFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the reports as JSON",
+	}
+
+	builderCommand = &cli.Command{
+		Name:  "builder",
+		Usage: "Debug the block building of the builder",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "replay",
+				Usage:     "Replay the rounds of a build trace on top of the local chain",
+				ArgsUsage: "",
+				Action:    replayBuildTrace,
+				Flags: flags.Merge([]cli.Flag{
+					replayTraceFlag,
+					replayBlockFlag,
+					replayHashFlag,
+					replayAlgoFlag,
+					replayJSONFlag,
+				}, utils.NetworkFlags, utils.DatabasePathFlags),
+				Description: `
+geth builder replay --trace trace.json [--block N] [--hash H] [--algo greedy]
+
+The replay command builds the blocks of the rounds of a build trace again, on
+top of the state of their parent in the local datadir, without starting the
+node. The traced bundles are simulated again and given to the algorithm with the
+traced mempool, the sbundles are not replayed.
+
+Every replayed round is compared with the trace: the profit of the block and
+the commit attempts of the algorithm, in order, with their outcome and the
+resulting block profit. The first attempt differing from the trace is reported,
+to find where a change of the algorithm, or of the state, costs profit. The
+state of the parent of every replayed block must be available.`,
+			},
+		},
+	}
+)
+
+func replayBuildTrace(ctx *cli.Context) error {
+	if ctx.NArg() != 0 {
+		return errors.New("too many arguments")
+	}
+	var hash common.Hash
+	if arg := ctx.String(replayHashFlag.Name); arg != "" {
+		if len(strings.TrimPrefix(arg, "0x")) != 2*common.HashLength {
+			return fmt.Errorf("invalid block hash %q", arg)
+		}
+		hash = common.HexToHash(arg)
+	}
+	file, err := os.Open(ctx.String(replayTraceFlag.Name))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+	defer chain.Stop()
+
+	var (
+		number  = ctx.Uint64(replayBlockFlag.Name)
+		reports []*miner.ReplayReport
+		failed  error
+	)
+	err = buildtrace.Read(file, func(round *buildtrace.Round) bool {
+		if (number != 0 && round.Number() != number) || (hash != (common.Hash{}) && round.BlockHash != hash) {
+			return true
+		}
+		report, err := miner.ReplayRound(chain, round, ctx.String(replayAlgoFlag.Name))
+		if err != nil {
+			failed = fmt.Errorf("failed to replay block %d (%s): %w", round.Number(), round.BlockHash, err)
+			return false
+		}
+		reports = append(reports, report)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if failed != nil {
+		return failed
+	}
+	if len(reports) == 0 {
+		return errors.New("no matching round in the trace")
+	}
+	if ctx.Bool(replayJSONFlag.Name) {
+		out, err := json.MarshalIndent(reports, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	printReplayReports(reports)
+	return nil
+}
+
+func printReplayReports(reports []*miner.ReplayReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NUMBER\tBLOCK\tALGO\tTRACED PROFIT\tREPLAYED PROFIT\tSTEPS\tDIVERGENCE\tFAILED SIMS")
+	for _, report := range reports {
+		divergence := "-"
+		if report.Divergence >= 0 {
+			divergence = fmt.Sprint(report.Divergence)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%v\t%v\t%d\t%s\t%d\n", report.BlockNumber, report.BlockHash.TerminalString(), report.Algorithm,
+			report.TracedProfit.ToInt(), report.ReplayedProfit.ToInt(), len(report.Steps), divergence, len(report.Failed))
+	}
+	w.Flush()
+
+	for _, report := range reports {
+		if report.Divergence < 0 {
+			continue
+		}
+		step := report.Steps[report.Divergence]
+		fmt.Printf("\nBlock %d diverges at step %d\n", report.BlockNumber, step.Index)
+		fmt.Printf("  traced:   %s\n", formatReplayStep(step.Traced))
+		fmt.Printf("  replayed: %s\n", formatReplayStep(step.Replayed))
+	}
+}
+
+func formatReplayStep(step *buildtrace.Step) string {
+	if step == nil {
+		return "none"
+	}
+	if step.BlockProfit != nil {
+		return fmt.Sprintf("%s %s, block profit %v", step.Hash, step.Reason, step.BlockProfit.ToInt())
+	}
+	return fmt.Sprintf("%s %s", step.Hash, step.Reason)
+}
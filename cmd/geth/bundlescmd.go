@@ -0,0 +1,240 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/builder"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	bundlesRPCFlag = &cli.StringFlag{
+		Name:  "rpc",
+		Usage: "Authenticated RPC endpoint of the builder (--authrpc.addr and --authrpc.port)",
+		Value: "http://127.0.0.1:8551",
+	}
+	bundlesFlags = []cli.Flag{
+		bundlesRPCFlag,
+		utils.JWTSecretFlag,
+	}
+
+	bundlesCommand = &cli.Command{
+		Name:  "bundles",
+		Usage: "Manage the bundle pool of a running builder",
+		Description: `
+The bundles commands manage the mev bundle pool of a running builder through its
+authenticated RPC, with the JWT secret given with --authrpc.jwtsecret.`,
+		Subcommands: []*cli.Command{
+			{
+				Name:      "list",
+				Usage:     "List the bundles of the pool",
+				ArgsUsage: "",
+				Action:    listBundles,
+				Flags:     bundlesFlags,
+				Description: `
+geth bundles list
+lists the bundles of the pool, with the block they target and their number of
+transactions. Bundles submitted for several blocks are listed once per block.`,
+			},
+			{
+				Name:      "inspect",
+				Usage:     "Print the transactions of a bundle of the pool",
+				ArgsUsage: "<bundle hash>",
+				Action:    inspectBundle,
+				Flags:     bundlesFlags,
+				Description: `
+geth bundles inspect <bundle hash>
+prints the bundles of the pool with the given hash as JSON, with the sender,
+recipient, nonce, gas and value of their transactions. The calldata is left out.`,
+			},
+			{
+				Name:      "evict",
+				Usage:     "Remove bundles from the pool",
+				ArgsUsage: "<bundle hash> [<bundle hash>...]",
+				Action:    evictBundles,
+				Flags:     bundlesFlags,
+				Description: `
+geth bundles evict <bundle hash> [<bundle hash>...]
+removes the bundles with the given hashes from the pool, for every block they
+target.`,
+			},
+			{
+				Name:      "export",
+				Usage:     "Export the bundles of the pool",
+				ArgsUsage: "<file (optional)>",
+				Action:    exportBundles,
+				Flags:     bundlesFlags,
+				Description: `
+geth bundles export [file]
+writes the bundles of the pool as a JSON array of bundles in the eth_sendBundle
+format, to stdout by default. Each bundle can be simulated with geth simulate.`,
+			},
+		},
+	}
+)
+
+// dialBundleAdmin connects to the authenticated RPC of the builder.
+func dialBundleAdmin(ctx *cli.Context) (*rpc.Client, error) {
+	path := ctx.String(utils.JWTSecretFlag.Name)
+	if path == "" {
+		return nil, errors.New("missing --authrpc.jwtsecret, the bundle pool is managed through the authenticated RPC")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT secret: %w", err)
+	}
+	secret := common.FromHex(strings.TrimSpace(string(data)))
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("invalid JWT secret %s, expected 32 hex encoded bytes", path)
+	}
+	var key [32]byte
+	copy(key[:], secret)
+	return rpc.DialOptions(ctx.Context, ctx.String(bundlesRPCFlag.Name), rpc.WithHTTPAuth(node.NewJWTAuth(key)))
+}
+
+func listBundles(ctx *cli.Context) error {
+	if ctx.NArg() != 0 {
+		return errors.New("too many arguments")
+	}
+	client, err := dialBundleAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var bundles []builder.PooledBundle
+	if err := client.CallContext(ctx.Context, &bundles, "builder_listBundles"); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "HASH\tBLOCK\tTXS\tMIN TIMESTAMP\tMAX TIMESTAMP\tREPLACEMENT UUID")
+	for _, bundle := range bundles {
+		replacementUuid := "-"
+		if bundle.ReplacementUuid != nil {
+			replacementUuid = bundle.ReplacementUuid.String()
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\n", bundle.Hash, bundle.BlockNumber.ToInt(), bundle.Txs, bundle.MinTimestamp, bundle.MaxTimestamp, replacementUuid)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d bundles in the pool\n", len(bundles))
+	return nil
+}
+
+func inspectBundle(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("expected one bundle hash")
+	}
+	hash, err := parseBundleHash(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	client, err := dialBundleAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var details []builder.PooledBundleDetails
+	if err := client.CallContext(ctx.Context, &details, "builder_inspectBundle", hash); err != nil {
+		return err
+	}
+	return printBundlesJSON(details)
+}
+
+func evictBundles(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return errors.New("expected at least one bundle hash")
+	}
+	var hashes []common.Hash
+	for _, arg := range ctx.Args().Slice() {
+		hash, err := parseBundleHash(arg)
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, hash)
+	}
+	client, err := dialBundleAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var evicted int
+	if err := client.CallContext(ctx.Context, &evicted, "builder_evictBundles", hashes); err != nil {
+		return err
+	}
+	fmt.Printf("Evicted %d bundles\n", evicted)
+	return nil
+}
+
+func exportBundles(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		return errors.New("too many arguments")
+	}
+	client, err := dialBundleAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var bundles []ethapi.SendBundleArgs
+	if err := client.CallContext(ctx.Context, &bundles, "builder_exportBundles"); err != nil {
+		return err
+	}
+	if ctx.NArg() == 0 {
+		return printBundlesJSON(bundles)
+	}
+	out, err := json.MarshalIndent(bundles, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(ctx.Args().First(), append(out, '\n'), 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d bundles to %s\n", len(bundles), ctx.Args().First())
+	return nil
+}
+
+func parseBundleHash(arg string) (common.Hash, error) {
+	if len(strings.TrimPrefix(arg, "0x")) != 2*common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid bundle hash %q", arg)
+	}
+	return common.HexToHash(arg), nil
+}
+
+func printBundlesJSON(v interface{}) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
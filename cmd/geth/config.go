@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/faultinject"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/internal/version"
 	"github.com/ethereum/go-ethereum/log"
@@ -157,6 +158,15 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 
 	// Apply builder flags
 	utils.SetBuilderConfig(ctx, &cfg.Builder)
+	if cfg.Builder.ConfigFile != "" {
+		file, err := builder.LoadFileConfig(cfg.Builder.ConfigFile)
+		if err != nil {
+			utils.Fatalf("Failed to load builder config file: %v", err)
+		}
+		if err := file.Apply(&cfg.Builder, &cfg.Eth.Miner); err != nil {
+			utils.Fatalf("Invalid builder config file %s: %v", cfg.Builder.ConfigFile, err)
+		}
+	}
 
 	return stack, cfg
 }
@@ -168,6 +178,10 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 		v := ctx.Uint64(utils.OverrideShanghai.Name)
 		cfg.Eth.OverrideShanghai = &v
 	}
+	// Faults are enabled before the backend opens the chain database
+	if err := faultinject.Configure(cfg.Builder.Faults); err != nil {
+		utils.Fatalf("Invalid builder faults: %v", err)
+	}
 	backend, eth := utils.RegisterEthService(stack, &cfg.Eth, &cfg.Builder)
 
 	// Configure log filter RPC API.
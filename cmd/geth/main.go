@@ -86,6 +86,9 @@ var (
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
 		utils.TxPoolPrivateLifetimeFlag,
+		utils.TxPoolBuilderOnlySlotsFlag,
+		utils.TxPoolBuilderOnlyAccountSlotsFlag,
+		utils.TxPoolBuilderOnlyLifetimeFlag,
 		utils.SyncModeFlag,
 		utils.SyncTargetFlag,
 		utils.ExitWhenSyncedFlag,
@@ -178,12 +181,90 @@ var (
 		utils.BuilderBeaconEndpoints,
 		utils.BuilderRemoteRelayEndpoint,
 		utils.BuilderSecondaryRemoteRelayEndpoints,
+		utils.BuilderRelaySubmissionTimeout,
+		utils.BuilderRelaySubmissionRetries,
+		utils.BuilderRelaySigningKey,
 		utils.BuilderRateLimitDuration,
 		utils.BuilderRateLimitMaxBurst,
 		utils.BuilderBlockResubmitInterval,
 		utils.BuilderSubmissionOffset,
+		utils.BuilderBorPeriod,
+		utils.BuilderBorProducerDelay,
+		utils.BuilderBorSprint,
+		utils.BuilderBorBackupDelay,
+		utils.BuilderProducers,
+		utils.BuilderAlwaysBuild,
 		utils.BuilderDiscardRevertibleTxOnErr,
 		utils.BuilderEnableCancellations,
+		utils.BuilderBundleEncryptionKeyFile,
+		utils.BuilderAuditLog,
+		utils.BuilderSimMaxMemorySize,
+		utils.BuilderSimMaxReturnDataSize,
+		utils.BuilderSimMaxPrecompileInputSize,
+		utils.BuilderTxSigner,
+		utils.BuilderProtectiveOrdering,
+		utils.BuilderDepositGateContract,
+		utils.BuilderDepositGateUnit,
+		utils.BuilderDepositGateBundlesPerUnit,
+		utils.BuilderDepositGateAllowlist,
+		utils.BuilderSearcherAuthRequired,
+		utils.BuilderSearcherRateLimit,
+		utils.BuilderSearcherRateBurst,
+		utils.BuilderSearcherMaxBundles,
+		utils.BuilderSearcherAllowlist,
+		utils.BuilderSearcherDenylist,
+		utils.BuilderGriefingGasThreshold,
+		utils.BuilderGriefingMaxStrikes,
+		utils.BuilderGriefingWindow,
+		utils.BuilderGriefingQuarantine,
+		utils.BuilderSearcherReputationFile,
+		utils.BuilderSearcherReputationHighLoad,
+		utils.BuilderSearcherReputationLowScore,
+		utils.BuilderSearcherReputationLowBundles,
+		utils.BuilderBidPolicy,
+		utils.BuilderBidMargin,
+		utils.BuilderBidMarginPercent,
+		utils.BuilderBidDynamicBlocks,
+		utils.BuilderHeimdallURL,
+		utils.BuilderHeimdallTimeout,
+		utils.BuilderStateSyncSprint,
+		utils.BuilderStateSyncDelay,
+		utils.BuilderParentReexec,
+		utils.BuilderPrefetchBudget,
+		utils.BuilderPreSimulationWorkers,
+		utils.BuilderMaxBlockStateGrowth,
+		utils.BuilderMinStateGrowthProfit,
+		utils.BuilderMinBundleGasPrice,
+		utils.BuilderMinBundleProfit,
+		utils.BuilderSprintMinBundleGasPrice,
+		utils.BuilderSprintMinBundleProfit,
+		utils.BuilderTopOfBlockGas,
+		utils.BuilderTopOfBlockSearchers,
+		utils.BuilderTopOfBlockContracts,
+		utils.BuilderMultiTxSnapshotMemoryLimit,
+		utils.BuilderMultiTxSnapshotSpill,
+		utils.BuilderVerifySnapshots,
+		utils.BuilderWitnessDir,
+		utils.BuilderWorkers,
+		utils.BuilderLogBundleContent,
+		utils.BuilderDecisionLog,
+		utils.BuilderBuildTrace,
+		utils.BuilderEventsURL,
+		utils.BuilderEventsTopicPrefix,
+		utils.BuilderProfileDir,
+		utils.BuilderAlertWebhooks,
+		utils.BuilderAlertTemplate,
+		utils.BuilderAlertMinInterval,
+		utils.BuilderAlertMinProfit,
+		utils.BuilderAlertLowProfitSlots,
+		utils.BuilderBundleRecord,
+		utils.BuilderConfigFile,
+		utils.BuilderCandidateDump,
+		utils.BuilderShadow,
+		utils.BuilderFaults,
+		utils.BuilderStandby,
+		utils.BuilderStandbyJWTSecret,
+		utils.BuilderStandbyTimeout,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -274,6 +355,18 @@ func init() {
 		snapshotCommand,
 		// See verkle.go
 		verkleCommand,
+		// See simulatecmd.go
+		simulateCommand,
+		// See migratecmd.go
+		migrateCommand,
+		// See bundlescmd.go
+		bundlesCommand,
+		// See benchcmd.go
+		benchCommand,
+		// See buildercmd.go
+		builderCommand,
+		// See statediffcmd.go
+		stateDiffCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
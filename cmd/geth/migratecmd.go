@@ -0,0 +1,423 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/builder"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/naoina/toml"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	migrateArgsFlag = &cli.StringFlag{
+		Name:  "source.args",
+		Usage: "Path of a file with the flashbots/builder command line flags and NAME=value environment variables",
+	}
+	migrateConfigFlag = &cli.StringFlag{
+		Name:  "source.config",
+		Usage: "Path of the flashbots/builder TOML config file (--config)",
+	}
+	migrateDatadirFlag = &cli.StringFlag{
+		Name:  "source.datadir",
+		Usage: "Data directory of the flashbots/builder node, its keystore layout is checked",
+	}
+
+	migrateCommand = &cli.Command{
+		Action:    migrateBuilderConfig,
+		Name:      "migrate-builder-config",
+		Usage:     "Convert a flashbots/builder configuration to a builder config file",
+		ArgsUsage: "<builder config file (optional)>",
+		Flags: []cli.Flag{
+			migrateArgsFlag,
+			migrateConfigFlag,
+			migrateDatadirFlag,
+		},
+		Description: `
+geth migrate-builder-config --source.args builder.args [builder.toml]
+
+The migrate-builder-config command reads the configuration of a flashbots/builder
+node and writes the equivalent builder config file (--builder.config), to stdout
+by default. The migration report is printed to stderr.
+
+--source.args is a file with the command line of the node, and the environment
+variables it is started with as NAME=value lines, like a systemd environment
+file. The relay, algorithm, limits and timing settings are moved to the builder
+config file, the other flags known to this builder are reported to be kept on
+the command line, and the options without an equivalent are flagged.
+
+--source.config is the TOML config file of the node, the fields without an
+equivalent are flagged. --source.datadir is checked for the keystore and the
+node key to reuse.`,
+	}
+)
+
+// migrateFileSettings are the flags with an equivalent in the builder config file, by their
+// canonical name. The deprecated miner flags do not override the builder flags replacing them.
+var migrateFileSettings = map[string]func(file *builder.FileConfig, value string) error{
+	utils.BuilderRemoteRelayEndpoint.Name: func(file *builder.FileConfig, value string) error {
+		file.Relays.Remote = value
+		return nil
+	},
+	utils.BuilderSecondaryRemoteRelayEndpoints.Name: func(file *builder.FileConfig, value string) error {
+		file.Relays.Secondary = nil
+		for _, endpoint := range strings.Split(value, ",") {
+			if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+				file.Relays.Secondary = append(file.Relays.Secondary, endpoint)
+			}
+		}
+		return nil
+	},
+	utils.BuilderEnableCancellations.Name: func(file *builder.FileConfig, value string) error {
+		return setMigratedBool(&file.Relays.Cancellations, value)
+	},
+	utils.BuilderAlgoTypeFlag.Name: func(file *builder.FileConfig, value string) error {
+		file.Algorithm.Type = value
+		return nil
+	},
+	utils.MinerAlgoTypeFlag.Name: func(file *builder.FileConfig, value string) error {
+		if file.Algorithm.Type == "" {
+			file.Algorithm.Type = value
+		}
+		return nil
+	},
+	utils.BuilderPriceCutoffPercentFlag.Name: func(file *builder.FileConfig, value string) error {
+		percent, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		file.Algorithm.PriceCutoffPercent = &percent
+		return nil
+	},
+	utils.BuilderDiscardRevertibleTxOnErr.Name: func(file *builder.FileConfig, value string) error {
+		return setMigratedBool(&file.Algorithm.DiscardRevertibleTxOnErr, value)
+	},
+	utils.BuilderRateLimitDuration.Name: func(file *builder.FileConfig, value string) error {
+		file.Limits.RateLimitDuration = value
+		return nil
+	},
+	utils.BuilderRateLimitMaxBurst.Name: func(file *builder.FileConfig, value string) error {
+		burst, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		file.Limits.RateLimitMaxBurst = burst
+		return nil
+	},
+	utils.BuilderBlockResubmitInterval.Name: func(file *builder.FileConfig, value string) error {
+		file.Limits.RateLimitResubmitInterval = value
+		return nil
+	},
+	utils.BuilderSubmissionOffset.Name: func(file *builder.FileConfig, value string) error {
+		file.Timing.SubmissionOffset = value
+		return nil
+	},
+}
+
+func setMigratedBool(field **bool, value string) error {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*field = &enabled
+	return nil
+}
+
+// migrationFlag is a flag of the builder the source options are matched against.
+type migrationFlag struct {
+	name       string // Canonical name
+	takesValue bool
+}
+
+// migrationFlags indexes the flags of the builder by name, alias and environment variable.
+type migrationFlags struct {
+	names map[string]migrationFlag
+	envs  map[string]migrationFlag
+}
+
+func newMigrationFlags(flags []cli.Flag) *migrationFlags {
+	known := &migrationFlags{names: make(map[string]migrationFlag), envs: make(map[string]migrationFlag)}
+	for _, f := range flags {
+		names := f.Names()
+		flag := migrationFlag{name: names[0], takesValue: true}
+		if df, ok := f.(cli.DocGenerationFlag); ok {
+			flag.takesValue = df.TakesValue()
+			for _, env := range df.GetEnvVars() {
+				known.envs[env] = flag
+			}
+		}
+		for _, name := range names {
+			known.names[name] = flag
+		}
+	}
+	return known
+}
+
+// migrationOption is a flag of the migrated configuration kept on the command line.
+type migrationOption struct {
+	name, value string
+	takesValue  bool
+}
+
+func (o migrationOption) String() string {
+	if !o.takesValue {
+		if o.value == "" || o.value == "true" {
+			return "--" + o.name
+		}
+		return fmt.Sprintf("--%s=%s", o.name, o.value)
+	}
+	return fmt.Sprintf("--%s %s", o.name, strconv.Quote(o.value))
+}
+
+// builderMigration is the result of the migration of a flashbots/builder configuration.
+type builderMigration struct {
+	file      builder.FileConfig
+	moved     []string          // Options moved to the builder config file
+	kept      []migrationOption // Options kept on the command line
+	unknown   []string          // Options without an equivalent
+	plaintext bool              // Whether the builder key is set in plain text
+}
+
+// migrateBuilderArgs migrates the command line flags and the environment variables read from r.
+// The flags take precedence over the environment variables, like they do for the node.
+func migrateBuilderArgs(r io.Reader, known *migrationFlags) (*builderMigration, error) {
+	var (
+		migration = new(builderMigration)
+		fromEnv   []migrationOption
+		fromFlags []migrationOption
+		tokens    []string
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// environment files may export the variables
+		line = strings.TrimPrefix(line, "export ")
+		if name, value, ok := strings.Cut(line, "="); ok && isEnvName(name) {
+			value = strings.Trim(value, `"'`)
+			if flag, ok := known.envs[name]; ok {
+				fromEnv = append(fromEnv, migrationOption{name: flag.name, value: value, takesValue: flag.takesValue})
+			} else if strings.HasPrefix(name, "FLASHBOTS_") || strings.HasPrefix(name, "BUILDER_") {
+				migration.unknown = append(migration.unknown, name)
+			}
+			continue
+		}
+		for _, token := range strings.Fields(line) {
+			// line continuations of shell scripts
+			if token != `\` {
+				tokens = append(tokens, strings.Trim(token, `"'`))
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		if !strings.HasPrefix(tokens[i], "-") {
+			// the binary and the command are not options
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(tokens[i], "-"), "=")
+		flag, ok := known.names[name]
+		if !hasValue && (!ok || flag.takesValue) && i+1 < len(tokens) && !strings.HasPrefix(tokens[i+1], "-") {
+			i++
+			value = tokens[i]
+		}
+		if !ok {
+			migration.unknown = append(migration.unknown, "--"+name)
+			continue
+		}
+		fromFlags = append(fromFlags, migrationOption{name: flag.name, value: value, takesValue: flag.takesValue})
+	}
+
+	// the options are applied in order, the flags override the environment variables
+	for _, option := range append(fromEnv, fromFlags...) {
+		if option.name == utils.BuilderSecretKey.Name && !strings.HasPrefix(option.value, "vault://") {
+			migration.plaintext = true
+		}
+		set, ok := migrateFileSettings[option.name]
+		if !ok {
+			migration.kept = append(migration.kept, option)
+			continue
+		}
+		if !option.takesValue && option.value == "" {
+			option.value = "true"
+		}
+		if err := set(&migration.file, option.value); err != nil {
+			return nil, fmt.Errorf("invalid value %q of --%s: %w", option.value, option.name, err)
+		}
+		migration.moved = append(migration.moved, "--"+option.name)
+	}
+	// the migrated values must be accepted by the builder
+	cfg, minerCfg := builder.DefaultConfig, miner.DefaultConfig
+	if err := migration.file.Apply(&cfg, &minerCfg); err != nil {
+		return nil, err
+	}
+	return migration, nil
+}
+
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// checkSourceConfig returns the fields of the TOML config file without an equivalent.
+func checkSourceConfig(path string) ([]string, error) {
+	var unknown []string
+	settings := tomlSettings
+	settings.MissingField = func(rt reflect.Type, field string) error {
+		unknown = append(unknown, fmt.Sprintf("%s.%s", rt.String(), field))
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg := gethConfig{
+		Eth:     ethconfig.Defaults,
+		Node:    defaultNodeConfig(),
+		Metrics: metrics.DefaultConfig,
+		Builder: builder.DefaultConfig,
+	}
+	err = settings.NewDecoder(bufio.NewReader(f)).Decode(&cfg)
+	if _, ok := err.(*toml.LineError); ok {
+		err = errors.New(path + ", " + err.Error())
+	}
+	return unknown, err
+}
+
+// checkSourceDatadir reports the keys of the data directory to reuse.
+func checkSourceDatadir(w io.Writer, datadir string) error {
+	if _, err := os.Stat(datadir); err != nil {
+		return err
+	}
+	keystore := filepath.Join(datadir, "keystore")
+	if entries, err := os.ReadDir(keystore); err == nil && len(entries) > 0 {
+		fmt.Fprintf(w, "Keystore: %d key files in %s, reuse them with --keystore %s\n", len(entries), keystore, keystore)
+	} else {
+		fmt.Fprintln(w, "Keystore: no key files found")
+	}
+	nodekey := filepath.Join(datadir, "geth", "nodekey")
+	if _, err := os.Stat(nodekey); err == nil {
+		fmt.Fprintf(w, "Node key: %s, reuse it with --nodekey %s to keep the node identity\n", nodekey, nodekey)
+	}
+	return nil
+}
+
+// migrateBuilderConfig is the migrate-builder-config command.
+func migrateBuilderConfig(ctx *cli.Context) error {
+	if !ctx.IsSet(migrateArgsFlag.Name) && !ctx.IsSet(migrateConfigFlag.Name) && !ctx.IsSet(migrateDatadirFlag.Name) {
+		return errors.New("nothing to migrate, set --source.args, --source.config or --source.datadir")
+	}
+	report := os.Stderr
+	migration := new(builderMigration)
+	if path := ctx.String(migrateArgsFlag.Name); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if migration, err = migrateBuilderArgs(f, newMigrationFlags(ctx.App.Flags)); err != nil {
+			return err
+		}
+	}
+	if path := ctx.String(migrateConfigFlag.Name); path != "" {
+		unknown, err := checkSourceConfig(path)
+		if err != nil {
+			return err
+		}
+		if len(unknown) == 0 {
+			fmt.Fprintf(report, "Config file: %s can be reused with --config\n", path)
+		} else {
+			fmt.Fprintf(report, "Config file: %s can be reused with --config once the fields without an equivalent are removed\n", path)
+		}
+		migration.unknown = append(migration.unknown, unknown...)
+	}
+	if path := ctx.String(migrateDatadirFlag.Name); path != "" {
+		if err := checkSourceDatadir(report, path); err != nil {
+			return err
+		}
+	}
+
+	out, err := migration.file.Marshal()
+	if err != nil {
+		return err
+	}
+	output := os.Stdout
+	if ctx.NArg() > 0 {
+		if output, err = os.OpenFile(ctx.Args().Get(0), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644); err != nil {
+			return err
+		}
+		defer output.Close()
+	}
+	if _, err := output.Write(out); err != nil {
+		return err
+	}
+
+	if len(migration.moved) > 0 {
+		fmt.Fprintln(report, "Moved to the builder config file:", strings.Join(migration.moved, " "))
+		path := "<builder config file>"
+		if ctx.NArg() > 0 {
+			path = ctx.Args().Get(0)
+		}
+		migration.kept = append(migration.kept, migrationOption{name: utils.BuilderConfigFile.Name, value: path, takesValue: true})
+	}
+	if len(migration.kept) > 0 {
+		kept := make([]string, 0, len(migration.kept))
+		for _, option := range migration.kept {
+			kept = append(kept, option.String())
+		}
+		fmt.Fprintln(report, "Command line:", strings.Join(kept, " "))
+	}
+	if migration.plaintext {
+		fmt.Fprintln(report, "Warning: the builder key is set in plain text, consider --builder.secret_key vault://<secret path>?field=<name>")
+	}
+	if len(migration.unknown) > 0 {
+		sort.Strings(migration.unknown)
+		fmt.Fprintln(report, "No equivalent in this builder, not migrated:")
+		for _, option := range migration.unknown {
+			fmt.Fprintln(report, "  "+option)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrateBuilderArgs(t *testing.T) {
+	args := `
+# flashbots/builder environment
+export BUILDER_REMOTE_RELAY_ENDPOINT="http://env.relay"
+FLASHBOTS_BUILDER_RATE_LIMIT_DURATION=250ms
+FLASHBOTS_BUILDER_UNKNOWN=1
+PATH=/usr/bin
+
+geth --http --builder \
+  --builder.remote_relay_endpoint=http://flag.relay \
+  --builder.secondary_remote_relay_endpoints http://a.relay,http://b.relay \
+  --miner.algotype greedy --builder.algotype greedy-buckets \
+  --builder.cancellations --builder.submission_offset 2s \
+  --builder.secret_key 0x2fc12ae741f29701f8e30f5de6350766c020cb80768a0ff01e6838ffd2431e11 \
+  --builder.removed_option value --http.port 8546
+`
+	migration, err := migrateBuilderArgs(strings.NewReader(args), newMigrationFlags(app.Flags))
+	if err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	file := migration.file
+	if file.Relays.Remote != "http://flag.relay" {
+		t.Errorf("remote relay mismatch: have %q, want the flag value", file.Relays.Remote)
+	}
+	if want := []string{"http://a.relay", "http://b.relay"}; !reflect.DeepEqual(file.Relays.Secondary, want) {
+		t.Errorf("secondary relays mismatch: have %v, want %v", file.Relays.Secondary, want)
+	}
+	if file.Relays.Cancellations == nil || !*file.Relays.Cancellations {
+		t.Errorf("cancellations not enabled")
+	}
+	if file.Algorithm.Type != "greedy-buckets" {
+		t.Errorf("algorithm mismatch: have %q, want greedy-buckets", file.Algorithm.Type)
+	}
+	if file.Limits.RateLimitDuration != "250ms" {
+		t.Errorf("rate limit mismatch: have %q, want the environment value", file.Limits.RateLimitDuration)
+	}
+	if file.Timing.SubmissionOffset != "2s" {
+		t.Errorf("submission offset mismatch: have %q, want 2s", file.Timing.SubmissionOffset)
+	}
+
+	var kept []string
+	for _, option := range migration.kept {
+		kept = append(kept, option.String())
+	}
+	want := []string{"--http", "--builder", `--builder.secret_key "0x2fc12ae741f29701f8e30f5de6350766c020cb80768a0ff01e6838ffd2431e11"`, `--http.port "8546"`}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept options mismatch: have %v, want %v", kept, want)
+	}
+	if want := []string{"FLASHBOTS_BUILDER_UNKNOWN", "--builder.removed_option"}; !reflect.DeepEqual(migration.unknown, want) {
+		t.Errorf("unknown options mismatch: have %v, want %v", migration.unknown, want)
+	}
+	if !migration.plaintext {
+		t.Errorf("plain text builder key not reported")
+	}
+
+	// invalid values are rejected
+	for _, args := range []string{
+		"--builder.algotype unknown",
+		"--builder.rate_limit_max_burst many",
+		"--builder.submission_offset 20s",
+	} {
+		if _, err := migrateBuilderArgs(strings.NewReader(args), newMigrationFlags(app.Flags)); err == nil {
+			t.Errorf("%s: expected an error", args)
+		}
+	}
+}
+
+func TestCheckSourceConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	config := `
+[Eth]
+NetworkId = 1
+
+[Builder]
+Enabled = true
+RemovedField = "value"
+`
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	unknown, err := checkSourceConfig(path)
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if want := []string{"builder.Config.RemovedField"}; !reflect.DeepEqual(unknown, want) {
+		t.Errorf("unknown fields mismatch: have %v, want %v", unknown, want)
+	}
+}
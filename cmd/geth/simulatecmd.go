@@ -0,0 +1,281 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	simulateBundleFlag = &cli.StringFlag{
+		Name:     "bundle",
+		Usage:    "Path of the bundle to simulate, a JSON file in the eth_sendBundle format",
+		Required: true,
+	}
+	simulateBlockFlag = &cli.Uint64Flag{
+		Name:  "block",
+		Usage: "Number of the block the bundle is simulated in, on top of the state of its parent (default = block of the bundle, or the block after the head)",
+	}
+	simulateCoinbaseFlag = &cli.StringFlag{
+		Name:  "coinbase",
+		Usage: "Coinbase the bundle pays to (default = coinbase of the block if it is on chain)",
+	}
+	simulateTraceFlag = &cli.BoolFlag{
+		Name:  "trace",
+		Usage: "Include the call trace of every transaction in the result",
+	}
+
+	simulateCommand = &cli.Command{
+		Action:    simulateBundle,
+		Name:      "simulate",
+		Usage:     "Simulate a bundle on top of the local chain",
+		ArgsUsage: "",
+		Flags: flags.Merge([]cli.Flag{
+			simulateBundleFlag,
+			simulateBlockFlag,
+			simulateCoinbaseFlag,
+			simulateTraceFlag,
+		}, utils.NetworkFlags, utils.DatabasePathFlags),
+		Description: `
+geth simulate --bundle bundle.json [--block N]
+
+The simulate command executes the transactions of a bundle on top of the state
+of the local datadir, without starting the node or going through RPC, and
+prints the result: the status and gas used of every transaction, and what the
+bundle pays to the coinbase.
+
+The bundle is simulated in block N, on top of the state of block N-1. If block N
+is on chain, its header is used, otherwise N must be the block after the head.
+The state of block N-1 must be available.`,
+	}
+)
+
+// simulatedTx is the outcome of a transaction of a simulated bundle.
+type simulatedTx struct {
+	Hash         common.Hash     `json:"hash"`
+	From         common.Address  `json:"from"`
+	Status       uint64          `json:"status"`
+	CanRevert    bool            `json:"canRevert,omitempty"`
+	GasUsed      uint64          `json:"gasUsed"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	Logs         []*types.Log    `json:"logs"`
+	Trace        json.RawMessage `json:"trace,omitempty"`
+}
+
+// bundleSimulation is the outcome of a simulated bundle.
+type bundleSimulation struct {
+	BlockNumber uint64         `json:"blockNumber"`
+	ParentHash  common.Hash    `json:"parentHash"`
+	Coinbase    common.Address `json:"coinbase"`
+	Success     bool           `json:"success"`
+	Error       string         `json:"error,omitempty"`
+	GasUsed     uint64         `json:"gasUsed"`
+	// Profit is the balance increase of the coinbase, the gas fees and the direct payments
+	Profit            *hexutil.Big  `json:"profit"`
+	GasFees           *hexutil.Big  `json:"gasFees"`
+	EthSentToCoinbase *hexutil.Big  `json:"ethSentToCoinbase"`
+	MevGasPrice       *hexutil.Big  `json:"mevGasPrice"`
+	Txs               []simulatedTx `json:"txs"`
+}
+
+func simulateBundle(ctx *cli.Context) error {
+	var args ethapi.SendBundleArgs
+	content, err := os.ReadFile(ctx.String(simulateBundleFlag.Name))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, &args); err != nil {
+		return fmt.Errorf("invalid bundle: %w", err)
+	}
+	if len(args.Txs) == 0 {
+		return errors.New("bundle missing txs")
+	}
+	txs := make(types.Transactions, 0, len(args.Txs))
+	for i, encodedTx := range args.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(encodedTx); err != nil {
+			return fmt.Errorf("invalid bundle tx %d: %w", i, err)
+		}
+		txs = append(txs, tx)
+	}
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chain, _ := utils.MakeChain(ctx, stack, true)
+	defer chain.Stop()
+
+	head := chain.CurrentBlock()
+	number := ctx.Uint64(simulateBlockFlag.Name)
+	if number == 0 && args.BlockNumber > 0 {
+		number = uint64(args.BlockNumber.Int64())
+	}
+	if number == 0 {
+		number = head.Number.Uint64() + 1
+	}
+
+	// the header of the block the bundle is simulated in
+	var header *types.Header
+	switch {
+	case number <= head.Number.Uint64():
+		block := chain.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("block %d not found", number)
+		}
+		header = types.CopyHeader(block.Header())
+	case number == head.Number.Uint64()+1:
+		header = &types.Header{
+			ParentHash: head.Hash(),
+			Number:     new(big.Int).SetUint64(number),
+			GasLimit:   head.GasLimit,
+			Time:       head.Time + 1,
+			Difficulty: new(big.Int),
+		}
+		if now := uint64(time.Now().Unix()); now > header.Time {
+			header.Time = now
+		}
+		if chain.Config().IsLondon(header.Number) {
+			header.BaseFee = misc.CalcBaseFee(chain.Config(), head)
+		}
+	default:
+		return fmt.Errorf("block %d is beyond the block after the head %d", number, head.Number.Uint64())
+	}
+	if ctx.IsSet(simulateCoinbaseFlag.Name) {
+		if !common.IsHexAddress(ctx.String(simulateCoinbaseFlag.Name)) {
+			return fmt.Errorf("invalid coinbase %q", ctx.String(simulateCoinbaseFlag.Name))
+		}
+		header.Coinbase = common.HexToAddress(ctx.String(simulateCoinbaseFlag.Name))
+	}
+
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return fmt.Errorf("block %d not found", number-1)
+	}
+	statedb, err := chain.StateAt(parent.Root)
+	if err != nil {
+		return fmt.Errorf("state of block %d unavailable: %w", number-1, err)
+	}
+
+	canRevert := make(map[common.Hash]bool, len(args.RevertingTxHashes))
+	for _, hash := range args.RevertingTxHashes {
+		canRevert[hash] = true
+	}
+	var (
+		signer   = types.MakeSigner(chain.Config(), header.Number)
+		gasPool  = new(core.GasPool).AddGas(header.GasLimit)
+		balance  = statedb.GetBalance(header.Coinbase)
+		gasFees  = new(big.Int)
+		gasUsed  uint64
+		coinbase = header.Coinbase
+		result   = bundleSimulation{
+			BlockNumber: number,
+			ParentHash:  parent.Hash(),
+			Coinbase:    coinbase,
+			Success:     true,
+			Txs:         make([]simulatedTx, 0, len(txs)),
+		}
+	)
+	for i, tx := range txs {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return fmt.Errorf("invalid bundle tx %d: %w", i, err)
+		}
+		simulated := simulatedTx{Hash: tx.Hash(), From: from, CanRevert: canRevert[tx.Hash()], Logs: []*types.Log{}}
+
+		vmConfig := *chain.GetVMConfig()
+		var tracer tracers.Tracer
+		if ctx.Bool(simulateTraceFlag.Name) {
+			tracer, err = tracers.DefaultDirectory.New("callTracer", &tracers.Context{BlockNumber: header.Number, TxIndex: i, TxHash: tx.Hash()}, nil)
+			if err != nil {
+				return err
+			}
+			vmConfig.Tracer = tracer
+			vmConfig.Debug = true
+		}
+
+		statedb.SetTxContext(tx.Hash(), i)
+		txGasUsed := gasUsed
+		receipt, execution, err := core.ApplyTransactionWithResult(chain.Config(), chain, &coinbase, gasPool, statedb, header, tx, &gasUsed, vmConfig)
+		if err != nil {
+			// invalid transactions are not included, the rest of the bundle is not executed
+			simulated.Error = err.Error()
+			result.Txs = append(result.Txs, simulated)
+			result.Success = false
+			result.Error = fmt.Sprintf("tx %d: %v", i, err)
+			break
+		}
+		simulated.Status = receipt.Status
+		simulated.GasUsed = gasUsed - txGasUsed
+		simulated.Logs = receipt.Logs
+		if execution.Err != nil {
+			simulated.Error = execution.Err.Error()
+			if reason, err := abi.UnpackRevert(execution.Revert()); err == nil {
+				simulated.RevertReason = reason
+			}
+			if !simulated.CanRevert && result.Success {
+				result.Success = false
+				result.Error = fmt.Sprintf("tx %d reverted", i)
+			}
+		}
+		if tracer != nil {
+			if simulated.Trace, err = tracer.GetResult(); err != nil {
+				return err
+			}
+		}
+		tip, err := tx.EffectiveGasTip(header.BaseFee)
+		if err != nil {
+			return err
+		}
+		gasFees.Add(gasFees, new(big.Int).Mul(tip, new(big.Int).SetUint64(simulated.GasUsed)))
+		result.Txs = append(result.Txs, simulated)
+	}
+
+	profit := new(big.Int).Sub(statedb.GetBalance(coinbase), balance)
+	result.GasUsed = gasUsed
+	result.Profit = (*hexutil.Big)(profit)
+	result.GasFees = (*hexutil.Big)(gasFees)
+	result.EthSentToCoinbase = (*hexutil.Big)(new(big.Int).Sub(profit, gasFees))
+	result.MevGasPrice = (*hexutil.Big)(new(big.Int))
+	if gasUsed > 0 {
+		result.MevGasPrice = (*hexutil.Big)(new(big.Int).Div(profit, new(big.Int).SetUint64(gasUsed)))
+	}
+
+	encoded, err := json.MarshalIndent(&result, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	stateDiffSnapshotFlag = &cli.StringFlag{
+		Name:  "snapshot",
+		Usage: "Path of a serialized multi-transaction snapshot, reverted on top of the first dump before the comparison",
+	}
+	stateDiffJSONFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the diff as JSON",
+	}
+
+	stateDiffCommand = &cli.Command{
+		Action:    stateDiff,
+		Name:      "statediff",
+		Usage:     "Compare two state dumps",
+		ArgsUsage: "<dump> [<dump>]",
+		Flags: []cli.Flag{
+			stateDiffSnapshotFlag,
+			stateDiffJSONFlag,
+		},
+		Description: `
+geth statediff <dump A> <dump B>
+geth statediff --snapshot <snapshot> <dump A> [<dump B>]
+
+The statediff command prints the accounts, storage slots and code that differ
+between two state dumps written by geth dump, in the collected or iterative
+format. The iterative dumps must hold the addresses of the accounts.
+
+With --snapshot, the multi-transaction snapshot, as serialized by
+StateDB.MultiTxSnapshotDump, is reverted on top of dump A, and the reverted
+state is compared with dump B, the state the revert is expected to restore.
+Without dump B, the reverted state is compared with dump A itself, to show
+what the revert changes. This is used to investigate revert inconsistencies.`,
+	}
+)
+
+func stateDiff(ctx *cli.Context) error {
+	snapshotPath := ctx.String(stateDiffSnapshotFlag.Name)
+	switch {
+	case ctx.NArg() == 2:
+	case ctx.NArg() == 1 && snapshotPath != "":
+	default:
+		return errors.New("expected two state dumps, or one with --snapshot")
+	}
+	a, err := readStateDump(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	b := a
+	if ctx.NArg() == 2 {
+		if b, err = readStateDump(ctx.Args().Get(1)); err != nil {
+			return err
+		}
+	}
+	if snapshotPath != "" {
+		content, err := os.ReadFile(snapshotPath)
+		if err != nil {
+			return err
+		}
+		var snapshot state.MultiTxSnapshotDump
+		if err := json.Unmarshal(content, &snapshot); err != nil {
+			return fmt.Errorf("invalid snapshot: %w", err)
+		}
+		a = a.RevertMultiTxSnapshot(&snapshot)
+	}
+
+	diff := state.DiffDumps(a, b)
+	if ctx.Bool(stateDiffJSONFlag.Name) {
+		out, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	printStateDiff(diff)
+	return nil
+}
+
+func readStateDump(path string) (*state.Dump, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dump, err := state.ReadDump(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return dump, nil
+}
+
+func printStateDiff(diff *state.DumpDiff) {
+	fmt.Printf("--- a %s\n+++ b %s\n", stateDiffValue(diff.RootA), stateDiffValue(diff.RootB))
+	for _, account := range diff.Accounts {
+		switch account.Status {
+		case state.AccountAdded:
+			fmt.Printf("+ %s\n", account.Address)
+		case state.AccountRemoved:
+			fmt.Printf("- %s\n", account.Address)
+		default:
+			fmt.Printf("~ %s\n", account.Address)
+		}
+		for _, field := range account.Fields {
+			fmt.Printf("    %s: %s -> %s\n", field.Field, stateDiffValue(field.A), stateDiffValue(field.B))
+		}
+		for _, slot := range account.Storage {
+			fmt.Printf("    storage %s: %s -> %s\n", slot.Key, stateDiffValue(slot.A), stateDiffValue(slot.B))
+		}
+	}
+	fmt.Printf("%d accounts differ\n", len(diff.Accounts))
+}
+
+func stateDiffValue(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
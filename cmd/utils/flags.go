@@ -38,6 +38,8 @@ import (
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/builder"
+	"github.com/ethereum/go-ethereum/builder/eventexport"
+	"github.com/ethereum/go-ethereum/builder/keymanager"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/fdlimit"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -447,6 +449,24 @@ var (
 		Value:    ethconfig.Defaults.TxPool.PrivateTxLifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolBuilderOnlySlotsFlag = &cli.Uint64Flag{
+		Name:     "txpool.builderonlyslots",
+		Usage:    "Maximum number of transactions in the builder-only lane of the private transactions never shared with the peers",
+		Value:    ethconfig.Defaults.TxPool.BuilderOnlySlots,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolBuilderOnlyAccountSlotsFlag = &cli.Uint64Flag{
+		Name:     "txpool.builderonlyaccountslots",
+		Usage:    "Maximum number of transactions of an account in the builder-only lane",
+		Value:    ethconfig.Defaults.TxPool.BuilderOnlyAccountSlots,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolBuilderOnlyLifetimeFlag = &cli.DurationFlag{
+		Name:     "txpool.builderonlylifetime",
+		Usage:    "Maximum amount of time transactions are kept in the builder-only lane",
+		Value:    ethconfig.Defaults.TxPool.BuilderOnlyLifetime,
+		Category: flags.TxPoolCategory,
+	}
 	// Performance tuning settings
 	CacheFlag = &cli.IntFlag{
 		Name:     "cache",
@@ -703,7 +723,7 @@ var (
 	// see setMiner in cmd/utils/flags.go
 	BuilderAlgoTypeFlag = &cli.StringFlag{
 		Name:     "builder.algotype",
-		Usage:    "Block building algorithm to use [=mev-geth] (mev-geth, greedy, greedy-buckets)",
+		Usage:    "Block building algorithm to use [=mev-geth] (mev-geth, greedy, greedy-buckets, greedy-egp, greedy-profit)",
 		Category: flags.BuilderCategory,
 	}
 
@@ -775,7 +795,7 @@ var (
 	}
 	BuilderSecretKey = &cli.StringFlag{
 		Name:     "builder.secret_key",
-		Usage:    "Builder key used for signing blocks",
+		Usage:    "Builder key used for signing blocks, either hex encoded or vault://<secret path>?field=<name>",
 		EnvVars:  []string{"BUILDER_SECRET_KEY"},
 		Value:    "0x2fc12ae741f29701f8e30f5de6350766c020cb80768a0ff01e6838ffd2431e11",
 		Category: flags.BuilderCategory,
@@ -836,6 +856,26 @@ var (
 		Value:    "",
 		Category: flags.BuilderCategory,
 	}
+	BuilderRelaySubmissionTimeout = &cli.DurationFlag{
+		Name:     "builder.relay_submission_timeout",
+		Usage:    "Timeout of every attempt to submit a block to a remote relay",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_RELAY_SUBMISSION_TIMEOUT"},
+		Value:    builder.DefaultConfig.RelaySubmissionTimeout,
+		Category: flags.BuilderCategory,
+	}
+	BuilderRelaySubmissionRetries = &cli.IntFlag{
+		Name:     "builder.relay_submission_retries",
+		Usage:    "Number of times a block submission failing with an error, a timeout, a 429 or a 5xx response is retried",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_RELAY_SUBMISSION_RETRIES"},
+		Value:    builder.DefaultConfig.RelaySubmissionRetries,
+		Category: flags.BuilderCategory,
+	}
+	BuilderRelaySigningKey = &cli.StringFlag{
+		Name:     "builder.relay_signing_key",
+		Usage:    "Key signing the block submissions to the remote relays, either hex encoded or vault://<secret path>?field=<name>, unsigned if empty",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_RELAY_SIGNING_KEY"},
+		Category: flags.BuilderCategory,
+	}
 
 	// Builder rate limit settings
 
@@ -877,6 +917,50 @@ var (
 		Category: flags.BuilderCategory,
 	}
 
+	BuilderBorPeriod = &cli.DurationFlag{
+		Name: "builder.bor_period",
+		Usage: "Bor block period, the building jobs are timed to the Bor sealing deadlines instead of the beacon slots " +
+			"and re-build as new transactions arrive. 0 to follow the beacon slots",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_BOR_PERIOD"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderBorProducerDelay = &cli.DurationFlag{
+		Name:     "builder.bor_producer_delay",
+		Usage:    "Bor delay before the first block of a sprint, the block period if 0",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_BOR_PRODUCER_DELAY"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderBorSprint = &cli.Uint64Flag{
+		Name:     "builder.bor_sprint",
+		Usage:    "Bor sprint length, the blocks produced in a row by the same producer",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_BOR_SPRINT"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderBorBackupDelay = &cli.DurationFlag{
+		Name:     "builder.bor_backup_delay",
+		Usage:    "Bor extra delay given to each backup producer when the in-turn producer misses its block",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_BOR_BACKUP_DELAY"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderProducers = &cli.StringFlag{
+		Name: "builder.producers",
+		Usage: "Comma separated list of the Bor producers the builder builds for, our validator and the connected proposers. " +
+			"The slots of the other producers are skipped, following the Heimdall spans of --builder.heimdall_url and the --builder.bor_sprint",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_PRODUCERS"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderAlwaysBuild = &cli.BoolFlag{
+		Name:     "builder.always_build",
+		Usage:    "Build every slot whichever Bor producer is in turn, for relay-style operation",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_ALWAYS_BUILD"},
+		Category: flags.BuilderCategory,
+	}
+
 	BuilderDiscardRevertibleTxOnErr = &cli.BoolFlag{
 		Name: "builder.discard_revertible_tx_on_error",
 		Usage: "When enabled, if a transaction submitted as part of a bundle in a send bundle request has error on commit, " +
@@ -893,6 +977,452 @@ var (
 		Category: flags.BuilderCategory,
 	}
 
+	BuilderBundleEncryptionKeyFile = &cli.StringFlag{
+		Name: "builder.bundle_encryption_key_file",
+		Usage: "File holding the hex encoded secp256k1 private key used to decrypt encrypted bundles. When set, signed searchers can " +
+			"submit bundles encrypted to the matching public key (see eth_bundleEncryptionKey) via eth_sendEncryptedBundle",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_BUNDLE_ENCRYPTION_KEY_FILE"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderAuditLog = &cli.StringFlag{
+		Name:     "builder.audit_log",
+		Usage:    "Path of the append-only, hash-chained audit log of received bundles, inclusion decisions and block submissions. Verify it with the auditverify tool",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_AUDIT_LOG"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderSimMaxMemorySize = &cli.Uint64Flag{
+		Name:     "builder.sim_max_memory_size",
+		Usage:    "Maximum memory size in bytes of a single call frame when simulating bundles, exceeding it fails the simulation (0 = unlimited)",
+		Value:    ethconfig.Defaults.Miner.SimulationLimits.MaxMemorySize,
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderSimMaxReturnDataSize = &cli.Uint64Flag{
+		Name:     "builder.sim_max_return_data_size",
+		Usage:    "Maximum size in bytes of data returned by a call frame or precompile when simulating bundles, exceeding it fails the simulation (0 = unlimited)",
+		Value:    ethconfig.Defaults.Miner.SimulationLimits.MaxReturnDataSize,
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderSimMaxPrecompileInputSize = &cli.Uint64Flag{
+		Name:     "builder.sim_max_precompile_input_size",
+		Usage:    "Maximum precompile call input size in bytes when simulating bundles, exceeding it fails the simulation (0 = unlimited)",
+		Value:    ethconfig.Defaults.Miner.SimulationLimits.MaxPrecompileInputSize,
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderTxSigner = &cli.StringFlag{
+		Name: "builder.tx_signer",
+		Usage: "Signer of the builder payout and refund transactions, overrides BUILDER_TX_SIGNING_KEY. Either a hex private key, " +
+			"vault://<secret path>?field=<name> to read the key from HashiCorp Vault ($VAULT_ADDR, $VAULT_TOKEN) or " +
+			"awskms://<key id>?region=<region> to sign with an AWS KMS secp256k1 key. PKCS#11 tokens are not supported",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_TX_SIGNER"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderProtectiveOrdering = &cli.BoolFlag{
+		Name:     "builder.protective_ordering",
+		Usage:    "Reject bundles sandwiching a mempool transaction (front-run and back-run swaps on the same Uniswap V2/V3 pool)",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_PROTECTIVE_ORDERING"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderDepositGateContract = &cli.StringFlag{
+		Name: "builder.deposit_gate_contract",
+		Usage: "Address of a contract exposing deposits(address) returns (uint256). When set, searchers that are not allowlisted " +
+			"need to sign their requests and hold a deposit in the contract to submit bundles, their submission quota grows with the deposit",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_DEPOSIT_GATE_CONTRACT"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderDepositGateUnit = &cli.StringFlag{
+		Name:     "builder.deposit_gate_unit",
+		Usage:    "Deposit in wei granting one unit of bundle submission quota",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_DEPOSIT_GATE_UNIT"},
+		Value:    builder.DefaultConfig.DepositGateUnit,
+		Category: flags.BuilderCategory,
+	}
+	BuilderDepositGateBundlesPerUnit = &cli.Uint64Flag{
+		Name:     "builder.deposit_gate_bundles_per_unit",
+		Usage:    "Bundle submissions per minute granted by each unit of deposit",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_DEPOSIT_GATE_BUNDLES_PER_UNIT"},
+		Value:    builder.DefaultConfig.DepositGateBundlesPerUnit,
+		Category: flags.BuilderCategory,
+	}
+	BuilderDepositGateAllowlist = &cli.StringFlag{
+		Name:     "builder.deposit_gate_allowlist",
+		Usage:    "Comma separated list of searcher addresses exempt from the deposit requirement",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_DEPOSIT_GATE_ALLOWLIST"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderSearcherAuthRequired = &cli.BoolFlag{
+		Name:     "builder.searcher_auth_required",
+		Usage:    "Reject the bundle submissions which are not signed with the " + rpc.SignatureHeader + " header",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_SEARCHER_AUTH_REQUIRED"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherRateLimit = &cli.Float64Flag{
+		Name:     "builder.searcher_rate_limit",
+		Usage:    "Bundle submissions per second allowed to every request signer (0 = unlimited)",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_SEARCHER_RATE_LIMIT"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherRateBurst = &cli.IntFlag{
+		Name:     "builder.searcher_rate_burst",
+		Usage:    "Bundle submissions a request signer can make at once",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_SEARCHER_RATE_BURST"},
+		Value:    builder.DefaultConfig.SearcherRateBurst,
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherMaxBundles = &cli.IntFlag{
+		Name:     "builder.searcher_max_bundles",
+		Usage:    "Maximum number of bundles of a request signer in the bundle pool (0 = unlimited)",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_SEARCHER_MAX_BUNDLES"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherAllowlist = &cli.StringFlag{
+		Name:     "builder.searcher_allowlist",
+		Usage:    "Comma separated list of request signers exempt from the rate limit, replaceable with builder_setSearcherLists",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_SEARCHER_ALLOWLIST"},
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherDenylist = &cli.StringFlag{
+		Name:     "builder.searcher_denylist",
+		Usage:    "Comma separated list of request signers whose bundles are rejected, replaceable with builder_setSearcherLists",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_SEARCHER_DENYLIST"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderGriefingGasThreshold = &cli.Uint64Flag{
+		Name:     "builder.griefing_gas_threshold",
+		Usage:    "Gas burned by a reverting bundle simulation that counts as a griefing strike against the searcher (0 = disable griefing detection)",
+		Value:    ethconfig.Defaults.Miner.GriefingDetection.GasThreshold,
+		Category: flags.BuilderCategory,
+	}
+	BuilderGriefingMaxStrikes = &cli.IntFlag{
+		Name:     "builder.griefing_max_strikes",
+		Usage:    "Griefing strikes within builder.griefing_window after which the searcher is quarantined",
+		Value:    ethconfig.Defaults.Miner.GriefingDetection.MaxStrikes,
+		Category: flags.BuilderCategory,
+	}
+	BuilderGriefingWindow = &cli.DurationFlag{
+		Name:     "builder.griefing_window",
+		Usage:    "Period over which griefing strikes are counted",
+		Value:    ethconfig.Defaults.Miner.GriefingDetection.Window,
+		Category: flags.BuilderCategory,
+	}
+	BuilderGriefingQuarantine = &cli.DurationFlag{
+		Name:     "builder.griefing_quarantine",
+		Usage:    "Period during which bundles of a quarantined searcher are dropped",
+		Value:    ethconfig.Defaults.Miner.GriefingDetection.Quarantine,
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherReputationFile = &cli.StringFlag{
+		Name:     "builder.searcher_reputation_file",
+		Usage:    "JSON file the searcher reputations (simulation failures, reverts on chain, profit delivered) are persisted to across restarts, kept in memory if empty",
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherReputationHighLoad = &cli.IntFlag{
+		Name:     "builder.searcher_reputation_high_load",
+		Usage:    "Bundles to simulate for a block above which they are simulated by searcher reputation and the low reputation searchers are capped (0 = disabled)",
+		Value:    ethconfig.Defaults.Miner.Reputation.HighLoad,
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherReputationLowScore = &cli.Float64Flag{
+		Name:     "builder.searcher_reputation_low_score",
+		Usage:    "Reputation score, between 0 and 1, under which a searcher has a low reputation",
+		Value:    ethconfig.Defaults.Miner.Reputation.LowScore,
+		Category: flags.BuilderCategory,
+	}
+	BuilderSearcherReputationLowBundles = &cli.IntFlag{
+		Name:     "builder.searcher_reputation_low_bundles",
+		Usage:    "Bundles of a low reputation searcher simulated per block under high load",
+		Value:    ethconfig.Defaults.Miner.Reputation.LowReputationBundles,
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderBidPolicy = &cli.StringFlag{
+		Name:     "builder.bid_policy",
+		Usage:    "Policy deciding the value paid to the proposer out of the block profit: full, fixed (keep --builder.bid_margin), percentage (keep --builder.bid_margin_percent of the profit) or dynamic (bid the median winning bid of the recent blocks, keeping up to --builder.bid_margin_percent)",
+		Value:    ethconfig.Defaults.Miner.BidPolicy.Type,
+		Category: flags.BuilderCategory,
+	}
+	BuilderBidMargin = &flags.BigFlag{
+		Name:     "builder.bid_margin",
+		Usage:    "Wei kept by the builder per block with the fixed bid policy",
+		Category: flags.BuilderCategory,
+	}
+	BuilderBidMarginPercent = &cli.Float64Flag{
+		Name:     "builder.bid_margin_percent",
+		Usage:    "Percent of the block profit kept by the builder with the percentage bid policy, the most kept with the dynamic bid policy",
+		Value:    ethconfig.Defaults.Miner.BidPolicy.MarginPercent,
+		Category: flags.BuilderCategory,
+	}
+	BuilderBidDynamicBlocks = &cli.IntFlag{
+		Name:     "builder.bid_dynamic_blocks",
+		Usage:    "Recent blocks whose winning bids the dynamic bid policy bids against",
+		Value:    ethconfig.Defaults.Miner.BidPolicy.Blocks,
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderHeimdallURL = &cli.StringFlag{
+		Name:     "builder.heimdall_url",
+		Usage:    "Comma separated list of Heimdall REST endpoints, in order of preference, the Bor spans, checkpoints and state-sync events are fetched from. The state-sync events are committed in the first block of each Bor sprint (empty = disabled)",
+		Category: flags.BuilderCategory,
+	}
+	BuilderHeimdallTimeout = &cli.DurationFlag{
+		Name:     "builder.heimdall_timeout",
+		Usage:    "Timeout of a request to a Heimdall endpoint, after which the next endpoint is tried",
+		Value:    ethconfig.Defaults.Miner.Heimdall.Timeout,
+		Category: flags.BuilderCategory,
+	}
+	BuilderStateSyncSprint = &cli.Uint64Flag{
+		Name:     "builder.state_sync_sprint",
+		Usage:    "Bor sprint length, the state-sync events are committed in the blocks whose number is a multiple of it",
+		Value:    ethconfig.Defaults.Miner.StateSync.Sprint,
+		Category: flags.BuilderCategory,
+	}
+	BuilderStateSyncDelay = &cli.DurationFlag{
+		Name:     "builder.state_sync_delay",
+		Usage:    "Age of the state-sync events committed in a block, relative to the block timestamp",
+		Value:    ethconfig.Defaults.Miner.StateSync.ConfirmationDelay,
+		Category: flags.BuilderCategory,
+	}
+	BuilderPreSimulationWorkers = &cli.IntFlag{
+		Name:     "builder.presimulation_workers",
+		Usage:    "Number of goroutines simulating the bundles in the background as they enter the pool, by searcher reputation, at the top of the block being built (0 = disabled)",
+		Value:    ethconfig.Defaults.Miner.PreSimulation.Workers,
+		Category: flags.BuilderCategory,
+	}
+	BuilderParentReexec = &cli.Uint64Flag{
+		Name:     "builder.parent_reexec",
+		Usage:    "Maximum number of blocks re-executed to derive the state of a non-canonical parent to build on (0 = only build on stored states)",
+		Value:    ethconfig.Defaults.Miner.ParentReexec,
+		Category: flags.BuilderCategory,
+	}
+	BuilderPrefetchBudget = &cli.IntFlag{
+		Name:     "builder.prefetch_budget",
+		Usage:    "Maximum number of accounts and storage slots touched by the bundles on the previous head whose state is prefetched on a new head (0 = disabled)",
+		Value:    ethconfig.Defaults.Miner.PrefetchBudget,
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderMaxBlockStateGrowth = &cli.Uint64Flag{
+		Name:     "builder.max_block_state_growth",
+		Usage:    "Maximum number of accounts and storage slots the bundles of a block may create, bundles exceeding the budget are skipped (0 = unlimited)",
+		Category: flags.BuilderCategory,
+	}
+	BuilderMinStateGrowthProfit = &flags.BigFlag{
+		Name:     "builder.min_state_growth_profit",
+		Usage:    "Minimum bundle profit in wei per account or storage slot created by the bundle, bundles below it are dropped (0 = disabled)",
+		Category: flags.BuilderCategory,
+	}
+	BuilderMinBundleGasPrice = &flags.BigFlag{
+		Name:     "builder.min_bundle_gas_price",
+		Usage:    "Minimum effective gas price in wei of the bundles accepted in a block, bundles below it are dropped (0 = disabled)",
+		Category: flags.BuilderCategory,
+	}
+	BuilderMinBundleProfit = &flags.BigFlag{
+		Name:     "builder.min_bundle_profit",
+		Usage:    "Minimum coinbase profit in wei of the bundles accepted in a block, bundles below it are dropped (0 = disabled)",
+		Category: flags.BuilderCategory,
+	}
+	BuilderSprintMinBundleGasPrice = &flags.BigFlag{
+		Name:     "builder.sprint_min_bundle_gas_price",
+		Usage:    "Minimum effective gas price in wei of the bundles accepted in the first block of a Bor sprint (default = builder.min_bundle_gas_price)",
+		Category: flags.BuilderCategory,
+	}
+	BuilderSprintMinBundleProfit = &flags.BigFlag{
+		Name:     "builder.sprint_min_bundle_profit",
+		Usage:    "Minimum coinbase profit in wei of the bundles accepted in the first block of a Bor sprint (default = builder.min_bundle_profit)",
+		Category: flags.BuilderCategory,
+	}
+	BuilderTopOfBlockGas = &cli.Uint64Flag{
+		Name:     "builder.tob_gas",
+		Usage:    "Gas reserved at the top of the blocks to the bundles and transactions of --builder.tob_searchers and --builder.tob_contracts, the unused gas is released to the other orders (0 = disabled)",
+		Category: flags.BuilderCategory,
+	}
+	BuilderTopOfBlockSearchers = &cli.StringFlag{
+		Name:     "builder.tob_searchers",
+		Usage:    "Comma separated searcher addresses whose bundles and transactions may use the gas reserved at the top of the blocks",
+		Category: flags.BuilderCategory,
+	}
+	BuilderTopOfBlockContracts = &cli.StringFlag{
+		Name:     "builder.tob_contracts",
+		Usage:    "Comma separated contract addresses, e.g. oracles, the bundles and transactions calling only them may use the gas reserved at the top of the blocks",
+		Category: flags.BuilderCategory,
+	}
+	BuilderMultiTxSnapshotMemoryLimit = &cli.Uint64Flag{
+		Name:     "builder.multi_tx_snapshot_memory_limit",
+		Usage:    "Memory cap in MB of the multi-transaction snapshots of a block, no more orders are committed to the block once reached unless the snapshots are spilled (0 = unlimited)",
+		Category: flags.BuilderCategory,
+	}
+	BuilderMultiTxSnapshotSpill = &cli.BoolFlag{
+		Name:     "builder.multi_tx_snapshot_spill",
+		Usage:    "Spill the oldest multi-transaction snapshots over the memory cap to a temporary pebble store",
+		Category: flags.BuilderCategory,
+	}
+	BuilderWitnessDir = &cli.StringFlag{
+		Name:     "builder.witness_dir",
+		Usage:    "Directory the execution witness of every sealed block is written to, with the trie nodes and codes needed to validate the block without the state. Witnesses are kept for the last 64 blocks",
+		Category: flags.BuilderCategory,
+	}
+	BuilderVerifySnapshots = &cli.BoolFlag{
+		Name:     "builder.verify_snapshots",
+		Usage:    "Debug mode comparing the state root after each multi-transaction snapshot revert with the root before the snapshot, a mismatch logs the changed accounts and slots and stops the block",
+		Category: flags.BuilderCategory,
+	}
+	BuilderWorkers = &cli.IntFlag{
+		Name:     "builder.workers",
+		Usage:    "Number of greedy workers building every block in parallel, each with a different algorithm starting with builder.algotype, the most profitable block is submitted",
+		Value:    1,
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderLogBundleContent = &cli.DurationFlag{
+		Name: "builder.log_bundle_content",
+		Usage: "Log full bundle contents (transactions, calldata, error data) for the given period after startup, " +
+			"otherwise only hashes and metadata are logged. Only meant for temporary debugging",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_LOG_BUNDLE_CONTENT"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderDecisionLog = &cli.StringFlag{
+		Name:     "builder.decision_log",
+		Usage:    "Path of the file the JSON inclusion decision record of every built block is appended to, or \"log\" to write the records to the node log",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_DECISION_LOG"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderBuildTrace = &cli.StringFlag{
+		Name:     "builder.build_trace",
+		Usage:    "Path of the file the JSON trace of every block building round is appended to, for offline replay with geth builder replay. Only meant for debugging",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_BUILD_TRACE"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderEventsURL = &cli.StringFlag{
+		Name: "builder.events_url",
+		Usage: "Broker the builder lifecycle events (bundle received, simulated, block built, submitted, landed) are exported to: " +
+			"nats://[user:password@]host:port for NATS, or the http(s) url of a Kafka REST proxy. Disabled if empty",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_EVENTS_URL"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderEventsTopicPrefix = &cli.StringFlag{
+		Name:     "builder.events_topic_prefix",
+		Usage:    "Prefix of the topics the builder events are exported to, events are published to \"<prefix>.<event type>\"",
+		Value:    eventexport.DefaultTopicPrefix,
+		EnvVars:  []string{"FLASHBOTS_BUILDER_EVENTS_TOPIC_PREFIX"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderProfileDir = &cli.StringFlag{
+		Name: "builder.profile_dir",
+		Usage: "Directory the builder_cpuProfile, builder_heapProfile, builder_blockProfile, builder_mutexProfile and " +
+			"builder_goroutineProfile endpoints of the authenticated builder API write profiles to. Disabled if empty",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_PROFILE_DIR"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderAlertWebhooks = &cli.StringFlag{
+		Name: "builder.alert_webhooks",
+		Usage: "Comma separated list of webhook urls builder anomalies are posted to: build failures, block profit below " +
+			"builder.alert_min_profit, relay demotions and multi-tx snapshot invalidations. Disabled if empty",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_ALERT_WEBHOOKS"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderAlertTemplate = &cli.StringFlag{
+		Name: "builder.alert_template",
+		Usage: "Path to a Go text/template rendering the alert webhook payload from the alert fields .Kind, .Time, " +
+			".Message, .Details and .Suppressed. Alerts are posted as JSON if empty",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_ALERT_TEMPLATE"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderAlertMinInterval = &cli.DurationFlag{
+		Name:     "builder.alert_min_interval",
+		Usage:    "Minimum interval between two alerts of the same kind, alerts in between are dropped",
+		Value:    builder.DefaultConfig.AlertMinInterval,
+		EnvVars:  []string{"FLASHBOTS_BUILDER_ALERT_MIN_INTERVAL"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderAlertMinProfit = &cli.StringFlag{
+		Name:     "builder.alert_min_profit",
+		Usage:    "Alert when the best block built for a slot is worth less than this amount of wei for consecutive slots. Disabled if empty",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_ALERT_MIN_PROFIT"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderAlertLowProfitSlots = &cli.IntFlag{
+		Name:     "builder.alert_low_profit_slots",
+		Usage:    "Number of consecutive slots below builder.alert_min_profit raising a low profit alert",
+		Value:    builder.DefaultConfig.AlertLowProfitSlots,
+		EnvVars:  []string{"FLASHBOTS_BUILDER_ALERT_LOW_PROFIT_SLOTS"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderBundleRecord = &cli.StringFlag{
+		Name:     "builder.bundle_record",
+		Usage:    "Path of the file every incoming bundle is recorded to with its arrival time. Replay it against a builder with the bundlereplay tool",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_BUNDLE_RECORD"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderConfigFile = &cli.StringFlag{
+		Name:     "builder.config",
+		Usage:    "Path of the TOML builder config file with relays, algorithm, limits, blocklists and timing sections. Its values take precedence over the flags, limits, timing, blocklists and per-relay settings are reloaded on SIGHUP",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_CONFIG"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderCandidateDump = &cli.StringFlag{
+		Name:     "builder.candidate_dump",
+		Usage:    "Directory every sealed candidate block is written to for debugging, with its header, transactions and profit breakdown. Candidates are kept for the last 16 slots",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_CANDIDATE_DUMP"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderShadow = &cli.BoolFlag{
+		Name:     "builder.shadow",
+		Usage:    "Run the full block building pipeline every slot without sealing or submitting, and compare the best candidate with the block that landed (see builder_shadowComparisons and builder_shadowSummary)",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_SHADOW"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderFaults = &cli.StringFlag{
+		Name:     "builder.faults",
+		Usage:    "Comma separated faults injected for resilience testing, each as name[:rate][:delay] with name one of relay_timeout, beacon_outage, slow_disk and snapshot_invalidation. Requires a build with the faultinject tag",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_FAULTS"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderStandby = &cli.StringFlag{
+		Name:     "builder.standby",
+		Usage:    "Authenticated RPC endpoint of the active builder. The builder runs as its hot standby with submissions paused, mirrors its bundle pool and takes over the submissions when its heartbeat stops",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_STANDBY"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderStandbyJWTSecret = &cli.StringFlag{
+		Name:     "builder.standby_jwtsecret",
+		Usage:    "Path of the JWT secret of the authenticated RPC of the active builder (default = the JWT secret of this node)",
+		EnvVars:  []string{"FLASHBOTS_BUILDER_STANDBY_JWTSECRET"},
+		Category: flags.BuilderCategory,
+	}
+
+	BuilderStandbyTimeout = &cli.DurationFlag{
+		Name:     "builder.standby_timeout",
+		Usage:    "Time without heartbeat of the active builder after which the standby takes over the submissions, shorter than a slot",
+		Value:    builder.DefaultConfig.StandbyTimeout,
+		EnvVars:  []string{"FLASHBOTS_BUILDER_STANDBY_TIMEOUT"},
+		Category: flags.BuilderCategory,
+	}
+
 	// RPC settings
 	IPCDisabledFlag = &cli.BoolFlag{
 		Name:     "ipcdisable",
@@ -1708,6 +2238,9 @@ func SetBuilderConfig(ctx *cli.Context, cfg *builder.Config) {
 	cfg.BeaconEndpoints = strings.Split(ctx.String(BuilderBeaconEndpoints.Name), ",")
 	cfg.RemoteRelayEndpoint = ctx.String(BuilderRemoteRelayEndpoint.Name)
 	cfg.SecondaryRemoteRelayEndpoints = strings.Split(ctx.String(BuilderSecondaryRemoteRelayEndpoints.Name), ",")
+	cfg.RelaySubmissionTimeout = ctx.Duration(BuilderRelaySubmissionTimeout.Name)
+	cfg.RelaySubmissionRetries = ctx.Int(BuilderRelaySubmissionRetries.Name)
+	cfg.RelaySigningKey = ctx.String(BuilderRelaySigningKey.Name)
 	// NOTE: This flag is deprecated and will be removed in the future in favor of BuilderBlockValidationBlacklistSourceFilePath
 	if ctx.IsSet(MinerBlocklistFileFlag.Name) {
 		cfg.ValidationBlocklist = ctx.String(MinerBlocklistFileFlag.Name)
@@ -1721,9 +2254,56 @@ func SetBuilderConfig(ctx *cli.Context, cfg *builder.Config) {
 	cfg.BuilderRateLimitDuration = ctx.String(BuilderRateLimitDuration.Name)
 	cfg.BuilderRateLimitMaxBurst = ctx.Int(BuilderRateLimitMaxBurst.Name)
 	cfg.BuilderSubmissionOffset = ctx.Duration(BuilderSubmissionOffset.Name)
+	cfg.BorPeriod = ctx.Duration(BuilderBorPeriod.Name)
+	cfg.BorProducerDelay = ctx.Duration(BuilderBorProducerDelay.Name)
+	cfg.BorSprint = ctx.Uint64(BuilderBorSprint.Name)
+	cfg.BorBackupDelay = ctx.Duration(BuilderBorBackupDelay.Name)
+	if ctx.IsSet(BuilderProducers.Name) {
+		cfg.Producers = strings.Split(ctx.String(BuilderProducers.Name), ",")
+	}
+	cfg.AlwaysBuild = ctx.Bool(BuilderAlwaysBuild.Name)
 	cfg.DiscardRevertibleTxOnErr = ctx.Bool(BuilderDiscardRevertibleTxOnErr.Name)
 	cfg.EnableCancellations = ctx.IsSet(BuilderEnableCancellations.Name)
 	cfg.BuilderRateLimitResubmitInterval = ctx.String(BuilderBlockResubmitInterval.Name)
+	cfg.BundleEncryptionKeyFile = ctx.String(BuilderBundleEncryptionKeyFile.Name)
+	cfg.AuditLogPath = ctx.String(BuilderAuditLog.Name)
+	cfg.DepositGateContract = ctx.String(BuilderDepositGateContract.Name)
+	cfg.DepositGateUnit = ctx.String(BuilderDepositGateUnit.Name)
+	cfg.DepositGateBundlesPerUnit = ctx.Uint64(BuilderDepositGateBundlesPerUnit.Name)
+	if ctx.IsSet(BuilderDepositGateAllowlist.Name) {
+		cfg.DepositGateAllowlist = strings.Split(ctx.String(BuilderDepositGateAllowlist.Name), ",")
+	}
+	cfg.SearcherAuthRequired = ctx.Bool(BuilderSearcherAuthRequired.Name)
+	cfg.SearcherRateLimit = ctx.Float64(BuilderSearcherRateLimit.Name)
+	cfg.SearcherRateBurst = ctx.Int(BuilderSearcherRateBurst.Name)
+	cfg.SearcherMaxBundles = ctx.Int(BuilderSearcherMaxBundles.Name)
+	if ctx.IsSet(BuilderSearcherAllowlist.Name) {
+		cfg.SearcherAllowlist = strings.Split(ctx.String(BuilderSearcherAllowlist.Name), ",")
+	}
+	if ctx.IsSet(BuilderSearcherDenylist.Name) {
+		cfg.SearcherDenylist = strings.Split(ctx.String(BuilderSearcherDenylist.Name), ",")
+	}
+	cfg.LogBundleContent = ctx.Duration(BuilderLogBundleContent.Name)
+	cfg.DecisionLog = ctx.String(BuilderDecisionLog.Name)
+	cfg.BuildTrace = ctx.String(BuilderBuildTrace.Name)
+	cfg.EventsURL = ctx.String(BuilderEventsURL.Name)
+	cfg.EventsTopicPrefix = ctx.String(BuilderEventsTopicPrefix.Name)
+	cfg.ProfileDir = ctx.String(BuilderProfileDir.Name)
+	if ctx.IsSet(BuilderAlertWebhooks.Name) {
+		cfg.AlertWebhooks = strings.Split(ctx.String(BuilderAlertWebhooks.Name), ",")
+	}
+	cfg.AlertTemplate = ctx.String(BuilderAlertTemplate.Name)
+	cfg.AlertMinInterval = ctx.Duration(BuilderAlertMinInterval.Name)
+	cfg.AlertMinProfit = ctx.String(BuilderAlertMinProfit.Name)
+	cfg.AlertLowProfitSlots = ctx.Int(BuilderAlertLowProfitSlots.Name)
+	cfg.BundleRecordPath = ctx.String(BuilderBundleRecord.Name)
+	cfg.ConfigFile = ctx.String(BuilderConfigFile.Name)
+	cfg.CandidateDumpDir = ctx.String(BuilderCandidateDump.Name)
+	cfg.Shadow = ctx.Bool(BuilderShadow.Name)
+	cfg.Faults = ctx.String(BuilderFaults.Name)
+	cfg.Standby = ctx.String(BuilderStandby.Name)
+	cfg.StandbyJWTSecret = ctx.String(BuilderStandbyJWTSecret.Name)
+	cfg.StandbyTimeout = ctx.Duration(BuilderStandbyTimeout.Name)
 }
 
 // SetNodeConfig applies node-related command line flags to the config.
@@ -1832,6 +2412,19 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config, light bool) {
 	}
 }
 
+// splitAddresses parses the comma separated addresses of a flag.
+func splitAddresses(ctx *cli.Context, name string) []common.Address {
+	var addresses []common.Address
+	for _, address := range strings.Split(ctx.String(name), ",") {
+		if trimmed := strings.TrimSpace(address); !common.IsHexAddress(trimmed) {
+			Fatalf("Invalid address in --%s: %s", name, trimmed)
+		} else {
+			addresses = append(addresses, common.HexToAddress(trimmed))
+		}
+	}
+	return addresses
+}
+
 func setTxPool(ctx *cli.Context, cfg *txpool.Config) {
 	if ctx.IsSet(TxPoolLocalsFlag.Name) {
 		locals := strings.Split(ctx.String(TxPoolLocalsFlag.Name), ",")
@@ -1876,6 +2469,15 @@ func setTxPool(ctx *cli.Context, cfg *txpool.Config) {
 	if ctx.IsSet(TxPoolPrivateLifetimeFlag.Name) {
 		cfg.PrivateTxLifetime = ctx.Duration(TxPoolPrivateLifetimeFlag.Name)
 	}
+	if ctx.IsSet(TxPoolBuilderOnlySlotsFlag.Name) {
+		cfg.BuilderOnlySlots = ctx.Uint64(TxPoolBuilderOnlySlotsFlag.Name)
+	}
+	if ctx.IsSet(TxPoolBuilderOnlyAccountSlotsFlag.Name) {
+		cfg.BuilderOnlyAccountSlots = ctx.Uint64(TxPoolBuilderOnlyAccountSlotsFlag.Name)
+	}
+	if ctx.IsSet(TxPoolBuilderOnlyLifetimeFlag.Name) {
+		cfg.BuilderOnlyLifetime = ctx.Duration(TxPoolBuilderOnlyLifetimeFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *ethconfig.Config) {
@@ -1973,6 +2575,74 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 
 	cfg.DiscardRevertibleTxOnErr = ctx.Bool(BuilderDiscardRevertibleTxOnErr.Name)
 	cfg.PriceCutoffPercent = ctx.Int(BuilderPriceCutoffPercentFlag.Name)
+	cfg.SimulationLimits.MaxMemorySize = ctx.Uint64(BuilderSimMaxMemorySize.Name)
+	cfg.SimulationLimits.MaxReturnDataSize = ctx.Uint64(BuilderSimMaxReturnDataSize.Name)
+	cfg.SimulationLimits.MaxPrecompileInputSize = ctx.Uint64(BuilderSimMaxPrecompileInputSize.Name)
+	cfg.ProtectiveOrdering = ctx.Bool(BuilderProtectiveOrdering.Name)
+	cfg.GriefingDetection.GasThreshold = ctx.Uint64(BuilderGriefingGasThreshold.Name)
+	cfg.GriefingDetection.MaxStrikes = ctx.Int(BuilderGriefingMaxStrikes.Name)
+	cfg.GriefingDetection.Window = ctx.Duration(BuilderGriefingWindow.Name)
+	cfg.GriefingDetection.Quarantine = ctx.Duration(BuilderGriefingQuarantine.Name)
+	cfg.Reputation.File = ctx.String(BuilderSearcherReputationFile.Name)
+	cfg.Reputation.HighLoad = ctx.Int(BuilderSearcherReputationHighLoad.Name)
+	cfg.Reputation.LowScore = ctx.Float64(BuilderSearcherReputationLowScore.Name)
+	cfg.Reputation.LowReputationBundles = ctx.Int(BuilderSearcherReputationLowBundles.Name)
+	cfg.BidPolicy.Type = ctx.String(BuilderBidPolicy.Name)
+	if ctx.IsSet(BuilderBidMargin.Name) {
+		cfg.BidPolicy.Margin = flags.GlobalBig(ctx, BuilderBidMargin.Name)
+	}
+	cfg.BidPolicy.MarginPercent = ctx.Float64(BuilderBidMarginPercent.Name)
+	cfg.BidPolicy.Blocks = ctx.Int(BuilderBidDynamicBlocks.Name)
+	if err := cfg.BidPolicy.Validate(); err != nil {
+		Fatalf("Invalid bid policy: %v", err)
+	}
+	if ctx.IsSet(BuilderHeimdallURL.Name) {
+		cfg.Heimdall.Endpoints = strings.Split(ctx.String(BuilderHeimdallURL.Name), ",")
+	}
+	cfg.Heimdall.Timeout = ctx.Duration(BuilderHeimdallTimeout.Name)
+	cfg.StateSync.Sprint = ctx.Uint64(BuilderStateSyncSprint.Name)
+	cfg.StateSync.ConfirmationDelay = ctx.Duration(BuilderStateSyncDelay.Name)
+	cfg.ParentReexec = ctx.Uint64(BuilderParentReexec.Name)
+	cfg.PrefetchBudget = ctx.Int(BuilderPrefetchBudget.Name)
+	cfg.PreSimulation.Workers = ctx.Int(BuilderPreSimulationWorkers.Name)
+	cfg.StateGrowth.MaxPerBlock = ctx.Uint64(BuilderMaxBlockStateGrowth.Name)
+	if ctx.IsSet(BuilderMinStateGrowthProfit.Name) {
+		cfg.StateGrowth.MinProfitPerItem = flags.GlobalBig(ctx, BuilderMinStateGrowthProfit.Name)
+	}
+	if ctx.IsSet(BuilderMinBundleGasPrice.Name) {
+		cfg.BundleFloor.MinGasPrice = flags.GlobalBig(ctx, BuilderMinBundleGasPrice.Name)
+	}
+	if ctx.IsSet(BuilderMinBundleProfit.Name) {
+		cfg.BundleFloor.MinProfit = flags.GlobalBig(ctx, BuilderMinBundleProfit.Name)
+	}
+	if ctx.IsSet(BuilderSprintMinBundleGasPrice.Name) {
+		cfg.BundleFloor.SprintMinGasPrice = flags.GlobalBig(ctx, BuilderSprintMinBundleGasPrice.Name)
+	}
+	if ctx.IsSet(BuilderSprintMinBundleProfit.Name) {
+		cfg.BundleFloor.SprintMinProfit = flags.GlobalBig(ctx, BuilderSprintMinBundleProfit.Name)
+	}
+	cfg.TopOfBlock.Gas = ctx.Uint64(BuilderTopOfBlockGas.Name)
+	if ctx.IsSet(BuilderTopOfBlockSearchers.Name) {
+		cfg.TopOfBlock.Searchers = splitAddresses(ctx, BuilderTopOfBlockSearchers.Name)
+	}
+	if ctx.IsSet(BuilderTopOfBlockContracts.Name) {
+		cfg.TopOfBlock.Contracts = splitAddresses(ctx, BuilderTopOfBlockContracts.Name)
+	}
+	cfg.MultiTxSnapshotMemoryLimit = ctx.Uint64(BuilderMultiTxSnapshotMemoryLimit.Name) * 1024 * 1024
+	cfg.MultiTxSnapshotSpill = ctx.Bool(BuilderMultiTxSnapshotSpill.Name)
+	cfg.VerifyMultiTxSnapshots = ctx.Bool(BuilderVerifySnapshots.Name)
+	cfg.WitnessDir = ctx.String(BuilderWitnessDir.Name)
+	cfg.Workers = ctx.Int(BuilderWorkers.Name)
+	// the blocks built in shadow mode never leave the node
+	cfg.NoSeal = ctx.Bool(BuilderShadow.Name)
+
+	if ctx.IsSet(BuilderTxSigner.Name) {
+		txSigner, err := keymanager.NewTxSigner(ctx.String(BuilderTxSigner.Name))
+		if err != nil {
+			Fatalf("Failed to set up builder tx signer: %v", err)
+		}
+		cfg.BuilderTxSigner = txSigner
+	}
 }
 
 func setRequiredBlocks(ctx *cli.Context, cfg *ethconfig.Config) {
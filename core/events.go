@@ -24,6 +24,13 @@ import (
 // NewTxsEvent is posted when a batch of transactions enter the transaction pool.
 type NewTxsEvent struct{ Txs []*types.Transaction }
 
+// NewMevBundlesEvent is posted when mev bundles enter the transaction pool.
+type NewMevBundlesEvent struct{ Bundles []types.MevBundle }
+
+// MissedMevBundlesEvent is posted when mev bundles with a block range are dropped from the
+// transaction pool, the head reached the last block of their range without including them.
+type MissedMevBundlesEvent struct{ Bundles []types.MevBundle }
+
 // NewMinedBlockEvent is posted when a block has been imported.
 type NewMinedBlockEvent struct{ Block *types.Block }
 
@@ -0,0 +1,192 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Status of an account in a state diff
+const (
+	AccountAdded    = "added"
+	AccountRemoved  = "removed"
+	AccountModified = "modified"
+)
+
+// DumpDiff is the difference between two state dumps.
+type DumpDiff struct {
+	RootA    string        `json:"rootA"`
+	RootB    string        `json:"rootB"`
+	Accounts []AccountDiff `json:"accounts"` // Sorted by address
+}
+
+// AccountDiff is the difference of an account between two state dumps.
+type AccountDiff struct {
+	Address common.Address `json:"address"`
+	Status  string         `json:"status"`
+	Fields  []FieldDiff    `json:"fields,omitempty"`
+	Storage []StorageDiff  `json:"storage,omitempty"` // Sorted by key
+}
+
+// FieldDiff is a field of an account that differs, empty if the account is missing.
+type FieldDiff struct {
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// StorageDiff is a storage slot that differs, empty if the slot is unset.
+type StorageDiff struct {
+	Key common.Hash `json:"key"`
+	A   string      `json:"a"`
+	B   string      `json:"b"`
+}
+
+// DiffDumps compares the accounts, storage and code of two state dumps. The code is compared by
+// hash, the storage roots only if both dumps have them, since they are not known for the dumps
+// derived with RevertMultiTxSnapshot.
+func DiffDumps(a, b *Dump) *DumpDiff {
+	diff := &DumpDiff{RootA: a.Root, RootB: b.Root, Accounts: []AccountDiff{}}
+
+	addresses := make([]common.Address, 0, len(a.Accounts))
+	for address := range a.Accounts {
+		addresses = append(addresses, address)
+	}
+	for address := range b.Accounts {
+		if _, ok := a.Accounts[address]; !ok {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool { return bytes.Compare(addresses[i][:], addresses[j][:]) < 0 })
+
+	for _, address := range addresses {
+		accountA, inA := a.Accounts[address]
+		accountB, inB := b.Accounts[address]
+		var account AccountDiff
+		switch {
+		case !inA:
+			account = diffAccounts(DumpAccount{}, accountB)
+			account.Status = AccountAdded
+		case !inB:
+			account = diffAccounts(accountA, DumpAccount{})
+			account.Status = AccountRemoved
+		default:
+			account = diffAccounts(accountA, accountB)
+			if len(account.Fields) == 0 && len(account.Storage) == 0 {
+				continue
+			}
+			account.Status = AccountModified
+		}
+		account.Address = address
+		diff.Accounts = append(diff.Accounts, account)
+	}
+	return diff
+}
+
+func diffAccounts(a, b DumpAccount) AccountDiff {
+	var diff AccountDiff
+	field := func(name, valueA, valueB string) {
+		if valueA != valueB {
+			diff.Fields = append(diff.Fields, FieldDiff{Field: name, A: valueA, B: valueB})
+		}
+	}
+	var nonceA, nonceB string
+	if a.Balance != "" {
+		nonceA = fmt.Sprint(a.Nonce)
+	}
+	if b.Balance != "" {
+		nonceB = fmt.Sprint(b.Nonce)
+	}
+	field("balance", a.Balance, b.Balance)
+	field("nonce", nonceA, nonceB)
+	field("codeHash", dumpBytes(a.CodeHash), dumpBytes(b.CodeHash))
+	if len(a.Root) != 0 && len(b.Root) != 0 {
+		field("root", dumpBytes(a.Root), dumpBytes(b.Root))
+	}
+
+	keys := make([]common.Hash, 0, len(a.Storage))
+	for key := range a.Storage {
+		keys = append(keys, key)
+	}
+	for key := range b.Storage {
+		if _, ok := a.Storage[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+	for _, key := range keys {
+		if a.Storage[key] != b.Storage[key] {
+			diff.Storage = append(diff.Storage, StorageDiff{Key: key, A: a.Storage[key], B: b.Storage[key]})
+		}
+	}
+	return diff
+}
+
+func dumpBytes(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return common.Bytes2Hex(b)
+}
+
+// ReadDump reads a state dump written by geth dump, in the collected format or in the
+// iterative (line-by-line) format. Accounts without address are rejected, the iterative dumps
+// must be made with the preimages of the addresses.
+func ReadDump(r io.Reader) (*Dump, error) {
+	var (
+		dec  = json.NewDecoder(r)
+		dump = &Dump{Accounts: make(map[common.Address]DumpAccount)}
+	)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid state dump: %w", err)
+		}
+		var entry map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil, fmt.Errorf("invalid state dump: %w", err)
+		}
+		switch {
+		case entry["accounts"] != nil:
+			// collected dump, a single object holding the whole state
+			var collected Dump
+			if err := json.Unmarshal(raw, &collected); err != nil {
+				return nil, fmt.Errorf("invalid state dump: %w", err)
+			}
+			dump.Root = strings.TrimPrefix(collected.Root, "0x")
+			for address, account := range collected.Accounts {
+				dump.Accounts[address] = account
+			}
+		case entry["address"] != nil:
+			var account DumpAccount
+			if err := json.Unmarshal(raw, &account); err != nil {
+				return nil, fmt.Errorf("invalid state dump account: %w", err)
+			}
+			if account.Address == nil {
+				return nil, errors.New("invalid state dump account, missing address")
+			}
+			address := *account.Address
+			account.Address = nil
+			dump.Accounts[address] = account
+		case entry["key"] != nil:
+			return nil, errors.New("state dump account without address, dump the state with the address preimages")
+		case entry["root"] != nil && len(entry) == 1:
+			var root string
+			if err := json.Unmarshal(entry["root"], &root); err != nil {
+				return nil, fmt.Errorf("invalid state dump root: %w", err)
+			}
+			dump.Root = strings.TrimPrefix(root, "0x")
+		default:
+			return nil, errors.New("invalid state dump entry")
+		}
+	}
+	return dump, nil
+}
@@ -0,0 +1,167 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+func TestDiffDumps(t *testing.T) {
+	var (
+		addr1 = common.HexToAddress("0x01")
+		addr2 = common.HexToAddress("0x02")
+		addr3 = common.HexToAddress("0x03")
+		key1  = common.HexToHash("0x01")
+		key2  = common.HexToHash("0x02")
+	)
+	a := &Dump{Root: "aa", Accounts: map[common.Address]DumpAccount{
+		addr1: {Balance: "1", Nonce: 1, Root: []byte{1}, CodeHash: []byte{1}, Storage: map[common.Hash]string{key1: "01", key2: "02"}},
+		addr2: {Balance: "2", Nonce: 2, CodeHash: []byte{2}},
+	}}
+	b := &Dump{Root: "bb", Accounts: map[common.Address]DumpAccount{
+		addr1: {Balance: "1", Nonce: 2, CodeHash: []byte{1}, Storage: map[common.Hash]string{key1: "03"}},
+		addr3: {Balance: "3", CodeHash: []byte{3}},
+	}}
+
+	diff := DiffDumps(a, b)
+	if diff.RootA != "aa" || diff.RootB != "bb" {
+		t.Fatalf("unexpected roots %s, %s", diff.RootA, diff.RootB)
+	}
+	want := []AccountDiff{
+		{
+			Address: addr1,
+			Status:  AccountModified,
+			Fields:  []FieldDiff{{Field: "nonce", A: "1", B: "2"}},
+			Storage: []StorageDiff{{Key: key1, A: "01", B: "03"}, {Key: key2, A: "02", B: ""}},
+		},
+		{
+			Address: addr2,
+			Status:  AccountRemoved,
+			Fields: []FieldDiff{
+				{Field: "balance", A: "2", B: ""},
+				{Field: "nonce", A: "2", B: ""},
+				{Field: "codeHash", A: "02", B: ""},
+			},
+		},
+		{
+			Address: addr3,
+			Status:  AccountAdded,
+			Fields: []FieldDiff{
+				{Field: "balance", A: "", B: "3"},
+				{Field: "nonce", A: "", B: "0"},
+				{Field: "codeHash", A: "", B: "03"},
+			},
+		},
+	}
+	got, _ := json.Marshal(diff.Accounts)
+	expected, _ := json.Marshal(want)
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("unexpected diff\nhave %s\nwant %s", got, expected)
+	}
+
+	if diff := DiffDumps(a, a); len(diff.Accounts) != 0 {
+		t.Fatalf("expected no difference with itself, got %d accounts", len(diff.Accounts))
+	}
+}
+
+func TestReadDump(t *testing.T) {
+	sdb := NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Preimages: true})
+	state, _ := New(common.Hash{}, sdb, nil)
+	state.SetBalance(common.HexToAddress("0x01"), big.NewInt(22))
+	state.SetCode(common.HexToAddress("0x02"), []byte{3, 3, 3})
+	state.SetState(common.HexToAddress("0x02"), common.HexToHash("0x01"), common.HexToHash("0x0102"))
+	root, _ := state.Commit(false)
+	state, _ = New(root, sdb, nil)
+	want := state.RawDump(nil)
+
+	collected, err := ReadDump(bytes.NewReader(state.Dump(nil)))
+	if err != nil {
+		t.Fatalf("failed to read collected dump: %v", err)
+	}
+	var iterative bytes.Buffer
+	state.IterativeDump(nil, json.NewEncoder(&iterative))
+	lines, err := ReadDump(&iterative)
+	if err != nil {
+		t.Fatalf("failed to read iterative dump: %v", err)
+	}
+	for name, dump := range map[string]*Dump{"collected": collected, "iterative": lines} {
+		if dump.Root != want.Root {
+			t.Errorf("%s: root mismatch %s != %s", name, dump.Root, want.Root)
+		}
+		if len(dump.Accounts) != len(want.Accounts) {
+			t.Errorf("%s: expected %d accounts, got %d", name, len(want.Accounts), len(dump.Accounts))
+		}
+		if diff := DiffDumps(dump, &want); len(diff.Accounts) != 0 {
+			t.Errorf("%s: unexpected difference %+v", name, diff.Accounts)
+		}
+	}
+
+	if _, err := ReadDump(strings.NewReader(`{"key": "0x01", "balance": "1"}`)); err == nil {
+		t.Error("expected account without address to be rejected")
+	}
+}
+
+func TestMultiTxSnapshotDump(t *testing.T) {
+	var (
+		sdb   = NewDatabaseWithConfig(rawdb.NewMemoryDatabase(), &trie.Config{Preimages: true})
+		addr1 = common.HexToAddress("0x01")
+		addr2 = common.HexToAddress("0x02")
+		addr3 = common.HexToAddress("0x03")
+		key1  = common.HexToHash("0x01")
+		key2  = common.HexToHash("0x02")
+		key3  = common.HexToHash("0x03")
+	)
+	state, _ := New(common.Hash{}, sdb, nil)
+	state.SetBalance(addr1, big.NewInt(100))
+	state.SetNonce(addr1, 1)
+	state.SetState(addr1, key1, common.HexToHash("0x0a"))
+	state.SetState(addr1, key2, common.HexToHash("0x0b"))
+	state.SetBalance(addr2, big.NewInt(5))
+	state.SetCode(addr2, []byte{1, 2, 3})
+	root, _ := state.Commit(true)
+	state, _ = New(root, sdb, nil)
+	before := state.RawDump(nil)
+
+	if _, err := state.MultiTxSnapshotDump(); err != errNoMultiTxSnapshot {
+		t.Fatalf("expected %v, got %v", errNoMultiTxSnapshot, err)
+	}
+	if err := state.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	state.SetBalance(addr1, big.NewInt(50))
+	state.SetNonce(addr1, 2)
+	state.SetState(addr1, key1, common.HexToHash("0x0c"))
+	state.SetState(addr1, key2, common.Hash{})
+	state.SetState(addr1, key3, common.HexToHash("0x0d"))
+	state.SetCode(addr2, []byte{4})
+	state.SetBalance(addr3, big.NewInt(7))
+	state.Finalise(true)
+
+	snapshot, err := state.MultiTxSnapshotDump()
+	if err != nil {
+		t.Fatalf("failed to dump snapshot: %v", err)
+	}
+	if restored := snapshot.Accounts[addr1]; restored.Balance != "100" || restored.Nonce != 1 ||
+		restored.Storage[key1] != "0a" || restored.Storage[key2] != "0b" || restored.Storage[key3] != "" {
+		t.Fatalf("unexpected restored account %+v", restored)
+	}
+	if restored := snapshot.Accounts[addr3]; restored.Exists {
+		t.Fatalf("expected created account to be removed, got %+v", restored)
+	}
+
+	root, _ = state.Copy().Commit(true)
+	after, _ := New(root, sdb, nil)
+	afterDump := after.RawDump(nil)
+	if diff := DiffDumps(&before, &afterDump); len(diff.Accounts) != 3 {
+		t.Fatalf("expected 3 changed accounts, got %+v", diff.Accounts)
+	}
+	if diff := DiffDumps(&before, afterDump.RevertMultiTxSnapshot(snapshot)); len(diff.Accounts) != 0 {
+		t.Fatalf("reverted state differs from the state before the snapshot: %+v", diff.Accounts)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/holiman/uint256"
 )
 
 // journalEntry is a modification entry in the state change journal that can be
@@ -96,13 +97,13 @@ type (
 	suicideChange struct {
 		account     *common.Address
 		prev        bool // whether account had already suicided
-		prevbalance *big.Int
+		prevbalance *uint256.Int
 	}
 
 	// Changes to individual accounts.
 	balanceChange struct {
 		account *common.Address
-		prev    *big.Int
+		prev    *uint256.Int
 	}
 	nonceChange struct {
 		account *common.Address
@@ -169,7 +170,7 @@ func (ch suicideChange) revert(s *StateDB) {
 	obj := s.getStateObject(*ch.account)
 	if obj != nil {
 		obj.suicided = ch.prev
-		obj.setBalance(ch.prevbalance)
+		obj.setBalance(revertedBalance(ch.prevbalance))
 	}
 }
 
@@ -186,8 +187,24 @@ func (ch touchChange) dirtied() *common.Address {
 	return ch.account
 }
 
+// journalBalance returns a copy of a balance for the journal. Balances fit in 256 bits, which
+// spares the journal and the multi-transaction snapshots the allocations of a big integer.
+func journalBalance(balance *big.Int) *uint256.Int {
+	prev, _ := uint256.FromBig(balance)
+	return prev
+}
+
+// revertedBalance returns a journaled balance as a big integer. A zero balance is restored as a
+// fresh big integer, the same value a new account starts with.
+func revertedBalance(balance *uint256.Int) *big.Int {
+	if balance.IsZero() {
+		return new(big.Int)
+	}
+	return balance.ToBig()
+}
+
 func (ch balanceChange) revert(s *StateDB) {
-	s.getStateObject(*ch.account).setBalance(ch.prev)
+	s.getStateObject(*ch.account).setBalance(revertedBalance(ch.prev))
 }
 
 func (ch balanceChange) dirtied() *common.Address {
@@ -29,5 +29,13 @@ var (
 	storageTriesDeletedMeter = metrics.NewRegisteredMeter("state/delete/storagenodes", nil)
 
 	stateCopyMeter     = metrics.NewRegisteredMeter("state/copy", nil)
+	stateForkMeter     = metrics.NewRegisteredMeter("state/fork", nil)
 	stateSnapshotMeter = metrics.NewRegisteredMeter("state/snapshot", nil)
+
+	multiTxSnapshotMemoryGauge   = metrics.NewRegisteredGauge("state/multitxsnapshot/memory", nil)
+	multiTxSnapshotSpilledGauge  = metrics.NewRegisteredGauge("state/multitxsnapshot/spilled", nil)
+	multiTxSnapshotSpillMeter    = metrics.NewRegisteredMeter("state/multitxsnapshot/spill", nil)
+	multiTxSnapshotLoadMeter     = metrics.NewRegisteredMeter("state/multitxsnapshot/load", nil)
+	multiTxSnapshotMismatchMeter = metrics.NewRegisteredMeter("state/multitxsnapshot/mismatch", nil)
+	multiTxSnapshotDepthHist     = metrics.NewRegisteredHistogram("state/multitxsnapshot/depth", nil, metrics.NewExpDecaySample(1028, 0.015))
 )
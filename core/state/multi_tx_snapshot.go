@@ -3,10 +3,12 @@ package state
 import (
 	"errors"
 	"fmt"
-	"math/big"
 	"reflect"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/holiman/uint256"
 )
 
 // MultiTxSnapshot retains StateDB changes for multiple transactions.
@@ -18,7 +20,7 @@ type MultiTxSnapshot struct {
 	prevObjects map[common.Address]*stateObject
 
 	accountStorage  map[common.Address]map[common.Hash]*common.Hash
-	accountBalance  map[common.Address]*big.Int
+	accountBalance  map[common.Address]*uint256.Int
 	accountNonce    map[common.Address]uint64
 	accountCode     map[common.Address][]byte
 	accountCodeHash map[common.Address][]byte
@@ -33,7 +35,48 @@ type MultiTxSnapshot struct {
 	// we clear dirty storage for touched accounts when snapshot is reverted
 	touchedAccounts map[common.Address]struct{}
 
-	// TODO: snapdestructs, snapaccount storage
+	// accountDestruct holds whether the accounts destructed since the snapshot were marked as
+	// destructed in the block before
+	accountDestruct map[common.Address]bool
+	// snapAccounts and snapStorage hold the snapshot layer data of the accounts destructed since
+	// the snapshot, nil if there was none
+	snapAccounts map[common.Hash][]byte
+	snapStorage  map[common.Hash]map[common.Hash][]byte
+
+	// transientStorage is the transient storage (EIP-1153) before its first change since the
+	// snapshot, nil if unchanged
+	transientStorage transientStorage
+
+	// journal is the journal the snapshot was last updated from, and journalIndex the index of
+	// its first entry not applied to the snapshot yet
+	journal      *journal
+	journalIndex int
+
+	// memory is the estimated memory held by the snapshot while it is below the head of the stack,
+	// and spilled whether its changes were moved to the spill store of the stack
+	memory  uint64
+	spilled bool
+
+	// root is the intermediate root of the state when the snapshot was created, only captured
+	// when the stack verifies its reverts
+	root common.Hash
+
+	// refund is the refund counter of the state when the snapshot was created
+	refund uint64
+
+	// reads is the state read since the snapshot, only recorded if the state reads are, see RecordReads
+	reads *stateReads
+
+	// prefetches are the trie items scheduled for prefetching since the snapshot, shipped to the
+	// prefetcher when the snapshot is committed
+	prefetches []prefetchTask
+}
+
+// prefetchTask is a batch of trie items to prefetch.
+type prefetchTask struct {
+	owner common.Hash
+	root  common.Hash
+	keys  [][]byte
 }
 
 // NewMultiTxSnapshot creates a new MultiTxSnapshot
@@ -47,7 +90,7 @@ func newMultiTxSnapshot() MultiTxSnapshot {
 		numLogsAdded:      make(map[common.Hash]int),
 		prevObjects:       make(map[common.Address]*stateObject),
 		accountStorage:    make(map[common.Address]map[common.Hash]*common.Hash),
-		accountBalance:    make(map[common.Address]*big.Int),
+		accountBalance:    make(map[common.Address]*uint256.Int),
 		accountNonce:      make(map[common.Address]uint64),
 		accountCode:       make(map[common.Address][]byte),
 		accountCodeHash:   make(map[common.Address][]byte),
@@ -56,12 +99,21 @@ func newMultiTxSnapshot() MultiTxSnapshot {
 		accountNotPending: make(map[common.Address]struct{}),
 		accountNotDirty:   make(map[common.Address]struct{}),
 		touchedAccounts:   make(map[common.Address]struct{}),
+		accountDestruct:   make(map[common.Address]bool),
+		snapAccounts:      make(map[common.Hash][]byte),
+		snapStorage:       make(map[common.Hash]map[common.Hash][]byte),
 	}
 }
 
 func (s MultiTxSnapshot) Copy() MultiTxSnapshot {
 	newSnapshot := newMultiTxSnapshot()
 	newSnapshot.invalid = s.invalid
+	newSnapshot.journal = s.journal
+	newSnapshot.journalIndex = s.journalIndex
+	newSnapshot.root = s.root
+	newSnapshot.refund = s.refund
+	newSnapshot.reads = s.reads.copy()
+	newSnapshot.prefetches = append([]prefetchTask(nil), s.prefetches...)
 
 	for txHash, numLogs := range s.numLogsAdded {
 		newSnapshot.numLogsAdded[txHash] = numLogs
@@ -114,6 +166,22 @@ func (s MultiTxSnapshot) Copy() MultiTxSnapshot {
 		newSnapshot.touchedAccounts[address] = struct{}{}
 	}
 
+	for address, destructed := range s.accountDestruct {
+		newSnapshot.accountDestruct[address] = destructed
+	}
+
+	for addrHash, data := range s.snapAccounts {
+		newSnapshot.snapAccounts[addrHash] = data
+	}
+
+	for addrHash, storage := range s.snapStorage {
+		newSnapshot.snapStorage[addrHash] = storage
+	}
+
+	if s.transientStorage != nil {
+		newSnapshot.transientStorage = s.transientStorage.Copy()
+	}
+
 	return newSnapshot
 }
 
@@ -172,12 +240,23 @@ func (s *MultiTxSnapshot) Equal(other *MultiTxSnapshot) bool {
 		reflect.DeepEqual(s.accountDeleted, other.accountDeleted) &&
 		reflect.DeepEqual(s.accountNotPending, other.accountNotPending) &&
 		reflect.DeepEqual(s.accountNotDirty, other.accountNotDirty) &&
-		reflect.DeepEqual(s.touchedAccounts, other.touchedAccounts)
+		reflect.DeepEqual(s.touchedAccounts, other.touchedAccounts) &&
+		reflect.DeepEqual(s.accountDestruct, other.accountDestruct) &&
+		reflect.DeepEqual(s.snapAccounts, other.snapAccounts) &&
+		reflect.DeepEqual(s.snapStorage, other.snapStorage) &&
+		reflect.DeepEqual(s.transientStorage, other.transientStorage)
 }
 
-// updateFromJournal updates the snapshot with the changes from the journal.
+// updateFromJournal updates the snapshot with the changes from the journal. Only the entries
+// appended since the last update from the same journal are applied.
 func (s *MultiTxSnapshot) updateFromJournal(journal *journal) {
-	for _, journalEntry := range journal.entries {
+	if s.journal != journal {
+		s.journal, s.journalIndex = journal, 0
+	}
+	if s.journalIndex > len(journal.entries) {
+		s.journalIndex = len(journal.entries)
+	}
+	for _, journalEntry := range journal.entries[s.journalIndex:] {
 		switch entry := journalEntry.(type) {
 		case balanceChange:
 			s.updateBalanceChange(entry)
@@ -195,6 +274,15 @@ func (s *MultiTxSnapshot) updateFromJournal(journal *journal) {
 			s.updateSuicideChange(entry)
 		}
 	}
+	s.journalIndex = len(journal.entries)
+}
+
+// journalReverted moves the journal cursor back when the entries of the journal from the given
+// index are reverted, for the entries appended in their place to be applied.
+func (s *MultiTxSnapshot) journalReverted(journal *journal, index int) {
+	if s.journal == journal && s.journalIndex > index {
+		s.journalIndex = index
+	}
 }
 
 // objectChanged returns whether the object was changed (in the set of prevObjects), which can happen
@@ -238,12 +326,17 @@ func (s *MultiTxSnapshot) updateCodeChange(change codeChange) {
 	}
 }
 
-// updateResetObjectChange updates the snapshot with the reset object change.
+// updateResetObjectChange updates the snapshot with the reset object change. The previous object
+// is copied, it is restored by a journal revert and can be modified after the capture.
 func (s *MultiTxSnapshot) updateResetObjectChange(change resetObjectChange) {
 	s.touchedAccounts[change.prev.address] = struct{}{}
 	address := change.prev.address
 	if _, ok := s.prevObjects[address]; !ok {
-		s.prevObjects[address] = change.prev
+		s.prevObjects[address] = change.prev.deepCopy(change.prev.db)
+	}
+	// the replaced account is marked as destructed
+	if _, ok := s.accountDestruct[address]; !ok {
+		s.accountDestruct[address] = change.prevdestruct
 	}
 }
 
@@ -310,6 +403,32 @@ func (s *MultiTxSnapshot) updateObjectDeleted(address common.Address, deleted bo
 	}
 }
 
+// updateDestruct updates the snapshot with the destruct markers and the snapshot layer data of an
+// account about to be destructed.
+func (s *MultiTxSnapshot) updateDestruct(st *StateDB, address common.Address, addrHash common.Hash) {
+	if _, ok := s.accountDestruct[address]; !ok {
+		_, destructed := st.stateObjectsDestruct[address]
+		s.accountDestruct[address] = destructed
+	}
+	if st.snap == nil {
+		return
+	}
+	// the destruct drops the maps from the state, they are not modified afterwards
+	if _, ok := s.snapAccounts[addrHash]; !ok {
+		s.snapAccounts[addrHash] = st.snapAccounts[addrHash]
+	}
+	if _, ok := s.snapStorage[addrHash]; !ok {
+		s.snapStorage[addrHash] = st.snapStorage[addrHash]
+	}
+}
+
+// updateTransientStorage updates the snapshot with the transient storage about to be changed.
+func (s *MultiTxSnapshot) updateTransientStorage(storage transientStorage) {
+	if s.transientStorage == nil {
+		s.transientStorage = storage.Copy()
+	}
+}
+
 // Merge merges the changes from another snapshot into the current snapshot.
 // The operation assumes that the other snapshot is later (newer) than the current snapshot.
 // Changes are merged such that older state is retained and not overwritten.
@@ -320,21 +439,16 @@ func (s *MultiTxSnapshot) Merge(other *MultiTxSnapshot) error {
 		return errors.New("failed to merge snapshots - invalid snapshot found")
 	}
 
+	// the state read since the other snapshot is kept, unlike its changes it is not reverted
+	s.reads.merge(other.reads)
+	s.prefetches = append(s.prefetches, other.prefetches...)
+
 	// each snapshot increments the number of logs per transaction hash
 	// when we merge snapshots, the number of logs added per transaction are appended to current snapshot
 	for txHash, numLogs := range other.numLogsAdded {
 		s.numLogsAdded[txHash] += numLogs
 	}
 
-	// prevObjects contain mapping of address to state objects
-	// if the current snapshot has previous object for same address, retain previous object
-	// otherwise, add new object from other snapshot
-	for address, object := range other.prevObjects {
-		if _, exist := s.prevObjects[address]; !exist {
-			s.prevObjects[address] = object
-		}
-	}
-
 	// merge account storage -
 	//   we want to retain any existing storage values for a given account,
 	//   update storage keys if they do not exist for a given account's storage,
@@ -418,6 +532,16 @@ func (s *MultiTxSnapshot) Merge(other *MultiTxSnapshot) error {
 		}
 	}
 
+	// prevObjects contain mapping of address to state objects
+	// if the current snapshot has previous object for same address, retain previous object
+	// otherwise, add new object from other snapshot. This comes after the account changes above,
+	// which the other snapshot may have captured before replacing the object.
+	for address, object := range other.prevObjects {
+		if _, exist := s.prevObjects[address]; !exist {
+			s.prevObjects[address] = object
+		}
+	}
+
 	// add previous pending status if not found
 	for address := range other.accountNotPending {
 		if _, exist := s.accountNotPending[address]; !exist {
@@ -435,6 +559,33 @@ func (s *MultiTxSnapshot) Merge(other *MultiTxSnapshot) error {
 		s.touchedAccounts[address] = struct{}{}
 	}
 
+	// add previous destruct markers and snapshot layer data if not found
+	for address, destructed := range other.accountDestruct {
+		if _, exist := s.accountDestruct[address]; !exist {
+			s.accountDestruct[address] = destructed
+		}
+	}
+	for addrHash, data := range other.snapAccounts {
+		if _, exist := s.snapAccounts[addrHash]; !exist {
+			s.snapAccounts[addrHash] = data
+		}
+	}
+	for addrHash, storage := range other.snapStorage {
+		if _, exist := s.snapStorage[addrHash]; !exist {
+			s.snapStorage[addrHash] = storage
+		}
+	}
+
+	// retain the transient storage of the current snapshot if changed
+	if s.transientStorage == nil {
+		s.transientStorage = other.transientStorage
+	}
+
+	// the journal entries applied to the other snapshot are applied to the merged snapshot
+	if other.journal != nil {
+		s.journal, s.journalIndex = other.journal, other.journalIndex
+	}
+
 	return nil
 }
 
@@ -451,12 +602,15 @@ func (s *MultiTxSnapshot) revertState(st *StateDB) {
 		st.logSize -= uint(numLogs)
 	}
 
-	// restore the objects
+	// restore the refund counter, which is only non-zero within a transaction
+	st.refund = s.refund
+
+	// restore the objects, copied to keep the captured objects unmodified
 	for address, object := range s.prevObjects {
 		if object == nil {
 			delete(st.stateObjects, address)
 		} else {
-			st.stateObjects[address] = object
+			st.stateObjects[address] = object.deepCopy(st)
 		}
 	}
 
@@ -480,7 +634,7 @@ func (s *MultiTxSnapshot) revertState(st *StateDB) {
 
 	// restore balance
 	for address, balance := range s.accountBalance {
-		st.stateObjects[address].setBalance(balance)
+		st.stateObjects[address].setBalance(revertedBalance(balance))
 	}
 	// restore nonce
 	for address, nonce := range s.accountNonce {
@@ -499,6 +653,36 @@ func (s *MultiTxSnapshot) revertState(st *StateDB) {
 		st.stateObjects[address].deleted = deleted
 	}
 
+	// restore destruct markers and snapshot layer data
+	for address, destructed := range s.accountDestruct {
+		if destructed {
+			st.stateObjectsDestruct[address] = struct{}{}
+		} else {
+			delete(st.stateObjectsDestruct, address)
+		}
+	}
+	if st.snap != nil {
+		for addrHash, data := range s.snapAccounts {
+			if data == nil {
+				delete(st.snapAccounts, addrHash)
+			} else {
+				st.snapAccounts[addrHash] = data
+			}
+		}
+		for addrHash, storage := range s.snapStorage {
+			if storage == nil {
+				delete(st.snapStorage, addrHash)
+			} else {
+				st.snapStorage[addrHash] = storage
+			}
+		}
+	}
+
+	// restore transient storage
+	if s.transientStorage != nil {
+		st.transientStorage = s.transientStorage.Copy()
+	}
+
 	// restore pending status
 	for address := range s.accountNotPending {
 		delete(st.stateObjectsPending, address)
@@ -523,9 +707,18 @@ func (s *MultiTxSnapshot) revertState(st *StateDB) {
 //   - If applied changes are not desired, revert the changes from the head snapshot and pop the snapshot from the stack
 //   - If applied changes are desired, commit the changes from the head snapshot by merging with previous entry
 //     and pop the snapshot from the stack
+//
+// The memory held by the snapshots below the head can be capped with SetMemoryLimit.
 type MultiTxSnapshotStack struct {
 	snapshots []MultiTxSnapshot
 	state     *StateDB
+
+	id          uint64 // Prefix of the keys of the spilled snapshots
+	memoryLimit uint64 // 0 = unlimited
+	memory      uint64 // Estimated memory held by the snapshots below the head, not spilled
+	spill       ethdb.KeyValueStore
+
+	verify bool // Compare the state root after each revert with the one of the reverted snapshot
 }
 
 // NewMultiTxSnapshotStack creates a new MultiTxSnapshotStack with a given StateDB.
@@ -533,24 +726,74 @@ func NewMultiTxSnapshotStack(state *StateDB) *MultiTxSnapshotStack {
 	return &MultiTxSnapshotStack{
 		snapshots: make([]MultiTxSnapshot, 0),
 		state:     state,
+		id:        multiTxSnapshotStackID.Add(1),
 	}
 }
 
-// NewSnapshot creates a new snapshot and pushes it on top of the stack.
+// NewSnapshot creates a new snapshot and pushes it on top of the stack. The snapshot is updated
+// from the journal entries appended after its creation.
 func (stack *MultiTxSnapshotStack) NewSnapshot() (*MultiTxSnapshot, error) {
 	if len(stack.snapshots) > 0 && stack.snapshots[len(stack.snapshots)-1].invalid {
 		return nil, errors.New("failed to create new multi-transaction snapshot - invalid snapshot found at head")
 	}
 
+	// the journal entries before the new snapshot belong to the head snapshot
+	journal := stack.state.journal
+	if head := stack.Peek(); head != nil {
+		head.updateFromJournal(journal)
+	}
+	if err := stack.coolHead(); err != nil {
+		return nil, err
+	}
 	snap := newMultiTxSnapshot()
+	snap.journal, snap.journalIndex = journal, journal.length()
+	snap.refund = stack.state.refund
+	if stack.state.witness != nil {
+		snap.reads = newStateReads()
+	}
+	if stack.verify {
+		snap.root = stack.state.verificationRoot()
+	}
 	stack.snapshots = append(stack.snapshots, snap)
+	multiTxSnapshotDepthHist.Update(int64(len(stack.snapshots)))
+	stack.spillOldest()
 	return &snap, nil
 }
 
 func (stack *MultiTxSnapshotStack) Copy(statedb *StateDB) *MultiTxSnapshotStack {
 	newStack := NewMultiTxSnapshotStack(statedb)
-	for _, snapshot := range stack.snapshots {
-		newStack.snapshots = append(newStack.snapshots, snapshot.Copy())
+	newStack.SetMemoryLimit(stack.memoryLimit, stack.spill)
+	newStack.verify = stack.verify
+	invalid := false
+	for i, snapshot := range stack.snapshots {
+		if snapshot.spilled {
+			snapshotCopy, err := stack.readSpilled(i, statedb)
+			if err != nil {
+				log.Error("Failed to copy spilled multi-transaction snapshot", "err", err)
+				snapshotCopy, invalid = MultiTxSnapshot{invalid: true}, true
+			}
+			snapshotCopy.reads = snapshotCopy.reads.copy()
+			snapshotCopy.prefetches = append([]prefetchTask(nil), snapshotCopy.prefetches...)
+			newStack.snapshots = append(newStack.snapshots, snapshotCopy)
+			continue
+		}
+		snapshotCopy := snapshot.Copy()
+		// the captured objects are not shared between the state copies
+		for address, object := range snapshotCopy.prevObjects {
+			if object != nil {
+				snapshotCopy.prevObjects[address] = object.deepCopy(statedb)
+			}
+		}
+		newStack.snapshots = append(newStack.snapshots, snapshotCopy)
+	}
+	if invalid {
+		newStack.Invalidate()
+	}
+	if newStack.memoryLimit > 0 {
+		for i := 0; i < len(newStack.snapshots)-1; i++ {
+			newStack.track(&newStack.snapshots[i], newStack.snapshots[i].memoryUsage())
+		}
+		newStack.spillOldest()
 	}
 	return newStack
 }
@@ -570,9 +813,7 @@ func (stack *MultiTxSnapshotStack) Pop() (*MultiTxSnapshot, error) {
 		return nil, errors.New("failed to revert multi-transaction snapshot - does not exist")
 	}
 
-	head := &stack.snapshots[size-1]
-	stack.snapshots = stack.snapshots[:size-1]
-	return head, nil
+	return stack.removeHead(), nil
 }
 
 // Revert rewinds the changes from the head snapshot and removes it from the stack.
@@ -587,11 +828,36 @@ func (stack *MultiTxSnapshotStack) Revert() (*MultiTxSnapshot, error) {
 		return nil, errors.New("failed to revert multi-transaction snapshot - invalid snapshot found")
 	}
 
+	// only the snapshots created while verifying have a root
+	var diff *StateDiff
+	verify := stack.verify && head.root != (common.Hash{})
+	if verify {
+		diff = head.Diff()
+	}
 	head.revertState(stack.state)
-	stack.snapshots = stack.snapshots[:size-1]
+	head = stack.removeHead()
+	if verify {
+		if err := stack.verifyRevert(head, diff); err != nil {
+			return nil, err
+		}
+	}
 	return head, nil
 }
 
+// removeHead removes the snapshot at the top of the stack and returns it. The snapshot below is
+// loaded back if it was spilled.
+func (stack *MultiTxSnapshotStack) removeHead() *MultiTxSnapshot {
+	size := len(stack.snapshots)
+	head := &stack.snapshots[size-1]
+	if head.spilled {
+		// only left on top of the stack when it failed to load
+		stack.deleteSpilled(size - 1)
+	}
+	stack.snapshots = stack.snapshots[:size-1]
+	stack.warmHead()
+	return head
+}
+
 // RevertAll reverts all snapshots in the stack.
 func (stack *MultiTxSnapshotStack) RevertAll() (snapshot *MultiTxSnapshot, err error) {
 	for len(stack.snapshots) > 0 {
@@ -607,9 +873,17 @@ func (stack *MultiTxSnapshotStack) Commit() (*MultiTxSnapshot, error) {
 	if len(stack.snapshots) == 0 {
 		return nil, errors.New("failed to commit multi-transaction snapshot - does not exist")
 	}
+	if stack.Peek().invalid {
+		return nil, errors.New("failed to commit multi-transaction snapshot - invalid snapshot found")
+	}
 
 	if len(stack.snapshots) == 1 {
-		return stack.Pop()
+		head, err := stack.Pop()
+		if err == nil {
+			stack.state.witness.merge(head.reads)
+			stack.shipPrefetches(head)
+		}
+		return head, err
 	}
 
 	var (
@@ -619,6 +893,7 @@ func (stack *MultiTxSnapshotStack) Commit() (*MultiTxSnapshot, error) {
 	if head, err = stack.Pop(); err != nil {
 		return nil, err
 	}
+	stack.shipPrefetches(head)
 
 	current := stack.Peek()
 	if err = current.Merge(head); err != nil {
@@ -629,22 +904,70 @@ func (stack *MultiTxSnapshotStack) Commit() (*MultiTxSnapshot, error) {
 	return head, nil
 }
 
+// SquashToDepth merges the snapshots above the given depth into one, leaving depth+1 snapshots on
+// the stack. The merged snapshot reverts the changes of all the snapshots it replaces, so a
+// builder can push a checkpoint after each committed bundle and squash them, without reverts
+// walking a deep stack later. The stack is invalidated if the snapshots fail to merge.
+func (stack *MultiTxSnapshotStack) SquashToDepth(depth int) error {
+	size := len(stack.snapshots)
+	if depth < 0 || depth >= size {
+		return fmt.Errorf("failed to squash multi-transaction snapshots - invalid depth %d for %d snapshots", depth, size)
+	}
+	if stack.Peek().invalid {
+		return errors.New("failed to squash multi-transaction snapshots - invalid snapshot found")
+	}
+
+	for i := depth; i < size; i++ {
+		stack.untrack(&stack.snapshots[i])
+		if !stack.snapshots[i].spilled {
+			continue
+		}
+		if err := stack.load(i); err != nil {
+			stack.Invalidate()
+			return fmt.Errorf("failed to squash multi-transaction snapshots - %w", err)
+		}
+	}
+	squashed := &stack.snapshots[depth]
+	for i := depth + 1; i < size; i++ {
+		if err := squashed.Merge(&stack.snapshots[i]); err != nil {
+			stack.Invalidate()
+			return err
+		}
+	}
+	stack.snapshots = stack.snapshots[:depth+1]
+	return nil
+}
+
+// shipPrefetches ships the trie items held by a committed snapshot off to the prefetcher of the
+// state. The items are not held by the snapshot below, which usually spans the whole block, as
+// they would only be prefetched once the block is built.
+func (stack *MultiTxSnapshotStack) shipPrefetches(snapshot *MultiTxSnapshot) {
+	if prefetcher := stack.state.prefetcher; prefetcher != nil {
+		for _, task := range snapshot.prefetches {
+			prefetcher.prefetch(task.owner, task.root, task.keys)
+		}
+	}
+	snapshot.prefetches = nil
+}
+
 // Size returns the number of snapshots in the stack.
 func (stack *MultiTxSnapshotStack) Size() int {
 	return len(stack.snapshots)
 }
 
-// Invalidate invalidates the latest snapshot. This is used when state changes are committed to trie.
+// Invalidate invalidates all the snapshots of the stack. This is used when state changes are
+// committed to trie: none of the snapshots can be reverted or committed anymore, and no snapshot
+// can be pushed on top of them. The snapshots stay on the stack until popped.
 func (stack *MultiTxSnapshotStack) Invalidate() {
-	size := len(stack.snapshots)
-	if size == 0 {
-		return
+	for i := range stack.snapshots {
+		stack.snapshots[i].invalid = true
 	}
+}
 
-	head := stack.snapshots[size-1]
-	head.invalid = true
-	stack.snapshots = stack.snapshots[:0]
-	stack.snapshots = append(stack.snapshots, head)
+// Invalidated returns whether the snapshots of the stack were invalidated.
+func (stack *MultiTxSnapshotStack) Invalidated() bool {
+	head := stack.Peek()
+	return head != nil && head.invalid
 }
 
 // UpdatePendingStatus updates the pending status for an address.
@@ -680,6 +1003,36 @@ func (stack *MultiTxSnapshotStack) UpdateFromJournal(journal *journal) {
 	stack.snapshots[len(stack.snapshots)-1] = *current
 }
 
+// JournalReverted moves the journal cursors of the snapshots back when the entries of the journal
+// from the given index are reverted.
+func (stack *MultiTxSnapshotStack) JournalReverted(journal *journal, index int) {
+	for i := range stack.snapshots {
+		stack.snapshots[i].journalReverted(journal, index)
+	}
+}
+
+// UpdateDestruct updates the snapshot with the destruct markers and the snapshot layer data of an
+// account about to be destructed.
+func (stack *MultiTxSnapshotStack) UpdateDestruct(address common.Address, addrHash common.Hash) {
+	if len(stack.snapshots) == 0 {
+		return
+	}
+
+	current := stack.Peek()
+	current.updateDestruct(stack.state, address, addrHash)
+}
+
+// UpdateTransientStorage updates the snapshot with the transient storage about to be changed or
+// reset.
+func (stack *MultiTxSnapshotStack) UpdateTransientStorage(storage transientStorage) {
+	if len(stack.snapshots) == 0 {
+		return
+	}
+
+	current := stack.Peek()
+	current.updateTransientStorage(storage)
+}
+
 // UpdateObjectDeleted updates the snapshot with the object deletion.
 func (stack *MultiTxSnapshotStack) UpdateObjectDeleted(address common.Address, deleted bool) {
 	if len(stack.snapshots) == 0 {
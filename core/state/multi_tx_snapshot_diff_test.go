@@ -0,0 +1,109 @@
+package state
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// diffTx is a transaction of the differential tests, its reverted actions are rolled back with a
+// native snapshot like the state changes of a failed call frame.
+type diffTx struct {
+	fuzzTx
+	reverted byte // Bit i is set if action i is reverted
+}
+
+// apply applies the transaction with geth's native snapshots, discarded transactions are reverted
+// to the snapshot taken at their start before they are finalised.
+func (tx diffTx) apply(s *StateDB, discard bool) {
+	s.SetTxContext(tx.hash, 0)
+	start := s.Snapshot()
+	for i, action := range tx.actions {
+		frame := s.Snapshot()
+		action.apply(s)
+		if tx.reverted&(1<<i) != 0 {
+			s.RevertToSnapshot(frame)
+		}
+	}
+	if discard {
+		s.RevertToSnapshot(start)
+	}
+	s.Finalise(true)
+}
+
+// diffBundle is a group of transactions included or discarded together.
+type diffBundle struct {
+	txs     []diffTx
+	discard bool
+}
+
+func decodeDiffBundle(in *fuzzInput, index int) diffBundle {
+	header := in.readByte()
+	bundle := diffBundle{discard: header&0x80 != 0}
+	for n := int(header%3) + 1; n > 0; n-- {
+		tx := diffTx{fuzzTx: decodeFuzzTx(in, index)}
+		tx.reverted = in.readByte()
+		bundle.txs = append(bundle.txs, tx)
+		index++
+	}
+	return bundle
+}
+
+// testSnapshotDifferential applies the bundles of the input to two states. The multi-transaction
+// state applies every bundle in a multi-transaction snapshot and reverts the discarded ones after
+// their transactions were finalised, the native state reverts each discarded transaction with geth's
+// native snapshots before finalising it. The states must be identical after every bundle.
+func testSnapshotDifferential(t *testing.T, data []byte) {
+	var (
+		in     = &fuzzInput{data: data}
+		multi  = newFuzzReference(nil)
+		native = newFuzzReference(nil)
+		txs    int
+	)
+	for i := 0; i < maxFuzzMultiTxOps && len(in.data) > 0; i++ {
+		bundle := decodeDiffBundle(in, txs)
+		txs += len(bundle.txs)
+
+		if err := multi.NewMultiTxSnapshot(); err != nil {
+			t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+		}
+		for _, tx := range bundle.txs {
+			tx.apply(multi, false)
+			tx.apply(native, bundle.discard)
+		}
+		if bundle.discard {
+			if err := multi.MultiTxSnapshotRevert(); err != nil {
+				t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+			}
+		} else if err := multi.MultiTxSnapshotCommit(); err != nil {
+			t.Fatalf("MultiTxSnapshotCommit failed: %v", err)
+		}
+		if err := checkFuzzEqual(multi, native, txs); err != nil {
+			t.Fatalf("state mismatch after bundle %d (discarded: %v): %v", i, bundle.discard, err)
+		}
+	}
+	if size := multi.MultiTxSnapshotStackSize(); size != 0 {
+		t.Fatalf("expected empty snapshot stack, got %d", size)
+	}
+	if root, expected := multi.IntermediateRoot(true), native.IntermediateRoot(true); root != expected {
+		t.Fatalf("root mismatch %v != %v", root, expected)
+	}
+}
+
+// TestMultiTxSnapshotDifferential validates the multi-transaction snapshots against geth's native
+// snapshots on random transaction sequences, to catch changes of the StateDB the multi-transaction
+// snapshots do not account for.
+func TestMultiTxSnapshotDifferential(t *testing.T) {
+	for seed := int64(0); seed < 64; seed++ {
+		data := make([]byte, 512)
+		rand.New(rand.NewSource(seed)).Read(data)
+		testSnapshotDifferential(t, data)
+	}
+}
+
+func FuzzMultiTxSnapshotDifferential(f *testing.F) {
+	f.Add([]byte{0x80, 0, 0, 0, 0, 0, 0, 0, 0})
+	f.Add([]byte{2, 1, 6, 1, 0, 0, 0, 1, 0, 5, 2, 0, 0, 0, 0x82, 1, 3, 0, 2, 0, 0, 2, 0})
+	f.Add([]byte{0x81, 3, 4, 0, 0, 0, 0, 7, 0, 0, 0, 0, 5, 2, 0, 0, 0, 6, 2, 0, 0, 0, 0x0a, 0, 0, 0, 0, 0, 0})
+
+	f.Fuzz(testSnapshotDifferential)
+}
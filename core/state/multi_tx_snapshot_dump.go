@@ -0,0 +1,178 @@
+package state
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var errNoMultiTxSnapshot = errors.New("no multi-transaction snapshot")
+
+// MultiTxSnapshotDump is the serialized form of a multi-transaction snapshot: the accounts it
+// restores when reverted, with the values they are restored to.
+type MultiTxSnapshotDump struct {
+	Accounts map[common.Address]SnapshotAccount `json:"accounts"`
+}
+
+// SnapshotAccount is an account as a multi-transaction snapshot restores it.
+type SnapshotAccount struct {
+	Exists   bool          `json:"exists"` // False if the account is removed by the revert
+	Balance  string        `json:"balance,omitempty"`
+	Nonce    uint64        `json:"nonce,omitempty"`
+	CodeHash hexutil.Bytes `json:"codeHash,omitempty"`
+	Code     hexutil.Bytes `json:"code,omitempty"`
+	// Storage holds the slots written since the snapshot with the value they are restored to, in
+	// the format of the state dumps, empty if the slot is cleared. The storage of replaced
+	// accounts is not recorded.
+	Storage map[common.Hash]string `json:"storage,omitempty"`
+}
+
+// MultiTxSnapshotDump serializes the multi-transaction snapshot on top of the stack, to compare
+// the state its revert restores with a dump of the state. The snapshot is only updated by
+// Finalise, the changes of the transactions not yet finalised are not in the dump.
+func (s *StateDB) MultiTxSnapshotDump() (*MultiTxSnapshotDump, error) {
+	snapshot := s.multiTxSnapshotStack.Peek()
+	if snapshot == nil {
+		return nil, errNoMultiTxSnapshot
+	}
+	if snapshot.invalid {
+		return nil, errors.New("multi-transaction snapshot is invalid")
+	}
+	return snapshot.dump(s), nil
+}
+
+// dump returns the accounts the snapshot restores on the state.
+func (s *MultiTxSnapshot) dump(st *StateDB) *MultiTxSnapshotDump {
+	addresses := make(map[common.Address]struct{})
+	for address := range s.prevObjects {
+		addresses[address] = struct{}{}
+	}
+	for address := range s.accountBalance {
+		addresses[address] = struct{}{}
+	}
+	for address := range s.accountNonce {
+		addresses[address] = struct{}{}
+	}
+	for address := range s.accountCode {
+		addresses[address] = struct{}{}
+	}
+	for address := range s.accountStorage {
+		addresses[address] = struct{}{}
+	}
+	for address := range s.accountDeleted {
+		addresses[address] = struct{}{}
+	}
+
+	dump := &MultiTxSnapshotDump{Accounts: make(map[common.Address]SnapshotAccount, len(addresses))}
+	for address := range addresses {
+		if prev, replaced := s.prevObjects[address]; replaced {
+			// accounts created or replaced since the snapshot are restored whole
+			if prev == nil || prev.deleted {
+				dump.Accounts[address] = SnapshotAccount{}
+			} else {
+				dump.Accounts[address] = newSnapshotAccount(st, prev)
+			}
+			continue
+		}
+		obj := st.stateObjects[address]
+		if obj == nil {
+			continue
+		}
+		account := newSnapshotAccount(st, obj)
+		if deleted, ok := s.accountDeleted[address]; ok {
+			account.Exists = !deleted
+		}
+		if balance, ok := s.accountBalance[address]; ok {
+			account.Balance = balance.ToBig().String()
+		}
+		if nonce, ok := s.accountNonce[address]; ok {
+			account.Nonce = nonce
+		}
+		if code, ok := s.accountCode[address]; ok {
+			account.Code = code
+			account.CodeHash = s.accountCodeHash[address]
+		}
+		if storage := s.accountStorage[address]; len(storage) != 0 {
+			// the slots without pending value before the snapshot are restored to their
+			// committed value, read from a copy without the pending writes of the snapshot
+			var committed *stateObject
+			account.Storage = make(map[common.Hash]string, len(storage))
+			for key, value := range storage {
+				if value == nil {
+					if committed == nil {
+						committed = obj.deepCopy(st)
+						for key, value := range storage {
+							if value == nil {
+								delete(committed.pendingStorage, key)
+							}
+						}
+					}
+					account.Storage[key] = dumpStorageValue(committed.GetCommittedState(st.db, key))
+				} else {
+					account.Storage[key] = dumpStorageValue(*value)
+				}
+			}
+		}
+		dump.Accounts[address] = account
+	}
+	return dump
+}
+
+func newSnapshotAccount(st *StateDB, obj *stateObject) SnapshotAccount {
+	return SnapshotAccount{
+		Exists:   !obj.deleted,
+		Balance:  obj.Balance().String(),
+		Nonce:    obj.Nonce(),
+		CodeHash: common.CopyBytes(obj.CodeHash()),
+		Code:     common.CopyBytes(obj.Code(st.db)),
+	}
+}
+
+// dumpStorageValue formats a storage value like the state dumps, empty for the zero value.
+func dumpStorageValue(value common.Hash) string {
+	return common.Bytes2Hex(common.TrimLeftZeroes(value[:]))
+}
+
+// RevertMultiTxSnapshot returns the state the revert of the snapshot restores on top of the
+// dump. The storage roots of the accounts with restored storage, and the root of the state, are
+// unknown and left empty.
+func (d *Dump) RevertMultiTxSnapshot(snapshot *MultiTxSnapshotDump) *Dump {
+	reverted := &Dump{Accounts: make(map[common.Address]DumpAccount, len(d.Accounts))}
+	for address, account := range d.Accounts {
+		reverted.Accounts[address] = account
+	}
+	for address, restored := range snapshot.Accounts {
+		if !restored.Exists {
+			delete(reverted.Accounts, address)
+			continue
+		}
+		prev := reverted.Accounts[address]
+		account := DumpAccount{
+			Balance:   restored.Balance,
+			Nonce:     restored.Nonce,
+			Root:      prev.Root,
+			CodeHash:  restored.CodeHash,
+			Code:      restored.Code,
+			SecureKey: prev.SecureKey,
+		}
+		if len(prev.Storage) != 0 || len(restored.Storage) != 0 {
+			account.Storage = make(map[common.Hash]string, len(prev.Storage))
+			for key, value := range prev.Storage {
+				account.Storage[key] = value
+			}
+		}
+		if len(restored.Storage) != 0 {
+			account.Root = nil
+			for key, value := range restored.Storage {
+				if value == "" {
+					delete(account.Storage, key)
+				} else {
+					account.Storage[key] = value
+				}
+			}
+		}
+		reverted.Accounts[address] = account
+	}
+	return reverted
+}
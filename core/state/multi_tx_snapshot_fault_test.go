@@ -0,0 +1,38 @@
+//go:build faultinject
+
+package state
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/internal/faultinject"
+)
+
+func TestMultiTxSnapshotInvalidationFault(t *testing.T) {
+	defer faultinject.Reset()
+
+	s, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err := s.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+
+	faultinject.Set(faultinject.SnapshotInvalidation, faultinject.Fault{Rate: 1})
+	if err := s.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+	// the invalidation cascades to the whole stack, which can not be reverted
+	if size := s.MultiTxSnapshotStackSize(); size != 2 {
+		t.Errorf("stack size mismatch: have %d, want 2", size)
+	}
+	if !s.MultiTxSnapshotInvalidated() {
+		t.Error("stack not invalidated")
+	}
+	if err := s.MultiTxSnapshotRevert(); err == nil {
+		t.Error("revert of an invalidated snapshot succeeded")
+	}
+	if err := s.NewMultiTxSnapshot(); err == nil {
+		t.Error("snapshot on top of an invalidated snapshot succeeded")
+	}
+}
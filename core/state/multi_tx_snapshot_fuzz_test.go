@@ -0,0 +1,243 @@
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// maxFuzzMultiTxOps bounds the number of operations decoded from a single fuzz input
+const maxFuzzMultiTxOps = 128
+
+// fuzzAddrs are the accounts touched by the fuzzer, the prefilled accounts and a few empty ones.
+var fuzzAddrs = func() []common.Address {
+	all := append([]common.Address{}, addrs...)
+	for i := len(addrs); i < len(addrs)+4; i++ {
+		all = append(all, common.HexToAddress(fmt.Sprintf("0x%02x", i)))
+	}
+	return all
+}()
+
+// fuzzInput decodes the fuzzer data, reading past the end yields zeroes.
+type fuzzInput struct {
+	data []byte
+}
+
+func (in *fuzzInput) readByte() byte {
+	if len(in.data) == 0 {
+		return 0
+	}
+	b := in.data[0]
+	in.data = in.data[1:]
+	return b
+}
+
+func (in *fuzzInput) readUint16() uint16 {
+	return uint16(in.readByte())<<8 | uint16(in.readByte())
+}
+
+type fuzzAction struct {
+	kind  byte
+	addr  common.Address
+	key   common.Hash
+	value uint16
+}
+
+func (a fuzzAction) apply(s *StateDB) {
+	switch a.kind % 8 {
+	case 0:
+		s.SetBalance(a.addr, big.NewInt(int64(a.value)))
+	case 1:
+		s.AddBalance(a.addr, big.NewInt(int64(a.value)))
+	case 2:
+		s.SetNonce(a.addr, uint64(a.value))
+	case 3:
+		var value common.Hash
+		// zero values delete the slot
+		if a.value%4 != 0 {
+			binary.BigEndian.PutUint16(value[30:], a.value)
+		}
+		s.SetState(a.addr, a.key, value)
+	case 4:
+		code := make([]byte, 2+a.value%32)
+		binary.BigEndian.PutUint16(code, a.value)
+		s.SetCode(a.addr, code)
+	case 5:
+		s.CreateAccount(a.addr)
+	case 6:
+		s.Suicide(a.addr)
+	case 7:
+		data := make([]byte, 2)
+		binary.BigEndian.PutUint16(data, a.value)
+		s.AddLog(&types.Log{Address: a.addr, Data: data})
+	}
+}
+
+// fuzzTx is a group of actions finalised together, like the state changes of a transaction.
+type fuzzTx struct {
+	hash    common.Hash
+	actions []fuzzAction
+}
+
+func (tx fuzzTx) apply(s *StateDB) {
+	s.SetTxContext(tx.hash, 0)
+	for _, action := range tx.actions {
+		action.apply(s)
+	}
+	s.Finalise(true)
+}
+
+func decodeFuzzTx(in *fuzzInput, index int) fuzzTx {
+	tx := fuzzTx{hash: common.BigToHash(big.NewInt(int64(index + 1)))}
+	for n := int(in.readByte()%4) + 1; n > 0; n-- {
+		tx.actions = append(tx.actions, fuzzAction{
+			kind:  in.readByte(),
+			addr:  fuzzAddrs[int(in.readByte())%len(fuzzAddrs)],
+			key:   keys[int(in.readByte())%len(keys)],
+			value: in.readUint16(),
+		})
+	}
+	return tx
+}
+
+// newFuzzReference returns an untouched state with the transactions applied on top of the initial state.
+func newFuzzReference(txs []fuzzTx) *StateDB {
+	s := newStateTest().state
+	prepareInitialState(s)
+	for _, tx := range txs {
+		tx.apply(s)
+	}
+	return s
+}
+
+// checkFuzzEqual checks that the state is observably identical to the reference.
+func checkFuzzEqual(state, reference *StateDB, txs int) error {
+	for _, addr := range fuzzAddrs {
+		if err := verifyObservableAccountState(state, getObservableAccountState(reference, addr, keys)); err != nil {
+			return fmt.Errorf("account %v: %w", addr, err)
+		}
+	}
+	for i := 0; i < txs; i++ {
+		hash := common.BigToHash(big.NewInt(int64(i + 1)))
+		if got, want := len(state.GetLogs(hash, 0, common.Hash{})), len(reference.GetLogs(hash, 0, common.Hash{})); got != want {
+			return fmt.Errorf("tx %d: logs mismatch %d != %d", i+1, got, want)
+		}
+	}
+	if state.logSize != reference.logSize {
+		return fmt.Errorf("log size mismatch %d != %d", state.logSize, reference.logSize)
+	}
+	if len(state.stateObjectsPending) != len(reference.stateObjectsPending) {
+		return fmt.Errorf("pending state objects mismatch %d != %d", len(state.stateObjectsPending), len(reference.stateObjectsPending))
+	}
+	for addr := range reference.stateObjectsPending {
+		if _, ok := state.stateObjectsPending[addr]; !ok {
+			return fmt.Errorf("state object %v not pending", addr)
+		}
+	}
+	if len(state.stateObjectsDirty) != len(reference.stateObjectsDirty) {
+		return fmt.Errorf("dirty state objects mismatch %d != %d", len(state.stateObjectsDirty), len(reference.stateObjectsDirty))
+	}
+	for addr := range reference.stateObjectsDirty {
+		if _, ok := state.stateObjectsDirty[addr]; !ok {
+			return fmt.Errorf("state object %v not dirty", addr)
+		}
+	}
+	return nil
+}
+
+// FuzzMultiTxSnapshotRevert applies random transactions under a random stack of multi-transaction
+// snapshots, reverting and committing the snapshots in a random order. After every revert the state
+// must be identical to an untouched state with only the transactions that were not reverted applied.
+func FuzzMultiTxSnapshotRevert(f *testing.F) {
+	f.Add([]byte{0, 4, 0, 1, 2, 3, 0, 1, 2})
+	f.Add([]byte{4, 3, 6, 1, 0, 0, 0, 0, 0, 4, 0, 0, 5, 0, 2})
+	f.Add([]byte{0, 4, 1, 3, 5, 7, 0, 1, 0, 5, 2, 6, 5, 9, 0, 0, 3, 2, 0, 4, 0, 6, 5, 0, 0, 0, 2})
+	f.Add([]byte{0, 0, 4, 2, 7, 22, 1, 0, 3, 3, 20, 0, 0, 0, 4, 0, 6, 23, 0, 0, 0, 2, 4, 0, 5, 23, 0, 0, 0, 2, 2})
+	f.Add([]byte{1, 5, 3, 4, 1, 0, 3, 0, 9, 0, 1, 7, 9, 0, 0, 3, 1, 4, 0, 0, 3, 1, 5, 0, 5, 4, 3, 2})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var (
+			in    = &fuzzInput{data: data}
+			state = newFuzzReference(nil)
+
+			// base are the transactions applied outside of any snapshot, or whose snapshots were
+			// all committed, layers are the transactions applied in each snapshot of the stack
+			base   []fuzzTx
+			layers [][]fuzzTx
+			txs    int
+		)
+		applied := func() []fuzzTx {
+			all := append([]fuzzTx{}, base...)
+			for _, layer := range layers {
+				all = append(all, layer...)
+			}
+			return all
+		}
+
+		for op := 0; op < maxFuzzMultiTxOps && len(in.data) > 0; op++ {
+			switch in.readByte() % 8 {
+			case 0, 1:
+				if err := state.NewMultiTxSnapshot(); err != nil {
+					t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+				}
+				layers = append(layers, nil)
+			case 2:
+				if len(layers) == 0 {
+					continue
+				}
+				if err := state.MultiTxSnapshotRevert(); err != nil {
+					t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+				}
+				layers = layers[:len(layers)-1]
+				if err := checkFuzzEqual(state, newFuzzReference(applied()), txs); err != nil {
+					t.Fatalf("state mismatch after revert at op %d: %v", op, err)
+				}
+			case 3:
+				if len(layers) == 0 {
+					continue
+				}
+				if err := state.MultiTxSnapshotCommit(); err != nil {
+					t.Fatalf("MultiTxSnapshotCommit failed: %v", err)
+				}
+				head := layers[len(layers)-1]
+				layers = layers[:len(layers)-1]
+				if len(layers) == 0 {
+					base = append(base, head...)
+				} else {
+					layers[len(layers)-1] = append(layers[len(layers)-1], head...)
+				}
+			default:
+				tx := decodeFuzzTx(in, txs)
+				txs++
+				tx.apply(state)
+				if len(layers) == 0 {
+					base = append(base, tx)
+				} else {
+					layers[len(layers)-1] = append(layers[len(layers)-1], tx)
+				}
+			}
+		}
+
+		// revert the remaining snapshots, the state must only contain the committed transactions
+		for len(layers) > 0 {
+			if err := state.MultiTxSnapshotRevert(); err != nil {
+				t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+			}
+			layers = layers[:len(layers)-1]
+		}
+		if size := state.MultiTxSnapshotStackSize(); size != 0 {
+			t.Fatalf("expected empty snapshot stack, got %d", size)
+		}
+		reference := newFuzzReference(base)
+		if err := checkFuzzEqual(state, reference, txs); err != nil {
+			t.Fatalf("state mismatch after reverting all snapshots: %v", err)
+		}
+		if root, expected := state.IntermediateRoot(true), reference.IntermediateRoot(true); root != expected {
+			t.Fatalf("root mismatch %v != %v", root, expected)
+		}
+	})
+}
@@ -0,0 +1,479 @@
+package state
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+)
+
+// ErrMultiTxSnapshotMemoryLimit is returned when a multi-transaction snapshot cannot be created
+// without exceeding the memory limit of the stack.
+var ErrMultiTxSnapshotMemoryLimit = errors.New("failed to create new multi-transaction snapshot - memory limit exceeded")
+
+// The estimated memory of the snapshot entries, including the map overhead
+const (
+	snapshotEntrySize  = 64  // Entry keyed by an address or a hash
+	snapshotSlotSize   = 96  // Storage slot
+	snapshotObjectSize = 512 // State object without its code and storage
+)
+
+// multiTxSnapshotStackID is the last id of the stacks, which prefixes the keys of their spilled
+// snapshots in a shared store
+var multiTxSnapshotStackID atomic.Uint64
+
+// SetMemoryLimit caps the estimated memory held by the snapshots below the head of the stack, 0
+// for no limit. Once the limit is exceeded the oldest snapshots are spilled to the given store,
+// and loaded back when they are on top of the stack again. The snapshot right below the head is
+// not spilled, it is loaded back by the next commit or revert. Without store, no snapshot can be
+// pushed over the limit. The spilled snapshots are deleted from the store when loaded back, the
+// ones of a stack dropped before being unwound are left in it.
+func (stack *MultiTxSnapshotStack) SetMemoryLimit(limit uint64, spill ethdb.KeyValueStore) {
+	stack.memoryLimit, stack.spill = limit, spill
+}
+
+// memoryUsage estimates the memory held by the snapshot.
+func (s *MultiTxSnapshot) memoryUsage() uint64 {
+	entries := len(s.numLogsAdded) + len(s.prevObjects) + len(s.accountStorage) + len(s.accountBalance) +
+		len(s.accountNonce) + len(s.accountCode) + len(s.accountSuicided) + len(s.accountDeleted) +
+		len(s.accountNotPending) + len(s.accountNotDirty) + len(s.touchedAccounts) + len(s.accountDestruct) +
+		len(s.snapAccounts) + len(s.snapStorage) + len(s.transientStorage) + s.reads.size()
+	size := uint64(entries) * snapshotEntrySize
+	for _, object := range s.prevObjects {
+		if object != nil {
+			slots := len(object.originStorage) + len(object.pendingStorage) + len(object.dirtyStorage)
+			size += snapshotObjectSize + uint64(len(object.code)) + uint64(slots)*snapshotSlotSize
+		}
+	}
+	for _, storage := range s.accountStorage {
+		size += uint64(len(storage)) * snapshotSlotSize
+	}
+	for address, code := range s.accountCode {
+		size += uint64(len(code) + len(s.accountCodeHash[address]))
+	}
+	for _, data := range s.snapAccounts {
+		size += uint64(len(data))
+	}
+	for _, storage := range s.snapStorage {
+		size += uint64(len(storage)) * snapshotSlotSize
+	}
+	for _, storage := range s.transientStorage {
+		size += uint64(len(storage)) * snapshotSlotSize
+	}
+	for _, task := range s.prefetches {
+		size += uint64(len(task.keys)) * snapshotEntrySize
+	}
+	return size
+}
+
+// coolHead accounts the memory of the head snapshot about to be covered by a new snapshot.
+func (stack *MultiTxSnapshotStack) coolHead() error {
+	head := stack.Peek()
+	if head == nil || stack.memoryLimit == 0 {
+		return nil
+	}
+	memory := head.memoryUsage()
+	if stack.spill == nil && stack.memory+memory > stack.memoryLimit {
+		return ErrMultiTxSnapshotMemoryLimit
+	}
+	stack.track(head, memory)
+	return nil
+}
+
+// warmHead takes the head snapshot out of the memory accounting and loads it back if it was
+// spilled. The stack is invalidated if the snapshot cannot be loaded.
+func (stack *MultiTxSnapshotStack) warmHead() {
+	head := stack.Peek()
+	if head == nil {
+		return
+	}
+	stack.untrack(head)
+	if !head.spilled {
+		return
+	}
+	if err := stack.load(len(stack.snapshots) - 1); err != nil {
+		log.Error("Failed to load spilled multi-transaction snapshot", "err", err)
+		stack.Invalidate()
+	}
+}
+
+func (stack *MultiTxSnapshotStack) track(snapshot *MultiTxSnapshot, memory uint64) {
+	snapshot.memory = memory
+	stack.memory += memory
+	multiTxSnapshotMemoryGauge.Inc(int64(memory))
+}
+
+func (stack *MultiTxSnapshotStack) untrack(snapshot *MultiTxSnapshot) {
+	stack.memory -= snapshot.memory
+	multiTxSnapshotMemoryGauge.Dec(int64(snapshot.memory))
+	snapshot.memory = 0
+}
+
+// spillOldest spills the oldest snapshots in memory until the memory limit is met, except the
+// head and the snapshot right below it. A snapshot that cannot be spilled stays in memory.
+func (stack *MultiTxSnapshotStack) spillOldest() {
+	if stack.spill == nil {
+		return
+	}
+	for i := 0; i < len(stack.snapshots)-2; i++ {
+		if stack.memory <= stack.memoryLimit {
+			return
+		}
+		if stack.snapshots[i].spilled || stack.snapshots[i].memory == 0 {
+			continue
+		}
+		if err := stack.spillSnapshot(i); err != nil {
+			log.Warn("Failed to spill multi-transaction snapshot", "err", err)
+			return
+		}
+	}
+}
+
+func (stack *MultiTxSnapshotStack) spillKey(i int) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[:8], stack.id)
+	binary.BigEndian.PutUint64(key[8:], uint64(i))
+	return key
+}
+
+// spillSnapshot moves the changes of a snapshot to the spill store. The snapshot left on the
+// stack only keeps its invalid flag, journal cursor, root, refund, reads and prefetches, it must
+// be loaded back before use.
+func (stack *MultiTxSnapshotStack) spillSnapshot(i int) error {
+	snapshot := &stack.snapshots[i]
+	data, err := rlp.EncodeToBytes(snapshot.encodeSpilled())
+	if err != nil {
+		return err
+	}
+	if err := stack.spill.Put(stack.spillKey(i), data); err != nil {
+		return err
+	}
+	stack.untrack(snapshot)
+	*snapshot = MultiTxSnapshot{
+		invalid:      snapshot.invalid,
+		journal:      snapshot.journal,
+		journalIndex: snapshot.journalIndex,
+		spilled:      true,
+		root:         snapshot.root,
+		refund:       snapshot.refund,
+		reads:        snapshot.reads,
+		prefetches:   snapshot.prefetches,
+	}
+	multiTxSnapshotSpilledGauge.Inc(1)
+	multiTxSnapshotSpillMeter.Mark(1)
+	return nil
+}
+
+// readSpilled reads the changes of a spilled snapshot, with the state objects bound to the
+// given state.
+func (stack *MultiTxSnapshotStack) readSpilled(i int, st *StateDB) (MultiTxSnapshot, error) {
+	data, err := stack.spill.Get(stack.spillKey(i))
+	if err != nil {
+		return MultiTxSnapshot{}, fmt.Errorf("missing spilled snapshot %d: %w", i, err)
+	}
+	var spilled spilledSnapshot
+	if err := rlp.DecodeBytes(data, &spilled); err != nil {
+		return MultiTxSnapshot{}, fmt.Errorf("invalid spilled snapshot %d: %w", i, err)
+	}
+	snapshot := spilled.decode(st)
+	snapshot.invalid = stack.snapshots[i].invalid
+	snapshot.journal, snapshot.journalIndex = stack.snapshots[i].journal, stack.snapshots[i].journalIndex
+	snapshot.root, snapshot.refund = stack.snapshots[i].root, stack.snapshots[i].refund
+	snapshot.reads, snapshot.prefetches = stack.snapshots[i].reads, stack.snapshots[i].prefetches
+	return snapshot, nil
+}
+
+// load loads a spilled snapshot back in memory and deletes it from the spill store.
+func (stack *MultiTxSnapshotStack) load(i int) error {
+	snapshot, err := stack.readSpilled(i, stack.state)
+	if err != nil {
+		return err
+	}
+	stack.snapshots[i] = snapshot
+	stack.deleteSpilled(i)
+	multiTxSnapshotLoadMeter.Mark(1)
+	return nil
+}
+
+func (stack *MultiTxSnapshotStack) deleteSpilled(i int) {
+	if err := stack.spill.Delete(stack.spillKey(i)); err != nil {
+		log.Warn("Failed to delete spilled multi-transaction snapshot", "err", err)
+	}
+	multiTxSnapshotSpilledGauge.Dec(1)
+}
+
+// spilledSnapshot is the RLP encoding of the changes of a spilled snapshot.
+type spilledSnapshot struct {
+	Logs         []spilledLogs
+	Objects      []spilledObject
+	Storage      []spilledStorage
+	Balances     []spilledBalance
+	Nonces       []spilledNonce
+	Codes        []spilledCode
+	Suicided     []spilledFlag
+	Deleted      []spilledFlag
+	NotPending   []common.Address
+	NotDirty     []common.Address
+	Touched      []common.Address
+	Destruct     []spilledFlag
+	SnapAccounts []spilledSnapAccount
+	SnapStorage  []spilledSnapStorage
+	HasTransient bool // False if the transient storage was not changed
+	Transient    []spilledTransient
+}
+
+type spilledLogs struct {
+	TxHash common.Hash
+	Count  uint64
+}
+
+// spilledObject is a state object replaced since the snapshot. Its storage trie is opened again
+// from the storage root, the tries are not modified before the snapshots are invalidated.
+type spilledObject struct {
+	Address        common.Address
+	Exists         bool // False if the account did not exist
+	Account        types.StateAccount
+	Code           []byte
+	DirtyCode      bool
+	Suicided       bool
+	Deleted        bool
+	OriginStorage  []spilledSlot
+	PendingStorage []spilledSlot
+	DirtyStorage   []spilledSlot
+}
+
+type spilledSlot struct {
+	Key   common.Hash
+	Value common.Hash
+}
+
+type spilledStorage struct {
+	Address common.Address
+	Slots   []spilledStorageSlot
+}
+
+type spilledStorageSlot struct {
+	Key   common.Hash
+	Value *common.Hash `rlp:"nil"` // Nil if the slot was not pending
+}
+
+type spilledBalance struct {
+	Address common.Address
+	Balance *uint256.Int
+}
+
+type spilledNonce struct {
+	Address common.Address
+	Nonce   uint64
+}
+
+type spilledCode struct {
+	Address  common.Address
+	Code     []byte
+	CodeHash []byte
+}
+
+type spilledFlag struct {
+	Address common.Address
+	Value   bool
+}
+
+type spilledSnapAccount struct {
+	AddrHash common.Hash
+	Data     []byte
+}
+
+type spilledSnapStorage struct {
+	AddrHash common.Hash
+	Exists   bool // False if there was no storage in the snapshot layer
+	Slots    []spilledSnapSlot
+}
+
+type spilledSnapSlot struct {
+	Key   common.Hash
+	Value []byte
+}
+
+type spilledTransient struct {
+	Address common.Address
+	Slots   []spilledSlot
+}
+
+func encodeSpilledSlots(storage Storage) []spilledSlot {
+	slots := make([]spilledSlot, 0, len(storage))
+	for key, value := range storage {
+		slots = append(slots, spilledSlot{key, value})
+	}
+	return slots
+}
+
+func decodeSpilledSlots(slots []spilledSlot) Storage {
+	storage := make(Storage, len(slots))
+	for _, slot := range slots {
+		storage[slot.Key] = slot.Value
+	}
+	return storage
+}
+
+// nilIfEmpty returns nil for empty data, the RLP decoding does not tell nil and empty apart.
+func nilIfEmpty(data []byte) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
+func (s *MultiTxSnapshot) encodeSpilled() *spilledSnapshot {
+	spilled := new(spilledSnapshot)
+	for txHash, count := range s.numLogsAdded {
+		spilled.Logs = append(spilled.Logs, spilledLogs{txHash, uint64(count)})
+	}
+	for address, object := range s.prevObjects {
+		entry := spilledObject{Address: address, Account: types.StateAccount{Balance: new(big.Int)}}
+		if object != nil {
+			entry.Exists = true
+			entry.Account = object.data
+			entry.Code = object.code
+			entry.DirtyCode, entry.Suicided, entry.Deleted = object.dirtyCode, object.suicided, object.deleted
+			entry.OriginStorage = encodeSpilledSlots(object.originStorage)
+			entry.PendingStorage = encodeSpilledSlots(object.pendingStorage)
+			entry.DirtyStorage = encodeSpilledSlots(object.dirtyStorage)
+		}
+		spilled.Objects = append(spilled.Objects, entry)
+	}
+	for address, storage := range s.accountStorage {
+		entry := spilledStorage{Address: address}
+		for key, value := range storage {
+			entry.Slots = append(entry.Slots, spilledStorageSlot{key, value})
+		}
+		spilled.Storage = append(spilled.Storage, entry)
+	}
+	for address, balance := range s.accountBalance {
+		spilled.Balances = append(spilled.Balances, spilledBalance{address, balance})
+	}
+	for address, nonce := range s.accountNonce {
+		spilled.Nonces = append(spilled.Nonces, spilledNonce{address, nonce})
+	}
+	for address, code := range s.accountCode {
+		spilled.Codes = append(spilled.Codes, spilledCode{address, code, s.accountCodeHash[address]})
+	}
+	for address, suicided := range s.accountSuicided {
+		spilled.Suicided = append(spilled.Suicided, spilledFlag{address, suicided})
+	}
+	for address, deleted := range s.accountDeleted {
+		spilled.Deleted = append(spilled.Deleted, spilledFlag{address, deleted})
+	}
+	for address := range s.accountNotPending {
+		spilled.NotPending = append(spilled.NotPending, address)
+	}
+	for address := range s.accountNotDirty {
+		spilled.NotDirty = append(spilled.NotDirty, address)
+	}
+	for address := range s.touchedAccounts {
+		spilled.Touched = append(spilled.Touched, address)
+	}
+	for address, destructed := range s.accountDestruct {
+		spilled.Destruct = append(spilled.Destruct, spilledFlag{address, destructed})
+	}
+	for addrHash, data := range s.snapAccounts {
+		spilled.SnapAccounts = append(spilled.SnapAccounts, spilledSnapAccount{addrHash, data})
+	}
+	for addrHash, storage := range s.snapStorage {
+		entry := spilledSnapStorage{AddrHash: addrHash, Exists: storage != nil}
+		for key, value := range storage {
+			entry.Slots = append(entry.Slots, spilledSnapSlot{key, value})
+		}
+		spilled.SnapStorage = append(spilled.SnapStorage, entry)
+	}
+	if s.transientStorage != nil {
+		spilled.HasTransient = true
+		for address, storage := range s.transientStorage {
+			spilled.Transient = append(spilled.Transient, spilledTransient{address, encodeSpilledSlots(storage)})
+		}
+	}
+	return spilled
+}
+
+func (spilled *spilledSnapshot) decode(st *StateDB) MultiTxSnapshot {
+	s := newMultiTxSnapshot()
+	for _, entry := range spilled.Logs {
+		s.numLogsAdded[entry.TxHash] = int(entry.Count)
+	}
+	for _, entry := range spilled.Objects {
+		if !entry.Exists {
+			s.prevObjects[entry.Address] = nil
+			continue
+		}
+		object := newObject(st, entry.Address, entry.Account)
+		object.code = nilIfEmpty(entry.Code)
+		object.dirtyCode, object.suicided, object.deleted = entry.DirtyCode, entry.Suicided, entry.Deleted
+		object.originStorage = decodeSpilledSlots(entry.OriginStorage)
+		object.pendingStorage = decodeSpilledSlots(entry.PendingStorage)
+		object.dirtyStorage = decodeSpilledSlots(entry.DirtyStorage)
+		s.prevObjects[entry.Address] = object
+	}
+	for _, entry := range spilled.Storage {
+		storage := make(map[common.Hash]*common.Hash, len(entry.Slots))
+		for _, slot := range entry.Slots {
+			storage[slot.Key] = slot.Value
+		}
+		s.accountStorage[entry.Address] = storage
+	}
+	for _, entry := range spilled.Balances {
+		s.accountBalance[entry.Address] = entry.Balance
+	}
+	for _, entry := range spilled.Nonces {
+		s.accountNonce[entry.Address] = entry.Nonce
+	}
+	for _, entry := range spilled.Codes {
+		s.accountCode[entry.Address] = nilIfEmpty(entry.Code)
+		s.accountCodeHash[entry.Address] = nilIfEmpty(entry.CodeHash)
+	}
+	for _, entry := range spilled.Suicided {
+		s.accountSuicided[entry.Address] = entry.Value
+	}
+	for _, entry := range spilled.Deleted {
+		s.accountDeleted[entry.Address] = entry.Value
+	}
+	for _, address := range spilled.NotPending {
+		s.accountNotPending[address] = struct{}{}
+	}
+	for _, address := range spilled.NotDirty {
+		s.accountNotDirty[address] = struct{}{}
+	}
+	for _, address := range spilled.Touched {
+		s.touchedAccounts[address] = struct{}{}
+	}
+	for _, entry := range spilled.Destruct {
+		s.accountDestruct[entry.Address] = entry.Value
+	}
+	for _, entry := range spilled.SnapAccounts {
+		s.snapAccounts[entry.AddrHash] = nilIfEmpty(entry.Data)
+	}
+	for _, entry := range spilled.SnapStorage {
+		if !entry.Exists {
+			s.snapStorage[entry.AddrHash] = nil
+			continue
+		}
+		storage := make(map[common.Hash][]byte, len(entry.Slots))
+		for _, slot := range entry.Slots {
+			storage[slot.Key] = nilIfEmpty(slot.Value)
+		}
+		s.snapStorage[entry.AddrHash] = storage
+	}
+	if spilled.HasTransient {
+		s.transientStorage = newTransientStorage()
+		for _, entry := range spilled.Transient {
+			s.transientStorage[entry.Address] = decodeSpilledSlots(entry.Slots)
+		}
+	}
+	return s
+}
@@ -0,0 +1,91 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+)
+
+// This test verifies that the snapshots spilled over the memory limit are restored, in the state
+// and its copies, when they are loaded back
+func TestMultiTxSnapshotSpill(t *testing.T) {
+	spill := memorydb.New()
+	testMultiTxSnapshot(t, func(s *StateDB) {
+		stack := s.multiTxSnapshotStack
+		stack.SetMemoryLimit(1, spill)
+		for i, addr := range addrs {
+			s.SetBalance(addr, big.NewInt(int64(79+i)))
+			s.SetState(addr, keys[i], common.HexToHash("0x80"))
+			switch i % 3 {
+			case 0:
+				s.CreateAccount(addr)
+				s.SetCode(addr, []byte{0x80, byte(i)})
+			case 1:
+				s.Suicide(addr)
+			}
+			s.Finalise(true)
+			if err := s.NewMultiTxSnapshot(); err != nil {
+				t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+			}
+		}
+
+		// all the snapshots but the head and the one right below are spilled
+		size := stack.Size()
+		for i, snapshot := range stack.snapshots {
+			if spilled := i < size-2; snapshot.spilled != spilled {
+				t.Errorf("snapshot %d spilled mismatch, got %v, expected %v", i, snapshot.spilled, spilled)
+			}
+		}
+		if spill.Len() != size-2 {
+			t.Errorf("spilled snapshots mismatch, got %d, expected %d", spill.Len(), size-2)
+		}
+
+		// the copy reads the spilled snapshots, and spills its own
+		stateCopy := s.Copy()
+		if stateCopy.MultiTxSnapshotStackSize() != size {
+			t.Fatalf("copy stack size mismatch, got %d, expected %d", stateCopy.MultiTxSnapshotStackSize(), size)
+		}
+		if spill.Len() != 2*(size-2) {
+			t.Errorf("spilled snapshots mismatch after copy, got %d, expected %d", spill.Len(), 2*(size-2))
+		}
+		for stateCopy.MultiTxSnapshotStackSize() > 0 {
+			if err := stateCopy.MultiTxSnapshotRevert(); err != nil {
+				t.Fatalf("MultiTxSnapshotRevert of the copy failed: %v", err)
+			}
+		}
+		cleanState := newStateTest()
+		prepareInitialState(cleanState.state)
+		if root, expected := stateCopy.IntermediateRoot(true), cleanState.state.IntermediateRoot(true); root != expected {
+			t.Errorf("copy root mismatch, got %x, expected %x", root, expected)
+		}
+
+		// the spilled snapshots are merged once loaded back
+		for stack.Size() > 1 {
+			if err := s.MultiTxSnapshotCommit(); err != nil {
+				t.Fatalf("MultiTxSnapshotCommit failed: %v", err)
+			}
+		}
+		if spill.Len() != 0 {
+			t.Errorf("spilled snapshots left, got %d", spill.Len())
+		}
+		if stack.memory != 0 {
+			t.Errorf("memory left, got %d", stack.memory)
+		}
+	})
+}
+
+func TestMultiTxSnapshotMemoryLimit(t *testing.T) {
+	testMultiTxSnapshot(t, func(s *StateDB) {
+		s.multiTxSnapshotStack.SetMemoryLimit(1, nil)
+		s.SetBalance(addrs[0], big.NewInt(79))
+		s.Finalise(true)
+		if err := s.NewMultiTxSnapshot(); err != ErrMultiTxSnapshotMemoryLimit {
+			t.Fatalf("expected %v, got %v", ErrMultiTxSnapshotMemoryLimit, err)
+		}
+		if size := s.MultiTxSnapshotStackSize(); size != 1 {
+			t.Errorf("stack size mismatch, got %d, expected 1", size)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// StateDiff is the state touched since a multi-transaction snapshot was created. It only holds
+// the changes of the finalised transactions, the journal is applied to the snapshot by Finalise.
+type StateDiff struct {
+	Accounts map[common.Address]*TouchedAccount `json:"accounts"`
+	// Reads are the accounts read since the snapshot was created, with the storage slots read,
+	// sorted. Nil unless the state reads are recorded, see RecordReads.
+	Reads map[common.Address][]common.Hash `json:"reads,omitempty"`
+}
+
+// TouchedAccount is an account touched since a multi-transaction snapshot was created, with the
+// fields written. An account touched without write, e.g. by a zero value transfer, has no field
+// set.
+type TouchedAccount struct {
+	// Replaced is true if the account was created or replaced, all its fields and storage are
+	// written
+	Replaced   bool          `json:"replaced,omitempty"`
+	Destructed bool          `json:"destructed,omitempty"`
+	Balance    bool          `json:"balance,omitempty"`
+	Nonce      bool          `json:"nonce,omitempty"`
+	Code       bool          `json:"code,omitempty"`
+	Storage    []common.Hash `json:"storage,omitempty"` // Sorted
+}
+
+// Diff returns the accounts, storage slots, balances and code touched since the snapshot was
+// created.
+func (s *MultiTxSnapshot) Diff() *StateDiff {
+	diff := &StateDiff{Accounts: make(map[common.Address]*TouchedAccount, len(s.touchedAccounts))}
+	account := func(address common.Address) *TouchedAccount {
+		touched, ok := diff.Accounts[address]
+		if !ok {
+			touched = new(TouchedAccount)
+			diff.Accounts[address] = touched
+		}
+		return touched
+	}
+	for address := range s.touchedAccounts {
+		account(address)
+	}
+	for address := range s.prevObjects {
+		account(address).Replaced = true
+	}
+	for address := range s.accountBalance {
+		account(address).Balance = true
+	}
+	for address := range s.accountNonce {
+		account(address).Nonce = true
+	}
+	for address := range s.accountCode {
+		account(address).Code = true
+	}
+	for address, suicided := range s.accountSuicided {
+		if !suicided {
+			account(address).Destructed = true
+		}
+	}
+	for address, deleted := range s.accountDeleted {
+		if !deleted {
+			account(address).Destructed = true
+		}
+	}
+	for address, storage := range s.accountStorage {
+		touched := account(address)
+		touched.Storage = make([]common.Hash, 0, len(storage))
+		for key := range storage {
+			touched.Storage = append(touched.Storage, key)
+		}
+		sortHashes(touched.Storage)
+	}
+
+	if s.reads != nil {
+		diff.Reads = make(map[common.Address][]common.Hash, len(s.reads.accounts))
+		for address := range s.reads.accounts {
+			diff.Reads[address] = nil
+		}
+		for address := range s.reads.codes {
+			diff.Reads[address] = nil
+		}
+		for address, slots := range s.reads.storage {
+			keys := make([]common.Hash, 0, len(slots))
+			for key := range slots {
+				keys = append(keys, key)
+			}
+			sortHashes(keys)
+			diff.Reads[address] = keys
+		}
+	}
+	return diff
+}
+
+func sortHashes(hashes []common.Hash) {
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+}
+
+// Conflicts returns whether both diffs write the same account field or storage slot, the state
+// changes they hold then depend on their order. The diffs do not hold the reads, the changes of
+// one diff can still change the outcome of the other.
+func (d *StateDiff) Conflicts(other *StateDiff) bool {
+	for address, touched := range d.Accounts {
+		otherTouched, ok := other.Accounts[address]
+		if !ok {
+			continue
+		}
+		if touched.conflicts(otherTouched) {
+			return true
+		}
+	}
+	return false
+}
+
+// Interferes returns whether the diffs write the same state, or one of them reads state the other
+// writes: the changes of the diffs may then differ when applied on top of each other. Diffs
+// without the reads always interfere.
+func (d *StateDiff) Interferes(other *StateDiff) bool {
+	if d.Reads == nil || other.Reads == nil {
+		return true
+	}
+	return d.Conflicts(other) || d.readsWritesOf(other) || other.readsWritesOf(d)
+}
+
+// readsWritesOf returns whether the diff reads an account or storage slot the other diff writes.
+// Reading an account reads all its fields, a write to any of them interferes.
+func (d *StateDiff) readsWritesOf(other *StateDiff) bool {
+	for address, slots := range d.Reads {
+		touched, ok := other.Accounts[address]
+		if !ok {
+			continue
+		}
+		if touched.Replaced || touched.Destructed || touched.Balance || touched.Nonce || touched.Code {
+			return true
+		}
+		if sortedIntersect(slots, touched.Storage) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedIntersect returns whether the sorted hashes share an element.
+func sortedIntersect(a, b []common.Hash) bool {
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch bytes.Compare(a[i][:], b[j][:]) {
+		case 0:
+			return true
+		case -1:
+			i++
+		default:
+			j++
+		}
+	}
+	return false
+}
+
+func (a *TouchedAccount) conflicts(other *TouchedAccount) bool {
+	written := func(account *TouchedAccount) bool {
+		return account.Balance || account.Nonce || account.Code || len(account.Storage) != 0
+	}
+	switch {
+	case a.Replaced || a.Destructed:
+		return other.Replaced || other.Destructed || written(other)
+	case other.Replaced || other.Destructed:
+		return written(a)
+	case a.Balance && other.Balance, a.Nonce && other.Nonce, a.Code && other.Code:
+		return true
+	}
+	// the storage slots are sorted
+	return sortedIntersect(a.Storage, other.Storage)
+}
+
+// MultiTxSnapshotDiff returns the state touched since the multi-transaction snapshot on top of
+// the stack was created.
+func (s *StateDB) MultiTxSnapshotDiff() (*StateDiff, error) {
+	snapshot := s.multiTxSnapshotStack.Peek()
+	if snapshot == nil {
+		return nil, errNoMultiTxSnapshot
+	}
+	if snapshot.invalid {
+		return nil, errors.New("multi-transaction snapshot is invalid")
+	}
+	return snapshot.Diff(), nil
+}
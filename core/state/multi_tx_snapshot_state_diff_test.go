@@ -0,0 +1,157 @@
+package state
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestMultiTxSnapshotDiff(t *testing.T) {
+	var (
+		addr1 = common.HexToAddress("0x01")
+		addr2 = common.HexToAddress("0x02")
+		addr3 = common.HexToAddress("0x03")
+		key1  = common.HexToHash("0x01")
+		key2  = common.HexToHash("0x02")
+	)
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	state.SetBalance(addr1, big.NewInt(100))
+	state.SetBalance(addr2, big.NewInt(5))
+	state.SetCode(addr2, []byte{1, 2, 3})
+	state.Finalise(true)
+
+	if _, err := state.MultiTxSnapshotDiff(); err != errNoMultiTxSnapshot {
+		t.Fatalf("expected %v, got %v", errNoMultiTxSnapshot, err)
+	}
+	if err := state.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	state.SetBalance(addr1, big.NewInt(50))
+	state.SetNonce(addr1, 2)
+	state.SetState(addr1, key2, common.HexToHash("0x0b"))
+	state.SetState(addr1, key1, common.HexToHash("0x0a"))
+	state.Suicide(addr2)
+	state.CreateAccount(addr3)
+	state.Finalise(true)
+
+	diff, err := state.MultiTxSnapshotDiff()
+	if err != nil {
+		t.Fatalf("failed to diff snapshot: %v", err)
+	}
+	want := map[common.Address]*TouchedAccount{
+		addr1: {Balance: true, Nonce: true, Storage: []common.Hash{key1, key2}},
+		addr2: {Destructed: true, Balance: true},
+		addr3: {Replaced: true},
+	}
+	if len(diff.Accounts) != len(want) {
+		t.Fatalf("expected %d accounts, got %d", len(want), len(diff.Accounts))
+	}
+	for addr, touched := range want {
+		if !reflect.DeepEqual(diff.Accounts[addr], touched) {
+			t.Errorf("account %x: expected %+v, got %+v", addr, touched, diff.Accounts[addr])
+		}
+	}
+}
+
+func TestStateDiffConflicts(t *testing.T) {
+	var (
+		addr1 = common.HexToAddress("0x01")
+		addr2 = common.HexToAddress("0x02")
+		key1  = common.HexToHash("0x01")
+		key2  = common.HexToHash("0x02")
+		key3  = common.HexToHash("0x03")
+	)
+	diff := func(addr common.Address, touched TouchedAccount) *StateDiff {
+		return &StateDiff{Accounts: map[common.Address]*TouchedAccount{addr: &touched}}
+	}
+	tests := []struct {
+		name      string
+		a, b      *StateDiff
+		conflicts bool
+	}{
+		{"different accounts", diff(addr1, TouchedAccount{Balance: true}), diff(addr2, TouchedAccount{Balance: true}), false},
+		{"same balance", diff(addr1, TouchedAccount{Balance: true}), diff(addr1, TouchedAccount{Balance: true}), true},
+		{"different fields", diff(addr1, TouchedAccount{Balance: true}), diff(addr1, TouchedAccount{Nonce: true}), false},
+		{"touched only", diff(addr1, TouchedAccount{}), diff(addr1, TouchedAccount{Balance: true}), false},
+		{"different slots", diff(addr1, TouchedAccount{Storage: []common.Hash{key1, key3}}), diff(addr1, TouchedAccount{Storage: []common.Hash{key2}}), false},
+		{"same slot", diff(addr1, TouchedAccount{Storage: []common.Hash{key1, key3}}), diff(addr1, TouchedAccount{Storage: []common.Hash{key2, key3}}), true},
+		{"destructed written", diff(addr1, TouchedAccount{Destructed: true}), diff(addr1, TouchedAccount{Storage: []common.Hash{key1}}), true},
+		{"written replaced", diff(addr1, TouchedAccount{Code: true}), diff(addr1, TouchedAccount{Replaced: true}), true},
+		{"destructed touched", diff(addr1, TouchedAccount{Destructed: true}), diff(addr1, TouchedAccount{}), false},
+	}
+	for _, test := range tests {
+		if conflicts := test.a.Conflicts(test.b); conflicts != test.conflicts {
+			t.Errorf("%s: expected conflicts %v, got %v", test.name, test.conflicts, conflicts)
+		}
+		if conflicts := test.b.Conflicts(test.a); conflicts != test.conflicts {
+			t.Errorf("%s (reversed): expected conflicts %v, got %v", test.name, test.conflicts, conflicts)
+		}
+	}
+}
+
+func TestStateDiffInterferes(t *testing.T) {
+	var (
+		addr1 = common.HexToAddress("0x01")
+		addr2 = common.HexToAddress("0x02")
+		key1  = common.HexToHash("0x01")
+		key2  = common.HexToHash("0x02")
+	)
+	diff := func(addr common.Address, touched TouchedAccount, reads map[common.Address][]common.Hash) *StateDiff {
+		return &StateDiff{Accounts: map[common.Address]*TouchedAccount{addr: &touched}, Reads: reads}
+	}
+	tests := []struct {
+		name       string
+		a, b       *StateDiff
+		interferes bool
+	}{
+		{"disjoint", diff(addr1, TouchedAccount{Balance: true}, map[common.Address][]common.Hash{addr1: nil}), diff(addr2, TouchedAccount{Balance: true}, map[common.Address][]common.Hash{addr2: nil}), false},
+		{"reads unknown", diff(addr1, TouchedAccount{Balance: true}, map[common.Address][]common.Hash{addr1: nil}), diff(addr2, TouchedAccount{Balance: true}, nil), true},
+		{"same balance", diff(addr1, TouchedAccount{Balance: true}, map[common.Address][]common.Hash{}), diff(addr1, TouchedAccount{Balance: true}, map[common.Address][]common.Hash{}), true},
+		{"account read written", diff(addr1, TouchedAccount{}, map[common.Address][]common.Hash{addr2: nil}), diff(addr2, TouchedAccount{Nonce: true}, map[common.Address][]common.Hash{}), true},
+		{"other slot read", diff(addr1, TouchedAccount{}, map[common.Address][]common.Hash{addr2: {key1}}), diff(addr2, TouchedAccount{Storage: []common.Hash{key2}}, map[common.Address][]common.Hash{}), false},
+		{"slot read written", diff(addr1, TouchedAccount{}, map[common.Address][]common.Hash{addr2: {key1, key2}}), diff(addr2, TouchedAccount{Storage: []common.Hash{key2}}, map[common.Address][]common.Hash{}), true},
+		{"both read", diff(addr1, TouchedAccount{}, map[common.Address][]common.Hash{addr2: {key1}}), diff(addr1, TouchedAccount{}, map[common.Address][]common.Hash{addr2: {key1}}), false},
+	}
+	for _, test := range tests {
+		if interferes := test.a.Interferes(test.b); interferes != test.interferes {
+			t.Errorf("%s: expected interferes %v, got %v", test.name, test.interferes, interferes)
+		}
+		if interferes := test.b.Interferes(test.a); interferes != test.interferes {
+			t.Errorf("%s (reversed): expected interferes %v, got %v", test.name, test.interferes, interferes)
+		}
+	}
+}
+
+func TestMultiTxSnapshotDiffReads(t *testing.T) {
+	var (
+		addr1 = common.HexToAddress("0x01")
+		addr2 = common.HexToAddress("0x02")
+		key1  = common.HexToHash("0x01")
+	)
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	state.SetBalance(addr1, big.NewInt(100))
+	state.SetNonce(addr2, 1)
+	state.SetState(addr2, key1, common.HexToHash("0x0a"))
+	state.Finalise(true)
+
+	state.RecordReads()
+	if err := state.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("failed to create snapshot: %v", err)
+	}
+	state.GetBalance(addr1)
+	state.GetState(addr2, key1)
+	diff, err := state.MultiTxSnapshotDiff()
+	if err != nil {
+		t.Fatalf("failed to diff snapshot: %v", err)
+	}
+	want := map[common.Address][]common.Hash{addr1: nil, addr2: {key1}}
+	if !reflect.DeepEqual(diff.Reads, want) {
+		t.Errorf("reads mismatch, expected %v, got %v", want, diff.Reads)
+	}
+	if len(diff.Accounts) != 0 {
+		t.Errorf("expected no account written, got %d", len(diff.Accounts))
+	}
+}
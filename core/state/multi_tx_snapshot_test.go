@@ -9,6 +9,10 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 var (
@@ -376,6 +380,34 @@ func TestMultiTxSnapshotRefund(t *testing.T) {
 	})
 }
 
+func TestMultiTxSnapshotRefundCounter(t *testing.T) {
+	testMultiTxSnapshot(t, func(s *StateDB) {
+		// the refund is only kept within a transaction, the snapshots are taken between its calls
+		s.AddRefund(100)
+		if err := s.NewMultiTxSnapshot(); err != nil {
+			t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+		}
+		s.AddRefund(50)
+		if err := s.NewMultiTxSnapshot(); err != nil {
+			t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+		}
+		s.SubRefund(120)
+		if err := s.MultiTxSnapshotRevert(); err != nil {
+			t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+		}
+		if refund := s.GetRefund(); refund != 150 {
+			t.Errorf("refund mismatch, got %d, expected 150", refund)
+		}
+		if err := s.MultiTxSnapshotRevert(); err != nil {
+			t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+		}
+		if refund := s.GetRefund(); refund != 100 {
+			t.Errorf("refund mismatch, got %d, expected 100", refund)
+		}
+		s.Finalise(true)
+	})
+}
+
 func TestMultiTxSnapshotAccountChangesMultiTx(t *testing.T) {
 	testMultiTxSnapshot(t, func(s *StateDB) {
 		for _, addr := range addrs {
@@ -456,6 +488,326 @@ func TestMultiTxSnapshotStateChanges(t *testing.T) {
 	})
 }
 
+// This test verifies that the snapshot is updated incrementally from the journal, across the
+// journal reverts interleaved with the updates
+func TestMultiTxSnapshotJournalCursor(t *testing.T) {
+	testMultiTxSnapshot(t, func(s *StateDB) {
+		stack := s.multiTxSnapshotStack
+		txHash := common.HexToHash("0x01")
+		s.SetTxContext(txHash, 0)
+		s.AddLog(&types.Log{})
+		s.SetBalance(addrs[0], big.NewInt(79))
+
+		// the entries already applied are not applied again
+		stack.UpdateFromJournal(s.journal)
+		stack.UpdateFromJournal(s.journal)
+		head := stack.Peek()
+		if head.journalIndex != s.journal.length() {
+			t.Errorf("journal cursor mismatch, got %d, expected %d", head.journalIndex, s.journal.length())
+		}
+		if head.numLogsAdded[txHash] != 1 {
+			t.Errorf("logs added mismatch, got %d, expected 1", head.numLogsAdded[txHash])
+		}
+
+		// the entries appended in place of reverted entries are applied
+		snap := s.Snapshot()
+		s.SetNonce(addrs[1], 78)
+		stack.UpdateFromJournal(s.journal)
+		s.RevertToSnapshot(snap)
+		if head.journalIndex != s.journal.length() {
+			t.Errorf("journal cursor not moved back on revert, got %d, expected %d", head.journalIndex, s.journal.length())
+		}
+		s.SetNonce(addrs[2], 78)
+		s.SetCode(addrs[3], []byte{0x80})
+		stack.UpdateFromJournal(s.journal)
+		if _, ok := head.accountCode[addrs[3]]; !ok {
+			t.Error("code change appended after the revert not applied")
+		}
+
+		// a nested snapshot pushed before the journal is cleared
+		if err := s.NewMultiTxSnapshot(); err != nil {
+			t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+		}
+		s.SetBalance(addrs[4], big.NewInt(80))
+		s.Finalise(true)
+		if _, err := stack.Commit(); err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+		if head := stack.Peek(); head.numLogsAdded[txHash] != 1 {
+			t.Errorf("logs added mismatch after commit, got %d, expected 1", head.numLogsAdded[txHash])
+		}
+
+		for _, addr := range addrs {
+			s.SetNonce(addr, 79)
+		}
+		stack.UpdateFromJournal(s.journal)
+		s.Finalise(true)
+	})
+}
+
+// This test verifies that the object captured by the snapshot when an account is replaced is not
+// modified when a journal revert restores it
+func TestMultiTxSnapshotReplacedObjectAliasing(t *testing.T) {
+	testMultiTxSnapshot(t, func(s *StateDB) {
+		for _, addr := range addrs {
+			snap := s.Snapshot()
+			s.CreateAccount(addr)
+			// the snapshot captures the replaced object, then the journal revert restores it
+			s.multiTxSnapshotStack.UpdateFromJournal(s.journal)
+			s.RevertToSnapshot(snap)
+
+			s.SetBalance(addr, big.NewInt(79))
+			s.SetNonce(addr, 78)
+			s.SetState(addr, keys[0], common.HexToHash("0x80"))
+		}
+		s.Finalise(true)
+
+		for _, addr := range addrs {
+			s.CreateAccount(addr)
+			s.SetBalance(addr, big.NewInt(81))
+		}
+		s.Finalise(true)
+	})
+}
+
+// This test verifies that the destruct markers and the snapshot layer data of the accounts
+// destructed since the snapshot are restored on revert
+func TestMultiTxSnapshotDestruct(t *testing.T) {
+	rng = rand.New(rand.NewSource(0))
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	snaps, err := snapshot.New(snapshot.Config{CacheSize: 1}, db.DiskDB(), db.TrieDB(), types.EmptyRootHash)
+	if err != nil {
+		t.Fatalf("failed to create snapshot tree: %v", err)
+	}
+	s, err := New(types.EmptyRootHash, db, snaps)
+	if err != nil {
+		t.Fatalf("failed to create state: %v", err)
+	}
+	if s.snap == nil {
+		t.Fatal("state without snapshot layer")
+	}
+	for _, addr := range addrs {
+		randFillAccount(addr, s)
+	}
+	// the destructed account is resurrected in the block before the snapshot
+	s.Suicide(addrs[0])
+	s.Finalise(true)
+	randFillAccount(addrs[0], s)
+	s.IntermediateRoot(true)
+
+	destructBefore := make(map[common.Address]struct{})
+	for addr := range s.stateObjectsDestruct {
+		destructBefore[addr] = struct{}{}
+	}
+	snapAccountsBefore := make(map[common.Hash][]byte)
+	for addrHash, data := range s.snapAccounts {
+		snapAccountsBefore[addrHash] = data
+	}
+	snapStorageBefore := make(map[common.Hash]map[common.Hash][]byte)
+	for addrHash, storage := range s.snapStorage {
+		snapStorageBefore[addrHash] = storage
+	}
+	expectedRoot := s.Copy().IntermediateRoot(true)
+
+	if err := s.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+	for _, addr := range addrs[:4] {
+		s.Suicide(addr)
+	}
+	s.Finalise(true)
+	s.CreateAccount(addrs[4])
+	s.SetBalance(addrs[4], big.NewInt(79))
+	s.Finalise(true)
+	if len(s.stateObjectsDestruct) == len(destructBefore) || len(s.snapAccounts) == len(snapAccountsBefore) {
+		t.Fatal("destructs not applied")
+	}
+	if err := s.MultiTxSnapshotRevert(); err != nil {
+		t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(s.stateObjectsDestruct, destructBefore) {
+		t.Errorf("destructed accounts mismatch, got %v, expected %v", s.stateObjectsDestruct, destructBefore)
+	}
+	if !reflect.DeepEqual(s.snapAccounts, snapAccountsBefore) {
+		t.Errorf("snapshot layer accounts mismatch, got %d accounts, expected %d", len(s.snapAccounts), len(snapAccountsBefore))
+	}
+	if !reflect.DeepEqual(s.snapStorage, snapStorageBefore) {
+		t.Errorf("snapshot layer storage mismatch, got %d accounts, expected %d", len(s.snapStorage), len(snapStorageBefore))
+	}
+	if root := s.IntermediateRoot(true); root != expectedRoot {
+		t.Errorf("root mismatch, got %x, expected %x", root, expectedRoot)
+	}
+}
+
+// This test verifies that the transient storage (EIP-1153) is restored on revert, across journal
+// reverts and the transient storage resets of the transactions
+func TestMultiTxSnapshotTransientStorage(t *testing.T) {
+	s := newStateTest().state
+	rules := params.Rules{IsBerlin: true}
+	s.SetTransientState(addrs[0], keys[0], common.HexToHash("0x01"))
+	s.Finalise(true)
+
+	if err := s.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+	// a transaction reverted by the journal
+	snap := s.Snapshot()
+	s.SetTransientState(addrs[0], keys[0], common.HexToHash("0x02"))
+	s.RevertToSnapshot(snap)
+	if value := s.GetTransientState(addrs[0], keys[0]); value != common.HexToHash("0x01") {
+		t.Fatalf("transient storage not reverted by the journal, got %x", value)
+	}
+
+	// nested snapshot, with transactions resetting the transient storage
+	if err := s.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+	for i := 1; i < 3; i++ {
+		s.Prepare(rules, addrs[i], common.Address{}, nil, nil, nil)
+		s.SetTransientState(addrs[i], keys[i], common.HexToHash("0x03"))
+		s.Finalise(true)
+	}
+	if err := s.MultiTxSnapshotRevert(); err != nil {
+		t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+	}
+	expected := newTransientStorage()
+	expected.Set(addrs[0], keys[0], common.HexToHash("0x01"))
+	if !reflect.DeepEqual(s.transientStorage, expected) {
+		t.Errorf("transient storage mismatch after nested revert, got %v, expected %v", s.transientStorage, expected)
+	}
+
+	// changes committed to the parent snapshot
+	if err := s.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+	s.Prepare(rules, addrs[3], common.Address{}, nil, nil, nil)
+	s.SetTransientState(addrs[3], keys[3], common.HexToHash("0x04"))
+	s.Finalise(true)
+	if err := s.MultiTxSnapshotCommit(); err != nil {
+		t.Fatalf("MultiTxSnapshotCommit failed: %v", err)
+	}
+	if value := s.GetTransientState(addrs[3], keys[3]); value != common.HexToHash("0x04") {
+		t.Errorf("committed transient storage mismatch, got %x", value)
+	}
+	if err := s.MultiTxSnapshotRevert(); err != nil {
+		t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+	}
+	if !reflect.DeepEqual(s.transientStorage, expected) {
+		t.Errorf("transient storage mismatch after revert, got %v, expected %v", s.transientStorage, expected)
+	}
+}
+
+func TestMultiTxSnapshotPrefetch(t *testing.T) {
+	// the state of the test has no snapshot tree, StartPrefetcher would not start the prefetcher
+	s := newStateTest().state
+	s.prefetcher = newTriePrefetcher(s.db, s.originalRoot, "test")
+	defer s.StopPrefetcher()
+
+	// the items scheduled while the snapshot is active are dropped with it
+	if err := s.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+	s.SetBalance(addrs[0], big.NewInt(79))
+	s.Finalise(true)
+	if tasks := len(s.multiTxSnapshotStack.Peek().prefetches); tasks != 1 {
+		t.Fatalf("expected 1 prefetch held by the snapshot, got %d", tasks)
+	}
+	if fetchers := len(s.prefetcher.fetchers); fetchers != 0 {
+		t.Fatalf("expected no fetcher before commit, got %d", fetchers)
+	}
+	if err := s.MultiTxSnapshotRevert(); err != nil {
+		t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+	}
+	if fetchers := len(s.prefetcher.fetchers); fetchers != 0 {
+		t.Fatalf("expected no fetcher after revert, got %d", fetchers)
+	}
+
+	// and shipped to the prefetcher once committed
+	if err := s.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+	if err := s.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+	s.SetBalance(addrs[1], big.NewInt(79))
+	s.Finalise(true)
+	if err := s.MultiTxSnapshotCommit(); err != nil {
+		t.Fatalf("MultiTxSnapshotCommit failed: %v", err)
+	}
+	if fetchers := len(s.prefetcher.fetchers); fetchers != 1 {
+		t.Fatalf("expected 1 fetcher after commit, got %d", fetchers)
+	}
+	if tasks := len(s.multiTxSnapshotStack.Peek().prefetches); tasks != 0 {
+		t.Fatalf("expected no prefetch promoted to the snapshot below, got %d", tasks)
+	}
+}
+
+func TestStackInvalidate(t *testing.T) {
+	s := newStateTest()
+	prepareInitialState(s.state)
+
+	for i := 0; i < 3; i++ {
+		if err := s.state.NewMultiTxSnapshot(); err != nil {
+			t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+		}
+		s.state.SetBalance(addrs[i], big.NewInt(79))
+		s.state.Finalise(true)
+	}
+	// the head is committed before the trie flush
+	if err := s.state.MultiTxSnapshotCommit(); err != nil {
+		t.Fatalf("MultiTxSnapshotCommit failed: %v", err)
+	}
+	if s.state.MultiTxSnapshotInvalidated() {
+		t.Fatal("stack invalidated before the trie flush")
+	}
+
+	// the trie flush invalidates all the snapshots, which are kept on the stack
+	s.state.IntermediateRoot(true)
+	if !s.state.MultiTxSnapshotInvalidated() {
+		t.Fatal("stack not invalidated by the trie flush")
+	}
+	stack := s.state.multiTxSnapshotStack
+	if stack.Size() != 2 {
+		t.Fatalf("expected stack size to be 2, got %d", stack.Size())
+	}
+	for i, snapshot := range stack.snapshots {
+		if !snapshot.invalid {
+			t.Errorf("snapshot %d not invalidated", i)
+		}
+	}
+
+	if err := s.state.MultiTxSnapshotCommit(); err == nil {
+		t.Error("commit of an invalidated snapshot succeeded")
+	}
+	if err := s.state.MultiTxSnapshotRevert(); err == nil {
+		t.Error("revert of an invalidated snapshot succeeded")
+	}
+	if err := s.state.NewMultiTxSnapshot(); err == nil {
+		t.Error("snapshot on top of an invalidated snapshot succeeded")
+	}
+	if stack.Size() != 2 {
+		t.Errorf("expected stack size to be 2 after the failed operations, got %d", stack.Size())
+	}
+
+	// the ancestors are invalid as well once the head is dropped
+	if _, err := stack.Pop(); err != nil {
+		t.Fatalf("Pop failed: %v", err)
+	}
+	if err := s.state.MultiTxSnapshotCommit(); err == nil {
+		t.Error("commit of an invalidated ancestor succeeded")
+	}
+	if _, err := stack.Pop(); err != nil {
+		t.Fatalf("Pop failed: %v", err)
+	}
+	if s.state.MultiTxSnapshotInvalidated() {
+		t.Error("empty stack invalidated")
+	}
+	if err := s.state.NewMultiTxSnapshot(); err != nil {
+		t.Errorf("NewMultiTxSnapshot on an empty stack failed: %v", err)
+	}
+}
+
 func TestStackBasic(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		testMultiTxSnapshot(t, func(s *StateDB) {
@@ -927,3 +1279,177 @@ func CompareAndPrintSnapshotMismatches(t *testing.T, target, other *MultiTxSnaps
 	fmt.Println(out.String())
 	out.Reset()
 }
+
+func TestStackSquashToDepth(t *testing.T) {
+	testMultiTxSnapshot(t, func(s *StateDB) {
+		stack := s.multiTxSnapshotStack
+		randFillAccount(addrs[0], s)
+		s.Finalise(true)
+
+		var obsStates []*observableAccountState
+		for _, addr := range addrs {
+			obsStates = append(obsStates, getObservableAccountState(s, addr, keys))
+		}
+		for _, addr := range addrs {
+			if err := s.NewMultiTxSnapshot(); err != nil {
+				t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+			}
+			randFillAccount(addr, s)
+			s.Finalise(true)
+		}
+
+		if err := stack.SquashToDepth(stack.Size()); err == nil {
+			t.Error("expected squash above the head to fail")
+		}
+		if err := stack.SquashToDepth(1); err != nil {
+			t.Fatalf("SquashToDepth failed: %v", err)
+		}
+		if size := stack.Size(); size != 2 {
+			t.Fatalf("expected stack size to be 2, got %d", size)
+		}
+
+		// the squashed snapshot reverts the changes of all the snapshots it replaced
+		if err := s.MultiTxSnapshotRevert(); err != nil {
+			t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+		}
+		for _, obsState := range obsStates {
+			if err := verifyObservableAccountState(s, obsState); err != nil {
+				t.Error("state mismatch", "account", obsState.address, err)
+			}
+		}
+
+		if err := s.NewMultiTxSnapshot(); err != nil {
+			t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+		}
+		randFillAccount(addrs[1], s)
+		s.Finalise(true)
+		if err := stack.SquashToDepth(0); err != nil {
+			t.Fatalf("SquashToDepth failed: %v", err)
+		}
+		if size := stack.Size(); size != 1 {
+			t.Fatalf("expected stack size to be 1, got %d", size)
+		}
+	})
+}
+
+// benchmarkStack returns a state with a stack of the given number of snapshots, each changing an
+// account and a storage slot.
+func benchmarkStack(b *testing.B, depth int) *StateDB {
+	s := newStateTest().state
+	prepareInitialState(s)
+	s.Finalise(true)
+	for i := 0; i < depth; i++ {
+		if err := s.NewMultiTxSnapshot(); err != nil {
+			b.Fatalf("NewMultiTxSnapshot failed: %v", err)
+		}
+		addr := addrs[i%len(addrs)]
+		s.SetBalance(addr, big.NewInt(int64(i)))
+		s.SetState(addr, keys[i%len(keys)], common.Hash{byte(i)})
+		s.Finalise(true)
+	}
+	return s
+}
+
+// BenchmarkMultiTxSnapshotRevert compares reverting a deep stack of snapshots with reverting it
+// once squashed into one snapshot.
+func BenchmarkMultiTxSnapshotRevert(b *testing.B) {
+	for _, depth := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("deep-%d", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				s := benchmarkStack(b, depth)
+				b.StartTimer()
+				if _, err := s.multiTxSnapshotStack.RevertAll(); err != nil {
+					b.Fatalf("RevertAll failed: %v", err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("squashed-%d", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				s := benchmarkStack(b, depth)
+				if err := s.MultiTxSnapshotSquashToDepth(0); err != nil {
+					b.Fatalf("SquashToDepth failed: %v", err)
+				}
+				b.StartTimer()
+				if _, err := s.multiTxSnapshotStack.RevertAll(); err != nil {
+					b.Fatalf("RevertAll failed: %v", err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("squash-%d", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				s := benchmarkStack(b, depth)
+				b.StartTimer()
+				if err := s.MultiTxSnapshotSquashToDepth(0); err != nil {
+					b.Fatalf("SquashToDepth failed: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkMultiTxSnapshotBalances reports the allocations of the balance changes captured by the
+// snapshots, when they are journaled and when the snapshots are merged and reverted.
+func BenchmarkMultiTxSnapshotBalances(b *testing.B) {
+	balance := new(big.Int).Lsh(big.NewInt(1), 100)
+	fill := func(s *StateDB) {
+		for i, addr := range addrs {
+			s.SetBalance(addr, new(big.Int).Add(balance, big.NewInt(int64(i))))
+		}
+		s.Finalise(true)
+	}
+	b.Run("journal", func(b *testing.B) {
+		s := newStateTest().state
+		prepareInitialState(s)
+		s.Finalise(true)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := s.NewMultiTxSnapshot(); err != nil {
+				b.Fatalf("NewMultiTxSnapshot failed: %v", err)
+			}
+			fill(s)
+			if err := s.MultiTxSnapshotCommit(); err != nil {
+				b.Fatalf("MultiTxSnapshotCommit failed: %v", err)
+			}
+		}
+	})
+	b.Run("merge", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			s := newStateTest().state
+			prepareInitialState(s)
+			s.Finalise(true)
+			for j := 0; j < 2; j++ {
+				if err := s.NewMultiTxSnapshot(); err != nil {
+					b.Fatalf("NewMultiTxSnapshot failed: %v", err)
+				}
+				fill(s)
+			}
+			b.StartTimer()
+			if err := s.MultiTxSnapshotCommit(); err != nil {
+				b.Fatalf("MultiTxSnapshotCommit failed: %v", err)
+			}
+		}
+	})
+	b.Run("revert", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			s := newStateTest().state
+			prepareInitialState(s)
+			s.Finalise(true)
+			if err := s.NewMultiTxSnapshot(); err != nil {
+				b.Fatalf("NewMultiTxSnapshot failed: %v", err)
+			}
+			fill(s)
+			b.StartTimer()
+			if err := s.MultiTxSnapshotRevert(); err != nil {
+				b.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// errMultiTxSnapshotRootMismatch is returned by a verified revert which did not restore the state
+// root of the reverted snapshot.
+var errMultiTxSnapshotRootMismatch = errors.New("failed to revert multi-transaction snapshot - state root mismatch")
+
+// SetVerify enables the verification of the reverts of the stack. The state root is computed
+// when each snapshot is created and compared with the root once the snapshot is reverted. On a
+// mismatch, the accounts and slots changed since the snapshot are logged and the stack is
+// invalidated. The roots are computed on forks of the state, which is left unchanged, but every
+// snapshot and revert hashes the state, so this is meant for debugging.
+func (stack *MultiTxSnapshotStack) SetVerify(verify bool) {
+	stack.verify = verify
+}
+
+// verificationRoot returns the intermediate root of the state without finalising it, which
+// would invalidate its multi-transaction snapshots.
+func (s *StateDB) verificationRoot() common.Hash {
+	return s.Fork().IntermediateRoot(true)
+}
+
+// verifyRevert compares the state root after the revert of a snapshot with the root when it was
+// created. The diff is the one of the snapshot before the revert.
+func (stack *MultiTxSnapshotStack) verifyRevert(snapshot *MultiTxSnapshot, diff *StateDiff) error {
+	root := stack.state.verificationRoot()
+	if root == snapshot.root {
+		return nil
+	}
+	multiTxSnapshotMismatchMeter.Mark(1)
+	log.Error("Multi-transaction snapshot revert did not restore the state", "expected", snapshot.root, "got", root, "accounts", len(diff.Accounts), "depth", len(stack.snapshots))
+
+	addresses := make([]common.Address, 0, len(diff.Accounts))
+	for address := range diff.Accounts {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+	for _, address := range addresses {
+		touched := diff.Accounts[address]
+		slots := make([]string, len(touched.Storage))
+		for i, slot := range touched.Storage {
+			slots[i] = slot.Hex()
+		}
+		log.Error("Account changed since the reverted snapshot", "address", address,
+			"replaced", touched.Replaced, "destructed", touched.Destructed, "balance", touched.Balance,
+			"nonce", touched.Nonce, "code", touched.Code, "slots", strings.Join(slots, ","))
+	}
+	stack.Invalidate()
+	return errMultiTxSnapshotRootMismatch
+}
@@ -0,0 +1,47 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestMultiTxSnapshotVerify(t *testing.T) {
+	testMultiTxSnapshot(t, func(s *StateDB) {
+		s.SetMultiTxSnapshotVerification(true)
+		if err := s.NewMultiTxSnapshot(); err != nil {
+			t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+		}
+		for _, addr := range addrs {
+			randFillAccount(addr, s)
+		}
+		s.Suicide(addrs[0])
+		s.Finalise(true)
+		if err := s.MultiTxSnapshotRevert(); err != nil {
+			t.Fatalf("verified MultiTxSnapshotRevert failed: %v", err)
+		}
+	})
+
+	// a change the snapshot does not know of is not reverted
+	s := newStateTest().state
+	prepareInitialState(s)
+	s.SetMultiTxSnapshotVerification(true)
+	for i := 0; i < 2; i++ {
+		if err := s.NewMultiTxSnapshot(); err != nil {
+			t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+		}
+	}
+	s.SetBalance(addrs[0], big.NewInt(79))
+	s.Finalise(true)
+	object := s.getStateObject(addrs[1])
+	object.data.Nonce++
+	s.stateObjectsPending[addrs[1]] = struct{}{}
+	if err := s.MultiTxSnapshotRevert(); err != errMultiTxSnapshotRootMismatch {
+		t.Fatalf("expected %v, got %v", errMultiTxSnapshotRootMismatch, err)
+	}
+	if s.GetBalance(addrs[0]).Cmp(big.NewInt(79)) == 0 {
+		t.Error("balance not reverted")
+	}
+	if !s.MultiTxSnapshotInvalidated() {
+		t.Error("expected the stack to be invalidated")
+	}
+}
@@ -0,0 +1,242 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var errWitnessNotRecorded = errors.New("failed to build witness - state reads not recorded")
+
+// Witness is the state an execution of a block reads and writes, for validators not holding the
+// state: the trie nodes proving the accounts, storage slots and codes read against the state
+// root before the block, along with the nodes needed to compute the new root.
+type Witness struct {
+	Root  common.Hash // State root before the block
+	Nodes [][]byte    // Encoded trie nodes, sorted
+	Codes [][]byte    // Contract codes, sorted by hash
+}
+
+// stateReads are the accounts, storage slots and contract codes read from the state.
+type stateReads struct {
+	accounts map[common.Address]struct{}
+	storage  map[common.Address]map[common.Hash]struct{}
+	codes    map[common.Address]struct{}
+}
+
+func newStateReads() *stateReads {
+	return &stateReads{
+		accounts: make(map[common.Address]struct{}),
+		storage:  make(map[common.Address]map[common.Hash]struct{}),
+		codes:    make(map[common.Address]struct{}),
+	}
+}
+
+// merge adds the reads of other to the reads, both may be nil.
+func (r *stateReads) merge(other *stateReads) {
+	if r == nil || other == nil {
+		return
+	}
+	for address := range other.accounts {
+		r.accounts[address] = struct{}{}
+	}
+	for address, slots := range other.storage {
+		storage, ok := r.storage[address]
+		if !ok {
+			storage = make(map[common.Hash]struct{}, len(slots))
+			r.storage[address] = storage
+		}
+		for key := range slots {
+			storage[key] = struct{}{}
+		}
+	}
+	for address := range other.codes {
+		r.codes[address] = struct{}{}
+	}
+}
+
+func (r *stateReads) copy() *stateReads {
+	if r == nil {
+		return nil
+	}
+	cpy := newStateReads()
+	cpy.merge(r)
+	return cpy
+}
+
+func (r *stateReads) size() int {
+	if r == nil {
+		return 0
+	}
+	size := len(r.accounts) + len(r.codes)
+	for _, slots := range r.storage {
+		size += len(slots)
+	}
+	return size
+}
+
+// RecordWitness starts recording the state read, for the witness of the block built on the
+// state. It must be called before any multi-transaction snapshot is created. The reads made since
+// a snapshot are dropped when it is reverted, so the witness only proves the state read by the
+// transactions kept in the block.
+func (s *StateDB) RecordWitness() {
+	s.RecordReads()
+}
+
+// RecordReads starts recording the state read, reported by the diffs of the multi-transaction
+// snapshots created afterwards. It must be called before any multi-transaction snapshot is created.
+func (s *StateDB) RecordReads() {
+	if s.witness == nil {
+		s.witness = newStateReads()
+	}
+}
+
+// reads returns the reads the state accesses are recorded to, those of the head multi-transaction
+// snapshot if any, nil if the reads are not recorded.
+func (s *StateDB) reads() *stateReads {
+	if s.witness == nil {
+		return nil
+	}
+	if head := s.multiTxSnapshotStack.Peek(); head != nil && head.reads != nil {
+		return head.reads
+	}
+	return s.witness
+}
+
+func (s *StateDB) recordAccountRead(address common.Address) {
+	if reads := s.reads(); reads != nil {
+		reads.accounts[address] = struct{}{}
+	}
+}
+
+func (s *StateDB) recordStorageRead(address common.Address, key common.Hash) {
+	if reads := s.reads(); reads != nil {
+		storage, ok := reads.storage[address]
+		if !ok {
+			storage = make(map[common.Hash]struct{})
+			reads.storage[address] = storage
+		}
+		storage[key] = struct{}{}
+	}
+}
+
+func (s *StateDB) recordCodeRead(address common.Address) {
+	if reads := s.reads(); reads != nil {
+		reads.codes[address] = struct{}{}
+	}
+}
+
+// witnessNodes collects the encoded trie nodes of the proofs.
+type witnessNodes map[string]struct{}
+
+func (w witnessNodes) Put(key []byte, value []byte) error {
+	w[string(value)] = struct{}{}
+	return nil
+}
+
+func (w witnessNodes) Delete(key []byte) error {
+	return nil
+}
+
+// Witness returns the witness of the state recorded since RecordWitness. It must be called once
+// the new root is computed, for the witness to hold the nodes loaded by the updates, and with no
+// multi-transaction snapshot left, whose reads are not part of it yet.
+func (s *StateDB) Witness() (*Witness, error) {
+	if s.witness == nil {
+		return nil, errWitnessNotRecorded
+	}
+	if s.multiTxSnapshotStack.Size() > 0 {
+		return nil, errors.New("failed to build witness - multi-transaction snapshots not committed")
+	}
+	accountTrie, err := s.db.OpenTrie(s.originalRoot)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		nodes = make(witnessNodes)
+		codes = make(map[common.Hash][]byte)
+	)
+	addresses := make(map[common.Address]struct{}, len(s.witness.accounts))
+	for address := range s.witness.accounts {
+		addresses[address] = struct{}{}
+	}
+	for address := range s.witness.storage {
+		addresses[address] = struct{}{}
+	}
+	for address := range s.witness.codes {
+		addresses[address] = struct{}{}
+	}
+	for address := range addresses {
+		addrHash := crypto.Keccak256Hash(address[:])
+		if err := accountTrie.Prove(addrHash[:], 0, nodes); err != nil {
+			return nil, fmt.Errorf("failed to prove account %x: %w", address, err)
+		}
+		account, err := accountTrie.TryGetAccount(address)
+		if err != nil {
+			return nil, err
+		}
+		// the accounts created in the block have no prior storage or code
+		if account == nil {
+			continue
+		}
+		if slots := s.witness.storage[address]; len(slots) > 0 && account.Root != types.EmptyRootHash {
+			storageTrie, err := s.db.OpenStorageTrie(s.originalRoot, addrHash, account.Root)
+			if err != nil {
+				return nil, err
+			}
+			for key := range slots {
+				if err := storageTrie.Prove(crypto.Keccak256(key[:]), 0, nodes); err != nil {
+					return nil, fmt.Errorf("failed to prove slot %x of account %x: %w", key, address, err)
+				}
+			}
+		}
+		if _, ok := s.witness.codes[address]; ok && !bytes.Equal(account.CodeHash, types.EmptyCodeHash[:]) {
+			code, err := s.db.ContractCode(addrHash, common.BytesToHash(account.CodeHash))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read code of account %x: %w", address, err)
+			}
+			codes[common.BytesToHash(account.CodeHash)] = code
+		}
+	}
+
+	// the nodes loaded to compute the new root, among which the siblings of the deleted nodes
+	type witnessTrie interface {
+		Witness() map[string]struct{}
+	}
+	if trie, ok := s.trie.(witnessTrie); ok {
+		for node := range trie.Witness() {
+			nodes[node] = struct{}{}
+		}
+	}
+	for _, object := range s.stateObjects {
+		if trie, ok := object.trie.(witnessTrie); ok {
+			for node := range trie.Witness() {
+				nodes[node] = struct{}{}
+			}
+		}
+	}
+
+	witness := &Witness{Root: s.originalRoot, Nodes: make([][]byte, 0, len(nodes))}
+	for node := range nodes {
+		witness.Nodes = append(witness.Nodes, []byte(node))
+	}
+	sort.Slice(witness.Nodes, func(i, j int) bool {
+		return bytes.Compare(witness.Nodes[i], witness.Nodes[j]) < 0
+	})
+	hashes := make([]common.Hash, 0, len(codes))
+	for hash := range codes {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+	for _, hash := range hashes {
+		witness.Codes = append(witness.Codes, codes[hash])
+	}
+	return witness, nil
+}
@@ -0,0 +1,103 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+func TestMultiTxSnapshotWitness(t *testing.T) {
+	var (
+		addr1 = common.HexToAddress("0x01")
+		addr2 = common.HexToAddress("0x02")
+		addr3 = common.HexToAddress("0x03")
+		key1  = common.HexToHash("0x01")
+		code  = []byte{0x60, 0x80, 0x60, 0x40}
+	)
+	db := NewDatabase(rawdb.NewMemoryDatabase())
+	state, _ := New(common.Hash{}, db, nil)
+	for i, addr := range []common.Address{addr1, addr2, addr3} {
+		state.SetBalance(addr, big.NewInt(int64(100+i)))
+	}
+	state.SetCode(addr1, code)
+	state.SetState(addr1, key1, common.HexToHash("0x0a"))
+	root, err := state.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+
+	state, _ = New(root, db, nil)
+	state.RecordWitness()
+	if _, err := state.Witness(); err != nil {
+		t.Fatalf("failed to build empty witness: %v", err)
+	}
+	state.GetBalance(addr2)
+
+	// the state read by a reverted snapshot is not part of the witness
+	if err := state.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+	state.GetBalance(addr3)
+	if err := state.MultiTxSnapshotRevert(); err != nil {
+		t.Fatalf("MultiTxSnapshotRevert failed: %v", err)
+	}
+	if err := state.NewMultiTxSnapshot(); err != nil {
+		t.Fatalf("NewMultiTxSnapshot failed: %v", err)
+	}
+	state.GetState(addr1, key1)
+	state.GetCode(addr1)
+	state.AddBalance(addr2, big.NewInt(1))
+	if _, err := state.Witness(); err == nil {
+		t.Fatal("expected witness to fail with a snapshot left")
+	}
+	if err := state.MultiTxSnapshotCommit(); err != nil {
+		t.Fatalf("MultiTxSnapshotCommit failed: %v", err)
+	}
+	// the recorded reads are checked, without snapshot tree the reverted reads also load nodes in
+	// the live trie, which are all part of the witness
+	if _, ok := state.witness.accounts[addr3]; ok {
+		t.Errorf("account %x read by a reverted snapshot recorded", addr3)
+	}
+	for _, addr := range []common.Address{addr1, addr2} {
+		if _, ok := state.witness.accounts[addr]; !ok {
+			t.Errorf("account %x not recorded", addr)
+		}
+	}
+	state.IntermediateRoot(true)
+
+	witness, err := state.Witness()
+	if err != nil {
+		t.Fatalf("failed to build witness: %v", err)
+	}
+	if witness.Root != root {
+		t.Errorf("witness root mismatch, got %x, expected %x", witness.Root, root)
+	}
+	proofs := memorydb.New()
+	for _, node := range witness.Nodes {
+		proofs.Put(crypto.Keccak256(node), node)
+	}
+	for _, addr := range []common.Address{addr1, addr2} {
+		if _, err := trie.VerifyProof(root, crypto.Keccak256(addr[:]), proofs); err != nil {
+			t.Errorf("account %x not proven: %v", addr, err)
+		}
+	}
+
+	enc, _ := trie.VerifyProof(root, crypto.Keccak256(addr1[:]), proofs)
+	var account types.StateAccount
+	if err := rlp.DecodeBytes(enc, &account); err != nil {
+		t.Fatalf("failed to decode account: %v", err)
+	}
+	if _, err := trie.VerifyProof(account.Root, crypto.Keccak256(key1[:]), proofs); err != nil {
+		t.Errorf("slot %x not proven: %v", key1, err)
+	}
+	if len(witness.Codes) != 1 || string(witness.Codes[0]) != string(code) {
+		t.Errorf("codes mismatch, got %x, expected %x", witness.Codes, code)
+	}
+}
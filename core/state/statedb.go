@@ -29,6 +29,8 @@ import (
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/faultinject"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
@@ -112,6 +114,12 @@ type StateDB struct {
 	// Multi-Transaction Snapshot Stack
 	multiTxSnapshotStack *MultiTxSnapshotStack
 
+	// The state the live objects are copied from on first access, nil unless the state is a fork
+	base *StateDB
+
+	// The state read outside of the multi-transaction snapshots, nil unless recorded for the witness
+	witness *stateReads
+
 	// Measurements gathered during execution for debugging purposes
 	AccountReads         time.Duration
 	AccountHashes        time.Duration
@@ -187,6 +195,20 @@ func (s *StateDB) StopPrefetcher() {
 	}
 }
 
+// schedulePrefetch ships a batch of trie items off to the prefetcher. While a multi-transaction
+// snapshot is active, the items are held by the snapshot until it is committed, and dropped if it
+// is reverted, not to load the tries for transactions left out of the block.
+func (s *StateDB) schedulePrefetch(owner common.Hash, root common.Hash, keys [][]byte) {
+	if s.prefetcher == nil {
+		return
+	}
+	if head := s.multiTxSnapshotStack.Peek(); head != nil {
+		head.prefetches = append(head.prefetches, prefetchTask{owner: owner, root: root, keys: keys})
+		return
+	}
+	s.prefetcher.prefetch(owner, root, keys)
+}
+
 // setError remembers the first non-nil error it is called with.
 func (s *StateDB) setError(err error) {
 	if s.dbErr == nil {
@@ -298,6 +320,7 @@ func (s *StateDB) TxIndex() int {
 func (s *StateDB) GetCode(addr common.Address) []byte {
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
+		s.recordCodeRead(addr)
 		return stateObject.Code(s.db)
 	}
 	return nil
@@ -306,6 +329,7 @@ func (s *StateDB) GetCode(addr common.Address) []byte {
 func (s *StateDB) GetCodeSize(addr common.Address) int {
 	stateObject := s.getStateObject(addr)
 	if stateObject != nil {
+		s.recordCodeRead(addr)
 		return stateObject.CodeSize(s.db)
 	}
 	return 0
@@ -470,7 +494,7 @@ func (s *StateDB) Suicide(addr common.Address) bool {
 	s.journal.append(suicideChange{
 		account:     &addr,
 		prev:        stateObject.suicided,
-		prevbalance: new(big.Int).Set(stateObject.Balance()),
+		prevbalance: journalBalance(stateObject.Balance()),
 	})
 	stateObject.markSuicided()
 	stateObject.data.Balance = new(big.Int)
@@ -491,6 +515,7 @@ func (s *StateDB) SetTransientState(addr common.Address, key, value common.Hash)
 		key:      key,
 		prevalue: prev,
 	})
+	s.multiTxSnapshotStack.UpdateTransientStorage(s.transientStorage)
 	s.setTransientState(addr, key, value)
 }
 
@@ -558,8 +583,10 @@ func (s *StateDB) getStateObject(addr common.Address) *stateObject {
 // flag set. This is needed by the state journal to revert to the correct s-
 // destructed object instead of wiping all knowledge about the state object.
 func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
+	s.recordAccountRead(addr)
+
 	// Prefer live objects if any is available
-	if obj := s.stateObjects[addr]; obj != nil {
+	if obj := s.forkObject(addr); obj != nil {
 		return obj
 	}
 	// If no live objects are available, attempt to use snapshots
@@ -614,6 +641,32 @@ func (s *StateDB) setStateObject(object *stateObject) {
 	s.stateObjects[object.Address()] = object
 }
 
+// lookupObject returns the live object of the address in the state or in the base states of a
+// fork, without copying it.
+func (s *StateDB) lookupObject(addr common.Address) (*stateObject, bool) {
+	for state := s; state != nil; state = state.base {
+		if obj, exist := state.stateObjects[addr]; exist {
+			return obj, true
+		}
+	}
+	return nil, false
+}
+
+// forkObject returns the live object of the address, the live objects of the base states of a
+// fork are copied into the fork on first access.
+func (s *StateDB) forkObject(addr common.Address) *stateObject {
+	if obj := s.stateObjects[addr]; obj != nil || s.base == nil {
+		return obj
+	}
+	obj, exist := s.base.lookupObject(addr)
+	if !exist {
+		return nil
+	}
+	obj = obj.deepCopy(s)
+	s.setStateObject(obj)
+	return obj
+}
+
 // GetOrNewStateObject retrieves a state object or create a new state object if nil.
 func (s *StateDB) GetOrNewStateObject(addr common.Address) *stateObject {
 	stateObject := s.getStateObject(addr)
@@ -725,7 +778,7 @@ func (s *StateDB) Copy() *StateDB {
 		// and in the Finalise-method, there is a case where an object is in the journal but not
 		// in the stateObjects: OOG after touch on ripeMD prior to Byzantium. Thus, we need to check for
 		// nil
-		if object, exist := s.stateObjects[addr]; exist {
+		if object, exist := s.lookupObject(addr); exist {
 			// Even though the original object is dirty, we are not copying the journal,
 			// so we need to make sure that any side-effect the journal would have caused
 			// during a commit (or similar op) is already applied to the copy.
@@ -741,13 +794,15 @@ func (s *StateDB) Copy() *StateDB {
 	// of copies.
 	for addr := range s.stateObjectsPending {
 		if _, exist := state.stateObjects[addr]; !exist {
-			state.stateObjects[addr] = s.stateObjects[addr].deepCopy(state)
+			object, _ := s.lookupObject(addr)
+			state.stateObjects[addr] = object.deepCopy(state)
 		}
 		state.stateObjectsPending[addr] = struct{}{}
 	}
 	for addr := range s.stateObjectsDirty {
 		if _, exist := state.stateObjects[addr]; !exist {
-			state.stateObjects[addr] = s.stateObjects[addr].deepCopy(state)
+			object, _ := s.lookupObject(addr)
+			state.stateObjects[addr] = object.deepCopy(state)
 		}
 		state.stateObjectsDirty[addr] = struct{}{}
 	}
@@ -755,6 +810,19 @@ func (s *StateDB) Copy() *StateDB {
 	for addr := range s.stateObjectsDestruct {
 		state.stateObjectsDestruct[addr] = struct{}{}
 	}
+	s.copyExtras(state)
+	state.witness = s.witness.copy()
+
+	if metrics.EnabledBuilder {
+		stateCopyMeter.Mark(1)
+	}
+
+	return state
+}
+
+// copyExtras copies the logs, preimages, access list, transient storage, prefetcher and snapshot
+// data of the state into a copy.
+func (s *StateDB) copyExtras(state *StateDB) {
 	for hash, logs := range s.logs {
 		cpy := make([]*types.Log, len(logs))
 		for i, l := range logs {
@@ -803,9 +871,53 @@ func (s *StateDB) Copy() *StateDB {
 			state.snapStorage[k] = temp
 		}
 	}
+}
+
+// Fork returns a copy of the state with an empty multi-transaction snapshot stack, for a worker
+// running concurrently with other forks. Unlike Copy, the live state objects are not copied
+// upfront, the fork copies them from the state on first access. The state must not be modified
+// while its forks are in use.
+func (s *StateDB) Fork() *StateDB {
+	state := &StateDB{
+		db:                   s.db,
+		trie:                 s.db.CopyTrie(s.trie),
+		originalRoot:         s.originalRoot,
+		stateObjects:         make(map[common.Address]*stateObject),
+		stateObjectsPending:  make(map[common.Address]struct{}, len(s.stateObjectsPending)),
+		stateObjectsDirty:    make(map[common.Address]struct{}, len(s.stateObjectsDirty)),
+		stateObjectsDestruct: make(map[common.Address]struct{}, len(s.stateObjectsDestruct)),
+		refund:               s.refund,
+		logs:                 make(map[common.Hash][]*types.Log, len(s.logs)),
+		logSize:              s.logSize,
+		preimages:            make(map[common.Hash][]byte, len(s.preimages)),
+		journal:              newJournal(),
+		hasher:               crypto.NewKeccakState(),
+		base:                 s,
+	}
+	state.multiTxSnapshotStack = NewMultiTxSnapshotStack(state)
+	state.multiTxSnapshotStack.SetMemoryLimit(s.multiTxSnapshotStack.memoryLimit, s.multiTxSnapshotStack.spill)
+	state.multiTxSnapshotStack.SetVerify(s.multiTxSnapshotStack.verify)
+
+	// The objects dirty in the journal are marked pending and dirty, as in Copy
+	for addr := range s.journal.dirties {
+		if _, exist := s.lookupObject(addr); exist {
+			state.stateObjectsDirty[addr] = struct{}{}
+			state.stateObjectsPending[addr] = struct{}{}
+		}
+	}
+	for addr := range s.stateObjectsPending {
+		state.stateObjectsPending[addr] = struct{}{}
+	}
+	for addr := range s.stateObjectsDirty {
+		state.stateObjectsDirty[addr] = struct{}{}
+	}
+	for addr := range s.stateObjectsDestruct {
+		state.stateObjectsDestruct[addr] = struct{}{}
+	}
+	s.copyExtras(state)
 
 	if metrics.EnabledBuilder {
-		stateCopyMeter.Mark(1)
+		stateForkMeter.Mark(1)
 	}
 
 	return state
@@ -837,6 +949,7 @@ func (s *StateDB) RevertToSnapshot(revid int) {
 
 	// Replay the journal to undo changes and remove invalidated snapshots
 	s.journal.revert(s, snapshot)
+	s.multiTxSnapshotStack.JournalReverted(s.journal, snapshot)
 	s.validRevisions = s.validRevisions[:idx]
 }
 
@@ -865,6 +978,7 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 		}
 		if obj.suicided || (deleteEmptyObjects && obj.empty()) {
 			s.multiTxSnapshotStack.UpdateObjectDeleted(obj.address, obj.deleted)
+			s.multiTxSnapshotStack.UpdateDestruct(obj.address, obj.addrHash)
 
 			obj.deleted = true
 
@@ -897,8 +1011,8 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 		// the commit-phase will be a lot faster
 		addressesToPrefetch = append(addressesToPrefetch, common.CopyBytes(addr[:])) // Copy needed for closure
 	}
-	if s.prefetcher != nil && len(addressesToPrefetch) > 0 {
-		s.prefetcher.prefetch(common.Hash{}, s.originalRoot, addressesToPrefetch)
+	if len(addressesToPrefetch) > 0 {
+		s.schedulePrefetch(common.Hash{}, s.originalRoot, addressesToPrefetch)
 	}
 	// Invalidate journal because reverting across transactions is not allowed.
 	s.clearJournalAndRefund()
@@ -935,7 +1049,7 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	// first, giving the account prefetches just a few more milliseconds of time
 	// to pull useful data from disk.
 	for addr := range s.stateObjectsPending {
-		if obj := s.stateObjects[addr]; !obj.deleted {
+		if obj := s.forkObject(addr); !obj.deleted {
 			obj.updateRoot(s.db)
 		}
 	}
@@ -949,7 +1063,7 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	}
 	usedAddrs := make([][]byte, 0, len(s.stateObjectsPending))
 	for addr := range s.stateObjectsPending {
-		if obj := s.stateObjects[addr]; obj.deleted {
+		if obj := s.forkObject(addr); obj.deleted {
 			s.deleteStateObject(obj)
 			s.AccountDeleted += 1
 		} else {
@@ -1006,7 +1120,7 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (common.Hash, error) {
 		codeWriter              = s.db.DiskDB().NewBatch()
 	)
 	for addr := range s.stateObjectsDirty {
-		if obj := s.stateObjects[addr]; !obj.deleted {
+		if obj := s.forkObject(addr); !obj.deleted {
 			// Write any contract code associated with the state object
 			if obj.code != nil && obj.dirtyCode {
 				rawdb.WriteCode(codeWriter, common.BytesToHash(obj.CodeHash()), obj.code)
@@ -1151,6 +1265,7 @@ func (s *StateDB) Prepare(rules params.Rules, sender, coinbase common.Address, d
 		}
 	}
 	// Reset transient storage at the beginning of transaction execution
+	s.multiTxSnapshotStack.UpdateTransientStorage(s.transientStorage)
 	s.transientStorage = newTransientStorage()
 }
 
@@ -1193,7 +1308,7 @@ func (s *StateDB) SlotInAccessList(addr common.Address, slot common.Hash) (addre
 func (s *StateDB) convertAccountSet(set map[common.Address]struct{}) map[common.Hash]struct{} {
 	ret := make(map[common.Hash]struct{})
 	for addr := range set {
-		obj, exist := s.stateObjects[addr]
+		obj, exist := s.lookupObject(addr)
 		if !exist {
 			ret[crypto.Keccak256Hash(addr[:])] = struct{}{}
 		} else {
@@ -1205,6 +1320,9 @@ func (s *StateDB) convertAccountSet(set map[common.Address]struct{}) map[common.
 
 func (s *StateDB) NewMultiTxSnapshot() (err error) {
 	_, err = s.multiTxSnapshotStack.NewSnapshot()
+	if err == nil && faultinject.Trigger(faultinject.SnapshotInvalidation) {
+		s.multiTxSnapshotStack.Invalidate()
+	}
 	return
 }
 
@@ -1221,3 +1339,26 @@ func (s *StateDB) MultiTxSnapshotCommit() (err error) {
 func (s *StateDB) MultiTxSnapshotStackSize() int {
 	return s.multiTxSnapshotStack.Size()
 }
+
+// MultiTxSnapshotSquashToDepth merges the multi-transaction snapshots above the given depth into
+// one.
+func (s *StateDB) MultiTxSnapshotSquashToDepth(depth int) error {
+	return s.multiTxSnapshotStack.SquashToDepth(depth)
+}
+
+func (s *StateDB) MultiTxSnapshotInvalidated() bool {
+	return s.multiTxSnapshotStack.Invalidated()
+}
+
+// SetMultiTxSnapshotMemoryLimit caps the memory held by the multi-transaction snapshots, spilling
+// the oldest ones to the given store if any. The limit is kept by the copies of the state.
+func (s *StateDB) SetMultiTxSnapshotMemoryLimit(limit uint64, spill ethdb.KeyValueStore) {
+	s.multiTxSnapshotStack.SetMemoryLimit(limit, spill)
+}
+
+// SetMultiTxSnapshotVerification enables the comparison of the state root after each
+// multi-transaction snapshot revert with the root when the snapshot was created. The verification
+// is kept by the copies of the state.
+func (s *StateDB) SetMultiTxSnapshotVerification(verify bool) {
+	s.multiTxSnapshotStack.SetVerify(verify)
+}
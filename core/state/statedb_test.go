@@ -500,6 +500,73 @@ func TestTouchDelete(t *testing.T) {
 
 // TestCopyOfCopy tests that modified objects are carried over to the copy, and the copy of the copy.
 // See https://github.com/ethereum/go-ethereum/pull/15225#issuecomment-380191512
+// TestFork tests that forks of a state are modified and reverted independently, and
+// concurrently, without modifying the state.
+func TestFork(t *testing.T) {
+	orig, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	for i := byte(0); i < 255; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		orig.AddBalance(addr, big.NewInt(int64(i)))
+		orig.SetState(addr, common.Hash{i}, common.Hash{i})
+	}
+	orig.Finalise(true)
+	root := orig.Copy().IntermediateRoot(true)
+
+	forks := make([]*StateDB, 4)
+	for j := range forks {
+		forks[j] = orig.Fork()
+	}
+	var wg sync.WaitGroup
+	for j, fork := range forks {
+		wg.Add(1)
+		go func(j int, fork *StateDB) {
+			defer wg.Done()
+			if err := fork.NewMultiTxSnapshot(); err != nil {
+				t.Errorf("fork %d: failed to create snapshot: %v", j, err)
+				return
+			}
+			for i := byte(0); i < 255; i++ {
+				addr := common.BytesToAddress([]byte{i})
+				fork.AddBalance(addr, big.NewInt(int64(j)))
+				fork.SetState(addr, common.Hash{i}, common.Hash{byte(j)})
+			}
+			fork.Finalise(true)
+			// the even forks revert their changes
+			if j%2 == 0 {
+				if err := fork.MultiTxSnapshotRevert(); err != nil {
+					t.Errorf("fork %d: failed to revert snapshot: %v", j, err)
+				}
+			}
+		}(j, fork)
+	}
+	wg.Wait()
+
+	for i := byte(0); i < 255; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		if have, want := orig.GetBalance(addr), big.NewInt(int64(i)); have.Cmp(want) != 0 {
+			t.Errorf("orig obj %d: balance mismatch: have %v, want %v", i, have, want)
+		}
+		for j, fork := range forks {
+			want, wantState := int64(i), common.Hash{i}
+			if j%2 == 1 {
+				want, wantState = int64(i)+int64(j), common.Hash{byte(j)}
+			}
+			if have := fork.GetBalance(addr); have.Cmp(big.NewInt(want)) != 0 {
+				t.Errorf("fork %d obj %d: balance mismatch: have %v, want %v", j, i, have, want)
+			}
+			if have := fork.GetState(addr, common.Hash{i}); have != wantState {
+				t.Errorf("fork %d obj %d: state mismatch: have %x, want %x", j, i, have, wantState)
+			}
+		}
+	}
+	if have := forks[0].IntermediateRoot(true); have != root {
+		t.Errorf("reverted fork root mismatch: have %x, want %x", have, root)
+	}
+	if have := orig.IntermediateRoot(true); have != root {
+		t.Errorf("orig root mismatch: have %x, want %x", have, root)
+	}
+}
+
 func TestCopyOfCopy(t *testing.T) {
 	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
 	addr := common.HexToAddress("aaaa")
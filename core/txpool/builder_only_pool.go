@@ -0,0 +1,249 @@
+package txpool
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	ErrBuilderOnlyLaneFull    = errors.New("builder-only lane full")
+	ErrBuilderOnlyAccountFull = errors.New("too many builder-only transactions of the sender")
+)
+
+var (
+	builderOnlyGauge         = metrics.NewRegisteredGauge("txpool/builderonly", nil)
+	builderOnlyEvictionMeter = metrics.NewRegisteredMeter("txpool/builderonly/eviction", nil) // Evicted by better paying transactions
+	builderOnlyExpiredMeter  = metrics.NewRegisteredMeter("txpool/builderonly/expired", nil)  // Dropped after their max block or lifetime
+)
+
+// builderOnlyTx is a transaction of the builder-only lane.
+type builderOnlyTx struct {
+	tx       *types.Transaction
+	from     common.Address
+	added    time.Time
+	maxBlock uint64 // Last block the transaction may be included in, 0 if unbounded
+	prefs    types.PrivateTxPreferences
+}
+
+// BuilderOnlyPool is the lane of the transactions received through private channels which must
+// never be shared with the peers. They are kept out of the pending and queued pools, which are
+// announced and served to the peers, and are only included by the builder, at the top of its
+// blocks. The lane has its own limits: when full, the transaction paying the lowest tip among
+// the last transactions of their senders is evicted for a better paying one.
+type BuilderOnlyPool struct {
+	mu sync.Mutex
+
+	slots        uint64        // Maximum number of transactions in the lane
+	accountSlots uint64        // Maximum number of transactions of a sender in the lane
+	lifetime     time.Duration // Maximum amount of time a transaction is kept in the lane
+	priceBump    uint64        // Minimum price bump percentage to replace a transaction of the same nonce
+
+	txs map[common.Address][]*builderOnlyTx // Transactions of every sender, sorted by nonce
+	all map[common.Hash]*builderOnlyTx
+}
+
+func NewBuilderOnlyPool(config Config) *BuilderOnlyPool {
+	return &BuilderOnlyPool{
+		slots:        config.BuilderOnlySlots,
+		accountSlots: config.BuilderOnlyAccountSlots,
+		lifetime:     config.BuilderOnlyLifetime,
+		priceBump:    config.PriceBump,
+		txs:          make(map[common.Address][]*builderOnlyTx),
+		all:          make(map[common.Hash]*builderOnlyTx),
+	}
+}
+
+// Add stores a transaction validated against the head state, replacing the transaction of the
+// sender with the same nonce if it pays enough more.
+func (p *BuilderOnlyPool) Add(tx *types.Transaction, from common.Address, maxBlockNumber uint64, prefs types.PrivateTxPreferences) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.all[tx.Hash()]; ok {
+		return ErrAlreadyKnown
+	}
+	txs := p.txs[from]
+	i := sort.Search(len(txs), func(i int) bool { return txs[i].tx.Nonce() >= tx.Nonce() })
+	if i < len(txs) && txs[i].tx.Nonce() == tx.Nonce() {
+		if !p.replaces(tx, txs[i].tx) {
+			return ErrReplaceUnderpriced
+		}
+		delete(p.all, txs[i].tx.Hash())
+		txs[i] = &builderOnlyTx{tx: tx, from: from, added: time.Now(), maxBlock: maxBlockNumber, prefs: prefs}
+		p.all[tx.Hash()] = txs[i]
+		return nil
+	}
+	if uint64(len(txs)) >= p.accountSlots {
+		return ErrBuilderOnlyAccountFull
+	}
+	if uint64(len(p.all)) >= p.slots && !p.evictFor(tx) {
+		return ErrBuilderOnlyLaneFull
+	}
+
+	// the eviction may have dropped the last transaction of the sender
+	entry := &builderOnlyTx{tx: tx, from: from, added: time.Now(), maxBlock: maxBlockNumber, prefs: prefs}
+	txs = p.txs[from]
+	i = sort.Search(len(txs), func(i int) bool { return txs[i].tx.Nonce() >= tx.Nonce() })
+	txs = append(txs, nil)
+	copy(txs[i+1:], txs[i:])
+	txs[i] = entry
+	p.txs[from] = txs
+	p.all[tx.Hash()] = entry
+	builderOnlyGauge.Update(int64(len(p.all)))
+	return nil
+}
+
+// replaces reports whether tx pays the price bump over old, like a replacement in the pending pool.
+func (p *BuilderOnlyPool) replaces(tx, old *types.Transaction) bool {
+	bump := big.NewInt(100 + int64(p.priceBump))
+	thresholdFeeCap := new(big.Int).Div(new(big.Int).Mul(bump, old.GasFeeCap()), big.NewInt(100))
+	thresholdTip := new(big.Int).Div(new(big.Int).Mul(bump, old.GasTipCap()), big.NewInt(100))
+	return old.GasFeeCapCmp(tx) < 0 && old.GasTipCapCmp(tx) < 0 &&
+		tx.GasFeeCapIntCmp(thresholdFeeCap) >= 0 && tx.GasTipCapIntCmp(thresholdTip) >= 0
+}
+
+// evictFor evicts the transaction paying the lowest tip among the last transactions of their
+// senders, so that the nonces of the senders stay sequential, if tx pays a higher tip. The
+// caller must hold p.mu.
+func (p *BuilderOnlyPool) evictFor(tx *types.Transaction) bool {
+	var cheapest *builderOnlyTx
+	for _, txs := range p.txs {
+		last := txs[len(txs)-1]
+		if cheapest == nil || last.tx.GasTipCapCmp(cheapest.tx) < 0 {
+			cheapest = last
+		}
+	}
+	if cheapest == nil || cheapest.tx.GasTipCapCmp(tx) >= 0 {
+		return false
+	}
+	log.Trace("Evicting builder-only transaction", "hash", cheapest.tx.Hash(), "tip", cheapest.tx.GasTipCap())
+	p.remove(cheapest)
+	builderOnlyEvictionMeter.Mark(1)
+	return true
+}
+
+// remove drops a transaction from the lane. The caller must hold p.mu.
+func (p *BuilderOnlyPool) remove(entry *builderOnlyTx) {
+	delete(p.all, entry.tx.Hash())
+	txs := p.txs[entry.from]
+	for i := range txs {
+		if txs[i] == entry {
+			txs = append(txs[:i], txs[i+1:]...)
+			break
+		}
+	}
+	if len(txs) == 0 {
+		delete(p.txs, entry.from)
+	} else {
+		p.txs[entry.from] = txs
+	}
+	builderOnlyGauge.Update(int64(len(p.all)))
+}
+
+// Remove drops a transaction of the sender from the lane, it returns whether the transaction was
+// in the lane. The transactions of other senders are kept.
+func (p *BuilderOnlyPool) Remove(hash common.Hash, from common.Address) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.all[hash]
+	if !ok || entry.from != from {
+		return false
+	}
+	p.remove(entry)
+	return true
+}
+
+// Contains reports whether the transaction is in the lane.
+func (p *BuilderOnlyPool) Contains(hash common.Hash) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, ok := p.all[hash]
+	return ok
+}
+
+// Added returns when the transaction was added to the lane, false if it is not in the lane.
+func (p *BuilderOnlyPool) Added(hash common.Hash) (time.Time, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.all[hash]
+	if !ok {
+		return time.Time{}, false
+	}
+	return entry.added, true
+}
+
+// Preferences returns the preferences of the sender of the transaction on the bundles including
+// it, false if the transaction is not in the lane or has no preference.
+func (p *BuilderOnlyPool) Preferences(hash common.Hash) (types.PrivateTxPreferences, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.all[hash]
+	if !ok || entry.prefs == (types.PrivateTxPreferences{}) {
+		return types.PrivateTxPreferences{}, false
+	}
+	return entry.prefs, true
+}
+
+// Reset drops the transactions which can no longer be included after the head: those below the
+// nonce of their sender in the head state, included or replaced, those whose max block is the
+// head or an earlier block and those older than the lifetime of the lane.
+func (p *BuilderOnlyPool) Reset(head uint64, nonceAt func(common.Address) uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var (
+		now            = time.Now()
+		stale, expired []*builderOnlyTx
+	)
+	for from, txs := range p.txs {
+		nonce := nonceAt(from)
+		for _, entry := range txs {
+			switch {
+			case entry.tx.Nonce() < nonce:
+				stale = append(stale, entry)
+			case (entry.maxBlock != 0 && head >= entry.maxBlock) || now.Sub(entry.added) > p.lifetime:
+				expired = append(expired, entry)
+			}
+		}
+	}
+	for _, entry := range stale {
+		p.remove(entry)
+	}
+	for _, entry := range expired {
+		log.Trace("Dropping expired builder-only transaction", "hash", entry.tx.Hash(), "maxBlock", entry.maxBlock)
+		p.remove(entry)
+	}
+	builderOnlyExpiredMeter.Mark(int64(len(expired)))
+}
+
+// Pending returns the transactions executable on top of the head state, grouped by sender and
+// sorted by nonce: the transactions following the nonce of their sender without gap.
+func (p *BuilderOnlyPool) Pending(nonceAt func(common.Address) uint64) map[common.Address]types.Transactions {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pending := make(map[common.Address]types.Transactions)
+	for from, txs := range p.txs {
+		nonce := nonceAt(from)
+		for _, entry := range txs {
+			if entry.tx.Nonce() != nonce {
+				break
+			}
+			pending[from] = append(pending[from], entry.tx)
+			nonce++
+		}
+	}
+	return pending
+}
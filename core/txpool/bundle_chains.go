@@ -0,0 +1,164 @@
+package txpool
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var chainedBundlesMeter = metrics.NewRegisteredMeter("txpool/bundles/chained", nil)
+
+// bundleNonces are the first and last nonces of every sender of a bundle.
+type bundleNonces struct {
+	first map[common.Address]uint64
+	last  map[common.Address]uint64
+}
+
+func newBundleNonces(bundle *types.MevBundle, signer types.Signer) *bundleNonces {
+	nonces := &bundleNonces{first: make(map[common.Address]uint64), last: make(map[common.Address]uint64)}
+	for _, tx := range bundle.Txs {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil
+		}
+		if first, ok := nonces.first[from]; !ok || tx.Nonce() < first {
+			nonces.first[from] = tx.Nonce()
+		}
+		if last, ok := nonces.last[from]; !ok || tx.Nonce() > last {
+			nonces.last[from] = tx.Nonce()
+		}
+	}
+	return nonces
+}
+
+// continues reports whether the bundle with the nonces n can only execute after the bundle with
+// the nonces prev: one of its senders starts at the nonce following the last nonce of the sender
+// in prev, ahead of the nonce of the sender in the state.
+func (n *bundleNonces) continues(prev *bundleNonces, nonceAt func(common.Address) uint64) bool {
+	for from, first := range n.first {
+		if last, ok := prev.last[from]; ok && last+1 == first && first > nonceAt(from) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChainMevBundles merges the bundles of a signer that continue the nonces of the transactions of
+// another bundle of the signer into a single bundle, the bundles in nonce order. Simulated at the
+// top of the block, a bundle continuing another one fails on its nonce gap, and once merged into
+// the block after the bundle it continues, the bundles it conflicts with fail late in the build.
+// The merged bundle is included with all its bundles, in order, or not at all. Nonces are read
+// with nonceAt from the state the block is built on. Only the bundles of authenticated signers
+// are chained, and the bundles with a uuid are left to be replaced and cancelled by their uuid.
+func ChainMevBundles(bundles []types.MevBundle, signer types.Signer, nonceAt func(common.Address) uint64, blockNumber *big.Int) []types.MevBundle {
+	if len(bundles) < 2 {
+		return bundles
+	}
+	var (
+		nonces   = make([]*bundleNonces, len(bundles))
+		bySigner = make(map[common.Address][]int)
+	)
+	for i := range bundles {
+		bundle := &bundles[i]
+		if bundle.SigningAddress == (common.Address{}) || bundle.Uuid != types.EmptyUUID {
+			continue
+		}
+		if nonces[i] = newBundleNonces(bundle, signer); nonces[i] != nil {
+			bySigner[bundle.SigningAddress] = append(bySigner[bundle.SigningAddress], i)
+		}
+	}
+
+	// link every bundle to the first bundle of its signer it continues, each bundle is continued
+	// by at most one bundle
+	var (
+		next    = make(map[int]int)
+		chained = make(map[int]bool)
+	)
+	for i := range bundles {
+		if nonces[i] == nil {
+			continue
+		}
+		for _, j := range bySigner[bundles[i].SigningAddress] {
+			if i == j {
+				continue
+			}
+			if _, ok := next[j]; ok {
+				continue
+			}
+			if nonces[i].continues(nonces[j], nonceAt) {
+				next[j] = i
+				chained[i] = true
+				break
+			}
+		}
+	}
+	if len(next) == 0 {
+		return bundles
+	}
+
+	// merge the chains at the position of their first bundle, the bundles of nonce cycles are
+	// left as they are
+	var (
+		ret     = make([]types.MevBundle, 0, len(bundles))
+		visited = make(map[int]bool)
+	)
+	for i := range bundles {
+		if chained[i] {
+			continue
+		}
+		visited[i] = true
+		if _, ok := next[i]; !ok {
+			ret = append(ret, bundles[i])
+			continue
+		}
+		chain := []int{i}
+		for j, ok := next[i]; ok && !visited[j]; j, ok = next[j] {
+			visited[j] = true
+			chain = append(chain, j)
+		}
+		ret = append(ret, mergeBundleChain(bundles, chain, blockNumber))
+	}
+	for i := range bundles {
+		if !visited[i] {
+			ret = append(ret, bundles[i])
+		}
+	}
+	return ret
+}
+
+// mergeBundleChain merges the bundles of a chain, in order, into a single bundle for the block. The
+// merged bundle is valid for the blocks all its bundles are valid for, and keeps their hashes in
+// Chain, which the pool and the trackers of the bundles resolve it with.
+func mergeBundleChain(bundles []types.MevBundle, chain []int, blockNumber *big.Int) types.MevBundle {
+	merged := types.MevBundle{
+		BlockNumber:    new(big.Int).Set(blockNumber),
+		SigningAddress: bundles[chain[0]].SigningAddress,
+	}
+	lastBlock := bundles[chain[0]].LastBlockNumber()
+	for _, i := range chain {
+		bundle := &bundles[i]
+		if bundle.LastBlockNumber().Cmp(lastBlock) < 0 {
+			lastBlock = bundle.LastBlockNumber()
+		}
+		merged.Txs = append(merged.Txs, bundle.Txs...)
+		merged.RevertingTxHashes = append(merged.RevertingTxHashes, bundle.RevertingTxHashes...)
+		merged.Chain = append(merged.Chain, bundle.Hash)
+		if bundle.MinTimestamp > merged.MinTimestamp {
+			merged.MinTimestamp = bundle.MinTimestamp
+		}
+		if bundle.MaxTimestamp != 0 && (merged.MaxTimestamp == 0 || bundle.MaxTimestamp < merged.MaxTimestamp) {
+			merged.MaxTimestamp = bundle.MaxTimestamp
+		}
+	}
+	if lastBlock.Cmp(blockNumber) > 0 {
+		merged.MaxBlockNumber = new(big.Int).Set(lastBlock)
+	}
+	merged.Hash = MevBundleHash(merged.Txs)
+
+	chainedBundlesMeter.Mark(int64(len(chain)))
+	log.Debug("Chained bundles by nonce", "signer", merged.SigningAddress, "bundles", merged.Chain, "hash", merged.Hash)
+	return merged
+}
@@ -0,0 +1,242 @@
+package txpool
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// maxEncryptedBundleSize caps the ciphertext of a single encrypted bundle
+	maxEncryptedBundleSize = 32 * txMaxSize
+
+	// maxEncryptedBundles caps the number of encrypted bundles in the pool
+	maxEncryptedBundles = 4096
+
+	// maxEncryptedBundlesSize caps the total ciphertext size of the encrypted bundles in the pool
+	maxEncryptedBundlesSize = 256 * 1024 * 1024
+)
+
+var (
+	ErrBundleEncryptionDisabled = errors.New("encrypted bundles are not enabled")
+	ErrEncryptedBundleTooLarge  = errors.New("encrypted bundle too large")
+	ErrEncryptedBundleEmpty     = errors.New("encrypted bundle is empty")
+	ErrEncryptedBundleUnsigned  = errors.New("encrypted bundle submissions must be signed")
+	ErrEncryptedBundlePoolFull  = errors.New("encrypted bundle pool full")
+)
+
+// EncryptedBundlePool keeps bundles encrypted to the builder's bundle encryption key.
+// Bundles are stored as ciphertext and are only decrypted, in memory, the first time they are
+// handed out for the block they target. The decrypted bundle is kept with its ciphertext for
+// the next build rounds of the block, until the bundle is pruned.
+//
+// As their contents can't be checked before they are decrypted, encrypted bundles are only
+// accepted from authenticated signers, and the pool is capped in number of bundles and in
+// ciphertext size.
+type EncryptedBundlePool struct {
+	mu sync.Mutex
+
+	key     *ecies.PrivateKey
+	bundles []*encryptedBundle
+	size    int // Total ciphertext size of the bundles
+}
+
+// encryptedBundle is an encrypted bundle of the pool, decrypted at most once.
+type encryptedBundle struct {
+	types.EncryptedMevBundle
+
+	once      sync.Once
+	decrypted types.MevBundle
+	err       error
+}
+
+func NewEncryptedBundlePool() *EncryptedBundlePool {
+	return &EncryptedBundlePool{}
+}
+
+// SetKey sets the key used to decrypt bundles. A nil key disables encrypted bundles.
+func (p *EncryptedBundlePool) SetKey(key *ecies.PrivateKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.key = key
+}
+
+// PublicKey returns the key searchers should encrypt bundles to, or nil if encrypted bundles are disabled.
+func (p *EncryptedBundlePool) PublicKey() *ecies.PublicKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.key == nil {
+		return nil
+	}
+	return &p.key.PublicKey
+}
+
+// Add stores an encrypted bundle without decrypting it. The signing address of the bundle must
+// be the authenticated signer of the submission, which may have at most signerLimit bundles in
+// the pool, 0 = unlimited.
+func (p *EncryptedBundlePool) Add(bundle types.EncryptedMevBundle, signerLimit int64) error {
+	if len(bundle.Ciphertext) == 0 {
+		return ErrEncryptedBundleEmpty
+	}
+	if len(bundle.Ciphertext) > maxEncryptedBundleSize {
+		return ErrEncryptedBundleTooLarge
+	}
+	if bundle.SigningAddress == (common.Address{}) {
+		return ErrEncryptedBundleUnsigned
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.key == nil {
+		return ErrBundleEncryptionDisabled
+	}
+	if len(p.bundles) >= maxEncryptedBundles || p.size+len(bundle.Ciphertext) > maxEncryptedBundlesSize {
+		return ErrEncryptedBundlePoolFull
+	}
+	if signerLimit > 0 {
+		var count int64
+		for _, b := range p.bundles {
+			if b.SigningAddress == bundle.SigningAddress {
+				count++
+			}
+		}
+		if count >= signerLimit {
+			return ErrTooManySignerBundles
+		}
+	}
+	p.bundles = append(p.bundles, &encryptedBundle{EncryptedMevBundle: bundle})
+	p.size += len(bundle.Ciphertext)
+	return nil
+}
+
+// Prune drops the bundles which can no longer be included after the head: those targeting the
+// head or an earlier block and those whose max timestamp is reached.
+func (p *EncryptedBundlePool) Prune(head *types.Header) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.retain(func(bundle *encryptedBundle) bool {
+		return head.Number.Cmp(bundle.BlockNumber) < 0 && (bundle.MaxTimestamp == 0 || bundle.MaxTimestamp > head.Time)
+	})
+}
+
+// retain keeps the bundles for which keep returns true. The caller must hold p.mu.
+func (p *EncryptedBundlePool) retain(keep func(bundle *encryptedBundle) bool) {
+	bundles := p.bundles[:0]
+	p.size = 0
+	for _, bundle := range p.bundles {
+		if keep(bundle) {
+			bundles = append(bundles, bundle)
+			p.size += len(bundle.Ciphertext)
+		}
+	}
+	for i := len(bundles); i < len(p.bundles); i++ {
+		p.bundles[i] = nil
+	}
+	p.bundles = bundles
+}
+
+// Bundles returns the bundles valid for the given blockNumber/blockTimestamp, pruning outdated
+// bundles. The bundles are decrypted the first time they are returned, outside the lock of the
+// pool, and the bundles that fail to decrypt are dropped.
+func (p *EncryptedBundlePool) Bundles(blockNumber *big.Int, blockTimestamp uint64) []types.MevBundle {
+	p.mu.Lock()
+	// Prune outdated bundles
+	p.retain(func(bundle *encryptedBundle) bool {
+		return (bundle.MaxTimestamp == 0 || blockTimestamp <= bundle.MaxTimestamp) && blockNumber.Cmp(bundle.BlockNumber) <= 0
+	})
+	var (
+		key      = p.key
+		eligible []*encryptedBundle
+	)
+	for _, bundle := range p.bundles {
+		// Roll over future bundles
+		if (bundle.MinTimestamp != 0 && blockTimestamp < bundle.MinTimestamp) || blockNumber.Cmp(bundle.BlockNumber) < 0 {
+			continue
+		}
+		eligible = append(eligible, bundle)
+	}
+	p.mu.Unlock()
+
+	if key == nil {
+		return nil
+	}
+	var (
+		ret    []types.MevBundle
+		failed = make(map[*encryptedBundle]bool)
+	)
+	for _, bundle := range eligible {
+		bundle.once.Do(func() {
+			bundle.decrypted, bundle.err = decryptMevBundle(key, &bundle.EncryptedMevBundle)
+		})
+		if bundle.err != nil {
+			// only the ciphertext hash is logged so bundle contents never leak
+			log.Debug("Dropping undecryptable bundle", "hash", bundle.Hash(), "err", bundle.err)
+			failed[bundle] = true
+			continue
+		}
+		ret = append(ret, bundle.decrypted)
+	}
+	if len(failed) > 0 {
+		p.mu.Lock()
+		p.retain(func(bundle *encryptedBundle) bool { return !failed[bundle] })
+		p.mu.Unlock()
+	}
+	return ret
+}
+
+// decryptMevBundle opens the ciphertext of an encrypted bundle and decodes the contained transactions.
+func decryptMevBundle(key *ecies.PrivateKey, bundle *types.EncryptedMevBundle) (types.MevBundle, error) {
+	plaintext, err := key.Decrypt(bundle.Ciphertext, nil, nil)
+	if err != nil {
+		return types.MevBundle{}, err
+	}
+
+	var body types.EncryptedMevBundleBody
+	if err := json.Unmarshal(plaintext, &body); err != nil {
+		return types.MevBundle{}, fmt.Errorf("invalid bundle body: %w", err)
+	}
+	if len(body.Txs) == 0 {
+		return types.MevBundle{}, errors.New("bundle missing txs")
+	}
+
+	txs := make(types.Transactions, 0, len(body.Txs))
+	for _, encodedTx := range body.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(encodedTx); err != nil {
+			return types.MevBundle{}, err
+		}
+		txs = append(txs, tx)
+	}
+
+	return types.MevBundle{
+		Txs:               txs,
+		BlockNumber:       bundle.BlockNumber,
+		SigningAddress:    bundle.SigningAddress,
+		MinTimestamp:      bundle.MinTimestamp,
+		MaxTimestamp:      bundle.MaxTimestamp,
+		RevertingTxHashes: body.RevertingTxHashes,
+		Hash:              MevBundleHash(txs),
+	}, nil
+}
+
+// EncryptMevBundle seals a bundle body to the given public key. It is the inverse of the
+// decryption done by the pool and is provided for searcher tooling and tests.
+func EncryptMevBundle(pub *ecies.PublicKey, body *types.EncryptedMevBundleBody) ([]byte, error) {
+	plaintext, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return ecies.Encrypt(rand.Reader, pub, plaintext, nil, nil)
+}
@@ -0,0 +1,140 @@
+package txpool
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+func encryptedTestBundle(t *testing.T, pub *ecies.PublicKey, blockNumber int64, txs types.Transactions) types.EncryptedMevBundle {
+	t.Helper()
+
+	body := &types.EncryptedMevBundleBody{}
+	for _, tx := range txs {
+		encoded, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to encode tx: %v", err)
+		}
+		body.Txs = append(body.Txs, hexutil.Bytes(encoded))
+	}
+	ciphertext, err := EncryptMevBundle(pub, body)
+	if err != nil {
+		t.Fatalf("failed to encrypt bundle: %v", err)
+	}
+	return types.EncryptedMevBundle{BlockNumber: big.NewInt(blockNumber), Ciphertext: ciphertext, SigningAddress: common.Address{0x1}}
+}
+
+func TestEncryptedBundlePool(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	bundleKey := ecies.ImportECDSA(key)
+	txKey, _ := crypto.GenerateKey()
+	txs := types.Transactions{transaction(0, 21000, txKey), transaction(1, 21000, txKey)}
+
+	pool := NewEncryptedBundlePool()
+	if err := pool.Add(encryptedTestBundle(t, &bundleKey.PublicKey, 10, txs), 0); err != ErrBundleEncryptionDisabled {
+		t.Fatalf("expected %v, got %v", ErrBundleEncryptionDisabled, err)
+	}
+
+	pool.SetKey(bundleKey)
+	if pool.PublicKey() == nil {
+		t.Fatal("missing public key")
+	}
+	if err := pool.Add(types.EncryptedMevBundle{BlockNumber: big.NewInt(10)}, 0); err != ErrEncryptedBundleEmpty {
+		t.Fatalf("expected %v, got %v", ErrEncryptedBundleEmpty, err)
+	}
+	if err := pool.Add(encryptedTestBundle(t, &bundleKey.PublicKey, 10, txs), 0); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+
+	// bundle encrypted to a different key is dropped on decryption
+	otherKey, _ := ecies.GenerateKey(rand.Reader, crypto.S256(), nil)
+	if err := pool.Add(encryptedTestBundle(t, &otherKey.PublicKey, 10, txs), 0); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+
+	if bundles := pool.Bundles(big.NewInt(9), 0); len(bundles) != 0 {
+		t.Fatalf("future bundle returned: %d", len(bundles))
+	}
+
+	bundles := pool.Bundles(big.NewInt(10), 0)
+	if len(bundles) != 1 {
+		t.Fatalf("expected 1 decrypted bundle, got %d", len(bundles))
+	}
+	if len(bundles[0].Txs) != len(txs) {
+		t.Fatalf("expected %d txs, got %d", len(txs), len(bundles[0].Txs))
+	}
+	for i, tx := range bundles[0].Txs {
+		if tx.Hash() != txs[i].Hash() {
+			t.Errorf("tx %d mismatch: have %s, want %s", i, tx.Hash(), txs[i].Hash())
+		}
+	}
+	if bundles[0].Hash != MevBundleHash(txs) || bundles[0].Hash == (common.Hash{}) {
+		t.Errorf("unexpected bundle hash %s", bundles[0].Hash)
+	}
+	if len(pool.bundles) != 1 {
+		t.Fatalf("undecryptable bundle not dropped: %d bundles", len(pool.bundles))
+	}
+
+	// the bundles are decrypted once, the next rounds of the block reuse the decrypted bundle
+	pool.SetKey(otherKey)
+	if bundles := pool.Bundles(big.NewInt(10), 0); len(bundles) != 1 || bundles[0].Hash != MevBundleHash(txs) {
+		t.Fatalf("decrypted bundle not reused: %d", len(bundles))
+	}
+
+	if bundles := pool.Bundles(big.NewInt(11), 0); len(bundles) != 0 {
+		t.Fatalf("outdated bundle returned: %d", len(bundles))
+	}
+	if len(pool.bundles) != 0 || pool.size != 0 {
+		t.Fatalf("outdated bundles not pruned: %d, %d bytes", len(pool.bundles), pool.size)
+	}
+}
+
+func TestEncryptedBundlePoolLimits(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	pool := NewEncryptedBundlePool()
+	pool.SetKey(ecies.ImportECDSA(key))
+
+	bundle := func(signer byte, blockNumber int64) types.EncryptedMevBundle {
+		return types.EncryptedMevBundle{BlockNumber: big.NewInt(blockNumber), Ciphertext: []byte{0x1}, SigningAddress: common.Address{signer}}
+	}
+
+	// unsigned bundles are rejected
+	if err := pool.Add(types.EncryptedMevBundle{BlockNumber: big.NewInt(10), Ciphertext: []byte{0x1}}, 0); err != ErrEncryptedBundleUnsigned {
+		t.Fatalf("expected %v, got %v", ErrEncryptedBundleUnsigned, err)
+	}
+
+	// a signer has at most the signer limit of bundles
+	for i := 0; i < 2; i++ {
+		if err := pool.Add(bundle(0x1, 10), 2); err != nil {
+			t.Fatalf("failed to add bundle: %v", err)
+		}
+	}
+	if err := pool.Add(bundle(0x1, 10), 2); err != ErrTooManySignerBundles {
+		t.Fatalf("expected %v, got %v", ErrTooManySignerBundles, err)
+	}
+	if err := pool.Add(bundle(0x2, 11), 2); err != nil {
+		t.Fatalf("failed to add bundle: %v", err)
+	}
+
+	// the bundles are pruned on new heads
+	pool.Prune(&types.Header{Number: big.NewInt(10)})
+	if len(pool.bundles) != 1 || pool.size != 1 {
+		t.Fatalf("unexpected pool after prune: %d bundles, %d bytes", len(pool.bundles), pool.size)
+	}
+
+	// the pool is capped
+	for i := len(pool.bundles); i < maxEncryptedBundles; i++ {
+		if err := pool.Add(bundle(0x3, 20), 0); err != nil {
+			t.Fatalf("failed to add bundle %d: %v", i, err)
+		}
+	}
+	if err := pool.Add(bundle(0x4, 20), 0); err != ErrEncryptedBundlePoolFull {
+		t.Fatalf("expected %v, got %v", ErrEncryptedBundlePoolFull, err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -32,7 +33,9 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/redact"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
@@ -93,8 +96,24 @@ var (
 	// ErrOverdraft is returned if a transaction would cause the senders balance to go negative
 	// thus invalidating a potential large number of transactions.
 	ErrOverdraft = errors.New("transaction would cause overdraft")
+
+	// ErrBundleIngestionPaused is returned if a bundle is added while the bundle ingestion is
+	// paused by the operator.
+	ErrBundleIngestionPaused = errors.New("bundle ingestion paused")
+
+	// ErrTooManySignerBundles is returned if a bundle is added while its signer already has
+	// the maximum number of bundles in the pool.
+	ErrTooManySignerBundles = errors.New("too many bundles of the signer in the pool")
+
+	// ErrInvalidBundleRange is returned if a bundle is added with a max block number before its
+	// block number, or valid for more than MaxMevBundleRange blocks after it.
+	ErrInvalidBundleRange = errors.New("invalid bundle block range")
 )
 
+// MaxMevBundleRange is the number of blocks after its block number a mev bundle can be valid
+// for, like an sbundle.
+const MaxMevBundleRange = maxSBundleRange
+
 var (
 	evictionInterval         = time.Minute     // Time interval to check for evictable transactions
 	statsReportInterval      = 8 * time.Second // Time interval to report transaction pool stats
@@ -177,6 +196,10 @@ type Config struct {
 	Lifetime          time.Duration // Maximum amount of time non-executable transaction are queued
 	PrivateTxLifetime time.Duration // Maximum amount of time to keep private transactions private
 
+	BuilderOnlySlots        uint64        // Maximum number of transactions in the builder-only lane
+	BuilderOnlyAccountSlots uint64        // Maximum number of transactions of an account in the builder-only lane
+	BuilderOnlyLifetime     time.Duration // Maximum amount of time transactions are kept in the builder-only lane
+
 	TrustedRelays []common.Address // Trusted relay addresses. Duplicated from the miner config.
 }
 
@@ -196,6 +219,10 @@ var DefaultConfig = Config{
 
 	Lifetime:          3 * time.Hour,
 	PrivateTxLifetime: 3 * 24 * time.Hour,
+
+	BuilderOnlySlots:        1024,
+	BuilderOnlyAccountSlots: 16,
+	BuilderOnlyLifetime:     3 * time.Hour,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -238,6 +265,18 @@ func (config *Config) sanitize() Config {
 		log.Warn("Sanitizing invalid txpool private tx lifetime", "provided", conf.PrivateTxLifetime, "updated", DefaultConfig.PrivateTxLifetime)
 		conf.PrivateTxLifetime = DefaultConfig.PrivateTxLifetime
 	}
+	if conf.BuilderOnlySlots < 1 {
+		log.Warn("Sanitizing invalid txpool builder-only slots", "provided", conf.BuilderOnlySlots, "updated", DefaultConfig.BuilderOnlySlots)
+		conf.BuilderOnlySlots = DefaultConfig.BuilderOnlySlots
+	}
+	if conf.BuilderOnlyAccountSlots < 1 {
+		log.Warn("Sanitizing invalid txpool builder-only account slots", "provided", conf.BuilderOnlyAccountSlots, "updated", DefaultConfig.BuilderOnlyAccountSlots)
+		conf.BuilderOnlyAccountSlots = DefaultConfig.BuilderOnlyAccountSlots
+	}
+	if conf.BuilderOnlyLifetime < 1 {
+		log.Warn("Sanitizing invalid txpool builder-only lifetime", "provided", conf.BuilderOnlyLifetime, "updated", DefaultConfig.BuilderOnlyLifetime)
+		conf.BuilderOnlyLifetime = DefaultConfig.BuilderOnlyLifetime
+	}
 	return conf
 }
 
@@ -254,6 +293,8 @@ type TxPool struct {
 	chain       blockChain
 	gasPrice    *big.Int
 	txFeed      event.Feed
+	bundleFeed  event.Feed
+	missedFeed  event.Feed
 	scope       event.SubscriptionScope
 	signer      types.Signer
 	mu          sync.RWMutex
@@ -289,9 +330,18 @@ type TxPool struct {
 	changesSinceReorg int // A counter for how many drops we've performed in-between reorg.
 
 	privateTxs    *timestampedTxHashSet
+	builderOnly   *BuilderOnlyPool // Private transactions never shared with the peers
 	mevBundles    []types.MevBundle
+	missedBundles []types.MevBundle             // Bundles with a block range missed at the last reset, posted once the pool is unlocked
+	uuidBundles   map[uuidBundleKey]common.Hash // Hash of the last bundle sent by replacement uuid and signer
 	bundleFetcher IFetcher
 	sbundles      *SBundlePool
+
+	encryptedBundles *EncryptedBundlePool
+	userOpBundles    *UserOpPool
+
+	bundlesPaused atomic.Bool  // Bundle ingestion paused by the operator
+	maxSignerMevs atomic.Int64 // Maximum number of mev bundles of a signer in the pool, 0 = unlimited
 }
 
 type txpoolResetRequest struct {
@@ -323,7 +373,12 @@ func NewTxPool(config Config, chainconfig *params.ChainConfig, chain blockChain)
 		initDoneCh:      make(chan struct{}),
 		gasPrice:        new(big.Int).SetUint64(config.PriceLimit),
 		privateTxs:      newExpiringTxHashSet(config.PrivateTxLifetime),
+		builderOnly:     NewBuilderOnlyPool(config),
+		uuidBundles:     make(map[uuidBundleKey]common.Hash),
 		sbundles:        NewSBundlePool(types.LatestSigner(chainconfig)),
+
+		encryptedBundles: NewEncryptedBundlePool(),
+		userOpBundles:    NewUserOpPool(),
 	}
 
 	pool.locals = newAccountSet(pool.signer)
@@ -475,6 +530,18 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- core.NewTxsEvent) event.Subsc
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeNewMevBundlesEvent registers a subscription of NewMevBundlesEvent, sent when mev
+// bundles are added to the pool.
+func (pool *TxPool) SubscribeNewMevBundlesEvent(ch chan<- core.NewMevBundlesEvent) event.Subscription {
+	return pool.scope.Track(pool.bundleFeed.Subscribe(ch))
+}
+
+// SubscribeMissedMevBundlesEvent registers a subscription of MissedMevBundlesEvent, sent when
+// mev bundles with a block range are dropped after missing their range.
+func (pool *TxPool) SubscribeMissedMevBundlesEvent(ch chan<- core.MissedMevBundlesEvent) event.Subscription {
+	return pool.scope.Track(pool.missedFeed.Subscribe(ch))
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -572,7 +639,16 @@ func (pool *TxPool) ContentFrom(addr common.Address) (types.Transactions, types.
 
 // IsPrivateTxHash indicates whether the transaction should be shared with peers
 func (pool *TxPool) IsPrivateTxHash(hash common.Hash) bool {
-	return pool.privateTxs.Contains(hash)
+	return pool.privateTxs.Contains(hash) || pool.builderOnly.Contains(hash)
+}
+
+// PrivateTxTime returns when the private transaction was added to the pool, false if the
+// transaction is not private.
+func (pool *TxPool) PrivateTxTime(hash common.Hash) (time.Time, bool) {
+	if added, ok := pool.builderOnly.Added(hash); ok {
+		return added, true
+	}
+	return pool.privateTxs.Added(hash)
 }
 
 // Pending retrieves all currently processable transactions, grouped by origin
@@ -642,7 +718,7 @@ func resolveCancellableBundles(lubCh chan []types.LatestUuidBundle, errCh chan e
 
 	currentCancellableBundles := []types.MevBundle{}
 
-	log.Trace("Processing uuid bundles", "uuidBundles", uuidBundles)
+	log.Trace("Processing uuid bundles", "uuidBundles", redact.Value(uuidBundles), "count", len(uuidBundles))
 
 	lubs := <-lubCh
 LubLoop:
@@ -680,6 +756,17 @@ LubLoop:
 // also prunes bundles that are outdated
 // Returns regular bundles and a function resolving to current cancellable bundles
 func (pool *TxPool) MevBundles(blockNumber *big.Int, blockTimestamp uint64) ([]types.MevBundle, chan []types.MevBundle) {
+	bundles, cancellableBundlesCh := pool.mevBundlesFor(blockNumber, blockTimestamp)
+
+	// encrypted bundles are decrypted only now, for the block they are about to be simulated in,
+	// outside the pool lock so the decryption does not block the transactions and the reorgs
+	bundles = append(bundles, pool.encryptedBundles.Bundles(blockNumber, blockTimestamp)...)
+	return bundles, cancellableBundlesCh
+}
+
+// mevBundlesFor returns the plaintext bundles valid for the given blockNumber/blockTimestamp and
+// the channel resolving to the cancellable bundles, pruning the outdated bundles.
+func (pool *TxPool) mevBundlesFor(blockNumber *big.Int, blockTimestamp uint64) ([]types.MevBundle, chan []types.MevBundle) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
@@ -692,10 +779,13 @@ func (pool *TxPool) MevBundles(blockNumber *big.Int, blockTimestamp uint64) ([]t
 	var bundles []types.MevBundle
 	// (uuid, signingAddress) -> list of bundles
 	var uuidBundles = make(map[uuidBundleKey][]types.MevBundle)
+	// last bundles sent by uuid, resolved by the pool if there is no fetcher
+	var latestUuidBundles []types.MevBundle
 
 	for _, bundle := range pool.mevBundles {
 		// Prune outdated bundles
-		if (bundle.MaxTimestamp != 0 && blockTimestamp > bundle.MaxTimestamp) || blockNumber.Cmp(bundle.BlockNumber) > 0 {
+		if (bundle.MaxTimestamp != 0 && blockTimestamp > bundle.MaxTimestamp) || blockNumber.Cmp(bundle.LastBlockNumber()) > 0 {
+			pool.unindexUuidBundle(bundle)
 			continue
 		}
 
@@ -714,6 +804,9 @@ func (pool *TxPool) MevBundles(blockNumber *big.Int, blockTimestamp uint64) ([]t
 		if bundle.Uuid != types.EmptyUUID {
 			ubk := uuidBundleKey{bundle.Uuid, bundle.SigningAddress}
 			uuidBundles[ubk] = append(uuidBundles[ubk], bundle)
+			if pool.uuidBundles[ubk] == bundle.Hash {
+				latestUuidBundles = append(latestUuidBundles, bundle)
+			}
 			continue
 		}
 
@@ -724,6 +817,12 @@ func (pool *TxPool) MevBundles(blockNumber *big.Int, blockTimestamp uint64) ([]t
 	pool.mevBundles = bundles
 
 	cancellableBundlesCh := make(chan []types.MevBundle, 1)
+	if pool.bundleFetcher == nil {
+		// without a fetcher the pool resolves the replacements itself, the last bundle sent wins
+		cancellableBundlesCh <- latestUuidBundles
+		cancel()
+		return ret, cancellableBundlesCh
+	}
 	go func() {
 		cancellableBundlesCh <- resolveCancellableBundles(lubCh, errCh, uuidBundles)
 		cancel()
@@ -732,40 +831,231 @@ func (pool *TxPool) MevBundles(blockNumber *big.Int, blockTimestamp uint64) ([]t
 	return ret, cancellableBundlesCh
 }
 
+// SetBundleIngestionPaused pauses or resumes the ingestion of all kinds of bundles, the bundles
+// already in the pool are kept.
+func (pool *TxPool) SetBundleIngestionPaused(paused bool) {
+	pool.bundlesPaused.Store(paused)
+}
+
+// SetMaxBundlesPerSigner caps the number of mev bundles a signer can have in the pool, the
+// bundles without signer are not capped. Zero removes the cap.
+func (pool *TxPool) SetMaxBundlesPerSigner(limit int) {
+	pool.maxSignerMevs.Store(int64(limit))
+}
+
+// BundleIngestionPaused returns whether the bundle ingestion is paused.
+func (pool *TxPool) BundleIngestionPaused() bool {
+	return pool.bundlesPaused.Load()
+}
+
 // AddMevBundles adds a mev bundles to the pool
 func (pool *TxPool) AddMevBundles(mevBundles []types.MevBundle) error {
+	if pool.bundlesPaused.Load() {
+		return ErrBundleIngestionPaused
+	}
 	pool.mu.Lock()
-	defer pool.mu.Unlock()
-
 	pool.mevBundles = append(pool.mevBundles, mevBundles...)
+	for _, bundle := range mevBundles {
+		pool.indexUuidBundle(bundle)
+	}
+	pool.mu.Unlock()
+
+	pool.bundleFeed.Send(core.NewMevBundlesEvent{Bundles: mevBundles})
 	return nil
 }
 
-// AddMevBundle adds a mev bundle to the pool
-func (pool *TxPool) AddMevBundle(txs types.Transactions, blockNumber *big.Int, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
-	bundleHasher := sha3.NewLegacyKeccak256()
-	for _, tx := range txs {
-		bundleHasher.Write(tx.Hash().Bytes())
+// AddMevBundle adds a mev bundle to the pool. The bundle is valid for the blocks from blockNumber
+// to maxBlockNumber, only for blockNumber if maxBlockNumber is nil.
+func (pool *TxPool) AddMevBundle(txs types.Transactions, blockNumber, maxBlockNumber *big.Int, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
+	if pool.bundlesPaused.Load() {
+		return ErrBundleIngestionPaused
+	}
+	if maxBlockNumber != nil {
+		if span := new(big.Int).Sub(maxBlockNumber, blockNumber); span.Sign() < 0 || span.Cmp(big.NewInt(MaxMevBundleRange)) > 0 {
+			return ErrInvalidBundleRange
+		}
+	}
+	bundle, err := pool.addMevBundle(txs, blockNumber, maxBlockNumber, replacementUuid, signingAddress, minTimestamp, maxTimestamp, revertingTxHashes)
+	if err != nil {
+		return err
 	}
-	bundleHash := common.BytesToHash(bundleHasher.Sum(nil))
+	pool.bundleFeed.Send(core.NewMevBundlesEvent{Bundles: []types.MevBundle{bundle}})
+	return nil
+}
+
+// addMevBundle adds a mev bundle to the pool and returns it.
+func (pool *TxPool) addMevBundle(txs types.Transactions, blockNumber, maxBlockNumber *big.Int, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp, maxTimestamp uint64, revertingTxHashes []common.Hash) (types.MevBundle, error) {
+	bundleHash := MevBundleHash(txs)
 
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	pool.mevBundles = append(pool.mevBundles, types.MevBundle{
+	if limit := pool.maxSignerMevs.Load(); limit > 0 && signingAddress != (common.Address{}) {
+		var (
+			count    int64
+			replaced = replacementUuid != types.EmptyUUID && pool.bundleFetcher == nil
+		)
+		for _, bundle := range pool.mevBundles {
+			if bundle.SigningAddress == signingAddress && !(replaced && bundle.Uuid == replacementUuid) {
+				count++
+			}
+		}
+		if count >= limit {
+			return types.MevBundle{}, ErrTooManySignerBundles
+		}
+	}
+
+	bundle := types.MevBundle{
 		Txs:               txs,
 		BlockNumber:       blockNumber,
+		MaxBlockNumber:    maxBlockNumber,
 		Uuid:              replacementUuid,
 		SigningAddress:    signingAddress,
 		MinTimestamp:      minTimestamp,
 		MaxTimestamp:      maxTimestamp,
 		RevertingTxHashes: revertingTxHashes,
 		Hash:              bundleHash,
-	})
-	return nil
+	}
+	if replacementUuid != types.EmptyUUID && pool.bundleFetcher == nil {
+		// the bundle replaces the previous ones of the uuid, with a fetcher they are kept for
+		// the fetcher to pick the latest
+		pool.removeUuidBundles(uuidBundleKey{replacementUuid, signingAddress})
+	}
+	pool.mevBundles = append(pool.mevBundles, bundle)
+	pool.indexUuidBundle(bundle)
+	return bundle, nil
+}
+
+// CancelMevBundles removes the bundles sent with the replacement uuid by the signer from the
+// pool, it returns the number of bundles removed.
+func (pool *TxPool) CancelMevBundles(replacementUuid uuid.UUID, signingAddress common.Address) int {
+	if replacementUuid == types.EmptyUUID {
+		return 0
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.removeUuidBundles(uuidBundleKey{replacementUuid, signingAddress})
+}
+
+// removeUuidBundles removes the bundles of the replacement uuid and signer, it returns the number
+// of bundles removed. The caller must hold pool.mu.
+func (pool *TxPool) removeUuidBundles(key uuidBundleKey) int {
+	if _, ok := pool.uuidBundles[key]; !ok {
+		return 0
+	}
+	delete(pool.uuidBundles, key)
+
+	bundles := make([]types.MevBundle, 0, len(pool.mevBundles))
+	for _, bundle := range pool.mevBundles {
+		if bundle.Uuid != key.Uuid || bundle.SigningAddress != key.SigningAddress {
+			bundles = append(bundles, bundle)
+		}
+	}
+	removed := len(pool.mevBundles) - len(bundles)
+	pool.mevBundles = bundles
+	return removed
+}
+
+// indexUuidBundle records the bundle as the last one sent for its replacement uuid, if any. The
+// caller must hold pool.mu.
+func (pool *TxPool) indexUuidBundle(bundle types.MevBundle) {
+	if bundle.Uuid != types.EmptyUUID {
+		pool.uuidBundles[uuidBundleKey{bundle.Uuid, bundle.SigningAddress}] = bundle.Hash
+	}
+}
+
+// unindexUuidBundle drops the bundle removed from the pool from the index, if it is the last one
+// sent for its replacement uuid. The caller must hold pool.mu.
+func (pool *TxPool) unindexUuidBundle(bundle types.MevBundle) {
+	if bundle.Uuid == types.EmptyUUID {
+		return
+	}
+	key := uuidBundleKey{bundle.Uuid, bundle.SigningAddress}
+	if pool.uuidBundles[key] == bundle.Hash {
+		delete(pool.uuidBundles, key)
+	}
+}
+
+// PooledMevBundles returns the mev bundles in the pool, including the future and the cancellable
+// ones. Encrypted bundles are not included as they are only decrypted for the block they target.
+func (pool *TxPool) PooledMevBundles() []types.MevBundle {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	bundles := make([]types.MevBundle, len(pool.mevBundles))
+	copy(bundles, pool.mevBundles)
+	return bundles
+}
+
+// EvictMevBundles removes the mev bundles with the given hashes from the pool, it returns the
+// number of bundles removed.
+func (pool *TxPool) EvictMevBundles(hashes []common.Hash) int {
+	evict := make(map[common.Hash]struct{}, len(hashes))
+	for _, hash := range hashes {
+		evict[hash] = struct{}{}
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	bundles := make([]types.MevBundle, 0, len(pool.mevBundles))
+	for _, bundle := range pool.mevBundles {
+		if _, ok := evict[bundle.Hash]; !ok {
+			bundles = append(bundles, bundle)
+		} else {
+			pool.unindexUuidBundle(bundle)
+		}
+	}
+	evicted := len(pool.mevBundles) - len(bundles)
+	pool.mevBundles = bundles
+	return evicted
+}
+
+// MevBundleHash computes the hash of a bundle from the hashes of its transactions
+func MevBundleHash(txs types.Transactions) common.Hash {
+	bundleHasher := sha3.NewLegacyKeccak256()
+	for _, tx := range txs {
+		bundleHasher.Write(tx.Hash().Bytes())
+	}
+	return common.BytesToHash(bundleHasher.Sum(nil))
+}
+
+// SetBundleEncryptionKey enables encrypted bundle submission, decrypting bundles with the given key.
+func (pool *TxPool) SetBundleEncryptionKey(key *ecies.PrivateKey) {
+	pool.encryptedBundles.SetKey(key)
+}
+
+// BundleEncryptionKey returns the public key bundles should be encrypted to, or nil if disabled.
+func (pool *TxPool) BundleEncryptionKey() *ecies.PublicKey {
+	return pool.encryptedBundles.PublicKey()
+}
+
+// AddEncryptedMevBundle adds an encrypted mev bundle to the pool
+func (pool *TxPool) AddEncryptedMevBundle(bundle types.EncryptedMevBundle) error {
+	if pool.bundlesPaused.Load() {
+		return ErrBundleIngestionPaused
+	}
+	return pool.encryptedBundles.Add(bundle, pool.maxSignerMevs.Load())
+}
+
+// AddUserOpBundle adds an ERC-4337 user operation bundle to the pool
+func (pool *TxPool) AddUserOpBundle(bundle types.UserOperationBundle) error {
+	if pool.bundlesPaused.Load() {
+		return ErrBundleIngestionPaused
+	}
+	return pool.userOpBundles.Add(bundle)
+}
+
+// UserOpBundles returns the user operation bundles valid for the given blockNumber/blockTimestamp
+func (pool *TxPool) UserOpBundles(blockNumber *big.Int, blockTimestamp uint64) []types.UserOperationBundle {
+	return pool.userOpBundles.Bundles(blockNumber, blockTimestamp)
 }
 
 func (pool *TxPool) AddSBundle(bundle *types.SBundle) error {
+	if pool.bundlesPaused.Load() {
+		return ErrBundleIngestionPaused
+	}
 	return pool.sbundles.Add(bundle)
 }
 
@@ -1150,6 +1440,62 @@ func (pool *TxPool) AddPrivateRemote(tx *types.Transaction) error {
 	return errs[0]
 }
 
+// AddBuilderOnlyTx adds a transaction to the builder-only lane, which is never shared with the
+// peers and only included by the builder. The transaction is dropped from the lane once the chain
+// reaches maxBlockNumber without including it, a zero maxBlockNumber does not bound the inclusion.
+// The preferences of its sender are enforced on the bundles including it.
+func (pool *TxPool) AddBuilderOnlyTx(tx *types.Transaction, maxBlockNumber uint64, prefs types.PrivateTxPreferences) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if err := pool.validateTx(tx, false); err != nil {
+		return err
+	}
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	return pool.builderOnly.Add(tx, from, maxBlockNumber, prefs)
+}
+
+// PrivateTxPreferences returns the preferences of the sender of a transaction of the builder-only
+// lane on the bundles including it, false if it has none.
+func (pool *TxPool) PrivateTxPreferences(hash common.Hash) (types.PrivateTxPreferences, bool) {
+	return pool.builderOnly.Preferences(hash)
+}
+
+// BuilderOnlyPending returns the transactions of the builder-only lane executable on top of the
+// head, grouped by sender and sorted by nonce.
+func (pool *TxPool) BuilderOnlyPending() map[common.Address]types.Transactions {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	return pool.builderOnly.Pending(pool.currentState.GetNonce)
+}
+
+// CancelPrivateTx removes a private transaction of the sender from the builder-only lane or from
+// the pool, it returns whether the transaction was removed. Transactions which are not private may
+// already have been shared with the peers, they can not be cancelled, nor can the transactions of
+// other senders.
+func (pool *TxPool) CancelPrivateTx(hash common.Hash, from common.Address) bool {
+	if pool.builderOnly.Remove(hash, from) {
+		return true
+	}
+	if !pool.privateTxs.Contains(hash) {
+		return false
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	tx := pool.all.Get(hash)
+	if tx == nil {
+		return false
+	}
+	if sender, err := types.Sender(pool.signer, tx); err != nil || sender != from {
+		return false
+	}
+	pool.removeTx(hash, true)
+	pool.privateTxs.Remove(hash)
+	return true
+}
+
 // AddRemotesSync is like AddRemotes, but waits for pool reorganization. Tests use this method.
 func (pool *TxPool) AddRemotesSync(txs []*types.Transaction) []error {
 	return pool.addTxs(txs, false, true, false)
@@ -1490,8 +1836,14 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 
 	dropBetweenReorgHistogram.Update(int64(pool.changesSinceReorg))
 	pool.changesSinceReorg = 0 // Reset change counter
+	missed := pool.missedBundles
+	pool.missedBundles = nil
 	pool.mu.Unlock()
 
+	if len(missed) > 0 {
+		pool.missedFeed.Send(core.MissedMevBundlesEvent{Bundles: missed})
+	}
+
 	// Notify subsystems for newly added transactions
 	for _, tx := range promoted {
 		addr, _ := types.Sender(pool.signer, tx)
@@ -1604,6 +1956,59 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.eip1559 = pool.chainconfig.IsLondon(next)
 	pool.shanghai = pool.chainconfig.IsShanghai(uint64(time.Now().Unix()))
 	pool.sbundles.ResetPoolData(pool)
+	pool.pruneMevBundles(newHead)
+	pool.encryptedBundles.Prune(newHead)
+	pool.builderOnly.Reset(newHead.Number.Uint64(), statedb.GetNonce)
+}
+
+// pruneMevBundles drops the mev bundles which can no longer be included after the head, those
+// whose last block is the head or an earlier block, or with a max timestamp not after the head.
+// The bundles are otherwise only pruned while building, a node not building would keep them
+// forever. The bundles with a block range are also dropped once a transaction of theirs landed
+// in the head, and the ones which missed their range are kept in missedBundles to be posted.
+// The caller must hold pool.mu.
+func (pool *TxPool) pruneMevBundles(head *types.Header) {
+	var landed map[common.Hash]struct{}
+	for _, bundle := range pool.mevBundles {
+		if bundle.MaxBlockNumber == nil {
+			continue
+		}
+		if block := pool.chain.GetBlock(head.Hash(), head.Number.Uint64()); block != nil {
+			landed = make(map[common.Hash]struct{}, len(block.Transactions()))
+			for _, tx := range block.Transactions() {
+				landed[tx.Hash()] = struct{}{}
+			}
+		}
+		break
+	}
+
+	bundles := make([]types.MevBundle, 0, len(pool.mevBundles))
+	for _, bundle := range pool.mevBundles {
+		if head.Number.Cmp(bundle.LastBlockNumber()) >= 0 || (bundle.MaxTimestamp != 0 && bundle.MaxTimestamp <= head.Time) {
+			pool.unindexUuidBundle(bundle)
+			if bundle.MaxBlockNumber != nil && !bundleLanded(bundle, landed) {
+				pool.missedBundles = append(pool.missedBundles, bundle)
+			}
+			continue
+		}
+		if bundle.MaxBlockNumber != nil && bundleLanded(bundle, landed) {
+			log.Debug("Dropped landed bundle with a block range", "hash", bundle.Hash, "number", head.Number)
+			pool.unindexUuidBundle(bundle)
+			continue
+		}
+		bundles = append(bundles, bundle)
+	}
+	pool.mevBundles = bundles
+}
+
+// bundleLanded returns whether a transaction of the bundle is in the landed set.
+func bundleLanded(bundle types.MevBundle, landed map[common.Hash]struct{}) bool {
+	for _, tx := range bundle.Txs {
+		if _, ok := landed[tx.Hash()]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -2150,6 +2555,17 @@ func (s *timestampedTxHashSet) Contains(hash common.Hash) bool {
 	return ok
 }
 
+// Added returns when the hash was added to the set.
+func (s *timestampedTxHashSet) Added(hash common.Hash) (time.Time, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	ts, ok := s.timestamps[hash]
+	if !ok {
+		return time.Time{}, false
+	}
+	return ts.Add(-s.ttl), true
+}
+
 func (s *timestampedTxHashSet) Remove(hash common.Hash) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
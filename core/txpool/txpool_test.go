@@ -2537,6 +2537,46 @@ func TestBundleCancellations(t *testing.T) {
 	require.Equal(t, []types.MevBundle{bundle03_uuid1_signer1, bundle03_uuid1_signer2}, cr.Value)
 }
 
+func TestBundleReplacements(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(100, statedb, new(event.Feed))
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	var (
+		key           = uuid.New()
+		signer1       = common.Address{0x01}
+		signer2       = common.Address{0x02}
+		tx1           = types.NewTransaction(0, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		tx2           = types.NewTransaction(1, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		tx3           = types.NewTransaction(2, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		blockNumber   = big.NewInt(1)
+		latestBundles = func() []types.MevBundle {
+			_, ccBundles := pool.MevBundles(blockNumber, 20)
+			cr := test_utils.RequireChan[[]types.MevBundle](ccBundles, time.Millisecond)
+			require.False(t, cr.Timeout)
+			return cr.Value
+		}
+	)
+	require.NoError(t, pool.AddMevBundle(types.Transactions{tx1}, blockNumber, nil, key, signer1, 0, 0, nil))
+	require.NoError(t, pool.AddMevBundle(types.Transactions{tx2}, blockNumber, nil, key, signer1, 0, 0, nil))
+	require.NoError(t, pool.AddMevBundle(types.Transactions{tx3}, blockNumber, nil, key, signer2, 0, 0, nil))
+
+	// the second bundle of signer1 replaces the first, the uuid of signer2 is distinct
+	bundles := latestBundles()
+	require.Len(t, bundles, 2)
+	require.Equal(t, MevBundleHash(types.Transactions{tx2}), bundles[0].Hash)
+	require.Equal(t, MevBundleHash(types.Transactions{tx3}), bundles[1].Hash)
+	require.Len(t, pool.PooledMevBundles(), 2)
+
+	require.Equal(t, 1, pool.CancelMevBundles(key, signer1))
+	require.Equal(t, 0, pool.CancelMevBundles(key, signer1))
+	bundles = latestBundles()
+	require.Len(t, bundles, 1)
+	require.Equal(t, signer2, bundles[0].SigningAddress)
+}
+
 type mockFetcher struct {
 	errorResps map[int64]error
 	resps      map[int64][]types.LatestUuidBundle
@@ -2694,3 +2734,278 @@ func BenchmarkMultiAccountBatchInsert(b *testing.B) {
 		pool.AddRemotesSync([]*types.Transaction{tx})
 	}
 }
+
+func TestEvictMevBundles(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(100, statedb, new(event.Feed))
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	first := types.MevBundle{BlockNumber: big.NewInt(1), Hash: common.Hash{0xf0}}
+	second := types.MevBundle{BlockNumber: big.NewInt(1), Hash: common.Hash{0xf1}}
+	resubmitted := types.MevBundle{BlockNumber: big.NewInt(2), Hash: common.Hash{0xf0}}
+	require.NoError(t, pool.AddMevBundles([]types.MevBundle{first, second, resubmitted}))
+
+	bundles := pool.PooledMevBundles()
+	require.Equal(t, []types.MevBundle{first, second, resubmitted}, bundles)
+	// the returned bundles are a copy of the pool
+	bundles[0] = second
+	require.Equal(t, first, pool.PooledMevBundles()[0])
+
+	require.Equal(t, 2, pool.EvictMevBundles([]common.Hash{{0xf0}, {0xff}}))
+	require.Equal(t, []types.MevBundle{second}, pool.PooledMevBundles())
+	require.Equal(t, 0, pool.EvictMevBundles([]common.Hash{{0xf0}}))
+}
+
+func TestPruneMevBundles(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(100, statedb, new(event.Feed))
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	mined := types.MevBundle{BlockNumber: big.NewInt(5), Hash: common.Hash{0xf0}}
+	expired := types.MevBundle{BlockNumber: big.NewInt(6), MaxTimestamp: 100, Hash: common.Hash{0xf1}}
+	next := types.MevBundle{BlockNumber: big.NewInt(6), MaxTimestamp: 101, Hash: common.Hash{0xf2}}
+	future := types.MevBundle{BlockNumber: big.NewInt(8), Hash: common.Hash{0xf3}}
+	require.NoError(t, pool.AddMevBundles([]types.MevBundle{mined, expired, next, future}))
+
+	pool.mu.Lock()
+	pool.pruneMevBundles(&types.Header{Number: big.NewInt(5), Time: 100})
+	pool.mu.Unlock()
+	require.Equal(t, []types.MevBundle{next, future}, pool.PooledMevBundles())
+}
+
+func TestMevBundleRange(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(100, statedb, new(event.Feed))
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	require.ErrorIs(t, pool.AddMevBundle(nil, big.NewInt(5), big.NewInt(4), types.EmptyUUID, common.Address{}, 0, 0, nil), ErrInvalidBundleRange)
+	require.ErrorIs(t, pool.AddMevBundle(nil, big.NewInt(5), big.NewInt(6+MaxMevBundleRange), types.EmptyUUID, common.Address{}, 0, 0, nil), ErrInvalidBundleRange)
+
+	ranged := types.MevBundle{BlockNumber: big.NewInt(5), MaxBlockNumber: big.NewInt(7), Hash: common.Hash{0xf0}}
+	single := types.MevBundle{BlockNumber: big.NewInt(5), Hash: common.Hash{0xf1}}
+	require.NoError(t, pool.AddMevBundles([]types.MevBundle{ranged, single}))
+
+	// the bundle with a range is valid for every block of its range
+	bundles, _ := pool.MevBundles(big.NewInt(6), 0)
+	require.Equal(t, []types.MevBundle{ranged}, bundles)
+
+	pool.mu.Lock()
+	pool.pruneMevBundles(&types.Header{Number: big.NewInt(6)})
+	require.Empty(t, pool.missedBundles)
+	pool.mu.Unlock()
+	require.Equal(t, []types.MevBundle{ranged}, pool.PooledMevBundles())
+
+	// and dropped as missed once the head reaches the end of its range
+	pool.mu.Lock()
+	pool.pruneMevBundles(&types.Header{Number: big.NewInt(7)})
+	require.Equal(t, []types.MevBundle{ranged}, pool.missedBundles)
+	pool.mu.Unlock()
+	require.Empty(t, pool.PooledMevBundles())
+}
+
+func TestChainMevBundles(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	var (
+		from     = crypto.PubkeyToAddress(key.PublicKey)
+		searcher = common.Address{0x01}
+		nonceAt  = func(addr common.Address) uint64 {
+			if addr == from {
+				return 1
+			}
+			return 0
+		}
+		bundle = func(signer common.Address, hash byte, nonces ...uint64) types.MevBundle {
+			bundle := types.MevBundle{BlockNumber: big.NewInt(2), SigningAddress: signer, Hash: common.Hash{hash}}
+			for _, nonce := range nonces {
+				bundle.Txs = append(bundle.Txs, transaction(nonce, 21000, key))
+			}
+			return bundle
+		}
+	)
+	first := bundle(searcher, 1, 1, 2)
+	second := bundle(searcher, 2, 3)
+	third := bundle(searcher, 3, 4, 5)
+	alternative := bundle(searcher, 4, 1)
+	other := bundle(common.Address{0x02}, 5, 3)
+	first.MaxBlockNumber, second.MaxBlockNumber, third.MaxBlockNumber = big.NewInt(5), big.NewInt(4), big.NewInt(6)
+
+	// the chain is merged in nonce order at the position of its first bundle
+	bundles := ChainMevBundles([]types.MevBundle{third, alternative, first, other, second}, types.HomesteadSigner{}, nonceAt, big.NewInt(2))
+	require.Len(t, bundles, 3)
+	require.Equal(t, alternative, bundles[0])
+	require.Equal(t, []common.Hash{{1}, {2}, {3}}, bundles[1].Chain)
+	require.Equal(t, big.NewInt(2), bundles[1].BlockNumber)
+	// valid for the blocks all its bundles are valid for
+	require.Equal(t, big.NewInt(4), bundles[1].MaxBlockNumber)
+	require.Equal(t, searcher, bundles[1].SigningAddress)
+	require.Len(t, bundles[1].Txs, 5)
+	for i, tx := range bundles[1].Txs {
+		require.Equal(t, uint64(i+1), tx.Nonce())
+	}
+	require.Equal(t, MevBundleHash(bundles[1].Txs), bundles[1].Hash)
+	// bundles of other signers are not chained
+	require.Equal(t, other, bundles[2])
+
+	// bundles executable at the state nonce are not chained
+	executable := bundle(searcher, 6, 2)
+	require.Equal(t, []types.MevBundle{alternative, executable}, ChainMevBundles([]types.MevBundle{alternative, executable}, types.HomesteadSigner{}, func(common.Address) uint64 { return 2 }, big.NewInt(2)))
+
+	// neither are the bundles of unsigned submitters nor the bundles with a uuid
+	unsigned := []types.MevBundle{bundle(common.Address{}, 7, 1, 2), bundle(common.Address{}, 8, 3)}
+	require.Equal(t, unsigned, ChainMevBundles(unsigned, types.HomesteadSigner{}, nonceAt, big.NewInt(2)))
+	withUuid := []types.MevBundle{bundle(searcher, 9, 1, 2), bundle(searcher, 10, 3)}
+	withUuid[1].Uuid = uuid.New()
+	require.Equal(t, withUuid, ChainMevBundles(withUuid, types.HomesteadSigner{}, nonceAt, big.NewInt(2)))
+}
+
+func TestBundleIngestionPaused(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(100, statedb, new(event.Feed))
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	bundle := types.MevBundle{BlockNumber: big.NewInt(1), Hash: common.Hash{0xf0}}
+	require.NoError(t, pool.AddMevBundles([]types.MevBundle{bundle}))
+
+	pool.SetBundleIngestionPaused(true)
+	require.True(t, pool.BundleIngestionPaused())
+	require.ErrorIs(t, pool.AddMevBundles([]types.MevBundle{bundle}), ErrBundleIngestionPaused)
+	require.ErrorIs(t, pool.AddMevBundle(nil, big.NewInt(1), nil, types.EmptyUUID, common.Address{}, 0, 0, nil), ErrBundleIngestionPaused)
+	require.ErrorIs(t, pool.AddSBundle(&types.SBundle{}), ErrBundleIngestionPaused)
+	// the pooled bundles are kept
+	require.Equal(t, []types.MevBundle{bundle}, pool.PooledMevBundles())
+
+	pool.SetBundleIngestionPaused(false)
+	require.NoError(t, pool.AddMevBundles([]types.MevBundle{bundle}))
+	require.Len(t, pool.PooledMevBundles(), 2)
+}
+
+func TestPrivateTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupPool()
+	defer pool.Stop()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	var (
+		bounded   = transaction(0, 100000, key)
+		unbounded = transaction(1, 100000, key)
+		cancelled = transaction(2, 100000, key)
+		gapped    = transaction(4, 100000, key)
+		public    = transaction(5, 100000, key)
+	)
+	require.NoError(t, pool.AddBuilderOnlyTx(bounded, 5, types.PrivateTxPreferences{NoSandwich: true}))
+	require.NoError(t, pool.AddBuilderOnlyTx(unbounded, 0, types.PrivateTxPreferences{}))
+	require.NoError(t, pool.AddBuilderOnlyTx(cancelled, 0, types.PrivateTxPreferences{}))
+	require.NoError(t, pool.AddBuilderOnlyTx(gapped, 0, types.PrivateTxPreferences{}))
+	require.ErrorIs(t, pool.AddBuilderOnlyTx(bounded, 5, types.PrivateTxPreferences{}), ErrAlreadyKnown)
+	prefs, ok := pool.PrivateTxPreferences(bounded.Hash())
+	require.True(t, ok)
+	require.Equal(t, types.PrivateTxPreferences{NoSandwich: true}, prefs)
+	_, ok = pool.PrivateTxPreferences(unbounded.Hash())
+	require.False(t, ok)
+	require.NoError(t, pool.AddPrivateRemote(public))
+	for _, tx := range []*types.Transaction{bounded, unbounded, cancelled, gapped, public} {
+		require.True(t, pool.IsPrivateTxHash(tx.Hash()))
+	}
+	// the builder-only transactions are kept out of the pool shared with the peers
+	for _, tx := range []*types.Transaction{bounded, unbounded, cancelled, gapped} {
+		require.Nil(t, pool.Get(tx.Hash()))
+	}
+	require.Equal(t, types.Transactions{bounded, unbounded, cancelled}, pool.BuilderOnlyPending()[crypto.PubkeyToAddress(key.PublicKey)])
+
+	// only the sender of a private transaction can cancel it
+	from, thirdParty := crypto.PubkeyToAddress(key.PublicKey), common.Address{0x1}
+	require.False(t, pool.CancelPrivateTx(cancelled.Hash(), thirdParty))
+	require.True(t, pool.IsPrivateTxHash(cancelled.Hash()))
+	require.False(t, pool.CancelPrivateTx(public.Hash(), thirdParty))
+	require.NotNil(t, pool.Get(public.Hash()))
+
+	require.True(t, pool.CancelPrivateTx(cancelled.Hash(), from))
+	require.False(t, pool.CancelPrivateTx(cancelled.Hash(), from))
+	require.False(t, pool.IsPrivateTxHash(cancelled.Hash()))
+	require.True(t, pool.CancelPrivateTx(public.Hash(), from))
+	require.Nil(t, pool.Get(public.Hash()))
+
+	pool.builderOnly.Reset(4, func(common.Address) uint64 { return 0 })
+	require.True(t, pool.IsPrivateTxHash(bounded.Hash()))
+
+	pool.builderOnly.Reset(5, func(common.Address) uint64 { return 0 })
+	require.False(t, pool.IsPrivateTxHash(bounded.Hash()))
+	require.True(t, pool.IsPrivateTxHash(unbounded.Hash()))
+
+	// included transactions are dropped
+	pool.builderOnly.Reset(6, func(common.Address) uint64 { return 2 })
+	require.False(t, pool.IsPrivateTxHash(unbounded.Hash()))
+	require.True(t, pool.IsPrivateTxHash(gapped.Hash()))
+}
+
+func TestBuilderOnlyLaneLimits(t *testing.T) {
+	config := testTxPoolConfig
+	config.BuilderOnlySlots = 3
+	config.BuilderOnlyAccountSlots = 2
+	lane := NewBuilderOnlyPool(config)
+
+	var (
+		key1, _ = crypto.GenerateKey()
+		key2, _ = crypto.GenerateKey()
+		key3, _ = crypto.GenerateKey()
+		from1   = crypto.PubkeyToAddress(key1.PublicKey)
+		from2   = crypto.PubkeyToAddress(key2.PublicKey)
+		from3   = crypto.PubkeyToAddress(key3.PublicKey)
+	)
+	require.NoError(t, lane.Add(pricedTransaction(0, 100000, big.NewInt(10), key1), from1, 0, types.PrivateTxPreferences{}))
+	require.NoError(t, lane.Add(pricedTransaction(1, 100000, big.NewInt(5), key1), from1, 0, types.PrivateTxPreferences{}))
+	require.ErrorIs(t, lane.Add(pricedTransaction(2, 100000, big.NewInt(10), key1), from1, 0, types.PrivateTxPreferences{}), ErrBuilderOnlyAccountFull)
+
+	// replacements must pay the price bump
+	require.ErrorIs(t, lane.Add(pricedTransaction(1, 200000, big.NewInt(5), key1), from1, 0, types.PrivateTxPreferences{}), ErrReplaceUnderpriced)
+	replacement := pricedTransaction(1, 100000, big.NewInt(6), key1)
+	require.NoError(t, lane.Add(replacement, from1, 0, types.PrivateTxPreferences{}))
+
+	require.NoError(t, lane.Add(pricedTransaction(0, 100000, big.NewInt(8), key2), from2, 0, types.PrivateTxPreferences{}))
+
+	// the full lane evicts the last transaction of a sender paying the lowest tip for a better paying one
+	require.ErrorIs(t, lane.Add(pricedTransaction(0, 100000, big.NewInt(6), key3), from3, 0, types.PrivateTxPreferences{}), ErrBuilderOnlyLaneFull)
+	better := pricedTransaction(0, 100000, big.NewInt(7), key3)
+	require.NoError(t, lane.Add(better, from3, 0, types.PrivateTxPreferences{}))
+	require.False(t, lane.Contains(replacement.Hash()))
+	require.True(t, lane.Contains(better.Hash()))
+
+	pending := lane.Pending(func(common.Address) uint64 { return 0 })
+	require.Len(t, pending, 3)
+	require.Len(t, pending[from1], 1)
+}
+
+func TestMaxBundlesPerSigner(t *testing.T) {
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockChain(100, statedb, new(event.Feed))
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+	pool.SetMaxBundlesPerSigner(2)
+
+	var (
+		signer      = common.Address{0x01}
+		key         = uuid.New()
+		blockNumber = big.NewInt(1)
+		txs         = func(nonce uint64) types.Transactions {
+			return types.Transactions{types.NewTransaction(nonce, common.Address{}, big.NewInt(1), 21000, big.NewInt(1), nil)}
+		}
+	)
+	require.NoError(t, pool.AddMevBundle(txs(0), blockNumber, nil, types.EmptyUUID, signer, 0, 0, nil))
+	require.NoError(t, pool.AddMevBundle(txs(1), blockNumber, nil, key, signer, 0, 0, nil))
+	require.ErrorIs(t, pool.AddMevBundle(txs(2), blockNumber, nil, types.EmptyUUID, signer, 0, 0, nil), ErrTooManySignerBundles)
+	// a replacement does not add to the bundles of the signer
+	require.NoError(t, pool.AddMevBundle(txs(3), blockNumber, nil, key, signer, 0, 0, nil))
+	// bundles without signer are not capped
+	require.NoError(t, pool.AddMevBundle(txs(4), blockNumber, nil, types.EmptyUUID, common.Address{}, 0, 0, nil))
+	require.NoError(t, pool.AddMevBundle(txs(5), blockNumber, nil, types.EmptyUUID, common.Address{}, 0, 0, nil))
+}
@@ -0,0 +1,91 @@
+package txpool
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// maxUserOpsPerBundle caps the number of user operations of a single bundle
+	maxUserOpsPerBundle = 64
+
+	// maxUserOpBundles caps the number of user operation bundles kept in the pool
+	maxUserOpBundles = 1024
+)
+
+var (
+	ErrUserOpBundleEmpty    = errors.New("user operation bundle is empty")
+	ErrUserOpBundleTooLarge = errors.New("user operation bundle too large")
+	ErrUserOpPoolFull       = errors.New("user operation pool full")
+)
+
+// UserOpPool keeps the ERC-4337 user operation bundles until the block they target is built.
+// The builder wraps them into handleOps transactions of its own when it builds the block.
+type UserOpPool struct {
+	mu      sync.Mutex
+	bundles []types.UserOperationBundle
+	known   map[common.Hash]struct{}
+}
+
+func NewUserOpPool() *UserOpPool {
+	return &UserOpPool{known: make(map[common.Hash]struct{})}
+}
+
+// Add stores a user operation bundle, bundles already known are ignored.
+func (p *UserOpPool) Add(bundle types.UserOperationBundle) error {
+	if len(bundle.Ops) == 0 {
+		return ErrUserOpBundleEmpty
+	}
+	if len(bundle.Ops) > maxUserOpsPerBundle {
+		return ErrUserOpBundleTooLarge
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	hash := bundle.Hash()
+	if _, ok := p.known[hash]; ok {
+		return nil
+	}
+	if len(p.bundles) >= maxUserOpBundles {
+		return ErrUserOpPoolFull
+	}
+	p.bundles = append(p.bundles, bundle)
+	p.known[hash] = struct{}{}
+	return nil
+}
+
+// Bundles returns the bundles valid for the given blockNumber/blockTimestamp, pruning
+// outdated bundles.
+func (p *UserOpPool) Bundles(blockNumber *big.Int, blockTimestamp uint64) []types.UserOperationBundle {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var (
+		ret     []types.UserOperationBundle
+		bundles []types.UserOperationBundle
+	)
+	for _, bundle := range p.bundles {
+		// Prune outdated bundles
+		if (bundle.MaxTimestamp != 0 && blockTimestamp > bundle.MaxTimestamp) || blockNumber.Cmp(bundle.BlockNumber) > 0 {
+			delete(p.known, bundle.Hash())
+			continue
+		}
+
+		// keep the bundles around until they need to be pruned
+		bundles = append(bundles, bundle)
+
+		// Roll over future bundles
+		if (bundle.MinTimestamp != 0 && blockTimestamp < bundle.MinTimestamp) || blockNumber.Cmp(bundle.BlockNumber) < 0 {
+			continue
+		}
+		ret = append(ret, bundle)
+	}
+	p.bundles = bundles
+
+	return ret
+}
@@ -86,6 +86,8 @@ type SimSBundle struct {
 	// MevGasPrice = (total coinbase profit) / (gas used)
 	MevGasPrice *big.Int
 	Profit      *big.Int
+	// Priority is the price the sbundle is ordered by instead of MevGasPrice, nil = MevGasPrice
+	Priority *big.Int
 }
 
 func GetRefundConfig(body *BundleBody, signer Signer) ([]RefundConfig, error) {
@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -579,6 +580,9 @@ func NewTxWithMinerFee(tx *Transaction, baseFee *big.Int) (*TxWithMinerFee, erro
 // NewBundleWithMinerFee creates a wrapped bundle.
 func NewBundleWithMinerFee(bundle *SimulatedBundle, _ *big.Int) (*TxWithMinerFee, error) {
 	minerFee := bundle.MevGasPrice
+	if bundle.Priority != nil {
+		minerFee = bundle.Priority
+	}
 	return &TxWithMinerFee{
 		order:    _BundleOrder{bundle},
 		minerFee: minerFee,
@@ -588,6 +592,9 @@ func NewBundleWithMinerFee(bundle *SimulatedBundle, _ *big.Int) (*TxWithMinerFee
 // NewSBundleWithMinerFee creates a wrapped bundle.
 func NewSBundleWithMinerFee(sbundle *SimSBundle, _ *big.Int) (*TxWithMinerFee, error) {
 	minerFee := sbundle.MevGasPrice
+	if sbundle.Priority != nil {
+		minerFee = sbundle.Priority
+	}
 	return &TxWithMinerFee{
 		order:    _SBundleOrder{sbundle},
 		minerFee: minerFee,
@@ -778,14 +785,31 @@ type LatestUuidBundle struct {
 }
 
 type MevBundle struct {
-	Txs               Transactions
-	BlockNumber       *big.Int
+	Txs         Transactions
+	BlockNumber *big.Int
+	// MaxBlockNumber is the last block of the range the bundle is valid for, nil if it is only
+	// valid for BlockNumber. A bundle with a range is included at most once, it is dropped from
+	// the pool once any of its transactions lands or once the range is missed.
+	MaxBlockNumber    *big.Int
 	Uuid              uuid.UUID
 	SigningAddress    common.Address
 	MinTimestamp      uint64
 	MaxTimestamp      uint64
 	RevertingTxHashes []common.Hash
 	Hash              common.Hash
+	// Chain are the hashes of the bundles merged in order into this bundle because they continue
+	// the nonces of each other, nil for the bundles sent as they are.
+	Chain []common.Hash
+}
+
+// PrivateTxPreferences are the preferences of the sender of a private transaction on the bundles
+// including it, enforced on the order of the transactions of the bundles in the block.
+type PrivateTxPreferences struct {
+	// BackrunOnly forbids a transaction of the bundle right before the private transaction
+	BackrunOnly bool `json:"backrunOnly,omitempty"`
+	// NoSandwich forbids transactions of the bundle right before and right after the private
+	// transaction together
+	NoSandwich bool `json:"noSandwich,omitempty"`
 }
 
 func (b *MevBundle) UniquePayload() []byte {
@@ -805,6 +829,14 @@ func (b *MevBundle) ComputeUUID() uuid.UUID {
 	return uuid.NewHash(sha256.New(), uuid.Nil, b.UniquePayload(), 5)
 }
 
+// LastBlockNumber returns the last block the bundle is valid for.
+func (b *MevBundle) LastBlockNumber() *big.Int {
+	if b.MaxBlockNumber != nil {
+		return b.MaxBlockNumber
+	}
+	return b.BlockNumber
+}
+
 func (b *MevBundle) RevertingHash(hash common.Hash) bool {
 	for _, revHash := range b.RevertingTxHashes {
 		if revHash == hash {
@@ -814,10 +846,34 @@ func (b *MevBundle) RevertingHash(hash common.Hash) bool {
 	return false
 }
 
+// EncryptedMevBundle is a bundle whose body is encrypted to the builder's bundle
+// encryption key. Only the fields needed to schedule the bundle are kept in the clear.
+type EncryptedMevBundle struct {
+	BlockNumber    *big.Int
+	SigningAddress common.Address
+	MinTimestamp   uint64
+	MaxTimestamp   uint64
+	Ciphertext     []byte
+}
+
+// Hash returns the hash of the ciphertext, which identifies the bundle without
+// revealing its contents.
+func (b *EncryptedMevBundle) Hash() common.Hash {
+	return crypto.Keccak256Hash(b.Ciphertext)
+}
+
+// EncryptedMevBundleBody is the plaintext sealed inside EncryptedMevBundle.Ciphertext.
+type EncryptedMevBundleBody struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"`
+}
+
 type SimulatedBundle struct {
 	MevGasPrice       *big.Int
 	TotalEth          *big.Int
 	EthSentToCoinbase *big.Int
 	TotalGasUsed      uint64
+	StateGrowth       uint64   // Accounts and storage slots created by the bundle, only measured if the builder limits state growth
+	Priority          *big.Int // Price the bundle is ordered by instead of MevGasPrice, e.g. boosted by its orderflow channel, nil = MevGasPrice
 	OriginalBundle    MevBundle
 }
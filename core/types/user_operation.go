@@ -0,0 +1,39 @@
+package types
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// UserOperation is an ERC-4337 user operation, in the layout of the v0.6 EntryPoint contract.
+type UserOperation struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+// UserOperationBundle is a set of user operations to be included together, through a single
+// handleOps call to their EntryPoint, in the block they target. The builder acts as the bundler,
+// the EntryPoint compensates it for the gas of the operations.
+type UserOperationBundle struct {
+	EntryPoint     common.Address
+	Ops            []UserOperation
+	BlockNumber    *big.Int
+	SigningAddress common.Address
+	MinTimestamp   uint64
+	MaxTimestamp   uint64
+}
+
+// Hash identifies the bundle by its entry point and operations.
+func (b *UserOperationBundle) Hash() common.Hash {
+	return rlpHash([]interface{}{b.EntryPoint, b.Ops})
+}
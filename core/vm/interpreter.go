@@ -30,6 +30,8 @@ type Config struct {
 	NoBaseFee               bool      // Forces the EIP-1559 baseFee to 0 (needed for 0 price calls)
 	EnablePreimageRecording bool      // Enables recording of SHA3/keccak preimages
 	ExtraEips               []int     // Additional EIPS that are to be enabled
+
+	Sandbox *SandboxLimits // Resource limits enforced when simulating untrusted code, never set when processing blocks
 }
 
 // ScopeContext contains the things that are per-call, such as stack and memory,
@@ -218,6 +220,12 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 				logged = true
 			}
 			if memorySize > 0 {
+				if sandbox := in.evm.Config.Sandbox; sandbox != nil {
+					if err := sandbox.checkMemory(memorySize); err != nil {
+						in.evm.Cancel()
+						return nil, err
+					}
+				}
 				mem.Resize(memorySize)
 			}
 		} else if in.evm.Config.Debug {
@@ -235,6 +243,12 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 	if err == errStopToken {
 		err = nil // clear stop token error
 	}
+	if sandbox := in.evm.Config.Sandbox; sandbox != nil {
+		if err := sandbox.checkReturnData(len(res)); err != nil {
+			in.evm.Cancel()
+			return nil, err
+		}
+	}
 
 	return res, err
 }
@@ -0,0 +1,86 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrSandboxMemoryLimit          = errors.New("sandbox memory limit exceeded")
+	ErrSandboxReturnDataLimit      = errors.New("sandbox return data limit exceeded")
+	ErrSandboxPrecompileInputLimit = errors.New("sandbox precompile input limit exceeded")
+)
+
+// SandboxLimits bounds the resources untrusted code may use beyond what gas accounting
+// already allows. Limits are not part of consensus: they are only meant for simulating
+// bundles and must never be set when processing blocks. A zero limit is not enforced.
+//
+// Exceeding a limit aborts the current call frame and cancels the EVM, the violation is
+// then reported by Err. SandboxLimits is not safe for concurrent use, every simulation
+// should use its own copy.
+type SandboxLimits struct {
+	MaxMemorySize          uint64 // Maximum memory size of a single call frame
+	MaxReturnDataSize      uint64 // Maximum size of the data returned by a call frame or precompile
+	MaxPrecompileInputSize uint64 // Maximum input size of a precompile call
+
+	err error // First violated limit
+}
+
+// Copy returns the limits without any recorded violation.
+func (s *SandboxLimits) Copy() *SandboxLimits {
+	if s == nil {
+		return nil
+	}
+	return &SandboxLimits{
+		MaxMemorySize:          s.MaxMemorySize,
+		MaxReturnDataSize:      s.MaxReturnDataSize,
+		MaxPrecompileInputSize: s.MaxPrecompileInputSize,
+	}
+}
+
+// Err returns the first limit violated since the limits were created.
+func (s *SandboxLimits) Err() error {
+	if s == nil {
+		return nil
+	}
+	return s.err
+}
+
+func (s *SandboxLimits) check(size, limit uint64, err error) error {
+	if limit == 0 || size <= limit {
+		return nil
+	}
+	err = fmt.Errorf("%w: %d > %d", err, size, limit)
+	if s.err == nil {
+		s.err = err
+	}
+	return err
+}
+
+func (s *SandboxLimits) checkMemory(size uint64) error {
+	return s.check(size, s.MaxMemorySize, ErrSandboxMemoryLimit)
+}
+
+func (s *SandboxLimits) checkReturnData(size int) error {
+	return s.check(uint64(size), s.MaxReturnDataSize, ErrSandboxReturnDataLimit)
+}
+
+func (s *SandboxLimits) checkPrecompileInput(size int) error {
+	return s.check(uint64(size), s.MaxPrecompileInputSize, ErrSandboxPrecompileInputLimit)
+}
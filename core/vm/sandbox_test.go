@@ -0,0 +1,78 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var sandboxTests = []struct {
+	code    string
+	input   []byte
+	limits  SandboxLimits
+	failure error
+}{
+	// mstore(0x10000, 0)
+	{"0x600062010000520000", nil, SandboxLimits{}, nil},
+	{"0x600062010000520000", nil, SandboxLimits{MaxMemorySize: 0x20000}, nil},
+	{"0x600062010000520000", nil, SandboxLimits{MaxMemorySize: 1024}, ErrSandboxMemoryLimit},
+	// return(0, 0x800)
+	{"0x6108006000f3", nil, SandboxLimits{MaxReturnDataSize: 0x800}, nil},
+	{"0x6108006000f3", nil, SandboxLimits{MaxReturnDataSize: 1024}, ErrSandboxReturnDataLimit},
+	// identity precompile
+	{"", make([]byte, 0x800), SandboxLimits{MaxPrecompileInputSize: 0x800}, nil},
+	{"", make([]byte, 0x800), SandboxLimits{MaxPrecompileInputSize: 1024}, ErrSandboxPrecompileInputLimit},
+	{"", make([]byte, 0x800), SandboxLimits{MaxReturnDataSize: 1024}, ErrSandboxReturnDataLimit},
+}
+
+func TestSandboxLimits(t *testing.T) {
+	for i, tt := range sandboxTests {
+		address := common.BytesToAddress([]byte{4})
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		if tt.code != "" {
+			address = common.BytesToAddress([]byte("contract"))
+			statedb.CreateAccount(address)
+			statedb.SetCode(address, hexutil.MustDecode(tt.code))
+		}
+
+		vmctx := BlockContext{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+		}
+		sandbox := tt.limits.Copy()
+		vmenv := NewEVM(vmctx, TxContext{}, statedb, params.AllEthashProtocolChanges, Config{Sandbox: sandbox})
+
+		_, _, err := vmenv.Call(AccountRef(common.Address{}), address, tt.input, 1000000, new(big.Int))
+		if !errors.Is(err, tt.failure) {
+			t.Errorf("test %d: failure mismatch: have %v, want %v", i, err, tt.failure)
+		}
+		if !errors.Is(sandbox.Err(), tt.failure) {
+			t.Errorf("test %d: recorded violation mismatch: have %v, want %v", i, sandbox.Err(), tt.failure)
+		}
+		if cancelled := tt.failure != nil; vmenv.Cancelled() != cancelled {
+			t.Errorf("test %d: cancellation mismatch: have %v, want %v", i, vmenv.Cancelled(), cancelled)
+		}
+	}
+}
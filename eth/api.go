@@ -33,9 +33,11 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/ethereum/go-ethereum/trie"
@@ -134,6 +136,274 @@ func (api *MinerAPI) SetRecommitInterval(interval int) {
 	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
 }
 
+// BidPolicyArgs is the bid policy set with miner_setBidPolicy.
+type BidPolicyArgs struct {
+	Type          string       `json:"type"`          // full, fixed, percentage or dynamic
+	Margin        *hexutil.Big `json:"margin"`        // Wei kept per block by the fixed policy
+	MarginPercent float64      `json:"marginPercent"` // Percent of the profit kept, the most kept by the dynamic policy
+	Blocks        int          `json:"blocks"`        // Recent winning bids the dynamic policy bids against, the default if omitted
+}
+
+// SetBidPolicy replaces the policy deciding the value paid to the proposer out of the profit of
+// the built blocks.
+func (api *MinerAPI) SetBidPolicy(args BidPolicyArgs) (bool, error) {
+	config := miner.BidPolicyConfig{
+		Type:          args.Type,
+		Margin:        (*big.Int)(args.Margin),
+		MarginPercent: args.MarginPercent,
+		Blocks:        args.Blocks,
+	}
+	if config.Blocks == 0 {
+		config.Blocks = miner.DefaultConfig.BidPolicy.Blocks
+	}
+	if err := api.e.Miner().SetBidPolicy(config); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// TopOfBlockArgs is the top of block reservation set with miner_setTopOfBlockReservation.
+type TopOfBlockArgs struct {
+	Gas       hexutil.Uint64   `json:"gas"`       // Gas reserved at the top of the block, 0 disables the reservation
+	Searchers []common.Address `json:"searchers"` // Searchers whose bundles and transactions may use the reserved gas
+	Contracts []common.Address `json:"contracts"` // Contracts the bundles and transactions using the reserved gas call
+}
+
+// SetTopOfBlockReservation replaces the gas reserved at the top of the built blocks to the
+// orders of designated searchers and contracts, e.g. oracle updates.
+func (api *MinerAPI) SetTopOfBlockReservation(args TopOfBlockArgs) (bool, error) {
+	config := miner.TopOfBlockConfig{
+		Gas:       uint64(args.Gas),
+		Searchers: args.Searchers,
+		Contracts: args.Contracts,
+	}
+	if err := api.e.Miner().SetTopOfBlockReservation(config); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// TopOfBlockReservation returns the gas reserved at the top of the built blocks and the
+// searchers and contracts allowed to use it.
+func (api *MinerAPI) TopOfBlockReservation() TopOfBlockArgs {
+	config := api.e.Miner().TopOfBlockReservation()
+	return TopOfBlockArgs{
+		Gas:       hexutil.Uint64(config.Gas),
+		Searchers: config.Searchers,
+		Contracts: config.Contracts,
+	}
+}
+
+// ProfitReport returns the profit breakdown of the recent blocks built by the node that
+// landed on chain, along with daily aggregates for accounting.
+func (api *MinerAPI) ProfitReport() miner.ProfitReport {
+	return api.e.Miner().ProfitReport()
+}
+
+// SearcherAnalytics returns the submission volume, simulation failures, inclusion rate and
+// total paid of every searcher over the last window seconds, 24 hours if omitted.
+func (api *MinerAPI) SearcherAnalytics(window *uint64) []miner.SearcherStats {
+	var duration time.Duration
+	if window != nil {
+		duration = time.Duration(*window) * time.Second
+	}
+	return api.e.Miner().SearcherAnalytics(duration)
+}
+
+// SearcherReputations returns the decayed history of simulation failures, reverts on chain and
+// profit delivered of every searcher, with the score prioritising its bundles under high load.
+func (api *MinerAPI) SearcherReputations() []miner.SearcherReputation {
+	return api.e.Miner().SearcherReputations()
+}
+
+// BundleSourceAnalytics returns the submission volume, simulations, inclusion rate and total
+// paid of every bundle ingestion source over the last window seconds, 24 hours if omitted.
+func (api *MinerAPI) BundleSourceAnalytics(window *uint64) []miner.SourceStats {
+	var duration time.Duration
+	if window != nil {
+		duration = time.Duration(*window) * time.Second
+	}
+	return api.e.Miner().BundleSourceAnalytics(duration)
+}
+
+// ReorgReports returns the recent blocks built by the node that landed on chain and were
+// reorged out, with the bundles that lost their inclusion and the profit to reconcile.
+func (api *MinerAPI) ReorgReports() []miner.ReorgReport {
+	return api.e.Miner().ReorgReports()
+}
+
+// BlockValueReport compares the coinbase value of the recent canonical blocks with the most
+// valuable blocks built by the node for the same heights, with the value the node missed.
+func (api *MinerAPI) BlockValueReport() *miner.BlockValueReport {
+	return api.e.Miner().BlockValueReport()
+}
+
+// BacktestBundleArgs is a recorded bundle replayed by a backtest.
+type BacktestBundleArgs struct {
+	Txs               []hexutil.Bytes `json:"txs"`
+	BlockNumber       hexutil.Uint64  `json:"blockNumber"`
+	SigningAddress    *common.Address `json:"signingAddress"`
+	RevertingTxHashes []common.Hash   `json:"revertingTxHashes"`
+}
+
+// BacktestArgs are the arguments of a backtest.
+type BacktestArgs struct {
+	FromBlock hexutil.Uint64 `json:"fromBlock"`
+	ToBlock   hexutil.Uint64 `json:"toBlock"`
+	// Algo is the block building algorithm to evaluate, the configured one if empty
+	Algo    string               `json:"algo"`
+	Bundles []BacktestBundleArgs `json:"bundles"`
+	// Mempool is the path on the node of a historical mempool dataset, a CSV file in the
+	// mempool-dumpster format, replacing the landed transactions as the mempool of the blocks
+	Mempool string `json:"mempool"`
+}
+
+// Backtest replays a range of historical blocks through a block building algorithm and reports
+// the profit of the blocks the builder would have built versus the blocks that landed. Blocks
+// without recorded bundles are replayed with their own transactions as bundles, unless a
+// historical mempool dataset is given as the transaction source.
+func (api *MinerAPI) Backtest(ctx context.Context, args BacktestArgs) (*miner.BacktestReport, error) {
+	bundles := make([]types.MevBundle, 0, len(args.Bundles))
+	for i, recorded := range args.Bundles {
+		txs := make(types.Transactions, 0, len(recorded.Txs))
+		for _, encodedTx := range recorded.Txs {
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(encodedTx); err != nil {
+				return nil, fmt.Errorf("bundle %d: %w", i, err)
+			}
+			txs = append(txs, tx)
+		}
+		bundle := types.MevBundle{
+			Txs:               txs,
+			BlockNumber:       new(big.Int).SetUint64(uint64(recorded.BlockNumber)),
+			RevertingTxHashes: recorded.RevertingTxHashes,
+			Hash:              txpool.MevBundleHash(txs),
+		}
+		if recorded.SigningAddress != nil {
+			bundle.SigningAddress = *recorded.SigningAddress
+		}
+		bundles = append(bundles, bundle)
+	}
+	var mempool []miner.MempoolTx
+	if args.Mempool != "" {
+		var err error
+		if mempool, err = miner.LoadMempoolDataset(args.Mempool, api.e.BlockChain().Config().ChainID); err != nil {
+			return nil, fmt.Errorf("mempool dataset: %w", err)
+		}
+	}
+	return api.e.Miner().Backtest(ctx, uint64(args.FromBlock), uint64(args.ToBlock), args.Algo, bundles, mempool)
+}
+
+// BundleStatusAPI notifies searchers of status changes of their bundles.
+type BundleStatusAPI struct {
+	e *Ethereum
+}
+
+// NewBundleStatusAPI creates a new BundleStatusAPI instance.
+func NewBundleStatusAPI(e *Ethereum) *BundleStatusAPI {
+	return &BundleStatusAPI{e}
+}
+
+// BundleReorgs creates a subscription notified when a block built by the node that included
+// bundles of the searcher is reorged out. The notification lists the bundles of the searcher
+// that lost their inclusion. Without searcher, every reorged block with bundles is notified.
+func (api *BundleStatusAPI) BundleReorgs(ctx context.Context, searcher *common.Address) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		reports := make(chan miner.ReorgReport, 16)
+		reportsSub := api.e.Miner().SubscribeReorgReports(reports)
+
+		for {
+			select {
+			case report := <-reports:
+				if notification := report.BundleNotification(searcher); notification != nil {
+					notifier.Notify(rpcSub.ID, notification)
+				}
+			case <-rpcSub.Err():
+				reportsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				reportsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// MissedBundle is a bundle with a block range dropped from the pool, the range ended without the
+// bundle being included.
+type MissedBundle struct {
+	BundleHash     common.Hash    `json:"bundleHash"`
+	Searcher       common.Address `json:"searcher"`
+	BlockNumber    hexutil.Uint64 `json:"blockNumber"`
+	MaxBlockNumber hexutil.Uint64 `json:"maxBlockNumber"`
+}
+
+// MissedBundles creates a subscription notified when a bundle of the searcher sent with a block
+// range is dropped from the pool after missing its range. Without searcher, every missed bundle
+// is notified.
+func (api *BundleStatusAPI) MissedBundles(ctx context.Context, searcher *common.Address) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		missed := make(chan core.MissedMevBundlesEvent, 16)
+		missedSub := api.e.TxPool().SubscribeMissedMevBundlesEvent(missed)
+
+		for {
+			select {
+			case ev := <-missed:
+				for _, bundle := range ev.Bundles {
+					if searcher != nil && bundle.SigningAddress != *searcher {
+						continue
+					}
+					notifier.Notify(rpcSub.ID, &MissedBundle{
+						BundleHash:     bundle.Hash,
+						Searcher:       bundle.SigningAddress,
+						BlockNumber:    hexutil.Uint64(bundle.BlockNumber.Uint64()),
+						MaxBlockNumber: hexutil.Uint64(bundle.LastBlockNumber().Uint64()),
+					})
+				}
+			case <-rpcSub.Err():
+				missedSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				missedSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// GetBundleStats returns the status of a bundle submitted to the node: when it was received, its
+// recent simulations, the block building rounds which considered it and whether a block built by
+// the node landed with it. The stats of a bundle with a signer are only served to requests
+// signed by it.
+func (api *BundleStatusAPI) GetBundleStats(ctx context.Context, hash common.Hash) (*miner.BundleStats, error) {
+	stats := api.e.Miner().BundleStats(hash)
+	if stats == nil {
+		return nil, errors.New("unknown bundle")
+	}
+	if stats.Searcher != (common.Address{}) && rpc.PeerInfoFromContext(ctx).HTTP.Signer != stats.Searcher {
+		return nil, errors.New("bundle stats are only served to the bundle signer")
+	}
+	return stats, nil
+}
+
 // AdminAPI is the collection of Ethereum full node related APIs for node
 // administration.
 type AdminAPI struct {
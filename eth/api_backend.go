@@ -18,12 +18,18 @@ package eth
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"math/big"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/builder/auditlog"
+	"github.com/ethereum/go-ethereum/builder/bundlerecord"
+	"github.com/ethereum/go-ethereum/builder/depositgate"
+	"github.com/ethereum/go-ethereum/builder/eventexport"
+	"github.com/ethereum/go-ethereum/builder/searcherauth"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
@@ -33,22 +39,40 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/internal/redact"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/miner"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/google/uuid"
 )
 
+var (
+	bundleReceivedMeter          = metrics.NewRegisteredMeter("eth/bundle/received", nil)
+	bundleRejectedMeter          = metrics.NewRegisteredMeter("eth/bundle/rejected", nil)
+	encryptedBundleReceivedMeter = metrics.NewRegisteredMeter("eth/bundle/encrypted/received", nil)
+	encryptedBundleRejectedMeter = metrics.NewRegisteredMeter("eth/bundle/encrypted/rejected", nil)
+	sbundleReceivedMeter         = metrics.NewRegisteredMeter("eth/sbundle/received", nil)
+	sbundleRejectedMeter         = metrics.NewRegisteredMeter("eth/sbundle/rejected", nil)
+	userOpBundleReceivedMeter    = metrics.NewRegisteredMeter("eth/userop/received", nil)
+	userOpBundleRejectedMeter    = metrics.NewRegisteredMeter("eth/userop/rejected", nil)
+)
+
 // EthAPIBackend implements ethapi.Backend for full nodes
 type EthAPIBackend struct {
 	extRPCEnabled       bool
 	allowUnprotectedTxs bool
 	eth                 *Ethereum
 	gpo                 *gasprice.Oracle
+	depositGate         *depositgate.Gate
+	searcherAuth        *searcherauth.Authenticator
 }
 
 // ChainConfig returns the active chain configuration.
@@ -282,12 +306,180 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction,
 	}
 }
 
-func (b *EthAPIBackend) SendBundle(ctx context.Context, txs types.Transactions, blockNumber rpc.BlockNumber, uuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
-	return b.eth.txPool.AddMevBundle(txs, big.NewInt(blockNumber.Int64()), uuid, signingAddress, minTimestamp, maxTimestamp, revertingTxHashes)
+func (b *EthAPIBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, maxBlockNumber uint64, prefs types.PrivateTxPreferences) error {
+	if err := b.eth.txPool.AddBuilderOnlyTx(signedTx, maxBlockNumber, prefs); err != nil {
+		log.Debug("Rejected private transaction", "tx", redact.Tx(signedTx), "err", err)
+		return err
+	}
+	return nil
+}
+
+func (b *EthAPIBackend) CancelPrivateTx(ctx context.Context, txHash common.Hash, from common.Address) bool {
+	return b.eth.txPool.CancelPrivateTx(txHash, from)
+}
+
+// SetDepositGate enables the deposit-gated access tier for bundle submissions. Must be called before the node is started.
+func (b *EthAPIBackend) SetDepositGate(gate *depositgate.Gate) {
+	b.depositGate = gate
+}
+
+// SetSearcherAuth enables the authentication and rate limiting of the bundle signers. Must be called before the node is started.
+func (b *EthAPIBackend) SetSearcherAuth(auth *searcherauth.Authenticator) {
+	b.searcherAuth = auth
+}
+
+// AuthenticateBundle returns the signer of a bundle submission: the signer of the request, the
+// zero address if it is not signed. The signing address claimed by the bundle is ignored unless
+// the request is signed by it, so the per-signer limits only apply to authenticated signers.
+func (b *EthAPIBackend) AuthenticateBundle(ctx context.Context, signingAddress *common.Address) (common.Address, error) {
+	signer, err := searcherauth.Identity(rpc.PeerInfoFromContext(ctx).HTTP.Signer, signingAddress)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if b.searcherAuth != nil {
+		if err := b.searcherAuth.Authenticate(signer); err != nil {
+			return common.Address{}, err
+		}
+	}
+	return signer, nil
+}
+
+// allowBundle checks the submission quota of the bundle searcher, identified by the address that
+// signed the request. Unsigned requests have no searcher and are rejected by the gate.
+func (b *EthAPIBackend) allowBundle(signer common.Address) error {
+	if b.depositGate == nil {
+		return nil
+	}
+	return b.depositGate.Allow(signer)
+}
+
+// SendBundle adds the bundle to the pool, valid for the blocks from blockNumber to maxBlockNumber
+// or only for blockNumber if maxBlockNumber is 0.
+func (b *EthAPIBackend) SendBundle(ctx context.Context, txs types.Transactions, blockNumber, maxBlockNumber rpc.BlockNumber, uuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash) (err error) {
+	defer markBundleIngestion(bundleReceivedMeter, bundleRejectedMeter, &err)
+	if bundlerecord.Enabled() {
+		bundlerecord.RecordBundle(bundlerecord.NewBundle(txs, uint64(blockNumber.Int64()), uuid, signingAddress, minTimestamp, maxTimestamp, revertingTxHashes))
+	}
+	if err := b.allowBundle(signingAddress); err != nil {
+		return err
+	}
+	var maxBlock *big.Int
+	if maxBlockNumber > 0 {
+		maxBlock = big.NewInt(maxBlockNumber.Int64())
+	}
+	if err := b.eth.txPool.AddMevBundle(txs, big.NewInt(blockNumber.Int64()), maxBlock, uuid, signingAddress, minTimestamp, maxTimestamp, revertingTxHashes); err != nil {
+		// bundles are submitted asynchronously, the rejection is not returned to the searcher
+		log.Debug("Rejected bundle", "bundle", redact.Bundle(&types.MevBundle{
+			Txs:            txs,
+			BlockNumber:    big.NewInt(blockNumber.Int64()),
+			SigningAddress: signingAddress,
+			Hash:           txpool.MevBundleHash(txs),
+		}), "err", err)
+		return err
+	}
+	b.eth.Miner().BundleSubmitted(signingAddress, txpool.MevBundleHash(txs), miner.BundleSourceFromTransport(rpc.PeerInfoFromContext(ctx).Transport))
+	if auditlog.Enabled() {
+		auditlog.Append(auditlog.EventBundleReceived, auditlog.NewBundleReceived(txs, uint64(blockNumber.Int64()), uuid, signingAddress, minTimestamp, maxTimestamp))
+	}
+	if eventexport.Enabled() {
+		eventexport.Publish(eventexport.EventBundleReceived, eventexport.NewBundleReceived(txs, uint64(blockNumber.Int64()), signingAddress))
+	}
+	return nil
+}
+
+func (b *EthAPIBackend) CancelBundle(ctx context.Context, uuid uuid.UUID, signingAddress common.Address) int {
+	return b.eth.txPool.CancelMevBundles(uuid, signingAddress)
 }
 
-func (b *EthAPIBackend) SendSBundle(ctx context.Context, sbundle *types.SBundle) error {
-	return b.eth.txPool.AddSBundle(sbundle)
+func (b *EthAPIBackend) SimulateBundle(ctx context.Context, bundle types.MevBundle) (*types.SimulatedBundle, error) {
+	return b.eth.Miner().SimulateBundle(bundle)
+}
+
+func (b *EthAPIBackend) SendEncryptedBundle(ctx context.Context, bundle *types.EncryptedMevBundle) (err error) {
+	defer markBundleIngestion(encryptedBundleReceivedMeter, encryptedBundleRejectedMeter, &err)
+	if err := b.allowBundle(bundle.SigningAddress); err != nil {
+		return err
+	}
+	if err := b.eth.txPool.AddEncryptedMevBundle(*bundle); err != nil {
+		return err
+	}
+	// the bundle hash is only known once decrypted, encrypted bundles are not tracked by source
+	b.eth.Miner().BundleSubmitted(bundle.SigningAddress, common.Hash{}, "")
+	if eventexport.Enabled() {
+		eventexport.Publish(eventexport.EventBundleReceived, &eventexport.BundleReceived{
+			BundleHash:  bundle.Hash(),
+			Encrypted:   true,
+			BlockNumber: bundle.BlockNumber.Uint64(),
+			Searcher:    bundle.SigningAddress,
+		})
+	}
+	return nil
+}
+
+func (b *EthAPIBackend) BundleEncryptionKey() *ecies.PublicKey {
+	return b.eth.txPool.BundleEncryptionKey()
+}
+
+func (b *EthAPIBackend) SendUserOperationBundle(ctx context.Context, bundle *types.UserOperationBundle) (err error) {
+	defer markBundleIngestion(userOpBundleReceivedMeter, userOpBundleRejectedMeter, &err)
+	if err := b.allowBundle(bundle.SigningAddress); err != nil {
+		return err
+	}
+	return b.eth.txPool.AddUserOpBundle(*bundle)
+}
+
+func (b *EthAPIBackend) SendSBundle(ctx context.Context, sbundle *types.SBundle) (err error) {
+	defer markBundleIngestion(sbundleReceivedMeter, sbundleRejectedMeter, &err)
+	if bundlerecord.Enabled() {
+		recordSBundle(sbundle)
+	}
+	if err := b.allowBundle(rpc.PeerInfoFromContext(ctx).HTTP.Signer); err != nil {
+		return err
+	}
+	if err := b.eth.txPool.AddSBundle(sbundle); err != nil {
+		return err
+	}
+	b.eth.Miner().BundleSubmitted(common.Address{}, sbundle.Hash(), miner.BundleSourceFromTransport(rpc.PeerInfoFromContext(ctx).Transport))
+	if auditlog.Enabled() {
+		auditlog.Append(auditlog.EventSBundleReceived, &auditlog.BundleReceived{
+			BundleHash:  sbundle.Hash(),
+			BlockNumber: sbundle.Inclusion.BlockNumber,
+		})
+	}
+	if eventexport.Enabled() {
+		eventexport.Publish(eventexport.EventBundleReceived, &eventexport.BundleReceived{
+			BundleHash:  sbundle.Hash(),
+			SBundle:     true,
+			BlockNumber: sbundle.Inclusion.BlockNumber,
+		})
+	}
+	return nil
+}
+
+// recordSBundle records a sbundle in the encoding of its mev_sendBundle arguments.
+func recordSBundle(sbundle *types.SBundle) {
+	args, err := ethapi.ConvertSBundleToArgs(sbundle)
+	if err != nil {
+		log.Error("Failed to record sbundle", "bundle", sbundle.Hash(), "err", err)
+		return
+	}
+	encoded, err := json.Marshal(&args)
+	if err != nil {
+		log.Error("Failed to record sbundle", "bundle", sbundle.Hash(), "err", err)
+		return
+	}
+	bundlerecord.RecordBundle(bundlerecord.NewSBundle(sbundle.Inclusion.BlockNumber, encoded))
+}
+
+// markBundleIngestion counts a bundle submission, and its rejection if it failed.
+func markBundleIngestion(received, rejected metrics.Meter, err *error) {
+	if !metrics.EnabledBuilder {
+		return
+	}
+	received.Mark(1)
+	if *err != nil {
+		rejected.Mark(1)
+	}
 }
 
 func (b *EthAPIBackend) CancelSBundles(ctx context.Context, hashes []common.Hash) {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"sync"
 
 	bellatrixapi "github.com/attestantio/go-builder-client/api/bellatrix"
 	capellaapi "github.com/attestantio/go-builder-client/api/capella"
@@ -16,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/internal/redact"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -28,7 +30,7 @@ type AccessVerifier struct {
 }
 
 func (a *AccessVerifier) verifyTraces(tracer *logger.AccessListTracer) error {
-	log.Trace("x", "tracer.AccessList()", tracer.AccessList())
+	log.Trace("x", "tracer.AccessList()", redact.Value(tracer.AccessList()))
 	for _, accessTuple := range tracer.AccessList() {
 		// TODO: should we ignore common.Address{}?
 		if _, found := a.blacklistedAddresses[accessTuple.Address]; found {
@@ -113,8 +115,9 @@ func Register(stack *node.Node, backend *eth.Ethereum, cfg BlockValidationConfig
 }
 
 type BlockValidationAPI struct {
-	eth            *eth.Ethereum
-	accessVerifier *AccessVerifier
+	eth              *eth.Ethereum
+	accessVerifierMu sync.RWMutex
+	accessVerifier   *AccessVerifier
 	// If set to true, proposer payment is calculated as a balance difference of the fee recipient.
 	useBalanceDiffProfit bool
 }
@@ -129,6 +132,20 @@ func NewBlockValidationAPI(eth *eth.Ethereum, accessVerifier *AccessVerifier, us
 	}
 }
 
+// SetAccessVerifier replaces the blocklist the submissions are checked against, nil disables
+// the checks.
+func (api *BlockValidationAPI) SetAccessVerifier(accessVerifier *AccessVerifier) {
+	api.accessVerifierMu.Lock()
+	defer api.accessVerifierMu.Unlock()
+	api.accessVerifier = accessVerifier
+}
+
+func (api *BlockValidationAPI) getAccessVerifier() *AccessVerifier {
+	api.accessVerifierMu.RLock()
+	defer api.accessVerifierMu.RUnlock()
+	return api.accessVerifier
+}
+
 type BuilderBlockValidationRequest struct {
 	bellatrixapi.SubmitBlockRequest
 	RegisteredGasLimit uint64 `json:"registered_gas_limit,string"`
@@ -168,14 +185,15 @@ func (api *BlockValidationAPI) ValidateBuilderSubmissionV1(params *BuilderBlockV
 
 	var vmconfig vm.Config
 	var tracer *logger.AccessListTracer = nil
-	if api.accessVerifier != nil {
-		if err := api.accessVerifier.isBlacklisted(block.Coinbase()); err != nil {
+	accessVerifier := api.getAccessVerifier()
+	if accessVerifier != nil {
+		if err := accessVerifier.isBlacklisted(block.Coinbase()); err != nil {
 			return err
 		}
-		if err := api.accessVerifier.isBlacklisted(feeRecipient); err != nil {
+		if err := accessVerifier.isBlacklisted(feeRecipient); err != nil {
 			return err
 		}
-		if err := api.accessVerifier.verifyTransactions(types.LatestSigner(api.eth.BlockChain().Config()), block.Transactions()); err != nil {
+		if err := accessVerifier.verifyTransactions(types.LatestSigner(api.eth.BlockChain().Config()), block.Transactions()); err != nil {
 			return err
 		}
 		isPostMerge := true // the call is PoS-native
@@ -191,8 +209,8 @@ func (api *BlockValidationAPI) ValidateBuilderSubmissionV1(params *BuilderBlockV
 		return err
 	}
 
-	if api.accessVerifier != nil && tracer != nil {
-		if err := api.accessVerifier.verifyTraces(tracer); err != nil {
+	if accessVerifier != nil && tracer != nil {
+		if err := accessVerifier.verifyTraces(tracer); err != nil {
 			return err
 		}
 	}
@@ -261,14 +279,15 @@ func (api *BlockValidationAPI) ValidateBuilderSubmissionV2(params *BuilderBlockV
 
 	var vmconfig vm.Config
 	var tracer *logger.AccessListTracer = nil
-	if api.accessVerifier != nil {
-		if err := api.accessVerifier.isBlacklisted(block.Coinbase()); err != nil {
+	accessVerifier := api.getAccessVerifier()
+	if accessVerifier != nil {
+		if err := accessVerifier.isBlacklisted(block.Coinbase()); err != nil {
 			return err
 		}
-		if err := api.accessVerifier.isBlacklisted(feeRecipient); err != nil {
+		if err := accessVerifier.isBlacklisted(feeRecipient); err != nil {
 			return err
 		}
-		if err := api.accessVerifier.verifyTransactions(types.LatestSigner(api.eth.BlockChain().Config()), block.Transactions()); err != nil {
+		if err := accessVerifier.verifyTransactions(types.LatestSigner(api.eth.BlockChain().Config()), block.Transactions()); err != nil {
 			return err
 		}
 		isPostMerge := true // the call is PoS-native
@@ -283,8 +302,8 @@ func (api *BlockValidationAPI) ValidateBuilderSubmissionV2(params *BuilderBlockV
 		return err
 	}
 
-	if api.accessVerifier != nil && tracer != nil {
-		if err := api.accessVerifier.verifyTraces(tracer); err != nil {
+	if accessVerifier != nil && tracer != nil {
+		if err := accessVerifier.verifyTraces(tracer); err != nil {
 			return err
 		}
 	}
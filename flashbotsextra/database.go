@@ -27,6 +27,9 @@ type IDatabaseService interface {
 		bidTrace *apiv1.BidTrace)
 	GetPriorityBundles(ctx context.Context, blockNum int64, isHighPrio bool) ([]DbBundle, error)
 	GetLatestUuidBundles(ctx context.Context, blockNum int64) ([]types.LatestUuidBundle, error)
+	ConsumeSlotOutcome(outcome *SlotOutcome)
+	GetSlotOutcomeCounts(ctx context.Context) (won uint64, lost uint64, err error)
+	GetMissedSlots(ctx context.Context, limit int) ([]SlotOutcome, error)
 }
 
 type NilDbService struct{}
@@ -42,6 +45,16 @@ func (NilDbService) GetLatestUuidBundles(ctx context.Context, blockNum int64) ([
 	return []types.LatestUuidBundle{}, nil
 }
 
+func (NilDbService) ConsumeSlotOutcome(*SlotOutcome) {}
+
+func (NilDbService) GetSlotOutcomeCounts(ctx context.Context) (uint64, uint64, error) {
+	return 0, 0, nil
+}
+
+func (NilDbService) GetMissedSlots(ctx context.Context, limit int) ([]SlotOutcome, error) {
+	return []SlotOutcome{}, nil
+}
+
 type DatabaseService struct {
 	db *sqlx.DB
 
@@ -57,6 +70,10 @@ func NewDatabaseService(postgresDSN string) (*DatabaseService, error) {
 		return nil, err
 	}
 
+	if _, err := db.Exec(slotOutcomesSchema); err != nil {
+		return nil, err
+	}
+
 	insertBuiltBlockStmt, err := db.PrepareNamed("insert into built_blocks (block_number, profit, slot, hash, gas_limit, gas_used, base_fee, parent_hash, proposer_pubkey, proposer_fee_recipient, builder_pubkey, timestamp, timestamp_datetime, orders_closed_at, sealed_at) values (:block_number, :profit, :slot, :hash, :gas_limit, :gas_used, :base_fee, :parent_hash, :proposer_pubkey, :proposer_fee_recipient, :builder_pubkey, :timestamp, to_timestamp(:timestamp), :orders_closed_at, :sealed_at) returning block_id")
 	if err != nil {
 		return nil, err
@@ -368,3 +385,30 @@ func (ds *DatabaseService) GetLatestUuidBundles(ctx context.Context, blockNum in
 	}
 	return latestBundles, nil
 }
+
+func (ds *DatabaseService) ConsumeSlotOutcome(outcome *SlotOutcome) {
+	ctx, cancel := context.WithTimeout(context.Background(), 12*time.Second)
+	defer cancel()
+
+	if _, err := ds.db.NamedExecContext(ctx, insertSlotOutcomeQuery, outcome); err != nil {
+		log.Error("could not insert slot outcome", "slot", outcome.Slot, "err", err)
+	}
+}
+
+// GetSlotOutcomeCounts returns the number of won slots, and of lost slots the builder
+// successfully submitted blocks for.
+func (ds *DatabaseService) GetSlotOutcomeCounts(ctx context.Context) (uint64, uint64, error) {
+	var counts struct {
+		Won  uint64 `db:"won"`
+		Lost uint64 `db:"lost"`
+	}
+	err := ds.db.GetContext(ctx, &counts, "select count(*) filter (where won) as won, count(*) filter (where not won and submitted_blocks > 0) as lost from slot_outcomes")
+	return counts.Won, counts.Lost, err
+}
+
+// GetMissedSlots returns the most recent missed slots, most recent first.
+func (ds *DatabaseService) GetMissedSlots(ctx context.Context, limit int) ([]SlotOutcome, error) {
+	var outcomes []SlotOutcome
+	err := ds.db.SelectContext(ctx, &outcomes, "select slot, block_number, parent_hash, proposer_fee_recipient, submissions, failed_submissions, submitted_blocks, best_block_hash, best_bid, landed_block_hash, won, postmortem, settled_at from slot_outcomes where not won order by slot desc limit $1", limit)
+	return outcomes, err
+}
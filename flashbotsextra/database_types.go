@@ -109,3 +109,44 @@ func SimulatedBundleToDbBundle(bundle *types.SimulatedBundle) DbBundle {
 		EthSentToCoinbase: new(big.Rat).SetFrac(bundle.EthSentToCoinbase, big.NewInt(1e18)).FloatString(18),
 	}
 }
+
+// SlotOutcome is the outcome of a slot the builder submitted blocks for, settled once the
+// chain moved past the slot.
+type SlotOutcome struct {
+	Slot                 uint64    `db:"slot"`
+	BlockNumber          uint64    `db:"block_number"`
+	ParentHash           string    `db:"parent_hash"`
+	ProposerFeeRecipient string    `db:"proposer_fee_recipient"`
+	Submissions          int       `db:"submissions"`
+	FailedSubmissions    int       `db:"failed_submissions"`
+	SubmittedBlocks      int       `db:"submitted_blocks"`
+	BestBlockHash        string    `db:"best_block_hash"`
+	BestBid              string    `db:"best_bid"`
+	LandedBlockHash      string    `db:"landed_block_hash"`
+	Won                  bool      `db:"won"`
+	Postmortem           *string   `db:"postmortem"` // JSON postmortem of a missed slot
+	SettledAt            time.Time `db:"settled_at"`
+}
+
+// slotOutcomesSchema creates the table of the slot outcomes, the table is owned by the builder.
+var slotOutcomesSchema = `
+CREATE TABLE IF NOT EXISTS slot_outcomes (
+	slot                   bigint PRIMARY KEY,
+	block_number           bigint NOT NULL,
+	parent_hash            varchar(66) NOT NULL,
+	proposer_fee_recipient varchar(42) NOT NULL,
+	submissions            integer NOT NULL,
+	failed_submissions     integer NOT NULL,
+	submitted_blocks       integer NOT NULL,
+	best_block_hash        varchar(66) NOT NULL,
+	best_bid               numeric(48, 18) NOT NULL,
+	landed_block_hash      varchar(66) NOT NULL,
+	won                    boolean NOT NULL,
+	postmortem             jsonb,
+	settled_at             timestamptz NOT NULL
+)`
+
+var insertSlotOutcomeQuery = `
+INSERT INTO slot_outcomes (slot, block_number, parent_hash, proposer_fee_recipient, submissions, failed_submissions, submitted_blocks, best_block_hash, best_bid, landed_block_hash, won, postmortem, settled_at)
+VALUES (:slot, :block_number, :parent_hash, :proposer_fee_recipient, :submissions, :failed_submissions, :submitted_blocks, :best_block_hash, :best_bid, :landed_block_hash, :won, :postmortem, :settled_at)
+ON CONFLICT (slot) DO NOTHING`
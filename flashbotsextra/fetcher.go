@@ -13,6 +13,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/miner"
 	"golang.org/x/crypto/sha3"
 )
 
@@ -52,6 +53,9 @@ func (b *bundleFetcher) Run() {
 			log.Info("Start receiving mev bundles")
 			for bundles := range b.bundlesCh {
 				b.backend.TxPool().AddMevBundles(bundles)
+				for _, bundle := range bundles {
+					b.backend.Miner().BundleSubmitted(bundle.SigningAddress, bundle.Hash, miner.BundleSourceFetcher)
+				}
 			}
 		}
 		go pushBlockNum()
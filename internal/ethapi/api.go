@@ -38,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -2130,6 +2131,7 @@ func NewPrivateTxBundleAPI(b Backend) *PrivateTxBundleAPI {
 type SendBundleArgs struct {
 	Txs               []hexutil.Bytes `json:"txs"`
 	BlockNumber       rpc.BlockNumber `json:"blockNumber"`
+	MaxBlockNumber    rpc.BlockNumber `json:"maxBlockNumber,omitempty"` // Last block of the range the bundle is valid for, if any
 	ReplacementUuid   *uuid.UUID      `json:"replacementUuid"`
 	SigningAddress    *common.Address `json:"signingAddress"`
 	MinTimestamp      *uint64         `json:"minTimestamp"`
@@ -2140,18 +2142,37 @@ type SendBundleArgs struct {
 // SendBundle will add the signed transaction to the transaction pool.
 // The sender is responsible for signing the transaction and using the correct nonce and ensuring validity
 func (s *PrivateTxBundleAPI) SendBundle(ctx context.Context, args SendBundleArgs) error {
+	bundle, err := s.decodeBundle(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	go s.b.SendBundle(ctx, bundle.Txs, args.BlockNumber, args.MaxBlockNumber, bundle.Uuid, bundle.SigningAddress, bundle.MinTimestamp, bundle.MaxTimestamp, bundle.RevertingTxHashes)
+
+	return nil
+}
+
+// decodeBundle decodes the transactions of the bundle and authenticates its signer.
+func (s *PrivateTxBundleAPI) decodeBundle(ctx context.Context, args SendBundleArgs) (*types.MevBundle, error) {
 	var txs types.Transactions
 	if len(args.Txs) == 0 {
-		return errors.New("bundle missing txs")
+		return nil, errors.New("bundle missing txs")
 	}
 	if args.BlockNumber == 0 {
-		return errors.New("bundle missing blockNumber")
+		return nil, errors.New("bundle missing blockNumber")
+	}
+	var maxBlockNumber *big.Int
+	if args.MaxBlockNumber != 0 {
+		if args.MaxBlockNumber < args.BlockNumber || args.MaxBlockNumber-args.BlockNumber > txpool.MaxMevBundleRange {
+			return nil, fmt.Errorf("%w: blocks %d to %d", txpool.ErrInvalidBundleRange, args.BlockNumber, args.MaxBlockNumber)
+		}
+		maxBlockNumber = big.NewInt(args.MaxBlockNumber.Int64())
 	}
 
 	for _, encodedTx := range args.Txs {
 		tx := new(types.Transaction)
 		if err := tx.UnmarshalBinary(encodedTx); err != nil {
-			return err
+			return nil, err
 		}
 		txs = append(txs, tx)
 	}
@@ -2161,9 +2182,9 @@ func (s *PrivateTxBundleAPI) SendBundle(ctx context.Context, args SendBundleArgs
 		replacementUuid = *args.ReplacementUuid
 	}
 
-	var signingAddress common.Address
-	if args.SigningAddress != nil {
-		signingAddress = *args.SigningAddress
+	signingAddress, err := s.b.AuthenticateBundle(ctx, args.SigningAddress)
+	if err != nil {
+		return nil, err
 	}
 
 	var minTimestamp, maxTimestamp uint64
@@ -2174,9 +2195,270 @@ func (s *PrivateTxBundleAPI) SendBundle(ctx context.Context, args SendBundleArgs
 		maxTimestamp = *args.MaxTimestamp
 	}
 
-	go s.b.SendBundle(ctx, txs, args.BlockNumber, replacementUuid, signingAddress, minTimestamp, maxTimestamp, args.RevertingTxHashes)
+	return &types.MevBundle{
+		Txs:               txs,
+		BlockNumber:       big.NewInt(args.BlockNumber.Int64()),
+		MaxBlockNumber:    maxBlockNumber,
+		Uuid:              replacementUuid,
+		SigningAddress:    signingAddress,
+		MinTimestamp:      minTimestamp,
+		MaxTimestamp:      maxTimestamp,
+		RevertingTxHashes: args.RevertingTxHashes,
+		Hash:              txpool.MevBundleHash(txs),
+	}, nil
+}
 
-	return nil
+// BundleFeedback is the outcome of a bundle streamed with eth_streamBundle.
+type BundleFeedback struct {
+	BundleHash      common.Hash    `json:"bundleHash"`
+	Accepted        bool           `json:"accepted"`                  // Whether the bundle entered the bundle pool
+	Error           string         `json:"error,omitempty"`           // Reason the bundle was rejected or failed its simulation
+	GasUsed         hexutil.Uint64 `json:"gasUsed,omitempty"`         // Gas used by the bundle in the simulation
+	MevGasPrice     *hexutil.Big   `json:"mevGasPrice,omitempty"`     // Coinbase profit of the bundle per gas used
+	EstimatedProfit *hexutil.Big   `json:"estimatedProfit,omitempty"` // Coinbase profit of the bundle, fees included
+}
+
+// StreamBundle adds the bundle to the pool like SendBundle and simulates it at once at the top of
+// the block being built, returning the outcome on the same connection. It is only served over
+// WebSocket, for searchers to stream their bundles without the round trips of HTTP requests.
+func (s *PrivateTxBundleAPI) StreamBundle(ctx context.Context, args SendBundleArgs) (*BundleFeedback, error) {
+	if rpc.PeerInfoFromContext(ctx).Transport != "ws" {
+		return nil, errors.New("bundle streams are only served over WebSocket")
+	}
+	bundle, err := s.decodeBundle(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	feedback := &BundleFeedback{BundleHash: bundle.Hash}
+	if err := s.b.SendBundle(ctx, bundle.Txs, args.BlockNumber, args.MaxBlockNumber, bundle.Uuid, bundle.SigningAddress, bundle.MinTimestamp, bundle.MaxTimestamp, bundle.RevertingTxHashes); err != nil {
+		feedback.Error = err.Error()
+		return feedback, nil
+	}
+	feedback.Accepted = true
+
+	simulated, err := s.b.SimulateBundle(ctx, *bundle)
+	if err != nil {
+		feedback.Error = err.Error()
+		return feedback, nil
+	}
+	if simulated != nil {
+		feedback.GasUsed = hexutil.Uint64(simulated.TotalGasUsed)
+		feedback.MevGasPrice = (*hexutil.Big)(simulated.MevGasPrice)
+		feedback.EstimatedProfit = (*hexutil.Big)(simulated.TotalEth)
+	}
+	return feedback, nil
+}
+
+// CancelBundleArgs represents the arguments for a CancelBundle call.
+type CancelBundleArgs struct {
+	ReplacementUuid uuid.UUID       `json:"replacementUuid"`
+	SigningAddress  *common.Address `json:"signingAddress"`
+}
+
+// CancelBundle removes the bundles sent with the replacement uuid by the signer from the pool,
+// returns the number of bundles removed. A bundle already included in a block being built is not
+// removed from the block.
+func (s *PrivateTxBundleAPI) CancelBundle(ctx context.Context, args CancelBundleArgs) (int, error) {
+	if args.ReplacementUuid == types.EmptyUUID {
+		return 0, errors.New("bundle missing replacementUuid")
+	}
+	signingAddress, err := s.b.AuthenticateBundle(ctx, args.SigningAddress)
+	if err != nil {
+		return 0, err
+	}
+	return s.b.CancelBundle(ctx, args.ReplacementUuid, signingAddress), nil
+}
+
+// SendPrivateTransactionArgs represents the arguments for a SendPrivateTransaction call.
+type SendPrivateTransactionArgs struct {
+	Tx             hexutil.Bytes               `json:"tx"`
+	MaxBlockNumber *hexutil.Uint64             `json:"maxBlockNumber"`
+	Preferences    *types.PrivateTxPreferences `json:"preferences,omitempty"`
+}
+
+// SendPrivateTransaction adds a signed transaction to the pool which is never shared with the
+// peers, only this builder includes it. The transaction is dropped if not included by the max
+// block number, if any. The bundles including the transaction must follow its preferences,
+// a backrun only transaction can't be frontrun and a no sandwich one can't be sandwiched.
+func (s *PrivateTxBundleAPI) SendPrivateTransaction(ctx context.Context, args SendPrivateTransactionArgs) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(args.Tx); err != nil {
+		return common.Hash{}, err
+	}
+	var maxBlockNumber uint64
+	if args.MaxBlockNumber != nil {
+		maxBlockNumber = uint64(*args.MaxBlockNumber)
+		if current := s.b.CurrentBlock().Number.Uint64(); maxBlockNumber <= current {
+			return common.Hash{}, fmt.Errorf("maxBlockNumber %d not after the current block %d", maxBlockNumber, current)
+		}
+	}
+	if err := checkTxFee(tx.GasPrice(), tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+		return common.Hash{}, err
+	}
+	if !s.b.UnprotectedAllowed() && !tx.Protected() {
+		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
+	}
+	var prefs types.PrivateTxPreferences
+	if args.Preferences != nil {
+		prefs = *args.Preferences
+	}
+	if err := s.b.SendPrivateTx(ctx, tx, maxBlockNumber, prefs); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted private transaction", "hash", tx.Hash(), "maxBlock", maxBlockNumber, "backrunOnly", prefs.BackrunOnly, "noSandwich", prefs.NoSandwich)
+	return tx.Hash(), nil
+}
+
+// CancelPrivateTransactionArgs represents the arguments for a CancelPrivateTransaction call.
+type CancelPrivateTransactionArgs struct {
+	TxHash common.Hash `json:"txHash"`
+}
+
+// CancelPrivateTransaction removes a transaction sent with SendPrivateTransaction from the pool,
+// returns whether it was removed. The request must be signed by the sender of the transaction. A
+// transaction already included in a block being built is not removed from the block.
+func (s *PrivateTxBundleAPI) CancelPrivateTransaction(ctx context.Context, args CancelPrivateTransactionArgs) (bool, error) {
+	signer := rpc.PeerInfoFromContext(ctx).HTTP.Signer
+	if signer == (common.Address{}) {
+		return false, errors.New("cancellation must be signed by the sender of the transaction")
+	}
+	return s.b.CancelPrivateTx(ctx, args.TxHash, signer), nil
+}
+
+// SendEncryptedBundleArgs represents the arguments for a SendEncryptedBundle call.
+// The ciphertext holds the ECIES-encrypted JSON encoding of types.EncryptedMevBundleBody.
+type SendEncryptedBundleArgs struct {
+	Ciphertext     hexutil.Bytes   `json:"ciphertext"`
+	BlockNumber    rpc.BlockNumber `json:"blockNumber"`
+	SigningAddress *common.Address `json:"signingAddress"`
+	MinTimestamp   *uint64         `json:"minTimestamp"`
+	MaxTimestamp   *uint64         `json:"maxTimestamp"`
+}
+
+// SendEncryptedBundle will add a bundle encrypted to the key returned by BundleEncryptionKey to the pool.
+// The bundle stays encrypted until it is simulated for its target block, returns the hash of the ciphertext.
+// The request must be signed, the contents of the bundle can't be checked before it is decrypted.
+func (s *PrivateTxBundleAPI) SendEncryptedBundle(ctx context.Context, args SendEncryptedBundleArgs) (common.Hash, error) {
+	if len(args.Ciphertext) == 0 {
+		return common.Hash{}, errors.New("bundle missing ciphertext")
+	}
+	if args.BlockNumber == 0 {
+		return common.Hash{}, errors.New("bundle missing blockNumber")
+	}
+
+	signingAddress, err := s.b.AuthenticateBundle(ctx, args.SigningAddress)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	bundle := &types.EncryptedMevBundle{
+		BlockNumber:    big.NewInt(args.BlockNumber.Int64()),
+		Ciphertext:     args.Ciphertext,
+		SigningAddress: signingAddress,
+	}
+	if args.MinTimestamp != nil {
+		bundle.MinTimestamp = *args.MinTimestamp
+	}
+	if args.MaxTimestamp != nil {
+		bundle.MaxTimestamp = *args.MaxTimestamp
+	}
+
+	if err := s.b.SendEncryptedBundle(ctx, bundle); err != nil {
+		return common.Hash{}, err
+	}
+	return bundle.Hash(), nil
+}
+
+// UserOperationArgs represents an ERC-4337 user operation, in the layout of the v0.6 EntryPoint.
+type UserOperationArgs struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+// SendUserOperationBundleArgs represents the arguments for a SendUserOperationBundle call.
+type SendUserOperationBundleArgs struct {
+	UserOps        []UserOperationArgs `json:"userOps"`
+	EntryPoint     common.Address      `json:"entryPoint"`
+	BlockNumber    rpc.BlockNumber     `json:"blockNumber"`
+	SigningAddress *common.Address     `json:"signingAddress"`
+	MinTimestamp   *uint64             `json:"minTimestamp"`
+	MaxTimestamp   *uint64             `json:"maxTimestamp"`
+}
+
+// SendUserOperationBundle will add a bundle of ERC-4337 user operations to the pool. The builder
+// submits the operations of the bundle to the EntryPoint in a handleOps transaction of its own,
+// as their bundler, if they pass the validation of the EntryPoint. Returns the bundle hash.
+func (s *PrivateTxBundleAPI) SendUserOperationBundle(ctx context.Context, args SendUserOperationBundleArgs) (common.Hash, error) {
+	if len(args.UserOps) == 0 {
+		return common.Hash{}, errors.New("bundle missing userOps")
+	}
+	if args.BlockNumber == 0 {
+		return common.Hash{}, errors.New("bundle missing blockNumber")
+	}
+	if args.EntryPoint == (common.Address{}) {
+		return common.Hash{}, errors.New("bundle missing entryPoint")
+	}
+
+	ops := make([]types.UserOperation, 0, len(args.UserOps))
+	for i, op := range args.UserOps {
+		if op.Nonce == nil || op.CallGasLimit == nil || op.VerificationGasLimit == nil || op.PreVerificationGas == nil ||
+			op.MaxFeePerGas == nil || op.MaxPriorityFeePerGas == nil {
+			return common.Hash{}, fmt.Errorf("userOp %d missing nonce, gas limits or fees", i)
+		}
+		ops = append(ops, types.UserOperation{
+			Sender:               op.Sender,
+			Nonce:                op.Nonce.ToInt(),
+			InitCode:             op.InitCode,
+			CallData:             op.CallData,
+			CallGasLimit:         op.CallGasLimit.ToInt(),
+			VerificationGasLimit: op.VerificationGasLimit.ToInt(),
+			PreVerificationGas:   op.PreVerificationGas.ToInt(),
+			MaxFeePerGas:         op.MaxFeePerGas.ToInt(),
+			MaxPriorityFeePerGas: op.MaxPriorityFeePerGas.ToInt(),
+			PaymasterAndData:     op.PaymasterAndData,
+			Signature:            op.Signature,
+		})
+	}
+
+	signingAddress, err := s.b.AuthenticateBundle(ctx, args.SigningAddress)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	bundle := &types.UserOperationBundle{
+		EntryPoint:     args.EntryPoint,
+		Ops:            ops,
+		BlockNumber:    big.NewInt(args.BlockNumber.Int64()),
+		SigningAddress: signingAddress,
+	}
+	if args.MinTimestamp != nil {
+		bundle.MinTimestamp = *args.MinTimestamp
+	}
+	if args.MaxTimestamp != nil {
+		bundle.MaxTimestamp = *args.MaxTimestamp
+	}
+
+	if err := s.b.SendUserOperationBundle(ctx, bundle); err != nil {
+		return common.Hash{}, err
+	}
+	return bundle.Hash(), nil
+}
+
+// BundleEncryptionKey returns the uncompressed secp256k1 public key bundles should be encrypted to.
+func (s *PrivateTxBundleAPI) BundleEncryptionKey() (hexutil.Bytes, error) {
+	pub := s.b.BundleEncryptionKey()
+	if pub == nil {
+		return nil, errors.New("encrypted bundles are not enabled")
+	}
+	return crypto.FromECDSAPub(pub.ExportECDSA()), nil
 }
 
 // BundleAPI offers an API for accepting bundled transactions
@@ -2339,6 +2621,9 @@ func (s *BundleAPI) CallBundle(ctx context.Context, args CallBundleArgs) (map[st
 			revert := result.Revert()
 			if len(revert) > 0 {
 				jsonResult["revert"] = string(revert)
+				if reason, err := abi.UnpackRevert(revert); err == nil {
+					jsonResult["revertReason"] = reason
+				}
 			}
 		} else {
 			dst := make([]byte, hex.EncodedLen(len(result.Return())))
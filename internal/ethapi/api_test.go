@@ -17,14 +17,19 @@
 package ethapi
 
 import (
+	"context"
 	"encoding/json"
 	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 func TestTransaction_RoundTripRpcJSON(t *testing.T) {
@@ -157,3 +162,100 @@ func allTransactionTypes(addr common.Address, config *params.ChainConfig) []type
 		},
 	}
 }
+
+// callBundleBackend serves the state of the bundle simulations.
+type callBundleBackend struct {
+	*backendMock
+	state *state.StateDB
+}
+
+func (b *callBundleBackend) StateAndHeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*state.StateDB, *types.Header, error) {
+	return b.state, b.current, nil
+}
+
+func TestCallBundle(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		sender   = crypto.PubkeyToAddress(key.PublicKey)
+		coinbase = common.HexToAddress("0xc0")
+		reverter = common.HexToAddress("0x10")
+		config   = params.AllEthashProtocolChanges
+	)
+	// the reverter reverts with Error("nope"), copied from the end of its code
+	revert := append(crypto.Keccak256([]byte("Error(string)"))[:4], common.LeftPadBytes([]byte{0x20}, 32)...)
+	revert = append(revert, common.LeftPadBytes([]byte{4}, 32)...)
+	revert = append(revert, common.RightPadBytes([]byte("nope"), 32)...)
+	code := append(common.FromHex("0x6064600c60003960646000fd"), revert...)
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetBalance(sender, big.NewInt(params.Ether))
+	statedb.SetCode(reverter, code)
+	backend := &callBundleBackend{
+		backendMock: &backendMock{
+			current: &types.Header{Number: big.NewInt(10), GasLimit: 30_000_000, Difficulty: big.NewInt(1)},
+			config:  config,
+		},
+		state: statedb,
+	}
+	api := NewBundleAPI(backend, nil)
+
+	signer := types.LatestSigner(config)
+	tx := func(nonce uint64, to common.Address, value int64, gas uint64) hexutil.Bytes {
+		enc, err := types.MustSignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   config.ChainID,
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(2),
+			GasFeeCap: big.NewInt(10),
+			Gas:       gas,
+			To:        &to,
+			Value:     big.NewInt(value),
+		}).MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return enc
+	}
+	coinbaseHex := coinbase.Hex()
+	res, err := api.CallBundle(context.Background(), CallBundleArgs{
+		Txs:                    []hexutil.Bytes{tx(0, coinbase, 1000, params.TxGas), tx(1, reverter, 0, 100_000)},
+		BlockNumber:            11,
+		StateBlockNumberOrHash: rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber),
+		Coinbase:               &coinbaseHex,
+		BaseFee:                big.NewInt(1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := res["results"].([]map[string]interface{})
+	if len(results) != 2 {
+		t.Fatalf("unexpected results %v", results)
+	}
+	// the payment pays the tip of 2 wei per gas and 1000 wei to the coinbase
+	if gasUsed := results[0]["gasUsed"].(uint64); gasUsed != params.TxGas {
+		t.Errorf("unexpected payment gas %d", gasUsed)
+	}
+	if diff := results[0]["coinbaseDiff"]; diff != "43000" || results[0]["ethSentToCoinbase"] != "1000" {
+		t.Errorf("unexpected payment coinbase diff %v, sent %v", diff, results[0]["ethSentToCoinbase"])
+	}
+	// the reverting tx is charged for its gas and reports the revert reason
+	revertGas := results[1]["gasUsed"].(uint64)
+	if revertGas <= params.TxGas || revertGas >= 100_000 {
+		t.Errorf("unexpected reverted tx gas %d", revertGas)
+	}
+	if results[1]["revertReason"] != "nope" || results[1]["error"] != "execution reverted" {
+		t.Errorf("unexpected revert %v: %v", results[1]["error"], results[1]["revertReason"])
+	}
+	if fees := results[1]["gasFees"]; fees != new(big.Int).SetUint64(2*revertGas).String() || results[1]["ethSentToCoinbase"] != "0" {
+		t.Errorf("unexpected reverted tx fees %v", fees)
+	}
+
+	totalGas := params.TxGas + revertGas
+	coinbaseDiff := 2*totalGas + 1000
+	if res["totalGasUsed"] != totalGas || res["coinbaseDiff"] != new(big.Int).SetUint64(coinbaseDiff).String() {
+		t.Errorf("unexpected bundle gas %v, coinbase diff %v", res["totalGasUsed"], res["coinbaseDiff"])
+	}
+	if price := res["bundleGasPrice"]; price != new(big.Int).SetUint64(coinbaseDiff/totalGas).String() {
+		t.Errorf("unexpected bundle gas price %v", price)
+	}
+}
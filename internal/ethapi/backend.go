@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
@@ -76,7 +77,15 @@ type Backend interface {
 
 	// Transaction pool API
 	SendTx(ctx context.Context, signedTx *types.Transaction, private bool) error
-	SendBundle(ctx context.Context, txs types.Transactions, blockNumber rpc.BlockNumber, uuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash) error
+	SendPrivateTx(ctx context.Context, signedTx *types.Transaction, maxBlockNumber uint64, prefs types.PrivateTxPreferences) error
+	CancelPrivateTx(ctx context.Context, txHash common.Hash, from common.Address) bool
+	SendBundle(ctx context.Context, txs types.Transactions, blockNumber, maxBlockNumber rpc.BlockNumber, uuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash) error
+	AuthenticateBundle(ctx context.Context, signingAddress *common.Address) (common.Address, error)
+	CancelBundle(ctx context.Context, uuid uuid.UUID, signingAddress common.Address) int
+	SimulateBundle(ctx context.Context, bundle types.MevBundle) (*types.SimulatedBundle, error)
+	SendEncryptedBundle(ctx context.Context, bundle *types.EncryptedMevBundle) error
+	BundleEncryptionKey() *ecies.PublicKey
+	SendUserOperationBundle(ctx context.Context, bundle *types.UserOperationBundle) error
 	SendSBundle(ctx context.Context, sbundle *types.SBundle) error
 	CancelSBundles(ctx context.Context, hashes []common.Hash)
 	GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error)
@@ -27,6 +27,7 @@ var (
 	ErrBundleTooLarge   = errors.New("bundle too large")
 	ErrInvalidValidity  = errors.New("invalid validity")
 	ErrInvalidInclusion = errors.New("invalid inclusion")
+	ErrInvalidBody      = errors.New("invalid body")
 )
 
 type MevAPI struct {
@@ -111,7 +112,10 @@ func parseBundleInner(level int, args *SendMevBundleArgs) (bundle types.SBundle,
 		return bundle, ErrInvalidInclusion
 	}
 
-	if len(bundle.Body) > maxBodySize {
+	if len(args.Body) == 0 {
+		return bundle, ErrInvalidBody
+	}
+	if len(args.Body) > maxBodySize {
 		return bundle, ErrBundleTooLarge
 	}
 
@@ -134,6 +138,8 @@ func parseBundleInner(level int, args *SendMevBundleArgs) (bundle types.SBundle,
 				return bundle, err
 			}
 			bundle.Body[i].Bundle = &innerBundle
+		} else {
+			return bundle, ErrInvalidBody
 		}
 	}
 
@@ -172,6 +178,9 @@ func (api *MevAPI) SendBundle(ctx context.Context, args SendMevBundleArgs) error
 	if err != nil {
 		return err
 	}
+	if _, err := api.b.AuthenticateBundle(ctx, nil); err != nil {
+		return err
+	}
 	go api.b.SendSBundle(ctx, &bundle)
 	return nil
 }
@@ -0,0 +1,42 @@
+package ethapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestParseSBundleArgsBody(t *testing.T) {
+	tx, err := types.NewTransaction(0, common.Address{0x01}, common.Big1, 21000, common.Big1, nil).MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode tx: %v", err)
+	}
+	txBody := MevBundleBody{Tx: (*hexutil.Bytes)(&tx)}
+	args := func(body ...MevBundleBody) *SendMevBundleArgs {
+		return &SendMevBundleArgs{Version: "v0.1", Inclusion: MevBundleInclusion{BlockNumber: 1}, Body: body}
+	}
+	oversized := make([]MevBundleBody, maxBodySize+1)
+	for i := range oversized {
+		oversized[i] = txBody
+	}
+	tests := []struct {
+		name string
+		args *SendMevBundleArgs
+		err  error
+	}{
+		{"tx", args(txBody), nil},
+		{"nested", args(txBody, MevBundleBody{Bundle: args(txBody)}), nil},
+		{"empty", args(), ErrInvalidBody},
+		{"empty element", args(txBody, MevBundleBody{CanRevert: true}), ErrInvalidBody},
+		{"empty nested", args(MevBundleBody{Bundle: args()}), ErrInvalidBody},
+		{"too large", args(oversized...), ErrBundleTooLarge},
+	}
+	for _, test := range tests {
+		if _, err := ParseSBundleArgs(test.args); !errors.Is(err, test.err) {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.err, err)
+		}
+	}
+}
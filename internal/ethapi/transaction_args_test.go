@@ -34,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
@@ -217,7 +218,34 @@ func (b *backendMock) SendMegabundle(ctx context.Context, txs types.Transactions
 	return nil
 }
 
-func (b *backendMock) SendBundle(ctx context.Context, txs types.Transactions, blockNumber rpc.BlockNumber, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
+func (b *backendMock) SendBundle(ctx context.Context, txs types.Transactions, blockNumber, maxBlockNumber rpc.BlockNumber, replacementUuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
+	return nil
+}
+
+func (b *backendMock) AuthenticateBundle(ctx context.Context, signingAddress *common.Address) (common.Address, error) {
+	if signingAddress != nil {
+		return *signingAddress, nil
+	}
+	return common.Address{}, nil
+}
+
+func (b *backendMock) CancelBundle(ctx context.Context, uuid uuid.UUID, signingAddress common.Address) int {
+	return 0
+}
+
+func (b *backendMock) SimulateBundle(ctx context.Context, bundle types.MevBundle) (*types.SimulatedBundle, error) {
+	return nil, nil
+}
+
+func (b *backendMock) SendEncryptedBundle(ctx context.Context, bundle *types.EncryptedMevBundle) error {
+	return nil
+}
+
+func (b *backendMock) BundleEncryptionKey() *ecies.PublicKey {
+	return nil
+}
+
+func (b *backendMock) SendUserOperationBundle(ctx context.Context, bundle *types.UserOperationBundle) error {
 	return nil
 }
 
@@ -334,6 +362,12 @@ func (b *backendMock) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) eve
 func (b *backendMock) SendTx(ctx context.Context, signedTx *types.Transaction, private bool) error {
 	return nil
 }
+func (b *backendMock) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, maxBlockNumber uint64, prefs types.PrivateTxPreferences) error {
+	return nil
+}
+func (b *backendMock) CancelPrivateTx(ctx context.Context, txHash common.Hash, from common.Address) bool {
+	return false
+}
 func (b *backendMock) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
 	return nil, [32]byte{}, 0, 0, nil
 }
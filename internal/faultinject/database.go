@@ -0,0 +1,37 @@
+package faultinject
+
+import "github.com/ethereum/go-ethereum/ethdb"
+
+// WrapDatabase returns the database with the slow disk fault injected in its reads and
+// writes. The database is returned as is without the faultinject build tag.
+func WrapDatabase(db ethdb.Database) ethdb.Database {
+	if !Enabled {
+		return db
+	}
+	return &slowDatabase{Database: db}
+}
+
+// slowDatabase is a database whose reads and writes are delayed by the slow disk fault.
+type slowDatabase struct {
+	ethdb.Database
+}
+
+func (db *slowDatabase) Has(key []byte) (bool, error) {
+	Trigger(SlowDisk)
+	return db.Database.Has(key)
+}
+
+func (db *slowDatabase) Get(key []byte) ([]byte, error) {
+	Trigger(SlowDisk)
+	return db.Database.Get(key)
+}
+
+func (db *slowDatabase) Put(key []byte, value []byte) error {
+	Trigger(SlowDisk)
+	return db.Database.Put(key, value)
+}
+
+func (db *slowDatabase) Delete(key []byte) error {
+	Trigger(SlowDisk)
+	return db.Database.Delete(key)
+}
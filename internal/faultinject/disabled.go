@@ -0,0 +1,37 @@
+//go:build !faultinject
+
+package faultinject
+
+import "errors"
+
+// Enabled is true if the faults are compiled in.
+const Enabled = false
+
+// Configure enables the faults of the spec, see Parse. It fails for a non-empty spec, as the
+// faults are not compiled in.
+func Configure(spec string) error {
+	faults, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	if len(faults) > 0 {
+		return errors.New("fault injection requires a build with the faultinject tag")
+	}
+	return nil
+}
+
+// Set enables a kind of fault, it is a no-op without the faultinject build tag.
+func Set(kind Kind, fault Fault) {}
+
+// Clear disables a kind of fault, it is a no-op without the faultinject build tag.
+func Clear(kind Kind) {}
+
+// Reset disables all the faults, it is a no-op without the faultinject build tag.
+func Reset() {}
+
+// Triggered returns the number of calls a kind of fault triggered on, always 0 without the
+// faultinject build tag.
+func Triggered(kind Kind) uint64 { return 0 }
+
+// Trigger is the hook of a kind of fault, it never triggers without the faultinject build tag.
+func Trigger(kind Kind) bool { return false }
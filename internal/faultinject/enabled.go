@@ -0,0 +1,95 @@
+//go:build faultinject
+
+package faultinject
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Enabled is true if the faults are compiled in.
+const Enabled = true
+
+// injector is the state of a kind of fault.
+type injector struct {
+	fault     Fault
+	calls     uint64 // Calls of the hook since the fault was set
+	triggered uint64
+}
+
+var (
+	mu        sync.Mutex
+	injectors [numKinds]*injector
+)
+
+// Configure enables the faults of the spec, see Parse, replacing the current faults.
+func Configure(spec string) error {
+	faults, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	Reset()
+	for kind, fault := range faults {
+		Set(kind, fault)
+		log.Warn("Fault injection enabled", "fault", kind, "rate", fault.Rate, "delay", fault.Delay)
+	}
+	return nil
+}
+
+// Set enables a kind of fault, restarting its count of calls.
+func Set(kind Kind, fault Fault) {
+	mu.Lock()
+	defer mu.Unlock()
+	injectors[kind] = &injector{fault: fault}
+}
+
+// Clear disables a kind of fault.
+func Clear(kind Kind) {
+	mu.Lock()
+	defer mu.Unlock()
+	injectors[kind] = nil
+}
+
+// Reset disables all the faults.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	injectors = [numKinds]*injector{}
+}
+
+// Triggered returns the number of calls a kind of fault triggered on since it was set.
+func Triggered(kind Kind) uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+	if injectors[kind] == nil {
+		return 0
+	}
+	return injectors[kind].triggered
+}
+
+// Trigger is the hook of a kind of fault. It returns whether the fault triggers on this call,
+// after waiting for the delay of the fault.
+func Trigger(kind Kind) bool {
+	mu.Lock()
+	inj := injectors[kind]
+	if inj == nil {
+		mu.Unlock()
+		return false
+	}
+	// trigger whenever the rate crosses an integer, spreading the triggered calls evenly
+	n := inj.calls
+	inj.calls++
+	triggered := uint64(float64(n+1)*inj.fault.Rate) > uint64(float64(n)*inj.fault.Rate)
+	if triggered {
+		inj.triggered++
+	}
+	delay := inj.fault.Delay
+	mu.Unlock()
+
+	if triggered && delay > 0 {
+		time.Sleep(delay)
+	}
+	return triggered
+}
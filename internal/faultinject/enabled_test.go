@@ -0,0 +1,63 @@
+//go:build faultinject
+
+package faultinject
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+)
+
+func TestTrigger(t *testing.T) {
+	defer Reset()
+
+	if Trigger(RelayTimeout) {
+		t.Fatal("fault triggered before it was set")
+	}
+	if err := Configure("relay_timeout:0.25,beacon_outage"); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+	var triggered []int
+	for i := 0; i < 8; i++ {
+		if Trigger(RelayTimeout) {
+			triggered = append(triggered, i)
+		}
+		if !Trigger(BeaconOutage) {
+			t.Errorf("call %d: beacon outage not triggered", i)
+		}
+	}
+	if len(triggered) != 2 || triggered[0] != 3 || triggered[1] != 7 {
+		t.Errorf("relay timeout triggered on calls %v, want [3 7]", triggered)
+	}
+	if n := Triggered(RelayTimeout); n != 2 {
+		t.Errorf("triggered count mismatch: have %d, want 2", n)
+	}
+
+	Clear(BeaconOutage)
+	if Trigger(BeaconOutage) {
+		t.Error("cleared fault triggered")
+	}
+}
+
+func TestSlowDatabase(t *testing.T) {
+	defer Reset()
+
+	db := WrapDatabase(rawdb.NewMemoryDatabase())
+	if err := db.Put([]byte("key"), []byte("value")); err != nil {
+		t.Fatal(err)
+	}
+	Set(SlowDisk, Fault{Rate: 1})
+	if value, err := db.Get([]byte("key")); err != nil || string(value) != "value" {
+		t.Fatalf("get mismatch: have %q %v, want value", value, err)
+	}
+	if ok, err := db.Has([]byte("key")); err != nil || !ok {
+		t.Fatalf("has mismatch: have %v %v, want true", ok, err)
+	}
+	if n := Triggered(SlowDisk); n != 2 {
+		t.Errorf("triggered count mismatch: have %d, want 2", n)
+	}
+	if !errors.Is(ErrTimeout, ErrInjected) {
+		t.Error("timeout is not an injected fault")
+	}
+}
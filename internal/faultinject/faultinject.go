@@ -0,0 +1,116 @@
+// Package faultinject injects failures into the builder dependencies to test its resilience.
+//
+// Faults are only compiled in with the faultinject build tag:
+//
+//	go build -tags faultinject ./cmd/geth
+//
+// and are enabled with --builder.faults, or with Set in tests. Without the tag every hook is
+// a no-op. Faults trigger on a deterministic share of the calls of their hook, a fault with
+// a rate of 0.25 triggers on every fourth call, so their effect is reproducible.
+package faultinject
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is a kind of fault.
+type Kind int
+
+const (
+	// RelayTimeout makes the requests to the remote relays time out
+	RelayTimeout Kind = iota
+	// BeaconOutage makes the requests to the beacon nodes fail
+	BeaconOutage
+	// SlowDisk delays the reads and writes of the chain database
+	SlowDisk
+	// SnapshotInvalidation invalidates the multi-transaction state snapshots when they are taken
+	SnapshotInvalidation
+
+	numKinds
+)
+
+var kindNames = [numKinds]string{
+	RelayTimeout:         "relay_timeout",
+	BeaconOutage:         "beacon_outage",
+	SlowDisk:             "slow_disk",
+	SnapshotInvalidation: "snapshot_invalidation",
+}
+
+func (k Kind) String() string {
+	if k < 0 || k >= numKinds {
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+	return kindNames[k]
+}
+
+var (
+	// ErrInjected is the error returned by the failing hooks.
+	ErrInjected = errors.New("injected fault")
+	// ErrTimeout is the error returned by the hooks simulating a timeout.
+	ErrTimeout = fmt.Errorf("%w: timeout", ErrInjected)
+)
+
+// Fault is the configuration of a kind of fault.
+type Fault struct {
+	Rate  float64       // Share of the calls the fault triggers on, from 0 to 1
+	Delay time.Duration // Delay of the triggered calls
+}
+
+// defaultSlowDiskDelay is the delay of the slow disk fault if none is given
+const defaultSlowDiskDelay = 10 * time.Millisecond
+
+// Parse parses a comma separated list of faults, each given as name[:rate][:delay], e.g.
+// "relay_timeout:0.5:2s,beacon_outage,slow_disk:5ms". The rate defaults to 1.
+func Parse(spec string) (map[Kind]Fault, error) {
+	faults := make(map[Kind]Fault)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		kind, err := parseKind(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := faults[kind]; ok {
+			return nil, fmt.Errorf("duplicate fault %s", kind)
+		}
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("invalid fault %q, expected name[:rate][:delay]", item)
+		}
+		fault := Fault{Rate: 1}
+		if kind == SlowDisk {
+			fault.Delay = defaultSlowDiskDelay
+		}
+		for _, param := range parts[1:] {
+			if delay, err := time.ParseDuration(param); err == nil {
+				if delay < 0 {
+					return nil, fmt.Errorf("invalid fault %q, negative delay", item)
+				}
+				fault.Delay = delay
+				continue
+			}
+			rate, err := strconv.ParseFloat(param, 64)
+			if err != nil || rate < 0 || rate > 1 {
+				return nil, fmt.Errorf("invalid fault %q, expected a rate from 0 to 1 or a delay, got %q", item, param)
+			}
+			fault.Rate = rate
+		}
+		faults[kind] = fault
+	}
+	return faults, nil
+}
+
+func parseKind(name string) (Kind, error) {
+	for kind, kindName := range kindNames {
+		if kindName == name {
+			return Kind(kind), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown fault %q, expected one of %s", name, strings.Join(kindNames[:], ", "))
+}
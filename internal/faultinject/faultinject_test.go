@@ -0,0 +1,38 @@
+package faultinject
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	faults, err := Parse("relay_timeout:0.5:2s, beacon_outage,slow_disk,snapshot_invalidation:0.1")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	want := map[Kind]Fault{
+		RelayTimeout:         {Rate: 0.5, Delay: 2 * time.Second},
+		BeaconOutage:         {Rate: 1},
+		SlowDisk:             {Rate: 1, Delay: defaultSlowDiskDelay},
+		SnapshotInvalidation: {Rate: 0.1},
+	}
+	if !reflect.DeepEqual(faults, want) {
+		t.Errorf("faults mismatch: have %v, want %v", faults, want)
+	}
+
+	if faults, err := Parse(""); err != nil || len(faults) != 0 {
+		t.Errorf("empty spec: have %v %v, want no faults", faults, err)
+	}
+	for _, spec := range []string{
+		"unknown",
+		"relay_timeout:2",
+		"relay_timeout:-1s",
+		"relay_timeout:0.5:1s:1",
+		"slow_disk,slow_disk:1ms",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
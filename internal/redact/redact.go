@@ -0,0 +1,99 @@
+// Package redact keeps the contents of bundles out of logs and crash reports.
+//
+// Bundles are private until they are included in a block, so by default only their hashes
+// and metadata are logged. Operators can log full contents for a limited period to debug.
+package redact
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Redacted replaces values whose contents must not be logged.
+const Redacted = "<redacted>"
+
+// fullContentUntil is the unix time in nanoseconds until which full contents are logged
+var fullContentUntil atomic.Int64
+
+// EnableFullContent logs full contents for the given period, a non-positive period restores redaction.
+func EnableFullContent(period time.Duration) {
+	if period <= 0 {
+		fullContentUntil.Store(0)
+		return
+	}
+	fullContentUntil.Store(time.Now().Add(period).UnixNano())
+}
+
+// FullContent reports whether full contents are currently logged.
+func FullContent() bool {
+	return time.Now().UnixNano() < fullContentUntil.Load()
+}
+
+// TxSummary is the metadata of a transaction that is safe to log.
+type TxSummary struct {
+	Hash  common.Hash
+	Type  uint8
+	Nonce uint64
+	Gas   uint64
+	Size  uint64
+}
+
+// BundleSummary is the metadata of a bundle that is safe to log.
+type BundleSummary struct {
+	Hash           common.Hash
+	BlockNumber    uint64
+	SigningAddress common.Address
+	Txs            []common.Hash
+}
+
+// Value returns v if full contents are logged, otherwise a placeholder.
+func Value(v interface{}) interface{} {
+	if FullContent() {
+		return v
+	}
+	return Redacted
+}
+
+// Tx returns the encoded transaction if full contents are logged, otherwise its metadata.
+func Tx(tx *types.Transaction) interface{} {
+	if FullContent() {
+		if enc, err := tx.MarshalBinary(); err == nil {
+			return hexutil.Bytes(enc)
+		}
+	}
+	return TxSummary{Hash: tx.Hash(), Type: tx.Type(), Nonce: tx.Nonce(), Gas: tx.Gas(), Size: tx.Size()}
+}
+
+// Bundle returns the bundle if full contents are logged, otherwise its metadata.
+func Bundle(bundle *types.MevBundle) interface{} {
+	if FullContent() {
+		return bundle
+	}
+	summary := BundleSummary{
+		Hash:           bundle.Hash,
+		SigningAddress: bundle.SigningAddress,
+		Txs:            make([]common.Hash, len(bundle.Txs)),
+	}
+	if bundle.BlockNumber != nil {
+		summary.BlockNumber = bundle.BlockNumber.Uint64()
+	}
+	for i, tx := range bundle.Txs {
+		summary.Txs[i] = tx.Hash()
+	}
+	return summary
+}
+
+// Panic returns a recovered panic value that is safe to log. Runtime errors have fixed
+// messages and are kept, other values may be built from the data being processed.
+func Panic(v interface{}) interface{} {
+	if _, ok := v.(runtime.Error); ok || FullContent() {
+		return v
+	}
+	return fmt.Sprintf("%s panic of type %T", Redacted, v)
+}
@@ -0,0 +1,60 @@
+package redact
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestRedact(t *testing.T) {
+	defer EnableFullContent(0)
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, Gas: 21000, Data: []byte{0xde, 0xad, 0xbe, 0xef}})
+	bundle := &types.MevBundle{Txs: types.Transactions{tx}, BlockNumber: big.NewInt(10), Hash: common.Hash{1}}
+
+	if FullContent() {
+		t.Fatal("full contents must not be logged by default")
+	}
+	if v := Value(tx.Data()); v != Redacted {
+		t.Errorf("value not redacted: %v", v)
+	}
+	if v, ok := Tx(tx).(TxSummary); !ok || v.Hash != tx.Hash() || v.Nonce != 1 {
+		t.Errorf("unexpected tx summary: %v", v)
+	}
+	if v, ok := Bundle(bundle).(BundleSummary); !ok || v.Hash != bundle.Hash || v.BlockNumber != 10 || v.Txs[0] != tx.Hash() {
+		t.Errorf("unexpected bundle summary: %v", v)
+	}
+	if v := Panic(errors.New("calldata 0xdeadbeef")); v != "<redacted> panic of type *errors.errorString" {
+		t.Errorf("panic not redacted: %v", v)
+	}
+	var runtimeErr error
+	func() {
+		defer func() { runtimeErr = recover().(error) }()
+		var m map[string]int
+		m["x"] = 1
+	}()
+	if v := Panic(runtimeErr); v != runtimeErr {
+		t.Errorf("runtime error redacted: %v", v)
+	}
+
+	EnableFullContent(time.Minute)
+	if !FullContent() {
+		t.Fatal("full contents not enabled")
+	}
+	if v, ok := Tx(tx).(hexutil.Bytes); !ok || len(v) != int(tx.Size()) {
+		t.Errorf("unexpected tx %v", v)
+	}
+	if v := Bundle(bundle); v != bundle {
+		t.Errorf("unexpected bundle %v", v)
+	}
+
+	EnableFullContent(0)
+	if FullContent() {
+		t.Fatal("full contents not disabled")
+	}
+}
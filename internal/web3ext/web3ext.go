@@ -692,10 +692,57 @@ web3._extend({
 			call: 'miner_setRecommitInterval',
 			params: 1,
 		}),
+		new web3._extend.Method({
+			name: 'setBidPolicy',
+			call: 'miner_setBidPolicy',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'setTopOfBlockReservation',
+			call: 'miner_setTopOfBlockReservation',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'topOfBlockReservation',
+			call: 'miner_topOfBlockReservation'
+		}),
 		new web3._extend.Method({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
 		}),
+		new web3._extend.Method({
+			name: 'profitReport',
+			call: 'miner_profitReport'
+		}),
+		new web3._extend.Method({
+			name: 'searcherAnalytics',
+			call: 'miner_searcherAnalytics',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'bundleSourceAnalytics',
+			call: 'miner_bundleSourceAnalytics',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'searcherReputations',
+			call: 'miner_searcherReputations',
+		}),
+		new web3._extend.Method({
+			name: 'reorgReports',
+			call: 'miner_reorgReports',
+		}),
+		new web3._extend.Method({
+			name: 'blockValueReport',
+			call: 'miner_blockValueReport',
+		}),
+		new web3._extend.Method({
+			name: 'backtest',
+			call: 'miner_backtest',
+			params: 1,
+		}),
 	],
 	properties: []
 });
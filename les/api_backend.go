@@ -24,6 +24,7 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/builder/searcherauth"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
@@ -32,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -198,12 +200,44 @@ func (b *LesApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction,
 	return b.eth.txPool.Add(ctx, signedTx)
 }
 
+func (b *LesApiBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, maxBlockNumber uint64, prefs types.PrivateTxPreferences) error {
+	return errors.New("private transactions are not supported by light clients")
+}
+
+func (b *LesApiBackend) CancelPrivateTx(ctx context.Context, txHash common.Hash, from common.Address) bool {
+	return false
+}
+
 func (b *LesApiBackend) RemoveTx(txHash common.Hash) {
 	b.eth.txPool.RemoveTx(txHash)
 }
 
-func (b *LesApiBackend) SendBundle(ctx context.Context, txs types.Transactions, blockNumber rpc.BlockNumber, uuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
-	return b.eth.txPool.AddMevBundle(txs, big.NewInt(blockNumber.Int64()), uuid, signingAddress, minTimestamp, maxTimestamp, revertingTxHashes)
+func (b *LesApiBackend) SendBundle(ctx context.Context, txs types.Transactions, blockNumber, maxBlockNumber rpc.BlockNumber, uuid uuid.UUID, signingAddress common.Address, minTimestamp uint64, maxTimestamp uint64, revertingTxHashes []common.Hash) error {
+	return b.eth.txPool.AddMevBundle(txs, big.NewInt(blockNumber.Int64()), nil, uuid, signingAddress, minTimestamp, maxTimestamp, revertingTxHashes)
+}
+
+func (b *LesApiBackend) AuthenticateBundle(ctx context.Context, signingAddress *common.Address) (common.Address, error) {
+	return searcherauth.Identity(rpc.PeerInfoFromContext(ctx).HTTP.Signer, signingAddress)
+}
+
+func (b *LesApiBackend) CancelBundle(ctx context.Context, uuid uuid.UUID, signingAddress common.Address) int {
+	return 0
+}
+
+func (b *LesApiBackend) SimulateBundle(ctx context.Context, bundle types.MevBundle) (*types.SimulatedBundle, error) {
+	return nil, errors.New("bundle simulation is not supported by light clients")
+}
+
+func (b *LesApiBackend) SendEncryptedBundle(ctx context.Context, bundle *types.EncryptedMevBundle) error {
+	return errors.New("encrypted bundles are not supported by light clients")
+}
+
+func (b *LesApiBackend) BundleEncryptionKey() *ecies.PublicKey {
+	return nil
+}
+
+func (b *LesApiBackend) SendUserOperationBundle(ctx context.Context, bundle *types.UserOperationBundle) error {
+	return errors.New("user operation bundles are not supported by light clients")
 }
 
 func (b *LesApiBackend) SendSBundle(ctx context.Context, sbundle *types.SBundle) error {
@@ -0,0 +1,15 @@
+package miner
+
+import (
+	"github.com/ethereum/go-ethereum/builder/alerting"
+)
+
+// alertSnapshotInvalidated raises an alert when an operation on the multi-tx snapshot stack of
+// a block build fails, the build falls back to its input environment
+func alertSnapshotInvalidated(algo AlgoType, op string, err error) {
+	alerting.Fire(alerting.KindSnapshotInvalidated, "Multi-tx snapshot stack invalidated", map[string]string{
+		"algorithm": algo.String(),
+		"operation": op,
+		"error":     err.Error(),
+	})
+}
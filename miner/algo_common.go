@@ -1,7 +1,6 @@
 package miner
 
 import (
-	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"math/big"
@@ -13,6 +12,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -38,6 +38,14 @@ var (
 		ProfitThresholdPercent: defaultProfitThresholdPercent,
 		PriceCutoffPercent:     defaultPriceCutoffPercent,
 	}
+
+	// defaultSimulationLimits keep pathological bundles from causing memory spikes during simulation,
+	// they are well above what any legitimate transaction needs
+	defaultSimulationLimits = vm.SandboxLimits{
+		MaxMemorySize:          4 * 1024 * 1024,
+		MaxReturnDataSize:      1024 * 1024,
+		MaxPrecompileInputSize: 1024 * 1024,
+	}
 )
 
 var emptyCodeHash = common.HexToHash("c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470")
@@ -46,6 +54,7 @@ var (
 	ErrMevGasPriceNotSet = errors.New("mev gas price not set")
 	errInterrupt         = errors.New("miner worker interrupted")
 	errNoPrivateKey      = errors.New("no private key provided")
+	errBundleTxReverted  = errors.New("bundle tx revert")
 )
 
 // lowProfitError is returned when an order is not committed due to low profit or low effective gas price
@@ -81,6 +90,20 @@ type algorithmConfig struct {
 	// is 10 (i.e. 10%), then the minimum effective gas price included in the same bucket as the top transaction
 	// is (1000 * 10%) = 100 wei.
 	PriceCutoffPercent int
+	// Griefing records bundles whose simulated profit vanishes on commit, nil if griefing detection is disabled
+	Griefing *griefingTracker
+	// MaxStateGrowth is the maximum state growth of the bundles committed to a block, 0 = unlimited
+	MaxStateGrowth uint64
+	// Decisions records the outcome of every bundle and sbundle commit, nil if the decision log and the build trace are disabled
+	Decisions *decisionRecorder
+	// TxPreferences returns the preferences of the private transactions on the bundles including them, nil if they are not enforced
+	TxPreferences txPreferencesFn
+}
+
+// blockBuilder is a block building algorithm, packing the simulated bundles, sbundles and
+// transactions into the environment it was created with.
+type blockBuilder interface {
+	buildBlock(simBundles []types.SimulatedBundle, simSBundles []*types.SimSBundle, transactions map[common.Address]types.Transactions) (*environment, []types.SimulatedBundle, []types.UsedSBundle)
 }
 
 type chainData struct {
@@ -101,7 +124,7 @@ type PayoutTransactionParams struct {
 	SenderBalance *big.Int
 	SenderNonce   uint64
 	Signer        types.Signer
-	PrivateKey    *ecdsa.PrivateKey
+	TxSigner      TxSigner
 }
 
 type (
@@ -150,6 +173,29 @@ func ValidateGasPriceAndProfit(algoConf algorithmConfig, actualPrice, expectedPr
 	return nil
 }
 
+// checkBundleReverts checks the receipts of the transactions of the bundles, committed in order,
+// against the transactions each bundle allows to revert.
+func checkBundleReverts(bundles []types.SimulatedBundle, receipts []*types.Receipt) error {
+	i := 0
+	for _, bundle := range bundles {
+		for _, tx := range bundle.OriginalBundle.Txs {
+			if i >= len(receipts) {
+				return fmt.Errorf("missing receipt of bundle tx %s", tx.Hash())
+			}
+			receipt := receipts[i]
+			i++
+			if receipt.TxHash != tx.Hash() {
+				return fmt.Errorf("receipt of tx %s for bundle tx %s", receipt.TxHash, tx.Hash())
+			}
+			if receipt.Status == types.ReceiptStatusFailed && !bundle.OriginalBundle.RevertingHash(receipt.TxHash) {
+				log.Trace("Bundle tx failed", "bundle", bundle.OriginalBundle.Hash, "tx", receipt.TxHash)
+				return errBundleTxReverted
+			}
+		}
+	}
+	return nil
+}
+
 func checkInterrupt(i *int32) bool {
 	return i != nil && atomic.LoadInt32(i) != commitInterruptNone
 }
@@ -216,7 +262,7 @@ func applyTransactionWithBlacklist(
 	return receipt, statedb, err
 }
 
-func estimatePayoutTxGas(env *environment, sender, receiver common.Address, prv *ecdsa.PrivateKey, chData chainData) (uint64, bool, error) {
+func estimatePayoutTxGas(env *environment, sender, receiver common.Address, txSigner TxSigner, chData chainData) (uint64, bool, error) {
 	if codeHash := env.state.GetCodeHash(receiver); codeHash == (common.Hash{}) || codeHash == emptyCodeHash {
 		return params.TxGas, true, nil
 	}
@@ -227,20 +273,32 @@ func estimatePayoutTxGas(env *environment, sender, receiver common.Address, prv
 
 	diff := newEnvironmentDiff(env)
 	diff.state.SetBalance(sender, balance)
-	receipt, err := diff.commitPayoutTx(value, sender, receiver, gasLimit, prv, chData)
+	receipt, err := diff.commitPayoutTx(value, sender, receiver, gasLimit, txSigner, chData)
 	if err != nil {
 		return 0, false, err
 	}
 	return receipt.GasUsed, false, nil
 }
 
-func applyPayoutTx(envDiff *environmentDiff, sender, receiver common.Address, gas uint64, amountWithFees *big.Int, prv *ecdsa.PrivateKey, chData chainData) (*types.Receipt, error) {
+// txCoinbaseProfit returns the coinbase profit of a committed transaction, the change of the
+// coinbase balance: the priority fee plus the payments to the coinbase, including the ones made
+// by internal calls of contracts. The transactions sent by the coinbase, the proposer payout and
+// the sbundle refunds, only count their priority fee as the value they pay out is accounted for
+// apart from the block profit.
+func txCoinbaseProfit(from, coinbase common.Address, gasUsed uint64, gasTip, balanceDelta *big.Int) *big.Int {
+	if from == coinbase {
+		return new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasTip)
+	}
+	return new(big.Int).Set(balanceDelta)
+}
+
+func applyPayoutTx(envDiff *environmentDiff, sender, receiver common.Address, gas uint64, amountWithFees *big.Int, txSigner TxSigner, chData chainData) (*types.Receipt, error) {
 	amount := new(big.Int).Sub(amountWithFees, new(big.Int).Mul(envDiff.header.BaseFee, big.NewInt(int64(gas))))
 
 	if amount.Sign() < 0 {
 		return nil, errors.New("not enough funds available")
 	}
-	rec, err := envDiff.commitPayoutTx(amount, sender, receiver, gas, prv, chData)
+	rec, err := envDiff.commitPayoutTx(amount, sender, receiver, gas, txSigner, chData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to commit payment tx: %w", err)
 	} else if rec.Status != types.ReceiptStatusSuccessful {
@@ -260,7 +318,7 @@ func commitPayoutTx(parameters PayoutTransactionParams) (*types.Receipt, error)
 		return nil, errors.New("not enough balance")
 	}
 
-	tx, err := types.SignNewTx(parameters.PrivateKey, parameters.Signer, &types.DynamicFeeTx{
+	tx, err := parameters.TxSigner.SignTx(types.NewTx(&types.DynamicFeeTx{
 		ChainID:   parameters.ChainData.chainConfig.ChainID,
 		Nonce:     parameters.SenderNonce,
 		GasTipCap: new(big.Int),
@@ -268,7 +326,7 @@ func commitPayoutTx(parameters PayoutTransactionParams) (*types.Receipt, error)
 		Gas:       parameters.Gas,
 		To:        &parameters.Receiver,
 		Value:     parameters.Amount,
-	})
+	}), parameters.Signer)
 	if err != nil {
 		return nil, err
 	}
@@ -286,10 +344,10 @@ func commitPayoutTx(parameters PayoutTransactionParams) (*types.Receipt, error)
 	return receipt, err
 }
 
-func insertPayoutTx(env *environment, sender, receiver common.Address, gas uint64, isEOA bool, availableFunds *big.Int, prv *ecdsa.PrivateKey, chData chainData) (*types.Receipt, error) {
+func insertPayoutTx(env *environment, sender, receiver common.Address, gas uint64, isEOA bool, availableFunds *big.Int, txSigner TxSigner, chData chainData) (*types.Receipt, error) {
 	if isEOA {
 		diff := newEnvironmentDiff(env)
-		rec, err := applyPayoutTx(diff, sender, receiver, gas, availableFunds, prv, chData)
+		rec, err := applyPayoutTx(diff, sender, receiver, gas, availableFunds, txSigner, chData)
 		if err != nil {
 			return nil, err
 		}
@@ -301,7 +359,7 @@ func insertPayoutTx(env *environment, sender, receiver common.Address, gas uint6
 	for i := 0; i < 6; i++ {
 		diff := newEnvironmentDiff(env)
 		var rec *types.Receipt
-		rec, err = applyPayoutTx(diff, sender, receiver, gas, availableFunds, prv, chData)
+		rec, err = applyPayoutTx(diff, sender, receiver, gas, availableFunds, txSigner, chData)
 		if err != nil {
 			gas += 1000
 			continue
@@ -313,7 +371,7 @@ func insertPayoutTx(env *environment, sender, receiver common.Address, gas uint6
 		}
 
 		exactEnvDiff := newEnvironmentDiff(env)
-		exactRec, err := applyPayoutTx(exactEnvDiff, sender, receiver, rec.GasUsed, availableFunds, prv, chData)
+		exactRec, err := applyPayoutTx(exactEnvDiff, sender, receiver, rec.GasUsed, availableFunds, txSigner, chData)
 		if err != nil {
 			diff.applyToBaseEnv()
 			return rec, nil
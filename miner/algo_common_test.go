@@ -31,6 +31,9 @@ var (
 	// log contract logs value that it receives
 	logContractAddress = common.HexToAddress("0x2200000000000000000000000000000000000000")
 	logContractCode    = hexutil.MustDecode("0x346000523460206000a1")
+
+	// Revert contract reverts every call, see contractRevert
+	revertContractAddress = common.HexToAddress("0x3300000000000000000000000000000000000000")
 )
 
 type signerList struct {
@@ -285,6 +288,48 @@ func TestTxCommit(t *testing.T) {
 	}
 }
 
+func TestTxCommitCoinbasePayment(t *testing.T) {
+	commits := map[string]func(env *environment) (CommitTxFunc, func() *big.Int){
+		"diff": func(env *environment) (CommitTxFunc, func() *big.Int) {
+			envDiff := newEnvironmentDiff(env)
+			return envDiff.commitTx, func() *big.Int { return envDiff.newProfit }
+		},
+		"snaps": func(env *environment) (CommitTxFunc, func() *big.Int) {
+			changes, err := newEnvChanges(env)
+			if err != nil {
+				t.Fatalf("Error creating changes: %v", err)
+			}
+			return changes.commitTx, func() *big.Int { return new(big.Int).Sub(changes.profit, env.profit) }
+		},
+	}
+	for name, commit := range commits {
+		t.Run(name, func(t *testing.T) {
+			statedb, chData, signers := genTestSetup(GasLimit)
+			coinbase := signers.addresses[0]
+			env := newEnvironment(chData, statedb, coinbase, GasLimit, big.NewInt(1))
+			commitTx, profit := commit(env)
+
+			// the payment to the coinbase is profit besides the priority fee
+			tx := signers.signTx(1, 21000, big.NewInt(2), big.NewInt(3), coinbase, big.NewInt(1000), []byte{})
+			if _, _, err := commitTx(tx, chData); err != nil {
+				t.Fatal("can't commit transaction:", err)
+			}
+			if want := big.NewInt(2*21000 + 1000); profit().Cmp(want) != 0 {
+				t.Fatalf("profit %v, want %v", profit(), want)
+			}
+
+			// the value paid out by the coinbase is not deducted
+			tx = signers.signTx(0, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(500), []byte{})
+			if _, _, err := commitTx(tx, chData); err != nil {
+				t.Fatal("can't commit transaction:", err)
+			}
+			if want := big.NewInt(2*21000 + 1000); profit().Cmp(want) != 0 {
+				t.Fatalf("profit %v, want %v", profit(), want)
+			}
+		})
+	}
+}
+
 func TestBundleCommit(t *testing.T) {
 	algoConf := defaultAlgorithmConfig
 	statedb, chData, signers := genTestSetup(GasLimit)
@@ -445,6 +490,118 @@ func TestErrorBundleCommit(t *testing.T) {
 	}
 }
 
+// genPartialRevertBundles returns the simulations of a transfer bundle and of a bundle whose first
+// transaction reverts as allowed, followed by a bundle whose transaction reverts without being
+// allowed to.
+func genPartialRevertBundles(t *testing.T, env *environment, chData chainData, signers signerList) ([]types.SimulatedBundle, types.SimulatedBundle) {
+	transfer := signers.signTx(1, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(0), []byte{})
+	reverting := signers.signTx(2, 50000, big.NewInt(0), big.NewInt(1), revertContractAddress, big.NewInt(0), []byte{})
+	following := signers.signTx(3, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(1), []byte{})
+
+	simEnv := env.copy()
+	var merged []types.SimulatedBundle
+	for _, bundle := range []types.MevBundle{
+		{Txs: types.Transactions{transfer}, BlockNumber: env.header.Number},
+		{Txs: types.Transactions{reverting, following}, BlockNumber: env.header.Number, RevertingTxHashes: []common.Hash{reverting.Hash()}},
+	} {
+		simBundle, err := simulateBundle(simEnv, bundle, chData, nil)
+		if err != nil {
+			t.Fatal("Failed to simulate bundle", err)
+		}
+		merged = append(merged, simBundle)
+	}
+
+	// the reverting hash of another bundle does not allow the revert
+	rejected := types.SimulatedBundle{
+		MevGasPrice: big.NewInt(0),
+		TotalEth:    big.NewInt(0),
+		OriginalBundle: types.MevBundle{
+			Txs: types.Transactions{
+				signers.signTx(4, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(1), []byte{}),
+				signers.signTx(4, 50000, big.NewInt(0), big.NewInt(1), revertContractAddress, big.NewInt(0), []byte{}),
+			},
+			BlockNumber:       env.header.Number,
+			RevertingTxHashes: []common.Hash{reverting.Hash()},
+		},
+	}
+	return merged, rejected
+}
+
+func genRevertTestSetup() (*state.StateDB, chainData, signerList) {
+	config := params.AllEthashProtocolChanges
+	signers := genSignerList(10, config)
+	alloc := genGenesisAlloc(signers,
+		[]common.Address{payProxyAddress, logContractAddress, revertContractAddress},
+		[][]byte{payProxyCode, logContractCode, contractRevert})
+
+	statedb, chData := genTestSetupWithAlloc(config, alloc, GasLimit)
+	return statedb, chData, signers
+}
+
+func TestPartialRevertBundleCommit(t *testing.T) {
+	statedb, chData, signers := genRevertTestSetup()
+
+	env := newEnvironment(chData, statedb, signers.addresses[0], GasLimit, big.NewInt(1))
+	merged, rejected := genPartialRevertBundles(t, env, chData, signers)
+	envDiff := newEnvironmentDiff(env)
+
+	if err := envDiff.commitBundle(&merged[0], chData, nil, defaultAlgorithmConfig); err != nil {
+		t.Fatal("Failed to commit bundle", err)
+	}
+	gasUsedBefore := envDiff.header.GasUsed
+	balanceBefore := envDiff.state.GetBalance(signers.addresses[2])
+	if err := envDiff.commitBundle(&rejected, chData, nil, defaultAlgorithmConfig); !errors.Is(err, errBundleTxReverted) {
+		t.Fatal("Committed bundle with a tx reverting without being allowed to", err)
+	}
+	if envDiff.header.GasUsed != gasUsedBefore {
+		t.Fatal("gasUsed changed")
+	}
+	if envDiff.state.GetBalance(signers.addresses[2]).Cmp(balanceBefore) != 0 || envDiff.state.GetNonce(signers.addresses[4]) != 0 {
+		t.Fatal("state of the rejected bundle not reverted")
+	}
+	if err := envDiff.commitBundle(&merged[1], chData, nil, defaultAlgorithmConfig); err != nil {
+		t.Fatal("Failed to commit bundle with an allowed revert", err)
+	}
+
+	if len(envDiff.newReceipts) != 3 {
+		t.Fatal("Incorrect receipts txs")
+	}
+	if envDiff.newReceipts[1].Status != types.ReceiptStatusFailed || envDiff.newReceipts[2].Status != types.ReceiptStatusSuccessful {
+		t.Fatal("Incorrect receipts status")
+	}
+	if err := checkBundleReverts(merged, envDiff.newReceipts); err != nil {
+		t.Fatal("Allowed revert rejected", err)
+	}
+}
+
+func TestCheckBundleReverts(t *testing.T) {
+	statedb, chData, signers := genRevertTestSetup()
+
+	env := newEnvironment(chData, statedb, signers.addresses[0], GasLimit, big.NewInt(1))
+	merged, rejected := genPartialRevertBundles(t, env, chData, signers)
+
+	// the merge committed as plain transactions, as the mev-geth algorithm does
+	bundles := []types.SimulatedBundle{merged[0], rejected, merged[1]}
+	envDiff := newEnvironmentDiff(env)
+	for _, bundle := range bundles {
+		for _, tx := range bundle.OriginalBundle.Txs {
+			if _, _, err := envDiff.commitTx(tx, chData); err != nil {
+				t.Fatal("Failed to commit tx", err)
+			}
+		}
+	}
+
+	if err := checkBundleReverts(bundles, envDiff.newReceipts); !errors.Is(err, errBundleTxReverted) {
+		t.Fatal("Tx reverting without being allowed to not detected", err)
+	}
+	if err := checkBundleReverts(bundles[:1], envDiff.newReceipts[:1]); err != nil {
+		t.Fatal("Bundle without revert rejected", err)
+	}
+	if err := checkBundleReverts(bundles[2:], envDiff.newReceipts[1:]); err == nil {
+		t.Fatal("Receipts of other txs accepted")
+	}
+}
+
 func TestBlacklist(t *testing.T) {
 	statedb, chData, signers := genTestSetup(GasLimit)
 
@@ -530,7 +687,7 @@ func TestGetSealingWorkAlgos(t *testing.T) {
 		testConfig.AlgoType = ALGO_MEV_GETH
 	})
 
-	for _, algoType := range []AlgoType{ALGO_MEV_GETH, ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP} {
+	for _, algoType := range []AlgoType{ALGO_MEV_GETH, ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP, ALGO_GREEDY_EGP, ALGO_GREEDY_PROFIT} {
 		local := new(params.ChainConfig)
 		*local = *ethashChainConfig
 		local.TerminalTotalDifficulty = big.NewInt(0)
@@ -563,14 +720,14 @@ func TestPayoutTxUtils(t *testing.T) {
 	env := newEnvironment(chData, statedb, signers.addresses[0], GasLimit, big.NewInt(1))
 
 	// Sending payment to the plain EOA
-	gas, isEOA, err := estimatePayoutTxGas(env, signers.addresses[1], signers.addresses[2], signers.signers[1], chData)
+	gas, isEOA, err := estimatePayoutTxGas(env, signers.addresses[1], signers.addresses[2], NewLocalTxSigner(signers.signers[1]), chData)
 	require.Equal(t, uint64(21000), gas)
 	require.True(t, isEOA)
 	require.NoError(t, err)
 
 	expectedPayment := new(big.Int).Sub(availableFunds, big.NewInt(21000))
 	balanceBefore := env.state.GetBalance(signers.addresses[2])
-	rec, err := insertPayoutTx(env, signers.addresses[1], signers.addresses[2], gas, isEOA, availableFunds, signers.signers[1], chData)
+	rec, err := insertPayoutTx(env, signers.addresses[1], signers.addresses[2], gas, isEOA, availableFunds, NewLocalTxSigner(signers.signers[1]), chData)
 	balanceAfter := env.state.GetBalance(signers.addresses[2])
 	require.NoError(t, err)
 	require.NotNil(t, rec)
@@ -580,14 +737,14 @@ func TestPayoutTxUtils(t *testing.T) {
 	require.Equal(t, env.state.GetNonce(signers.addresses[1]), uint64(1))
 
 	// Sending payment to the contract that logs event of the amount
-	gas, isEOA, err = estimatePayoutTxGas(env, signers.addresses[1], logContractAddress, signers.signers[1], chData)
+	gas, isEOA, err = estimatePayoutTxGas(env, signers.addresses[1], logContractAddress, NewLocalTxSigner(signers.signers[1]), chData)
 	require.Equal(t, uint64(22025), gas)
 	require.False(t, isEOA)
 	require.NoError(t, err)
 
 	expectedPayment = new(big.Int).Sub(availableFunds, big.NewInt(22025))
 	balanceBefore = env.state.GetBalance(logContractAddress)
-	rec, err = insertPayoutTx(env, signers.addresses[1], logContractAddress, gas, isEOA, availableFunds, signers.signers[1], chData)
+	rec, err = insertPayoutTx(env, signers.addresses[1], logContractAddress, gas, isEOA, availableFunds, NewLocalTxSigner(signers.signers[1]), chData)
 	balanceAfter = env.state.GetBalance(logContractAddress)
 	require.NoError(t, err)
 	require.NotNil(t, rec)
@@ -599,7 +756,7 @@ func TestPayoutTxUtils(t *testing.T) {
 	// Try requesting less gas for contract tx. We request 21k gas, but we must pay 22025
 	expectedPayment = new(big.Int).Sub(availableFunds, big.NewInt(22025))
 	balanceBefore = env.state.GetBalance(logContractAddress)
-	rec, err = insertPayoutTx(env, signers.addresses[1], logContractAddress, 21000, isEOA, availableFunds, signers.signers[1], chData)
+	rec, err = insertPayoutTx(env, signers.addresses[1], logContractAddress, 21000, isEOA, availableFunds, NewLocalTxSigner(signers.signers[1]), chData)
 	balanceAfter = env.state.GetBalance(logContractAddress)
 	require.NoError(t, err)
 	require.NotNil(t, rec)
@@ -610,17 +767,17 @@ func TestPayoutTxUtils(t *testing.T) {
 
 	// errors
 
-	_, err = insertPayoutTx(env, signers.addresses[1], signers.addresses[2], 21000, true, availableFunds, signers.signers[2], chData)
+	_, err = insertPayoutTx(env, signers.addresses[1], signers.addresses[2], 21000, true, availableFunds, NewLocalTxSigner(signers.signers[2]), chData)
 	require.ErrorContains(t, err, "incorrect sender private key")
-	_, err = insertPayoutTx(env, signers.addresses[1], logContractAddress, 23000, false, availableFunds, signers.signers[2], chData)
+	_, err = insertPayoutTx(env, signers.addresses[1], logContractAddress, 23000, false, availableFunds, NewLocalTxSigner(signers.signers[2]), chData)
 	require.ErrorContains(t, err, "incorrect sender private key")
 
-	_, err = insertPayoutTx(env, signers.addresses[1], signers.addresses[2], 21000, true, big.NewInt(21000-1), signers.signers[1], chData)
+	_, err = insertPayoutTx(env, signers.addresses[1], signers.addresses[2], 21000, true, big.NewInt(21000-1), NewLocalTxSigner(signers.signers[1]), chData)
 	require.ErrorContains(t, err, "not enough funds available")
-	_, err = insertPayoutTx(env, signers.addresses[1], logContractAddress, 23000, false, big.NewInt(23000-1), signers.signers[1], chData)
+	_, err = insertPayoutTx(env, signers.addresses[1], logContractAddress, 23000, false, big.NewInt(23000-1), NewLocalTxSigner(signers.signers[1]), chData)
 	require.ErrorContains(t, err, "not enough funds available")
 
-	_, err = insertPayoutTx(env, signers.addresses[1], signers.addresses[2], 20000, true, availableFunds, signers.signers[1], chData)
+	_, err = insertPayoutTx(env, signers.addresses[1], signers.addresses[2], 20000, true, availableFunds, NewLocalTxSigner(signers.signers[1]), chData)
 	require.ErrorContains(t, err, "not enough gas")
 
 	require.Equal(t, env.state.GetNonce(signers.addresses[1]), uint64(3))
@@ -1,8 +1,6 @@
 package miner
 
 import (
-	"crypto/ecdsa"
-
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -18,14 +16,14 @@ import (
 type greedyBuilder struct {
 	inputEnvironment *environment
 	chainData        chainData
-	builderKey       *ecdsa.PrivateKey
+	builderKey       TxSigner
 	interrupt        *int32
 	algoConf         algorithmConfig
 }
 
 func newGreedyBuilder(
 	chain *core.BlockChain, chainConfig *params.ChainConfig, algoConf *algorithmConfig,
-	blacklist map[common.Address]struct{}, env *environment, key *ecdsa.PrivateKey, interrupt *int32,
+	blacklist map[common.Address]struct{}, env *environment, key TxSigner, interrupt *int32,
 ) *greedyBuilder {
 	if algoConf == nil {
 		panic("algoConf cannot be nil")
@@ -76,10 +74,12 @@ func (b *greedyBuilder) mergeOrdersIntoEnvDiff(
 			orders.Pop()
 			if err != nil {
 				log.Trace("Could not apply bundle", "bundle", bundle.OriginalBundle.Hash, "err", err)
+				b.algoConf.Decisions.rejected(bundle.OriginalBundle.Hash, err)
 				continue
 			}
 
 			log.Trace("Included bundle", "bundleEGP", bundle.MevGasPrice.String(), "gasUsed", bundle.TotalGasUsed, "ethToCoinbase", ethIntToFloat(bundle.TotalEth))
+			b.algoConf.Decisions.committed(bundle.OriginalBundle.Hash, envDiff.blockProfit())
 			usedBundles = append(usedBundles, *bundle)
 		} else if sbundle := order.SBundle(); sbundle != nil {
 			usedEntry := types.UsedSBundle{
@@ -89,12 +89,14 @@ func (b *greedyBuilder) mergeOrdersIntoEnvDiff(
 			orders.Pop()
 			if err != nil {
 				log.Trace("Could not apply sbundle", "bundle", sbundle.Bundle.Hash(), "err", err)
+				b.algoConf.Decisions.rejected(sbundle.Bundle.Hash(), err)
 				usedEntry.Success = false
 				usedSbundles = append(usedSbundles, usedEntry)
 				continue
 			}
 
 			log.Trace("Included sbundle", "bundleEGP", sbundle.MevGasPrice.String(), "ethToCoinbase", ethIntToFloat(sbundle.Profit))
+			b.algoConf.Decisions.committed(sbundle.Bundle.Hash(), envDiff.blockProfit())
 			usedEntry.Success = true
 			usedSbundles = append(usedSbundles, usedEntry)
 		}
@@ -1,7 +1,6 @@
 package miner
 
 import (
-	"crypto/ecdsa"
 	"errors"
 	"math/big"
 	"sort"
@@ -21,7 +20,7 @@ import (
 type greedyBucketsBuilder struct {
 	inputEnvironment *environment
 	chainData        chainData
-	builderKey       *ecdsa.PrivateKey
+	builderKey       TxSigner
 	interrupt        *int32
 	gasUsedMap       map[*types.TxWithMinerFee]uint64
 	algoConf         algorithmConfig
@@ -29,7 +28,7 @@ type greedyBucketsBuilder struct {
 
 func newGreedyBucketsBuilder(
 	chain *core.BlockChain, chainConfig *params.ChainConfig, algoConf *algorithmConfig,
-	blacklist map[common.Address]struct{}, env *environment, key *ecdsa.PrivateKey, interrupt *int32,
+	blacklist map[common.Address]struct{}, env *environment, key TxSigner, interrupt *int32,
 ) *greedyBucketsBuilder {
 	if algoConf == nil {
 		panic("algoConf cannot be nil")
@@ -98,9 +97,12 @@ func (b *greedyBucketsBuilder) commit(envDiff *environmentDiff,
 			err := envDiff.commitBundle(bundle, b.chainData, b.interrupt, algoConf)
 			if err != nil {
 				log.Trace("Could not apply bundle", "bundle", bundle.OriginalBundle.Hash, "err", err)
+				algoConf.Decisions.rejected(bundle.OriginalBundle.Hash, err)
 
 				var e *lowProfitError
 				if errors.As(err, &e) {
+					algoConf.Griefing.profitVanished(bundle.OriginalBundle.Hash, e)
+
 					if e.ActualEffectiveGasPrice != nil {
 						order.SetPrice(e.ActualEffectiveGasPrice)
 					}
@@ -116,6 +118,7 @@ func (b *greedyBucketsBuilder) commit(envDiff *environmentDiff,
 
 			log.Trace("Included bundle", "bundleEGP", bundle.MevGasPrice.String(),
 				"gasUsed", bundle.TotalGasUsed, "ethToCoinbase", ethIntToFloat(bundle.EthSentToCoinbase))
+			algoConf.Decisions.committed(bundle.OriginalBundle.Hash, envDiff.blockProfit())
 			usedBundles = append(usedBundles, *bundle)
 		} else if sbundle := order.SBundle(); sbundle != nil {
 			usedEntry := types.UsedSBundle{
@@ -124,6 +127,7 @@ func (b *greedyBucketsBuilder) commit(envDiff *environmentDiff,
 			err := envDiff.commitSBundle(sbundle, b.chainData, b.interrupt, b.builderKey, algoConf)
 			if err != nil {
 				log.Trace("Could not apply sbundle", "bundle", sbundle.Bundle.Hash(), "err", err)
+				algoConf.Decisions.rejected(sbundle.Bundle.Hash(), err)
 
 				var e *lowProfitError
 				if errors.As(err, &e) {
@@ -145,6 +149,7 @@ func (b *greedyBucketsBuilder) commit(envDiff *environmentDiff,
 			}
 
 			log.Trace("Included sbundle", "bundleEGP", sbundle.MevGasPrice.String(), "ethToCoinbase", ethIntToFloat(sbundle.Profit))
+			algoConf.Decisions.committed(sbundle.Bundle.Hash(), envDiff.blockProfit())
 			usedEntry.Success = true
 			usedSbundles = append(usedSbundles, usedEntry)
 		} else {
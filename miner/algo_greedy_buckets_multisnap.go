@@ -1,13 +1,13 @@
 package miner
 
 import (
-	"crypto/ecdsa"
 	"errors"
 	"math/big"
 	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
@@ -21,7 +21,7 @@ import (
 type greedyBucketsMultiSnapBuilder struct {
 	inputEnvironment *environment
 	chainData        chainData
-	builderKey       *ecdsa.PrivateKey
+	builderKey       TxSigner
 	interrupt        *int32
 	gasUsedMap       map[*types.TxWithMinerFee]uint64
 	algoConf         algorithmConfig
@@ -29,7 +29,7 @@ type greedyBucketsMultiSnapBuilder struct {
 
 func newGreedyBucketsMultiSnapBuilder(
 	chain *core.BlockChain, chainConfig *params.ChainConfig, algoConf *algorithmConfig,
-	blacklist map[common.Address]struct{}, env *environment, key *ecdsa.PrivateKey, interrupt *int32,
+	blacklist map[common.Address]struct{}, env *environment, key TxSigner, interrupt *int32,
 ) *greedyBucketsMultiSnapBuilder {
 	if algoConf == nil {
 		panic("algoConf cannot be nil")
@@ -56,10 +56,29 @@ func (b *greedyBucketsMultiSnapBuilder) commit(changes *envChanges,
 		usedBundles  []types.SimulatedBundle
 		usedSbundles []types.UsedSBundle
 	)
+	// the snapshot of every committed order is kept as a checkpoint, a failed order only reverts
+	// its own snapshot, and the checkpoints of the bucket are squashed into the changes at the end
+	defer func() {
+		if err := changes.squashSnapshots(); err != nil {
+			log.Error("Failed to squash snapshots", "round", b.inputEnvironment.round.ID(), "err", err)
+			alertSnapshotInvalidated(ALGO_GREEDY_BUCKETS_MULTISNAP, "squash", err)
+		}
+	}()
 
 	for _, order := range transactions {
-		if err := changes.env.state.NewMultiTxSnapshot(); err != nil {
-			log.Error("Failed to create new multi-tx snapshot", "err", err)
+		err := changes.newSnapshot()
+		if errors.Is(err, state.ErrMultiTxSnapshotMemoryLimit) && len(changes.gasCheckpoints) > 0 {
+			// the checkpoints held so far are squashed to make room for the snapshot of the order
+			if err = changes.squashSnapshots(); err == nil {
+				err = changes.newSnapshot()
+			}
+		}
+		if errors.Is(err, state.ErrMultiTxSnapshotMemoryLimit) {
+			log.Debug("Multi-transaction snapshot memory limit reached, order not committed to the block", "round", b.inputEnvironment.round.ID())
+			return usedBundles, usedSbundles
+		} else if err != nil {
+			log.Error("Failed to create new multi-tx snapshot", "round", b.inputEnvironment.round.ID(), "err", err)
+			alertSnapshotInvalidated(ALGO_GREEDY_BUCKETS_MULTISNAP, "create", err)
 			return usedBundles, usedSbundles
 		}
 
@@ -93,9 +112,12 @@ func (b *greedyBucketsMultiSnapBuilder) commit(changes *envChanges,
 			orderFailed = err != nil
 			if err != nil {
 				log.Trace("Could not apply bundle", "bundle", bundle.OriginalBundle.Hash, "err", err)
+				algoConf.Decisions.rejected(bundle.OriginalBundle.Hash, err)
 
 				var e *lowProfitError
 				if errors.As(err, &e) {
+					algoConf.Griefing.profitVanished(bundle.OriginalBundle.Hash, e)
+
 					if e.ActualEffectiveGasPrice != nil {
 						order.SetPrice(e.ActualEffectiveGasPrice)
 					}
@@ -109,6 +131,7 @@ func (b *greedyBucketsMultiSnapBuilder) commit(changes *envChanges,
 			} else {
 				log.Trace("Included bundle", "bundleEGP", bundle.MevGasPrice.String(),
 					"gasUsed", bundle.TotalGasUsed, "ethToCoinbase", ethIntToFloat(bundle.EthSentToCoinbase))
+				algoConf.Decisions.committed(bundle.OriginalBundle.Hash, changes.profit)
 				usedBundles = append(usedBundles, *bundle)
 			}
 		} else if sbundle := order.SBundle(); sbundle != nil {
@@ -122,6 +145,7 @@ func (b *greedyBucketsMultiSnapBuilder) commit(changes *envChanges,
 			isValidOrNotRetried := true
 			if err != nil {
 				log.Trace("Could not apply sbundle", "bundle", sbundle.Bundle.Hash(), "err", err)
+				algoConf.Decisions.rejected(sbundle.Bundle.Hash(), err)
 
 				var e *lowProfitError
 				if errors.As(err, &e) {
@@ -140,6 +164,7 @@ func (b *greedyBucketsMultiSnapBuilder) commit(changes *envChanges,
 				}
 			} else {
 				log.Trace("Included sbundle", "bundleEGP", sbundle.MevGasPrice.String(), "ethToCoinbase", ethIntToFloat(sbundle.Profit))
+				algoConf.Decisions.committed(sbundle.Bundle.Hash(), changes.profit)
 			}
 
 			if isValidOrNotRetried {
@@ -152,13 +177,9 @@ func (b *greedyBucketsMultiSnapBuilder) commit(changes *envChanges,
 		}
 
 		if orderFailed {
-			if err := changes.env.state.MultiTxSnapshotRevert(); err != nil {
-				log.Error("Failed to revert snapshot", "err", err)
-				return usedBundles, usedSbundles
-			}
-		} else {
-			if err := changes.env.state.MultiTxSnapshotCommit(); err != nil {
-				log.Error("Failed to commit snapshot", "err", err)
+			if err := changes.revertSnapshot(); err != nil {
+				log.Error("Failed to revert snapshot", "round", b.inputEnvironment.round.ID(), "err", err)
+				alertSnapshotInvalidated(ALGO_GREEDY_BUCKETS_MULTISNAP, "revert", err)
 				return usedBundles, usedSbundles
 			}
 		}
@@ -174,7 +195,8 @@ func (b *greedyBucketsMultiSnapBuilder) mergeOrdersAndApplyToEnv(
 
 	changes, err := newEnvChanges(b.inputEnvironment)
 	if err != nil {
-		log.Error("Failed to create new environment changes", "err", err)
+		log.Error("Failed to create new environment changes", "round", b.inputEnvironment.round.ID(), "err", err)
+		alertSnapshotInvalidated(ALGO_GREEDY_BUCKETS_MULTISNAP, "create", err)
 		return b.inputEnvironment, nil, nil
 	}
 
@@ -228,7 +250,8 @@ func (b *greedyBucketsMultiSnapBuilder) mergeOrdersAndApplyToEnv(
 	}
 
 	if err := changes.apply(); err != nil {
-		log.Error("Failed to apply changes", "err", err)
+		log.Error("Failed to apply changes", "round", b.inputEnvironment.round.ID(), "err", err)
+		alertSnapshotInvalidated(ALGO_GREEDY_BUCKETS_MULTISNAP, "commit", err)
 		return b.inputEnvironment, nil, nil
 	}
 
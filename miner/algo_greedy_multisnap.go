@@ -1,10 +1,11 @@
 package miner
 
 import (
-	"crypto/ecdsa"
+	"errors"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
@@ -18,14 +19,14 @@ import (
 type greedyMultiSnapBuilder struct {
 	inputEnvironment *environment
 	chainData        chainData
-	builderKey       *ecdsa.PrivateKey
+	builderKey       TxSigner
 	interrupt        *int32
 	algoConf         algorithmConfig
 }
 
 func newGreedyMultiSnapBuilder(
 	chain *core.BlockChain, chainConfig *params.ChainConfig, algoConf *algorithmConfig,
-	blacklist map[common.Address]struct{}, env *environment, key *ecdsa.PrivateKey, interrupt *int32,
+	blacklist map[common.Address]struct{}, env *environment, key TxSigner, interrupt *int32,
 ) *greedyMultiSnapBuilder {
 	if algoConf == nil {
 		algoConf = &defaultAlgorithmConfig
@@ -49,7 +50,8 @@ func (b *greedyMultiSnapBuilder) buildBlock(simBundles []types.SimulatedBundle,
 
 	changes, err := newEnvChanges(b.inputEnvironment)
 	if err != nil {
-		log.Error("Failed to create new environment changes", "err", err)
+		log.Error("Failed to create new environment changes", "round", b.inputEnvironment.round.ID(), "err", err)
+		alertSnapshotInvalidated(ALGO_GREEDY_MULTISNAP, "create", err)
 		return b.inputEnvironment, usedBundles, usedSbundles
 	}
 
@@ -60,8 +62,12 @@ func (b *greedyMultiSnapBuilder) buildBlock(simBundles []types.SimulatedBundle,
 		}
 
 		orderFailed := false
-		if err := changes.env.state.NewMultiTxSnapshot(); err != nil {
-			log.Error("Failed to create snapshot", "err", err)
+		if err := changes.newSnapshot(); errors.Is(err, state.ErrMultiTxSnapshotMemoryLimit) {
+			log.Warn("Multi-transaction snapshot memory limit reached, no more orders committed to the block", "round", b.inputEnvironment.round.ID())
+			break
+		} else if err != nil {
+			log.Error("Failed to create snapshot", "round", b.inputEnvironment.round.ID(), "err", err)
+			alertSnapshotInvalidated(ALGO_GREEDY_MULTISNAP, "create", err)
 			return b.inputEnvironment, usedBundles, usedSbundles
 		}
 
@@ -89,9 +95,11 @@ func (b *greedyMultiSnapBuilder) buildBlock(simBundles []types.SimulatedBundle,
 
 			if err != nil {
 				log.Trace("Could not apply bundle", "bundle", bundle.OriginalBundle.Hash, "err", err)
+				b.algoConf.Decisions.rejected(bundle.OriginalBundle.Hash, err)
 			} else {
 				log.Trace("Included bundle", "bundleEGP", bundle.MevGasPrice.String(),
 					"gasUsed", bundle.TotalGasUsed, "ethToCoinbase", ethIntToFloat(bundle.EthSentToCoinbase))
+				b.algoConf.Decisions.committed(bundle.OriginalBundle.Hash, changes.profit)
 				usedBundles = append(usedBundles, *bundle)
 			}
 		} else if sbundle := order.SBundle(); sbundle != nil {
@@ -105,28 +113,33 @@ func (b *greedyMultiSnapBuilder) buildBlock(simBundles []types.SimulatedBundle,
 
 			if err != nil {
 				log.Trace("Could not apply sbundle", "bundle", sbundle.Bundle.Hash(), "err", err)
+				b.algoConf.Decisions.rejected(sbundle.Bundle.Hash(), err)
 			} else {
 				log.Trace("Included sbundle", "bundleEGP", sbundle.MevGasPrice.String(), "ethToCoinbase", ethIntToFloat(sbundle.Profit))
+				b.algoConf.Decisions.committed(sbundle.Bundle.Hash(), changes.profit)
 			}
 
 			usedSbundles = append(usedSbundles, usedEntry)
 		}
 
 		if orderFailed {
-			if err := changes.env.state.MultiTxSnapshotRevert(); err != nil {
-				log.Error("Failed to revert snapshot", "err", err)
+			if err := changes.revertSnapshot(); err != nil {
+				log.Error("Failed to revert snapshot", "round", b.inputEnvironment.round.ID(), "err", err)
+				alertSnapshotInvalidated(ALGO_GREEDY_MULTISNAP, "revert", err)
 				return b.inputEnvironment, usedBundles, usedSbundles
 			}
 		} else {
-			if err := changes.env.state.MultiTxSnapshotCommit(); err != nil {
-				log.Error("Failed to commit snapshot", "err", err)
+			if err := changes.commitSnapshot(); err != nil {
+				log.Error("Failed to commit snapshot", "round", b.inputEnvironment.round.ID(), "err", err)
+				alertSnapshotInvalidated(ALGO_GREEDY_MULTISNAP, "commit", err)
 				return b.inputEnvironment, usedBundles, usedSbundles
 			}
 		}
 	}
 
 	if err := changes.apply(); err != nil {
-		log.Error("Failed to apply changes", "err", err)
+		log.Error("Failed to apply changes", "round", b.inputEnvironment.round.ID(), "err", err)
+		alertSnapshotInvalidated(ALGO_GREEDY_MULTISNAP, "commit", err)
 		return b.inputEnvironment, usedBundles, usedSbundles
 	}
 
@@ -11,7 +11,7 @@ import (
 )
 
 func TestBuildBlockGasLimit(t *testing.T) {
-	algos := []AlgoType{ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP}
+	algos := []AlgoType{ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP, ALGO_GREEDY_EGP, ALGO_GREEDY_PROFIT}
 	for _, algo := range algos {
 		statedb, chData, signers := genTestSetup(GasLimit)
 		env := newEnvironment(chData, statedb, signers.addresses[0], 21000, big.NewInt(1))
@@ -41,6 +41,12 @@ func TestBuildBlockGasLimit(t *testing.T) {
 		case ALGO_GREEDY_BUCKETS_MULTISNAP:
 			builder := newGreedyBucketsMultiSnapBuilder(chData.chain, chData.chainConfig, &defaultAlgorithmConfig, nil, env, nil, nil)
 			result, _, _ = builder.buildBlock([]types.SimulatedBundle{}, nil, txs)
+		case ALGO_GREEDY_EGP:
+			builder := &algorithmBuilder{newGreedyEGPAlgorithm(chData.chain, chData.chainConfig, &defaultAlgorithmConfig, nil, env, nil, nil)}
+			result, _, _ = builder.buildBlock([]types.SimulatedBundle{}, nil, txs)
+		case ALGO_GREEDY_PROFIT:
+			builder := &algorithmBuilder{newGreedyProfitAlgorithm(chData.chain, chData.chainConfig, &defaultAlgorithmConfig, nil, env, nil, nil)}
+			result, _, _ = builder.buildBlock([]types.SimulatedBundle{}, nil, txs)
 		}
 
 		t.Log("block built", "txs", len(result.txs), "gasPool", result.gasPool.Gas(), "algorithm", algo.String())
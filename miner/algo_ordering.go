@@ -0,0 +1,209 @@
+package miner
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// BuilderAlgorithm is a policy ordering the simulated orders merged into a block. The bundles and
+// sbundles are committed first, in the order of the policy, then the mempool transactions fill the
+// rest of the block. An algorithm is tied to a single block building task.
+type BuilderAlgorithm interface {
+	// CommitBundles commits the bundles and sbundles which still apply on top of the block built so far.
+	CommitBundles(simBundles []types.SimulatedBundle, simSBundles []*types.SimSBundle)
+	// CommitTxs commits the mempool transactions, by price and nonce, until the block is full.
+	CommitTxs(transactions map[common.Address]types.Transactions)
+	// Result returns the block built, along with the bundles and sbundles committed.
+	Result() (*environment, []types.SimulatedBundle, []types.UsedSBundle)
+}
+
+// algorithmBuilder packs a block with a BuilderAlgorithm.
+type algorithmBuilder struct {
+	algorithm BuilderAlgorithm
+}
+
+func (b *algorithmBuilder) buildBlock(simBundles []types.SimulatedBundle, simSBundles []*types.SimSBundle, transactions map[common.Address]types.Transactions) (*environment, []types.SimulatedBundle, []types.UsedSBundle) {
+	b.algorithm.CommitBundles(simBundles, simSBundles)
+	b.algorithm.CommitTxs(transactions)
+	return b.algorithm.Result()
+}
+
+// bundleOrder is a bundle or sbundle to commit, along with the value it is ranked by.
+type bundleOrder struct {
+	bundle  *types.SimulatedBundle
+	sbundle *types.SimSBundle
+	rank    *big.Int
+}
+
+// orderingAlgorithm commits the orders ranked by an ordering policy to an environment diff.
+type orderingAlgorithm struct {
+	envDiff    *environmentDiff
+	chainData  chainData
+	builderKey TxSigner
+	interrupt  *int32
+	algoConf   algorithmConfig
+
+	// bundleRank and sbundleRank return the value an order is ranked by, highest first
+	bundleRank  func(bundle *types.SimulatedBundle) *big.Int
+	sbundleRank func(sbundle *types.SimSBundle) *big.Int
+
+	usedBundles  []types.SimulatedBundle
+	usedSbundles []types.UsedSBundle
+}
+
+func newOrderingAlgorithm(
+	chain *core.BlockChain, chainConfig *params.ChainConfig, algoConf *algorithmConfig,
+	blacklist map[common.Address]struct{}, env *environment, key TxSigner, interrupt *int32,
+) orderingAlgorithm {
+	if algoConf == nil {
+		panic("algoConf cannot be nil")
+	}
+
+	return orderingAlgorithm{
+		envDiff:    newEnvironmentDiff(env.copy()),
+		chainData:  chainData{chainConfig, chain, blacklist},
+		builderKey: key,
+		interrupt:  interrupt,
+		algoConf:   *algoConf,
+	}
+}
+
+func (a *orderingAlgorithm) CommitBundles(simBundles []types.SimulatedBundle, simSBundles []*types.SimSBundle) {
+	orders := make([]bundleOrder, 0, len(simBundles)+len(simSBundles))
+	for i := range simBundles {
+		orders = append(orders, bundleOrder{bundle: &simBundles[i], rank: a.bundleRank(&simBundles[i])})
+	}
+	for _, sbundle := range simSBundles {
+		orders = append(orders, bundleOrder{sbundle: sbundle, rank: a.sbundleRank(sbundle)})
+	}
+	for i := range orders {
+		if orders[i].rank == nil {
+			orders[i].rank = new(big.Int)
+		}
+	}
+	// the orders of equal rank keep the order they were received in, for blocks to be reproducible
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].rank.Cmp(orders[j].rank) > 0
+	})
+
+	for _, order := range orders {
+		if bundle := order.bundle; bundle != nil {
+			if err := a.envDiff.commitBundle(bundle, a.chainData, a.interrupt, a.algoConf); err != nil {
+				log.Trace("Could not apply bundle", "bundle", bundle.OriginalBundle.Hash, "err", err)
+				a.algoConf.Decisions.rejected(bundle.OriginalBundle.Hash, err)
+				continue
+			}
+
+			log.Trace("Included bundle", "bundleEGP", bundle.MevGasPrice.String(), "gasUsed", bundle.TotalGasUsed, "ethToCoinbase", ethIntToFloat(bundle.TotalEth))
+			a.algoConf.Decisions.committed(bundle.OriginalBundle.Hash, a.envDiff.blockProfit())
+			a.usedBundles = append(a.usedBundles, *bundle)
+		} else {
+			sbundle := order.sbundle
+			usedEntry := types.UsedSBundle{
+				Bundle: sbundle.Bundle,
+			}
+			if err := a.envDiff.commitSBundle(sbundle, a.chainData, a.interrupt, a.builderKey, a.algoConf); err != nil {
+				log.Trace("Could not apply sbundle", "bundle", sbundle.Bundle.Hash(), "err", err)
+				a.algoConf.Decisions.rejected(sbundle.Bundle.Hash(), err)
+				usedEntry.Success = false
+				a.usedSbundles = append(a.usedSbundles, usedEntry)
+				continue
+			}
+
+			log.Trace("Included sbundle", "bundleEGP", sbundle.MevGasPrice.String(), "ethToCoinbase", ethIntToFloat(sbundle.Profit))
+			a.algoConf.Decisions.committed(sbundle.Bundle.Hash(), a.envDiff.blockProfit())
+			usedEntry.Success = true
+			a.usedSbundles = append(a.usedSbundles, usedEntry)
+		}
+	}
+}
+
+func (a *orderingAlgorithm) CommitTxs(transactions map[common.Address]types.Transactions) {
+	env := a.envDiff.baseEnvironment
+	orders := types.NewTransactionsByPriceAndNonce(env.signer, transactions, nil, nil, env.header.BaseFee)
+	for {
+		order := orders.Peek()
+		if order == nil {
+			break
+		}
+
+		tx := order.Tx()
+		receipt, skip, err := a.envDiff.commitTx(tx, a.chainData)
+		switch skip {
+		case shiftTx:
+			orders.Shift()
+		case popTx:
+			orders.Pop()
+		}
+
+		if err != nil {
+			log.Trace("could not apply tx", "hash", tx.Hash(), "err", err)
+			continue
+		}
+		effGapPrice, err := tx.EffectiveGasTip(env.header.BaseFee)
+		if err == nil {
+			log.Trace("Included tx", "EGP", effGapPrice.String(), "gasUsed", receipt.GasUsed)
+		}
+	}
+}
+
+func (a *orderingAlgorithm) Result() (*environment, []types.SimulatedBundle, []types.UsedSBundle) {
+	a.envDiff.applyToBaseEnv()
+	return a.envDiff.baseEnvironment, a.usedBundles, a.usedSbundles
+}
+
+// greedyEGPAlgorithm commits the bundles and sbundles by effective gas price, the coinbase profit
+// per gas used, favouring the orders making the best use of the block space.
+type greedyEGPAlgorithm struct {
+	orderingAlgorithm
+}
+
+func newGreedyEGPAlgorithm(
+	chain *core.BlockChain, chainConfig *params.ChainConfig, algoConf *algorithmConfig,
+	blacklist map[common.Address]struct{}, env *environment, key TxSigner, interrupt *int32,
+) *greedyEGPAlgorithm {
+	algorithm := &greedyEGPAlgorithm{newOrderingAlgorithm(chain, chainConfig, algoConf, blacklist, env, key, interrupt)}
+	algorithm.bundleRank = func(bundle *types.SimulatedBundle) *big.Int {
+		return prioritizedRank(bundle.MevGasPrice, bundle.MevGasPrice, bundle.Priority)
+	}
+	algorithm.sbundleRank = func(sbundle *types.SimSBundle) *big.Int {
+		return prioritizedRank(sbundle.MevGasPrice, sbundle.MevGasPrice, sbundle.Priority)
+	}
+	return algorithm
+}
+
+// greedyProfitAlgorithm commits the bundles and sbundles by total coinbase profit, favouring the
+// most valuable orders whatever gas they use.
+type greedyProfitAlgorithm struct {
+	orderingAlgorithm
+}
+
+func newGreedyProfitAlgorithm(
+	chain *core.BlockChain, chainConfig *params.ChainConfig, algoConf *algorithmConfig,
+	blacklist map[common.Address]struct{}, env *environment, key TxSigner, interrupt *int32,
+) *greedyProfitAlgorithm {
+	algorithm := &greedyProfitAlgorithm{newOrderingAlgorithm(chain, chainConfig, algoConf, blacklist, env, key, interrupt)}
+	algorithm.bundleRank = func(bundle *types.SimulatedBundle) *big.Int {
+		return prioritizedRank(bundle.TotalEth, bundle.MevGasPrice, bundle.Priority)
+	}
+	algorithm.sbundleRank = func(sbundle *types.SimSBundle) *big.Int {
+		return prioritizedRank(sbundle.Profit, sbundle.MevGasPrice, sbundle.Priority)
+	}
+	return algorithm
+}
+
+// prioritizedRank scales the rank of an order by the ratio of the price it is ordered by, e.g.
+// boosted by its orderflow channel, to its effective gas price.
+func prioritizedRank(rank, mevGasPrice, priority *big.Int) *big.Int {
+	if rank == nil || priority == nil || mevGasPrice == nil || mevGasPrice.Sign() == 0 {
+		return rank
+	}
+	scaled := new(big.Int).Mul(rank, priority)
+	return scaled.Div(scaled, mevGasPrice)
+}
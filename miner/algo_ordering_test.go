@@ -0,0 +1,59 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestOrderingAlgorithms(t *testing.T) {
+	for _, algo := range []AlgoType{ALGO_GREEDY_EGP, ALGO_GREEDY_PROFIT} {
+		statedb, chData, signers := genTestSetup(GasLimit)
+		env := newEnvironment(chData, statedb, signers.addresses[0], GasLimit, big.NewInt(1))
+
+		// both bundles spend the same nonce, only the first one committed makes it into the block
+		efficient := types.SimulatedBundle{
+			MevGasPrice: big.NewInt(5),
+			TotalEth:    big.NewInt(1),
+			OriginalBundle: types.MevBundle{
+				Hash: common.HexToHash("0xb1"),
+				Txs:  types.Transactions{signers.signTx(1, 21000, big.NewInt(10), big.NewInt(11), signers.addresses[2], big.NewInt(1), []byte{})},
+			},
+		}
+		signers.nonces[1] = 0
+		valuable := types.SimulatedBundle{
+			MevGasPrice: big.NewInt(1),
+			TotalEth:    big.NewInt(100),
+			OriginalBundle: types.MevBundle{
+				Hash: common.HexToHash("0xb2"),
+				Txs:  types.Transactions{signers.signTx(1, 21000, big.NewInt(10), big.NewInt(11), signers.addresses[3], big.NewInt(1), []byte{})},
+			},
+		}
+		txs := map[common.Address]types.Transactions{
+			signers.addresses[4]: {signers.signTx(4, 21000, big.NewInt(1), big.NewInt(2), signers.addresses[2], big.NewInt(0), []byte{})},
+		}
+
+		var (
+			builder  blockBuilder
+			expected common.Hash
+		)
+		switch algo {
+		case ALGO_GREEDY_EGP:
+			builder = &algorithmBuilder{newGreedyEGPAlgorithm(chData.chain, chData.chainConfig, &defaultAlgorithmConfig, nil, env, nil, nil)}
+			expected = efficient.OriginalBundle.Hash
+		case ALGO_GREEDY_PROFIT:
+			builder = &algorithmBuilder{newGreedyProfitAlgorithm(chData.chain, chData.chainConfig, &defaultAlgorithmConfig, nil, env, nil, nil)}
+			expected = valuable.OriginalBundle.Hash
+		}
+		result, usedBundles, _ := builder.buildBlock([]types.SimulatedBundle{valuable, efficient}, nil, txs)
+
+		if len(usedBundles) != 1 || usedBundles[0].OriginalBundle.Hash != expected {
+			t.Fatalf("%s: unexpected bundles committed %v, expected %x", algo, usedBundles, expected)
+		}
+		if result.tcount != 2 {
+			t.Fatalf("%s: incorrect tx count [found: %d]", algo, result.tcount)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package miner
+
+import (
+	"github.com/ethereum/go-ethereum/builder/auditlog"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// auditTopOfBlockUnfilled records the gas reserved at the top of the block left unused by the
+// designated orders
+func auditTopOfBlockUnfilled(header *types.Header, reserved, used uint64) {
+	auditlog.Append(auditlog.EventReservationUnfilled, &auditlog.TopOfBlockReservation{
+		BlockNumber: header.Number.Uint64(),
+		ParentHash:  header.ParentHash,
+		ReservedGas: reserved,
+		UsedGas:     used,
+	})
+}
@@ -0,0 +1,244 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// maxBacktestBlocks is the largest range of blocks replayed by a backtest
+const maxBacktestBlocks = 1024
+
+var errBacktestMevGeth = errors.New("backtests are not supported by the mev-geth algorithm")
+
+// BlockBacktest compares the block the builder would have built on top of the parent of a
+// historical block with the block that landed on chain.
+type BlockBacktest struct {
+	Number     hexutil.Uint64 `json:"number"`
+	LandedHash common.Hash    `json:"landedHash"`
+	// Synthetic is true if no bundle was recorded for the block, and the transactions of the
+	// landed block were fed to the algorithm as one bundle per sender
+	Synthetic bool `json:"synthetic"`
+	Bundles   int  `json:"bundles"`
+	// MempoolTxs is the number of transactions of the mempool dataset pending at the block
+	MempoolTxs int `json:"mempoolTxs,omitempty"`
+
+	LandedTxs    int          `json:"landedTxs"`
+	LandedProfit *hexutil.Big `json:"landedProfit,omitempty"` // Paid to the coinbase by the landed block
+	BuiltTxs     int          `json:"builtTxs"`
+	BuiltBundles int          `json:"builtBundles"`
+	BuiltProfit  *hexutil.Big `json:"builtProfit,omitempty"` // Paid to the coinbase by the backtest block
+
+	Error string `json:"error,omitempty"`
+}
+
+// BacktestReport is the outcome of the replay of a range of historical blocks.
+type BacktestReport struct {
+	Algo      string         `json:"algo"`
+	FromBlock hexutil.Uint64 `json:"fromBlock"`
+	ToBlock   hexutil.Uint64 `json:"toBlock"`
+
+	Blocks []BlockBacktest `json:"blocks"`
+	// Better and Worse are the number of blocks the builder would have paid more, or less,
+	// than the landed block. Failed blocks could not be replayed.
+	Better int `json:"better"`
+	Worse  int `json:"worse"`
+	Failed int `json:"failed"`
+
+	// Total profits of the replayed blocks
+	LandedProfit *hexutil.Big `json:"landedProfit"`
+	BuiltProfit  *hexutil.Big `json:"builtProfit"`
+}
+
+// backtest replays the blocks from..to through the given algorithm, the configured one if empty.
+// The bundles are fed to the block they target. With a mempool dataset, the transactions of the
+// dataset pending at every block are its mempool; without, blocks without bundles are replayed
+// with synthetic bundles made of their transactions. The state of the parent of every block, and
+// of the block itself, must be available.
+func (w *worker) backtest(ctx context.Context, from, to uint64, algo string, bundles []types.MevBundle, mempool []MempoolTx) (*BacktestReport, error) {
+	algoType := w.algorithm()
+	if algo != "" {
+		var err error
+		if algoType, err = AlgoTypeFlagToEnum(algo); err != nil {
+			return nil, err
+		}
+	}
+	if algoType == ALGO_MEV_GETH {
+		return nil, errBacktestMevGeth
+	}
+	switch {
+	case from == 0:
+		return nil, errors.New("genesis block cannot be backtested")
+	case from > to:
+		return nil, fmt.Errorf("invalid block range %d-%d", from, to)
+	case to-from >= maxBacktestBlocks:
+		return nil, fmt.Errorf("block range larger than %d blocks", maxBacktestBlocks)
+	case to > w.chain.CurrentBlock().Number.Uint64():
+		return nil, fmt.Errorf("block %d not found", to)
+	}
+
+	recorded := make(map[uint64][]types.MevBundle)
+	for _, bundle := range bundles {
+		if bundle.BlockNumber == nil || !bundle.BlockNumber.IsUint64() {
+			continue
+		}
+		recorded[bundle.BlockNumber.Uint64()] = append(recorded[bundle.BlockNumber.Uint64()], bundle)
+	}
+
+	report := &BacktestReport{
+		Algo:      algoType.String(),
+		FromBlock: hexutil.Uint64(from),
+		ToBlock:   hexutil.Uint64(to),
+		Blocks:    make([]BlockBacktest, 0, to-from+1),
+	}
+	landedTotal, builtTotal := new(big.Int), new(big.Int)
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block := w.chain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block %d not found", number)
+		}
+		result, err := w.backtestBlock(algoType, block, recorded[number], mempool)
+		if err != nil {
+			log.Debug("Failed to backtest block", "number", number, "err", err)
+			report.Failed++
+			report.Blocks = append(report.Blocks, BlockBacktest{
+				Number:     hexutil.Uint64(number),
+				LandedHash: block.Hash(),
+				LandedTxs:  len(block.Transactions()),
+				Error:      err.Error(),
+			})
+			continue
+		}
+		switch result.BuiltProfit.ToInt().Cmp(result.LandedProfit.ToInt()) {
+		case 1:
+			report.Better++
+		case -1:
+			report.Worse++
+		}
+		landedTotal.Add(landedTotal, result.LandedProfit.ToInt())
+		builtTotal.Add(builtTotal, result.BuiltProfit.ToInt())
+		report.Blocks = append(report.Blocks, *result)
+	}
+	report.LandedProfit = (*hexutil.Big)(landedTotal)
+	report.BuiltProfit = (*hexutil.Big)(builtTotal)
+	return report, nil
+}
+
+// backtestBlock builds a block with the algorithm on top of the parent of the landed block, with
+// the same header fields and coinbase, and compares the profit of both blocks. The bundles are
+// simulated against the parent state. The mempool of the block is made of the pending
+// transactions of the mempool dataset if any, of the transactions of the landed block otherwise;
+// these are used as synthetic bundles if there are no bundles.
+func (w *worker) backtestBlock(algo AlgoType, block *types.Block, bundles []types.MevBundle, mempool []MempoolTx) (*BlockBacktest, error) {
+	parent := w.chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent of block %d not found", block.NumberU64())
+	}
+	header := types.CopyHeader(block.Header())
+	env, err := w.makeEnv(parent, header, header.Coinbase)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d unavailable: %w", parent.Number.Uint64(), err)
+	}
+	defer env.discard()
+
+	landedProfit, err := w.landedProfit(env, block)
+	if err != nil {
+		return nil, err
+	}
+
+	// the transactions of the landed block are the mempool of the backtest, unless they are
+	// replayed as synthetic bundles or replaced by the pending transactions of the dataset
+	senders := make([]common.Address, 0)
+	transactions := make(map[common.Address]types.Transactions)
+	for _, tx := range block.Transactions() {
+		from, err := types.Sender(env.signer, tx)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := transactions[from]; !ok {
+			senders = append(senders, from)
+		}
+		transactions[from] = append(transactions[from], tx)
+	}
+	var mempoolTxs int
+	if mempool != nil {
+		transactions, mempoolTxs = pendingMempoolTxs(mempool, env.signer, block.NumberU64(), time.Unix(int64(block.Time()), 0))
+	}
+	synthetic := len(bundles) == 0 && mempool == nil
+	if synthetic {
+		bundles = make([]types.MevBundle, 0, len(senders))
+		for _, sender := range senders {
+			bundles = append(bundles, types.MevBundle{
+				Txs:         transactions[sender],
+				BlockNumber: block.Number(),
+				Hash:        txpool.MevBundleHash(transactions[sender]),
+			})
+		}
+		transactions = make(map[common.Address]types.Transactions)
+	}
+
+	simBundles := make([]types.SimulatedBundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		if len(bundle.Txs) == 0 {
+			continue
+		}
+		gasPool := new(core.GasPool).AddGas(header.GasLimit)
+		simmed, err := w.computeBundleGas(env, bundle, env.state.Copy(), gasPool, nil, 0)
+		if err != nil {
+			log.Trace("Backtest bundle simulation failed", "number", block.NumberU64(), "bundle", bundle.Hash, "err", err)
+			continue
+		}
+		simBundles = append(simBundles, simmed)
+	}
+
+	builder, err := w.newBlockBuilder(algo, env, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	built, builtBundles, _ := builder.buildBlock(simBundles, nil, transactions)
+
+	return &BlockBacktest{
+		Number:       hexutil.Uint64(block.NumberU64()),
+		LandedHash:   block.Hash(),
+		Synthetic:    synthetic,
+		Bundles:      len(bundles),
+		MempoolTxs:   mempoolTxs,
+		LandedTxs:    len(block.Transactions()),
+		LandedProfit: (*hexutil.Big)(landedProfit),
+		BuiltTxs:     len(built.txs),
+		BuiltBundles: len(builtBundles),
+		BuiltProfit:  (*hexutil.Big)(new(big.Int).Set(built.profit)),
+	}, nil
+}
+
+// landedProfit returns the profit of the transactions of the landed block, replayed on a copy of
+// the state of the environment. Unlike the balance of the coinbase in the landed state, it leaves
+// out the rewards the consensus engine pays to the coinbase, which the built block has not.
+func (w *worker) landedProfit(env *environment, block *types.Block) (*big.Int, error) {
+	var (
+		statedb = env.state.Copy()
+		header  = types.CopyHeader(block.Header())
+		gasPool = new(core.GasPool).AddGas(header.GasLimit)
+		gasUsed uint64
+	)
+	before := new(big.Int).Set(statedb.GetBalance(header.Coinbase))
+	for i, tx := range block.Transactions() {
+		statedb.SetTxContext(tx.Hash(), i)
+		if _, err := core.ApplyTransaction(w.chainConfig, w.chain, &header.Coinbase, gasPool, statedb, header, tx, &gasUsed, *w.chain.GetVMConfig(), nil); err != nil {
+			return nil, fmt.Errorf("failed to replay transaction %s of block %d: %w", tx.Hash(), block.NumberU64(), err)
+		}
+	}
+	return new(big.Int).Sub(statedb.GetBalance(header.Coinbase), before), nil
+}
@@ -0,0 +1,192 @@
+package miner
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// backtestMempoolMaxAge is the longest time a transaction of a mempool dataset stays pending in
+// a backtest after it was first seen
+const backtestMempoolMaxAge = time.Hour
+
+// MempoolTx is a transaction of a historical mempool dataset.
+type MempoolTx struct {
+	Tx   *types.Transaction
+	Seen time.Time // First seen in the mempool
+	// IncludedAt is the block the transaction was included in, 0 if unknown or never included
+	IncludedAt uint64
+}
+
+// mempoolTimeLayouts are the layouts of the timestamps of the datasets converted from parquet
+var mempoolTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// LoadMempoolDataset reads a historical mempool dataset from a CSV file, compressed or not in a
+// zip archive like the mempool-dumpster releases. See ReadMempoolDataset for the format.
+func LoadMempoolDataset(path string, chainID *big.Int) ([]MempoolTx, error) {
+	if strings.HasSuffix(path, ".zip") {
+		archive, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, err
+		}
+		defer archive.Close()
+		for _, file := range archive.File {
+			if !strings.HasSuffix(file.Name, ".csv") {
+				continue
+			}
+			r, err := file.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return ReadMempoolDataset(r, chainID)
+		}
+		return nil, fmt.Errorf("no CSV file in %s", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadMempoolDataset(f, chainID)
+}
+
+// ReadMempoolDataset reads a historical mempool dataset in the CSV format of mempool-dumpster,
+// with a header row. The columns are matched by name, in snake or camel case: the time the
+// transaction was first seen (timestamp_ms in milliseconds, or timestamp) and the signed
+// transaction (raw_tx, hex encoded) are required, the block it was included in
+// (included_at_block_height) is optional. The parquet files are read once converted to CSV, e.g.
+// with duckdb:
+//
+//	COPY (SELECT timestamp, hex(rawTx) AS rawTx, includedAtBlockHeight FROM 'transactions.parquet') TO 'transactions.csv'
+//
+// The transactions of other chains are skipped. The transactions are returned in the order they
+// were seen.
+func ReadMempoolDataset(r io.Reader, chainID *big.Int) ([]MempoolTx, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid mempool dataset header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "")] = i
+	}
+	column := func(names ...string) int {
+		for _, name := range names {
+			if i, ok := columns[name]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+	var (
+		timestampMs = column("timestampms")
+		timestamp   = column("timestamp")
+		rawTx       = column("rawtx")
+		includedAt  = column("includedatblockheight")
+	)
+	if timestampMs < 0 && timestamp < 0 {
+		return nil, errors.New("mempool dataset without timestamp column")
+	}
+	if rawTx < 0 {
+		return nil, errors.New("mempool dataset without raw transaction column")
+	}
+
+	var txs []MempoolTx
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid mempool dataset: %w", err)
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(common.FromHex(record[rawTx])); err != nil {
+			return nil, fmt.Errorf("line %d: invalid transaction: %w", line, err)
+		}
+		if chainID != nil && tx.Protected() && tx.ChainId().Cmp(chainID) != 0 {
+			continue
+		}
+		entry := MempoolTx{Tx: tx}
+		if timestampMs >= 0 {
+			ms, err := strconv.ParseInt(record[timestampMs], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid timestamp: %w", line, err)
+			}
+			entry.Seen = time.UnixMilli(ms)
+		} else if entry.Seen, err = parseMempoolTime(record[timestamp]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if includedAt >= 0 && record[includedAt] != "" {
+			if entry.IncludedAt, err = strconv.ParseUint(record[includedAt], 10, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid inclusion block: %w", line, err)
+			}
+		}
+		txs = append(txs, entry)
+	}
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].Seen.Before(txs[j].Seen) })
+	return txs, nil
+}
+
+func parseMempoolTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	for _, layout := range mempoolTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", value)
+}
+
+// pendingMempoolTxs returns the transactions of the dataset, sorted by seen time, pending when
+// the block was built: seen in the backtestMempoolMaxAge before the block and not included in an
+// earlier block. The transactions replaced by a transaction with the same nonce seen later are
+// dropped.
+func pendingMempoolTxs(mempool []MempoolTx, signer types.Signer, number uint64, blockTime time.Time) (map[common.Address]types.Transactions, int) {
+	start := sort.Search(len(mempool), func(i int) bool { return !mempool[i].Seen.Before(blockTime.Add(-backtestMempoolMaxAge)) })
+	end := sort.Search(len(mempool), func(i int) bool { return !mempool[i].Seen.Before(blockTime) })
+
+	type senderNonce struct {
+		sender common.Address
+		nonce  uint64
+	}
+	latest := make(map[senderNonce]*types.Transaction)
+	for _, pending := range mempool[start:end] {
+		if pending.IncludedAt != 0 && pending.IncludedAt < number {
+			continue
+		}
+		sender, err := types.Sender(signer, pending.Tx)
+		if err != nil {
+			continue
+		}
+		latest[senderNonce{sender, pending.Tx.Nonce()}] = pending.Tx
+	}
+
+	txs := make(map[common.Address]types.Transactions)
+	for key, tx := range latest {
+		txs[key.sender] = append(txs[key.sender], tx)
+	}
+	for _, senderTxs := range txs {
+		sort.Sort(types.TxByNonce(senderTxs))
+	}
+	return txs, len(latest)
+}
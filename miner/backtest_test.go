@@ -0,0 +1,192 @@
+package miner
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestBacktest(t *testing.T) {
+	w, b := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), defaultGenesisAlloc, 0)
+	defer w.close()
+
+	signer := types.LatestSigner(ethashChainConfig)
+	transfer := func(nonce uint64, baseFee *big.Int) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: new(big.Int).Mul(baseFee, big.NewInt(2)),
+		})
+	}
+	_, blocks, _ := core.GenerateChainWithGenesis(b.genesis, w.engine, 2, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(testAddress1)
+		if i == 0 {
+			gen.AddTx(transfer(gen.TxNonce(testBankAddress), gen.BaseFee()))
+		}
+	})
+	if _, err := w.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	// block 2 is empty, the recorded bundle makes it more profitable
+	recorded := types.MevBundle{
+		Txs:         types.Transactions{transfer(1, blocks[1].BaseFee())},
+		BlockNumber: big.NewInt(2),
+	}
+	report, err := w.backtest(context.Background(), 1, 2, ALGO_GREEDY.String(), []types.MevBundle{recorded}, nil)
+	if err != nil {
+		t.Fatalf("backtest failed: %v", err)
+	}
+	if len(report.Blocks) != 2 || report.Failed != 0 || report.Better != 1 || report.Worse != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// tip of a transfer paying twice the base fee
+	tip := func(block *types.Block) *big.Int {
+		return new(big.Int).Mul(block.BaseFee(), big.NewInt(int64(params.TxGas)))
+	}
+	first := report.Blocks[0]
+	if !first.Synthetic || first.Bundles != 1 || first.LandedTxs != 1 || first.BuiltTxs != 1 {
+		t.Errorf("unexpected synthetic backtest %+v", first)
+	}
+	if first.LandedProfit.ToInt().Cmp(tip(blocks[0])) != 0 || first.BuiltProfit.ToInt().Cmp(tip(blocks[0])) != 0 {
+		t.Errorf("unexpected synthetic backtest profit landed %v built %v", first.LandedProfit, first.BuiltProfit)
+	}
+	second := report.Blocks[1]
+	if second.Synthetic || second.Bundles != 1 || second.LandedTxs != 0 || second.BuiltBundles != 1 {
+		t.Errorf("unexpected recorded backtest %+v", second)
+	}
+	if second.LandedProfit.ToInt().Sign() != 0 || second.BuiltProfit.ToInt().Cmp(tip(blocks[1])) != 0 {
+		t.Errorf("unexpected recorded backtest profit landed %v built %v", second.LandedProfit, second.BuiltProfit)
+	}
+	if report.BuiltProfit.ToInt().Cmp(new(big.Int).Add(tip(blocks[0]), tip(blocks[1]))) != 0 {
+		t.Errorf("unexpected total built profit %v", report.BuiltProfit)
+	}
+
+	// the configured mev-geth algorithm cannot be backtested
+	if _, err := w.backtest(context.Background(), 1, 2, "", nil, nil); !errors.Is(err, errBacktestMevGeth) {
+		t.Errorf("unexpected error %v", err)
+	}
+	for _, r := range [][2]uint64{{0, 1}, {2, 1}, {1, 3}, {1, maxBacktestBlocks + 1}} {
+		if _, err := w.backtest(context.Background(), r[0], r[1], ALGO_GREEDY.String(), nil, nil); err == nil {
+			t.Errorf("range %d-%d: expected error", r[0], r[1])
+		}
+	}
+}
+
+func TestBacktestMempool(t *testing.T) {
+	w, b := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), defaultGenesisAlloc, 0)
+	defer w.close()
+
+	signer := types.LatestSigner(ethashChainConfig)
+	transfer := func(nonce uint64, baseFee *big.Int) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: new(big.Int).Mul(baseFee, big.NewInt(2)),
+		})
+	}
+	_, blocks, _ := core.GenerateChainWithGenesis(b.genesis, w.engine, 2, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(testAddress1)
+		if i == 0 {
+			gen.AddTx(transfer(gen.TxNonce(testBankAddress), gen.BaseFee()))
+		}
+	})
+	if _, err := w.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+
+	// the first transaction is pending until its inclusion in block 1, the second one, seen
+	// after block 1, is never included
+	seen := func(block *types.Block) time.Time { return time.Unix(int64(block.Time())-1, 0) }
+	mempool := []MempoolTx{
+		{Tx: blocks[0].Transactions()[0], Seen: seen(blocks[0]), IncludedAt: 1},
+		{Tx: transfer(1, blocks[1].BaseFee()), Seen: seen(blocks[1])},
+	}
+	report, err := w.backtest(context.Background(), 1, 2, ALGO_GREEDY.String(), nil, mempool)
+	if err != nil {
+		t.Fatalf("backtest failed: %v", err)
+	}
+	if len(report.Blocks) != 2 || report.Failed != 0 || report.Better != 1 || report.Worse != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	for i, block := range report.Blocks {
+		if block.Synthetic || block.Bundles != 0 || block.MempoolTxs != 1 || block.BuiltTxs != 1 {
+			t.Errorf("block %d: unexpected backtest %+v", i+1, block)
+		}
+		tip := new(big.Int).Mul(blocks[i].BaseFee(), big.NewInt(int64(params.TxGas)))
+		if block.BuiltProfit.ToInt().Cmp(tip) != 0 {
+			t.Errorf("block %d: unexpected built profit %v", i+1, block.BuiltProfit)
+		}
+	}
+}
+
+func TestReadMempoolDataset(t *testing.T) {
+	signer := types.LatestSigner(ethashChainConfig)
+	transfer := func(nonce uint64, chainID *big.Int) string {
+		tx := types.MustSignNewTx(testBankKey, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			To:        &testUserAddress,
+			Gas:       params.TxGas,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(params.InitialBaseFee),
+		})
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			t.Fatalf("failed to encode transaction: %v", err)
+		}
+		return hexutil.Encode(raw)
+	}
+	chainID := ethashChainConfig.ChainID
+
+	// mempool-dumpster CSV, the rows are not in the order the transactions were seen
+	dataset := "timestamp_ms,chain_id,raw_tx,included_at_block_height\n" +
+		"1693526400500," + chainID.String() + "," + transfer(1, chainID) + ",\n" +
+		"1693526400000," + chainID.String() + "," + transfer(0, chainID) + ",17000000\n" +
+		"1693526400200,137," + transfer(0, big.NewInt(137)) + ",\n"
+	txs, err := ReadMempoolDataset(strings.NewReader(dataset), chainID)
+	if err != nil {
+		t.Fatalf("failed to read dataset: %v", err)
+	}
+	if len(txs) != 2 {
+		t.Fatalf("unexpected number of transactions %d", len(txs))
+	}
+	if txs[0].Tx.Nonce() != 0 || txs[0].IncludedAt != 17000000 || !txs[0].Seen.Equal(time.UnixMilli(1693526400000)) {
+		t.Errorf("unexpected first transaction %+v", txs[0])
+	}
+	if txs[1].Tx.Nonce() != 1 || txs[1].IncludedAt != 0 {
+		t.Errorf("unexpected second transaction %+v", txs[1])
+	}
+	if from, err := types.Sender(signer, txs[0].Tx); err != nil || from != testBankAddress {
+		t.Errorf("unexpected sender %v: %v", from, err)
+	}
+
+	// converted from parquet
+	dataset = "timestamp,rawTx,includedAtBlockHeight\n" +
+		"2023-09-01 00:00:00.5," + strings.TrimPrefix(transfer(0, chainID), "0x") + ",\n"
+	if txs, err = ReadMempoolDataset(strings.NewReader(dataset), chainID); err != nil {
+		t.Fatalf("failed to read converted dataset: %v", err)
+	}
+	if len(txs) != 1 || !txs[0].Seen.Equal(time.UnixMilli(1693526400500)) {
+		t.Errorf("unexpected converted dataset %+v", txs)
+	}
+
+	if _, err := ReadMempoolDataset(strings.NewReader("hash,timestamp_ms\n"), chainID); err == nil {
+		t.Error("expected error for a dataset without raw transactions")
+	}
+}
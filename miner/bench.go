@@ -0,0 +1,414 @@
+package miner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Stages of the block building pipeline timed by the benchmark
+const (
+	BenchStageSimulate = "simulate" // Simulation of the bundles of the workload
+	BenchStagePack     = "pack"     // Block building algorithm
+	BenchStageFinalize = "finalize" // Block finalization
+	BenchStageTotal    = "total"
+)
+
+var benchStages = []string{BenchStageSimulate, BenchStagePack, BenchStageFinalize, BenchStageTotal}
+
+var (
+	// benchCoinbase is the coinbase of the blocks built by the benchmark, the canned bundles pay it
+	benchCoinbase = common.HexToAddress("0x00000000000000000000000000000000000bE7c4")
+	// benchBalance is the balance of the senders of the workload in the benchmark genesis
+	benchBalance = new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(params.Ether))
+)
+
+// BenchWorkload is the set of transactions and bundles the block building algorithms are
+// benchmarked with.
+type BenchWorkload struct {
+	Txs     types.Transactions // Mempool transactions
+	Bundles []types.MevBundle
+}
+
+// NewBenchWorkload generates a canned workload of transfers from the seed. The mempool holds
+// sequences of up to 4 transactions per sender with random tips, the bundles pay the coinbase
+// directly and a quarter of them compete with an earlier bundle for the same sender nonce.
+func NewBenchWorkload(seed int64, txs, bundles int) (*BenchWorkload, error) {
+	var (
+		rng     = rand.New(rand.NewSource(seed))
+		signer  = types.LatestSignerForChainID(params.AllEthashProtocolChanges.ChainID)
+		account = 0
+	)
+	nextKey := func() []byte {
+		var buf [16]byte
+		binary.BigEndian.PutUint64(buf[:8], uint64(seed))
+		binary.BigEndian.PutUint64(buf[8:], uint64(account))
+		account++
+		return crypto.Keccak256(buf[:])
+	}
+	sign := func(key []byte, nonce uint64, to common.Address, value *big.Int) (*types.Transaction, error) {
+		prv, err := crypto.ToECDSA(key)
+		if err != nil {
+			return nil, err
+		}
+		tip := big.NewInt(int64(1+rng.Intn(100)) * params.GWei)
+		return types.SignNewTx(prv, signer, &types.DynamicFeeTx{
+			ChainID:   signer.ChainID(),
+			Nonce:     nonce,
+			GasTipCap: tip,
+			GasFeeCap: new(big.Int).Add(tip, big.NewInt(100*params.GWei)),
+			Gas:       params.TxGas,
+			To:        &to,
+			Value:     value,
+		})
+	}
+
+	workload := &BenchWorkload{
+		Txs:     make(types.Transactions, 0, txs),
+		Bundles: make([]types.MevBundle, 0, bundles),
+	}
+	for len(workload.Txs) < txs {
+		key, n := nextKey(), 1+rng.Intn(4)
+		for nonce := uint64(0); nonce < uint64(n) && len(workload.Txs) < txs; nonce++ {
+			tx, err := sign(key, nonce, common.BytesToAddress(nextKey()), big.NewInt(1))
+			if err != nil {
+				return nil, err
+			}
+			workload.Txs = append(workload.Txs, tx)
+		}
+	}
+	var bundleKeys [][]byte
+	for i := 0; i < bundles; i++ {
+		key := nextKey()
+		if i%4 == 3 {
+			key = bundleKeys[rng.Intn(len(bundleKeys))]
+		} else {
+			bundleKeys = append(bundleKeys, key)
+		}
+		n := 1 + rng.Intn(3)
+		bundleTxs := make(types.Transactions, 0, n)
+		for nonce := uint64(0); nonce < uint64(n); nonce++ {
+			to, value := common.BytesToAddress(nextKey()), big.NewInt(1)
+			if int(nonce) == n-1 {
+				// the last transaction of the bundle pays the coinbase
+				to, value = benchCoinbase, big.NewInt(int64(1+rng.Intn(10))*params.GWei*1_000_000)
+			}
+			tx, err := sign(key, nonce, to, value)
+			if err != nil {
+				return nil, err
+			}
+			bundleTxs = append(bundleTxs, tx)
+		}
+		workload.Bundles = append(workload.Bundles, types.MevBundle{
+			Txs:         bundleTxs,
+			BlockNumber: big.NewInt(1),
+			Hash:        txpool.MevBundleHash(bundleTxs),
+		})
+	}
+	return workload, nil
+}
+
+// BenchConfig is the configuration of a benchmark.
+type BenchConfig struct {
+	Algos    []AlgoType
+	Rounds   int    // Blocks built per algorithm
+	GasLimit uint64 // Gas limit of the blocks
+}
+
+// StageLatency is the latency of a stage of the block building pipeline over the rounds.
+type StageLatency struct {
+	Stage string        `json:"stage"`
+	P50   time.Duration `json:"p50"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+// AlgoBench is the outcome of the benchmark of an algorithm.
+type AlgoBench struct {
+	Algo             string         `json:"algo"`
+	Profit           *hexutil.Big   `json:"profit"` // Paid to the coinbase by the built block
+	GasUsed          uint64         `json:"gasUsed"`
+	Txs              int            `json:"txs"`
+	Bundles          int            `json:"bundles"`
+	SimulatedBundles int            `json:"simulatedBundles"` // Bundles of the workload simulated successfully
+	Latencies        []StageLatency `json:"latencies"`
+	// Allocations of the runtime per built block
+	AllocBytes uint64 `json:"allocBytes"`
+	Allocs     uint64 `json:"allocs"`
+}
+
+// BenchReport is the outcome of a benchmark, with the environment it ran in to compare releases
+// and hardware.
+type BenchReport struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	CPUs      int    `json:"cpus"`
+
+	Txs      int    `json:"txs"` // Mempool transactions of the workload
+	Bundles  int    `json:"bundles"`
+	Senders  int    `json:"senders"`
+	GasLimit uint64 `json:"gasLimit"`
+	Rounds   int    `json:"rounds"`
+
+	Algos []AlgoBench `json:"algos"`
+}
+
+// RunBench builds blocks with every algorithm of the config over the workload, on top of a
+// genesis funding the senders of the workload, and reports the profit of the blocks with the
+// latency of the stages of the pipeline. Recorded workloads calling contracts do not reproduce
+// the original executions, the benchmark state holds only the senders.
+func RunBench(config BenchConfig, workload *BenchWorkload) (*BenchReport, error) {
+	if config.Rounds <= 0 {
+		return nil, errors.New("at least one round is required")
+	}
+	for _, algo := range config.Algos {
+		if algo == ALGO_MEV_GETH {
+			return nil, errors.New("the mev-geth algorithm cannot be benchmarked")
+		}
+	}
+	bench, err := newBenchWorker(config.GasLimit, workload)
+	if err != nil {
+		return nil, err
+	}
+	defer bench.chain.Stop()
+
+	report := &BenchReport{
+		Version:   params.VersionWithMeta,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+		Txs:       len(workload.Txs),
+		Bundles:   len(workload.Bundles),
+		Senders:   bench.senders,
+		GasLimit:  config.GasLimit,
+		Rounds:    config.Rounds,
+		Algos:     make([]AlgoBench, 0, len(config.Algos)),
+	}
+	for _, algo := range config.Algos {
+		result, err := bench.run(algo, config.Rounds, workload.Bundles)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", algo, err)
+		}
+		report.Algos = append(report.Algos, *result)
+	}
+	return report, nil
+}
+
+// benchWorker builds the blocks of a benchmark.
+type benchWorker struct {
+	*worker
+	parent  *types.Header
+	header  *types.Header                         // Header of the block built in every round
+	pending map[common.Address]types.Transactions // Mempool transactions by sender
+	senders int
+}
+
+// newBenchWorker creates a worker on an in-memory chain whose genesis funds the senders of the
+// workload, starting at their lowest nonce.
+func newBenchWorker(gasLimit uint64, workload *BenchWorkload) (*benchWorker, error) {
+	chainConfig := *params.AllEthashProtocolChanges
+	bundleTxs := benchBundleTxs(workload.Bundles)
+	for _, txs := range []types.Transactions{workload.Txs, bundleTxs} {
+		if chainID := benchChainID(txs); chainID != nil {
+			chainConfig.ChainID = chainID
+			break
+		}
+	}
+	signer := types.LatestSignerForChainID(chainConfig.ChainID)
+
+	alloc := make(core.GenesisAlloc)
+	addSender := func(tx *types.Transaction) (common.Address, error) {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("invalid transaction %s: %w", tx.Hash(), err)
+		}
+		if account, ok := alloc[from]; !ok || tx.Nonce() < account.Nonce {
+			alloc[from] = core.GenesisAccount{Balance: benchBalance, Nonce: tx.Nonce()}
+		}
+		return from, nil
+	}
+	pending := make(map[common.Address]types.Transactions)
+	for _, tx := range workload.Txs {
+		from, err := addSender(tx)
+		if err != nil {
+			return nil, err
+		}
+		pending[from] = append(pending[from], tx)
+	}
+	for _, tx := range bundleTxs {
+		if _, err := addSender(tx); err != nil {
+			return nil, err
+		}
+	}
+	for _, txs := range pending {
+		sort.Sort(types.TxByNonce(txs))
+	}
+
+	engine := ethash.NewFaker()
+	genesis := &core.Genesis{
+		Config:   &chainConfig,
+		Alloc:    alloc,
+		GasLimit: gasLimit,
+		BaseFee:  big.NewInt(params.InitialBaseFee),
+	}
+	chain, err := core.NewBlockChain(rawdb.NewMemoryDatabase(), nil, genesis, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	minerConfig := DefaultConfig
+	w := &worker{
+		config:      &minerConfig,
+		chainConfig: chain.Config(),
+		engine:      engine,
+		chain:       chain,
+		flashbots:   &flashbotsData{},
+	}
+
+	parent := chain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   gasLimit,
+		Time:       parent.Time + 12,
+		Coinbase:   benchCoinbase,
+		BaseFee:    misc.CalcBaseFee(w.chainConfig, parent),
+	}
+	if err := engine.Prepare(chain, header); err != nil {
+		chain.Stop()
+		return nil, err
+	}
+	return &benchWorker{worker: w, parent: parent, header: header, pending: pending, senders: len(alloc)}, nil
+}
+
+// run builds the block with the algorithm for the given number of rounds, the bundles are
+// simulated again in every round.
+func (w *benchWorker) run(algo AlgoType, rounds int, bundles []types.MevBundle) (*AlgoBench, error) {
+	var (
+		header    = w.header
+		latencies = make(map[string][]time.Duration, len(benchStages))
+		result    = &AlgoBench{Algo: algo.String()}
+
+		allocBytes, allocs uint64
+		before, after      runtime.MemStats
+	)
+	for round := 0; round < rounds; round++ {
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+
+		env, err := w.makeEnv(w.parent, types.CopyHeader(header), header.Coinbase)
+		if err != nil {
+			return nil, err
+		}
+		simBundles := make([]types.SimulatedBundle, 0, len(bundles))
+		for _, bundle := range bundles {
+			gasPool := new(core.GasPool).AddGas(header.GasLimit)
+			simmed, err := w.computeBundleGas(env, bundle, env.state.Copy(), gasPool, nil, 0)
+			if err != nil {
+				continue
+			}
+			simBundles = append(simBundles, simmed)
+		}
+		simulated := time.Now()
+
+		// the algorithms remove the included transactions from the mempool they are given
+		transactions := make(map[common.Address]types.Transactions, len(w.pending))
+		for from, txs := range w.pending {
+			transactions[from] = txs
+		}
+		builder, err := w.newBlockBuilder(algo, env, nil, nil)
+		if err != nil {
+			env.discard()
+			return nil, err
+		}
+		built, builtBundles, _ := builder.buildBlock(simBundles, nil, transactions)
+		packed := time.Now()
+
+		block, _, err := w.finalizeBlock(built, nil, header.Coinbase, false)
+		env.discard()
+		if err != nil {
+			return nil, err
+		}
+		finalized := time.Now()
+		runtime.ReadMemStats(&after)
+
+		latencies[BenchStageSimulate] = append(latencies[BenchStageSimulate], simulated.Sub(start))
+		latencies[BenchStagePack] = append(latencies[BenchStagePack], packed.Sub(simulated))
+		latencies[BenchStageFinalize] = append(latencies[BenchStageFinalize], finalized.Sub(packed))
+		latencies[BenchStageTotal] = append(latencies[BenchStageTotal], finalized.Sub(start))
+		allocBytes += after.TotalAlloc - before.TotalAlloc
+		allocs += after.Mallocs - before.Mallocs
+
+		result.Profit = (*hexutil.Big)(new(big.Int).Set(built.profit))
+		result.GasUsed = block.GasUsed()
+		result.Txs = len(block.Transactions())
+		result.Bundles = len(builtBundles)
+		result.SimulatedBundles = len(simBundles)
+	}
+	for _, stage := range benchStages {
+		result.Latencies = append(result.Latencies, newStageLatency(stage, latencies[stage]))
+	}
+	result.AllocBytes = allocBytes / uint64(rounds)
+	result.Allocs = allocs / uint64(rounds)
+	return result, nil
+}
+
+// newStageLatency computes the percentiles of the latencies of a stage.
+func newStageLatency(stage string, latencies []time.Duration) StageLatency {
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return StageLatency{
+		Stage: stage,
+		P50:   percentile(sorted, 50),
+		P99:   percentile(sorted, 99),
+		Max:   percentile(sorted, 100),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of the sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func benchBundleTxs(bundles []types.MevBundle) types.Transactions {
+	var txs types.Transactions
+	for _, bundle := range bundles {
+		txs = append(txs, bundle.Txs...)
+	}
+	return txs
+}
+
+// benchChainID returns the chain id of the first replay-protected transaction, nil if none is.
+func benchChainID(txs types.Transactions) *big.Int {
+	for _, tx := range txs {
+		if tx.Protected() {
+			return tx.ChainId()
+		}
+	}
+	return nil
+}
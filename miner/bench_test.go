@@ -0,0 +1,82 @@
+package miner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunBench(t *testing.T) {
+	workload, err := NewBenchWorkload(1, 100, 20)
+	if err != nil {
+		t.Fatalf("failed to generate workload: %v", err)
+	}
+	if len(workload.Txs) != 100 || len(workload.Bundles) != 20 {
+		t.Fatalf("unexpected workload: %d txs, %d bundles", len(workload.Txs), len(workload.Bundles))
+	}
+	algos := []AlgoType{ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP}
+	report, err := RunBench(BenchConfig{Algos: algos, Rounds: 3, GasLimit: 30_000_000}, workload)
+	if err != nil {
+		t.Fatalf("benchmark failed: %v", err)
+	}
+	if report.Txs != 100 || report.Bundles != 20 || report.Rounds != 3 {
+		t.Fatalf("unexpected report workload: %+v", report)
+	}
+	if len(report.Algos) != len(algos) {
+		t.Fatalf("expected %d algorithms, got %d", len(algos), len(report.Algos))
+	}
+	for i, result := range report.Algos {
+		if result.Algo != algos[i].String() {
+			t.Errorf("algorithm %d: expected %s, got %s", i, algos[i], result.Algo)
+		}
+		if result.Profit.ToInt().Sign() <= 0 {
+			t.Errorf("%s: expected profit, got %v", result.Algo, result.Profit)
+		}
+		// competing bundles of the same sender fail after the first one is included
+		if result.Bundles == 0 || result.Bundles >= result.SimulatedBundles {
+			t.Errorf("%s: unexpected bundles included %d, simulated %d", result.Algo, result.Bundles, result.SimulatedBundles)
+		}
+		if result.Txs <= result.Bundles || result.GasUsed == 0 {
+			t.Errorf("%s: expected mempool transactions in the block, got %d txs", result.Algo, result.Txs)
+		}
+		if len(result.Latencies) != len(benchStages) {
+			t.Fatalf("%s: expected %d stages, got %d", result.Algo, len(benchStages), len(result.Latencies))
+		}
+		for _, latency := range result.Latencies {
+			if latency.P50 > latency.P99 || latency.P99 > latency.Max {
+				t.Errorf("%s: unordered %s percentiles %+v", result.Algo, latency.Stage, latency)
+			}
+		}
+		if result.Allocs == 0 {
+			t.Errorf("%s: expected allocations", result.Algo)
+		}
+	}
+
+	if _, err := RunBench(BenchConfig{Algos: []AlgoType{ALGO_MEV_GETH}, Rounds: 1, GasLimit: 30_000_000}, workload); err == nil {
+		t.Error("expected mev-geth benchmark to fail")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i + 1)
+	}
+	for _, test := range []struct {
+		durations []time.Duration
+		p         int
+		want      time.Duration
+	}{
+		{nil, 50, 0},
+		{sorted[:1], 50, 1},
+		{sorted[:1], 99, 1},
+		{sorted[:3], 50, 2},
+		{sorted[:3], 99, 3},
+		{sorted, 50, 50},
+		{sorted, 99, 99},
+		{sorted, 100, 100},
+	} {
+		if got := percentile(test.durations, test.p); got != test.want {
+			t.Errorf("p%d of %d durations: expected %v, got %v", test.p, len(test.durations), test.want, got)
+		}
+	}
+}
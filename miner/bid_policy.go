@@ -0,0 +1,177 @@
+package miner
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// Bid policies deciding the value of the proposer payment
+const (
+	BidPolicyFull       = "full"       // Pay the whole block profit
+	BidPolicyFixed      = "fixed"      // Keep a fixed margin
+	BidPolicyPercentage = "percentage" // Keep a percentage of the block profit
+	BidPolicyDynamic    = "dynamic"    // Bid the median of the recent winning bids, keeping up to a percentage of the profit
+)
+
+// defaultBidPolicyConfig pays the whole block profit to the proposer.
+var defaultBidPolicyConfig = BidPolicyConfig{
+	Type:   BidPolicyFull,
+	Blocks: 32,
+}
+
+// BidPolicyConfig configures the value paid to the proposer out of the profit of a block, the
+// rest of the profit is kept by the builder.
+type BidPolicyConfig struct {
+	Type          string   // Bid policy, full, fixed, percentage or dynamic
+	Margin        *big.Int `toml:",omitempty"` // Wei kept per block by the fixed policy
+	MarginPercent float64  // Percent of the profit kept by the percentage policy, the most kept by the dynamic policy
+	Blocks        int      // Recent canonical blocks whose winning bids the dynamic policy bids against
+}
+
+// Validate checks the policy is known and its parameters are in range.
+func (c *BidPolicyConfig) Validate() error {
+	switch c.Type {
+	case BidPolicyFull:
+	case BidPolicyFixed:
+		if c.Margin == nil || c.Margin.Sign() < 0 {
+			return fmt.Errorf("fixed bid policy requires a positive margin")
+		}
+	case BidPolicyPercentage, BidPolicyDynamic:
+		if c.MarginPercent < 0 || c.MarginPercent > 100 {
+			return fmt.Errorf("invalid bid margin percent %v, expected 0 to 100", c.MarginPercent)
+		}
+		if c.Type == BidPolicyDynamic && c.Blocks <= 0 {
+			return fmt.Errorf("dynamic bid policy requires a positive number of blocks")
+		}
+	default:
+		return fmt.Errorf("unknown bid policy %q", c.Type)
+	}
+	return nil
+}
+
+// bidPolicy decides the value of the proposer payment of the built blocks, tracking the winning
+// bids of the recent canonical blocks for the dynamic policy. It is shared by all workers and safe
+// for concurrent use, a nil instance pays the whole profit.
+type bidPolicy struct {
+	mu     sync.Mutex
+	config BidPolicyConfig
+	heads  []common.Hash // Recent canonical blocks with a winning bid, oldest first
+	bids   []*big.Int    // Winning bids of the heads
+}
+
+// newBidPolicy returns the bid policy of the config, paying the whole profit if it is invalid.
+func newBidPolicy(config BidPolicyConfig) *bidPolicy {
+	p := new(bidPolicy)
+	if err := p.setConfig(config); err != nil {
+		log.Error("Invalid bid policy, paying the whole block profit", "err", err)
+		p.config = defaultBidPolicyConfig
+	}
+	return p
+}
+
+// setConfig replaces the policy, the tracked winning bids are kept.
+func (p *bidPolicy) setConfig(config BidPolicyConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if config.Margin != nil {
+		config.Margin = new(big.Int).Set(config.Margin)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.config = config
+	p.truncate()
+	return nil
+}
+
+// truncate forgets the winning bids over the tracked blocks, the lock must be held.
+func (p *bidPolicy) truncate() {
+	if n := len(p.bids) - p.config.Blocks; n > 0 {
+		p.heads, p.bids = p.heads[n:], p.bids[n:]
+	}
+}
+
+// chainHead records the winning bid of a new canonical block, the value of its last transaction
+// when it is a plain transfer, as the builders pay the proposer.
+func (p *bidPolicy) chainHead(head *types.Block) {
+	if p == nil {
+		return
+	}
+	txs := head.Transactions()
+	if len(txs) == 0 {
+		return
+	}
+	last := txs[len(txs)-1]
+	if last.To() == nil || len(last.Data()) > 0 || last.Value().Sign() <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// every worker reports the chain heads
+	for _, hash := range p.heads {
+		if hash == head.Hash() {
+			return
+		}
+	}
+	p.heads = append(p.heads, head.Hash())
+	p.bids = append(p.bids, new(big.Int).Set(last.Value()))
+	p.truncate()
+}
+
+// payout returns the funds paid to the proposer, payment fee included, out of the funds the block
+// made available to the builder.
+func (p *bidPolicy) payout(available *big.Int) *big.Int {
+	if p == nil {
+		return available
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var margin *big.Int
+	switch p.config.Type {
+	case BidPolicyFixed:
+		margin = new(big.Int).Set(p.config.Margin)
+	case BidPolicyPercentage:
+		margin = percentOf(available, p.config.MarginPercent)
+	case BidPolicyDynamic:
+		// bid the recent winning bids, keeping what the block makes over them up to the maximum margin
+		margin = percentOf(available, p.config.MarginPercent)
+		if len(p.bids) > 0 {
+			over := new(big.Int).Sub(available, p.medianBid())
+			if over.Sign() < 0 {
+				over.SetInt64(0)
+			}
+			if over.Cmp(margin) < 0 {
+				margin = over
+			}
+		}
+	default:
+		return available
+	}
+	if margin.Cmp(available) > 0 {
+		margin.Set(available)
+	}
+	return new(big.Int).Sub(available, margin)
+}
+
+// medianBid returns the median of the tracked winning bids, the lock must be held.
+func (p *bidPolicy) medianBid() *big.Int {
+	bids := make([]*big.Int, len(p.bids))
+	copy(bids, p.bids)
+	sort.Slice(bids, func(i, j int) bool { return bids[i].Cmp(bids[j]) < 0 })
+	return bids[len(bids)/2]
+}
+
+// percentOf returns percent % of value, rounded down.
+func percentOf(value *big.Int, percent float64) *big.Int {
+	result, _ := new(big.Float).Mul(new(big.Float).SetInt(value), big.NewFloat(percent/100)).Int(nil)
+	return result
+}
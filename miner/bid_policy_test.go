@@ -0,0 +1,72 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBidPolicy(t *testing.T) {
+	available := big.NewInt(1000)
+
+	var policy *bidPolicy
+	if payout := policy.payout(available); payout.Int64() != 1000 {
+		t.Errorf("nil policy pays %v, want the whole profit", payout)
+	}
+	policy = newBidPolicy(defaultBidPolicyConfig)
+	if payout := policy.payout(available); payout.Int64() != 1000 {
+		t.Errorf("full policy pays %v, want 1000", payout)
+	}
+	if err := policy.setConfig(BidPolicyConfig{Type: BidPolicyFixed, Margin: big.NewInt(150)}); err != nil {
+		t.Fatal(err)
+	}
+	if payout := policy.payout(available); payout.Int64() != 850 {
+		t.Errorf("fixed policy pays %v, want 850", payout)
+	}
+	if payout := policy.payout(big.NewInt(100)); payout.Sign() != 0 {
+		t.Errorf("fixed policy pays %v over the profit, want 0", payout)
+	}
+	if err := policy.setConfig(BidPolicyConfig{Type: BidPolicyPercentage, MarginPercent: 12.5}); err != nil {
+		t.Fatal(err)
+	}
+	if payout := policy.payout(available); payout.Int64() != 875 {
+		t.Errorf("percentage policy pays %v, want 875", payout)
+	}
+
+	// the dynamic policy keeps the maximum margin until winning bids are known
+	if err := policy.setConfig(BidPolicyConfig{Type: BidPolicyDynamic, MarginPercent: 20, Blocks: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if payout := policy.payout(available); payout.Int64() != 800 {
+		t.Errorf("dynamic policy without bids pays %v, want 800", payout)
+	}
+	for i, bid := range []int64{100, 900, 850, 950, 850} {
+		tx := types.NewTransaction(uint64(i), common.Address{1}, big.NewInt(bid), 21000, big.NewInt(1), nil)
+		head := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i))}).WithBody([]*types.Transaction{tx}, nil)
+		policy.chainHead(head)
+		policy.chainHead(head)
+	}
+	// the builder keeps what the block makes over the median of the last 3 bids, up to the maximum margin
+	if payout := policy.payout(available); payout.Int64() != 850 {
+		t.Errorf("dynamic policy pays %v, want 850", payout)
+	}
+	if payout := policy.payout(big.NewInt(2000)); payout.Int64() != 1600 {
+		t.Errorf("dynamic policy pays %v, want 1600", payout)
+	}
+	if payout := policy.payout(big.NewInt(500)); payout.Int64() != 500 {
+		t.Errorf("dynamic policy pays %v under the winning bids, want 500", payout)
+	}
+
+	for _, config := range []BidPolicyConfig{
+		{Type: "unknown"},
+		{Type: BidPolicyFixed},
+		{Type: BidPolicyPercentage, MarginPercent: 101},
+		{Type: BidPolicyDynamic, MarginPercent: 10},
+	} {
+		if err := policy.setConfig(config); err == nil {
+			t.Errorf("invalid config %+v accepted", config)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/builder/decisionlog"
+)
+
+// buildRoundIDs is the id of the last block building round of the process
+var buildRoundIDs atomic.Uint64
+
+// buildRound is an attempt of a worker at building a block. Its id is logged along the worker,
+// the bundle simulations, the multi-transaction snapshots and the submission of the block, so the
+// logs of an attempt can be followed end-to-end. It also counts the orders discarded in the round
+// by reason. The methods are safe for concurrent use, and no-ops on a nil round, e.g. for the
+// environments which do not build a block.
+type buildRound struct {
+	id uint64
+
+	mu        sync.Mutex
+	discarded map[string]int
+}
+
+func newBuildRound() *buildRound {
+	return &buildRound{
+		id:        buildRoundIDs.Add(1),
+		discarded: make(map[string]int),
+	}
+}
+
+// ID returns the id of the round, 0 for a nil round.
+func (r *buildRound) ID() uint64 {
+	if r == nil {
+		return 0
+	}
+	return r.id
+}
+
+// discard counts an order discarded from the round for the given reason.
+func (r *buildRound) discard(reason string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.discarded[reason]++
+}
+
+// summary returns the orders discarded in the round by reason, reason=count separated by
+// commas, with the bundles and sbundles the algorithm rejected or did not reach.
+func (r *buildRound) summary(decisions *decisionRecorder) string {
+	if r == nil {
+		return ""
+	}
+	r.mu.Lock()
+	discarded := make(map[string]int, len(r.discarded))
+	for reason, count := range r.discarded {
+		discarded[reason] = count
+	}
+	r.mu.Unlock()
+
+	for reason, count := range decisions.discarded() {
+		discarded[reason] += count
+	}
+	reasons := make([]string, 0, len(discarded))
+	for reason := range discarded {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	var summary strings.Builder
+	for i, reason := range reasons {
+		if i > 0 {
+			summary.WriteByte(',')
+		}
+		fmt.Fprintf(&summary, "%s=%d", reason, discarded[reason])
+	}
+	return summary.String()
+}
+
+// discardReason returns the reason an order failing with err is discarded for, the class of the
+// simulation failure or the rejection reason.
+func discardReason(err error) string {
+	if errors.Is(err, errBundleTxReverted) {
+		return simFailureRevert
+	}
+	reason, _ := classifySimulationFailure(err)
+	return reason
+}
+
+// discarded returns the number of candidates left out of the block by the class of the error
+// they were last rejected with, or decisionlog.ReasonNotReached.
+func (r *decisionRecorder) discarded() map[string]int {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	discarded := make(map[string]int)
+	for _, candidate := range r.candidates {
+		switch candidate.Reason {
+		case decisionlog.ReasonIncluded:
+		case decisionlog.ReasonNotReached:
+			discarded[decisionlog.ReasonNotReached]++
+		default:
+			discarded[r.discards[candidate.Hash]]++
+		}
+	}
+	return discarded
+}
@@ -0,0 +1,43 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/builder/decisionlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBuildRoundSummary(t *testing.T) {
+	var nilRound *buildRound
+	nilRound.discard(simFailureRevert)
+	if nilRound.ID() != 0 || nilRound.summary(nil) != "" {
+		t.Fatal("nil round is not a no-op")
+	}
+
+	round, next := newBuildRound(), newBuildRound()
+	if next.ID() != round.ID()+1 {
+		t.Fatalf("round ids %d and %d are not sequential", round.ID(), next.ID())
+	}
+
+	bundle := func(hash byte) types.SimulatedBundle {
+		return types.SimulatedBundle{
+			OriginalBundle: types.MevBundle{Hash: common.Hash{hash}},
+			MevGasPrice:    big.NewInt(int64(hash)),
+		}
+	}
+	decisions := newDecisionRecorder(ALGO_GREEDY, []types.SimulatedBundle{bundle(1), bundle(2), bundle(3), bundle(4)}, nil)
+	decisions.committed(common.Hash{1}, big.NewInt(1))
+	decisions.rejected(common.Hash{2}, errBundleTxReverted)
+	decisions.rejected(common.Hash{3}, core.ErrNonceTooLow)
+
+	round.discard(simFailureRevert)
+	round.discard(bundleRejectedPolicy)
+
+	want := simFailureNonce + "=1," + decisionlog.ReasonNotReached + "=1," + bundleRejectedPolicy + "=1," + simFailureRevert + "=2"
+	if summary := round.summary(decisions); summary != want {
+		t.Errorf("summary %q, want %q", summary, want)
+	}
+}
@@ -1,37 +1,77 @@
 package miner
 
 import (
+	"math/big"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 const (
 	maxHeaders = 3
 )
 
+// BundleCache keeps the bundle simulation results of the recent blocks, so that the blocks built
+// again as new orderflow arrives do not simulate the unchanged bundles again. The bundles are
+// simulated at the top of the block, the results are keyed by the parent state root and the block
+// context. The entries of the parents the chain advanced past are dropped. The state touched by
+// the simulations, the multi-transaction snapshot diff, is kept with the results: the bundles
+// interfering with the bundles merged before them are simulated again instead of reusing them.
 type BundleCache struct {
 	mu      sync.Mutex
 	entries []*BundleCacheEntry
 }
 
+// bundleCacheKey identifies the state and the block context the bundles are simulated in.
+type bundleCacheKey struct {
+	parentRoot common.Hash
+	context    common.Hash // Hash of the header fields the simulations depend on
+}
+
+// newBundleCacheKey returns the key of the simulations of the block on top of the parent state.
+// The blocks of different parents sharing their state root, such as empty sibling blocks, share
+// the simulations.
+func newBundleCacheKey(parentRoot common.Hash, header *types.Header) bundleCacheKey {
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+	context, _ := rlp.EncodeToBytes([]interface{}{
+		header.Number, header.Time, header.Coinbase, header.GasLimit, baseFee, header.Difficulty, header.MixDigest,
+	})
+	return bundleCacheKey{parentRoot: parentRoot, context: crypto.Keccak256Hash(context)}
+}
+
 func NewBundleCache() *BundleCache {
 	return &BundleCache{
 		entries: make([]*BundleCacheEntry, maxHeaders),
 	}
 }
 
-func (b *BundleCache) GetBundleCache(header common.Hash) *BundleCacheEntry {
+// GetBundleCache returns the simulation results of the block on top of the parent state.
+func (b *BundleCache) GetBundleCache(parentRoot common.Hash, header *types.Header) *BundleCacheEntry {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	for _, entry := range b.entries {
-		if entry != nil && entry.headerHash == header {
+	key := newBundleCacheKey(parentRoot, header)
+	number := header.Number.Uint64()
+	for i, entry := range b.entries {
+		if entry == nil {
+			continue
+		}
+		if entry.key == key {
 			return entry
 		}
+		// the chain advanced past the parent of the entry, its results are stale
+		if entry.number < number {
+			b.entries[i] = nil
+		}
 	}
-	newEntry := newCacheEntry(header)
+	newEntry := newCacheEntry(key, number)
 	b.entries = b.entries[1:]
 	b.entries = append(b.entries, newEntry)
 
@@ -40,20 +80,24 @@ func (b *BundleCache) GetBundleCache(header common.Hash) *BundleCacheEntry {
 
 type BundleCacheEntry struct {
 	mu                 sync.Mutex
-	headerHash         common.Hash
+	key                bundleCacheKey
+	number             uint64 // Number of the block the bundles are simulated in
 	successfulBundles  map[common.Hash]*simulatedBundle
 	failedBundles      map[common.Hash]struct{}
 	successfulSBundles map[common.Hash]*types.SimSBundle
 	failedSBundles     map[common.Hash]struct{}
+	bundleAccesses     map[common.Hash]*state.StateDiff // State read and written by the simulated bundles, if recorded
 }
 
-func newCacheEntry(header common.Hash) *BundleCacheEntry {
+func newCacheEntry(key bundleCacheKey, number uint64) *BundleCacheEntry {
 	return &BundleCacheEntry{
-		headerHash:         header,
+		key:                key,
+		number:             number,
 		successfulBundles:  make(map[common.Hash]*simulatedBundle),
 		failedBundles:      make(map[common.Hash]struct{}),
 		successfulSBundles: make(map[common.Hash]*types.SimSBundle),
 		failedSBundles:     make(map[common.Hash]struct{}),
+		bundleAccesses:     make(map[common.Hash]*state.StateDiff),
 	}
 }
 
@@ -86,6 +130,26 @@ func (c *BundleCacheEntry) UpdateSimulatedBundles(result []*types.SimulatedBundl
 	}
 }
 
+// GetBundleAccess returns the state read and written by the simulation of the bundle, nil if not
+// recorded.
+func (c *BundleCacheEntry) GetBundleAccess(bundle common.Hash) *state.StateDiff {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.bundleAccesses[bundle]
+}
+
+func (c *BundleCacheEntry) UpdateBundleAccesses(accesses []*state.StateDiff, bundles []types.MevBundle) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, access := range accesses {
+		if access != nil {
+			c.bundleAccesses[bundles[i].Hash] = access
+		}
+	}
+}
+
 func (c *BundleCacheEntry) GetSimSBundle(bundle common.Hash) (*types.SimSBundle, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -1,6 +1,7 @@
 package miner
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -8,7 +9,7 @@ import (
 )
 
 func TestBundleCacheEntry(t *testing.T) {
-	entry := newCacheEntry(common.HexToHash("0x01"))
+	entry := newCacheEntry(bundleCacheKey{parentRoot: common.HexToHash("0x01")}, 1)
 
 	failingBundle := common.HexToHash("0xff")
 	successBundle := common.HexToHash("0xaa")
@@ -39,31 +40,42 @@ func TestBundleCacheEntry(t *testing.T) {
 func TestBundleCache(t *testing.T) {
 	cache := NewBundleCache()
 
-	header1 := common.HexToHash("0x01")
-	header2 := common.HexToHash("0x02")
-	header3 := common.HexToHash("0x03")
-	header4 := common.HexToHash("0x04")
-
-	cache1 := cache.GetBundleCache(header1)
-	if cache1.headerHash != header1 {
-		t.Error("incorrect header cache")
+	root1 := common.HexToHash("0x01")
+	root2 := common.HexToHash("0x02")
+	header := func(number, time int64) *types.Header {
+		return &types.Header{Number: big.NewInt(number), Time: uint64(time), BaseFee: big.NewInt(1)}
 	}
 
-	cache2 := cache.GetBundleCache(header2)
-	if cache2.headerHash != header2 {
-		t.Error("incorrect header cache")
+	cache1 := cache.GetBundleCache(root1, header(1, 10))
+	if cache1.key.parentRoot != root1 {
+		t.Error("incorrect parent cache")
 	}
 
-	cache2Again := cache.GetBundleCache(header2)
-	if cache2 != cache2Again {
-		t.Error("header cache is not reused")
+	// the blocks built on the same parent state in the same context share the simulations
+	cache2 := cache.GetBundleCache(root2, header(1, 10))
+	if cache2 == cache1 {
+		t.Error("cache shared by different parent states")
+	}
+	withExtra := header(1, 10)
+	withExtra.Extra = []byte{1}
+	withExtra.ParentHash = common.HexToHash("0xff")
+	if cache.GetBundleCache(root2, withExtra) != cache2 {
+		t.Error("cache is not reused")
+	}
+	if cache.GetBundleCache(root2, header(1, 11)) == cache2 {
+		t.Error("cache shared by different block contexts")
 	}
 
-	cache.GetBundleCache(header3)
-	cache.GetBundleCache(header4)
-
-	cache1Again := cache.GetBundleCache(header1)
-	if cache1 == cache1Again {
+	cache.GetBundleCache(root1, header(1, 12))
+	if cache.GetBundleCache(root1, header(1, 10)) == cache1 {
 		t.Error("cache1 should be removed after insertions")
 	}
+
+	// the entries of the older parents are dropped as the chain advances
+	cache.GetBundleCache(root1, header(2, 20))
+	for _, entry := range cache.entries {
+		if entry != nil && entry.number < 2 {
+			t.Errorf("stale entry of block %d kept", entry.number)
+		}
+	}
 }
@@ -0,0 +1,52 @@
+package miner
+
+import (
+	"github.com/ethereum/go-ethereum/core/state"
+)
+
+// bundlePartitions splits the bundles into the connected components of their conflict graph, the
+// bundles being linked if they write the same state or one reads state written by the other. The
+// outcome of a bundle does not depend on the bundles of other partitions merged before it. The
+// bundles without accesses conflict with all the others. It returns the partition of every bundle
+// and the number of bundles of every partition.
+func bundlePartitions(accesses []*state.StateDiff) ([]int, []int) {
+	parent := make([]int, len(accesses))
+	for i := range parent {
+		parent[i] = i
+	}
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for i, access := range accesses {
+		for j := 0; j < i; j++ {
+			if find(i) == find(j) {
+				continue
+			}
+			if access == nil || accesses[j] == nil || access.Interferes(accesses[j]) {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	var (
+		partitions = make([]int, len(accesses))
+		sizes      []int
+		ids        = make(map[int]int)
+	)
+	for i := range accesses {
+		root := find(i)
+		id, ok := ids[root]
+		if !ok {
+			id = len(sizes)
+			ids[root] = id
+			sizes = append(sizes, 0)
+		}
+		partitions[i] = id
+		sizes[id]++
+	}
+	return partitions, sizes
+}
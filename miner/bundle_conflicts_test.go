@@ -0,0 +1,48 @@
+package miner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+)
+
+func TestBundlePartitions(t *testing.T) {
+	var (
+		addr1 = common.HexToAddress("0x01")
+		addr2 = common.HexToAddress("0x02")
+		addr3 = common.HexToAddress("0x03")
+	)
+	access := func(written common.Address, read ...common.Address) *state.StateDiff {
+		diff := &state.StateDiff{
+			Accounts: map[common.Address]*state.TouchedAccount{written: {Balance: true}},
+			Reads:    map[common.Address][]common.Hash{},
+		}
+		for _, address := range read {
+			diff.Reads[address] = nil
+		}
+		return diff
+	}
+	tests := []struct {
+		name       string
+		accesses   []*state.StateDiff
+		partitions []int
+		sizes      []int
+	}{
+		{"disjoint", []*state.StateDiff{access(addr1), access(addr2), access(addr3)}, []int{0, 1, 2}, []int{1, 1, 1}},
+		{"same write", []*state.StateDiff{access(addr1), access(addr2), access(addr1)}, []int{0, 1, 0}, []int{2, 1}},
+		{"read written", []*state.StateDiff{access(addr1), access(addr2, addr1), access(addr3)}, []int{0, 0, 1}, []int{2, 1}},
+		{"transitive", []*state.StateDiff{access(addr1), access(addr2, addr1), access(addr3, addr2)}, []int{0, 0, 0}, []int{3}},
+		{"unknown access", []*state.StateDiff{access(addr1), nil, access(addr3)}, []int{0, 0, 0}, []int{3}},
+	}
+	for _, test := range tests {
+		partitions, sizes := bundlePartitions(test.accesses)
+		if !reflect.DeepEqual(partitions, test.partitions) {
+			t.Errorf("%s: expected partitions %v, got %v", test.name, test.partitions, partitions)
+		}
+		if !reflect.DeepEqual(sizes, test.sizes) {
+			t.Errorf("%s: expected sizes %v, got %v", test.name, test.sizes, sizes)
+		}
+	}
+}
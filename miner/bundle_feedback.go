@@ -0,0 +1,51 @@
+package miner
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	feedbackSimulatedMeter = metrics.NewRegisteredMeter("miner/bundle/feedback/simulated", nil)
+	feedbackFailedMeter    = metrics.NewRegisteredMeter("miner/bundle/feedback/failed", nil)
+	feedbackSimTimer       = metrics.NewRegisteredTimer("miner/bundle/feedback/duration", nil)
+)
+
+// simulateSubmitted simulates a bundle just submitted by a searcher, for the feedback sent back
+// to it. The bundle is simulated at the top of the latest block being built, as the pre-simulator
+// does, or else of a new block on the head, with the checks applied before merging it into a
+// block.
+func (w *worker) simulateSubmitted(bundle types.MevBundle) (*simulatedBundle, error) {
+	start := time.Now()
+	env := w.flashbots.preSim.targetEnv()
+	if env == nil {
+		var err error
+		env, err = w.prepareWork(&generateParams{
+			timestamp: uint64(time.Now().Unix()),
+			coinbase:  w.etherbase(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		env.background = true
+	}
+	defer env.discard()
+
+	gasPool := new(core.GasPool).AddGas(env.header.GasLimit)
+	simmed, err := w.computeBundleGas(env, bundle, env.state, gasPool, nil, 0)
+	if metrics.EnabledBuilder {
+		feedbackSimTimer.UpdateSince(start)
+		if err != nil {
+			feedbackFailedMeter.Mark(1)
+		} else {
+			feedbackSimulatedMeter.Mark(1)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &simmed, nil
+}
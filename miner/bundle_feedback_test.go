@@ -0,0 +1,48 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestSimulateSubmitted(t *testing.T) {
+	w, _ := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), defaultGenesisAlloc, 0)
+	defer w.close()
+	// the coinbase of the test worker is the sender of the bundle, its profit would be negative
+	w.setEtherbase(testAddress1)
+
+	baseFee := w.chain.CurrentBlock().BaseFee
+	signTx := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, testUserAddress, big.NewInt(1000), params.TxGas, new(big.Int).Mul(baseFee, big.NewInt(2)), nil), types.HomesteadSigner{}, testBankKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	// the pool nonce counts the pending transactions of the test backend, not the head state
+	head, err := w.chain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := head.GetNonce(testBankAddress)
+
+	// without a block being built, the bundle is simulated on top of the head
+	simulated, err := w.simulateSubmitted(types.MevBundle{Txs: types.Transactions{signTx(nonce)}, Hash: common.HexToHash("0x01")})
+	if err != nil {
+		t.Fatalf("failed to simulate bundle: %v", err)
+	}
+	if simulated.TotalGasUsed != params.TxGas || simulated.TotalEth.Sign() <= 0 {
+		t.Errorf("unexpected simulation, gas used %d and profit %v", simulated.TotalGasUsed, simulated.TotalEth)
+	}
+
+	// the simulation errors are returned for the feedback
+	if _, err := w.simulateSubmitted(types.MevBundle{Txs: types.Transactions{signTx(nonce + 1)}, Hash: common.HexToHash("0x02")}); err == nil {
+		t.Error("bundle with a nonce gap simulated")
+	}
+}
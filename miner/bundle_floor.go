@@ -0,0 +1,104 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+var ErrBundleBelowFloor = errors.New("bundle below the block floor")
+
+// BundleFloorConfig sets the minimum effective gas price and coinbase profit of the bundles
+// accepted in a block, so that dust bundles do not take block space. The first block of a Bor
+// sprint may use its own floors, the floors of the other blocks apply if they are not set.
+type BundleFloorConfig struct {
+	MinGasPrice       *big.Int // Minimum effective gas price in wei of a bundle, nil or 0 = disabled
+	MinProfit         *big.Int // Minimum coinbase profit in wei of a bundle, nil or 0 = disabled
+	SprintMinGasPrice *big.Int // Minimum effective gas price in the first block of a sprint, nil = MinGasPrice
+	SprintMinProfit   *big.Int // Minimum coinbase profit in the first block of a sprint, nil = MinProfit
+}
+
+// Validate checks the floors are not negative.
+func (c *BundleFloorConfig) Validate() error {
+	for _, floor := range []*big.Int{c.MinGasPrice, c.MinProfit, c.SprintMinGasPrice, c.SprintMinProfit} {
+		if floor != nil && floor.Sign() < 0 {
+			return fmt.Errorf("negative bundle floor %v", floor)
+		}
+	}
+	return nil
+}
+
+// copy returns a deep copy of the floors.
+func (c *BundleFloorConfig) copy() BundleFloorConfig {
+	copyBig := func(n *big.Int) *big.Int {
+		if n == nil {
+			return nil
+		}
+		return new(big.Int).Set(n)
+	}
+	return BundleFloorConfig{
+		MinGasPrice:       copyBig(c.MinGasPrice),
+		MinProfit:         copyBig(c.MinProfit),
+		SprintMinGasPrice: copyBig(c.SprintMinGasPrice),
+		SprintMinProfit:   copyBig(c.SprintMinProfit),
+	}
+}
+
+// floors returns the minimum effective gas price and profit of the bundles of the block, given
+// the sprint length.
+func (c *BundleFloorConfig) floors(number, sprint uint64) (*big.Int, *big.Int) {
+	minGasPrice, minProfit := c.MinGasPrice, c.MinProfit
+	if sprint != 0 && number%sprint == 0 {
+		if c.SprintMinGasPrice != nil {
+			minGasPrice = c.SprintMinGasPrice
+		}
+		if c.SprintMinProfit != nil {
+			minProfit = c.SprintMinProfit
+		}
+	}
+	return minGasPrice, minProfit
+}
+
+// check returns an error if the simulated bundle pays less than the floors of the block.
+func (c *BundleFloorConfig) check(number, sprint uint64, bundle *simulatedBundle) error {
+	minGasPrice, minProfit := c.floors(number, sprint)
+	if minGasPrice != nil && minGasPrice.Sign() > 0 && bundle.MevGasPrice.Cmp(minGasPrice) < 0 {
+		return fmt.Errorf("%w: effective gas price %v below %v", ErrBundleBelowFloor, bundle.MevGasPrice, minGasPrice)
+	}
+	if minProfit != nil && minProfit.Sign() > 0 && bundle.TotalEth.Cmp(minProfit) < 0 {
+		return fmt.Errorf("%w: profit %v below %v", ErrBundleBelowFloor, bundle.TotalEth, minProfit)
+	}
+	return nil
+}
+
+// bundleFloors holds the floors of the bundles, shared by all workers so they can be changed
+// while the builder runs. It is safe for concurrent use.
+type bundleFloors struct {
+	mu     sync.RWMutex
+	config BundleFloorConfig
+}
+
+func newBundleFloors(config BundleFloorConfig) *bundleFloors {
+	return &bundleFloors{config: config}
+}
+
+// setConfig replaces the floors of the bundles simulated from now on.
+func (f *bundleFloors) setConfig(config BundleFloorConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.config = config.copy()
+	return nil
+}
+
+// getConfig returns the current floors.
+func (f *bundleFloors) getConfig() BundleFloorConfig {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.config.copy()
+}
@@ -0,0 +1,46 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestBundleFloor(t *testing.T) {
+	bundle := &simulatedBundle{MevGasPrice: big.NewInt(10), TotalEth: big.NewInt(1000)}
+
+	var disabled BundleFloorConfig
+	if err := disabled.check(16, 16, bundle); err != nil {
+		t.Fatalf("bundle rejected without floors: %v", err)
+	}
+
+	config := BundleFloorConfig{
+		MinGasPrice:       big.NewInt(5),
+		MinProfit:         big.NewInt(500),
+		SprintMinGasPrice: big.NewInt(20),
+	}
+	tests := []struct {
+		number   uint64
+		sprint   uint64
+		gasPrice int64
+		profit   int64
+		rejected bool
+	}{
+		{number: 17, sprint: 16, gasPrice: 10, profit: 1000},
+		{number: 17, sprint: 16, gasPrice: 4, profit: 1000, rejected: true},
+		{number: 17, sprint: 16, gasPrice: 10, profit: 499, rejected: true},
+		// the first block of a sprint has its own gas price floor, and the profit floor of the other blocks
+		{number: 32, sprint: 16, gasPrice: 10, profit: 1000, rejected: true},
+		{number: 32, sprint: 16, gasPrice: 20, profit: 499, rejected: true},
+		{number: 32, sprint: 16, gasPrice: 20, profit: 500},
+		// without sprint, every block has the same floors
+		{number: 32, sprint: 0, gasPrice: 10, profit: 1000},
+	}
+	for i, test := range tests {
+		bundle := &simulatedBundle{MevGasPrice: big.NewInt(test.gasPrice), TotalEth: big.NewInt(test.profit)}
+		err := config.check(test.number, test.sprint, bundle)
+		if test.rejected != errors.Is(err, ErrBundleBelowFloor) {
+			t.Errorf("test %d: unexpected error %v", i, err)
+		}
+	}
+}
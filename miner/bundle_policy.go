@@ -0,0 +1,46 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var ErrBundlePolicyViolation = errors.New("bundle violates policy")
+
+// BundleTx is a transaction of a simulated bundle as seen by bundle policies.
+type BundleTx struct {
+	Tx        *types.Transaction
+	From      common.Address
+	InMempool bool // The transaction is pending in the mempool, i.e. it was not originated by the searcher
+	Receipt   *types.Receipt
+}
+
+// BundlePolicy decides whether a bundle may be included in blocks. Policies are evaluated
+// after the bundle was simulated successfully, bundles rejected by any policy are dropped.
+type BundlePolicy interface {
+	// Name identifies the policy in logs and errors
+	Name() string
+	// Check returns an error if the bundle must not be included
+	Check(bundle *types.MevBundle, txs []BundleTx) error
+}
+
+// newBundlePolicies returns the policies enabled by the miner config.
+func newBundlePolicies(config *Config) []BundlePolicy {
+	policies := append([]BundlePolicy{}, config.BundlePolicies...)
+	if config.ProtectiveOrdering {
+		policies = append(policies, NewAntiSandwichPolicy())
+	}
+	return policies
+}
+
+func checkBundlePolicies(policies []BundlePolicy, bundle *types.MevBundle, txs []BundleTx) error {
+	for _, policy := range policies {
+		if err := policy.Check(bundle, txs); err != nil {
+			return fmt.Errorf("%w %s: %v", ErrBundlePolicyViolation, policy.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package miner
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// Swap(address indexed sender, uint amount0In, uint amount1In, uint amount0Out, uint amount1Out, address indexed to)
+	uniswapV2SwapTopic = common.HexToHash("0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822")
+	// Swap(address indexed sender, address indexed recipient, int256 amount0, int256 amount1, uint160 sqrtPriceX96, uint128 liquidity, int24 tick)
+	uniswapV3SwapTopic = common.HexToHash("0xc42079f94a6350d7e6235f29174924f928cc2ac818eb64fed8004e115fbcca67")
+)
+
+// poolSwap is a swap executed by a transaction on a constant function market maker pool.
+type poolSwap struct {
+	pool        common.Address
+	token0ToOne bool // Direction of the swap, token0 is sold to the pool for token1
+}
+
+// antiSandwichPolicy rejects bundles that sandwich a mempool transaction: the searcher trades on a
+// pool right before the mempool transaction in the same direction, and right after in the opposite direction.
+type antiSandwichPolicy struct{}
+
+// NewAntiSandwichPolicy returns the policy rejecting bundles which sandwich a mempool transaction
+// on a Uniswap V2 or V3 compatible pool.
+func NewAntiSandwichPolicy() BundlePolicy {
+	return antiSandwichPolicy{}
+}
+
+func (antiSandwichPolicy) Name() string {
+	return "anti-sandwich"
+}
+
+func (antiSandwichPolicy) Check(bundle *types.MevBundle, txs []BundleTx) error {
+	swaps := make([][]poolSwap, len(txs))
+	for i, tx := range txs {
+		swaps[i] = poolSwaps(tx.Receipt)
+	}
+
+	for victim, victimTx := range txs {
+		if !victimTx.InMempool {
+			continue
+		}
+		for front := 0; front < victim; front++ {
+			if txs[front].InMempool || txs[front].From == victimTx.From {
+				continue
+			}
+			for back := victim + 1; back < len(txs); back++ {
+				if txs[back].From != txs[front].From {
+					continue
+				}
+				if swap, ok := sandwichedSwap(swaps[victim], swaps[front], swaps[back]); ok {
+					return fmt.Errorf("tx %s sandwiched on pool %s by %s and %s",
+						victimTx.Tx.Hash(), swap.pool, txs[front].Tx.Hash(), txs[back].Tx.Hash())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sandwichedSwap returns the swap of the victim sandwiched by the front swaps, trading on its pool
+// in the same direction before it, and the back swaps, trading in the opposite direction after it.
+func sandwichedSwap(victim, front, back []poolSwap) (poolSwap, bool) {
+	for _, swap := range victim {
+		if hasSwap(front, swap.pool, swap.token0ToOne) && hasSwap(back, swap.pool, !swap.token0ToOne) {
+			return swap, true
+		}
+	}
+	return poolSwap{}, false
+}
+
+func hasSwap(swaps []poolSwap, pool common.Address, token0ToOne bool) bool {
+	for _, swap := range swaps {
+		if swap.pool == pool && swap.token0ToOne == token0ToOne {
+			return true
+		}
+	}
+	return false
+}
+
+// poolSwaps decodes the Uniswap V2 and V3 swaps of a receipt.
+func poolSwaps(receipt *types.Receipt) []poolSwap {
+	if receipt == nil {
+		return nil
+	}
+
+	var swaps []poolSwap
+	for _, l := range receipt.Logs {
+		if len(l.Topics) == 0 {
+			continue
+		}
+		switch l.Topics[0] {
+		case uniswapV2SwapTopic:
+			// amount0In, amount1In, amount0Out, amount1Out
+			if len(l.Data) < 4*32 {
+				continue
+			}
+			amount0In := new(big.Int).SetBytes(l.Data[:32])
+			swaps = append(swaps, poolSwap{pool: l.Address, token0ToOne: amount0In.Sign() > 0})
+		case uniswapV3SwapTopic:
+			// amount0 is positive when token0 is paid to the pool
+			if len(l.Data) < 2*32 {
+				continue
+			}
+			amount0Negative := l.Data[0]&0x80 != 0
+			amount0Zero := new(big.Int).SetBytes(l.Data[:32]).Sign() == 0
+			swaps = append(swaps, poolSwap{pool: l.Address, token0ToOne: !amount0Negative && !amount0Zero})
+		}
+	}
+	return swaps
+}
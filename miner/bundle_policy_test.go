@@ -0,0 +1,123 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func v2SwapLog(pool common.Address, token0ToOne bool) *types.Log {
+	data := make([]byte, 4*32)
+	if token0ToOne {
+		big.NewInt(100).FillBytes(data[0:32])   // amount0In
+		big.NewInt(100).FillBytes(data[96:128]) // amount1Out
+	} else {
+		big.NewInt(100).FillBytes(data[32:64]) // amount1In
+		big.NewInt(100).FillBytes(data[64:96]) // amount0Out
+	}
+	return &types.Log{Address: pool, Topics: []common.Hash{uniswapV2SwapTopic}, Data: data}
+}
+
+func v3SwapLog(pool common.Address, token0ToOne bool) *types.Log {
+	data := make([]byte, 5*32)
+	amount0, amount1 := big.NewInt(100), big.NewInt(-100)
+	if !token0ToOne {
+		amount0, amount1 = amount1, amount0
+	}
+	copy(data[0:32], math.U256Bytes(amount0))
+	copy(data[32:64], math.U256Bytes(amount1))
+	return &types.Log{Address: pool, Topics: []common.Hash{uniswapV3SwapTopic}, Data: data}
+}
+
+func policyTx(nonce uint64, from common.Address, inMempool bool, logs ...*types.Log) BundleTx {
+	return BundleTx{
+		Tx:        types.NewTx(&types.LegacyTx{Nonce: nonce}),
+		From:      from,
+		InMempool: inMempool,
+		Receipt:   &types.Receipt{Logs: logs},
+	}
+}
+
+func TestAntiSandwichPolicy(t *testing.T) {
+	var (
+		searcher = common.HexToAddress("0x01")
+		victim   = common.HexToAddress("0x02")
+		pool     = common.HexToAddress("0x10")
+		other    = common.HexToAddress("0x11")
+	)
+
+	tests := []struct {
+		name     string
+		txs      []BundleTx
+		sandwich bool
+	}{
+		{
+			name: "v2 sandwich",
+			txs: []BundleTx{
+				policyTx(0, searcher, false, v2SwapLog(pool, true)),
+				policyTx(1, victim, true, v2SwapLog(pool, true)),
+				policyTx(2, searcher, false, v2SwapLog(pool, false)),
+			},
+			sandwich: true,
+		},
+		{
+			name: "v3 sandwich",
+			txs: []BundleTx{
+				policyTx(0, searcher, false, v3SwapLog(pool, false)),
+				policyTx(1, victim, true, v3SwapLog(pool, false)),
+				policyTx(2, searcher, false, v3SwapLog(pool, true)),
+			},
+			sandwich: true,
+		},
+		{
+			name: "backrun only",
+			txs: []BundleTx{
+				policyTx(1, victim, true, v2SwapLog(pool, true)),
+				policyTx(2, searcher, false, v2SwapLog(pool, false)),
+			},
+		},
+		{
+			name: "different pools",
+			txs: []BundleTx{
+				policyTx(0, searcher, false, v2SwapLog(other, true)),
+				policyTx(1, victim, true, v2SwapLog(pool, true)),
+				policyTx(2, searcher, false, v2SwapLog(pool, false)),
+			},
+		},
+		{
+			name: "frontrun in opposite direction",
+			txs: []BundleTx{
+				policyTx(0, searcher, false, v2SwapLog(pool, false)),
+				policyTx(1, victim, true, v2SwapLog(pool, true)),
+				policyTx(2, searcher, false, v2SwapLog(pool, true)),
+			},
+		},
+		{
+			name: "victim not from mempool",
+			txs: []BundleTx{
+				policyTx(0, searcher, false, v2SwapLog(pool, true)),
+				policyTx(1, victim, false, v2SwapLog(pool, true)),
+				policyTx(2, searcher, false, v2SwapLog(pool, false)),
+			},
+		},
+	}
+
+	policies := newBundlePolicies(&Config{ProtectiveOrdering: true})
+	for _, test := range tests {
+		err := checkBundlePolicies(policies, &types.MevBundle{}, test.txs)
+		if test.sandwich && !errors.Is(err, ErrBundlePolicyViolation) {
+			t.Errorf("%s: expected policy violation, got %v", test.name, err)
+		}
+		if !test.sandwich && err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+		}
+	}
+
+	if policies := newBundlePolicies(&Config{}); len(policies) != 0 {
+		t.Errorf("expected no policies when protective ordering is disabled, got %d", len(policies))
+	}
+}
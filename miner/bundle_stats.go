@@ -0,0 +1,227 @@
+package miner
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// bundleStatsSimulations is the number of recent simulations kept per bundle
+	bundleStatsSimulations = 16
+
+	// bundleStatsRounds is the number of recent block building rounds kept per bundle
+	bundleStatsRounds = 32
+)
+
+// BundleSimulation is the outcome of a simulation of a bundle.
+type BundleSimulation struct {
+	BlockNumber uint64       `json:"blockNumber"`
+	ParentHash  common.Hash  `json:"parentHash"`
+	Time        time.Time    `json:"time"`
+	Success     bool         `json:"success"`
+	Error       string       `json:"error,omitempty"`
+	GasUsed     uint64       `json:"gasUsed,omitempty"`
+	MevGasPrice *hexutil.Big `json:"mevGasPrice,omitempty"`
+	Profit      *hexutil.Big `json:"profit,omitempty"` // Coinbase profit of the bundle, fees included
+}
+
+// BundleRound is a block building round which considered a bundle.
+type BundleRound struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"` // Hash of the candidate block built in the round
+	Time        time.Time   `json:"time"`
+	Included    bool        `json:"included"` // Whether the bundle was included in the candidate block
+}
+
+// BundleStats is the status of a submitted bundle: its simulations, the block building rounds
+// which considered it and its inclusion in a canonical block built by the node.
+type BundleStats struct {
+	BundleHash     common.Hash        `json:"bundleHash"`
+	Searcher       common.Address     `json:"searcher"`
+	ReceivedAt     time.Time          `json:"receivedAt"`
+	Simulations    []BundleSimulation `json:"simulations"`  // Most recent last
+	ConsideredIn   []BundleRound      `json:"consideredIn"` // Most recent last
+	Rounds         uint64             `json:"rounds"`       // Total rounds which considered the bundle, including the ones no longer listed
+	Included       bool               `json:"included"`     // Whether a block built by the node landed with the bundle
+	IncludedNumber uint64             `json:"includedNumber,omitempty"`
+	IncludedHash   common.Hash        `json:"includedHash,omitempty"`
+}
+
+// bundleStatsTracker follows the submitted bundles through the simulations and block building
+// rounds until they land, for the feedback of their searchers. Bundles of unknown origin are
+// ignored and the bundles are forgotten once they are too old to be included, like for the
+// source analytics. The methods are no-ops on a nil instance.
+type bundleStatsTracker struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	bundles map[common.Hash]*BundleStats
+	pending map[common.Hash]*pendingSourcedBundles // Bundles of the built blocks waiting for the blocks to land
+}
+
+func newBundleStatsTracker() *bundleStatsTracker {
+	return &bundleStatsTracker{
+		now:     time.Now,
+		bundles: make(map[common.Hash]*BundleStats),
+		pending: make(map[common.Hash]*pendingSourcedBundles),
+	}
+}
+
+// submitted starts tracking a bundle accepted from the searcher, resubmissions keep the time the
+// bundle was first received.
+func (t *bundleStatsTracker) submitted(hash common.Hash, searcher common.Address) {
+	if t == nil || hash == (common.Hash{}) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.bundles[hash]; !ok {
+		t.bundles[hash] = &BundleStats{BundleHash: hash, Searcher: searcher, ReceivedAt: t.now()}
+	}
+}
+
+// simulated records a simulation of the bundle at the top of the block of the header, failed if
+// err is not nil.
+func (t *bundleStatsTracker) simulated(hash common.Hash, header *types.Header, gasUsed uint64, mevGasPrice, profit *big.Int, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, ok := t.bundles[hash]
+	if !ok {
+		return
+	}
+	simulation := BundleSimulation{
+		BlockNumber: header.Number.Uint64(),
+		ParentHash:  header.ParentHash,
+		Time:        t.now(),
+		Success:     err == nil,
+	}
+	if err != nil {
+		simulation.Error = err.Error()
+	} else {
+		simulation.GasUsed = gasUsed
+		simulation.MevGasPrice = hexBig(mevGasPrice)
+		simulation.Profit = hexBig(profit)
+	}
+	stats.Simulations = append(stats.Simulations, simulation)
+	if n := len(stats.Simulations) - bundleStatsSimulations; n > 0 {
+		stats.Simulations = stats.Simulations[n:]
+	}
+}
+
+// blockBuilt records the round which built the block, considering the bundles and sbundles.
+func (t *bundleStatsTracker) blockBuilt(block *types.Block, blockBundles, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle) {
+	if t == nil || len(allBundles)+len(usedSbundles) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	included := make(map[common.Hash]struct{}, len(blockBundles)+len(usedSbundles))
+	for _, bundle := range blockBundles {
+		included[bundle.OriginalBundle.Hash] = struct{}{}
+		for _, hash := range bundle.OriginalBundle.Chain {
+			included[hash] = struct{}{}
+		}
+	}
+	for _, sbundle := range usedSbundles {
+		if sbundle.Success {
+			included[sbundle.Bundle.Hash()] = struct{}{}
+		}
+	}
+	var (
+		now     = t.now()
+		landing []sourcedBundle
+	)
+	consider := func(hash common.Hash) {
+		stats, ok := t.bundles[hash]
+		if !ok {
+			return
+		}
+		_, in := included[hash]
+		stats.Rounds++
+		stats.ConsideredIn = append(stats.ConsideredIn, BundleRound{
+			BlockNumber: block.NumberU64(),
+			BlockHash:   block.Hash(),
+			Time:        now,
+			Included:    in,
+		})
+		if n := len(stats.ConsideredIn) - bundleStatsRounds; n > 0 {
+			stats.ConsideredIn = stats.ConsideredIn[n:]
+		}
+		if in {
+			landing = append(landing, sourcedBundle{hash: hash})
+		}
+	}
+	for _, bundle := range allBundles {
+		consider(bundle.OriginalBundle.Hash)
+		// the bundles chained by nonce are considered with their chain
+		for _, hash := range bundle.OriginalBundle.Chain {
+			consider(hash)
+		}
+	}
+	for _, sbundle := range usedSbundles {
+		consider(sbundle.Bundle.Hash())
+	}
+	if len(landing) > 0 && len(t.pending) < profitPendingLimit {
+		t.pending[block.Hash()] = &pendingSourcedBundles{number: block.NumberU64(), bundles: landing}
+	}
+}
+
+// chainHead marks the bundles of the head block as included if the node built it, and forgets
+// the data that is too old.
+func (t *bundleStatsTracker) chainHead(head *types.Block) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if landed, ok := t.pending[head.Hash()]; ok {
+		delete(t.pending, head.Hash())
+		for _, bundle := range landed.bundles {
+			if stats, ok := t.bundles[bundle.hash]; ok {
+				stats.Included = true
+				stats.IncludedNumber = head.NumberU64()
+				stats.IncludedHash = head.Hash()
+			}
+		}
+	}
+	for hash, pending := range t.pending {
+		if pending.number+profitPendingDepth < head.NumberU64() {
+			delete(t.pending, hash)
+		}
+	}
+	now := t.now()
+	for hash, stats := range t.bundles {
+		if now.Sub(stats.ReceivedAt) > sourceBundleRetention {
+			delete(t.bundles, hash)
+		}
+	}
+}
+
+// stats returns a copy of the status of the bundle, nil if it is not tracked.
+func (t *bundleStatsTracker) stats(hash common.Hash) *BundleStats {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, ok := t.bundles[hash]
+	if !ok {
+		return nil
+	}
+	cpy := *stats
+	cpy.Simulations = append([]BundleSimulation{}, stats.Simulations...)
+	cpy.ConsideredIn = append([]BundleRound{}, stats.ConsideredIn...)
+	return &cpy
+}
@@ -0,0 +1,73 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBundleStats(t *testing.T) {
+	var (
+		now      = time.Unix(1_700_000_000, 0)
+		searcher = common.Address{0xaa}
+		landed   = common.Hash{1}
+		dropped  = common.Hash{2}
+	)
+	tracker := newBundleStatsTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.submitted(landed, searcher)
+	tracker.submitted(dropped, searcher)
+	tracker.submitted(common.Hash{}, searcher) // bundles without hash are not tracked
+
+	header := &types.Header{Number: big.NewInt(10), ParentHash: common.Hash{9}}
+	tracker.simulated(landed, header, 21000, big.NewInt(10), big.NewInt(210000), nil)
+	tracker.simulated(dropped, header, 0, nil, nil, errors.New("reverted"))
+	tracker.simulated(common.Hash{3}, header, 21000, big.NewInt(10), big.NewInt(210000), nil) // unknown origin
+
+	all := []types.SimulatedBundle{{OriginalBundle: types.MevBundle{Hash: landed}}, {OriginalBundle: types.MevBundle{Hash: dropped}}}
+	block := types.NewBlockWithHeader(header)
+	tracker.blockBuilt(block, all[:1], all, nil)
+	tracker.chainHead(block)
+
+	stats := tracker.stats(landed)
+	if stats == nil || stats.Searcher != searcher || !stats.ReceivedAt.Equal(now) {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if len(stats.Simulations) != 1 || !stats.Simulations[0].Success || stats.Simulations[0].Profit.ToInt().Int64() != 210000 {
+		t.Errorf("unexpected simulations %+v", stats.Simulations)
+	}
+	if stats.Rounds != 1 || len(stats.ConsideredIn) != 1 || !stats.ConsideredIn[0].Included || stats.ConsideredIn[0].BlockHash != block.Hash() {
+		t.Errorf("unexpected rounds %+v", stats.ConsideredIn)
+	}
+	if !stats.Included || stats.IncludedNumber != 10 || stats.IncludedHash != block.Hash() {
+		t.Errorf("bundle not included in the head")
+	}
+
+	stats = tracker.stats(dropped)
+	if len(stats.Simulations) != 1 || stats.Simulations[0].Error != "reverted" {
+		t.Errorf("unexpected simulations %+v", stats.Simulations)
+	}
+	if stats.Rounds != 1 || stats.ConsideredIn[0].Included || stats.Included {
+		t.Errorf("dropped bundle included")
+	}
+
+	// the rounds kept per bundle are capped
+	for i := 0; i < bundleStatsRounds; i++ {
+		tracker.blockBuilt(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(11), Extra: []byte{byte(i)}}), nil, all, nil)
+	}
+	if stats := tracker.stats(dropped); stats.Rounds != bundleStatsRounds+1 || len(stats.ConsideredIn) != bundleStatsRounds {
+		t.Errorf("unexpected rounds %d, %d listed", stats.Rounds, len(stats.ConsideredIn))
+	}
+
+	// bundles past the retention are forgotten
+	now = now.Add(sourceBundleRetention + time.Minute)
+	tracker.chainHead(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(12)}))
+	if tracker.stats(landed) != nil || tracker.stats(dropped) != nil {
+		t.Error("bundles kept past the retention")
+	}
+}
@@ -267,7 +267,7 @@ func TestSimulatorState(t *testing.T) {
 
 			targetBlockNumber := new(big.Int).Set(b.chain.CurrentHeader().Number)
 			targetBlockNumber.Add(targetBlockNumber, big.NewInt(1))
-			b.txPool.AddMevBundle(types.Transactions{userSwapTx, backrunTx}, targetBlockNumber, uuid.UUID{}, common.Address{}, 0, 0, nil)
+			b.txPool.AddMevBundle(types.Transactions{userSwapTx, backrunTx}, targetBlockNumber, nil, uuid.UUID{}, common.Address{}, 0, 0, nil)
 			buildBlock([]*types.Transaction{}, 3)
 		})
 	}
@@ -0,0 +1,216 @@
+package miner
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/builder/buildtrace"
+	"github.com/ethereum/go-ethereum/builder/decisionlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// decisionRecorder collects the outcome of every bundle and sbundle the block building algorithm
+// commits or rejects, for the summary of the build round, the decision log and the build trace.
+// The methods are no-ops on a nil recorder.
+type decisionRecorder struct {
+	mu         sync.Mutex
+	algorithm  AlgoType
+	candidates []decisionlog.Candidate
+	index      map[common.Hash]int
+	discards   map[common.Hash]string // Discard reason of the last rejection of the candidates
+	trace      *buildtrace.Round      // Round of the build trace, nil if the round is not traced
+}
+
+// newDecisionRecorder ranks the simulated bundles and sbundles by mev gas price, the order in
+// which the algorithms consider them.
+func newDecisionRecorder(algorithm AlgoType, bundles []types.SimulatedBundle, sbundles []*types.SimSBundle) *decisionRecorder {
+	r := &decisionRecorder{
+		algorithm:  algorithm,
+		candidates: make([]decisionlog.Candidate, 0, len(bundles)+len(sbundles)),
+		index:      make(map[common.Hash]int, len(bundles)+len(sbundles)),
+		discards:   make(map[common.Hash]string),
+	}
+	for _, bundle := range bundles {
+		r.candidates = append(r.candidates, decisionlog.Candidate{
+			Hash:            bundle.OriginalBundle.Hash,
+			MevGasPrice:     hexBig(bundle.MevGasPrice),
+			SimulatedProfit: hexBig(bundle.EthSentToCoinbase),
+			Reason:          decisionlog.ReasonNotReached,
+		})
+	}
+	for _, sbundle := range sbundles {
+		r.candidates = append(r.candidates, decisionlog.Candidate{
+			Hash:            sbundle.Bundle.Hash(),
+			SBundle:         true,
+			MevGasPrice:     hexBig(sbundle.MevGasPrice),
+			SimulatedProfit: hexBig(sbundle.Profit),
+			Reason:          decisionlog.ReasonNotReached,
+		})
+	}
+	price := func(c decisionlog.Candidate) *big.Int {
+		if c.MevGasPrice == nil {
+			return common.Big0
+		}
+		return c.MevGasPrice.ToInt()
+	}
+	sort.SliceStable(r.candidates, func(i, j int) bool {
+		return price(r.candidates[i]).Cmp(price(r.candidates[j])) > 0
+	})
+	for i := range r.candidates {
+		r.candidates[i].Rank = i
+		r.index[r.candidates[i].Hash] = i
+	}
+	return r
+}
+
+// committed records a candidate committed to the block, along with the resulting block profit.
+func (r *decisionRecorder) committed(hash common.Hash, blockProfit *big.Int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i, ok := r.index[hash]; ok {
+		r.candidates[i].Reason = decisionlog.ReasonIncluded
+		r.candidates[i].BlockProfit = hexBig(blockProfit)
+		r.traceStep(i, decisionlog.ReasonIncluded, blockProfit)
+	}
+}
+
+// rejected records the error a candidate failed to commit with. Candidates retried by the
+// algorithm keep the outcome of their last attempt.
+func (r *decisionRecorder) rejected(hash common.Hash, err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i, ok := r.index[hash]; ok {
+		r.candidates[i].Reason = err.Error()
+		r.candidates[i].BlockProfit = nil
+		r.discards[hash] = discardReason(err)
+		r.traceStep(i, err.Error(), nil)
+	}
+}
+
+// traceStep appends the commit attempt of the candidate to the traced round, the lock must be held.
+func (r *decisionRecorder) traceStep(i int, reason string, blockProfit *big.Int) {
+	if r.trace == nil {
+		return
+	}
+	r.trace.Steps = append(r.trace.Steps, buildtrace.Step{
+		Hash:        r.candidates[i].Hash,
+		SBundle:     r.candidates[i].SBundle,
+		Reason:      reason,
+		BlockProfit: hexBig(blockProfit),
+	})
+}
+
+// traceInputs starts the trace of the round building the block of the header, with the bundles,
+// sbundles and mempool transactions given to the algorithm. The transactions are encoded right
+// away, the algorithms consume the mempool they are given.
+func (r *decisionRecorder) traceInputs(header *types.Header, bundles []types.SimulatedBundle, sbundles []*types.SimSBundle, pending map[common.Address]types.Transactions) {
+	if r == nil {
+		return
+	}
+	round := &buildtrace.Round{
+		Algorithm:  r.algorithm.String(),
+		ParentHash: header.ParentHash,
+		Header:     types.CopyHeader(header),
+		Bundles:    make([]buildtrace.Bundle, 0, len(bundles)),
+		SBundles:   make([]common.Hash, 0, len(sbundles)),
+		Mempool:    make([]hexutil.Bytes, 0, len(pending)),
+		Steps:      make([]buildtrace.Step, 0, len(bundles)+len(sbundles)),
+	}
+	for _, bundle := range bundles {
+		traced := buildtrace.Bundle{
+			Hash:              bundle.OriginalBundle.Hash,
+			Txs:               make([]hexutil.Bytes, 0, len(bundle.OriginalBundle.Txs)),
+			RevertingTxHashes: bundle.OriginalBundle.RevertingTxHashes,
+			SigningAddress:    bundle.OriginalBundle.SigningAddress,
+			MevGasPrice:       hexBig(bundle.MevGasPrice),
+			Profit:            hexBig(bundle.TotalEth),
+			Priority:          hexBig(bundle.Priority),
+		}
+		for _, tx := range bundle.OriginalBundle.Txs {
+			if encoded, err := tx.MarshalBinary(); err == nil {
+				traced.Txs = append(traced.Txs, encoded)
+			}
+		}
+		round.Bundles = append(round.Bundles, traced)
+	}
+	for _, sbundle := range sbundles {
+		round.SBundles = append(round.SBundles, sbundle.Bundle.Hash())
+	}
+	for _, txs := range pending {
+		for _, tx := range txs {
+			if encoded, err := tx.MarshalBinary(); err == nil {
+				round.Mempool = append(round.Mempool, encoded)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.trace = round
+}
+
+// write records the decisions taken for the sealed block and its bid in the decision log.
+func (r *decisionRecorder) write(block *types.Block, bid *big.Int, blockBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !decisionlog.Enabled() && r.trace == nil {
+		return
+	}
+
+	included := make(map[common.Hash]struct{}, len(blockBundles)+len(usedSbundles))
+	for _, bundle := range blockBundles {
+		included[bundle.OriginalBundle.Hash] = struct{}{}
+	}
+	for _, sbundle := range usedSbundles {
+		if sbundle.Success {
+			included[sbundle.Bundle.Hash()] = struct{}{}
+		}
+	}
+
+	record := &decisionlog.BlockDecision{
+		Time:        time.Now().UTC(),
+		Algorithm:   r.algorithm.String(),
+		BlockNumber: block.NumberU64(),
+		BlockHash:   block.Hash(),
+		ParentHash:  block.ParentHash(),
+		Candidates:  make([]decisionlog.Candidate, len(r.candidates)),
+		Bid:         hexBig(bid),
+	}
+	for i, candidate := range r.candidates {
+		_, candidate.Included = included[candidate.Hash]
+		record.Candidates[i] = candidate
+	}
+	decisionlog.Write(record)
+
+	if r.trace != nil {
+		r.trace.Time = record.Time
+		r.trace.BlockHash = record.BlockHash
+		r.trace.Candidates = record.Candidates
+		r.trace.Profit = record.Bid
+		buildtrace.Write(r.trace)
+	}
+}
+
+func hexBig(v *big.Int) *hexutil.Big {
+	if v == nil {
+		return nil
+	}
+	return (*hexutil.Big)(new(big.Int).Set(v))
+}
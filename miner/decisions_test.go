@@ -0,0 +1,85 @@
+package miner
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/builder/decisionlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecisionRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "decisions.log")
+	decisionLog, err := decisionlog.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open decision log: %v", err)
+	}
+	decisionlog.SetDefault(decisionLog)
+	defer decisionlog.SetDefault(nil)
+
+	var (
+		low     = types.SimulatedBundle{OriginalBundle: types.MevBundle{Hash: common.Hash{1}}, MevGasPrice: big.NewInt(1), EthSentToCoinbase: big.NewInt(10)}
+		high    = types.SimulatedBundle{OriginalBundle: types.MevBundle{Hash: common.Hash{2}}, MevGasPrice: big.NewInt(3), EthSentToCoinbase: big.NewInt(30)}
+		skipped = types.SimulatedBundle{OriginalBundle: types.MevBundle{Hash: common.Hash{3}}, MevGasPrice: big.NewInt(0)}
+		sbundle = &types.SimSBundle{Bundle: &types.SBundle{}, MevGasPrice: big.NewInt(2), Profit: big.NewInt(20)}
+	)
+
+	recorder := newDecisionRecorder(ALGO_GREEDY, []types.SimulatedBundle{low, high, skipped}, []*types.SimSBundle{sbundle})
+	recorder.committed(high.OriginalBundle.Hash, big.NewInt(30))
+	recorder.rejected(sbundle.Bundle.Hash(), errors.New("bundle reverted"))
+	// the last attempt of a retried bundle is recorded
+	recorder.rejected(low.OriginalBundle.Hash, errors.New("low profit"))
+	recorder.committed(low.OriginalBundle.Hash, big.NewInt(40))
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5)})
+	recorder.write(block, big.NewInt(35), []types.SimulatedBundle{high, low}, []types.UsedSBundle{{Bundle: sbundle.Bundle}})
+	if err := decisionLog.Close(); err != nil {
+		t.Fatalf("failed to close decision log: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read decision log: %v", err)
+	}
+	var record decisionlog.BlockDecision
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("failed to decode decision record: %v", err)
+	}
+	if record.BlockNumber != 5 || record.BlockHash != block.Hash() || record.Bid.ToInt().Int64() != 35 || record.Algorithm != "greedy" {
+		t.Fatalf("unexpected block decision %+v", record)
+	}
+
+	expected := []struct {
+		hash     common.Hash
+		included bool
+		reason   string
+		profit   int64
+	}{
+		{high.OriginalBundle.Hash, true, decisionlog.ReasonIncluded, 30},
+		{sbundle.Bundle.Hash(), false, "bundle reverted", 0},
+		{low.OriginalBundle.Hash, true, decisionlog.ReasonIncluded, 40},
+		{skipped.OriginalBundle.Hash, false, decisionlog.ReasonNotReached, 0},
+	}
+	if len(record.Candidates) != len(expected) {
+		t.Fatalf("unexpected number of candidates %d", len(record.Candidates))
+	}
+	for i, exp := range expected {
+		candidate := record.Candidates[i]
+		if candidate.Rank != i || candidate.Hash != exp.hash || candidate.Included != exp.included || candidate.Reason != exp.reason {
+			t.Errorf("unexpected candidate %d: %+v", i, candidate)
+		}
+		if exp.profit != 0 && (candidate.BlockProfit == nil || candidate.BlockProfit.ToInt().Int64() != exp.profit) {
+			t.Errorf("unexpected block profit of candidate %d: %v", i, candidate.BlockProfit)
+		}
+	}
+
+	// a disabled recorder is a no-op
+	var disabled *decisionRecorder
+	disabled.committed(high.OriginalBundle.Hash, big.NewInt(1))
+	disabled.write(block, big.NewInt(1), nil, nil)
+}
@@ -1,7 +1,6 @@
 package miner
 
 import (
-	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"math/big"
@@ -10,16 +9,31 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
 // envChanges is a helper struct to apply and discard changes to the environment
 type envChanges struct {
-	env      *environment
-	gasPool  *core.GasPool
-	usedGas  uint64
-	profit   *big.Int
-	txs      []*types.Transaction
-	receipts []*types.Receipt
+	env       *environment
+	gasPool   *core.GasPool
+	usedGas   uint64
+	profit    *big.Int
+	txs       []*types.Transaction
+	receipts  []*types.Receipt
+	txProfits []*big.Int
+
+	stateGrowth uint64
+
+	// gasCheckpoints hold the gas budget of the changes when each multi-transaction snapshot
+	// pushed with newSnapshot was created
+	gasCheckpoints []gasCheckpoint
+	// baseDepth is the depth of the snapshot of the changes in the stack of the state
+	baseDepth int
+}
+
+type gasCheckpoint struct {
+	gasPool uint64
+	usedGas uint64
 }
 
 func newEnvChanges(env *environment) (*envChanges, error) {
@@ -34,12 +48,15 @@ func newEnvChanges(env *environment) (*envChanges, error) {
 		profit:   new(big.Int).Set(env.profit),
 		txs:      make([]*types.Transaction, 0),
 		receipts: make([]*types.Receipt, 0),
+
+		stateGrowth: env.stateGrowth,
+		baseDepth:   env.state.MultiTxSnapshotStackSize() - 1,
 	}, nil
 }
 
 func (c *envChanges) commitPayoutTx(
 	amount *big.Int, sender, receiver common.Address,
-	gas uint64, prv *ecdsa.PrivateKey, chData chainData) (*types.Receipt, error) {
+	gas uint64, txSigner TxSigner, chData chainData) (*types.Receipt, error) {
 	return commitPayoutTx(PayoutTransactionParams{
 		Amount:        amount,
 		BaseFee:       c.env.header.BaseFee,
@@ -51,7 +68,7 @@ func (c *envChanges) commitPayoutTx(
 		SenderBalance: c.env.state.GetBalance(sender),
 		SenderNonce:   c.env.state.GetNonce(sender),
 		Signer:        c.env.signer,
-		PrivateKey:    prv,
+		TxSigner:      txSigner,
 	})
 }
 
@@ -68,6 +85,7 @@ func (c *envChanges) commitTx(tx *types.Transaction, chData chainData) (*types.R
 	}
 
 	c.env.state.SetTxContext(tx.Hash(), c.env.tcount+len(c.txs))
+	coinbaseBefore := new(big.Int).Set(c.env.state.GetBalance(c.env.coinbase))
 	receipt, _, err := applyTransactionWithBlacklist(signer, chData.chainConfig, chData.chain, &c.env.coinbase, c.gasPool, c.env.state, c.env.header, tx, &c.usedGas, *chData.chain.GetVMConfig(), chData.blacklist)
 	if err != nil {
 		switch {
@@ -99,14 +117,26 @@ func (c *envChanges) commitTx(tx *types.Transaction, chData chainData) (*types.R
 		}
 	}
 
-	c.profit = c.profit.Add(c.profit, new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), gasPrice))
+	txProfit := new(big.Int).Sub(c.env.state.GetBalance(c.env.coinbase), coinbaseBefore)
+	c.profit = c.profit.Add(c.profit, txCoinbaseProfit(from, c.env.coinbase, receipt.GasUsed, gasPrice, txProfit))
 	c.txs = append(c.txs, tx)
 	c.receipts = append(c.receipts, receipt)
+	c.txProfits = append(c.txProfits, txProfit)
 
 	return receipt, shiftTx, nil
 }
 
 func (c *envChanges) commitBundle(bundle *types.SimulatedBundle, chData chainData, algoConf algorithmConfig) error {
+	if err := checkTxPreferences(algoConf.TxPreferences, bundle.OriginalBundle.Txs); err != nil {
+		return err
+	}
+	if err := checkStateGrowthBudget(algoConf, c.stateGrowth, bundle.StateGrowth); err != nil {
+		if metrics.EnabledBuilder {
+			stateGrowthSkippedMeter.Mark(1)
+		}
+		return err
+	}
+
 	var (
 		profitBefore   = new(big.Int).Set(c.profit)
 		coinbaseBefore = new(big.Int).Set(c.env.state.GetBalance(c.env.coinbase))
@@ -115,6 +145,7 @@ func (c *envChanges) commitBundle(bundle *types.SimulatedBundle, chData chainDat
 		txsBefore      = c.txs[:]
 		receiptsBefore = c.receipts[:]
 		hasBaseFee     = c.env.header.BaseFee != nil
+		tail           = blockTail(c.env.signer, c.env.txs, c.env.receipts, c.txs, c.receipts)
 
 		bundleErr error
 	)
@@ -156,7 +187,7 @@ func (c *envChanges) commitBundle(bundle *types.SimulatedBundle, chData chainDat
 			if receipt.Status == types.ReceiptStatusFailed && !bundle.OriginalBundle.RevertingHash(txHash) {
 				// if transaction reverted and isn't specified as reverting hash, return error
 				log.Trace("Bundle tx failed", "bundle", bundle.OriginalBundle.Hash, "tx", txHash, "err", err)
-				bundleErr = errors.New("bundle tx revert")
+				bundleErr = errBundleTxReverted
 			}
 		case receipt == nil && err == nil:
 			// NOTE: The expectation is that a receipt is only nil if an error occurred.
@@ -169,6 +200,9 @@ func (c *envChanges) commitBundle(bundle *types.SimulatedBundle, chData chainDat
 		}
 	}
 
+	if bundleErr == nil {
+		bundleErr = checkBlockTxPreferences(algoConf.TxPreferences, tail, committedTxs(c.env.signer, c.txs[len(txsBefore):], c.receipts[len(receiptsBefore):]))
+	}
 	if bundleErr != nil {
 		c.rollback(gasUsedBefore, gasPoolBefore, profitBefore, txsBefore, receiptsBefore)
 		return bundleErr
@@ -209,10 +243,11 @@ func (c *envChanges) commitBundle(bundle *types.SimulatedBundle, chData chainDat
 	}
 
 	c.profit.Add(profitBefore, bundleProfit)
+	c.stateGrowth += bundle.StateGrowth
 	return nil
 }
 
-func (c *envChanges) CommitSBundle(sbundle *types.SimSBundle, chData chainData, key *ecdsa.PrivateKey, algoConf algorithmConfig) error {
+func (c *envChanges) CommitSBundle(sbundle *types.SimSBundle, chData chainData, key TxSigner, algoConf algorithmConfig) error {
 	// TODO: Suggestion for future improvement: instead of checking if key is nil, panic.
 	//   Discussed with @Ruteri, see PR#90 for details: https://github.com/flashbots/builder/pull/90#discussion_r1285567550
 	if key == nil {
@@ -226,12 +261,17 @@ func (c *envChanges) CommitSBundle(sbundle *types.SimSBundle, chData chainData,
 		txsBefore      = c.txs[:]
 		receiptsBefore = c.receipts[:]
 		profitBefore   = new(big.Int).Set(c.profit)
+		tail           = blockTail(c.env.signer, c.env.txs, c.env.receipts, c.txs, c.receipts)
 	)
 
 	if err := c.commitSBundle(sbundle.Bundle, chData, key, algoConf); err != nil {
 		c.rollback(gasBefore, gasPoolBefore, profitBefore, txsBefore, receiptsBefore)
 		return err
 	}
+	if err := checkBlockTxPreferences(algoConf.TxPreferences, tail, committedTxs(c.env.signer, c.txs[len(txsBefore):], c.receipts[len(receiptsBefore):])); err != nil {
+		c.rollback(gasBefore, gasPoolBefore, profitBefore, txsBefore, receiptsBefore)
+		return err
+	}
 
 	var (
 		coinbaseAfter = c.env.state.GetBalance(c.env.header.Coinbase)
@@ -280,10 +320,15 @@ func (c *envChanges) CommitSBundle(sbundle *types.SimSBundle, chData chainData,
 		}
 	}
 
+	// the refunds paid by the coinbase are deducted from the profit of the sbundle
+	c.profit.Add(profitBefore, coinbaseDelta)
 	return nil
 }
 
-func (c *envChanges) commitSBundle(sbundle *types.SBundle, chData chainData, key *ecdsa.PrivateKey, algoConf algorithmConfig) error {
+func (c *envChanges) commitSBundle(sbundle *types.SBundle, chData chainData, key TxSigner, algoConf algorithmConfig) error {
+	if err := checkTxPreferences(algoConf.TxPreferences, sbundleTxs(sbundle)); err != nil {
+		return err
+	}
 	var (
 		// check inclusion
 		minBlock = sbundle.Inclusion.BlockNumber
@@ -361,28 +406,19 @@ func (c *envChanges) commitSBundle(sbundle *types.SBundle, chData chainData, key
 			return err
 		}
 
-		maxPayoutCost := new(big.Int).Set(core.SbundlePayoutMaxCost)
-		maxPayoutCost.Mul(maxPayoutCost, big.NewInt(int64(len(refundConfig))))
-		maxPayoutCost.Mul(maxPayoutCost, c.env.header.BaseFee)
-
-		allocatedValue := common.PercentOf(refundableProfit, el)
-		allocatedValue.Sub(allocatedValue, maxPayoutCost)
-
-		if allocatedValue.Cmp(common.Big0) < 0 {
-			return fmt.Errorf("negative payout")
+		refunds, err := sbundleRefunds(refundableProfit, el, refundConfig, c.env.header.BaseFee)
+		if err != nil {
+			return err
 		}
-
-		for _, refund := range refundConfig {
-			refundValue := common.PercentOf(allocatedValue, refund.Percent)
-			refundReceiver := refund.Address
-			rec, err := c.commitPayoutTx(refundValue, c.env.header.Coinbase, refundReceiver, core.SbundlePayoutMaxCostInt, key, chData)
+		for _, refund := range refunds {
+			rec, err := c.commitPayoutTx(refund.value, c.env.header.Coinbase, refund.receiver, core.SbundlePayoutMaxCostInt, key, chData)
 			if err != nil {
 				return err
 			}
 			if rec.Status != types.ReceiptStatusSuccessful {
 				return fmt.Errorf("refund tx failed")
 			}
-			log.Trace("Committed kickback", "payout", ethIntToFloat(allocatedValue), "receiver", refundReceiver)
+			log.Trace("Committed kickback", "payout", ethIntToFloat(refund.value), "receiver", refund.receiver)
 		}
 	}
 	coinbaseDelta.Set(c.env.state.GetBalance(c.env.header.Coinbase))
@@ -395,6 +431,57 @@ func (c *envChanges) commitSBundle(sbundle *types.SBundle, chData chainData, key
 	return nil
 }
 
+// newSnapshot pushes a multi-transaction snapshot of the state and records the gas budget of the
+// changes, to revert a single order.
+func (c *envChanges) newSnapshot() error {
+	if err := c.env.state.NewMultiTxSnapshot(); err != nil {
+		return err
+	}
+	c.gasCheckpoints = append(c.gasCheckpoints, gasCheckpoint{gasPool: c.gasPool.Gas(), usedGas: c.usedGas})
+	return nil
+}
+
+// revertSnapshot reverts the state to the last snapshot pushed with newSnapshot and gives the gas
+// consumed since back to the gas pool of the changes, including the gas of failed transactions.
+func (c *envChanges) revertSnapshot() error {
+	if err := c.env.state.MultiTxSnapshotRevert(); err != nil {
+		return err
+	}
+	checkpoint := c.popGasCheckpoint()
+	c.gasPool.SetGas(checkpoint.gasPool)
+	c.usedGas = checkpoint.usedGas
+	return nil
+}
+
+// commitSnapshot merges the last snapshot pushed with newSnapshot into the one below it.
+func (c *envChanges) commitSnapshot() error {
+	if err := c.env.state.MultiTxSnapshotCommit(); err != nil {
+		return err
+	}
+	c.popGasCheckpoint()
+	return nil
+}
+
+// squashSnapshots merges the snapshots pushed with newSnapshot and left on the stack as checkpoints
+// into the snapshot of the changes, in one pass rather than one commit per snapshot.
+func (c *envChanges) squashSnapshots() error {
+	if len(c.gasCheckpoints) == 0 {
+		return nil
+	}
+	if err := c.env.state.MultiTxSnapshotSquashToDepth(c.baseDepth); err != nil {
+		return err
+	}
+	c.gasCheckpoints = c.gasCheckpoints[:0]
+	return nil
+}
+
+func (c *envChanges) popGasCheckpoint() gasCheckpoint {
+	last := len(c.gasCheckpoints) - 1
+	checkpoint := c.gasCheckpoints[last]
+	c.gasCheckpoints = c.gasCheckpoints[:last]
+	return checkpoint
+}
+
 // discard reverts all changes to the environment - every commit operation must be followed by a discard or apply operation
 func (c *envChanges) discard() error {
 	return c.env.state.MultiTxSnapshotRevert()
@@ -409,6 +496,7 @@ func (c *envChanges) rollback(
 	c.gasPool = gasPoolBefore
 	c.txs = txsBefore
 	c.receipts = receiptsBefore
+	c.txProfits = c.txProfits[:len(txsBefore)]
 	c.profit.Set(profitBefore)
 }
 
@@ -420,8 +508,10 @@ func (c *envChanges) apply() error {
 	c.env.gasPool.SetGas(c.gasPool.Gas())
 	c.env.header.GasUsed = c.usedGas
 	c.env.profit.Set(c.profit)
+	c.env.stateGrowth = c.stateGrowth
 	c.env.tcount += len(c.txs)
 	c.env.txs = append(c.env.txs, c.txs...)
 	c.env.receipts = append(c.env.receipts, c.receipts...)
+	c.env.txProfits = append(c.env.txProfits, c.txProfits...)
 	return nil
 }
@@ -1,6 +1,7 @@
 package miner
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 
@@ -166,6 +167,96 @@ func TestCommitTxOverGasLimitSnaps(t *testing.T) {
 	require.Error(t, err, "committed tx over gas limit")
 }
 
+func TestRevertSnapshotGasSnaps(t *testing.T) {
+	statedb, chData, signers := genTestSetup(GasLimit)
+
+	env := newEnvironment(chData, statedb, signers.addresses[0], GasLimit, big.NewInt(1))
+	changes, err := newEnvChanges(env)
+	if err != nil {
+		t.Fatal("can't create env changes", err)
+	}
+
+	tx := signers.signTx(1, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(0), []byte{})
+	if err := changes.newSnapshot(); err != nil {
+		t.Fatal("can't create snapshot", err)
+	}
+	if _, _, err := changes.commitTx(tx, chData); err != nil {
+		t.Fatal("can't commit transaction:", err)
+	}
+	if err := changes.revertSnapshot(); err != nil {
+		t.Fatal("can't revert snapshot", err)
+	}
+	if changes.gasPool.Gas() != GasLimit {
+		t.Fatal("envDiff gas pool not restored")
+	}
+	if changes.usedGas != 0 {
+		t.Fatal("envDiff gas used not restored")
+	}
+	if env.state.GetNonce(signers.addresses[1]) != 0 {
+		t.Fatal("state not reverted")
+	}
+
+	if err := changes.newSnapshot(); err != nil {
+		t.Fatal("can't create snapshot", err)
+	}
+	if _, _, err := changes.commitTx(tx, chData); err != nil {
+		t.Fatal("can't commit transaction:", err)
+	}
+	if err := changes.commitSnapshot(); err != nil {
+		t.Fatal("can't commit snapshot", err)
+	}
+	if changes.usedGas != 21000 || changes.gasPool.Gas() != GasLimit-21000 {
+		t.Fatal("envDiff gas not kept on commit")
+	}
+	if len(changes.gasCheckpoints) != 0 {
+		t.Fatal("gas checkpoints left")
+	}
+}
+
+func TestSquashSnapshotsSnaps(t *testing.T) {
+	statedb, chData, signers := genTestSetup(GasLimit)
+
+	env := newEnvironment(chData, statedb, signers.addresses[0], GasLimit, big.NewInt(1))
+	changes, err := newEnvChanges(env)
+	if err != nil {
+		t.Fatal("can't create env changes", err)
+	}
+
+	// the first two transactions are kept as checkpoints, the third one is reverted
+	for i := 0; i < 3; i++ {
+		tx := signers.signTx(1, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(0), []byte{})
+		if err := changes.newSnapshot(); err != nil {
+			t.Fatal("can't create snapshot", err)
+		}
+		if _, _, err := changes.commitTx(tx, chData); err != nil {
+			t.Fatal("can't commit transaction:", err)
+		}
+	}
+	if err := changes.revertSnapshot(); err != nil {
+		t.Fatal("can't revert snapshot", err)
+	}
+	if err := changes.squashSnapshots(); err != nil {
+		t.Fatal("can't squash snapshots", err)
+	}
+	if size := env.state.MultiTxSnapshotStackSize(); size != changes.baseDepth+1 {
+		t.Fatalf("unexpected stack size %d after squash", size)
+	}
+	if len(changes.gasCheckpoints) != 0 {
+		t.Fatal("gas checkpoints left")
+	}
+	if changes.usedGas != 2*21000 || env.state.GetNonce(signers.addresses[1]) != 2 {
+		t.Fatal("checkpoints not kept on squash")
+	}
+
+	// the squashed snapshot reverts the changes of all the checkpoints
+	if err := changes.discard(); err != nil {
+		t.Fatal("can't discard changes", err)
+	}
+	if env.state.GetNonce(signers.addresses[1]) != 0 {
+		t.Fatal("state not reverted")
+	}
+}
+
 func TestErrorBundleCommitSnaps(t *testing.T) {
 	statedb, chData, signers := genTestSetup(GasLimit)
 
@@ -233,6 +324,50 @@ func TestErrorBundleCommitSnaps(t *testing.T) {
 	}
 }
 
+func TestPartialRevertBundleCommitSnaps(t *testing.T) {
+	statedb, chData, signers := genRevertTestSetup()
+
+	algoConf := defaultAlgorithmConfig
+	env := newEnvironment(chData, statedb, signers.addresses[0], GasLimit, big.NewInt(1))
+	merged, rejected := genPartialRevertBundles(t, env, chData, signers)
+
+	changes, err := newEnvChanges(env)
+	if err != nil {
+		t.Fatal("can't create env changes", err)
+	}
+	if err := changes.commitBundle(&merged[0], chData, algoConf); err != nil {
+		t.Fatal("Failed to commit bundle", err)
+	}
+
+	gasUsedBefore := changes.usedGas
+	balanceBefore := changes.env.state.GetBalance(signers.addresses[2])
+	if err := changes.newSnapshot(); err != nil {
+		t.Fatal("can't create snapshot", err)
+	}
+	if err := changes.commitBundle(&rejected, chData, algoConf); !errors.Is(err, errBundleTxReverted) {
+		t.Fatal("Committed bundle with a tx reverting without being allowed to", err)
+	}
+	if err := changes.revertSnapshot(); err != nil {
+		t.Fatal("can't revert snapshot", err)
+	}
+	if changes.usedGas != gasUsedBefore {
+		t.Fatal("gasUsed changed")
+	}
+	if changes.env.state.GetBalance(signers.addresses[2]).Cmp(balanceBefore) != 0 || changes.env.state.GetNonce(signers.addresses[4]) != 0 {
+		t.Fatal("state of the rejected bundle not reverted")
+	}
+
+	if err := changes.commitBundle(&merged[1], chData, algoConf); err != nil {
+		t.Fatal("Failed to commit bundle with an allowed revert", err)
+	}
+	if len(changes.receipts) != 3 {
+		t.Fatal("Incorrect receipts txs")
+	}
+	if err := checkBundleReverts(merged, changes.receipts); err != nil {
+		t.Fatal("Allowed revert rejected", err)
+	}
+}
+
 func TestErrorSBundleCommitSnaps(t *testing.T) {
 	statedb, chData, signers := genTestSetup(GasLimit)
 
@@ -278,7 +413,7 @@ func TestErrorSBundleCommitSnaps(t *testing.T) {
 	newProfitBefore := new(big.Int).Set(changes.profit)
 	balanceBefore := changes.env.state.GetBalance(signers.addresses[2])
 
-	err = changes.CommitSBundle(&sbundle, chData, builderPrivKey, defaultAlgorithmConfig)
+	err = changes.CommitSBundle(&sbundle, chData, NewLocalTxSigner(builderPrivKey), defaultAlgorithmConfig)
 	if err == nil {
 		t.Fatal("Committed failed bundle", err)
 	}
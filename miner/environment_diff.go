@@ -1,7 +1,6 @@
 package miner
 
 import (
-	"crypto/ecdsa"
 	"errors"
 	"fmt"
 	"math/big"
@@ -11,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 )
 
 // environmentDiff is a helper struct used to apply transactions to a block using a copy of the state at that block
@@ -20,8 +20,10 @@ type environmentDiff struct {
 	gasPool         *core.GasPool  // available gas used to pack transactions
 	state           *state.StateDB // apply state changes here
 	newProfit       *big.Int
+	newStateGrowth  uint64
 	newTxs          []*types.Transaction
 	newReceipts     []*types.Receipt
+	newTxProfits    []*big.Int
 }
 
 func newEnvironmentDiff(env *environment) *environmentDiff {
@@ -44,8 +46,10 @@ func (envDiff *environmentDiff) copy() *environmentDiff {
 		gasPool:         gasPool,
 		state:           envDiff.state.Copy(),
 		newProfit:       new(big.Int).Set(envDiff.newProfit),
+		newStateGrowth:  envDiff.newStateGrowth,
 		newTxs:          envDiff.newTxs[:],
 		newReceipts:     envDiff.newReceipts[:],
+		newTxProfits:    envDiff.newTxProfits[:],
 	}
 }
 
@@ -56,9 +60,27 @@ func (envDiff *environmentDiff) applyToBaseEnv() {
 	env.state.StopPrefetcher()
 	env.state = envDiff.state
 	env.profit.Add(env.profit, envDiff.newProfit)
+	env.stateGrowth += envDiff.newStateGrowth
 	env.tcount += len(envDiff.newTxs)
 	env.txs = append(env.txs, envDiff.newTxs...)
 	env.receipts = append(env.receipts, envDiff.newReceipts...)
+	env.txProfits = append(env.txProfits, envDiff.newTxProfits...)
+}
+
+// blockProfit returns the profit of the base environment including the changes of the diff
+func (envDiff *environmentDiff) blockProfit() *big.Int {
+	return new(big.Int).Add(envDiff.baseEnvironment.profit, envDiff.newProfit)
+}
+
+// blockTail returns the last two transactions of the base environment including the changes of the diff
+func (envDiff *environmentDiff) blockTail() []BundleTx {
+	env := envDiff.baseEnvironment
+	return blockTail(env.signer, env.txs, env.receipts, envDiff.newTxs, envDiff.newReceipts)
+}
+
+// committedSince returns the transactions committed to tmpEnvDiff, a copy of the diff, since the copy
+func (envDiff *environmentDiff) committedSince(tmpEnvDiff *environmentDiff) []BundleTx {
+	return committedTxs(envDiff.baseEnvironment.signer, tmpEnvDiff.newTxs[len(envDiff.newTxs):], tmpEnvDiff.newReceipts[len(envDiff.newReceipts):])
 }
 
 // commit tx to envDiff
@@ -73,6 +95,7 @@ func (envDiff *environmentDiff) commitTx(tx *types.Transaction, chData chainData
 	}
 
 	envDiff.state.SetTxContext(tx.Hash(), envDiff.baseEnvironment.tcount+len(envDiff.newTxs))
+	coinbaseBefore := new(big.Int).Set(envDiff.state.GetBalance(*coinbase))
 
 	receipt, newState, err := applyTransactionWithBlacklist(signer, chData.chainConfig, chData.chain, coinbase,
 		envDiff.gasPool, envDiff.state, header, tx, &header.GasUsed, *chData.chain.GetVMConfig(), chData.blacklist)
@@ -112,16 +135,30 @@ func (envDiff *environmentDiff) commitTx(tx *types.Transaction, chData chainData
 		}
 	}
 
-	envDiff.newProfit = envDiff.newProfit.Add(envDiff.newProfit, gasPrice.Mul(gasPrice, big.NewInt(int64(receipt.GasUsed))))
+	from, _ := types.Sender(signer, tx)
+	txProfit := new(big.Int).Sub(envDiff.state.GetBalance(*coinbase), coinbaseBefore)
+	envDiff.newProfit = envDiff.newProfit.Add(envDiff.newProfit, txCoinbaseProfit(from, *coinbase, receipt.GasUsed, gasPrice, txProfit))
 	envDiff.newTxs = append(envDiff.newTxs, tx)
 	envDiff.newReceipts = append(envDiff.newReceipts, receipt)
+	envDiff.newTxProfits = append(envDiff.newTxProfits, txProfit)
 
 	return receipt, shiftTx, nil
 }
 
 // Commit Bundle to env diff
 func (envDiff *environmentDiff) commitBundle(bundle *types.SimulatedBundle, chData chainData, interrupt *int32, algoConf algorithmConfig) error {
+	if err := checkTxPreferences(algoConf.TxPreferences, bundle.OriginalBundle.Txs); err != nil {
+		return err
+	}
+	if err := checkStateGrowthBudget(algoConf, envDiff.baseEnvironment.stateGrowth+envDiff.newStateGrowth, bundle.StateGrowth); err != nil {
+		if metrics.EnabledBuilder {
+			stateGrowthSkippedMeter.Mark(1)
+		}
+		return err
+	}
+
 	coinbase := envDiff.baseEnvironment.coinbase
+	tail := envDiff.blockTail()
 	tmpEnvDiff := envDiff.copy()
 
 	coinbaseBalanceBefore := tmpEnvDiff.state.GetBalance(coinbase)
@@ -181,7 +218,7 @@ func (envDiff *environmentDiff) commitBundle(bundle *types.SimulatedBundle, chDa
 			if receipt.Status == types.ReceiptStatusFailed && !bundle.OriginalBundle.RevertingHash(txHash) {
 				// if transaction reverted and isn't specified as reverting hash, return error
 				log.Trace("Bundle tx failed", "bundle", bundle.OriginalBundle.Hash, "tx", txHash, "err", err)
-				return errors.New("bundle tx revert")
+				return errBundleTxReverted
 			}
 		} else {
 			// NOTE: The expectation is that a receipt is only nil if an error occurred.
@@ -191,6 +228,9 @@ func (envDiff *environmentDiff) commitBundle(bundle *types.SimulatedBundle, chDa
 
 		gasUsed += receipt.GasUsed
 	}
+	if err := checkBlockTxPreferences(algoConf.TxPreferences, tail, envDiff.committedSince(tmpEnvDiff)); err != nil {
+		return err
+	}
 	coinbaseBalanceAfter := tmpEnvDiff.state.GetBalance(coinbase)
 	coinbaseBalanceDelta := new(big.Int).Sub(coinbaseBalanceAfter, coinbaseBalanceBefore)
 	tmpEnvDiff.newProfit.Add(profitBefore, coinbaseBalanceDelta)
@@ -224,11 +264,12 @@ func (envDiff *environmentDiff) commitBundle(bundle *types.SimulatedBundle, chDa
 		return err
 	}
 
+	tmpEnvDiff.newStateGrowth += bundle.StateGrowth
 	*envDiff = *tmpEnvDiff
 	return nil
 }
 
-func (envDiff *environmentDiff) commitPayoutTx(amount *big.Int, sender, receiver common.Address, gas uint64, prv *ecdsa.PrivateKey, chData chainData) (*types.Receipt, error) {
+func (envDiff *environmentDiff) commitPayoutTx(amount *big.Int, sender, receiver common.Address, gas uint64, txSigner TxSigner, chData chainData) (*types.Receipt, error) {
 	return commitPayoutTx(PayoutTransactionParams{
 		Amount:        amount,
 		BaseFee:       envDiff.header.BaseFee,
@@ -240,17 +281,21 @@ func (envDiff *environmentDiff) commitPayoutTx(amount *big.Int, sender, receiver
 		SenderBalance: envDiff.state.GetBalance(sender),
 		SenderNonce:   envDiff.state.GetNonce(sender),
 		Signer:        envDiff.baseEnvironment.signer,
-		PrivateKey:    prv,
+		TxSigner:      txSigner,
 	})
 }
 
-func (envDiff *environmentDiff) commitSBundle(b *types.SimSBundle, chData chainData, interrupt *int32, key *ecdsa.PrivateKey, algoConf algorithmConfig) error {
+func (envDiff *environmentDiff) commitSBundle(b *types.SimSBundle, chData chainData, interrupt *int32, key TxSigner, algoConf algorithmConfig) error {
 	// TODO: Suggestion for future improvement: instead of checking if key is nil, panic.
 	//   Discussed with @Ruteri, see PR#90 for details: https://github.com/flashbots/builder/pull/90#discussion_r1285567550
 	if key == nil {
 		return errNoPrivateKey
 	}
+	if err := checkTxPreferences(algoConf.TxPreferences, sbundleTxs(b.Bundle)); err != nil {
+		return err
+	}
 
+	tail := envDiff.blockTail()
 	tmpEnvDiff := envDiff.copy()
 
 	coinbaseBefore := tmpEnvDiff.state.GetBalance(tmpEnvDiff.header.Coinbase)
@@ -259,6 +304,9 @@ func (envDiff *environmentDiff) commitSBundle(b *types.SimSBundle, chData chainD
 	if err := tmpEnvDiff.commitSBundleInner(b.Bundle, chData, interrupt, key, algoConf); err != nil {
 		return err
 	}
+	if err := checkBlockTxPreferences(algoConf.TxPreferences, tail, envDiff.committedSince(tmpEnvDiff)); err != nil {
+		return err
+	}
 
 	coinbaseAfter := tmpEnvDiff.state.GetBalance(tmpEnvDiff.header.Coinbase)
 	gasAfter := tmpEnvDiff.gasPool.Gas()
@@ -303,11 +351,13 @@ func (envDiff *environmentDiff) commitSBundle(b *types.SimSBundle, chData chainD
 		}
 	}
 
+	// the refunds paid by the coinbase are deducted from the profit of the sbundle
+	tmpEnvDiff.newProfit.Add(envDiff.newProfit, coinbaseDelta)
 	*envDiff = *tmpEnvDiff
 	return nil
 }
 
-func (envDiff *environmentDiff) commitSBundleInner(b *types.SBundle, chData chainData, interrupt *int32, key *ecdsa.PrivateKey, algoConf algorithmConfig) error {
+func (envDiff *environmentDiff) commitSBundleInner(b *types.SBundle, chData chainData, interrupt *int32, key TxSigner, algoConf algorithmConfig) error {
 	// check inclusion
 	minBlock := b.Inclusion.BlockNumber
 	maxBlock := b.Inclusion.MaxBlockNumber
@@ -382,28 +432,19 @@ func (envDiff *environmentDiff) commitSBundleInner(b *types.SBundle, chData chai
 			return err
 		}
 
-		maxPayoutCost := new(big.Int).Set(core.SbundlePayoutMaxCost)
-		maxPayoutCost.Mul(maxPayoutCost, big.NewInt(int64(len(refundConfig))))
-		maxPayoutCost.Mul(maxPayoutCost, envDiff.header.BaseFee)
-
-		allocatedValue := common.PercentOf(refundableProfit, el)
-		allocatedValue.Sub(allocatedValue, maxPayoutCost)
-
-		if allocatedValue.Cmp(common.Big0) < 0 {
-			return fmt.Errorf("negative payout")
+		refunds, err := sbundleRefunds(refundableProfit, el, refundConfig, envDiff.header.BaseFee)
+		if err != nil {
+			return err
 		}
-
-		for _, refund := range refundConfig {
-			refundValue := common.PercentOf(allocatedValue, refund.Percent)
-			refundReceiver := refund.Address
-			rec, err := envDiff.commitPayoutTx(refundValue, envDiff.header.Coinbase, refundReceiver, core.SbundlePayoutMaxCostInt, key, chData)
+		for _, refund := range refunds {
+			rec, err := envDiff.commitPayoutTx(refund.value, envDiff.header.Coinbase, refund.receiver, core.SbundlePayoutMaxCostInt, key, chData)
 			if err != nil {
 				return err
 			}
 			if rec.Status != types.ReceiptStatusSuccessful {
 				return fmt.Errorf("refund tx failed")
 			}
-			log.Trace("Committed kickback", "payout", ethIntToFloat(allocatedValue), "receiver", refundReceiver)
+			log.Trace("Committed kickback", "payout", ethIntToFloat(refund.value), "receiver", refund.receiver)
 		}
 	}
 	coinbaseDelta.Set(envDiff.state.GetBalance(envDiff.header.Coinbase))
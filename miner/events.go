@@ -0,0 +1,62 @@
+package miner
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/builder/eventexport"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// exportBundleSimulated publishes the outcome of a bundle or sbundle simulation
+func exportBundleSimulated(header *types.Header, hash common.Hash, searcher common.Address, sbundle bool, mevGasPrice, profit *big.Int, err error) {
+	event := eventexport.BundleSimulated{
+		BundleHash:  hash,
+		SBundle:     sbundle,
+		Searcher:    searcher,
+		BlockNumber: header.Number.Uint64(),
+		ParentHash:  header.ParentHash,
+		Success:     err == nil,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	} else {
+		event.MevGasPrice = hexBig(mevGasPrice)
+		event.Profit = hexBig(profit)
+	}
+	eventexport.Publish(eventexport.EventBundleSimulated, &event)
+}
+
+// exportBlockBuilt publishes a finalized block with the bundles and sbundles included in it
+func exportBlockBuilt(block *types.Block, profit *big.Int, blockBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle) {
+	event := eventexport.BlockBuilt{
+		BlockNumber:  block.NumberU64(),
+		BlockHash:    block.Hash(),
+		ParentHash:   block.ParentHash(),
+		GasUsed:      block.GasUsed(),
+		Transactions: len(block.Transactions()),
+		Bundles:      make([]common.Hash, 0, len(blockBundles)+len(usedSbundles)),
+		Profit:       (*hexutil.Big)(profit),
+	}
+	for _, bundle := range blockBundles {
+		event.Bundles = append(event.Bundles, bundle.OriginalBundle.Hash)
+		event.Bundles = append(event.Bundles, bundle.OriginalBundle.Chain...)
+	}
+	for _, sbundle := range usedSbundles {
+		if sbundle.Success {
+			event.Bundles = append(event.Bundles, sbundle.Bundle.Hash())
+		}
+	}
+	eventexport.Publish(eventexport.EventBlockBuilt, &event)
+}
+
+// exportBlockLanded publishes a block built by the node that became the chain head
+func exportBlockLanded(profit *BlockProfit) {
+	eventexport.Publish(eventexport.EventBlockLanded, &eventexport.BlockLanded{
+		BlockNumber: uint64(profit.BlockNumber),
+		BlockHash:   profit.BlockHash,
+		Payout:      profit.Payout,
+		Margin:      profit.Margin,
+	})
+}
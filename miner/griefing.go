@@ -0,0 +1,179 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// griefingSignersCacheSize is the number of submitted bundles whose authenticated signer is remembered
+const griefingSignersCacheSize = 1 << 16
+
+// defaultGriefingConfig quarantines searchers for an hour after 5 offending bundles in 10 minutes
+var defaultGriefingConfig = GriefingConfig{
+	GasThreshold: 1_000_000,
+	MaxStrikes:   5,
+	Window:       10 * time.Minute,
+	Quarantine:   time.Hour,
+}
+
+// GriefingConfig configures the detection of searchers griefing the builder, either by submitting bundles
+// that burn a lot of simulation gas before reverting, or bundles whose simulated profit vanishes on commit.
+type GriefingConfig struct {
+	GasThreshold uint64        // Gas burned by a reverting bundle simulation that counts as a strike, 0 disables detection
+	MaxStrikes   int           // Strikes within the window after which the searcher is quarantined
+	Window       time.Duration // Period over which strikes are counted
+	Quarantine   time.Duration // Period during which bundles of a quarantined searcher are dropped
+}
+
+// bundleRevertError is returned by bundle simulation when a transaction not allowed to revert reverts.
+type bundleRevertError struct {
+	GasUsed uint64 // Gas burned by the bundle up to and including the reverted transaction
+}
+
+func (e *bundleRevertError) Error() string {
+	return "failed tx"
+}
+
+type searcherStrikes struct {
+	strikes          map[common.Hash]time.Time // Offending bundles by hash, each bundle counts once
+	quarantinedUntil time.Time
+}
+
+// griefingTracker counts the offending bundles of each searcher and quarantines searchers
+// reaching the strike limit. Strikes are only charged to the signer that authenticated the
+// submission of the bundle, as the signing address of a pooled bundle may be claimed by anyone.
+// It is shared by all workers and safe for concurrent use.
+type griefingTracker struct {
+	config  GriefingConfig
+	signers *lru.Cache[common.Hash, common.Address] // Authenticated signers of the submitted bundles by hash
+
+	mu        sync.Mutex
+	searchers map[common.Address]*searcherStrikes
+	now       func() time.Time
+}
+
+// newGriefingTracker returns the tracker for the config, nil if detection is disabled.
+func newGriefingTracker(config GriefingConfig) *griefingTracker {
+	if config.GasThreshold == 0 || config.MaxStrikes <= 0 {
+		return nil
+	}
+	return &griefingTracker{
+		config:    config,
+		signers:   lru.NewCache[common.Hash, common.Address](griefingSignersCacheSize),
+		searchers: make(map[common.Address]*searcherStrikes),
+		now:       time.Now,
+	}
+}
+
+// quarantined reports whether bundles of the searcher must be dropped.
+func (g *griefingTracker) quarantined(searcher common.Address) bool {
+	if g == nil || searcher == (common.Address{}) {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	s, ok := g.searchers[searcher]
+	return ok && g.now().Before(s.quarantinedUntil)
+}
+
+// submitted records the signer of a bundle submitted over RPC, the signer is zero for unsigned
+// requests. The bundles read from the bundle database carry a signing address the builder did not
+// verify, they are not recorded.
+func (g *griefingTracker) submitted(signer common.Address, bundleHash common.Hash, source string) {
+	if g == nil || signer == (common.Address{}) || bundleHash == (common.Hash{}) || source == BundleSourceFetcher {
+		return
+	}
+	g.signers.Add(bundleHash, signer)
+}
+
+// simulationFailed records a failed simulation of a searcher bundle, it is a strike
+// if the bundle reverted after burning at least the configured gas.
+func (g *griefingTracker) simulationFailed(bundleHash common.Hash, err error) {
+	if g == nil {
+		return
+	}
+	var revertErr *bundleRevertError
+	if !errors.As(err, &revertErr) || revertErr.GasUsed < g.config.GasThreshold {
+		return
+	}
+	g.strike(bundleHash, fmt.Sprintf("reverted after burning %d gas", revertErr.GasUsed))
+}
+
+// profitVanished records a bundle whose simulated profit was not paid when committed to a block,
+// such bundles inflate their apparent profit by targeting the coinbase during simulation.
+func (g *griefingTracker) profitVanished(bundleHash common.Hash, e *lowProfitError) {
+	if g == nil || e.ActualProfit == nil || e.ActualProfit.Sign() > 0 {
+		return
+	}
+	g.strike(bundleHash, fmt.Sprintf("simulated profit %v not paid on commit", e.ExpectedProfit))
+}
+
+// strike charges an offending bundle to the signer that submitted it, bundles without an
+// authenticated signer are not charged to anyone.
+func (g *griefingTracker) strike(bundleHash common.Hash, reason string) {
+	searcher, ok := g.signers.Get(bundleHash)
+	if !ok {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	s, ok := g.searchers[searcher]
+	if !ok {
+		s = &searcherStrikes{strikes: make(map[common.Hash]time.Time)}
+		g.searchers[searcher] = s
+	}
+	if now.Before(s.quarantinedUntil) {
+		return
+	}
+	for hash, at := range s.strikes {
+		if now.Sub(at) >= g.config.Window {
+			delete(s.strikes, hash)
+		}
+	}
+	s.strikes[bundleHash] = now
+	if metrics.EnabledBuilder {
+		griefingStrikeMeter.Mark(1)
+	}
+	log.Debug("Griefing bundle detected", "searcher", searcher, "bundle", bundleHash, "reason", reason, "strikes", len(s.strikes))
+
+	if len(s.strikes) >= g.config.MaxStrikes {
+		s.quarantinedUntil = now.Add(g.config.Quarantine)
+		s.strikes = make(map[common.Hash]time.Time)
+		if metrics.EnabledBuilder {
+			griefingQuarantineMeter.Mark(1)
+		}
+		log.Warn("Quarantined griefing searcher", "searcher", searcher, "until", s.quarantinedUntil, "lastBundle", bundleHash, "reason", reason)
+	}
+	g.prune(now)
+}
+
+// prune drops the searchers with no recent strikes and no ongoing quarantine
+func (g *griefingTracker) prune(now time.Time) {
+	for searcher, s := range g.searchers {
+		if now.Before(s.quarantinedUntil) {
+			continue
+		}
+		recent := false
+		for _, at := range s.strikes {
+			if now.Sub(at) < g.config.Window {
+				recent = true
+				break
+			}
+		}
+		if !recent {
+			delete(g.searchers, searcher)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGriefingTracker(t *testing.T) {
+	var (
+		griefer  = common.HexToAddress("0x01")
+		searcher = common.HexToAddress("0x02")
+	)
+
+	tracker := newGriefingTracker(GriefingConfig{
+		GasThreshold: 100_000,
+		MaxStrikes:   3,
+		Window:       time.Minute,
+		Quarantine:   time.Hour,
+	})
+	now := time.Unix(1000, 0)
+	tracker.now = func() time.Time { return now }
+	for i := byte(1); i <= 7; i++ {
+		tracker.submitted(griefer, common.Hash{i}, BundleSourceHTTP)
+	}
+
+	revert := &bundleRevertError{GasUsed: 500_000}
+	tracker.simulationFailed(common.Hash{1}, revert)
+	// the same bundle counts once
+	tracker.simulationFailed(common.Hash{1}, revert)
+	// cheap reverts and other simulation errors are not strikes
+	tracker.simulationFailed(common.Hash{2}, &bundleRevertError{GasUsed: 50_000})
+	tracker.simulationFailed(common.Hash{3}, errors.New("nonce too low"))
+	tracker.profitVanished(common.Hash{4}, &lowProfitError{ExpectedProfit: big.NewInt(10), ActualProfit: big.NewInt(5)})
+	if tracker.quarantined(griefer) {
+		t.Fatal("searcher quarantined before reaching the strike limit")
+	}
+
+	// strikes expire with the window
+	now = now.Add(time.Minute)
+	tracker.simulationFailed(common.Hash{5}, revert)
+	tracker.profitVanished(common.Hash{6}, &lowProfitError{ExpectedProfit: big.NewInt(10), ActualProfit: new(big.Int)})
+	if tracker.quarantined(griefer) {
+		t.Fatal("expired strike counted")
+	}
+
+	tracker.simulationFailed(common.Hash{7}, revert)
+	if !tracker.quarantined(griefer) {
+		t.Fatal("searcher not quarantined after reaching the strike limit")
+	}
+	if tracker.quarantined(searcher) || tracker.quarantined(common.Address{}) {
+		t.Fatal("unexpected quarantine")
+	}
+
+	now = now.Add(time.Hour)
+	if tracker.quarantined(griefer) {
+		t.Fatal("quarantine did not expire")
+	}
+
+	if newGriefingTracker(GriefingConfig{MaxStrikes: 3}) != nil {
+		t.Fatal("expected griefing detection to be disabled without gas threshold")
+	}
+	// disabled tracker is a no-op
+	var disabled *griefingTracker
+	disabled.submitted(griefer, common.Hash{1}, BundleSourceHTTP)
+	disabled.simulationFailed(common.Hash{1}, revert)
+	if disabled.quarantined(griefer) {
+		t.Fatal("disabled tracker quarantined searcher")
+	}
+}
+
+func TestGriefingTrackerSpoofedSigner(t *testing.T) {
+	var (
+		griefer = common.HexToAddress("0x01")
+		victim  = common.HexToAddress("0x02")
+	)
+	tracker := newGriefingTracker(GriefingConfig{
+		GasThreshold: 100_000,
+		MaxStrikes:   1,
+		Window:       time.Minute,
+		Quarantine:   time.Hour,
+	})
+	revert := &bundleRevertError{GasUsed: 500_000}
+
+	// bundles claiming the victim as signing address reach the pool unsigned, or from the
+	// bundle database, the victim never authenticated their submission
+	tracker.submitted(common.Address{}, common.Hash{1}, BundleSourceHTTP)
+	tracker.submitted(victim, common.Hash{2}, BundleSourceFetcher)
+	tracker.simulationFailed(common.Hash{1}, revert)
+	tracker.simulationFailed(common.Hash{2}, revert)
+	tracker.simulationFailed(common.Hash{3}, revert)
+	tracker.profitVanished(common.Hash{3}, &lowProfitError{ExpectedProfit: big.NewInt(10), ActualProfit: new(big.Int)})
+	if tracker.quarantined(victim) {
+		t.Fatal("victim quarantined for bundles it did not sign")
+	}
+
+	// the strikes go to the signer that submitted the bundle
+	tracker.submitted(griefer, common.Hash{4}, BundleSourceWS)
+	tracker.simulationFailed(common.Hash{4}, revert)
+	if !tracker.quarantined(griefer) || tracker.quarantined(victim) {
+		t.Fatal("strike not charged to the authenticated signer")
+	}
+}
@@ -1,9 +1,22 @@
 package miner
 
 import (
+	"time"
+
 	"github.com/ethereum/go-ethereum/metrics"
 )
 
+// slotPhaseEdge is the length of the first and last phases of the slot
+const slotPhaseEdge = 500 * time.Millisecond
+
+// Phases of the slot the latency of the builder stages is recorded by
+const (
+	slotPhaseEarly = iota // First slotPhaseEdge of the slot
+	slotPhaseMid
+	slotPhaseLate // Last slotPhaseEdge before the block timestamp, and past it
+	numSlotPhases
+)
+
 var (
 	blockProfitHistogram   = metrics.NewRegisteredHistogram("miner/block/profit", nil, metrics.NewExpDecaySample(1028, 0.015))
 	bundleTxNumHistogram   = metrics.NewRegisteredHistogram("miner/bundle/txnum", nil, metrics.NewExpDecaySample(1028, 0.015))
@@ -18,8 +31,104 @@ var (
 
 	simulationMeter          = metrics.NewRegisteredMeter("miner/block/simulation", nil)
 	simulationCommittedMeter = metrics.NewRegisteredMeter("miner/block/simulation/committed", nil)
-	simulationRevertedMeter  = metrics.NewRegisteredMeter("miner/block/simulation/reverted", nil)
+
+	bundlePolicyRejectedMeter = metrics.NewRegisteredMeter("miner/bundle/policy/rejected", nil)
+	bundleFloorRejectedMeter  = metrics.NewRegisteredMeter("miner/bundle/floor/rejected", nil)
+	griefingStrikeMeter       = metrics.NewRegisteredMeter("miner/bundle/griefing/strike", nil)
+	griefingQuarantineMeter   = metrics.NewRegisteredMeter("miner/bundle/griefing/quarantine", nil)
+	griefingDroppedMeter      = metrics.NewRegisteredMeter("miner/bundle/griefing/dropped", nil)
+	stateGrowthRejectedMeter  = metrics.NewRegisteredMeter("miner/bundle/stategrowth/rejected", nil)
+	stateGrowthSkippedMeter   = metrics.NewRegisteredMeter("miner/bundle/stategrowth/skipped", nil)
+	mergeReusedMeter          = metrics.NewRegisteredMeter("miner/bundle/merge/reused", nil)
+	mergeResimulatedMeter     = metrics.NewRegisteredMeter("miner/bundle/merge/resimulated", nil)
+	simulationCacheHitMeter   = metrics.NewRegisteredMeter("miner/bundle/simcache/hit", nil)
+	invalidPaymentMeter       = metrics.NewRegisteredMeter("miner/block/payment/invalid", nil)
 
 	gasUsedGauge        = metrics.NewRegisteredGauge("miner/block/gasused", nil)
 	transactionNumGauge = metrics.NewRegisteredGauge("miner/block/txnum", nil)
+	blockBundlesGauge   = metrics.NewRegisteredGauge("miner/block/bundles", nil)
+	finalizeBlockTimer  = metrics.NewRegisteredTimer("miner/block/finalize", nil)
+
+	bundlePoolGauge       = metrics.NewRegisteredGauge("miner/bundle/pool/bundles", nil)
+	sbundlePoolGauge      = metrics.NewRegisteredGauge("miner/bundle/pool/sbundles", nil)
+	simulatedBundlesGauge = metrics.NewRegisteredGauge("miner/bundle/pool/simulated", nil)
+)
+
+var (
+	bundleSimulationSlotPhaseTimers = newSlotPhaseTimers("miner/bundle/simulate")
+	blockSimulationSlotPhaseTimers  = newSlotPhaseTimers("miner/block/simulate")
+	packSlotPhaseTimers             = newSlotPhaseTimers("miner/block/merge")
+
+	simulationFailureMeters = newCategoryMeters("miner/block/simulation/failed", simFailureClasses)
+	bundleRejectionMeters   = newCategoryMeters("miner/block/simulation/rejected", bundleRejectionReasons)
 )
+
+// categoryMeters counts events by category, out of a fixed set of categories.
+type categoryMeters map[string]metrics.Meter
+
+func newCategoryMeters(name string, categories []string) categoryMeters {
+	meters := make(categoryMeters, len(categories))
+	for _, category := range categories {
+		meters[category] = metrics.NewRegisteredMeter(name+"/"+category, nil)
+	}
+	return meters
+}
+
+// markSimulationFailure counts a failed simulation, or a rejected bundle if rejected is true.
+func markSimulationFailure(category string, rejected bool) {
+	if !metrics.EnabledBuilder {
+		return
+	}
+	if rejected {
+		bundleRejectionMeters[category].Mark(1)
+	} else {
+		simulationFailureMeters[category].Mark(1)
+	}
+}
+
+// workerMetrics are the metrics of a greedy worker building blocks in parallel with others,
+// labelled by the algorithm of the worker.
+type workerMetrics struct {
+	build  metrics.Timer // Time to build the block of a payload
+	profit metrics.Gauge // Profit of the last block built
+	best   metrics.Meter // Payloads resolved to the block of the worker
+}
+
+func newWorkerMetrics(algo AlgoType) *workerMetrics {
+	name := "miner/worker/" + algo.String()
+	return &workerMetrics{
+		build:  metrics.GetOrRegisterTimer(name+"/build", nil),
+		profit: metrics.GetOrRegisterGauge(name+"/profit", nil),
+		best:   metrics.GetOrRegisterMeter(name+"/best", nil),
+	}
+}
+
+// slotPhaseTimers records the latency of a builder stage by the phase of the slot the stage
+// started in, as the tail latency close to the deadline matters more than the average.
+type slotPhaseTimers [numSlotPhases]metrics.Timer
+
+func newSlotPhaseTimers(name string) *slotPhaseTimers {
+	return &slotPhaseTimers{
+		slotPhaseEarly: metrics.NewRegisteredTimer(name+"/slot/early", nil),
+		slotPhaseMid:   metrics.NewRegisteredTimer(name+"/slot/mid", nil),
+		slotPhaseLate:  metrics.NewRegisteredTimer(name+"/slot/late", nil),
+	}
+}
+
+// updateSince records the latency of a stage started at start while building env.
+func (t *slotPhaseTimers) updateSince(env *environment, start time.Time) {
+	deadline := time.Unix(int64(env.header.Time), 0)
+	t[slotPhase(start, env.slotStart, deadline)].UpdateSince(start)
+}
+
+// slotPhase returns the phase of the slot spanning from slotStart to deadline at the given time.
+func slotPhase(at, slotStart, deadline time.Time) int {
+	switch {
+	case at.After(deadline.Add(-slotPhaseEdge)):
+		return slotPhaseLate
+	case at.Before(slotStart.Add(slotPhaseEdge)):
+		return slotPhaseEarly
+	default:
+		return slotPhaseMid
+	}
+}
@@ -0,0 +1,32 @@
+package miner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlotPhase(t *testing.T) {
+	var (
+		slotStart = time.Unix(100, 0)
+		deadline  = time.Unix(102, 0)
+	)
+	tests := []struct {
+		at    time.Time
+		phase int
+	}{
+		{slotStart.Add(-time.Second), slotPhaseEarly},
+		{slotStart.Add(100 * time.Millisecond), slotPhaseEarly},
+		{slotStart.Add(time.Second), slotPhaseMid},
+		{deadline.Add(-100 * time.Millisecond), slotPhaseLate},
+		{deadline.Add(time.Second), slotPhaseLate},
+	}
+	for i, test := range tests {
+		if phase := slotPhase(test.at, slotStart, deadline); phase != test.phase {
+			t.Errorf("test %d: unexpected phase %d, want %d", i, phase, test.phase)
+		}
+	}
+	// a slot shorter than both edges is late as soon as it gets close to the deadline
+	if phase := slotPhase(slotStart.Add(100*time.Millisecond), slotStart, slotStart.Add(500*time.Millisecond)); phase != slotPhaseLate {
+		t.Errorf("unexpected phase %d in short slot", phase)
+	}
+}
@@ -18,6 +18,7 @@
 package miner
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
@@ -27,6 +28,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/builder/heimdall"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/consensus"
@@ -34,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/event"
@@ -56,6 +59,8 @@ const (
 	ALGO_GREEDY_BUCKETS
 	ALGO_GREEDY_MULTISNAP
 	ALGO_GREEDY_BUCKETS_MULTISNAP
+	ALGO_GREEDY_EGP
+	ALGO_GREEDY_PROFIT
 )
 
 func (a AlgoType) String() string {
@@ -70,6 +75,10 @@ func (a AlgoType) String() string {
 		return "greedy-buckets"
 	case ALGO_GREEDY_BUCKETS_MULTISNAP:
 		return "greedy-buckets-multi-snap"
+	case ALGO_GREEDY_EGP:
+		return "greedy-egp"
+	case ALGO_GREEDY_PROFIT:
+		return "greedy-profit"
 	default:
 		return "unsupported"
 	}
@@ -87,6 +96,10 @@ func AlgoTypeFlagToEnum(algoString string) (AlgoType, error) {
 		return ALGO_GREEDY_MULTISNAP, nil
 	case ALGO_GREEDY_BUCKETS_MULTISNAP.String():
 		return ALGO_GREEDY_BUCKETS_MULTISNAP, nil
+	case ALGO_GREEDY_EGP.String():
+		return ALGO_GREEDY_EGP, nil
+	case ALGO_GREEDY_PROFIT.String():
+		return ALGO_GREEDY_PROFIT, nil
 	default:
 		return ALGO_MEV_GETH, errors.New("algo not recognized")
 	}
@@ -105,11 +118,34 @@ type Config struct {
 	Recommit                 time.Duration     // The time interval for miner to re-create mining work.
 	Noverify                 bool              // Disable remote mining solution verification(only useful in ethash).
 	BuilderTxSigningKey      *ecdsa.PrivateKey `toml:",omitempty"` // Signing key of builder coinbase to make transaction to validator
+	BuilderTxSigner          TxSigner          `toml:"-"`          // Signer of builder coinbase transactions, takes precedence over BuilderTxSigningKey
 	MaxMergedBundles         int
-	Blocklist                []common.Address `toml:",omitempty"`
-	NewPayloadTimeout        time.Duration    // The maximum time allowance for creating a new payload
-	PriceCutoffPercent       int              // Effective gas price cutoff % used for bucketing transactions by price (only useful in greedy-buckets AlgoType)
-	DiscardRevertibleTxOnErr bool             // When enabled, if bundle revertible transaction has error on commit, builder will discard the transaction
+	Workers                  int                 // Number of greedy workers building blocks in parallel with different algorithms, the most profitable block is submitted
+	Blocklist                []common.Address    `toml:",omitempty"`
+	NewPayloadTimeout        time.Duration       // The maximum time allowance for creating a new payload
+	PriceCutoffPercent       int                 // Effective gas price cutoff % used for bucketing transactions by price (only useful in greedy-buckets AlgoType)
+	DiscardRevertibleTxOnErr bool                // When enabled, if bundle revertible transaction has error on commit, builder will discard the transaction
+	SimulationLimits         vm.SandboxLimits    // Resource limits enforced on the EVM when simulating bundles
+	ProtectiveOrdering       bool                // Reject bundles that sandwich mempool transactions
+	BundlePolicies           []BundlePolicy      `toml:"-"` // Additional policies deciding which bundles may be included
+	GriefingDetection        GriefingConfig      // Quarantine of searchers submitting griefing bundles
+	Reputation               ReputationConfig    // Prioritisation of the bundle simulations by searcher reputation
+	StateGrowth              StateGrowthConfig   // Limits on the state created by bundles
+	BundleFloor              BundleFloorConfig   // Minimum effective gas price and profit of the bundles accepted in a block
+	BidPolicy                BidPolicyConfig     // Value paid to the proposer out of the block profit
+	TopOfBlock               TopOfBlockConfig    // Gas reserved at the top of the blocks to designated searchers and contracts
+	Channels                 []OrderflowChannel  `toml:",omitempty"` // Orderflow channels prioritised by the greedy algorithms
+	StateSync                StateSyncConfig     // Inclusion of the Heimdall state-sync events at the sprint boundaries
+	Heimdall                 heimdall.Config     // Heimdall endpoints of the Bor spans, checkpoints and state-sync events
+	ParentReexec             uint64              // Blocks re-executed to derive the state of a non-canonical parent, 0 = only build on stored states
+	PreSimulation            PreSimulationConfig // Simulation of the bundles in the background as they enter the pool
+	PrefetchBudget           int                 // Accounts and storage slots touched by the bundles on the previous head prefetched on a new head, 0 = disabled
+
+	MultiTxSnapshotMemoryLimit uint64 // Memory cap in bytes of the multi-transaction snapshots of a block, 0 = unlimited
+	MultiTxSnapshotSpill       bool   // Spill the multi-transaction snapshots over the memory cap to a temporary store
+	VerifyMultiTxSnapshots     bool   // Compare the state root after each multi-transaction snapshot revert with the root before the snapshot
+	WitnessDir                 string // Directory the execution witnesses of the sealed blocks are written to, empty = disabled
+	NoSeal                     bool   // Never seal the mined blocks, e.g. while the builder runs in shadow mode
 }
 
 // DefaultConfig contains default settings for miner.
@@ -124,6 +160,14 @@ var DefaultConfig = Config{
 	Recommit:           2 * time.Second,
 	NewPayloadTimeout:  2 * time.Second,
 	PriceCutoffPercent: defaultPriceCutoffPercent,
+	SimulationLimits:   defaultSimulationLimits,
+	GriefingDetection:  defaultGriefingConfig,
+	Reputation:         defaultReputationConfig,
+	BidPolicy:          defaultBidPolicyConfig,
+	StateSync:          defaultStateSyncConfig,
+	Heimdall:           heimdall.DefaultConfig,
+	ParentReexec:       64,
+	PreSimulation:      defaultPreSimulationConfig,
 }
 
 // Miner creates blocks and searches for proof-of-work values.
@@ -140,7 +184,7 @@ type Miner struct {
 }
 
 func New(eth Backend, config *Config, chainConfig *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, isLocalBlock func(header *types.Header) bool) *Miner {
-	if config.BuilderTxSigningKey == nil {
+	if config.BuilderTxSigner == nil && config.BuilderTxSigningKey == nil {
 		key := os.Getenv("BUILDER_TX_SIGNING_KEY")
 		if key, err := crypto.HexToECDSA(strings.TrimPrefix(key, "0x")); err != nil {
 			log.Error("Error parsing builder signing key from env", "err", err)
@@ -309,14 +353,151 @@ func (miner *Miner) DisablePreseal() {
 	miner.worker.disablePreseal()
 }
 
+// CheckTxSigner signs a throwaway transaction with the signer of the builder transactions,
+// returning an error if the signer is not set or not available.
+func (miner *Miner) CheckTxSigner() error {
+	return miner.worker.checkTxSigner()
+}
+
+// ProfitReport returns the profit breakdown of the recent blocks built by the node that landed
+// on chain, along with daily aggregates.
+func (miner *Miner) ProfitReport() ProfitReport {
+	return miner.worker.profitReport()
+}
+
+// BlockProfitBreakdown returns the profit of a recent block built by the node attributed to its
+// transactions and orders, nil if the block is unknown.
+func (miner *Miner) BlockProfitBreakdown(hash common.Hash) *BlockProfitBreakdown {
+	return miner.worker.blockProfitBreakdown(hash)
+}
+
+// Heimdall returns the Heimdall client of the miner, nil if no endpoint is configured.
+func (miner *Miner) Heimdall() *heimdall.Client {
+	return miner.worker.heimdallClient()
+}
+
+// SetBidPolicy replaces the policy deciding the value paid to the proposer out of the profit of
+// the blocks built from now on.
+func (miner *Miner) SetBidPolicy(config BidPolicyConfig) error {
+	return miner.worker.setBidPolicy(config)
+}
+
+// SetTopOfBlockReservation replaces the gas reserved at the top of the blocks built from now on
+// to the orders of designated searchers and contracts.
+func (miner *Miner) SetTopOfBlockReservation(config TopOfBlockConfig) error {
+	return miner.worker.setTopOfBlock(config)
+}
+
+// TopOfBlockReservation returns the gas reserved at the top of the blocks and the searchers and
+// contracts allowed to use it.
+func (miner *Miner) TopOfBlockReservation() TopOfBlockConfig {
+	return miner.worker.topOfBlock()
+}
+
+// SetBundleFloor replaces the minimum effective gas price and profit of the bundles simulated
+// from now on.
+func (miner *Miner) SetBundleFloor(config BundleFloorConfig) error {
+	return miner.worker.setBundleFloor(config)
+}
+
+// BundleFloor returns the minimum effective gas price and profit of the bundles.
+func (miner *Miner) BundleFloor() BundleFloorConfig {
+	return miner.worker.bundleFloor()
+}
+
+// SetAlgorithm replaces the block building algorithm of the blocks built from now on. The
+// greedy algorithms can be switched between each other, not to or from mev-geth.
+func (miner *Miner) SetAlgorithm(algo AlgoType) error {
+	return miner.worker.setAlgorithm(algo)
+}
+
+// Algorithms returns the block building algorithms of the workers, the configured one first.
+func (miner *Miner) Algorithms() []AlgoType {
+	return miner.worker.algorithms()
+}
+
+// SetActiveWorkers sets the number of workers building blocks in parallel, up to the number of
+// workers the node was started with.
+func (miner *Miner) SetActiveWorkers(count int) error {
+	return miner.worker.setActiveWorkers(count)
+}
+
+// Workers returns the number of workers building blocks in parallel and the number of workers
+// the node was started with.
+func (miner *Miner) Workers() (active int, total int) {
+	return len(miner.worker.activeWorkers()), len(miner.worker.workers)
+}
+
+// SearcherAnalytics returns the analytics of the searchers active in the given window.
+func (miner *Miner) SearcherAnalytics(window time.Duration) []SearcherStats {
+	return miner.worker.searcherStats(window)
+}
+
+// SearcherReputations returns the reputation of the searchers with a history, highest score first.
+func (miner *Miner) SearcherReputations() []SearcherReputation {
+	return miner.worker.searcherReputations()
+}
+
+// BundleSourceAnalytics returns the analytics of the bundle ingestion sources active in the given window.
+func (miner *Miner) BundleSourceAnalytics(window time.Duration) []SourceStats {
+	return miner.worker.bundleSourceStats(window)
+}
+
+// ReorgReports returns the reports of the recent blocks built by the node that landed and were
+// reorged out, most recent first.
+func (miner *Miner) ReorgReports() []ReorgReport {
+	return miner.worker.reorgReports()
+}
+
+// BlockValueReport compares the recent canonical blocks with the most valuable blocks built by
+// the node for the same heights, most recent first.
+func (miner *Miner) BlockValueReport() *BlockValueReport {
+	return miner.worker.blockValueReport()
+}
+
+// SubscribeReorgReports delivers the report of every block built by the node that is reorged
+// out after landing.
+func (miner *Miner) SubscribeReorgReports(ch chan<- ReorgReport) event.Subscription {
+	return miner.worker.subscribeReorgReports(ch)
+}
+
+// Backtest replays the historical blocks from..to through the given block building algorithm, the
+// configured one if empty, and compares the blocks the builder would have built with the blocks
+// that landed. The bundles are fed to the block they target. The transactions of the historical
+// mempool dataset, if not nil, pending at every block are its mempool; without dataset, the blocks
+// without bundles are replayed with their own transactions as bundles. It requires the state of
+// the replayed blocks.
+func (miner *Miner) Backtest(ctx context.Context, from, to uint64, algo string, bundles []types.MevBundle, mempool []MempoolTx) (*BacktestReport, error) {
+	return miner.worker.backtest(ctx, from, to, algo, bundles, mempool)
+}
+
+// BundleSubmitted records a bundle accepted from a searcher through the given ingestion source,
+// for the searcher and source analytics. Bundles without hash are not tracked by source.
+func (miner *Miner) BundleSubmitted(searcher common.Address, hash common.Hash, source string) {
+	miner.worker.bundleSubmitted(searcher, hash, source)
+}
+
+// BundleStats returns the status of a submitted bundle: its simulations, the block building
+// rounds which considered it and its inclusion. It returns nil for unknown or expired bundles.
+func (miner *Miner) BundleStats(hash common.Hash) *BundleStats {
+	return miner.worker.bundleStats(hash)
+}
+
+// SimulateBundle simulates a bundle submitted by a searcher at the top of the latest block being
+// built, for the feedback sent back to the searcher.
+func (miner *Miner) SimulateBundle(bundle types.MevBundle) (*types.SimulatedBundle, error) {
+	return miner.worker.simulateSubmitted(bundle)
+}
+
 // SubscribePendingLogs starts delivering logs from pending transactions
 // to the given channel.
 func (miner *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
 	return miner.worker.regularWorker.pendingLogsFeed.Subscribe(ch)
 }
 
-// Accepts the block, time at which orders were taken, bundles which were used to build the block and all bundles that were considered for the block
-type BlockHookFn = func(*types.Block, *big.Int, time.Time, []types.SimulatedBundle, []types.SimulatedBundle, []types.UsedSBundle)
+// Accepts the block, time at which orders were taken, bundles which were used to build the block and all bundles that were considered for the block,
+// and the id of the build round in the logs
+type BlockHookFn = func(*types.Block, *big.Int, time.Time, []types.SimulatedBundle, []types.SimulatedBundle, []types.UsedSBundle, uint64)
 
 // BuildPayload builds the payload according to the provided parameters.
 func (miner *Miner) BuildPayload(args *BuildPayloadArgs) (*Payload, error) {
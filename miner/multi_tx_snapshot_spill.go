@@ -0,0 +1,84 @@
+package miner
+
+import (
+	"os"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// The cache in MB and file handles of the multi-transaction snapshot spill store, the snapshots
+// are written once and read back at most once
+const (
+	multiTxSnapshotSpillCache   = 16
+	multiTxSnapshotSpillHandles = 16
+)
+
+// multiTxSnapshotSpill is the temporary store the multi-transaction snapshots over the memory
+// limit are spilled to. The store is deleted when closed.
+type multiTxSnapshotSpill struct {
+	ethdb.Database
+	dir string
+}
+
+func openMultiTxSnapshotSpill() (*multiTxSnapshotSpill, error) {
+	dir, err := os.MkdirTemp("", "multi-tx-snapshots-")
+	if err != nil {
+		return nil, err
+	}
+	db, err := rawdb.NewPebbleDBDatabase(dir, multiTxSnapshotSpillCache, multiTxSnapshotSpillHandles, "miner/multitxsnapshot/", false)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &multiTxSnapshotSpill{Database: db, dir: dir}, nil
+}
+
+func (s *multiTxSnapshotSpill) Close() error {
+	err := s.Database.Close()
+	if removeErr := os.RemoveAll(s.dir); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// configureMultiTxSnapshots applies the memory limit and the revert verification of the
+// multi-transaction snapshots to the state of a new environment.
+func (w *worker) configureMultiTxSnapshots(state *state.StateDB) {
+	if w.config.VerifyMultiTxSnapshots {
+		state.SetMultiTxSnapshotVerification(true)
+	}
+	if w.config.MultiTxSnapshotMemoryLimit == 0 {
+		return
+	}
+	var spill ethdb.KeyValueStore
+	if w.multiTxSnapshotSpill != nil {
+		spill = w.multiTxSnapshotSpill
+	}
+	state.SetMultiTxSnapshotMemoryLimit(w.config.MultiTxSnapshotMemoryLimit, spill)
+}
+
+// openMultiTxSnapshotSpill opens the spill store of the worker if enabled. The snapshots are
+// only capped without store if it cannot be opened.
+func (w *worker) openMultiTxSnapshotSpill() {
+	if w.config.MultiTxSnapshotMemoryLimit == 0 || !w.config.MultiTxSnapshotSpill {
+		return
+	}
+	spill, err := openMultiTxSnapshotSpill()
+	if err != nil {
+		log.Error("Failed to open the multi-transaction snapshot spill store, snapshots over the memory limit are not spilled", "err", err)
+		return
+	}
+	w.multiTxSnapshotSpill = spill
+}
+
+func (w *worker) closeMultiTxSnapshotSpill() {
+	if w.multiTxSnapshotSpill == nil {
+		return
+	}
+	if err := w.multiTxSnapshotSpill.Close(); err != nil {
+		log.Warn("Failed to close the multi-transaction snapshot spill store", "err", err)
+	}
+}
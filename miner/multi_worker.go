@@ -1,20 +1,26 @@
 package miner
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
 
+	"github.com/ethereum/go-ethereum/builder/heimdall"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 )
 
 type multiWorker struct {
 	workers       []*worker
 	regularWorker *worker
+	active        atomic.Int32 // Number of workers building the payloads, the first ones, 0 = all
 }
 
 func (w *multiWorker) stop() {
@@ -33,6 +39,8 @@ func (w *multiWorker) close() {
 	for _, worker := range w.workers {
 		worker.close()
 	}
+	w.regularWorker.flashbots.preSim.close()
+	w.regularWorker.flashbots.reputation.close()
 }
 
 func (w *multiWorker) isRunning() bool {
@@ -44,6 +52,145 @@ func (w *multiWorker) isRunning() bool {
 	return false
 }
 
+// checkTxSigner checks that the signer of the builder transactions is available
+func (w *multiWorker) checkTxSigner() error {
+	return checkTxSigner(w.regularWorker.txSigner, w.regularWorker.chainConfig)
+}
+
+// profitReport returns the profit of the recent blocks built by the workers that landed on chain
+func (w *multiWorker) profitReport() ProfitReport {
+	return w.regularWorker.flashbots.profits.report()
+}
+
+// blockProfitBreakdown returns the profit breakdown of a recent block built by the workers
+func (w *multiWorker) blockProfitBreakdown(hash common.Hash) *BlockProfitBreakdown {
+	return w.regularWorker.flashbots.breakdowns.get(hash)
+}
+
+// heimdallClient returns the Heimdall client shared by the workers, nil if not configured
+func (w *multiWorker) heimdallClient() *heimdall.Client {
+	return w.regularWorker.flashbots.heimdall
+}
+
+// setBidPolicy replaces the bid policy of the workers
+func (w *multiWorker) setBidPolicy(config BidPolicyConfig) error {
+	return w.regularWorker.flashbots.bids.setConfig(config)
+}
+
+// setTopOfBlock replaces the top of block reservation of the workers
+func (w *multiWorker) setTopOfBlock(config TopOfBlockConfig) error {
+	return w.regularWorker.flashbots.topOfBlock.setConfig(config)
+}
+
+// topOfBlock returns the top of block reservation of the workers
+func (w *multiWorker) topOfBlock() TopOfBlockConfig {
+	return w.regularWorker.flashbots.topOfBlock.getConfig()
+}
+
+// setBundleFloor replaces the floors of the bundles of the workers
+func (w *multiWorker) setBundleFloor(config BundleFloorConfig) error {
+	return w.regularWorker.flashbots.floors.setConfig(config)
+}
+
+// bundleFloor returns the floors of the bundles of the workers
+func (w *multiWorker) bundleFloor() BundleFloorConfig {
+	return w.regularWorker.bundleFloor()
+}
+
+// setAlgorithm replaces the algorithms of the greedy workers, the first worker runs the given
+// algorithm and the others the parallel algorithms. The mev-geth workers can't be switched. The
+// metrics of the workers keep the names of the algorithms they started with.
+func (w *multiWorker) setAlgorithm(algo AlgoType) error {
+	if algo == ALGO_MEV_GETH || w.regularWorker.algorithm() == ALGO_MEV_GETH {
+		return errors.New("the mev-geth algorithm can't be switched at runtime")
+	}
+	for i, algo := range workerAlgos(algo, len(w.workers)) {
+		w.workers[i].setAlgorithm(algo)
+	}
+	return nil
+}
+
+// algorithms returns the algorithms of the workers, in order.
+func (w *multiWorker) algorithms() []AlgoType {
+	algos := make([]AlgoType, len(w.workers))
+	for i, worker := range w.workers {
+		algos[i] = worker.algorithm()
+	}
+	return algos
+}
+
+// setActiveWorkers sets the number of workers building the payloads, the first ones. The workers
+// are started with the node, there can't be more than were configured.
+func (w *multiWorker) setActiveWorkers(count int) error {
+	if count < 1 || count > len(w.workers) {
+		return fmt.Errorf("invalid worker count %d, expected 1 to %d", count, len(w.workers))
+	}
+	w.active.Store(int32(count))
+	return nil
+}
+
+// activeWorkers returns the workers building the payloads.
+func (w *multiWorker) activeWorkers() []*worker {
+	if count := int(w.active.Load()); count > 0 && count < len(w.workers) {
+		return w.workers[:count]
+	}
+	return w.workers
+}
+
+// searcherStats returns the analytics of the searchers active in the given window
+func (w *multiWorker) searcherStats(window time.Duration) []SearcherStats {
+	return w.regularWorker.flashbots.searchers.stats(window)
+}
+
+// searcherReputations returns the reputation of the searchers with a history
+func (w *multiWorker) searcherReputations() []SearcherReputation {
+	return w.regularWorker.flashbots.reputation.reputations()
+}
+
+// bundleSourceStats returns the analytics of the bundle ingestion sources active in the given window
+func (w *multiWorker) bundleSourceStats(window time.Duration) []SourceStats {
+	return w.regularWorker.flashbots.sources.stats(window)
+}
+
+// reorgReports returns the reports of the recent built blocks that were reorged out
+func (w *multiWorker) reorgReports() []ReorgReport {
+	return w.regularWorker.flashbots.reorgs.recent()
+}
+
+// blockValueReport compares the recent canonical blocks with the blocks built for their heights
+func (w *multiWorker) blockValueReport() *BlockValueReport {
+	return w.regularWorker.flashbots.values.report()
+}
+
+// subscribeReorgReports delivers the reports of the built blocks reorged out to the channel
+func (w *multiWorker) subscribeReorgReports(ch chan<- ReorgReport) event.Subscription {
+	return w.regularWorker.flashbots.reorgs.subscribe(ch)
+}
+
+// backtest replays the historical blocks from..to through the given block building algorithm
+func (w *multiWorker) backtest(ctx context.Context, from, to uint64, algo string, bundles []types.MevBundle, mempool []MempoolTx) (*BacktestReport, error) {
+	return w.regularWorker.backtest(ctx, from, to, algo, bundles, mempool)
+}
+
+// bundleStats returns the status of a submitted bundle, nil if it is not tracked
+func (w *multiWorker) bundleStats(hash common.Hash) *BundleStats {
+	return w.regularWorker.flashbots.bundleStats.stats(hash)
+}
+
+// simulateSubmitted simulates a bundle submitted by a searcher for its feedback
+func (w *multiWorker) simulateSubmitted(bundle types.MevBundle) (*types.SimulatedBundle, error) {
+	return w.regularWorker.simulateSubmitted(bundle)
+}
+
+// bundleSubmitted records a bundle accepted from a searcher through the given source
+func (w *multiWorker) bundleSubmitted(searcher common.Address, hash common.Hash, source string) {
+	w.regularWorker.flashbots.searchers.submitted(searcher)
+	w.regularWorker.flashbots.sources.submitted(hash, source)
+	w.regularWorker.flashbots.channels.submitted(hash, searcher, source)
+	w.regularWorker.flashbots.bundleStats.submitted(hash, searcher)
+	w.regularWorker.flashbots.griefing.submitted(searcher, hash, source)
+}
+
 // pendingBlockAndReceipts returns pending block and corresponding receipts from the `regularWorker`
 func (w *multiWorker) pendingBlockAndReceipts() (*types.Block, types.Receipts) {
 	// return a snapshot to avoid contention on currentMu mutex
@@ -108,14 +255,15 @@ func (w *multiWorker) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 	// Construct a payload object for return.
 	payload := newPayload(empty, args.Id())
 
-	if len(w.workers) == 0 {
+	workers := w.activeWorkers()
+	if len(workers) == 0 {
 		return payload, nil
 	}
 
 	// Keep separate payloads for each worker so that ResolveFull actually resolves the best of all workers
 	workerPayloads := []*Payload{}
 
-	for _, w := range w.workers {
+	for _, w := range workers {
 		workerPayload := newPayload(empty, args.Id())
 		workerPayloads = append(workerPayloads, workerPayload)
 
@@ -124,6 +272,10 @@ func (w *multiWorker) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 			start := time.Now()
 			block, fees, err := w.getSealingBlock(args.Parent, args.Timestamp, args.FeeRecipient, args.GasLimit, args.Random, args.Withdrawals, false, args.BlockHook)
 			if err == nil {
+				if metrics.EnabledBuilder && w.flashbots.metrics != nil {
+					w.flashbots.metrics.build.UpdateSince(start)
+					w.flashbots.metrics.profit.Update(fees.Int64())
+				}
 				workerPayload.update(block, fees, time.Since(start))
 			} else {
 				log.Error("Error while sealing block", "err", err)
@@ -132,7 +284,12 @@ func (w *multiWorker) buildPayload(args *BuildPayloadArgs) (*Payload, error) {
 		}(w)
 	}
 
-	go payload.resolveBestFullPayload(workerPayloads)
+	go func() {
+		best := payload.resolveBestFullPayload(workerPayloads)
+		if metrics.EnabledBuilder && best >= 0 && workers[best].flashbots.metrics != nil {
+			workers[best].flashbots.metrics.best.Mark(1)
+		}
+	}()
 
 	return payload, nil
 }
@@ -141,28 +298,112 @@ func newMultiWorker(config *Config, chainConfig *params.ChainConfig, engine cons
 	switch config.AlgoType {
 	case ALGO_MEV_GETH:
 		return newMultiWorkerMevGeth(config, chainConfig, engine, eth, mux, isLocalBlock, init)
-	case ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP:
+	case ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP, ALGO_GREEDY_EGP, ALGO_GREEDY_PROFIT:
 		return newMultiWorkerGreedy(config, chainConfig, engine, eth, mux, isLocalBlock, init)
 	default:
 		panic("unsupported builder algorithm found")
 	}
 }
 
+// parallelAlgos are the algorithms of the greedy workers added to the configured one, in order.
+// The multi-snapshot algorithms are left out, they build the same blocks as the algorithms they
+// derive from.
+var parallelAlgos = []AlgoType{ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_EGP, ALGO_GREEDY_PROFIT}
+
+// workerAlgos returns the algorithms of the given number of greedy workers, the configured
+// algorithm first. Every worker runs a different algorithm, there are fewer algorithms than
+// workers if the count exceeds the algorithms available.
+func workerAlgos(algo AlgoType, count int) []AlgoType {
+	base := algo
+	switch algo {
+	case ALGO_GREEDY_MULTISNAP:
+		base = ALGO_GREEDY
+	case ALGO_GREEDY_BUCKETS_MULTISNAP:
+		base = ALGO_GREEDY_BUCKETS
+	}
+
+	algos := []AlgoType{algo}
+	for _, other := range parallelAlgos {
+		if len(algos) >= count {
+			break
+		}
+		if other != base {
+			algos = append(algos, other)
+		}
+	}
+	return algos
+}
+
 func newMultiWorkerGreedy(config *Config, chainConfig *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, isLocalBlock func(header *types.Header) bool, init bool) *multiWorker {
 	queue := make(chan *task)
 
-	greedyWorker := newWorker(config, chainConfig, engine, eth, mux, isLocalBlock, init, &flashbotsData{
-		isFlashbots:      true,
-		queue:            queue,
-		algoType:         config.AlgoType,
-		maxMergedBundles: config.MaxMergedBundles,
-		bundleCache:      NewBundleCache(),
-	})
+	bundleCache := NewBundleCache()
+	griefing := newGriefingTracker(config.GriefingDetection)
+	profits := newProfitLedger()
+	breakdowns := newProfitBreakdowns()
+	searchers := newSearcherAnalytics()
+	reputation := newReputationTracker(config.Reputation)
+	sources := newSourceAnalytics()
+	bundleStats := newBundleStatsTracker()
+	reorgs := newReorgTracker()
+	values := newValueComparisons()
+	bids := newBidPolicy(config.BidPolicy)
+	topOfBlock := newTopOfBlockReservation(config.TopOfBlock)
+	channels := newOrderflowChannels(config.Channels)
+	heimdallClient, err := heimdall.New(config.Heimdall)
+	if err != nil {
+		log.Error("Invalid Heimdall config, state-sync events are not committed", "err", err)
+	}
+	stateSync := newStateSyncer(config.StateSync, heimdallClient, chainConfig)
+	parentStates := newParentStates(config.ParentReexec)
+	preSim := newPreSimulator(config.PreSimulation)
+	floors := newBundleFloors(config.BundleFloor)
+	prefetch := newStatePrefetcher(config.PrefetchBudget)
+
+	algos := workerAlgos(config.AlgoType, config.Workers)
+	if config.Workers > len(algos) {
+		log.Warn("Fewer greedy workers than requested, every worker runs a different algorithm", "requested", config.Workers, "workers", len(algos))
+	}
+	workers := make([]*worker, 0, len(algos))
+	for _, algo := range algos {
+		var stats *workerMetrics
+		if len(algos) > 1 {
+			stats = newWorkerMetrics(algo)
+		}
+		workers = append(workers, newWorker(config, chainConfig, engine, eth, mux, isLocalBlock, init, &flashbotsData{
+			isFlashbots:      true,
+			queue:            queue,
+			algoType:         algo,
+			maxMergedBundles: config.MaxMergedBundles,
+			bundleCache:      bundleCache,
+			griefing:         griefing,
+			profits:          profits,
+			breakdowns:       breakdowns,
+			searchers:        searchers,
+			reputation:       reputation,
+			sources:          sources,
+			bundleStats:      bundleStats,
+			reorgs:           reorgs,
+			values:           values,
+			bids:             bids,
+			topOfBlock:       topOfBlock,
+			channels:         channels,
+			heimdall:         heimdallClient,
+			stateSync:        stateSync,
+			parentStates:     parentStates,
+			preSim:           preSim,
+			floors:           floors,
+			prefetch:         prefetch,
+			metrics:          stats,
+		}))
+	}
+
+	preSim.start(workers[0])
 
-	log.Info("creating new greedy worker")
+	log.Info("creating new greedy workers", "algorithms", algos)
 	return &multiWorker{
-		regularWorker: greedyWorker,
-		workers:       []*worker{greedyWorker},
+		regularWorker: workers[0],
+		workers:       workers,
 	}
 }
 
@@ -170,6 +411,26 @@ func newMultiWorkerMevGeth(config *Config, chainConfig *params.ChainConfig, engi
 	queue := make(chan *task)
 
 	bundleCache := NewBundleCache()
+	griefing := newGriefingTracker(config.GriefingDetection)
+	profits := newProfitLedger()
+	breakdowns := newProfitBreakdowns()
+	searchers := newSearcherAnalytics()
+	reputation := newReputationTracker(config.Reputation)
+	sources := newSourceAnalytics()
+	bundleStats := newBundleStatsTracker()
+	reorgs := newReorgTracker()
+	values := newValueComparisons()
+	bids := newBidPolicy(config.BidPolicy)
+	topOfBlock := newTopOfBlockReservation(config.TopOfBlock)
+	channels := newOrderflowChannels(config.Channels)
+	heimdallClient, err := heimdall.New(config.Heimdall)
+	if err != nil {
+		log.Error("Invalid Heimdall config, state-sync events are not committed", "err", err)
+	}
+	stateSync := newStateSyncer(config.StateSync, heimdallClient, chainConfig)
+	parentStates := newParentStates(config.ParentReexec)
+	floors := newBundleFloors(config.BundleFloor)
+	prefetch := newStatePrefetcher(config.PrefetchBudget)
 
 	regularWorker := newWorker(config, chainConfig, engine, eth, mux, isLocalBlock, init, &flashbotsData{
 		isFlashbots:      false,
@@ -177,6 +438,23 @@ func newMultiWorkerMevGeth(config *Config, chainConfig *params.ChainConfig, engi
 		algoType:         ALGO_MEV_GETH,
 		maxMergedBundles: config.MaxMergedBundles,
 		bundleCache:      bundleCache,
+		griefing:         griefing,
+		profits:          profits,
+		breakdowns:       breakdowns,
+		searchers:        searchers,
+		reputation:       reputation,
+		sources:          sources,
+		bundleStats:      bundleStats,
+		reorgs:           reorgs,
+		values:           values,
+		bids:             bids,
+		topOfBlock:       topOfBlock,
+		channels:         channels,
+		heimdall:         heimdallClient,
+		stateSync:        stateSync,
+		parentStates:     parentStates,
+		floors:           floors,
+		prefetch:         prefetch,
 	})
 
 	workers := []*worker{regularWorker}
@@ -189,6 +467,23 @@ func newMultiWorkerMevGeth(config *Config, chainConfig *params.ChainConfig, engi
 					algoType:         ALGO_MEV_GETH,
 					maxMergedBundles: i,
 					bundleCache:      bundleCache,
+					griefing:         griefing,
+					profits:          profits,
+					breakdowns:       breakdowns,
+					searchers:        searchers,
+					reputation:       reputation,
+					sources:          sources,
+					bundleStats:      bundleStats,
+					reorgs:           reorgs,
+					values:           values,
+					bids:             bids,
+					topOfBlock:       topOfBlock,
+					channels:         channels,
+					heimdall:         heimdallClient,
+					stateSync:        stateSync,
+					parentStates:     parentStates,
+					floors:           floors,
+					prefetch:         prefetch,
 				}))
 		}
 	}
@@ -206,4 +501,23 @@ type flashbotsData struct {
 	maxMergedBundles int
 	algoType         AlgoType
 	bundleCache      *BundleCache
+	griefing         *griefingTracker       // Shared by all workers, nil if griefing detection is disabled
+	profits          *profitLedger          // Shared by all workers
+	breakdowns       *profitBreakdowns      // Shared by all workers
+	searchers        *searcherAnalytics     // Shared by all workers
+	reputation       *reputationTracker     // Shared by all workers
+	sources          *sourceAnalytics       // Shared by all workers
+	bundleStats      *bundleStatsTracker    // Shared by all workers
+	reorgs           *reorgTracker          // Shared by all workers
+	values           *valueComparisons      // Shared by all workers
+	bids             *bidPolicy             // Shared by all workers
+	topOfBlock       *topOfBlockReservation // Shared by all workers
+	channels         *orderflowChannels     // Shared by all workers, nil unless orderflow channels are configured
+	heimdall         *heimdall.Client       // Shared by all workers, nil unless Heimdall endpoints are configured
+	stateSync        *stateSyncer           // Shared by all workers, nil unless the state-sync events are committed
+	parentStates     *parentStates          // Shared by all workers, nil unless the state of non-canonical parents is derived
+	preSim           *preSimulator          // Shared by all workers, nil unless the bundles are pre-simulated
+	floors           *bundleFloors          // Shared by all workers, nil = the bundle floors of the config
+	prefetch         *statePrefetcher       // Shared by all workers, nil unless the bundle state is prefetched on new heads
+	metrics          *workerMetrics         // Metrics of the worker, nil unless several greedy workers build in parallel
 }
@@ -0,0 +1,101 @@
+package miner
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestWorkerAlgos(t *testing.T) {
+	tests := []struct {
+		algo     AlgoType
+		count    int
+		expected []AlgoType
+	}{
+		{ALGO_GREEDY, 0, []AlgoType{ALGO_GREEDY}},
+		{ALGO_GREEDY, 1, []AlgoType{ALGO_GREEDY}},
+		{ALGO_GREEDY_PROFIT, 3, []AlgoType{ALGO_GREEDY_PROFIT, ALGO_GREEDY, ALGO_GREEDY_BUCKETS}},
+		// the multi-snapshot algorithms build the same blocks as the algorithms they derive from
+		{ALGO_GREEDY_BUCKETS_MULTISNAP, 2, []AlgoType{ALGO_GREEDY_BUCKETS_MULTISNAP, ALGO_GREEDY}},
+		{ALGO_GREEDY_MULTISNAP, 10, []AlgoType{ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_EGP, ALGO_GREEDY_PROFIT}},
+	}
+	for _, test := range tests {
+		if algos := workerAlgos(test.algo, test.count); !reflect.DeepEqual(algos, test.expected) {
+			t.Errorf("%s with %d workers: got %v, expected %v", test.algo, test.count, algos, test.expected)
+		}
+	}
+}
+
+func TestMultiWorkerRuntimeConfig(t *testing.T) {
+	floors := newBundleFloors(BundleFloorConfig{})
+	var workers []*worker
+	for _, algo := range workerAlgos(ALGO_GREEDY, 3) {
+		workers = append(workers, &worker{flashbots: &flashbotsData{algoType: algo, floors: floors}})
+	}
+	w := &multiWorker{regularWorker: workers[0], workers: workers}
+
+	if err := w.setAlgorithm(ALGO_GREEDY_PROFIT); err != nil {
+		t.Fatalf("failed to set algorithm: %v", err)
+	}
+	if algos := w.algorithms(); !reflect.DeepEqual(algos, []AlgoType{ALGO_GREEDY_PROFIT, ALGO_GREEDY, ALGO_GREEDY_BUCKETS}) {
+		t.Errorf("unexpected algorithms %v", algos)
+	}
+	if err := w.setAlgorithm(ALGO_MEV_GETH); err == nil {
+		t.Error("switched to mev-geth")
+	}
+
+	if len(w.activeWorkers()) != 3 {
+		t.Fatal("workers inactive by default")
+	}
+	for _, count := range []int{0, 4} {
+		if err := w.setActiveWorkers(count); err == nil {
+			t.Errorf("%d active workers accepted", count)
+		}
+	}
+	if err := w.setActiveWorkers(2); err != nil {
+		t.Fatalf("failed to set active workers: %v", err)
+	}
+	if active := w.activeWorkers(); len(active) != 2 || active[0] != workers[0] {
+		t.Errorf("unexpected active workers %v", active)
+	}
+
+	if err := w.setBundleFloor(BundleFloorConfig{MinProfit: big.NewInt(-1)}); err == nil {
+		t.Error("negative floor accepted")
+	}
+	if err := w.setBundleFloor(BundleFloorConfig{MinProfit: big.NewInt(100)}); err != nil {
+		t.Fatalf("failed to set bundle floor: %v", err)
+	}
+	if floor := workers[2].bundleFloor(); floor.MinProfit.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("floor not shared by the workers: %v", floor.MinProfit)
+	}
+}
+
+func TestResolveBestFullPayload(t *testing.T) {
+	var (
+		id       = engine.PayloadID{0x01}
+		empty    = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+		payloads = make([]*Payload, 3)
+	)
+	for i := range payloads {
+		payloads[i] = newPayload(empty, id)
+	}
+	payloads[0].update(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: []byte{0}}), big.NewInt(10), 0)
+	payloads[1].Cancel()
+	best := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: []byte{2}})
+	payloads[2].update(best, big.NewInt(20), 0)
+
+	payload := newPayload(empty, id)
+	if index := payload.resolveBestFullPayload(payloads); index != 2 {
+		t.Fatalf("resolved payload mismatch, got %d, expected 2", index)
+	}
+	if payload.full.Hash() != best.Hash() {
+		t.Errorf("resolved block mismatch, got %x, expected %x", payload.full.Hash(), best.Hash())
+	}
+
+	if index := newPayload(empty, id).resolveBestFullPayload(payloads[1:2]); index != -1 {
+		t.Errorf("expected no payload resolved, got %d", index)
+	}
+}
@@ -0,0 +1,269 @@
+package miner
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Built-in orderflow channels
+const (
+	ChannelPublic  = "public"  // Public mempool transactions
+	ChannelPrivate = "private" // Private transactions, and the bundles not received on a configured channel
+)
+
+var channelExcludedMeter = metrics.NewRegisteredMeter("miner/channel/excluded", nil)
+
+// OrderflowChannel is an ingress channel of the orderflow, e.g. a partner feed, whose orders get a
+// priority boost or an exclusivity window to honor an orderflow agreement. The built-in public and
+// private channels are configured by their name.
+type OrderflowChannel struct {
+	Name      string
+	Searchers []common.Address `toml:",omitempty"` // Signers of the bundles received on the channel
+	Sources   []string         `toml:",omitempty"` // Ingestion sources of the bundles received on the channel, e.g. rpc-ws for a websocket feed
+	Boost     float64          // Percent added to the price the bundles of the channel are ordered by, negative to lower their priority
+	Exclusive time.Duration    // Window after the transactions of the channel are received in which the bundles of other channels including them are excluded
+}
+
+// validateChannels checks the channels have distinct names and their parameters are in range.
+func validateChannels(channels []OrderflowChannel) error {
+	names := make(map[string]struct{}, len(channels))
+	for _, channel := range channels {
+		if channel.Name == "" {
+			return fmt.Errorf("orderflow channel without name")
+		}
+		if _, ok := names[channel.Name]; ok {
+			return fmt.Errorf("duplicate orderflow channel %q", channel.Name)
+		}
+		names[channel.Name] = struct{}{}
+		if channel.Boost <= -100 {
+			return fmt.Errorf("invalid boost %v of orderflow channel %q, expected over -100", channel.Boost, channel.Name)
+		}
+		if channel.Exclusive < 0 {
+			return fmt.Errorf("negative exclusivity window of orderflow channel %q", channel.Name)
+		}
+	}
+	return nil
+}
+
+// channelOrder is the channel a bundle was received on.
+type channelOrder struct {
+	channel string
+	seen    time.Time
+}
+
+// channelClaim is the exclusive channel a transaction was first received on.
+type channelClaim struct {
+	channel string
+	seen    time.Time
+}
+
+// orderflowChannels tags the bundles with the channel they are received on, from their signer or
+// ingestion source, and applies the priority boost and exclusivity window of the channels to the
+// orders of a block. It is shared by all workers and safe for concurrent use, a nil instance
+// leaves the orders untouched.
+type orderflowChannels struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	channels   map[string]OrderflowChannel
+	configured []OrderflowChannel // Channels other than the built-in ones, in the order they are matched
+	bundles    map[common.Hash]*channelOrder
+}
+
+// newOrderflowChannels returns the tracker of the channels, nil if no channel is configured.
+func newOrderflowChannels(channels []OrderflowChannel) *orderflowChannels {
+	if len(channels) == 0 {
+		return nil
+	}
+	if err := validateChannels(channels); err != nil {
+		log.Error("Invalid orderflow channels, the orders are not prioritised by channel", "err", err)
+		return nil
+	}
+	c := &orderflowChannels{
+		now:      time.Now,
+		channels: make(map[string]OrderflowChannel, len(channels)),
+		bundles:  make(map[common.Hash]*channelOrder),
+	}
+	for _, channel := range channels {
+		c.channels[channel.Name] = channel
+		if channel.Name != ChannelPublic && channel.Name != ChannelPrivate {
+			c.configured = append(c.configured, channel)
+		}
+	}
+	return c
+}
+
+// submitted tags a bundle accepted from the given signer and ingestion source with its channel,
+// the first configured channel of the signer or source, else the private channel.
+func (c *orderflowChannels) submitted(hash common.Hash, searcher common.Address, source string) {
+	if c == nil || hash == (common.Hash{}) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// resubmissions keep the channel the bundle was first received on
+	if _, ok := c.bundles[hash]; ok {
+		return
+	}
+	channel := ChannelPrivate
+	for _, configured := range c.configured {
+		if containsChannelMember(configured, searcher, source) {
+			channel = configured.Name
+			break
+		}
+	}
+	c.bundles[hash] = &channelOrder{channel: channel, seen: c.now()}
+}
+
+// containsChannelMember reports whether the bundles of the searcher or source belong to the channel.
+func containsChannelMember(channel OrderflowChannel, searcher common.Address, source string) bool {
+	if searcher != (common.Address{}) {
+		for _, member := range channel.Searchers {
+			if member == searcher {
+				return true
+			}
+		}
+	}
+	for _, member := range channel.Sources {
+		if member == source {
+			return true
+		}
+	}
+	return false
+}
+
+// channel returns the channel of the bundle and when it was received, the lock must be held.
+// Bundles of unknown origin, e.g. built by the builder, belong to the private channel.
+func (c *orderflowChannels) channel(hash common.Hash) (string, time.Time) {
+	if order, ok := c.bundles[hash]; ok {
+		return order.channel, order.seen
+	}
+	return ChannelPrivate, c.now()
+}
+
+// prioritize returns the bundles and sbundles of the block with the priority of their channel,
+// without the ones including transactions of another channel still in its exclusivity window.
+// The transactions of a channel are the ones of its bundles and, for the private channel, the
+// private transactions of the pool, whose time of arrival privateTx returns.
+func (c *orderflowChannels) prioritize(bundles []types.SimulatedBundle, sbundles []*types.SimSBundle, privateTx func(common.Hash) (time.Time, bool)) ([]types.SimulatedBundle, []*types.SimSBundle) {
+	if c == nil {
+		return bundles, sbundles
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		now    = c.now()
+		claims = make(map[common.Hash]channelClaim)
+	)
+	claim := func(hash common.Hash, channel string, seen time.Time) {
+		window := c.channels[channel].Exclusive
+		if window == 0 || now.Sub(seen) >= window {
+			return
+		}
+		if prev, ok := claims[hash]; !ok || seen.Before(prev.seen) {
+			claims[hash] = channelClaim{channel: channel, seen: seen}
+		}
+	}
+	// excluded reports whether the transactions include one claimed by another channel
+	excluded := func(channel string, txs []*types.Transaction) bool {
+		for _, tx := range txs {
+			if seen, ok := privateTx(tx.Hash()); ok {
+				claim(tx.Hash(), ChannelPrivate, seen)
+			}
+			if claim, ok := claims[tx.Hash()]; ok && claim.channel != channel {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, bundle := range bundles {
+		channel, seen := c.channel(bundle.OriginalBundle.Hash)
+		for _, tx := range bundle.OriginalBundle.Txs {
+			claim(tx.Hash(), channel, seen)
+		}
+	}
+	for _, sbundle := range sbundles {
+		channel, seen := c.channel(sbundle.Bundle.Hash())
+		for _, tx := range sbundleTxs(sbundle.Bundle) {
+			claim(tx.Hash(), channel, seen)
+		}
+	}
+
+	prioritized := make([]types.SimulatedBundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		channel, _ := c.channel(bundle.OriginalBundle.Hash)
+		if excluded(channel, bundle.OriginalBundle.Txs) {
+			if metrics.EnabledBuilder {
+				channelExcludedMeter.Mark(1)
+			}
+			log.Trace("Bundle excluded by orderflow exclusivity", "bundle", bundle.OriginalBundle.Hash, "channel", channel)
+			continue
+		}
+		if boost := c.channels[channel].Boost; boost != 0 && bundle.MevGasPrice != nil {
+			bundle.Priority = boostPrice(bundle.MevGasPrice, boost)
+		}
+		prioritized = append(prioritized, bundle)
+	}
+	prioritizedS := make([]*types.SimSBundle, 0, len(sbundles))
+	for _, sbundle := range sbundles {
+		channel, _ := c.channel(sbundle.Bundle.Hash())
+		if excluded(channel, sbundleTxs(sbundle.Bundle)) {
+			if metrics.EnabledBuilder {
+				channelExcludedMeter.Mark(1)
+			}
+			log.Trace("Sbundle excluded by orderflow exclusivity", "bundle", sbundle.Bundle.Hash(), "channel", channel)
+			continue
+		}
+		if boost := c.channels[channel].Boost; boost != 0 && sbundle.MevGasPrice != nil {
+			boosted := *sbundle
+			boosted.Priority = boostPrice(sbundle.MevGasPrice, boost)
+			sbundle = &boosted
+		}
+		prioritizedS = append(prioritizedS, sbundle)
+	}
+	return prioritized, prioritizedS
+}
+
+// chainHead forgets the channel of the bundles too old to be included.
+func (c *orderflowChannels) chainHead() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for hash, order := range c.bundles {
+		if now.Sub(order.seen) > sourceBundleRetention {
+			delete(c.bundles, hash)
+		}
+	}
+}
+
+// boostPrice returns the price increased by the percent boost.
+func boostPrice(price *big.Int, boost float64) *big.Int {
+	boosted := new(big.Int).Mul(price, big.NewInt(int64((100+boost)*100)))
+	return boosted.Div(boosted, big.NewInt(100*100))
+}
+
+// sbundleTxs returns the transactions of the sbundle and its nested sbundles.
+func sbundleTxs(sbundle *types.SBundle) []*types.Transaction {
+	var txs []*types.Transaction
+	for _, body := range sbundle.Body {
+		if body.Tx != nil {
+			txs = append(txs, body.Tx)
+		} else if body.Bundle != nil {
+			txs = append(txs, sbundleTxs(body.Bundle)...)
+		}
+	}
+	return txs
+}
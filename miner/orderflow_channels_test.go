@@ -0,0 +1,78 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestOrderflowChannels(t *testing.T) {
+	if newOrderflowChannels(nil) != nil {
+		t.Fatal("tracker created without channels")
+	}
+	if newOrderflowChannels([]OrderflowChannel{{Name: "partner"}, {Name: "partner"}}) != nil {
+		t.Fatal("duplicate channels accepted")
+	}
+
+	var (
+		partner  = common.HexToAddress("0xaa")
+		searcher = common.HexToAddress("0xbb")
+		now      = time.Unix(1_700_000_000, 0)
+	)
+	c := newOrderflowChannels([]OrderflowChannel{
+		{Name: "partner", Searchers: []common.Address{partner}, Boost: 50, Exclusive: 2 * time.Second},
+		{Name: "feed", Sources: []string{BundleSourceWS}},
+		{Name: ChannelPrivate, Exclusive: time.Second},
+	})
+	c.now = func() time.Time { return now }
+
+	tx := func(nonce uint64) *types.Transaction {
+		return types.NewTransaction(nonce, common.Address{}, new(big.Int), 21000, big.NewInt(1), nil)
+	}
+	bundle := func(hash common.Hash, txs ...*types.Transaction) types.SimulatedBundle {
+		return types.SimulatedBundle{MevGasPrice: big.NewInt(100), OriginalBundle: types.MevBundle{Hash: hash, Txs: txs}}
+	}
+	var (
+		partnerTx = tx(0)
+		privateTx = tx(1)
+
+		partnerBundle  = bundle(common.HexToHash("0x01"), partnerTx)
+		backrun        = bundle(common.HexToHash("0x02"), partnerTx, tx(2))
+		privateBackrun = bundle(common.HexToHash("0x03"), privateTx, tx(3))
+		feedBundle     = bundle(common.HexToHash("0x04"), tx(4))
+	)
+	c.submitted(partnerBundle.OriginalBundle.Hash, partner, BundleSourceHTTP)
+	c.submitted(backrun.OriginalBundle.Hash, searcher, BundleSourceHTTP)
+	c.submitted(privateBackrun.OriginalBundle.Hash, searcher, BundleSourceWS)
+	c.submitted(feedBundle.OriginalBundle.Hash, searcher, BundleSourceWS)
+
+	received := now
+	private := func(hash common.Hash) (time.Time, bool) {
+		return received, hash == privateTx.Hash()
+	}
+	bundles := []types.SimulatedBundle{partnerBundle, backrun, privateBackrun, feedBundle}
+	prioritized, _ := c.prioritize(bundles, nil, private)
+
+	// the bundles of other channels including the transactions of the exclusive channels are excluded
+	if len(prioritized) != 2 || prioritized[0].OriginalBundle.Hash != partnerBundle.OriginalBundle.Hash || prioritized[1].OriginalBundle.Hash != feedBundle.OriginalBundle.Hash {
+		t.Fatalf("unexpected prioritized bundles %v", prioritized)
+	}
+	if prioritized[0].Priority == nil || prioritized[0].Priority.Int64() != 150 {
+		t.Errorf("partner bundle priority %v, want 150", prioritized[0].Priority)
+	}
+	if prioritized[1].Priority != nil {
+		t.Errorf("feed bundle boosted to %v", prioritized[1].Priority)
+	}
+	if bundles[0].Priority != nil {
+		t.Error("prioritize modified the simulated bundles")
+	}
+
+	// once the exclusivity windows are over, the bundles of the other channels compete again
+	now = now.Add(2 * time.Second)
+	if prioritized, _ := c.prioritize(bundles, nil, private); len(prioritized) != len(bundles) {
+		t.Errorf("%d bundles prioritized after the exclusivity windows, want %d", len(prioritized), len(bundles))
+	}
+}
@@ -0,0 +1,110 @@
+package miner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// derivedStatesSize is the number of derived parent states kept
+const derivedStatesSize = 4
+
+var (
+	derivedStateTimer     = metrics.NewRegisteredTimer("miner/parentstate/derive", nil)
+	derivedStateHitMeter  = metrics.NewRegisteredMeter("miner/parentstate/hit", nil)
+	derivedStateFailMeter = metrics.NewRegisteredMeter("miner/parentstate/fail", nil)
+)
+
+// parentStates derives the state of the recent parents the chain did not store, such as the
+// blocks of a side chain, so that blocks can be built on top of a non-canonical parent. The
+// blocks since the closest ancestor with a state are re-executed, up to reexec blocks. The
+// derived states are shared by all workers, a nil instance only builds on the stored states.
+type parentStates struct {
+	reexec uint64
+
+	mu     sync.Mutex // Serialises the derivations, the workers building on a parent derive it once
+	states *lru.Cache[common.Hash, *state.StateDB]
+}
+
+// newParentStates returns the derivation of the parent states, nil if no block is re-executed.
+func newParentStates(reexec uint64) *parentStates {
+	if reexec == 0 {
+		return nil
+	}
+	return &parentStates{
+		reexec: reexec,
+		states: lru.NewCache[common.Hash, *state.StateDB](derivedStatesSize),
+	}
+}
+
+// stateAt returns the state of the parent, derived by re-executing its ancestors if the chain
+// did not store it.
+func (p *parentStates) stateAt(chain *core.BlockChain, chainConfig *params.ChainConfig, parent *types.Header) (*state.StateDB, error) {
+	statedb, err := chain.StateAt(parent.Root)
+	if err == nil || p == nil {
+		return statedb, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if statedb, ok := p.states.Get(parent.Root); ok {
+		derivedStateHitMeter.Mark(1)
+		return statedb.Copy(), nil
+	}
+	statedb, err = p.derive(chain, chainConfig, parent)
+	if err != nil {
+		derivedStateFailMeter.Mark(1)
+		return nil, err
+	}
+	p.states.Add(parent.Root, statedb)
+	return statedb.Copy(), nil
+}
+
+// derive re-executes the blocks from the closest ancestor of the parent with a state.
+func (p *parentStates) derive(chain *core.BlockChain, chainConfig *params.ChainConfig, parent *types.Header) (*state.StateDB, error) {
+	defer derivedStateTimer.UpdateSince(time.Now())
+
+	var (
+		blocks []*types.Block
+		header = parent
+	)
+	for !chain.HasState(header.Root) {
+		if uint64(len(blocks)) >= p.reexec {
+			return nil, fmt.Errorf("no state within %d blocks of parent %s", p.reexec, parent.Hash())
+		}
+		block := chain.GetBlock(header.Hash(), header.Number.Uint64())
+		if block == nil {
+			return nil, fmt.Errorf("block %s not found", header.Hash())
+		}
+		blocks = append(blocks, block)
+		if header = chain.GetHeader(header.ParentHash, header.Number.Uint64()-1); header == nil {
+			return nil, fmt.Errorf("parent of block %s not found", block.Hash())
+		}
+	}
+	statedb, err := chain.StateAt(header.Root)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(blocks) - 1; i >= 0; i-- {
+		block := blocks[i]
+		if _, _, _, err := chain.Processor().Process(block, statedb, vm.Config{}); err != nil {
+			return nil, fmt.Errorf("re-executing block %d %s: %w", block.NumberU64(), block.Hash(), err)
+		}
+		if root := statedb.IntermediateRoot(chainConfig.IsEIP158(block.Number())); root != block.Root() {
+			return nil, fmt.Errorf("re-executed block %d %s has root %s, want %s", block.NumberU64(), block.Hash(), root, block.Root())
+		}
+	}
+	log.Debug("Derived the parent state", "number", parent.Number, "hash", parent.Hash(), "reexec", len(blocks))
+	return statedb, nil
+}
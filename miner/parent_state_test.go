@@ -0,0 +1,68 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestParentStates(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	w, b := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), db, defaultGenesisAlloc, 0)
+	defer w.close()
+
+	_, canonical, _ := core.GenerateChainWithGenesis(b.genesis, w.engine, 3, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(testAddress1)
+	})
+	if _, err := w.chain.InsertChain(canonical); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// the shorter side chain is stored without its state, the blockchain would execute it on import
+	signer := types.LatestSigner(ethashChainConfig)
+	_, side, _ := core.GenerateChainWithGenesis(b.genesis, w.engine, 2, func(i int, gen *core.BlockGen) {
+		gen.SetCoinbase(testUserAddress)
+		gen.AddTx(types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    gen.TxNonce(testBankAddress),
+			To:       &testUserAddress,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: new(big.Int).Mul(gen.BaseFee(), big.NewInt(2)),
+		}))
+	})
+	for _, block := range side {
+		rawdb.WriteBlock(db, block)
+	}
+	parent := side[1].Header()
+	if w.chain.HasState(parent.Root) {
+		t.Fatal("side chain stored with its state")
+	}
+
+	var disabled *parentStates
+	if _, err := disabled.stateAt(w.chain, w.chainConfig, parent); err == nil {
+		t.Error("state of a side chain parent found without re-execution")
+	}
+	if _, err := newParentStates(1).stateAt(w.chain, w.chainConfig, parent); err == nil {
+		t.Error("state derived re-executing more blocks than allowed")
+	}
+
+	states := newParentStates(8)
+	for i := 0; i < 2; i++ {
+		statedb, err := states.stateAt(w.chain, w.chainConfig, parent)
+		if err != nil {
+			t.Fatalf("failed to derive the parent state: %v", err)
+		}
+		if root := statedb.IntermediateRoot(true); root != parent.Root {
+			t.Errorf("derived state root %s, want %s", root, parent.Root)
+		}
+		// the states handed out are copies of the cached one
+		statedb.SetBalance(testUserAddress, new(big.Int))
+	}
+	if states.states.Len() != 1 {
+		t.Errorf("%d derived states cached, want 1", states.states.Len())
+	}
+}
@@ -111,12 +111,15 @@ func (payload *Payload) update(block *types.Block, fees *big.Int, elapsed time.D
 	payload.cond.Broadcast() // fire signal for notifying full block
 }
 
-func (payload *Payload) resolveBestFullPayload(payloads []*Payload) {
+// resolveBestFullPayload waits for the payloads of the workers and keeps the full block paying the
+// highest fees. It returns the index of the payload resolved, -1 if none.
+func (payload *Payload) resolveBestFullPayload(payloads []*Payload) int {
+	best := -1
 	payload.lock.Lock()
 	defer payload.lock.Unlock()
 
 	log.Trace("resolving best payload")
-	for _, p := range payloads {
+	for i, p := range payloads {
 		p.lock.Lock()
 
 		if p.full == nil {
@@ -137,6 +140,7 @@ func (payload *Payload) resolveBestFullPayload(payloads []*Payload) {
 			log.Trace("best payload updated", "id", p.id, "blockHash", p.full.Hash())
 			payload.full = p.full
 			payload.fullFees = p.fullFees
+			best = i
 		}
 		p.lock.Unlock()
 	}
@@ -155,6 +159,7 @@ func (payload *Payload) resolveBestFullPayload(payloads []*Payload) {
 	} else {
 		log.Trace("no payload resolved", "id", payload.id)
 	}
+	return best
 }
 
 func (payload *Payload) Cancel() {
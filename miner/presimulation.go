@@ -0,0 +1,281 @@
+package miner
+
+import (
+	"container/heap"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// preSimulationBatch is the number of bundles a pre-simulation worker takes from the queue at once
+const preSimulationBatch = 16
+
+var (
+	preSimulatedMeter  = metrics.NewRegisteredMeter("miner/bundle/presim/simulated", nil)
+	preSimDroppedMeter = metrics.NewRegisteredMeter("miner/bundle/presim/dropped", nil)
+	preSimQueueGauge   = metrics.NewRegisteredGauge("miner/bundle/presim/queue", nil)
+)
+
+// defaultPreSimulationConfig leaves the pre-simulation disabled.
+var defaultPreSimulationConfig = PreSimulationConfig{
+	QueueSize: 1024,
+}
+
+// PreSimulationConfig configures the simulation of the bundles in the background as they enter
+// the pool, so that the blocks built again on the same parent find most bundles simulated.
+type PreSimulationConfig struct {
+	Workers   int // Goroutines simulating the queued bundles, 0 = disabled
+	QueueSize int // Bundles waiting for a simulation, the bundles of the lowest priority are dropped over it
+}
+
+// preSimItem is a bundle waiting for its pre-simulation.
+type preSimItem struct {
+	bundle   types.MevBundle
+	priority float64 // Reputation score of the searcher
+	seq      uint64  // Arrival order, the older bundles first among equal priorities
+}
+
+// preSimQueue is a heap of bundles, the bundles of the searchers of the highest reputation first.
+type preSimQueue []*preSimItem
+
+func (q preSimQueue) Len() int { return len(q) }
+
+func (q preSimQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q preSimQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *preSimQueue) Push(x interface{}) { *q = append(*q, x.(*preSimItem)) }
+
+func (q *preSimQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return item
+}
+
+// preSimulator simulates the bundles entering the pool in the background, by priority, at the top
+// of the latest block being built. The results go to the bundle cache shared by the workers. The
+// queue is bounded: when it is full the bundles of the lowest priority are dropped, they are
+// simulated when the block is built. A nil instance simulates nothing.
+type preSimulator struct {
+	config PreSimulationConfig
+	worker *worker
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queue  preSimQueue
+	queued map[common.Hash]struct{}
+	seq    uint64
+	target *environment // Top of the latest block being built, nil until a block is built on the head
+	closed bool
+
+	bundleSub event.Subscription
+	headSub   event.Subscription
+	wg        sync.WaitGroup
+}
+
+// newPreSimulator returns the pre-simulator of the config, nil if disabled.
+func newPreSimulator(config PreSimulationConfig) *preSimulator {
+	if config.Workers <= 0 {
+		return nil
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultPreSimulationConfig.QueueSize
+	}
+	p := &preSimulator{
+		config: config,
+		queued: make(map[common.Hash]struct{}),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// start follows the bundles entering the pool and starts the workers simulating them with the
+// settings of the given worker.
+func (p *preSimulator) start(w *worker) {
+	if p == nil {
+		return
+	}
+	p.worker = w
+	bundleCh := make(chan core.NewMevBundlesEvent, 16)
+	headCh := make(chan core.ChainHeadEvent, 1)
+	p.bundleSub = w.eth.TxPool().SubscribeNewMevBundlesEvent(bundleCh)
+	p.headSub = w.chain.SubscribeChainHeadEvent(headCh)
+
+	p.wg.Add(1)
+	go p.loop(bundleCh, headCh)
+	for i := 0; i < p.config.Workers; i++ {
+		p.wg.Add(1)
+		go p.simulateLoop()
+	}
+	log.Info("Started the bundle pre-simulation", "workers", p.config.Workers, "queue", p.config.QueueSize)
+}
+
+func (p *preSimulator) close() {
+	if p == nil || p.worker == nil {
+		return
+	}
+	p.mu.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mu.Unlock()
+
+	p.bundleSub.Unsubscribe()
+	p.headSub.Unsubscribe()
+	p.wg.Wait()
+}
+
+func (p *preSimulator) loop(bundleCh <-chan core.NewMevBundlesEvent, headCh <-chan core.ChainHeadEvent) {
+	defer p.wg.Done()
+	for {
+		select {
+		case ev := <-bundleCh:
+			for _, bundle := range ev.Bundles {
+				p.push(bundle)
+			}
+		case ev := <-headCh:
+			p.headChanged(ev.Block.Hash())
+		case <-p.bundleSub.Err():
+			return
+		case <-p.headSub.Err():
+			return
+		}
+	}
+}
+
+// push queues the bundle, dropping the bundle of the lowest priority if the queue is full.
+func (p *preSimulator) push(bundle types.MevBundle) {
+	priority := p.worker.flashbots.reputation.score(bundle.SigningAddress)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.queued[bundle.Hash]; ok {
+		return
+	}
+	if len(p.queue) >= p.config.QueueSize {
+		lowest := 0
+		for i := range p.queue {
+			if p.queue.Less(lowest, i) {
+				lowest = i
+			}
+		}
+		preSimDroppedMeter.Mark(1)
+		if p.queue[lowest].priority >= priority {
+			return
+		}
+		dropped := heap.Remove(&p.queue, lowest).(*preSimItem)
+		delete(p.queued, dropped.bundle.Hash)
+	}
+	p.seq++
+	heap.Push(&p.queue, &preSimItem{bundle: bundle, priority: priority, seq: p.seq})
+	p.queued[bundle.Hash] = struct{}{}
+	preSimQueueGauge.Update(int64(len(p.queue)))
+	p.cond.Signal()
+}
+
+// setTarget makes the block being built the target of the pre-simulations, env is at the top of
+// the block.
+func (p *preSimulator) setTarget(env *environment) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.target != nil && newBundleCacheKey(p.target.parentRoot, p.target.header) == newBundleCacheKey(env.parentRoot, env.header) {
+		return
+	}
+	target := env.copy()
+	target.decisions = nil
+	target.background = true
+	p.target = target
+	p.cond.Broadcast()
+}
+
+// targetEnv returns a copy of the target of the pre-simulations, nil if no block is built on the head.
+func (p *preSimulator) targetEnv() *environment {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.target == nil {
+		return nil
+	}
+	return p.target.copy()
+}
+
+// headChanged drops the target once the chain advanced past its parent, until a block is built
+// on the new head.
+func (p *preSimulator) headChanged(head common.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.target != nil && p.target.header.ParentHash != head {
+		p.target = nil
+	}
+}
+
+// next waits for bundles to simulate on the target, it returns the environment of the
+// simulations and the bundles, or nil once closed. The bundles invalid for the target block are
+// dropped.
+func (p *preSimulator) next() (*environment, []types.MevBundle) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for {
+		for !p.closed && (p.target == nil || len(p.queue) == 0) {
+			p.cond.Wait()
+		}
+		if p.closed {
+			return nil, nil
+		}
+		var (
+			header = p.target.header
+			batch  []types.MevBundle
+		)
+		for len(p.queue) > 0 && len(batch) < preSimulationBatch {
+			item := heap.Pop(&p.queue).(*preSimItem)
+			delete(p.queued, item.bundle.Hash)
+			bundle := item.bundle
+			if bundle.BlockNumber.Cmp(header.Number) > 0 || bundle.LastBlockNumber().Cmp(header.Number) < 0 ||
+				(bundle.MinTimestamp != 0 && header.Time < bundle.MinTimestamp) ||
+				(bundle.MaxTimestamp != 0 && header.Time > bundle.MaxTimestamp) {
+				continue
+			}
+			batch = append(batch, bundle)
+		}
+		preSimQueueGauge.Update(int64(len(p.queue)))
+		if len(batch) != 0 {
+			return p.target.copy(), batch
+		}
+	}
+}
+
+func (p *preSimulator) simulateLoop() {
+	defer p.wg.Done()
+	for {
+		env, bundles := p.next()
+		if env == nil {
+			return
+		}
+		if _, _, err := p.worker.simulateBundles(env, bundles, nil, nil); err != nil {
+			log.Debug("Failed to pre-simulate bundles", "err", err)
+			continue
+		}
+		preSimulatedMeter.Mark(int64(len(bundles)))
+	}
+}
@@ -0,0 +1,81 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestPreSimulation(t *testing.T) {
+	w, b := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), defaultGenesisAlloc, 0)
+	defer w.close()
+
+	searcher, unreliable := common.Address{0x1}, common.Address{0x2}
+	w.flashbots.reputation = newReputationTracker(ReputationConfig{})
+	for i := 0; i < 4; i++ {
+		w.flashbots.reputation.simulated(unreliable, true)
+	}
+
+	// the queue keeps the bundles of the searchers of the highest reputation
+	p := newPreSimulator(PreSimulationConfig{Workers: 1, QueueSize: 2})
+	p.worker = w
+	p.push(types.MevBundle{Hash: common.Hash{0x1}, SigningAddress: unreliable})
+	p.push(types.MevBundle{Hash: common.Hash{0x2}, SigningAddress: searcher})
+	p.push(types.MevBundle{Hash: common.Hash{0x3}, SigningAddress: searcher})
+	p.push(types.MevBundle{Hash: common.Hash{0x4}, SigningAddress: unreliable})
+	if len(p.queue) != 2 || p.queue[0].bundle.Hash != (common.Hash{0x2}) {
+		t.Fatalf("unexpected queue %v", p.queue)
+	}
+	if _, ok := p.queued[common.Hash{0x1}]; ok {
+		t.Error("bundle of the lowest priority kept over the queue size")
+	}
+
+	// the arriving bundles are simulated at the top of the block being built
+	p = newPreSimulator(PreSimulationConfig{Workers: 2})
+	w.flashbots.preSim = p
+	p.start(w)
+	env, err := w.prepareWork(&generateParams{gasLimit: 30000000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the pool nonce counts the pending transactions of the test backend, not the state of the block
+	nonce := env.state.GetNonce(testBankAddress)
+	p.setTarget(env)
+
+	signer := types.LatestSigner(ethashChainConfig)
+	tx := types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+		Nonce:    nonce,
+		To:       &testUserAddress,
+		Value:    big.NewInt(1000),
+		Gas:      params.TxGas,
+		GasPrice: new(big.Int).Mul(env.header.BaseFee, big.NewInt(2)),
+	})
+	if err := b.txPool.AddMevBundle(types.Transactions{tx}, env.header.Number, nil, types.EmptyUUID, searcher, 0, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	pooled, _ := b.txPool.MevBundles(env.header.Number, env.header.Time)
+	if len(pooled) != 1 {
+		t.Fatalf("%d bundles in the pool, want 1", len(pooled))
+	}
+	cache := w.flashbots.bundleCache.GetBundleCache(env.parentRoot, env.header)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if simmed, ok := cache.GetSimulatedBundle(pooled[0].Hash); ok {
+			if simmed == nil || simmed.TotalGasUsed != params.TxGas {
+				t.Fatalf("unexpected pre-simulation %v", simmed)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("bundle not pre-simulated")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	p.close()
+}
@@ -0,0 +1,175 @@
+package miner
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// profitBreakdownBlocks is the number of built blocks the profit breakdown is kept for
+const profitBreakdownBlocks = 1024
+
+// Kinds of the orders the transactions of a block are attributed to
+const (
+	orderKindBundle  = "bundle"
+	orderKindSBundle = "sbundle"
+	orderKindMempool = "mempool"
+	orderKindBuilder = "builder" // Proposer payment and refunds sent by the builder
+)
+
+// TxProfit is the change of the coinbase balance caused by a transaction of a built block.
+type TxProfit struct {
+	Hash   common.Hash  `json:"hash"`
+	Order  common.Hash  `json:"order"` // Bundle or sbundle the transaction was included with, zero for the other kinds
+	Kind   string       `json:"kind"`
+	Profit *hexutil.Big `json:"profit"`
+}
+
+// OrderProfit is the change of the coinbase balance caused by the transactions of a built block
+// included with an order. The mempool transactions and the builder transactions count as one
+// order each.
+type OrderProfit struct {
+	Order  common.Hash    `json:"order"`
+	Kind   string         `json:"kind"`
+	Txs    hexutil.Uint64 `json:"txs"`
+	Profit *hexutil.Big   `json:"profit"`
+}
+
+// BlockProfitBreakdown attributes the profit of a block built by the node to the transactions
+// and the orders that paid for it.
+type BlockProfitBreakdown struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Profit      *hexutil.Big   `json:"profit"` // Coinbase balance change of the block, payout included
+	Orders      []OrderProfit  `json:"orders"` // Most profitable first
+	Txs         []TxProfit     `json:"txs"`    // In block order
+}
+
+// newBlockProfitBreakdown attributes the coinbase balance change of every transaction of the block,
+// recorded while building, to the bundle or sbundle it was included with.
+func newBlockProfitBreakdown(env *environment, block *types.Block, blockBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle) *BlockProfitBreakdown {
+	type origin struct {
+		order common.Hash
+		kind  string
+	}
+	origins := make(map[common.Hash]origin)
+	for _, bundle := range blockBundles {
+		for _, tx := range bundle.OriginalBundle.Txs {
+			origins[tx.Hash()] = origin{bundle.OriginalBundle.Hash, orderKindBundle}
+		}
+	}
+	for _, sbundle := range usedSbundles {
+		if !sbundle.Success {
+			continue
+		}
+		for _, tx := range getShareBundleTxData(sbundle.Bundle) {
+			origins[tx.hash] = origin{sbundle.Bundle.Hash(), orderKindSBundle}
+		}
+	}
+
+	var (
+		breakdown = &BlockProfitBreakdown{
+			BlockNumber: hexutil.Uint64(block.NumberU64()),
+			BlockHash:   block.Hash(),
+			Txs:         make([]TxProfit, 0, len(env.txs)),
+		}
+		total  = new(big.Int)
+		orders = make(map[origin]*OrderProfit)
+	)
+	for i, tx := range env.txs {
+		o, ok := origins[tx.Hash()]
+		if !ok {
+			o.kind = orderKindMempool
+			if from, err := types.Sender(env.signer, tx); err == nil && from == env.coinbase {
+				o.kind = orderKindBuilder
+			}
+		}
+		profit := new(big.Int)
+		if i < len(env.txProfits) && env.txProfits[i] != nil {
+			profit.Set(env.txProfits[i])
+		}
+		total.Add(total, profit)
+		breakdown.Txs = append(breakdown.Txs, TxProfit{Hash: tx.Hash(), Order: o.order, Kind: o.kind, Profit: (*hexutil.Big)(profit)})
+
+		order, ok := orders[o]
+		if !ok {
+			order = &OrderProfit{Order: o.order, Kind: o.kind, Profit: new(hexutil.Big)}
+			orders[o] = order
+		}
+		order.Txs++
+		order.Profit.ToInt().Add(order.Profit.ToInt(), profit)
+	}
+	breakdown.Profit = (*hexutil.Big)(total)
+	breakdown.Orders = make([]OrderProfit, 0, len(orders))
+	for _, order := range orders {
+		breakdown.Orders = append(breakdown.Orders, *order)
+	}
+	sort.Slice(breakdown.Orders, func(i, j int) bool {
+		a, b := breakdown.Orders[i], breakdown.Orders[j]
+		if c := a.Profit.ToInt().Cmp(b.Profit.ToInt()); c != 0 {
+			return c > 0
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Order.Hex() < b.Order.Hex()
+	})
+	return breakdown
+}
+
+// profit returns the sum of the profit of the orders of the given kind.
+func (b *BlockProfitBreakdown) profit(kind string) *big.Int {
+	profit := new(big.Int)
+	for _, order := range b.Orders {
+		if order.Kind == kind {
+			profit.Add(profit, order.Profit.ToInt())
+		}
+	}
+	return profit
+}
+
+// profitBreakdowns keeps the profit breakdown of the recent blocks built by the workers. The
+// methods are no-ops on a nil instance.
+type profitBreakdowns struct {
+	mu     sync.Mutex
+	blocks map[common.Hash]*BlockProfitBreakdown
+	order  []common.Hash // Built blocks, oldest first
+}
+
+func newProfitBreakdowns() *profitBreakdowns {
+	return &profitBreakdowns{blocks: make(map[common.Hash]*BlockProfitBreakdown)}
+}
+
+// blockBuilt records the breakdown of a built block, forgetting the oldest one over profitBreakdownBlocks.
+func (p *profitBreakdowns) blockBuilt(breakdown *BlockProfitBreakdown) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.blocks[breakdown.BlockHash]; ok {
+		return
+	}
+	p.blocks[breakdown.BlockHash] = breakdown
+	p.order = append(p.order, breakdown.BlockHash)
+	if len(p.order) > profitBreakdownBlocks {
+		delete(p.blocks, p.order[0])
+		p.order = p.order[1:]
+	}
+}
+
+// get returns the breakdown of a recent built block, nil if unknown.
+func (p *profitBreakdowns) get(hash common.Hash) *BlockProfitBreakdown {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.blocks[hash]
+}
@@ -0,0 +1,73 @@
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestBlockProfitBreakdown(t *testing.T) {
+	var (
+		signer         = types.LatestSigner(params.TestChainConfig)
+		searcherKey, _ = crypto.GenerateKey()
+		builderKey, _  = crypto.GenerateKey()
+		builder        = crypto.PubkeyToAddress(builderKey.PublicKey)
+	)
+	newTx := func(key *ecdsa.PrivateKey, nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(key, signer, &types.DynamicFeeTx{ChainID: params.TestChainConfig.ChainID, Nonce: nonce, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1), Gas: 21000, To: &common.Address{1}})
+	}
+	var (
+		bundleTx1 = newTx(searcherKey, 0)
+		bundleTx2 = newTx(searcherKey, 1)
+		sbundleTx = newTx(searcherKey, 2)
+		mempoolTx = newTx(searcherKey, 3)
+		payoutTx  = newTx(builderKey, 0)
+		bundle    = types.SimulatedBundle{OriginalBundle: types.MevBundle{Txs: types.Transactions{bundleTx1, bundleTx2}, Hash: common.Hash{0xb}}}
+		sbundle   = &types.SBundle{Body: []types.BundleBody{{Bundle: &types.SBundle{Body: []types.BundleBody{{Tx: sbundleTx}}}}}}
+	)
+	env := &environment{
+		signer:    signer,
+		coinbase:  builder,
+		header:    &types.Header{Number: big.NewInt(1)},
+		txs:       []*types.Transaction{bundleTx1, bundleTx2, mempoolTx, sbundleTx, payoutTx},
+		txProfits: []*big.Int{big.NewInt(10), big.NewInt(500), big.NewInt(21), big.NewInt(300), big.NewInt(-700)},
+	}
+	block := types.NewBlockWithHeader(env.header)
+
+	breakdown := newBlockProfitBreakdown(env, block, []types.SimulatedBundle{bundle}, []types.UsedSBundle{{Bundle: sbundle, Success: true}})
+	if profit := breakdown.Profit.ToInt().Int64(); profit != 131 {
+		t.Errorf("unexpected block profit %d", profit)
+	}
+	kinds := []string{orderKindBundle, orderKindBundle, orderKindMempool, orderKindSBundle, orderKindBuilder}
+	for i, tx := range breakdown.Txs {
+		if tx.Hash != env.txs[i].Hash() || tx.Kind != kinds[i] {
+			t.Errorf("tx %d: unexpected attribution %v to %s", i, tx.Kind, tx.Order)
+		}
+	}
+	want := []OrderProfit{
+		{Order: bundle.OriginalBundle.Hash, Kind: orderKindBundle, Txs: 2},
+		{Order: sbundle.Hash(), Kind: orderKindSBundle, Txs: 1},
+		{Kind: orderKindMempool, Txs: 1},
+		{Kind: orderKindBuilder, Txs: 1},
+	}
+	profits := []int64{510, 300, 21, -700}
+	if len(breakdown.Orders) != len(want) {
+		t.Fatalf("unexpected orders %+v", breakdown.Orders)
+	}
+	for i, order := range breakdown.Orders {
+		if order.Order != want[i].Order || order.Kind != want[i].Kind || order.Txs != want[i].Txs || order.Profit.ToInt().Int64() != profits[i] {
+			t.Errorf("order %d: unexpected %+v", i, order)
+		}
+	}
+
+	breakdowns := newProfitBreakdowns()
+	breakdowns.blockBuilt(breakdown)
+	if breakdowns.get(block.Hash()) != breakdown || breakdowns.get(common.Hash{}) != nil {
+		t.Error("unexpected recorded breakdown")
+	}
+}
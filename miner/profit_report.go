@@ -0,0 +1,240 @@
+package miner
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	profitReportBlocks = 256 // Number of landed blocks reported with their breakdown
+	profitReportDays   = 30  // Number of days the daily aggregates are kept for
+
+	// profitPendingDepth is the number of blocks after which a built block that did not land is forgotten
+	profitPendingDepth = 64
+	// profitPendingLimit bounds the number of built blocks waiting to land
+	profitPendingLimit = 16384
+)
+
+// BlockProfit is the profit breakdown of a block built by the node.
+type BlockProfit struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Timestamp   hexutil.Uint64 `json:"timestamp"`
+
+	GasFees           *hexutil.Big `json:"gasFees"`           // Priority fees paid to the builder
+	CoinbaseTransfers *hexutil.Big `json:"coinbaseTransfers"` // Direct payments to the builder by bundles
+	Payout            *hexutil.Big `json:"payout"`            // Value paid to the proposer
+	PayoutFee         *hexutil.Big `json:"payoutFee"`         // Base fee of the proposer payment paid by the builder
+	Margin            *hexutil.Big `json:"margin"`            // Profit kept by the builder
+}
+
+// DailyProfit aggregates the profit of the blocks that landed on a day (UTC).
+type DailyProfit struct {
+	Day               string         `json:"day"`
+	Blocks            hexutil.Uint64 `json:"blocks"`
+	GasFees           *hexutil.Big   `json:"gasFees"`
+	CoinbaseTransfers *hexutil.Big   `json:"coinbaseTransfers"`
+	Payout            *hexutil.Big   `json:"payout"`
+	PayoutFee         *hexutil.Big   `json:"payoutFee"`
+	Margin            *hexutil.Big   `json:"margin"`
+}
+
+// ProfitReport is the profit of the recent blocks built by the node that landed on chain.
+type ProfitReport struct {
+	Blocks []BlockProfit `json:"blocks"`
+	Daily  []DailyProfit `json:"daily"`
+}
+
+// newBlockProfit computes the profit breakdown of a finalized block from the environment it was
+// built in. The proposer payment, if any, is the last transaction of the block.
+func newBlockProfit(env *environment, block *types.Block, payout *big.Int) *BlockProfit {
+	gasFees := new(big.Int)
+	for i, tx := range env.txs {
+		if i >= len(env.receipts) {
+			break
+		}
+		tip, err := tx.EffectiveGasTip(env.header.BaseFee)
+		if err != nil {
+			continue
+		}
+		gasFees.Add(gasFees, new(big.Int).Mul(tip, new(big.Int).SetUint64(env.receipts[i].GasUsed)))
+	}
+
+	payoutFee := new(big.Int)
+	if payout.Sign() > 0 && len(env.receipts) > 0 && env.header.BaseFee != nil {
+		payoutFee.Mul(env.header.BaseFee, new(big.Int).SetUint64(env.receipts[len(env.receipts)-1].GasUsed))
+	}
+
+	margin := new(big.Int).Sub(env.profit, payout)
+	margin.Sub(margin, payoutFee)
+
+	return &BlockProfit{
+		BlockNumber:       hexutil.Uint64(block.NumberU64()),
+		BlockHash:         block.Hash(),
+		Timestamp:         hexutil.Uint64(block.Time()),
+		GasFees:           (*hexutil.Big)(gasFees),
+		CoinbaseTransfers: (*hexutil.Big)(new(big.Int).Sub(env.profit, gasFees)),
+		Payout:            (*hexutil.Big)(new(big.Int).Set(payout)),
+		PayoutFee:         (*hexutil.Big)(payoutFee),
+		Margin:            (*hexutil.Big)(margin),
+	}
+}
+
+// profitLedger keeps the profit of the blocks built by the workers until one of them becomes
+// the chain head, and reports the landed ones. The methods are no-ops on a nil ledger.
+type profitLedger struct {
+	mu      sync.Mutex
+	pending map[common.Hash]*BlockProfit
+	landed  []*BlockProfit
+	daily   map[string]*DailyProfit
+}
+
+func newProfitLedger() *profitLedger {
+	return &profitLedger{
+		pending: make(map[common.Hash]*BlockProfit),
+		daily:   make(map[string]*DailyProfit),
+	}
+}
+
+// blockBuilt records the profit of a built block.
+func (l *profitLedger) blockBuilt(profit *BlockProfit) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.pending) >= profitPendingLimit {
+		return
+	}
+	l.pending[profit.BlockHash] = profit
+}
+
+// chainHead moves the profit of the head block into the report if the node built it, and
+// forgets the built blocks too old to land. It returns the profit of the head block the
+// first time it is reported, nil if the node did not build it.
+func (l *profitLedger) chainHead(head *types.Block) *BlockProfit {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	profit, landed := l.pending[head.Hash()]
+	if landed {
+		delete(l.pending, head.Hash())
+		l.landed = append(l.landed, profit)
+		if len(l.landed) > profitReportBlocks {
+			l.landed = l.landed[len(l.landed)-profitReportBlocks:]
+		}
+		l.addDaily(profit)
+	}
+
+	for hash, pending := range l.pending {
+		if uint64(pending.BlockNumber)+profitPendingDepth < head.NumberU64() {
+			delete(l.pending, hash)
+		}
+	}
+	if !landed {
+		return nil
+	}
+	return profit
+}
+
+// reorged removes a landed block that left the canonical chain from the report and the daily
+// aggregates. It returns the profit of the block, nil if it is not reported.
+func (l *profitLedger) reorged(hash common.Hash) *BlockProfit {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, profit := range l.landed {
+		if profit.BlockHash != hash {
+			continue
+		}
+		l.landed = append(l.landed[:i:i], l.landed[i+1:]...)
+		day := time.Unix(int64(profit.Timestamp), 0).UTC().Format("2006-01-02")
+		if daily, ok := l.daily[day]; ok {
+			daily.Blocks--
+			daily.GasFees.ToInt().Sub(daily.GasFees.ToInt(), profit.GasFees.ToInt())
+			daily.CoinbaseTransfers.ToInt().Sub(daily.CoinbaseTransfers.ToInt(), profit.CoinbaseTransfers.ToInt())
+			daily.Payout.ToInt().Sub(daily.Payout.ToInt(), profit.Payout.ToInt())
+			daily.PayoutFee.ToInt().Sub(daily.PayoutFee.ToInt(), profit.PayoutFee.ToInt())
+			daily.Margin.ToInt().Sub(daily.Margin.ToInt(), profit.Margin.ToInt())
+		}
+		return profit
+	}
+	return nil
+}
+
+func (l *profitLedger) addDaily(profit *BlockProfit) {
+	day := time.Unix(int64(profit.Timestamp), 0).UTC().Format("2006-01-02")
+	daily, ok := l.daily[day]
+	if !ok {
+		daily = &DailyProfit{
+			Day:               day,
+			GasFees:           new(hexutil.Big),
+			CoinbaseTransfers: new(hexutil.Big),
+			Payout:            new(hexutil.Big),
+			PayoutFee:         new(hexutil.Big),
+			Margin:            new(hexutil.Big),
+		}
+		l.daily[day] = daily
+	}
+	daily.Blocks++
+	daily.GasFees.ToInt().Add(daily.GasFees.ToInt(), profit.GasFees.ToInt())
+	daily.CoinbaseTransfers.ToInt().Add(daily.CoinbaseTransfers.ToInt(), profit.CoinbaseTransfers.ToInt())
+	daily.Payout.ToInt().Add(daily.Payout.ToInt(), profit.Payout.ToInt())
+	daily.PayoutFee.ToInt().Add(daily.PayoutFee.ToInt(), profit.PayoutFee.ToInt())
+	daily.Margin.ToInt().Add(daily.Margin.ToInt(), profit.Margin.ToInt())
+
+	// keep the most recent days only
+	if len(l.daily) > profitReportDays {
+		days := make([]string, 0, len(l.daily))
+		for d := range l.daily {
+			days = append(days, d)
+		}
+		sort.Strings(days)
+		for _, d := range days[:len(days)-profitReportDays] {
+			delete(l.daily, d)
+		}
+	}
+}
+
+// report returns the landed blocks, most recent first, and the daily aggregates, most recent first.
+func (l *profitLedger) report() ProfitReport {
+	if l == nil {
+		return ProfitReport{Blocks: []BlockProfit{}, Daily: []DailyProfit{}}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	report := ProfitReport{
+		Blocks: make([]BlockProfit, 0, len(l.landed)),
+		Daily:  make([]DailyProfit, 0, len(l.daily)),
+	}
+	for i := len(l.landed) - 1; i >= 0; i-- {
+		report.Blocks = append(report.Blocks, *l.landed[i])
+	}
+	for _, daily := range l.daily {
+		report.Daily = append(report.Daily, DailyProfit{
+			Day:               daily.Day,
+			Blocks:            daily.Blocks,
+			GasFees:           (*hexutil.Big)(new(big.Int).Set(daily.GasFees.ToInt())),
+			CoinbaseTransfers: (*hexutil.Big)(new(big.Int).Set(daily.CoinbaseTransfers.ToInt())),
+			Payout:            (*hexutil.Big)(new(big.Int).Set(daily.Payout.ToInt())),
+			PayoutFee:         (*hexutil.Big)(new(big.Int).Set(daily.PayoutFee.ToInt())),
+			Margin:            (*hexutil.Big)(new(big.Int).Set(daily.Margin.ToInt())),
+		})
+	}
+	sort.Slice(report.Daily, func(i, j int) bool { return report.Daily[i].Day > report.Daily[j].Day })
+	return report
+}
@@ -0,0 +1,103 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestProfitLedger(t *testing.T) {
+	newProfit := func(number, timestamp uint64, extra byte, margin int64) (*types.Block, *BlockProfit) {
+		block := types.NewBlockWithHeader(&types.Header{Number: new(big.Int).SetUint64(number), Time: timestamp, Extra: []byte{extra}})
+		return block, &BlockProfit{
+			BlockNumber:       hexutil.Uint64(number),
+			BlockHash:         block.Hash(),
+			Timestamp:         hexutil.Uint64(timestamp),
+			GasFees:           (*hexutil.Big)(big.NewInt(10)),
+			CoinbaseTransfers: (*hexutil.Big)(big.NewInt(20)),
+			Payout:            (*hexutil.Big)(big.NewInt(25)),
+			PayoutFee:         (*hexutil.Big)(big.NewInt(1)),
+			Margin:            (*hexutil.Big)(big.NewInt(margin)),
+		}
+	}
+
+	ledger := newProfitLedger()
+	won1, profit1 := newProfit(1, 0, 0, 4)
+	lost1, profitLost := newProfit(1, 0, 1, 100)
+	won2, profit2 := newProfit(2, 12, 0, 4)
+	nextDay, profit3 := newProfit(3, 86400, 0, 2)
+	ledger.blockBuilt(profit1)
+	ledger.blockBuilt(profitLost)
+	ledger.blockBuilt(profit2)
+	ledger.blockBuilt(profit3)
+
+	if landed := ledger.chainHead(won1); landed != profit1 {
+		t.Fatalf("unexpected landed block %v", landed)
+	}
+	if landed := ledger.chainHead(won1); landed != nil { // the head is reported by every worker
+		t.Fatalf("landed block reported twice")
+	}
+	ledger.chainHead(won2)
+	ledger.chainHead(nextDay)
+
+	report := ledger.report()
+	if len(report.Blocks) != 3 {
+		t.Fatalf("unexpected number of landed blocks %d", len(report.Blocks))
+	}
+	for i, hash := range []common.Hash{nextDay.Hash(), won2.Hash(), won1.Hash()} {
+		if report.Blocks[i].BlockHash != hash {
+			t.Errorf("unexpected block %d: %v", i, report.Blocks[i].BlockHash)
+		}
+	}
+	if len(report.Daily) != 2 {
+		t.Fatalf("unexpected number of days %d", len(report.Daily))
+	}
+	if day := report.Daily[0]; day.Day != "1970-01-02" || day.Blocks != 1 || day.Margin.ToInt().Int64() != 2 {
+		t.Errorf("unexpected daily profit %+v", day)
+	}
+	if day := report.Daily[1]; day.Day != "1970-01-01" || day.Blocks != 2 || day.Margin.ToInt().Int64() != 8 || day.Payout.ToInt().Int64() != 50 {
+		t.Errorf("unexpected daily profit %+v", day)
+	}
+
+	// blocks that did not land are forgotten once the chain moved past them
+	if _, ok := ledger.pending[lost1.Hash()]; !ok {
+		t.Fatal("pending block forgotten too early")
+	}
+	ledger.chainHead(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(profitPendingDepth + 2)}))
+	if len(ledger.pending) != 0 {
+		t.Fatalf("unexpected pending blocks %d", len(ledger.pending))
+	}
+}
+
+func TestNewBlockProfit(t *testing.T) {
+	var (
+		baseFee = big.NewInt(10)
+		to      = common.Address{1}
+		tx      = types.NewTx(&types.DynamicFeeTx{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(100), Gas: 21000, To: &to})
+		payout  = types.NewTx(&types.DynamicFeeTx{GasFeeCap: baseFee, Gas: 21000, To: &to, Value: big.NewInt(1000)})
+	)
+	env := &environment{
+		header:   &types.Header{Number: big.NewInt(1), BaseFee: baseFee},
+		profit:   big.NewInt(2*21000 + 500_000),
+		txs:      []*types.Transaction{tx, payout},
+		receipts: []*types.Receipt{{GasUsed: 21000}, {GasUsed: 21000}},
+	}
+	block := types.NewBlockWithHeader(env.header)
+
+	profit := newBlockProfit(env, block, big.NewInt(1000))
+	if fees := profit.GasFees.ToInt().Int64(); fees != 2*21000 {
+		t.Errorf("unexpected gas fees %d", fees)
+	}
+	if transfers := profit.CoinbaseTransfers.ToInt().Int64(); transfers != 500_000 {
+		t.Errorf("unexpected coinbase transfers %d", transfers)
+	}
+	if fee := profit.PayoutFee.ToInt().Int64(); fee != 10*21000 {
+		t.Errorf("unexpected payout fee %d", fee)
+	}
+	if margin := profit.Margin.ToInt().Int64(); margin != 2*21000+500_000-1000-10*21000 {
+		t.Errorf("unexpected margin %d", margin)
+	}
+}
@@ -0,0 +1,87 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// verifyProposerPayment is the validation pass of a sealed block paying the proposer. It checks the
+// last transaction of the block is the payment of the claimed bid from the builder wallet to the fee
+// recipient, that the fee recipient balance grew by the bid, and that the block is still valid with
+// the payment appended. It returns the bid value.
+func (w *worker) verifyProposerPayment(env *environment, block *types.Block, feeRecipient common.Address) (*big.Int, error) {
+	w.mu.RLock()
+	builder := w.coinbase
+	w.mu.RUnlock()
+
+	bid, err := checkProposerPayment(env, block, builder, feeRecipient)
+	if err == nil {
+		err = w.checkPaidBlock(env, block, feeRecipient, bid)
+	}
+	if err != nil {
+		if metrics.EnabledBuilder {
+			invalidPaymentMeter.Mark(1)
+		}
+		log.Error("Invalid proposer payment", "number", block.Number(), "hash", block.Hash(), "feeRecipient", feeRecipient, "err", err)
+		return nil, err
+	}
+	return bid, nil
+}
+
+// checkProposerPayment checks the last transaction of the block is a successful payment from the
+// builder to the fee recipient, returning its value.
+func checkProposerPayment(env *environment, block *types.Block, builder, feeRecipient common.Address) (*big.Int, error) {
+	txs := block.Transactions()
+	if len(txs) == 0 {
+		return nil, errors.New("no proposer payment tx")
+	} else if len(env.receipts) != len(txs) {
+		return nil, fmt.Errorf("%d receipts for %d transactions", len(env.receipts), len(txs))
+	}
+
+	payment := txs[len(txs)-1]
+	receipt := env.receipts[len(env.receipts)-1]
+	if receipt.TxHash != payment.Hash() || receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, errors.New("last transaction is not proposer payment")
+	}
+	if to := payment.To(); to == nil || *to != feeRecipient {
+		return nil, errors.New("last transaction is not to the proposer")
+	}
+	sender, err := types.Sender(env.signer, payment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proposer payment signature: %w", err)
+	}
+	if sender != builder {
+		return nil, fmt.Errorf("proposer payment sent by %s, not the builder %s", sender, builder)
+	}
+	return new(big.Int).Set(payment.Value()), nil
+}
+
+// checkPaidBlock checks the fee recipient balance grew by at least the bid over the block, a fee
+// recipient contract may not forward the payment, and the block gas, receipts and state root are
+// consistent with the state the block was built in.
+func (w *worker) checkPaidBlock(env *environment, block *types.Block, feeRecipient common.Address, bid *big.Int) error {
+	parent := w.chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return errors.New("parent not found")
+	}
+	parentState, err := w.flashbots.parentStates.stateAt(w.chain, w.chainConfig, parent)
+	if err != nil {
+		return err
+	}
+	received := new(big.Int).Sub(env.state.GetBalance(feeRecipient), parentState.GetBalance(feeRecipient))
+	if received.Cmp(bid) < 0 {
+		return fmt.Errorf("fee recipient received %v, less than the bid %v", received, bid)
+	}
+
+	if block.GasUsed() > block.GasLimit() {
+		return fmt.Errorf("gas used %d over the gas limit %d", block.GasUsed(), block.GasLimit())
+	}
+	usedGas := env.receipts[len(env.receipts)-1].CumulativeGasUsed
+	return w.chain.Validator().ValidateState(block, env.state, env.receipts, usedGas)
+}
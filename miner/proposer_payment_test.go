@@ -0,0 +1,40 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCheckProposerPayment(t *testing.T) {
+	statedb, chData, signers := genTestSetup(GasLimit)
+	builder, feeRecipient := signers.addresses[0], signers.addresses[1]
+
+	env := newEnvironment(chData, statedb, builder, GasLimit, big.NewInt(1))
+	if _, err := checkProposerPayment(env, types.NewBlockWithHeader(env.header), builder, feeRecipient); err == nil {
+		t.Error("block without payment accepted")
+	}
+
+	envDiff := newEnvironmentDiff(env)
+	payment := signers.signTx(0, 21000, big.NewInt(0), big.NewInt(1), feeRecipient, big.NewInt(1000), nil)
+	if _, _, err := envDiff.commitTx(payment, chData); err != nil {
+		t.Fatal("can't commit payment:", err)
+	}
+	envDiff.applyToBaseEnv()
+	block := types.NewBlockWithHeader(env.header).WithBody(env.txs, nil)
+
+	bid, err := checkProposerPayment(env, block, builder, feeRecipient)
+	if err != nil {
+		t.Fatal("valid payment rejected:", err)
+	}
+	if bid.Int64() != 1000 {
+		t.Errorf("unexpected bid %v, want 1000", bid)
+	}
+	if _, err := checkProposerPayment(env, block, builder, signers.addresses[2]); err == nil {
+		t.Error("payment to another fee recipient accepted")
+	}
+	if _, err := checkProposerPayment(env, block, signers.addresses[2], feeRecipient); err == nil {
+		t.Error("payment from another wallet than the builder accepted")
+	}
+}
@@ -0,0 +1,185 @@
+package miner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// reorgReportLimit is the number of reorg reports kept
+const reorgReportLimit = 64
+
+// ReorgedBundle is a bundle that lost its inclusion when a block built by the node was reorged out.
+type ReorgedBundle struct {
+	BundleHash common.Hash    `json:"bundleHash"`
+	Searcher   common.Address `json:"searcher"`
+	SBundle    bool           `json:"sbundle,omitempty"`
+	// CoinbasePayment is the payment of the bundle to the builder that was reverted with the block
+	CoinbasePayment *hexutil.Big `json:"coinbasePayment,omitempty"`
+}
+
+// ReorgReport describes a block built by the node that landed on chain and was reorged out.
+type ReorgReport struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	DetectedAt  time.Time      `json:"detectedAt"`
+	// Profit is the profit booked when the block landed, removed from the profit report.
+	// The proposer payout and builder margin must be reconciled with the block that replaced it.
+	Profit  *BlockProfit    `json:"profit,omitempty"`
+	Bundles []ReorgedBundle `json:"bundles"`
+}
+
+// BundleNotification returns the report without the block profit, restricted to the bundles of
+// the searcher if one is given. It returns nil if no bundle is left.
+func (r *ReorgReport) BundleNotification(searcher *common.Address) *ReorgReport {
+	report := &ReorgReport{
+		BlockNumber: r.BlockNumber,
+		BlockHash:   r.BlockHash,
+		DetectedAt:  r.DetectedAt,
+		Bundles:     make([]ReorgedBundle, 0),
+	}
+	for _, bundle := range r.Bundles {
+		if searcher == nil || bundle.Searcher == *searcher {
+			report.Bundles = append(report.Bundles, bundle)
+		}
+	}
+	if len(report.Bundles) == 0 {
+		return nil
+	}
+	return report
+}
+
+// builtBlockBundles are the bundles included in a block built by the node.
+type builtBlockBundles struct {
+	number  uint64
+	bundles []ReorgedBundle
+}
+
+// reorgTracker follows the blocks built by the node until they are too deep to be reorged out,
+// and reports the bundles and profit lost when one of them leaves the canonical chain. The
+// methods are no-ops on a nil tracker.
+type reorgTracker struct {
+	mu      sync.Mutex
+	pending map[common.Hash]*builtBlockBundles // Built blocks waiting to land
+	landed  map[common.Hash]*builtBlockBundles // Landed blocks that can still be reorged out
+	reports []ReorgReport
+
+	feed event.Feed
+}
+
+func newReorgTracker() *reorgTracker {
+	return &reorgTracker{
+		pending: make(map[common.Hash]*builtBlockBundles),
+		landed:  make(map[common.Hash]*builtBlockBundles),
+	}
+}
+
+// blockBuilt records the bundles and successful sbundles included in a built block.
+func (t *reorgTracker) blockBuilt(block *types.Block, bundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle) {
+	if t == nil {
+		return
+	}
+	built := &builtBlockBundles{number: block.NumberU64(), bundles: make([]ReorgedBundle, 0, len(bundles)+len(usedSbundles))}
+	for _, bundle := range bundles {
+		reorged := ReorgedBundle{BundleHash: bundle.OriginalBundle.Hash, Searcher: bundle.OriginalBundle.SigningAddress}
+		if bundle.EthSentToCoinbase != nil && bundle.EthSentToCoinbase.Sign() > 0 {
+			reorged.CoinbasePayment = (*hexutil.Big)(bundle.EthSentToCoinbase)
+		}
+		built.bundles = append(built.bundles, reorged)
+	}
+	for _, sbundle := range usedSbundles {
+		if sbundle.Success {
+			built.bundles = append(built.bundles, ReorgedBundle{BundleHash: sbundle.Bundle.Hash(), SBundle: true})
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.pending) >= profitPendingLimit {
+		return
+	}
+	t.pending[block.Hash()] = built
+}
+
+// chainHead marks the head block as landed if the node built it, and forgets the blocks too
+// old to land or to be reorged out.
+func (t *reorgTracker) chainHead(head *types.Block) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if built, ok := t.pending[head.Hash()]; ok {
+		delete(t.pending, head.Hash())
+		t.landed[head.Hash()] = built
+	}
+	for hash, built := range t.pending {
+		if built.number+profitPendingDepth < head.NumberU64() {
+			delete(t.pending, hash)
+		}
+	}
+	for hash, built := range t.landed {
+		if built.number+profitPendingDepth < head.NumberU64() {
+			delete(t.landed, hash)
+		}
+	}
+}
+
+// chainSide reports a landed block removed from the canonical chain, and removes its profit
+// from the ledger. It returns nil if the block is not a landed block built by the node.
+func (t *reorgTracker) chainSide(block *types.Block, profits *profitLedger) *ReorgReport {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	built, ok := t.landed[block.Hash()]
+	if !ok {
+		t.mu.Unlock()
+		return nil
+	}
+	delete(t.landed, block.Hash())
+
+	report := ReorgReport{
+		BlockNumber: hexutil.Uint64(block.NumberU64()),
+		BlockHash:   block.Hash(),
+		DetectedAt:  time.Now(),
+		Profit:      profits.reorged(block.Hash()),
+		Bundles:     built.bundles,
+	}
+	t.reports = append(t.reports, report)
+	if len(t.reports) > reorgReportLimit {
+		t.reports = t.reports[len(t.reports)-reorgReportLimit:]
+	}
+	t.mu.Unlock()
+
+	log.Warn("Built block reorged out", "number", block.NumberU64(), "hash", block.Hash(), "bundles", len(built.bundles))
+	t.feed.Send(report)
+	return &report
+}
+
+// recent returns the recent reorg reports, most recent first.
+func (t *reorgTracker) recent() []ReorgReport {
+	if t == nil {
+		return []ReorgReport{}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reports := make([]ReorgReport, 0, len(t.reports))
+	for i := len(t.reports) - 1; i >= 0; i-- {
+		reports = append(reports, t.reports[i])
+	}
+	return reports
+}
+
+// subscribe delivers the reorg reports to the channel.
+func (t *reorgTracker) subscribe(ch chan<- ReorgReport) event.Subscription {
+	return t.feed.Subscribe(ch)
+}
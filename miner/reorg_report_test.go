@@ -0,0 +1,85 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestReorgTracker(t *testing.T) {
+	var (
+		alice = common.Address{0xa}
+		bob   = common.Address{0xb}
+	)
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10), Time: 120})
+	replacement := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10), Time: 120, Extra: []byte{1}})
+	bundles := []types.SimulatedBundle{
+		{OriginalBundle: types.MevBundle{Hash: common.Hash{1}, SigningAddress: alice}, EthSentToCoinbase: big.NewInt(100)},
+		{OriginalBundle: types.MevBundle{Hash: common.Hash{2}, SigningAddress: bob}, EthSentToCoinbase: big.NewInt(0)},
+	}
+
+	profits := newProfitLedger()
+	profits.blockBuilt(&BlockProfit{
+		BlockNumber:       10,
+		BlockHash:         block.Hash(),
+		Timestamp:         120,
+		GasFees:           (*hexutil.Big)(big.NewInt(10)),
+		CoinbaseTransfers: (*hexutil.Big)(big.NewInt(100)),
+		Payout:            (*hexutil.Big)(big.NewInt(100)),
+		PayoutFee:         (*hexutil.Big)(big.NewInt(1)),
+		Margin:            (*hexutil.Big)(big.NewInt(9)),
+	})
+	tracker := newReorgTracker()
+	tracker.blockBuilt(block, bundles, nil)
+
+	// blocks that did not land are not reported
+	if report := tracker.chainSide(block, profits); report != nil {
+		t.Fatalf("unexpected report for a block that did not land %+v", report)
+	}
+
+	reports := make(chan ReorgReport, 1)
+	sub := tracker.subscribe(reports)
+	defer sub.Unsubscribe()
+
+	profits.chainHead(block)
+	tracker.chainHead(block)
+	tracker.chainHead(block) // the head is reported by every worker
+	tracker.chainHead(replacement)
+
+	report := tracker.chainSide(block, profits)
+	if report == nil {
+		t.Fatalf("missing reorg report")
+	}
+	if report.BlockHash != block.Hash() || len(report.Bundles) != 2 || report.Bundles[0].CoinbasePayment.ToInt().Int64() != 100 || report.Bundles[1].CoinbasePayment != nil {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if report.Profit == nil || report.Profit.Margin.ToInt().Int64() != 9 {
+		t.Errorf("unexpected reorged profit %+v", report.Profit)
+	}
+	if profitReport := profits.report(); len(profitReport.Blocks) != 0 || profitReport.Daily[0].Blocks != 0 || profitReport.Daily[0].Margin.ToInt().Sign() != 0 {
+		t.Errorf("reorged block not removed from the profit report %+v", profitReport)
+	}
+	if received := <-reports; received.BlockHash != block.Hash() {
+		t.Errorf("unexpected notified report %+v", received)
+	}
+	if report := tracker.chainSide(block, profits); report != nil { // the side event is received by every worker
+		t.Fatalf("reorg reported twice")
+	}
+	if recent := tracker.recent(); len(recent) != 1 {
+		t.Fatalf("unexpected number of reports %d", len(recent))
+	}
+
+	notification := report.BundleNotification(&alice)
+	if notification == nil || len(notification.Bundles) != 1 || notification.Bundles[0].Searcher != alice || notification.Profit != nil {
+		t.Errorf("unexpected searcher notification %+v", notification)
+	}
+	if notification := report.BundleNotification(&common.Address{0xc}); notification != nil {
+		t.Errorf("unexpected notification for a searcher without bundles %+v", notification)
+	}
+	if notification := report.BundleNotification(nil); notification == nil || len(notification.Bundles) != 2 {
+		t.Errorf("unexpected notification %+v", notification)
+	}
+}
@@ -0,0 +1,199 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/builder/buildtrace"
+	"github.com/ethereum/go-ethereum/builder/decisionlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var errReplayMevGeth = errors.New("build traces cannot be replayed with the mev-geth algorithm")
+
+// ReplayStep is a commit attempt of the traced round next to the one of the replay at the same
+// index, either is nil if the other has more attempts.
+type ReplayStep struct {
+	Index    int              `json:"index"`
+	Traced   *buildtrace.Step `json:"traced,omitempty"`
+	Replayed *buildtrace.Step `json:"replayed,omitempty"`
+	Match    bool             `json:"match"`
+}
+
+// ReplayFailure is a traced bundle whose simulation failed in the replay.
+type ReplayFailure struct {
+	Hash  common.Hash `json:"hash"`
+	Error string      `json:"error"`
+}
+
+// ReplayReport compares a round of a build trace with its replay.
+type ReplayReport struct {
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+	BlockHash       common.Hash    `json:"blockHash"` // Hash of the traced block
+	TracedAlgorithm string         `json:"tracedAlgorithm"`
+	Algorithm       string         `json:"algorithm"`
+
+	TracedProfit   *hexutil.Big `json:"tracedProfit"`
+	ReplayedProfit *hexutil.Big `json:"replayedProfit"`
+	// Divergence is the index of the first step of the replay differing from the trace, -1 if
+	// the replay took the same steps
+	Divergence int          `json:"divergence"`
+	Steps      []ReplayStep `json:"steps"`
+
+	Candidates []decisionlog.Candidate `json:"candidates"` // Ranking of the candidates of the replay and their outcome
+	Failed     []ReplayFailure         `json:"failed,omitempty"`
+	// SBundles is the number of traced sbundles, which are not replayed
+	SBundles int `json:"sbundles,omitempty"`
+}
+
+// ReplayRound builds the block of a traced round again on top of the state of its parent in the
+// chain, with the algorithm of the round if algo is empty, and compares the steps taken with the
+// traced ones. The bundles are simulated again, with the priority they were traced with.
+func ReplayRound(chain *core.BlockChain, round *buildtrace.Round, algo string) (*ReplayReport, error) {
+	config := DefaultConfig
+	w := &worker{
+		config:      &config,
+		chainConfig: chain.Config(),
+		engine:      chain.Engine(),
+		chain:       chain,
+		flashbots:   &flashbotsData{},
+	}
+	return w.replayRound(round, algo)
+}
+
+func (w *worker) replayRound(round *buildtrace.Round, algo string) (*ReplayReport, error) {
+	if algo == "" {
+		algo = round.Algorithm
+	}
+	algoType, err := AlgoTypeFlagToEnum(algo)
+	if err != nil {
+		return nil, err
+	}
+	if algoType == ALGO_MEV_GETH {
+		return nil, errReplayMevGeth
+	}
+	number := round.Number()
+	if number == 0 {
+		return nil, errors.New("round without block header")
+	}
+	parent := w.chain.GetHeader(round.ParentHash, number-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %s of block %d not found", round.ParentHash, number)
+	}
+	header := types.CopyHeader(round.Header)
+	env, err := w.makeEnv(parent, header, header.Coinbase)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d unavailable: %w", parent.Number.Uint64(), err)
+	}
+	defer env.discard()
+
+	report := &ReplayReport{
+		BlockNumber:     hexutil.Uint64(number),
+		BlockHash:       round.BlockHash,
+		TracedAlgorithm: round.Algorithm,
+		Algorithm:       algoType.String(),
+		TracedProfit:    round.Profit,
+		SBundles:        len(round.SBundles),
+	}
+
+	simBundles := make([]types.SimulatedBundle, 0, len(round.Bundles))
+	for _, traced := range round.Bundles {
+		bundle, err := replayBundle(traced, header.Number)
+		if err == nil {
+			gasPool := new(core.GasPool).AddGas(header.GasLimit)
+			var simmed types.SimulatedBundle
+			if simmed, err = w.computeBundleGas(env, bundle, env.state.Copy(), gasPool, nil, 0); err == nil {
+				if traced.Priority != nil {
+					simmed.Priority = new(big.Int).Set(traced.Priority.ToInt())
+				}
+				simBundles = append(simBundles, simmed)
+				continue
+			}
+		}
+		report.Failed = append(report.Failed, ReplayFailure{Hash: traced.Hash, Error: err.Error()})
+	}
+	pending := make(map[common.Address]types.Transactions)
+	for i, encoded := range round.Mempool {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(encoded); err != nil {
+			return nil, fmt.Errorf("invalid mempool tx %d: %w", i, err)
+		}
+		from, err := types.Sender(env.signer, tx)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mempool tx %s: %w", tx.Hash(), err)
+		}
+		pending[from] = append(pending[from], tx)
+	}
+
+	env.decisions = newDecisionRecorder(algoType, simBundles, nil)
+	env.decisions.trace = &buildtrace.Round{}
+	builder, err := w.newBlockBuilder(algoType, env, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	built, _, _ := builder.buildBlock(simBundles, nil, pending)
+	report.ReplayedProfit = (*hexutil.Big)(new(big.Int).Set(built.profit))
+
+	replayed := env.decisions.trace.Steps
+	report.Divergence = -1
+	for i := 0; i < len(round.Steps) || i < len(replayed); i++ {
+		step := ReplayStep{Index: i}
+		if i < len(round.Steps) {
+			step.Traced = &round.Steps[i]
+		}
+		if i < len(replayed) {
+			step.Replayed = &replayed[i]
+		}
+		step.Match = sameStep(step.Traced, step.Replayed)
+		if !step.Match && report.Divergence < 0 {
+			report.Divergence = i
+		}
+		report.Steps = append(report.Steps, step)
+	}
+	report.Candidates = env.decisions.candidates
+	for i := range report.Candidates {
+		report.Candidates[i].Included = report.Candidates[i].Reason == decisionlog.ReasonIncluded
+	}
+	return report, nil
+}
+
+// replayBundle decodes a traced bundle targeting the given block.
+func replayBundle(traced buildtrace.Bundle, number *big.Int) (types.MevBundle, error) {
+	txs := make(types.Transactions, 0, len(traced.Txs))
+	for i, encoded := range traced.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(encoded); err != nil {
+			return types.MevBundle{}, fmt.Errorf("invalid tx %d: %w", i, err)
+		}
+		txs = append(txs, tx)
+	}
+	if len(txs) == 0 {
+		return types.MevBundle{}, errors.New("bundle without txs")
+	}
+	return types.MevBundle{
+		Txs:               txs,
+		BlockNumber:       new(big.Int).Set(number),
+		RevertingTxHashes: traced.RevertingTxHashes,
+		SigningAddress:    traced.SigningAddress,
+		Hash:              traced.Hash,
+	}, nil
+}
+
+// sameStep reports whether both steps committed or rejected the same candidate with the same
+// outcome and block profit.
+func sameStep(a, b *buildtrace.Step) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Hash != b.Hash || a.Reason != b.Reason {
+		return false
+	}
+	if a.BlockProfit == nil || b.BlockProfit == nil {
+		return a.BlockProfit == b.BlockProfit
+	}
+	return a.BlockProfit.ToInt().Cmp(b.BlockProfit.ToInt()) == 0
+}
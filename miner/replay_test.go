@@ -0,0 +1,118 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/builder/buildtrace"
+	"github.com/ethereum/go-ethereum/builder/decisionlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestBuildTraceReplay(t *testing.T) {
+	w, _ := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), defaultGenesisAlloc, 0)
+	defer w.close()
+
+	parent := w.chain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     big.NewInt(1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + 12,
+		Coinbase:   testAddress1,
+		BaseFee:    misc.CalcBaseFee(ethashChainConfig, parent),
+	}
+	signer := types.LatestSigner(ethashChainConfig)
+	transfer := func(nonce uint64) *types.Transaction {
+		return types.MustSignNewTx(testBankKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			To:       &testUserAddress,
+			Value:    big.NewInt(1000),
+			Gas:      params.TxGas,
+			GasPrice: new(big.Int).Mul(header.BaseFee, big.NewInt(2)),
+		})
+	}
+	bundle := types.SimulatedBundle{
+		OriginalBundle: types.MevBundle{Txs: types.Transactions{transfer(0)}, Hash: common.Hash{1}},
+		MevGasPrice:    big.NewInt(1),
+	}
+	pending := map[common.Address]types.Transactions{testBankAddress: {transfer(1)}}
+
+	// the round is traced with its inputs and commit attempts
+	path := filepath.Join(t.TempDir(), "trace.json")
+	trace, err := buildtrace.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open build trace: %v", err)
+	}
+	buildtrace.SetDefault(trace)
+	defer buildtrace.SetDefault(nil)
+
+	recorder := newDecisionRecorder(ALGO_GREEDY, []types.SimulatedBundle{bundle}, nil)
+	recorder.traceInputs(header, []types.SimulatedBundle{bundle}, nil, pending)
+	recorder.rejected(bundle.OriginalBundle.Hash, errors.New("bundle reverted"))
+	recorder.write(types.NewBlockWithHeader(header), big.NewInt(0), nil, nil)
+	if err := trace.Close(); err != nil {
+		t.Fatalf("failed to close build trace: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open build trace: %v", err)
+	}
+	defer file.Close()
+	var rounds []*buildtrace.Round
+	if err := buildtrace.Read(file, func(round *buildtrace.Round) bool {
+		rounds = append(rounds, round)
+		return true
+	}); err != nil {
+		t.Fatalf("failed to read build trace: %v", err)
+	}
+	if len(rounds) != 1 {
+		t.Fatalf("%d rounds traced, want 1", len(rounds))
+	}
+	round := rounds[0]
+	if round.Number() != 1 || round.Algorithm != "greedy" || len(round.Bundles) != 1 || len(round.Mempool) != 1 || len(round.Candidates) != 1 {
+		t.Fatalf("unexpected round %+v", round)
+	}
+	if len(round.Steps) != 1 || round.Steps[0].Reason != "bundle reverted" {
+		t.Fatalf("unexpected steps %+v", round.Steps)
+	}
+
+	// the replay commits the bundle, diverging from the first traced step
+	report, err := w.replayRound(round, "")
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if len(report.Failed) != 0 || report.Divergence != 0 || report.ReplayedProfit.ToInt().Sign() <= 0 {
+		t.Fatalf("unexpected replay %+v", report)
+	}
+	if replayed := report.Steps[0].Replayed; replayed == nil || replayed.Hash != bundle.OriginalBundle.Hash || replayed.Reason != decisionlog.ReasonIncluded {
+		t.Fatalf("unexpected replayed step %+v", replayed)
+	}
+
+	// a round traced with the steps of the replay is reproduced
+	round.Steps = nil
+	for _, step := range report.Steps {
+		round.Steps = append(round.Steps, *step.Replayed)
+	}
+	round.Profit = (*hexutil.Big)(report.ReplayedProfit.ToInt())
+	if report, err = w.replayRound(round, ""); err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if report.Divergence != -1 || report.ReplayedProfit.ToInt().Cmp(round.Profit.ToInt()) != 0 {
+		t.Errorf("unexpected replay %+v", report)
+	}
+
+	if _, err := w.replayRound(round, ALGO_MEV_GETH.String()); !errors.Is(err, errReplayMevGeth) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var errNegativePayout = errors.New("negative payout")
+
+// sbundleRefund is a payment of part of the profit of a sbundle to one of its refund recipients.
+type sbundleRefund struct {
+	receiver common.Address
+	value    *big.Int
+}
+
+// sbundleRefunds returns the payments of the refund of a sbundle body element: the percent of the
+// refundable profit, less the maximum cost of the payout transactions, split between the refund
+// recipients. The payouts are sent by the builder, it fails if the refund does not cover their
+// cost.
+//
+// The refunds are paid right after the sbundle they belong to, not appended before sealing, and
+// their profitability is validated per sbundle: the payouts never exceed the refundable profit,
+// and the sbundle is rejected if the coinbase balance decreased once they are paid. As every
+// sbundle leaves the coinbase balance at least unchanged, the refunds can't make a block
+// unprofitable, the payout of the proposer still fails if the builder balance decreased over the
+// block. There is no separate block level validation.
+func sbundleRefunds(refundableProfit *big.Int, percent int, config []types.RefundConfig, baseFee *big.Int) ([]sbundleRefund, error) {
+	maxPayoutCost := new(big.Int).Set(core.SbundlePayoutMaxCost)
+	maxPayoutCost.Mul(maxPayoutCost, big.NewInt(int64(len(config))))
+	maxPayoutCost.Mul(maxPayoutCost, baseFee)
+
+	allocatedValue := common.PercentOf(refundableProfit, percent)
+	allocatedValue.Sub(allocatedValue, maxPayoutCost)
+	if allocatedValue.Sign() < 0 {
+		return nil, errNegativePayout
+	}
+
+	refunds := make([]sbundleRefund, 0, len(config))
+	for _, refund := range config {
+		refunds = append(refunds, sbundleRefund{
+			receiver: refund.Address,
+			value:    common.PercentOf(allocatedValue, refund.Percent),
+		})
+	}
+	return refunds, nil
+}
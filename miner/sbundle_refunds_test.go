@@ -0,0 +1,66 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSBundleRefunds(t *testing.T) {
+	var (
+		addr1  = common.HexToAddress("0x01")
+		addr2  = common.HexToAddress("0x02")
+		config = []types.RefundConfig{{Address: addr1, Percent: 50}, {Address: addr2, Percent: 50}}
+	)
+	// 90% of the profit, less the cost of the two payouts at a base fee of 1
+	refunds, err := sbundleRefunds(big.NewInt(1_000_000), 90, config, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("failed to compute refunds: %v", err)
+	}
+	if len(refunds) != 2 {
+		t.Fatalf("expected 2 refunds, got %d", len(refunds))
+	}
+	for i, receiver := range []common.Address{addr1, addr2} {
+		if refunds[i].receiver != receiver || refunds[i].value.Cmp(big.NewInt(420_000)) != 0 {
+			t.Errorf("refund %d mismatch, got %s to %s", i, refunds[i].value, refunds[i].receiver)
+		}
+	}
+
+	if _, err := sbundleRefunds(big.NewInt(50_000), 90, config, big.NewInt(1)); !errors.Is(err, errNegativePayout) {
+		t.Errorf("expected negative payout, got %v", err)
+	}
+}
+
+func TestSBundleRefundsCoverPayoutCost(t *testing.T) {
+	config := []types.RefundConfig{
+		{Address: common.HexToAddress("0x01"), Percent: 33},
+		{Address: common.HexToAddress("0x02"), Percent: 33},
+		{Address: common.HexToAddress("0x03"), Percent: 34},
+	}
+	baseFee := big.NewInt(7)
+	maxCost := new(big.Int).Mul(core.SbundlePayoutMaxCost, big.NewInt(int64(len(config))))
+	maxCost.Mul(maxCost, baseFee)
+
+	// the refunds and the cost of their payouts never exceed the refundable profit
+	for _, profit := range []int64{10_000_000, 12_345_679, 1_000_000_000_000} {
+		for _, percent := range []int{1, 50, 99, 100} {
+			refunds, err := sbundleRefunds(big.NewInt(profit), percent, config, baseFee)
+			if errors.Is(err, errNegativePayout) {
+				continue
+			} else if err != nil {
+				t.Fatalf("failed to compute refunds: %v", err)
+			}
+			paid := new(big.Int).Set(maxCost)
+			for _, refund := range refunds {
+				paid.Add(paid, refund.value)
+			}
+			if paid.Cmp(big.NewInt(profit)) > 0 {
+				t.Errorf("refunds of %d%% of %d cost %v", percent, profit, paid)
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package miner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+const (
+	searcherBucket    = 5 * time.Minute // Granularity of the searcher analytics windows
+	searcherRetention = 24 * time.Hour  // Longest searcher analytics window
+)
+
+// Simulation failure classes, every failed bundle simulation is counted in exactly one class
+const (
+	simFailureNonce             = "nonce"
+	simFailureInsufficientFunds = "insufficientFunds"
+	simFailureRevert            = "revert"
+	simFailureOutOfGas          = "outOfGas"
+	simFailureStateUnavailable  = "stateUnavailable"
+	simFailureTimeout           = "timeout"
+	simFailureInternal          = "internal" // Unexpected errors and simulation panics
+)
+
+// Bundle rejection reasons, for bundles refused by the builder rules rather than failing simulation
+const (
+	bundleRejectedFee         = "fee"
+	bundleRejectedPolicy      = "policy"
+	bundleRejectedBlocklist   = "blocklist"
+	bundleRejectedStateGrowth = "stateGrowth"
+	bundleRejectedSandbox     = "sandbox"
+	bundleRejectedQuarantined = "quarantined"
+	bundleRejectedReputation  = "reputation"
+	bundleRejectedTxPrefs     = "txPreferences"
+)
+
+var (
+	simFailureClasses      = []string{simFailureNonce, simFailureInsufficientFunds, simFailureRevert, simFailureOutOfGas, simFailureStateUnavailable, simFailureTimeout, simFailureInternal}
+	bundleRejectionReasons = []string{bundleRejectedFee, bundleRejectedPolicy, bundleRejectedBlocklist, bundleRejectedStateGrowth, bundleRejectedSandbox, bundleRejectedQuarantined, bundleRejectedReputation, bundleRejectedTxPrefs}
+)
+
+// SearcherStats are the aggregated analytics of a searcher, identified by its bundle signing address.
+type SearcherStats struct {
+	Searcher           common.Address    `json:"searcher"`
+	Submissions        uint64            `json:"submissions"`
+	SimulationFailures map[string]uint64 `json:"simulationFailures"` // By simulation failure class
+	Rejections         map[string]uint64 `json:"rejections"`         // By rejection reason
+	Included           uint64            `json:"included"`
+	InclusionRate      float64           `json:"inclusionRate"`
+	TotalPaid          *hexutil.Big      `json:"totalPaid"` // Paid to the builder by the bundles that landed
+}
+
+// searcherBucketStats are the analytics of a searcher in one bucket of time.
+type searcherBucketStats struct {
+	submissions uint64
+	simFailures map[string]uint64
+	rejections  map[string]uint64
+	included    uint64
+	paid        *big.Int
+}
+
+// pendingBundles are the bundles of a built block waiting for the block to land.
+type pendingBundles struct {
+	number  uint64
+	bundles []types.SimulatedBundle
+}
+
+// searcherAnalytics aggregates the activity of every searcher in buckets of time, so the
+// analytics can be queried over any window up to searcherRetention. The methods are no-ops
+// on a nil instance.
+type searcherAnalytics struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	searchers map[common.Address]map[int64]*searcherBucketStats
+	pending   map[common.Hash]*pendingBundles
+}
+
+func newSearcherAnalytics() *searcherAnalytics {
+	return &searcherAnalytics{
+		now:       time.Now,
+		searchers: make(map[common.Address]map[int64]*searcherBucketStats),
+		pending:   make(map[common.Hash]*pendingBundles),
+	}
+}
+
+// classifySimulationFailure returns the class of a bundle simulation error, or the rejection
+// reason if the bundle was refused by the builder rules, in which case rejected is true.
+func classifySimulationFailure(err error) (category string, rejected bool) {
+	var (
+		revert      *bundleRevertError
+		missingNode *trie.MissingNodeError
+	)
+	switch {
+	case errors.As(err, &revert), errors.Is(err, vm.ErrExecutionReverted):
+		return simFailureRevert, false
+	case errors.Is(err, core.ErrNonceTooLow), errors.Is(err, core.ErrNonceTooHigh), errors.Is(err, core.ErrNonceMax):
+		return simFailureNonce, false
+	case errors.Is(err, core.ErrInsufficientFunds), errors.Is(err, core.ErrInsufficientFundsForTransfer):
+		return simFailureInsufficientFunds, false
+	case errors.Is(err, core.ErrGasLimitReached), errors.Is(err, core.ErrIntrinsicGas), errors.Is(err, core.ErrGasUintOverflow), errors.Is(err, vm.ErrOutOfGas):
+		return simFailureOutOfGas, false
+	case errors.As(err, &missingNode):
+		return simFailureStateUnavailable, false
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return simFailureTimeout, false
+	case errors.Is(err, core.ErrFeeCapTooLow), errors.Is(err, core.ErrTipAboveFeeCap), errors.Is(err, core.ErrTipVeryHigh), errors.Is(err, core.ErrFeeCapVeryHigh):
+		return bundleRejectedFee, true
+	case errors.Is(err, ErrBundlePolicyViolation):
+		return bundleRejectedPolicy, true
+	case errors.Is(err, errBlocklistViolation):
+		return bundleRejectedBlocklist, true
+	case errors.Is(err, ErrStateGrowthUnprofitable):
+		return bundleRejectedStateGrowth, true
+	case errors.Is(err, errTxFrontrun), errors.Is(err, errTxSandwiched):
+		return bundleRejectedTxPrefs, true
+	case errors.Is(err, vm.ErrSandboxMemoryLimit), errors.Is(err, vm.ErrSandboxReturnDataLimit), errors.Is(err, vm.ErrSandboxPrecompileInputLimit):
+		return bundleRejectedSandbox, true
+	default:
+		return simFailureInternal, false
+	}
+}
+
+// bucket returns the stats of the searcher in the current bucket, nil for bundles without signing address.
+func (a *searcherAnalytics) bucket(searcher common.Address) *searcherBucketStats {
+	if searcher == (common.Address{}) {
+		return nil
+	}
+	buckets, ok := a.searchers[searcher]
+	if !ok {
+		buckets = make(map[int64]*searcherBucketStats)
+		a.searchers[searcher] = buckets
+	}
+	start := a.now().Truncate(searcherBucket).Unix()
+	stats, ok := buckets[start]
+	if !ok {
+		stats = &searcherBucketStats{simFailures: make(map[string]uint64), rejections: make(map[string]uint64), paid: new(big.Int)}
+		buckets[start] = stats
+	}
+	return stats
+}
+
+// submitted records a bundle accepted from the searcher.
+func (a *searcherAnalytics) submitted(searcher common.Address) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if stats := a.bucket(searcher); stats != nil {
+		stats.submissions++
+	}
+}
+
+// simulationFailed records a failed simulation of a bundle of the searcher, or a rejection of
+// the bundle if rejected is true.
+func (a *searcherAnalytics) simulationFailed(searcher common.Address, category string, rejected bool) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if stats := a.bucket(searcher); stats != nil {
+		if rejected {
+			stats.rejections[category]++
+		} else {
+			stats.simFailures[category]++
+		}
+	}
+}
+
+// blockBuilt records the bundles included in a built block.
+func (a *searcherAnalytics) blockBuilt(block *types.Block, bundles []types.SimulatedBundle) {
+	if a == nil || len(bundles) == 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.pending) >= profitPendingLimit {
+		return
+	}
+	a.pending[block.Hash()] = &pendingBundles{number: block.NumberU64(), bundles: bundles}
+}
+
+// chainHead credits the searchers of the bundles in the head block if the node built it,
+// and forgets the data that is too old. It returns the bundles of the head block, nil if
+// the node did not build it.
+func (a *searcherAnalytics) chainHead(head *types.Block) []types.SimulatedBundle {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var bundles []types.SimulatedBundle
+	if landed, ok := a.pending[head.Hash()]; ok {
+		delete(a.pending, head.Hash())
+		bundles = landed.bundles
+		for _, bundle := range landed.bundles {
+			if stats := a.bucket(bundle.OriginalBundle.SigningAddress); stats != nil {
+				stats.included++
+				if bundle.EthSentToCoinbase != nil {
+					stats.paid.Add(stats.paid, bundle.EthSentToCoinbase)
+				}
+			}
+		}
+	}
+	for hash, pending := range a.pending {
+		if pending.number+profitPendingDepth < head.NumberU64() {
+			delete(a.pending, hash)
+		}
+	}
+
+	cutoff := a.now().Add(-searcherRetention).Unix()
+	for searcher, buckets := range a.searchers {
+		for start := range buckets {
+			if start < cutoff {
+				delete(buckets, start)
+			}
+		}
+		if len(buckets) == 0 {
+			delete(a.searchers, searcher)
+		}
+	}
+	return bundles
+}
+
+// stats returns the analytics of every searcher active in the given window, capped to
+// searcherRetention, ordered by the number of submissions.
+func (a *searcherAnalytics) stats(window time.Duration) []SearcherStats {
+	if a == nil {
+		return []SearcherStats{}
+	}
+	if window <= 0 || window > searcherRetention {
+		window = searcherRetention
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cutoff := a.now().Add(-window).Truncate(searcherBucket).Unix()
+	result := make([]SearcherStats, 0, len(a.searchers))
+	for searcher, buckets := range a.searchers {
+		var (
+			stats = SearcherStats{Searcher: searcher, SimulationFailures: make(map[string]uint64), Rejections: make(map[string]uint64)}
+			paid  = new(big.Int)
+		)
+		for start, bucket := range buckets {
+			if start < cutoff {
+				continue
+			}
+			stats.Submissions += bucket.submissions
+			stats.Included += bucket.included
+			paid.Add(paid, bucket.paid)
+			for category, count := range bucket.simFailures {
+				stats.SimulationFailures[category] += count
+			}
+			for reason, count := range bucket.rejections {
+				stats.Rejections[reason] += count
+			}
+		}
+		if stats.Submissions == 0 && stats.Included == 0 && len(stats.SimulationFailures) == 0 && len(stats.Rejections) == 0 {
+			continue
+		}
+		if stats.Submissions > 0 {
+			stats.InclusionRate = float64(stats.Included) / float64(stats.Submissions)
+		}
+		stats.TotalPaid = (*hexutil.Big)(paid)
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Submissions != result[j].Submissions {
+			return result[i].Submissions > result[j].Submissions
+		}
+		return bytes.Compare(result[i].Searcher[:], result[j].Searcher[:]) < 0
+	})
+	return result
+}
@@ -0,0 +1,112 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+func TestClassifySimulationFailure(t *testing.T) {
+	tests := []struct {
+		err      error
+		category string
+		rejected bool
+	}{
+		{&bundleRevertError{GasUsed: 21000}, simFailureRevert, false},
+		{fmt.Errorf("tx 0: %w", core.ErrNonceTooLow), simFailureNonce, false},
+		{core.ErrInsufficientFunds, simFailureInsufficientFunds, false},
+		{core.ErrGasLimitReached, simFailureOutOfGas, false},
+		{fmt.Errorf("tx 1: %w", vm.ErrOutOfGas), simFailureOutOfGas, false},
+		{&trie.MissingNodeError{}, simFailureStateUnavailable, false},
+		{fmt.Errorf("simulation: %w", context.DeadlineExceeded), simFailureTimeout, false},
+		{fmt.Errorf("unknown"), simFailureInternal, false},
+		{core.ErrFeeCapTooLow, bundleRejectedFee, true},
+		{ErrBundlePolicyViolation, bundleRejectedPolicy, true},
+		{errBlocklistViolation, bundleRejectedBlocklist, true},
+		{ErrStateGrowthUnprofitable, bundleRejectedStateGrowth, true},
+		{vm.ErrSandboxMemoryLimit, bundleRejectedSandbox, true},
+	}
+	for i, test := range tests {
+		category, rejected := classifySimulationFailure(test.err)
+		if category != test.category || rejected != test.rejected {
+			t.Errorf("test %d: unexpected category %q (rejected %v), want %q (rejected %v)", i, category, rejected, test.category, test.rejected)
+		}
+	}
+	// every class has a meter
+	for _, class := range simFailureClasses {
+		if simulationFailureMeters[class] == nil {
+			t.Errorf("missing meter for simulation failure class %q", class)
+		}
+	}
+	for _, reason := range bundleRejectionReasons {
+		if bundleRejectionMeters[reason] == nil {
+			t.Errorf("missing meter for rejection reason %q", reason)
+		}
+	}
+}
+
+func TestSearcherAnalytics(t *testing.T) {
+	var (
+		alice = common.Address{0xa}
+		bob   = common.Address{0xb}
+		now   = time.Unix(1_700_000_000, 0)
+	)
+	analytics := newSearcherAnalytics()
+	analytics.now = func() time.Time { return now }
+
+	// an hour ago
+	now = now.Add(-time.Hour)
+	analytics.submitted(bob)
+	analytics.simulationFailed(bob, simFailureNonce, false)
+	now = now.Add(time.Hour)
+
+	analytics.submitted(alice)
+	analytics.submitted(alice)
+	analytics.submitted(bob)
+	analytics.submitted(common.Address{}) // bundles without signing address are not tracked
+	analytics.simulationFailed(bob, simFailureRevert, false)
+	analytics.simulationFailed(bob, bundleRejectedQuarantined, true)
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10)})
+	bundles := []types.SimulatedBundle{
+		{OriginalBundle: types.MevBundle{SigningAddress: alice}, EthSentToCoinbase: big.NewInt(100)},
+	}
+	analytics.blockBuilt(block, bundles)
+	analytics.chainHead(block)
+	analytics.chainHead(block) // the head is reported by every worker
+
+	stats := analytics.stats(0)
+	if len(stats) != 2 {
+		t.Fatalf("unexpected number of searchers %d", len(stats))
+	}
+	if s := stats[0]; s.Searcher != alice || s.Submissions != 2 || s.Included != 1 || s.InclusionRate != 0.5 || s.TotalPaid.ToInt().Int64() != 100 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if s := stats[1]; s.Searcher != bob || s.Submissions != 2 || s.SimulationFailures[simFailureNonce] != 1 || s.SimulationFailures[simFailureRevert] != 1 || s.Rejections[bundleRejectedQuarantined] != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	// the window excludes the older activity
+	stats = analytics.stats(10 * time.Minute)
+	if len(stats) != 2 {
+		t.Fatalf("unexpected number of searchers %d", len(stats))
+	}
+	if s := stats[1]; s.Searcher != bob || s.Submissions != 1 || s.SimulationFailures[simFailureNonce] != 0 {
+		t.Errorf("unexpected windowed stats %+v", s)
+	}
+
+	// activity past the retention is pruned
+	now = now.Add(searcherRetention + time.Hour)
+	analytics.chainHead(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(11)}))
+	if len(analytics.searchers) != 0 {
+		t.Fatalf("unexpected searchers after retention %d", len(analytics.searchers))
+	}
+}
@@ -0,0 +1,380 @@
+package miner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	reputationHalfLife     = 7 * 24 * time.Hour // Period after which the history of a searcher weighs half
+	reputationDecayPeriod  = time.Hour          // Interval at which the history is decayed
+	reputationSavePeriod   = time.Minute        // Interval at which the changed reputations are persisted
+	reputationForgetWeight = 0.01               // Decayed weight under which the history of a searcher is dropped
+)
+
+// defaultReputationConfig prioritises simulation by reputation above 500 pending bundles, capping the
+// searchers failing over 90% of their simulations to 5 bundles per block.
+var defaultReputationConfig = ReputationConfig{
+	HighLoad:             500,
+	LowScore:             0.1,
+	LowReputationBundles: 5,
+}
+
+// ReputationConfig configures the reputation of the searchers, scored from their history of
+// simulation failures, reverts on chain and profit delivered.
+type ReputationConfig struct {
+	File                 string  // JSON file the reputations are persisted to, empty keeps them in memory
+	HighLoad             int     // Bundles to simulate for a block above which simulation is prioritised by reputation, 0 disables
+	LowScore             float64 // Score under which a searcher has a low reputation
+	LowReputationBundles int     // Bundles of a low reputation searcher simulated per block under high load
+}
+
+// SearcherReputation is the reputation of a searcher, identified by its bundle signing address.
+// The history is decayed over time, the counts are weights rather than integers.
+type SearcherReputation struct {
+	Searcher    common.Address `json:"searcher"`
+	Simulations float64        `json:"simulations"`
+	SimFailures float64        `json:"simFailures"`
+	Landed      float64        `json:"landed"`
+	Reverted    float64        `json:"reverted"`  // Landed bundles with a reverted transaction
+	Delivered   float64        `json:"delivered"` // Paid to the builder by the landed bundles, in ether
+	Score       float64        `json:"score"`
+}
+
+// reputationHistory is the decayed history of a searcher.
+type reputationHistory struct {
+	Simulations float64 `json:"simulations"`
+	SimFailures float64 `json:"simFailures"`
+	Landed      float64 `json:"landed"`
+	Reverted    float64 `json:"reverted"`
+	Delivered   float64 `json:"delivered"`
+}
+
+// score is the share of successful simulations times the share of landed bundles that did not
+// revert, both smoothed so a searcher without history scores 0.5.
+func (h *reputationHistory) score() float64 {
+	simulated := (h.Simulations - h.SimFailures + 1) / (h.Simulations + 2)
+	clean := (h.Landed - h.Reverted + 1) / (h.Landed + 1)
+	return simulated * clean
+}
+
+func (h *reputationHistory) decay(factor float64) {
+	h.Simulations *= factor
+	h.SimFailures *= factor
+	h.Landed *= factor
+	h.Reverted *= factor
+	h.Delivered *= factor
+}
+
+// reputationStore is the content of the reputation file.
+type reputationStore struct {
+	Decayed   int64                                 `json:"decayed"` // Unix time of the last decay
+	Searchers map[common.Address]*reputationHistory `json:"searchers"`
+
+	seq uint64 // Order of the snapshot, the older snapshots are not written over the newer ones
+}
+
+// reputationTracker scores the searchers from their history, persisted across restarts, to
+// prioritise the simulation of their bundles. It is shared by all workers and safe for concurrent
+// use, the methods are no-ops on a nil instance.
+type reputationTracker struct {
+	config ReputationConfig
+
+	mu        sync.Mutex
+	now       func() time.Time
+	searchers map[common.Address]*reputationHistory
+	decayed   time.Time
+	saved     time.Time
+	dirty     bool
+	snapshots uint64
+
+	saving  sync.Mutex // Serialises the writes of the file
+	written uint64     // Sequence of the last snapshot written
+}
+
+// newReputationTracker returns the tracker for the config, loading the persisted reputations.
+func newReputationTracker(config ReputationConfig) *reputationTracker {
+	r := &reputationTracker{
+		config:    config,
+		now:       time.Now,
+		searchers: make(map[common.Address]*reputationHistory),
+	}
+	r.decayed, r.saved = r.now(), r.now()
+	if config.File == "" {
+		return r
+	}
+	data, err := os.ReadFile(config.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return r
+	}
+	var store reputationStore
+	if err == nil {
+		err = json.Unmarshal(data, &store)
+	}
+	if err != nil {
+		log.Error("Failed to load searcher reputations, starting afresh", "file", config.File, "err", err)
+		return r
+	}
+	for searcher, history := range store.Searchers {
+		if history != nil {
+			r.searchers[searcher] = history
+		}
+	}
+	if store.Decayed != 0 {
+		r.decayed = time.Unix(store.Decayed, 0)
+	}
+	log.Info("Loaded searcher reputations", "file", config.File, "searchers", len(r.searchers))
+	return r
+}
+
+// history returns the history of the searcher, nil for bundles without signing address.
+func (r *reputationTracker) history(searcher common.Address) *reputationHistory {
+	if searcher == (common.Address{}) {
+		return nil
+	}
+	h, ok := r.searchers[searcher]
+	if !ok {
+		h = new(reputationHistory)
+		r.searchers[searcher] = h
+	}
+	r.dirty = true
+	return h
+}
+
+// score returns the reputation score of the searcher, the score of an unknown searcher if it has
+// no history.
+func (r *reputationTracker) score(searcher common.Address) float64 {
+	if r != nil {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if h, ok := r.searchers[searcher]; ok {
+			return h.score()
+		}
+	}
+	return new(reputationHistory).score()
+}
+
+// simulated records a simulation of a bundle of the searcher.
+func (r *reputationTracker) simulated(searcher common.Address, failed bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if h := r.history(searcher); h != nil {
+		h.Simulations++
+		if failed {
+			h.SimFailures++
+		}
+	}
+}
+
+// landed records the bundles of a built block that landed on chain, with the receipts of the block.
+func (r *reputationTracker) landed(bundles []types.SimulatedBundle, receipts types.Receipts) {
+	if r == nil || len(bundles) == 0 {
+		return
+	}
+	failed := make(map[common.Hash]bool)
+	for _, receipt := range receipts {
+		if receipt.Status == types.ReceiptStatusFailed {
+			failed[receipt.TxHash] = true
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, bundle := range bundles {
+		h := r.history(bundle.OriginalBundle.SigningAddress)
+		if h == nil {
+			continue
+		}
+		h.Landed++
+		for _, tx := range bundle.OriginalBundle.Txs {
+			if failed[tx.Hash()] {
+				h.Reverted++
+				break
+			}
+		}
+		if bundle.EthSentToCoinbase != nil {
+			delivered, _ := ethIntToFloat(bundle.EthSentToCoinbase).Float64()
+			h.Delivered += delivered
+		}
+	}
+}
+
+// chainHead decays the history once per reputationDecayPeriod and persists the changed
+// reputations once per reputationSavePeriod.
+func (r *reputationTracker) chainHead() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if elapsed := now.Sub(r.decayed); elapsed >= reputationDecayPeriod {
+		factor := math.Pow(0.5, float64(elapsed)/float64(reputationHalfLife))
+		for searcher, h := range r.searchers {
+			h.decay(factor)
+			if h.Simulations+h.Landed < reputationForgetWeight {
+				delete(r.searchers, searcher)
+			}
+		}
+		r.decayed = now
+		r.dirty = true
+	}
+	if r.config.File != "" && r.dirty && now.Sub(r.saved) >= reputationSavePeriod {
+		store := r.snapshot()
+		r.saved, r.dirty = now, false
+		go r.save(store)
+	}
+}
+
+// snapshot copies the reputations to persist, the lock must be held.
+func (r *reputationTracker) snapshot() *reputationStore {
+	r.snapshots++
+	store := &reputationStore{Decayed: r.decayed.Unix(), Searchers: make(map[common.Address]*reputationHistory, len(r.searchers)), seq: r.snapshots}
+	for searcher, h := range r.searchers {
+		history := *h
+		store.Searchers[searcher] = &history
+	}
+	return store
+}
+
+// save writes the reputations to a temporary file renamed over the reputation file.
+func (r *reputationTracker) save(store *reputationStore) {
+	r.saving.Lock()
+	defer r.saving.Unlock()
+
+	if store.seq <= r.written {
+		return
+	}
+	r.written = store.seq
+
+	data, err := json.Marshal(store)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(r.config.File), 0700)
+	}
+	if err == nil {
+		err = os.WriteFile(r.config.File+".new", data, 0600)
+	}
+	if err == nil {
+		err = os.Rename(r.config.File+".new", r.config.File)
+	}
+	if err != nil {
+		log.Error("Failed to save searcher reputations", "file", r.config.File, "err", err)
+	}
+}
+
+// close persists the reputations, once the pending saves are done.
+func (r *reputationTracker) close() {
+	if r == nil || r.config.File == "" {
+		return
+	}
+	r.mu.Lock()
+	store := r.snapshot()
+	r.dirty = false
+	r.mu.Unlock()
+
+	r.save(store)
+}
+
+// prioritize returns the order the bundles are simulated in. Under high load, when more bundles than
+// the configured threshold are not simulated yet, the bundles are ordered by the score of their
+// searcher, then by the profit the searcher delivered, and the bundles of low reputation searchers
+// over the cap are returned as capped instead.
+func (r *reputationTracker) prioritize(bundles []types.MevBundle, cached func(common.Hash) bool) (order []int, capped []int, highLoad bool) {
+	order = make([]int, len(bundles))
+	for i := range bundles {
+		order[i] = i
+	}
+	if r == nil || r.config.HighLoad <= 0 {
+		return order, nil, false
+	}
+	pending := 0
+	for _, bundle := range bundles {
+		if !cached(bundle.Hash) {
+			pending++
+		}
+	}
+	if pending <= r.config.HighLoad {
+		return order, nil, false
+	}
+
+	r.mu.Lock()
+	var (
+		scores    = make([]float64, len(bundles))
+		delivered = make([]float64, len(bundles))
+		unknown   = new(reputationHistory).score()
+	)
+	for i, bundle := range bundles {
+		scores[i] = unknown
+		if h, ok := r.searchers[bundle.SigningAddress]; ok {
+			scores[i], delivered[i] = h.score(), h.Delivered
+		}
+	}
+	r.mu.Unlock()
+
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		return delivered[a] > delivered[b]
+	})
+	kept := order[:0]
+	simulations := make(map[common.Address]int)
+	for _, i := range order {
+		bundle := bundles[i]
+		if scores[i] < r.config.LowScore && !cached(bundle.Hash) {
+			if simulations[bundle.SigningAddress] >= r.config.LowReputationBundles {
+				capped = append(capped, i)
+				continue
+			}
+			simulations[bundle.SigningAddress]++
+		}
+		kept = append(kept, i)
+	}
+	return kept, capped, true
+}
+
+// reputations returns the reputation of every searcher with a history, highest score first.
+func (r *reputationTracker) reputations() []SearcherReputation {
+	if r == nil {
+		return []SearcherReputation{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]SearcherReputation, 0, len(r.searchers))
+	for searcher, h := range r.searchers {
+		result = append(result, SearcherReputation{
+			Searcher:    searcher,
+			Simulations: h.Simulations,
+			SimFailures: h.SimFailures,
+			Landed:      h.Landed,
+			Reverted:    h.Reverted,
+			Delivered:   h.Delivered,
+			Score:       h.score(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		return bytes.Compare(result[i].Searcher[:], result[j].Searcher[:]) < 0
+	})
+	return result
+}
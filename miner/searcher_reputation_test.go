@@ -0,0 +1,76 @@
+package miner
+
+import (
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestSearcherReputation(t *testing.T) {
+	var (
+		alice = common.Address{0xa}
+		bob   = common.Address{0xb}
+		carol = common.Address{0xc}
+		now   = time.Unix(1_700_000_000, 0)
+		file  = filepath.Join(t.TempDir(), "reputation.json")
+	)
+	config := ReputationConfig{File: file, HighLoad: 3, LowScore: 0.2, LowReputationBundles: 1}
+	tracker := newReputationTracker(config)
+	tracker.now = func() time.Time { return now }
+	tracker.decayed = now
+
+	// alice simulates cleanly and lands a bundle that reverts, bob fails every simulation
+	for i := 0; i < 8; i++ {
+		tracker.simulated(alice, false)
+		tracker.simulated(bob, true)
+	}
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+	tracker.landed([]types.SimulatedBundle{
+		{EthSentToCoinbase: big.NewInt(params.Ether), OriginalBundle: types.MevBundle{Txs: types.Transactions{tx}, SigningAddress: alice}},
+	}, types.Receipts{{TxHash: tx.Hash(), Status: types.ReceiptStatusFailed}})
+
+	reputations := tracker.reputations()
+	if len(reputations) != 2 || reputations[0].Searcher != alice || reputations[1].Searcher != bob {
+		t.Fatalf("unexpected reputations %+v", reputations)
+	}
+	if rep := reputations[0]; rep.Landed != 1 || rep.Reverted != 1 || rep.Delivered != 1 || rep.Score != 0.45 {
+		t.Errorf("unexpected reputation of alice %+v", rep)
+	}
+	if rep := reputations[1]; rep.Score != 0.1 {
+		t.Errorf("unexpected score of bob %v, want 0.1", rep.Score)
+	}
+
+	// under high load the bundles are ordered by score and bob is capped to one simulation
+	bundles := []types.MevBundle{
+		{Hash: common.Hash{1}, SigningAddress: bob},
+		{Hash: common.Hash{2}, SigningAddress: bob},
+		{Hash: common.Hash{3}, SigningAddress: carol},
+		{Hash: common.Hash{4}, SigningAddress: alice},
+		{Hash: common.Hash{5}, SigningAddress: bob},
+	}
+	uncached := func(common.Hash) bool { return false }
+	order, capped, highLoad := tracker.prioritize(bundles, uncached)
+	if !highLoad || !reflect.DeepEqual(order, []int{2, 3, 0}) || !reflect.DeepEqual(capped, []int{1, 4}) {
+		t.Errorf("unexpected prioritization %v capped %v (high load %v)", order, capped, highLoad)
+	}
+	// the simulated bundles do not count towards the load
+	order, capped, highLoad = tracker.prioritize(bundles, func(hash common.Hash) bool { return hash[0] <= 2 })
+	if highLoad || !reflect.DeepEqual(order, []int{0, 1, 2, 3, 4}) || capped != nil {
+		t.Errorf("unexpected prioritization %v capped %v (high load %v)", order, capped, highLoad)
+	}
+
+	// the history is decayed and persisted across restarts
+	now = now.Add(reputationHalfLife)
+	tracker.chainHead()
+	tracker.close()
+	restored := newReputationTracker(config)
+	if reputations := restored.reputations(); len(reputations) != 2 || reputations[0].Simulations != 4 || reputations[1].SimFailures != 4 {
+		t.Errorf("unexpected restored reputations %+v", reputations)
+	}
+}
@@ -0,0 +1,96 @@
+package miner
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var ErrNoTxSigner = errors.New("builder tx signer is not set")
+
+// TxSigner signs the transactions created by the builder itself, i.e. proposer payouts and sbundle refunds.
+// Implementations may keep the key outside of the builder host (see builder/keymanager).
+type TxSigner interface {
+	// Address returns the address of the builder coinbase account
+	Address() common.Address
+	// SignTx returns a copy of tx signed with the builder coinbase key
+	SignTx(tx *types.Transaction, signer types.Signer) (*types.Transaction, error)
+}
+
+type localTxSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewLocalTxSigner returns a TxSigner using a private key held in memory.
+func NewLocalTxSigner(key *ecdsa.PrivateKey) TxSigner {
+	return &localTxSigner{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}
+}
+
+func (s *localTxSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *localTxSigner) SignTx(tx *types.Transaction, signer types.Signer) (*types.Transaction, error) {
+	return types.SignTx(tx, signer, s.key)
+}
+
+// cachingTxSigner remembers the transactions signed by a signer making a remote call for every
+// signature.
+type cachingTxSigner struct {
+	TxSigner
+	signed *lru.Cache[common.Hash, *types.Transaction] // Signed transactions by signing hash
+}
+
+// NewCachingTxSigner returns a TxSigner remembering the last size transactions signed by txSigner,
+// for the signers calling a remote key manager for every signature. The payouts built again with
+// the same value by the build rounds of a block and its resubmissions are only signed once, so
+// the latency of the key manager isn't paid by every build round.
+func NewCachingTxSigner(txSigner TxSigner, size int) TxSigner {
+	return &cachingTxSigner{TxSigner: txSigner, signed: lru.NewCache[common.Hash, *types.Transaction](size)}
+}
+
+func (s *cachingTxSigner) SignTx(tx *types.Transaction, signer types.Signer) (*types.Transaction, error) {
+	hash := signer.Hash(tx)
+	if signed, ok := s.signed.Get(hash); ok {
+		return signed, nil
+	}
+	signed, err := s.TxSigner.SignTx(tx, signer)
+	if err != nil {
+		return nil, err
+	}
+	s.signed.Add(hash, signed)
+	return signed, nil
+}
+
+// checkTxSigner signs a throwaway transaction to check that the signer is available and signs
+// with the builder coinbase key. The transaction is unique so that it is never signed from a
+// cache.
+func checkTxSigner(txSigner TxSigner, chainConfig *params.ChainConfig) error {
+	if txSigner == nil {
+		return ErrNoTxSigner
+	}
+	signer := types.LatestSigner(chainConfig)
+	tx, err := txSigner.SignTx(types.NewTx(&types.LegacyTx{Nonce: uint64(time.Now().UnixNano())}), signer)
+	if err != nil {
+		return err
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return err
+	}
+	if from != txSigner.Address() {
+		return fmt.Errorf("tx signer signed as %v instead of %v", from, txSigner.Address())
+	}
+	return nil
+}
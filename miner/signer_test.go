@@ -0,0 +1,69 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// countingTxSigner counts the transactions it signs.
+type countingTxSigner struct {
+	TxSigner
+	signed int
+}
+
+func (s *countingTxSigner) SignTx(tx *types.Transaction, signer types.Signer) (*types.Transaction, error) {
+	s.signed++
+	return s.TxSigner.SignTx(tx, signer)
+}
+
+func TestCachingTxSigner(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	counting := &countingTxSigner{TxSigner: NewLocalTxSigner(key)}
+	txSigner := NewCachingTxSigner(counting, 2)
+	signer := types.LatestSigner(params.TestChainConfig)
+
+	payout := func(value int64) *types.Transaction {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   params.TestChainConfig.ChainID,
+			GasTipCap: new(big.Int),
+			GasFeeCap: big.NewInt(1),
+			Gas:       21000,
+			To:        &common.Address{0x1},
+			Value:     big.NewInt(value),
+		})
+	}
+
+	// the same transaction is signed once
+	first, err := txSigner.SignTx(payout(1), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := txSigner.SignTx(payout(1), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counting.signed != 1 || again.Hash() != first.Hash() {
+		t.Fatalf("transaction signed %d times", counting.signed)
+	}
+	if from, err := types.Sender(signer, again); err != nil || from != txSigner.Address() {
+		t.Fatalf("unexpected sender %v, err %v", from, err)
+	}
+	if _, err := txSigner.SignTx(payout(2), signer); err != nil || counting.signed != 2 {
+		t.Fatalf("new transaction not signed, err %v", err)
+	}
+
+	// the availability check always reaches the signer
+	for i := 0; i < 2; i++ {
+		if err := checkTxSigner(txSigner, params.TestChainConfig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if counting.signed != 4 {
+		t.Fatalf("availability checks signed from the cache, %d signatures", counting.signed)
+	}
+}
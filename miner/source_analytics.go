@@ -0,0 +1,273 @@
+package miner
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Bundle ingestion sources
+const (
+	BundleSourceHTTP    = "rpc-http" // JSON-RPC over HTTP
+	BundleSourceWS      = "rpc-ws"   // JSON-RPC over WebSocket
+	BundleSourceIPC     = "rpc-ipc"  // JSON-RPC over IPC
+	BundleSourceFetcher = "fetcher"  // Bundle fetcher reading the bundle database
+	BundleSourceUnknown = "unknown"
+)
+
+// sourceBundleRetention is how long the source of a bundle is remembered, bundles target
+// a block and are not included long after their submission
+const sourceBundleRetention = time.Hour
+
+// BundleSourceFromTransport returns the ingestion source of a bundle received over the given
+// RPC transport, as reported by rpc.PeerInfo.
+func BundleSourceFromTransport(transport string) string {
+	switch transport {
+	case "http":
+		return BundleSourceHTTP
+	case "ws":
+		return BundleSourceWS
+	case "ipc":
+		return BundleSourceIPC
+	default:
+		return BundleSourceUnknown
+	}
+}
+
+// SourceStats are the aggregated analytics of a bundle ingestion source.
+type SourceStats struct {
+	Source             string       `json:"source"`
+	Submissions        uint64       `json:"submissions"`
+	Simulations        uint64       `json:"simulations"`
+	SimulationFailures uint64       `json:"simulationFailures"`
+	Included           uint64       `json:"included"`
+	InclusionRate      float64      `json:"inclusionRate"`
+	TotalPaid          *hexutil.Big `json:"totalPaid"` // Paid to the builder by the bundles that landed
+}
+
+// sourceBucketStats are the analytics of a source in one bucket of time.
+type sourceBucketStats struct {
+	submissions uint64
+	simulations uint64
+	simFailures uint64
+	included    uint64
+	paid        *big.Int
+}
+
+// bundleOrigin is the ingestion source of a submitted bundle.
+type bundleOrigin struct {
+	source string
+	seen   time.Time
+}
+
+// sourcedBundle is a bundle of a built block, with the payment credited to its source if the block lands.
+type sourcedBundle struct {
+	hash common.Hash
+	paid *big.Int
+}
+
+// pendingSourcedBundles are the bundles of a built block waiting for the block to land.
+type pendingSourcedBundles struct {
+	number  uint64
+	bundles []sourcedBundle
+}
+
+// sourceAnalytics aggregates the submissions, simulations and inclusions of bundles by the
+// source they were received from, in the same buckets of time as the searcher analytics.
+// Bundles are attributed to their source by hash, bundles of unknown origin are ignored.
+// The methods are no-ops on a nil instance.
+type sourceAnalytics struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	bundles map[common.Hash]*bundleOrigin
+	sources map[string]map[int64]*sourceBucketStats
+	pending map[common.Hash]*pendingSourcedBundles
+}
+
+func newSourceAnalytics() *sourceAnalytics {
+	return &sourceAnalytics{
+		now:     time.Now,
+		bundles: make(map[common.Hash]*bundleOrigin),
+		sources: make(map[string]map[int64]*sourceBucketStats),
+		pending: make(map[common.Hash]*pendingSourcedBundles),
+	}
+}
+
+// bucket returns the stats of the source of the bundle in the current bucket, nil for bundles of
+// unknown origin.
+func (a *sourceAnalytics) bucket(hash common.Hash) *sourceBucketStats {
+	origin, ok := a.bundles[hash]
+	if !ok {
+		return nil
+	}
+	buckets, ok := a.sources[origin.source]
+	if !ok {
+		buckets = make(map[int64]*sourceBucketStats)
+		a.sources[origin.source] = buckets
+	}
+	start := a.now().Truncate(searcherBucket).Unix()
+	stats, ok := buckets[start]
+	if !ok {
+		stats = &sourceBucketStats{paid: new(big.Int)}
+		buckets[start] = stats
+	}
+	return stats
+}
+
+// submitted records a bundle accepted from the given source.
+func (a *sourceAnalytics) submitted(hash common.Hash, source string) {
+	if a == nil || hash == (common.Hash{}) {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// resubmissions are attributed to the first source
+	if _, ok := a.bundles[hash]; !ok {
+		a.bundles[hash] = &bundleOrigin{source: source, seen: a.now()}
+	}
+	a.bucket(hash).submissions++
+}
+
+// simulated records a simulation of the bundle, failed if err is not nil.
+func (a *sourceAnalytics) simulated(hash common.Hash, err error) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if stats := a.bucket(hash); stats != nil {
+		stats.simulations++
+		if err != nil {
+			stats.simFailures++
+		}
+	}
+}
+
+// blockBuilt records the bundles and successful sbundles included in a built block.
+func (a *sourceAnalytics) blockBuilt(block *types.Block, bundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle) {
+	if a == nil || len(bundles)+len(usedSbundles) == 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.pending) >= profitPendingLimit {
+		return
+	}
+	included := make([]sourcedBundle, 0, len(bundles)+len(usedSbundles))
+	for _, bundle := range bundles {
+		if _, ok := a.bundles[bundle.OriginalBundle.Hash]; ok {
+			included = append(included, sourcedBundle{hash: bundle.OriginalBundle.Hash, paid: bundle.EthSentToCoinbase})
+		}
+	}
+	for _, sbundle := range usedSbundles {
+		if !sbundle.Success {
+			continue
+		}
+		if hash := sbundle.Bundle.Hash(); a.bundles[hash] != nil {
+			included = append(included, sourcedBundle{hash: hash})
+		}
+	}
+	if len(included) > 0 {
+		a.pending[block.Hash()] = &pendingSourcedBundles{number: block.NumberU64(), bundles: included}
+	}
+}
+
+// chainHead credits the sources of the bundles in the head block if the node built it,
+// and forgets the data that is too old.
+func (a *sourceAnalytics) chainHead(head *types.Block) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if landed, ok := a.pending[head.Hash()]; ok {
+		delete(a.pending, head.Hash())
+		for _, bundle := range landed.bundles {
+			if stats := a.bucket(bundle.hash); stats != nil {
+				stats.included++
+				if bundle.paid != nil {
+					stats.paid.Add(stats.paid, bundle.paid)
+				}
+			}
+		}
+	}
+	for hash, pending := range a.pending {
+		if pending.number+profitPendingDepth < head.NumberU64() {
+			delete(a.pending, hash)
+		}
+	}
+
+	now := a.now()
+	for hash, origin := range a.bundles {
+		if now.Sub(origin.seen) > sourceBundleRetention {
+			delete(a.bundles, hash)
+		}
+	}
+	cutoff := now.Add(-searcherRetention).Unix()
+	for source, buckets := range a.sources {
+		for start := range buckets {
+			if start < cutoff {
+				delete(buckets, start)
+			}
+		}
+		if len(buckets) == 0 {
+			delete(a.sources, source)
+		}
+	}
+}
+
+// stats returns the analytics of every source active in the given window, capped to
+// searcherRetention, ordered by the number of submissions.
+func (a *sourceAnalytics) stats(window time.Duration) []SourceStats {
+	if a == nil {
+		return []SourceStats{}
+	}
+	if window <= 0 || window > searcherRetention {
+		window = searcherRetention
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cutoff := a.now().Add(-window).Truncate(searcherBucket).Unix()
+	result := make([]SourceStats, 0, len(a.sources))
+	for source, buckets := range a.sources {
+		var (
+			stats = SourceStats{Source: source}
+			paid  = new(big.Int)
+		)
+		for start, bucket := range buckets {
+			if start < cutoff {
+				continue
+			}
+			stats.Submissions += bucket.submissions
+			stats.Simulations += bucket.simulations
+			stats.SimulationFailures += bucket.simFailures
+			stats.Included += bucket.included
+			paid.Add(paid, bucket.paid)
+		}
+		if stats.Submissions == 0 && stats.Simulations == 0 && stats.Included == 0 {
+			continue
+		}
+		if stats.Submissions > 0 {
+			stats.InclusionRate = float64(stats.Included) / float64(stats.Submissions)
+		}
+		stats.TotalPaid = (*hexutil.Big)(paid)
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Submissions != result[j].Submissions {
+			return result[i].Submissions > result[j].Submissions
+		}
+		return result[i].Source < result[j].Source
+	})
+	return result
+}
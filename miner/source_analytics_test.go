@@ -0,0 +1,62 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestSourceAnalytics(t *testing.T) {
+	var (
+		now     = time.Unix(1_700_000_000, 0)
+		landed  = common.Hash{1}
+		dropped = common.Hash{2}
+		fetched = common.Hash{3}
+	)
+	analytics := newSourceAnalytics()
+	analytics.now = func() time.Time { return now }
+
+	analytics.submitted(landed, BundleSourceFromTransport("ws"))
+	analytics.submitted(landed, BundleSourceFromTransport("http")) // resubmissions count for the first source
+	analytics.submitted(dropped, BundleSourceFromTransport("http"))
+	analytics.submitted(fetched, BundleSourceFetcher)
+	analytics.submitted(common.Hash{}, BundleSourceHTTP) // bundles without hash are not tracked
+
+	analytics.simulated(landed, nil)
+	analytics.simulated(dropped, errors.New("reverted"))
+	analytics.simulated(dropped, errors.New("reverted"))
+	analytics.simulated(common.Hash{4}, nil) // unknown origin
+
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10)})
+	bundles := []types.SimulatedBundle{
+		{OriginalBundle: types.MevBundle{Hash: landed}, EthSentToCoinbase: big.NewInt(100)},
+	}
+	analytics.blockBuilt(block, bundles, nil)
+	analytics.chainHead(block)
+	analytics.chainHead(block) // the head is reported by every worker
+
+	stats := analytics.stats(0)
+	if len(stats) != 3 {
+		t.Fatalf("unexpected number of sources %d", len(stats))
+	}
+	if s := stats[0]; s.Source != BundleSourceWS || s.Submissions != 2 || s.Simulations != 1 || s.Included != 1 || s.InclusionRate != 0.5 || s.TotalPaid.ToInt().Int64() != 100 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if s := stats[1]; s.Source != BundleSourceFetcher || s.Submissions != 1 || s.Included != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if s := stats[2]; s.Source != BundleSourceHTTP || s.Submissions != 1 || s.Simulations != 2 || s.SimulationFailures != 2 || s.InclusionRate != 0 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+
+	// bundle origins and activity past the retention are pruned
+	now = now.Add(searcherRetention + time.Hour)
+	analytics.chainHead(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(11)}))
+	if len(analytics.sources) != 0 || len(analytics.bundles) != 0 {
+		t.Fatalf("unexpected sources after retention %d, bundles %d", len(analytics.sources), len(analytics.bundles))
+	}
+}
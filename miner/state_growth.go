@@ -0,0 +1,161 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+var (
+	ErrStateGrowthBudget       = errors.New("block state growth budget exceeded")
+	ErrStateGrowthUnprofitable = errors.New("bundle profit too low for its state growth")
+)
+
+// StateGrowthConfig bounds the state bundles may create. State growth is counted as the number
+// of new accounts plus the number of storage slots set from zero to a non-zero value, net of
+// the slots cleared by the bundle.
+type StateGrowthConfig struct {
+	MaxPerBlock      uint64   // Maximum state growth of the bundles packed in a block, 0 = unlimited
+	MinProfitPerItem *big.Int // Minimum bundle profit in wei per created account or slot, bundles below it are dropped
+}
+
+func (c *StateGrowthConfig) enabled() bool {
+	return c.MaxPerBlock != 0 || (c.MinProfitPerItem != nil && c.MinProfitPerItem.Sign() > 0)
+}
+
+// disproportionate reports whether the bundle profit is too low for the state it creates.
+func (c *StateGrowthConfig) disproportionate(growth uint64, profit *big.Int) bool {
+	if growth == 0 || c.MinProfitPerItem == nil || c.MinProfitPerItem.Sign() <= 0 {
+		return false
+	}
+	minProfit := new(big.Int).Mul(c.MinProfitPerItem, new(big.Int).SetUint64(growth))
+	return profit.Cmp(minProfit) < 0
+}
+
+// checkStateGrowthBudget returns an error if a bundle creating growth state items does not
+// fit in the block budget, given the state already created by the packed bundles.
+func checkStateGrowthBudget(algoConf algorithmConfig, used, growth uint64) error {
+	if algoConf.MaxStateGrowth == 0 || growth == 0 {
+		return nil
+	}
+	if used+growth > algoConf.MaxStateGrowth {
+		return ErrStateGrowthBudget
+	}
+	return nil
+}
+
+// stateGrowthTracer counts the accounts and storage slots created by a transaction. Creations
+// in reverted call frames are discarded. Value transfers of the transaction itself to new
+// accounts happen before tracing starts and are not counted.
+type stateGrowthTracer struct {
+	inner vm.EVMLogger // Optional tracer all events are forwarded to
+
+	env        *vm.EVM
+	frames     []int64 // Net state growth of the active call frames
+	newAccount bool    // The call about to be entered transfers value to a new account
+	growth     int64   // Net state growth of the transaction
+}
+
+func newStateGrowthTracer(inner vm.EVMLogger) *stateGrowthTracer {
+	return &stateGrowthTracer{inner: inner}
+}
+
+// Growth returns the net state growth of the traced transaction.
+func (t *stateGrowthTracer) Growth() uint64 {
+	if t.growth < 0 {
+		return 0
+	}
+	return uint64(t.growth)
+}
+
+func (t *stateGrowthTracer) CaptureTxStart(gasLimit uint64) {
+	t.growth = 0
+	if t.inner != nil {
+		t.inner.CaptureTxStart(gasLimit)
+	}
+}
+
+func (t *stateGrowthTracer) CaptureTxEnd(restGas uint64) {
+	if t.inner != nil {
+		t.inner.CaptureTxEnd(restGas)
+	}
+}
+
+func (t *stateGrowthTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	t.frames = t.frames[:0]
+	if create {
+		t.frames = append(t.frames, 1)
+	} else {
+		t.frames = append(t.frames, 0)
+	}
+	if t.inner != nil {
+		t.inner.CaptureStart(env, from, to, create, input, gas, value)
+	}
+}
+
+func (t *stateGrowthTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	if err == nil && len(t.frames) > 0 {
+		t.growth += t.frames[0]
+	}
+	t.frames = t.frames[:0]
+	if t.inner != nil {
+		t.inner.CaptureEnd(output, gasUsed, err)
+	}
+}
+
+func (t *stateGrowthTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	var growth int64
+	if typ == vm.CREATE || typ == vm.CREATE2 || t.newAccount {
+		growth = 1
+	}
+	t.newAccount = false
+	t.frames = append(t.frames, growth)
+	if t.inner != nil {
+		t.inner.CaptureEnter(typ, from, to, input, gas, value)
+	}
+}
+
+func (t *stateGrowthTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if n := len(t.frames); n > 1 {
+		if err == nil {
+			t.frames[n-2] += t.frames[n-1]
+		}
+		t.frames = t.frames[:n-1]
+	}
+	if t.inner != nil {
+		t.inner.CaptureExit(output, gasUsed, err)
+	}
+}
+
+func (t *stateGrowthTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	t.newAccount = false
+	if n := len(t.frames); n > 0 && t.env != nil {
+		stack := scope.Stack.Data()
+		switch {
+		case op == vm.SSTORE && len(stack) >= 2:
+			key := common.Hash(stack[len(stack)-1].Bytes32())
+			value := stack[len(stack)-2]
+			current := t.env.StateDB.GetState(scope.Contract.Address(), key)
+			if current == (common.Hash{}) && !value.IsZero() {
+				t.frames[n-1]++
+			} else if current != (common.Hash{}) && value.IsZero() {
+				t.frames[n-1]--
+			}
+		case op == vm.CALL && len(stack) >= 3:
+			addr := common.Address(stack[len(stack)-2].Bytes20())
+			t.newAccount = !stack[len(stack)-3].IsZero() && !t.env.StateDB.Exist(addr)
+		}
+	}
+	if t.inner != nil {
+		t.inner.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+	}
+}
+
+func (t *stateGrowthTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	if t.inner != nil {
+		t.inner.CaptureFault(pc, op, gas, cost, scope, depth, err)
+	}
+}
@@ -0,0 +1,72 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+)
+
+func TestStateGrowthTracer(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE), // set slot 0
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 1, byte(vm.SSTORE), // set slot 1
+		byte(vm.PUSH1), 2, byte(vm.PUSH1), 1, byte(vm.SSTORE), // overwrite slot 1
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.SSTORE), // clear slot 0
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.CREATE), // create an empty contract
+		byte(vm.STOP),
+	}
+
+	tracer := newStateGrowthTracer(nil)
+	_, _, err := runtime.Execute(code, nil, &runtime.Config{
+		EVMConfig: vm.Config{Debug: true, Tracer: tracer},
+	})
+	if err != nil {
+		t.Fatalf("failed to execute code: %v", err)
+	}
+	if growth := tracer.Growth(); growth != 2 {
+		t.Fatalf("unexpected state growth, expected 2, got %d", growth)
+	}
+
+	// state created by a reverted execution is not counted
+	tracer = newStateGrowthTracer(nil)
+	_, _, err = runtime.Execute([]byte{
+		byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE),
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.REVERT),
+	}, nil, &runtime.Config{
+		EVMConfig: vm.Config{Debug: true, Tracer: tracer},
+	})
+	if !errors.Is(err, vm.ErrExecutionReverted) {
+		t.Fatalf("expected execution to revert, got %v", err)
+	}
+	if growth := tracer.Growth(); growth != 0 {
+		t.Fatalf("unexpected state growth of reverted execution %d", growth)
+	}
+}
+
+func TestStateGrowthBudget(t *testing.T) {
+	algoConf := algorithmConfig{MaxStateGrowth: 10}
+	if err := checkStateGrowthBudget(algoConf, 8, 2); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := checkStateGrowthBudget(algoConf, 8, 3); !errors.Is(err, ErrStateGrowthBudget) {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	if err := checkStateGrowthBudget(algorithmConfig{}, 100, 100); err != nil {
+		t.Fatalf("unexpected error with unlimited budget %v", err)
+	}
+
+	config := StateGrowthConfig{MinProfitPerItem: big.NewInt(100)}
+	if !config.enabled() {
+		t.Fatal("expected state growth tracking to be enabled")
+	}
+	if !config.disproportionate(3, big.NewInt(299)) {
+		t.Fatal("expected bundle to be disproportionate")
+	}
+	if config.disproportionate(3, big.NewInt(300)) || config.disproportionate(0, common.Big0) {
+		t.Fatal("unexpected disproportionate bundle")
+	}
+}
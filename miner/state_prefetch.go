@@ -0,0 +1,251 @@
+package miner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	prefetchAccountsMeter = metrics.NewRegisteredMeter("miner/prefetch/accounts", nil)
+	prefetchSlotsMeter    = metrics.NewRegisteredMeter("miner/prefetch/slots", nil)
+	prefetchTimer         = metrics.NewRegisteredTimer("miner/prefetch/time", nil)
+	prefetchHitMeter      = metrics.NewRegisteredMeter("miner/prefetch/hit", nil)
+	prefetchMissMeter     = metrics.NewRegisteredMeter("miner/prefetch/miss", nil)
+	prefetchHitRateGauge  = metrics.NewRegisteredGauge("miner/prefetch/hitrate", nil) // Percent of the state touched by the simulations of a block which was prefetched
+)
+
+// statePrefetcher warms the caches of the state database on every new head with the trie nodes
+// and the contract code of the accounts and storage slots the bundles touched when they were
+// simulated on the previous head, before the bundles are simulated again on the new head. The
+// state of the bundles still pending in the pool is prefetched first, up to the budget of
+// accounts and storage slots. The methods are no-ops on a nil prefetcher.
+type statePrefetcher struct {
+	budget int
+
+	mu         sync.Mutex
+	simulated  uint64                           // Number of the block the accesses were recorded in
+	accesses   map[common.Hash]*state.StateDiff // State touched by the simulated bundles, by bundle hash
+	head       uint64                           // Number of the last head prefetched
+	prefetched map[common.Address]map[common.Hash]struct{}
+	hits       int // State touched by the simulations on top of the head which was prefetched
+	touched    int // State touched by the simulations on top of the head
+}
+
+func newStatePrefetcher(budget int) *statePrefetcher {
+	if budget <= 0 {
+		return nil
+	}
+	return &statePrefetcher{budget: budget, accesses: make(map[common.Hash]*state.StateDiff)}
+}
+
+// enabled reports whether the simulations must record the state they access for the prefetcher.
+func (p *statePrefetcher) enabled() bool {
+	return p != nil
+}
+
+// bundlesSimulated records the state accessed by the bundles simulated in a block, and measures
+// how much of it was prefetched if the block is built on the last head prefetched.
+func (p *statePrefetcher) bundlesSimulated(number uint64, bundles []types.MevBundle, accesses []*state.StateDiff) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if number > p.simulated {
+		p.simulated = number
+		p.accesses = make(map[common.Hash]*state.StateDiff)
+	} else if number < p.simulated {
+		return
+	}
+	var hits, touched int
+	for i, access := range accesses {
+		if access == nil {
+			continue
+		}
+		p.accesses[bundles[i].Hash] = access
+		if number != p.head+1 || p.prefetched == nil {
+			continue
+		}
+		forEachTouched(access, func(addr common.Address, slot *common.Hash) {
+			touched++
+			slots, ok := p.prefetched[addr]
+			if ok && slot != nil {
+				_, ok = slots[*slot]
+			}
+			if ok {
+				hits++
+			}
+		})
+	}
+	if touched == 0 {
+		return
+	}
+	p.hits += hits
+	p.touched += touched
+	if metrics.EnabledBuilder {
+		prefetchHitMeter.Mark(int64(hits))
+		prefetchMissMeter.Mark(int64(touched - hits))
+		prefetchHitRateGauge.Update(int64(100 * p.hits / p.touched))
+	}
+}
+
+// forEachTouched calls fn with every account touched by a bundle, with a nil slot, and with every
+// storage slot it touched.
+func forEachTouched(access *state.StateDiff, fn func(addr common.Address, slot *common.Hash)) {
+	visit := func(addr common.Address) {
+		fn(addr, nil)
+		seen := make(map[common.Hash]struct{})
+		each := func(slots []common.Hash) {
+			for i := range slots {
+				if _, ok := seen[slots[i]]; !ok {
+					seen[slots[i]] = struct{}{}
+					fn(addr, &slots[i])
+				}
+			}
+		}
+		each(access.Reads[addr])
+		if account, ok := access.Accounts[addr]; ok {
+			each(account.Storage)
+		}
+	}
+	for addr := range access.Reads {
+		visit(addr)
+	}
+	for addr := range access.Accounts {
+		if _, ok := access.Reads[addr]; !ok {
+			visit(addr)
+		}
+	}
+}
+
+// bundleHashes returns the hashes of the bundles.
+func bundleHashes(bundles []types.MevBundle) []common.Hash {
+	hashes := make([]common.Hash, len(bundles))
+	for i := range bundles {
+		hashes[i] = bundles[i].Hash
+	}
+	return hashes
+}
+
+// plan returns the accounts and storage slots to prefetch on top of a new head, the state of the
+// pending bundles first, up to the budget. It returns nil if the head was already prefetched.
+func (p *statePrefetcher) plan(head uint64, pending []common.Hash) map[common.Address]map[common.Hash]struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if head <= p.head {
+		return nil
+	}
+	p.head = head
+	p.hits, p.touched = 0, 0
+
+	var (
+		plan  = make(map[common.Address]map[common.Hash]struct{})
+		items int
+	)
+	add := func(access *state.StateDiff) {
+		forEachTouched(access, func(addr common.Address, slot *common.Hash) {
+			if items >= p.budget {
+				return
+			}
+			slots, ok := plan[addr]
+			if !ok {
+				slots = make(map[common.Hash]struct{})
+				plan[addr] = slots
+				items++
+			}
+			if slot != nil && items < p.budget {
+				if _, ok := slots[*slot]; !ok {
+					slots[*slot] = struct{}{}
+					items++
+				}
+			}
+		})
+	}
+	done := make(map[common.Hash]struct{}, len(pending))
+	for _, hash := range pending {
+		if access, ok := p.accesses[hash]; ok {
+			add(access)
+			done[hash] = struct{}{}
+		}
+	}
+	for hash, access := range p.accesses {
+		if _, ok := done[hash]; !ok {
+			add(access)
+		}
+	}
+	p.prefetched = plan
+	return plan
+}
+
+// stale reports whether a newer head than head is being prefetched.
+func (p *statePrefetcher) stale(head uint64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.head != head
+}
+
+// chainHead prefetches, in the background, the state the bundles touched on the previous head
+// from the state of the new head. The pending bundles are the hashes of the bundles in the pool.
+func (p *statePrefetcher) chainHead(head *types.Block, db state.Database, pending []common.Hash) {
+	if p == nil {
+		return
+	}
+	plan := p.plan(head.NumberU64(), pending)
+	if len(plan) == 0 {
+		return
+	}
+	go p.prefetch(head, db, plan)
+}
+
+// prefetch reads the trie nodes and the contract code of the planned accounts and storage slots
+// from the state of the head, until a newer head is prefetched.
+func (p *statePrefetcher) prefetch(head *types.Block, db state.Database, plan map[common.Address]map[common.Hash]struct{}) {
+	start := time.Now()
+	tr, err := db.OpenTrie(head.Root())
+	if err != nil {
+		log.Debug("Failed to open the state to prefetch", "number", head.NumberU64(), "err", err)
+		return
+	}
+	var accounts, slots int
+	for addr, keys := range plan {
+		if p.stale(head.NumberU64()) {
+			break
+		}
+		account, err := tr.TryGetAccount(addr)
+		accounts++
+		if err != nil || account == nil {
+			continue
+		}
+		addrHash := crypto.Keccak256Hash(addr.Bytes())
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != types.EmptyCodeHash {
+			db.ContractCode(addrHash, codeHash)
+		}
+		if len(keys) == 0 || account.Root == types.EmptyRootHash {
+			continue
+		}
+		storage, err := db.OpenStorageTrie(head.Root(), addrHash, account.Root)
+		if err != nil {
+			continue
+		}
+		for key := range keys {
+			storage.TryGet(key.Bytes())
+			slots++
+		}
+	}
+	if metrics.EnabledBuilder {
+		prefetchAccountsMeter.Mark(int64(accounts))
+		prefetchSlotsMeter.Mark(int64(slots))
+		prefetchTimer.UpdateSince(start)
+	}
+	log.Debug("Prefetched bundle state", "number", head.NumberU64(), "accounts", accounts, "slots", slots, "elapsed", time.Since(start))
+}
@@ -0,0 +1,86 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestStatePrefetcher(t *testing.T) {
+	// a disabled prefetcher records nothing
+	disabled := newStatePrefetcher(0)
+	if disabled.enabled() {
+		t.Fatal("prefetcher enabled without budget")
+	}
+	disabled.bundlesSimulated(1, nil, nil)
+	disabled.chainHead(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)}), nil, nil)
+
+	var (
+		addr1, addr2, addr3 = common.Address{0x1}, common.Address{0x2}, common.Address{0x3}
+		slot1, slot2        = common.Hash{0x1}, common.Hash{0x2}
+		bundles             = []types.MevBundle{{Hash: common.Hash{0xa}}, {Hash: common.Hash{0xb}}}
+		accesses            = []*state.StateDiff{
+			{
+				Accounts: map[common.Address]*state.TouchedAccount{addr1: {Storage: []common.Hash{slot1}}},
+				Reads:    map[common.Address][]common.Hash{addr1: {slot1, slot2}},
+			},
+			{
+				Accounts: map[common.Address]*state.TouchedAccount{addr2: {Balance: true}, addr3: {Balance: true}},
+			},
+		}
+	)
+	p := newStatePrefetcher(3)
+	p.bundlesSimulated(1, bundles, accesses)
+
+	// the state of the pending bundles is prefetched first, up to the budget
+	plan := p.plan(1, []common.Hash{bundles[1].Hash})
+	if len(plan) != 3 {
+		t.Fatalf("unexpected plan %v", plan)
+	}
+	for _, addr := range []common.Address{addr2, addr3} {
+		if _, ok := plan[addr]; !ok {
+			t.Fatalf("pending bundle account %s not planned", addr)
+		}
+	}
+	if len(plan[addr1]) != 0 {
+		t.Fatalf("slots planned over the budget %v", plan[addr1])
+	}
+	if p.plan(1, nil) != nil {
+		t.Fatal("head planned twice")
+	}
+
+	// the hit rate counts the state of the simulations on the next block which was prefetched
+	p.bundlesSimulated(2, bundles, accesses)
+	if p.hits != 3 || p.touched != 5 {
+		t.Fatalf("unexpected hit rate %d/%d", p.hits, p.touched)
+	}
+	if _, ok := p.accesses[bundles[0].Hash]; !ok || len(p.accesses) != 2 {
+		t.Fatalf("unexpected accesses %v", p.accesses)
+	}
+
+	// the planned state is read from the state of the head
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	statedb, _ := state.New(types.EmptyRootHash, db, nil)
+	statedb.SetState(addr1, slot1, common.Hash{0x1})
+	statedb.SetCode(addr1, []byte{0x1})
+	statedb.AddBalance(addr2, big.NewInt(1))
+	root, err := statedb.Commit(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.TrieDB().Commit(root, false); err != nil {
+		t.Fatal(err)
+	}
+	p = newStatePrefetcher(10)
+	p.bundlesSimulated(1, bundles, accesses)
+	head := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(2), Root: root})
+	plan = p.plan(head.NumberU64(), nil)
+	if len(plan) != 3 || len(plan[addr1]) != 2 {
+		t.Fatalf("unexpected plan %v", plan)
+	}
+	p.prefetch(head, db, plan)
+}
@@ -0,0 +1,166 @@
+package miner
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/builder/heimdall"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var (
+	// stateSyncSystemAddress is the sender of the state-sync commits.
+	stateSyncSystemAddress = common.HexToAddress("0xffffFFFfFFffffffffffffffFfFFFfffFFFfFFfE")
+
+	stateReceiverABI, _ = abi.JSON(strings.NewReader(`[
+		{"name":"lastStateId","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+		{"name":"commitState","type":"function","stateMutability":"nonpayable","inputs":[{"name":"syncTime","type":"uint256"},{"name":"recordBytes","type":"bytes"}],"outputs":[{"name":"success","type":"bool"}]}
+	]`))
+)
+
+// defaultStateSyncConfig commits the state-sync events to the Bor state receiver contract.
+var defaultStateSyncConfig = StateSyncConfig{
+	Sprint:            16,
+	Receiver:          common.HexToAddress("0x0000000000000000000000000000000000001001"),
+	ConfirmationDelay: 128 * time.Second,
+	GasPerEvent:       5_000_000,
+	MaxEvents:         50,
+}
+
+// StateSyncConfig configures the inclusion of the Heimdall state-sync events in the first block
+// of each Bor sprint, the way a Bor node commits them when it seals the block. The events are
+// committed if Heimdall endpoints are configured.
+type StateSyncConfig struct {
+	Sprint            uint64         // Blocks per sprint, the events are committed in the first block of a sprint
+	Receiver          common.Address // State receiver contract the events are committed to
+	ConfirmationDelay time.Duration  // Age of the events committed in a block, relative to the block timestamp
+	GasPerEvent       uint64         // Gas of the system call committing an event, not taken from the block gas
+	MaxEvents         int            // Events fetched per request to Heimdall
+}
+
+// stateSyncRecord returns the RLP encoding of the event committed to the state receiver.
+func stateSyncRecord(e *heimdall.EventRecord) ([]byte, error) {
+	return rlp.EncodeToBytes([]interface{}{e.ID, e.Contract, []byte(e.Data), e.TxHash, e.LogIndex, e.ChainID})
+}
+
+// stateSyncer commits the pending Heimdall state-sync events at the end of the blocks starting a
+// sprint. The events are fetched through the Heimdall client shared by all workers, a nil
+// instance commits nothing.
+type stateSyncer struct {
+	config  StateSyncConfig
+	chainID string
+	client  *heimdall.Client
+}
+
+// stateSyncQuery selects the events committed in a block.
+type stateSyncQuery struct {
+	fromID uint64
+	toTime int64
+}
+
+// newStateSyncer returns the state syncer of the config, nil without a Heimdall client.
+func newStateSyncer(config StateSyncConfig, client *heimdall.Client, chainConfig *params.ChainConfig) *stateSyncer {
+	if client == nil || config.Sprint == 0 {
+		return nil
+	}
+	if config.MaxEvents <= 0 {
+		config.MaxEvents = defaultStateSyncConfig.MaxEvents
+	}
+	return &stateSyncer{
+		config:  config,
+		chainID: chainConfig.ChainID.String(),
+		client:  client,
+	}
+}
+
+// commit applies the pending state-sync events to the block if it starts a sprint. The events
+// run as system calls after the transactions of the block, with their own gas.
+func (s *stateSyncer) commit(env *environment, chain core.ChainContext, chainConfig *params.ChainConfig) error {
+	if s == nil || env.header.Number.Uint64()%s.config.Sprint != 0 {
+		return nil
+	}
+	blockContext := core.NewEVMBlockContext(env.header, chain, nil)
+	evm := vm.NewEVM(blockContext, vm.TxContext{}, env.state, chainConfig, vm.Config{NoBaseFee: true})
+
+	lastID, err := s.lastStateID(evm)
+	if err != nil {
+		return err
+	}
+	query := stateSyncQuery{
+		fromID: lastID + 1,
+		toTime: int64(env.header.Time) - int64(s.config.ConfirmationDelay/time.Second),
+	}
+	events, err := s.pendingEvents(query)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		record, err := stateSyncRecord(event)
+		if err != nil {
+			return err
+		}
+		input, err := stateReceiverABI.Pack("commitState", new(big.Int).SetInt64(event.RecordTime.Unix()), record)
+		if err != nil {
+			return err
+		}
+		// a reverted commit is skipped by the receiver contract, as on a Bor node
+		if _, _, err := evm.Call(vm.AccountRef(stateSyncSystemAddress), s.config.Receiver, input, s.config.GasPerEvent, common.Big0); err != nil {
+			log.Warn("State-sync event commit failed", "id", event.ID, "block", env.header.Number, "err", err)
+		}
+		env.state.Finalise(true)
+	}
+	if len(events) > 0 {
+		log.Debug("Committed state-sync events", "block", env.header.Number, "from", query.fromID, "events", len(events))
+	}
+	return nil
+}
+
+// lastStateID returns the id of the last event committed to the state receiver.
+func (s *stateSyncer) lastStateID(evm *vm.EVM) (uint64, error) {
+	input, err := stateReceiverABI.Pack("lastStateId")
+	if err != nil {
+		return 0, err
+	}
+	output, _, err := evm.StaticCall(vm.AccountRef(stateSyncSystemAddress), s.config.Receiver, input, s.config.GasPerEvent)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the last state id: %w", err)
+	}
+	values, err := stateReceiverABI.Unpack("lastStateId", output)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the last state id: %w", err)
+	}
+	return values[0].(*big.Int).Uint64(), nil
+}
+
+// pendingEvents returns the events of the query, consecutive from the first id and recorded
+// before the query time for the chain.
+func (s *stateSyncer) pendingEvents(query stateSyncQuery) ([]*heimdall.EventRecord, error) {
+	var events []*heimdall.EventRecord
+	for {
+		page, err := s.client.StateSyncEvents(context.Background(), query.fromID+uint64(len(events)), query.toTime, s.config.MaxEvents)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range page {
+			if event.ID != query.fromID+uint64(len(events)) {
+				return nil, fmt.Errorf("unexpected state-sync event %d, expected %d", event.ID, query.fromID+uint64(len(events)))
+			}
+			if event.ChainID != s.chainID || event.RecordTime.Unix() >= query.toTime {
+				return nil, fmt.Errorf("state-sync event %d of chain %s recorded at %v is not committable", event.ID, event.ChainID, event.RecordTime)
+			}
+			events = append(events, event)
+		}
+		if len(page) < s.config.MaxEvents {
+			return events, nil
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package miner
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/builder/heimdall"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestStateSyncEvents(t *testing.T) {
+	recorded := time.Unix(1000, 0).UTC()
+	events := []*heimdall.EventRecord{
+		{ID: 5, ChainID: "1337", RecordTime: recorded},
+		{ID: 6, ChainID: "1337", RecordTime: recorded},
+		{ID: 7, ChainID: "1337", RecordTime: recorded},
+	}
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/clerk/event-record/list" || r.URL.Query().Get("to-time") != "2000" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		from, _ := strconv.ParseUint(r.URL.Query().Get("from-id"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		page := []*heimdall.EventRecord{}
+		for _, event := range events {
+			if event.ID >= from && len(page) < limit {
+				page = append(page, event)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"height": "1", "result": page})
+	}))
+	defer srv.Close()
+
+	client, err := heimdall.New(heimdall.Config{Endpoints: []string{srv.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := defaultStateSyncConfig
+	config.MaxEvents = 2
+	chainConfig := *params.TestChainConfig
+	chainConfig.ChainID = big.NewInt(1337)
+	syncer := newStateSyncer(config, client, &chainConfig)
+
+	// the pending events are fetched page by page and cached for the workers
+	query := stateSyncQuery{fromID: 5, toTime: 2000}
+	pending, err := syncer.pendingEvents(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 3 || pending[2].ID != 7 {
+		t.Fatalf("pending events %v, want 5 to 7", pending)
+	}
+	if requests != 2 {
+		t.Errorf("events fetched in %d requests, want 2", requests)
+	}
+	if _, err := syncer.pendingEvents(query); err != nil || requests != 2 {
+		t.Errorf("cached events fetched again, %d requests, err %v", requests, err)
+	}
+
+	// the events must follow the last committed event and belong to the chain
+	if _, err := syncer.pendingEvents(stateSyncQuery{fromID: 4, toTime: 2000}); err == nil {
+		t.Error("events not following the last committed event accepted")
+	}
+	events[1].ChainID = "137"
+	if _, err := syncer.pendingEvents(stateSyncQuery{fromID: 6, toTime: 2000}); err == nil {
+		t.Error("event of another chain accepted")
+	}
+
+	// only the first block of a sprint commits the events
+	env := &environment{header: &types.Header{Number: big.NewInt(17)}}
+	if err := syncer.commit(env, nil, &chainConfig); err != nil {
+		t.Errorf("block inside a sprint commits events: %v", err)
+	}
+	var disabled *stateSyncer
+	if err := disabled.commit(env, nil, &chainConfig); err != nil {
+		t.Error(err)
+	}
+	if newStateSyncer(defaultStateSyncConfig, nil, &chainConfig) != nil {
+		t.Error("state sync enabled without a Heimdall endpoint")
+	}
+}
@@ -0,0 +1,202 @@
+package miner
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/builder/auditlog"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	topOfBlockUnfilledMeter = metrics.NewRegisteredMeter("miner/topofblock/unfilled", nil)
+	topOfBlockUsedGasHist   = metrics.NewRegisteredHistogram("miner/topofblock/used", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// TopOfBlockConfig reserves the first gas of the blocks to the orders of designated searchers
+// and contracts, e.g. oracle updates. The reserved gas left unused is released to the other
+// orders.
+type TopOfBlockConfig struct {
+	Gas       uint64           // Gas reserved at the top of the block, 0 = disabled
+	Searchers []common.Address `toml:",omitempty"` // Searchers whose bundles and transactions may use the reserved gas
+	Contracts []common.Address `toml:",omitempty"` // Contracts the bundles and transactions using the reserved gas call
+}
+
+// Validate checks a reservation designates the orders allowed to use it.
+func (c *TopOfBlockConfig) Validate() error {
+	if c.Gas != 0 && len(c.Searchers) == 0 && len(c.Contracts) == 0 {
+		return errors.New("top of block reservation without searchers or contracts")
+	}
+	return nil
+}
+
+// topOfBlockReservation splits the orders of a block between the ones allowed to use the gas
+// reserved at the top of the block and the others. It is shared by all workers and safe for
+// concurrent use, a nil instance reserves nothing.
+type topOfBlockReservation struct {
+	mu        sync.Mutex
+	config    TopOfBlockConfig
+	searchers map[common.Address]struct{}
+	contracts map[common.Address]struct{}
+}
+
+// newTopOfBlockReservation returns the reservation of the config, reserving nothing if it is
+// invalid.
+func newTopOfBlockReservation(config TopOfBlockConfig) *topOfBlockReservation {
+	r := new(topOfBlockReservation)
+	if err := r.setConfig(config); err != nil {
+		log.Error("Invalid top of block reservation, nothing is reserved", "err", err)
+	}
+	return r
+}
+
+// setConfig replaces the reservation of the blocks built from now on.
+func (r *topOfBlockReservation) setConfig(config TopOfBlockConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	searchers := make(map[common.Address]struct{}, len(config.Searchers))
+	for _, searcher := range config.Searchers {
+		searchers[searcher] = struct{}{}
+	}
+	contracts := make(map[common.Address]struct{}, len(config.Contracts))
+	for _, contract := range config.Contracts {
+		contracts[contract] = struct{}{}
+	}
+	config.Searchers = append([]common.Address{}, config.Searchers...)
+	config.Contracts = append([]common.Address{}, config.Contracts...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.config, r.searchers, r.contracts = config, searchers, contracts
+	return nil
+}
+
+// getConfig returns a copy of the current reservation.
+func (r *topOfBlockReservation) getConfig() TopOfBlockConfig {
+	if r == nil {
+		return TopOfBlockConfig{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	config := r.config
+	config.Searchers = append([]common.Address{}, config.Searchers...)
+	config.Contracts = append([]common.Address{}, config.Contracts...)
+	return config
+}
+
+// split returns the reserved gas, the bundles and the mempool transactions allowed to use it,
+// and the bundles that are not. A bundle is allowed if it was sent by a designated searcher or
+// all its transactions call designated contracts. The transactions of an account are allowed if
+// it is a designated searcher, otherwise only its leading transactions calling designated
+// contracts are, to keep its nonces in order.
+func (r *topOfBlockReservation) split(bundles []types.SimulatedBundle, pending map[common.Address]types.Transactions) (uint64, []types.SimulatedBundle, []types.SimulatedBundle, map[common.Address]types.Transactions) {
+	if r == nil {
+		return 0, nil, bundles, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.config.Gas == 0 {
+		return 0, nil, bundles, nil
+	}
+	var reserved, others []types.SimulatedBundle
+	for _, bundle := range bundles {
+		if r.reservedBundle(bundle.OriginalBundle) {
+			reserved = append(reserved, bundle)
+		} else {
+			others = append(others, bundle)
+		}
+	}
+	txs := make(map[common.Address]types.Transactions)
+	for from, accountTxs := range pending {
+		if _, ok := r.searchers[from]; ok {
+			txs[from] = accountTxs
+			continue
+		}
+		n := 0
+		for n < len(accountTxs) && r.reservedCall(accountTxs[n]) {
+			n++
+		}
+		if n > 0 {
+			txs[from] = accountTxs[:n]
+		}
+	}
+	return r.config.Gas, reserved, others, txs
+}
+
+// reservedBundle reports whether the bundle may use the reserved gas, the lock must be held.
+func (r *topOfBlockReservation) reservedBundle(bundle types.MevBundle) bool {
+	if _, ok := r.searchers[bundle.SigningAddress]; ok {
+		return true
+	}
+	if len(bundle.Txs) == 0 {
+		return false
+	}
+	for _, tx := range bundle.Txs {
+		if !r.reservedCall(tx) {
+			return false
+		}
+	}
+	return true
+}
+
+// reservedCall reports whether the transaction calls a designated contract, the lock must be held.
+func (r *topOfBlockReservation) reservedCall(tx *types.Transaction) bool {
+	if tx.To() == nil {
+		return false
+	}
+	_, ok := r.contracts[*tx.To()]
+	return ok
+}
+
+// fillTopOfBlock packs the orders allowed to use the gas reserved at the top of the block, with
+// the block building algorithm of the worker limited to the reserved gas. The reserved gas left
+// unused is released to the other orders and audited. It returns the bundles packed and the
+// reserved bundles left out, which compete with the other bundles for the rest of the block.
+func (w *worker) fillTopOfBlock(env *environment, interrupt *int32, gas uint64, bundles []types.SimulatedBundle, txs map[common.Address]types.Transactions) ([]types.SimulatedBundle, []types.SimulatedBundle, error) {
+	available := env.gasPool.Gas()
+	if gas > available {
+		gas = available
+	}
+	env.gasPool.SetGas(gas)
+	builder, err := w.newBlockBuilder(w.algorithm(), env, interrupt, w.flashbots.griefing)
+	if err != nil {
+		env.gasPool.SetGas(available)
+		return nil, bundles, err
+	}
+	newEnv, blockBundles, _ := builder.buildBlock(bundles, nil, txs)
+	used := gas - newEnv.gasPool.Gas()
+	newEnv.gasPool.SetGas(available - used)
+	*env = *newEnv
+
+	if metrics.EnabledBuilder {
+		topOfBlockUsedGasHist.Update(int64(used))
+	}
+	if used < gas {
+		if metrics.EnabledBuilder {
+			topOfBlockUnfilledMeter.Mark(1)
+		}
+		log.Debug("Top of block reservation unfilled", "round", env.round.ID(), "number", env.header.Number, "reserved", gas, "used", used)
+		if auditlog.Enabled() {
+			auditTopOfBlockUnfilled(env.header, gas, used)
+		}
+	}
+
+	included := make(map[common.Hash]struct{}, len(blockBundles))
+	for _, bundle := range blockBundles {
+		included[bundle.OriginalBundle.Hash] = struct{}{}
+	}
+	var left []types.SimulatedBundle
+	for _, bundle := range bundles {
+		if _, ok := included[bundle.OriginalBundle.Hash]; !ok {
+			left = append(left, bundle)
+		}
+	}
+	return blockBundles, left, nil
+}
@@ -0,0 +1,68 @@
+package miner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestTopOfBlockSplit(t *testing.T) {
+	var (
+		searcher = common.HexToAddress("0x5e")
+		oracle   = common.HexToAddress("0x0a")
+		other    = common.HexToAddress("0x07")
+		user     = common.HexToAddress("0x01")
+	)
+	call := func(nonce uint64, to common.Address) *types.Transaction {
+		return types.NewTransaction(nonce, to, new(big.Int), 21000, big.NewInt(1), nil)
+	}
+	bundle := func(signer common.Address, txs ...*types.Transaction) types.SimulatedBundle {
+		return types.SimulatedBundle{OriginalBundle: types.MevBundle{Txs: txs, SigningAddress: signer, Hash: common.BigToHash(big.NewInt(int64(len(txs))))}}
+	}
+	bundles := []types.SimulatedBundle{
+		bundle(searcher, call(0, other)),
+		bundle(user, call(0, oracle), call(1, oracle)),
+		bundle(user, call(0, oracle), call(1, other), call(2, oracle)),
+	}
+	pending := map[common.Address]types.Transactions{
+		searcher: {call(0, other), call(1, oracle)},
+		user:     {call(0, oracle), call(1, other), call(2, oracle)},
+		other:    {call(0, other)},
+	}
+
+	// a nil or disabled reservation reserves nothing
+	var disabled *topOfBlockReservation
+	if gas, reserved, others, _ := disabled.split(bundles, pending); gas != 0 || len(reserved) != 0 || len(others) != len(bundles) {
+		t.Fatal("nil reservation reserved gas")
+	}
+	r := newTopOfBlockReservation(TopOfBlockConfig{})
+	if gas, _, _, _ := r.split(bundles, pending); gas != 0 {
+		t.Fatal("disabled reservation reserved gas")
+	}
+
+	if err := r.setConfig(TopOfBlockConfig{Gas: 100_000}); err == nil {
+		t.Fatal("reservation without searchers or contracts accepted")
+	}
+	if err := r.setConfig(TopOfBlockConfig{Gas: 100_000, Searchers: []common.Address{searcher}, Contracts: []common.Address{oracle}}); err != nil {
+		t.Fatal(err)
+	}
+	gas, reserved, others, txs := r.split(bundles, pending)
+	if gas != 100_000 {
+		t.Errorf("reserved gas %d, want %d", gas, 100_000)
+	}
+	// the bundle of the searcher and the one calling only the oracle use the reservation
+	if len(reserved) != 2 || len(others) != 1 || others[0].OriginalBundle.Hash != bundles[2].OriginalBundle.Hash {
+		t.Errorf("unexpected split of the bundles, %d reserved and %d others", len(reserved), len(others))
+	}
+	// all the transactions of the searcher, the leading oracle calls of the user
+	if len(txs) != 2 || len(txs[searcher]) != 2 || len(txs[user]) != 1 {
+		t.Errorf("unexpected reserved transactions %v", txs)
+	}
+
+	config := r.getConfig()
+	if config.Gas != 100_000 || len(config.Searchers) != 1 || len(config.Contracts) != 1 {
+		t.Errorf("unexpected config %+v", config)
+	}
+}
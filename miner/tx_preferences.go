@@ -0,0 +1,142 @@
+package miner
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	errTxFrontrun   = errors.New("bundle frontruns a backrun only private transaction")
+	errTxSandwiched = errors.New("bundle sandwiches a no sandwich private transaction")
+
+	txPreferencesRejectedMeter = metrics.NewRegisteredMeter("miner/bundles/txpreferences", nil)
+)
+
+// txPreferencesFn returns the preferences of the sender of a private transaction on the bundles
+// including it, false if it has none.
+type txPreferencesFn func(common.Hash) (types.PrivateTxPreferences, bool)
+
+// txPreferences returns the lookup of the private transaction preferences, nil for the bench
+// workers which have no transaction pool.
+func (w *worker) txPreferences() txPreferencesFn {
+	if w.eth == nil {
+		return nil
+	}
+	return w.eth.TxPool().PrivateTxPreferences
+}
+
+// checkTxPreferences returns an error if the transactions of a bundle break the preferences of a
+// private transaction of theirs. The transactions of a bundle are adjacent in the block: the
+// transaction of the bundle before a private transaction is placed right before it, frontrunning
+// it, and the one after it right after it, backrunning it. A nil preferences disables the check.
+func checkTxPreferences(preferences txPreferencesFn, txs []*types.Transaction) error {
+	if preferences == nil {
+		return nil
+	}
+	for i, tx := range txs {
+		prefs, ok := preferences(tx.Hash())
+		if !ok {
+			continue
+		}
+		frontrun, backrun := i > 0, i < len(txs)-1
+		var err error
+		switch {
+		case prefs.BackrunOnly && frontrun:
+			err = errTxFrontrun
+		case prefs.NoSandwich && frontrun && backrun:
+			err = errTxSandwiched
+		}
+		if err != nil {
+			return rejectTxPreferences(err, tx.Hash())
+		}
+	}
+	return nil
+}
+
+// checkBlockTxPreferences returns an error if the transactions of a bundle, committed right after
+// the last two transactions of the block, front and victim in tail, break the preferences of the
+// victim. The builder-only transactions are committed at the top of the block, before the bundles,
+// so the bundles backrunning one can only be placed right after it. The victim is frontrun when the
+// transaction before it is sent by a sender of the bundle, or when it trades on a pool of the
+// victim in the same direction and the bundle trades back on the pool, as the sandwiches rejected
+// by the anti-sandwich policy.
+func checkBlockTxPreferences(preferences txPreferencesFn, tail []BundleTx, txs []BundleTx) error {
+	if preferences == nil || len(tail) < 2 || len(txs) == 0 {
+		return nil
+	}
+	front, victim := tail[0], tail[1]
+	prefs, ok := preferences(victim.Tx.Hash())
+	if !ok || !frontrunFor(front, victim, txs) {
+		return nil
+	}
+	switch {
+	case prefs.BackrunOnly:
+		return rejectTxPreferences(errTxFrontrun, victim.Tx.Hash())
+	case prefs.NoSandwich:
+		return rejectTxPreferences(errTxSandwiched, victim.Tx.Hash())
+	}
+	return nil
+}
+
+// frontrunFor reports whether front frontruns victim for the bundle of txs backrunning it.
+func frontrunFor(front, victim BundleTx, txs []BundleTx) bool {
+	if front.From != victim.From {
+		for _, tx := range txs {
+			if tx.From == front.From {
+				return true
+			}
+		}
+	}
+	var backSwaps []poolSwap
+	for _, tx := range txs {
+		backSwaps = append(backSwaps, poolSwaps(tx.Receipt)...)
+	}
+	_, sandwiched := sandwichedSwap(poolSwaps(victim.Receipt), poolSwaps(front.Receipt), backSwaps)
+	return sandwiched
+}
+
+// blockTail returns the last two transactions of a block made of the base transactions followed
+// by the added ones, with their receipts.
+func blockTail(signer types.Signer, baseTxs []*types.Transaction, baseReceipts []*types.Receipt, addedTxs []*types.Transaction, addedReceipts []*types.Receipt) []BundleTx {
+	var tail []BundleTx
+	for i := len(baseTxs) + len(addedTxs) - 2; i < len(baseTxs)+len(addedTxs); i++ {
+		if i < 0 {
+			continue
+		}
+		txs, receipts, j := baseTxs, baseReceipts, i
+		if i >= len(baseTxs) {
+			txs, receipts, j = addedTxs, addedReceipts, i-len(baseTxs)
+		}
+		from, _ := types.Sender(signer, txs[j])
+		tx := BundleTx{Tx: txs[j], From: from}
+		if j < len(receipts) {
+			tx.Receipt = receipts[j]
+		}
+		tail = append(tail, tx)
+	}
+	return tail
+}
+
+// committedTxs returns the transactions committed to a block with their senders and receipts.
+func committedTxs(signer types.Signer, txs []*types.Transaction, receipts []*types.Receipt) []BundleTx {
+	committed := make([]BundleTx, len(txs))
+	for i, tx := range txs {
+		from, _ := types.Sender(signer, tx)
+		committed[i] = BundleTx{Tx: tx, From: from}
+		if i < len(receipts) {
+			committed[i].Receipt = receipts[i]
+		}
+	}
+	return committed
+}
+
+func rejectTxPreferences(err error, hash common.Hash) error {
+	if metrics.EnabledBuilder {
+		txPreferencesRejectedMeter.Mark(1)
+	}
+	return fmt.Errorf("%w: %s", err, hash)
+}
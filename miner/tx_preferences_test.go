@@ -0,0 +1,160 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func TestTxPreferences(t *testing.T) {
+	statedb, chData, signers := genTestSetup(GasLimit)
+	newEnv := func() *environment {
+		return newEnvironment(chData, statedb.Copy(), signers.addresses[0], GasLimit, big.NewInt(1))
+	}
+
+	var (
+		front   = signers.signTx(1, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(0), []byte{})
+		victim  = signers.signTx(3, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(0), []byte{})
+		back    = signers.signTx(5, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(0), []byte{})
+		noPrefs = signers.signTx(4, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(0), []byte{})
+		prefs   = map[common.Hash]types.PrivateTxPreferences{victim.Hash(): {NoSandwich: true}}
+	)
+	algoConf := defaultAlgorithmConfig
+	algoConf.TxPreferences = func(hash common.Hash) (types.PrivateTxPreferences, bool) {
+		p, ok := prefs[hash]
+		return p, ok
+	}
+	bundle := func(txs ...*types.Transaction) *types.SimulatedBundle {
+		env := newEnv()
+		simBundle, err := simulateBundle(env, types.MevBundle{Txs: txs, BlockNumber: env.header.Number}, chData, nil)
+		if err != nil {
+			t.Fatal("Failed to simulate bundle", err)
+		}
+		return &simBundle
+	}
+	sandwich := bundle(front, victim, back)
+
+	// a protected transaction is never placed between two transactions of the same bundle
+	if err := newEnvironmentDiff(newEnv()).commitBundle(sandwich, chData, nil, algoConf); !errors.Is(err, errTxSandwiched) {
+		t.Fatalf("sandwich committed, err %v", err)
+	}
+	changes, err := newEnvChanges(newEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = changes.commitBundle(sandwich, chData, algoConf); !errors.Is(err, errTxSandwiched) {
+		t.Fatalf("sandwich committed, err %v", err)
+	}
+	if len(changes.txs) != 0 {
+		t.Fatalf("sandwich left %d txs", len(changes.txs))
+	}
+	if reason, rejected := classifySimulationFailure(err); reason != bundleRejectedTxPrefs || !rejected {
+		t.Fatalf("unexpected rejection %s", reason)
+	}
+
+	// it can be frontrun or backrun alone
+	if err := newEnvironmentDiff(newEnv()).commitBundle(bundle(front, victim), chData, nil, algoConf); err != nil {
+		t.Fatal("Failed to commit frontrun", err)
+	}
+	if err := newEnvironmentDiff(newEnv()).commitBundle(bundle(victim, back), chData, nil, algoConf); err != nil {
+		t.Fatal("Failed to commit backrun", err)
+	}
+
+	// a backrun only transaction can't be frontrun
+	prefs[victim.Hash()] = types.PrivateTxPreferences{BackrunOnly: true}
+	if err := newEnvironmentDiff(newEnv()).commitBundle(bundle(front, victim), chData, nil, algoConf); !errors.Is(err, errTxFrontrun) {
+		t.Fatalf("frontrun committed, err %v", err)
+	}
+	if err := newEnvironmentDiff(newEnv()).commitBundle(bundle(victim, back), chData, nil, algoConf); err != nil {
+		t.Fatal("Failed to commit backrun", err)
+	}
+
+	// transactions without preferences are not checked
+	if err := newEnvironmentDiff(newEnv()).commitBundle(bundle(front, noPrefs, back), chData, nil, algoConf); err != nil {
+		t.Fatal("Failed to commit bundle", err)
+	}
+}
+
+func TestBlockTxPreferences(t *testing.T) {
+	// the pool emits a uniswap v2 swap log with the calldata as log data
+	var (
+		config   = params.AllEthashProtocolChanges
+		signers  = genSignerList(10, config)
+		pool     = common.HexToAddress("0x10")
+		poolCode = append(append(common.FromHex("0x3660006000377f"), uniswapV2SwapTopic.Bytes()...), common.FromHex("0x366000a100")...)
+	)
+	statedb, chData := genTestSetupWithAlloc(config, genGenesisAlloc(signers, []common.Address{pool}, [][]byte{poolCode}), GasLimit)
+
+	var (
+		swap = func(i int, token0ToOne bool) *types.Transaction {
+			return signers.signTx(i, 100000, big.NewInt(0), big.NewInt(1), pool, big.NewInt(0), v2SwapLog(pool, token0ToOne).Data)
+		}
+		front    = swap(6, true)
+		victim   = swap(7, true)
+		back     = signers.signTx(6, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(0), []byte{})
+		other    = signers.signTx(8, 21000, big.NewInt(0), big.NewInt(1), signers.addresses[2], big.NewInt(0), []byte{})
+		swapBack = swap(9, false)
+		prefs    = map[common.Hash]types.PrivateTxPreferences{victim.Hash(): {NoSandwich: true}}
+	)
+	algoConf := defaultAlgorithmConfig
+	algoConf.TxPreferences = func(hash common.Hash) (types.PrivateTxPreferences, bool) {
+		p, ok := prefs[hash]
+		return p, ok
+	}
+	// the builder-only transactions are committed at the top of the block, before the bundles
+	laneEnv := func() *environment {
+		env := newEnvironment(chData, statedb.Copy(), signers.addresses[0], GasLimit, big.NewInt(1))
+		envDiff := newEnvironmentDiff(env)
+		for _, tx := range []*types.Transaction{front, victim} {
+			if _, _, err := envDiff.commitTx(tx, chData); err != nil {
+				t.Fatal("Failed to commit lane tx", err)
+			}
+		}
+		envDiff.applyToBaseEnv()
+		return env
+	}
+	bundle := func(txs ...*types.Transaction) *types.SimulatedBundle {
+		env := laneEnv()
+		simBundle, err := simulateBundle(env, types.MevBundle{Txs: txs, BlockNumber: env.header.Number}, chData, nil)
+		if err != nil {
+			t.Fatal("Failed to simulate bundle", err)
+		}
+		return &simBundle
+	}
+	backrun, swapBackrun := bundle(back), bundle(swapBack)
+
+	// a bundle backrunning a private transaction frontrun by its searcher sandwiches it
+	if err := newEnvironmentDiff(laneEnv()).commitBundle(backrun, chData, nil, algoConf); !errors.Is(err, errTxSandwiched) {
+		t.Fatalf("sandwich committed, err %v", err)
+	}
+	changes, err := newEnvChanges(laneEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = changes.commitBundle(backrun, chData, algoConf); !errors.Is(err, errTxSandwiched) {
+		t.Fatalf("sandwich committed, err %v", err)
+	}
+	if len(changes.txs) != 0 {
+		t.Fatalf("sandwich left %d txs", len(changes.txs))
+	}
+
+	// so does a bundle trading back on the pool of the private transaction frontrun from another account
+	if err := newEnvironmentDiff(laneEnv()).commitBundle(swapBackrun, chData, nil, algoConf); !errors.Is(err, errTxSandwiched) {
+		t.Fatalf("sandwich committed, err %v", err)
+	}
+
+	// a backrun only transaction can't be frontrun by the searcher backrunning it
+	prefs[victim.Hash()] = types.PrivateTxPreferences{BackrunOnly: true}
+	if err := newEnvironmentDiff(laneEnv()).commitBundle(backrun, chData, nil, algoConf); !errors.Is(err, errTxFrontrun) {
+		t.Fatalf("frontrun committed, err %v", err)
+	}
+
+	// the backruns of other searchers are allowed
+	if err := newEnvironmentDiff(laneEnv()).commitBundle(bundle(other), chData, nil, algoConf); err != nil {
+		t.Fatal("Failed to commit backrun", err)
+	}
+}
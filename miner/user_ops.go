@@ -0,0 +1,171 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// handleOpsBaseGas is the gas of a handleOps call on top of the gas limits of its operations
+const handleOpsBaseGas = 100_000
+
+var (
+	errUserOpsGasLimit = errors.New("user operations over the block gas limit")
+
+	userOpBundleAcceptedMeter = metrics.NewRegisteredMeter("miner/userop/accepted", nil)
+	userOpBundleRejectedMeter = metrics.NewRegisteredMeter("miner/userop/rejected", nil)
+
+	entryPointABI, _ = abi.JSON(strings.NewReader(`[
+		{"name":"handleOps","type":"function","stateMutability":"nonpayable","inputs":[
+			{"name":"ops","type":"tuple[]","components":[
+				{"name":"sender","type":"address"},
+				{"name":"nonce","type":"uint256"},
+				{"name":"initCode","type":"bytes"},
+				{"name":"callData","type":"bytes"},
+				{"name":"callGasLimit","type":"uint256"},
+				{"name":"verificationGasLimit","type":"uint256"},
+				{"name":"preVerificationGas","type":"uint256"},
+				{"name":"maxFeePerGas","type":"uint256"},
+				{"name":"maxPriorityFeePerGas","type":"uint256"},
+				{"name":"paymasterAndData","type":"bytes"},
+				{"name":"signature","type":"bytes"}
+			]},
+			{"name":"beneficiary","type":"address"}
+		],"outputs":[]}
+	]`))
+)
+
+// userOpBundles wraps the ERC-4337 user operation bundles targeting the block into handleOps
+// transactions of the builder, the EntryPoint pays the compensation of the operations to the
+// block coinbase. The bundles are simulated in turn, on top of the ones accepted before them,
+// under a multi-transaction snapshot reverted if the handleOps call fails, e.g. when an operation
+// fails its validation. The accepted operations are merged into a handleOps transaction per
+// EntryPoint, all of them forming a single bundle merged by the algorithms like the others.
+func (w *worker) userOpBundles(env *environment) []types.MevBundle {
+	if w.txSigner == nil || w.txSigner.Address() != env.coinbase || env.header.BaseFee == nil {
+		return nil
+	}
+	pooled := w.eth.TxPool().UserOpBundles(env.header.Number, env.header.Time)
+	if len(pooled) == 0 {
+		return nil
+	}
+
+	var (
+		statedb     = env.state.Fork()
+		accepted    = make(map[common.Address][]types.UserOperation)
+		entryPoints []common.Address
+	)
+	for _, bundle := range pooled {
+		ops := append(append([]types.UserOperation{}, accepted[bundle.EntryPoint]...), bundle.Ops...)
+		if err := w.simulateUserOps(env, statedb, bundle.EntryPoint, bundle.Ops); err != nil {
+			if metrics.EnabledBuilder {
+				userOpBundleRejectedMeter.Mark(1)
+			}
+			log.Debug("User operation bundle rejected", "bundle", bundle.Hash(), "entryPoint", bundle.EntryPoint, "err", err)
+			continue
+		}
+		if metrics.EnabledBuilder {
+			userOpBundleAcceptedMeter.Mark(1)
+		}
+		if _, ok := accepted[bundle.EntryPoint]; !ok {
+			entryPoints = append(entryPoints, bundle.EntryPoint)
+		}
+		accepted[bundle.EntryPoint] = ops
+	}
+	if len(entryPoints) == 0 {
+		return nil
+	}
+
+	nonce := env.state.GetNonce(env.coinbase)
+	txs := make(types.Transactions, 0, len(entryPoints))
+	for _, entryPoint := range entryPoints {
+		tx, err := w.handleOpsTx(env, nonce, entryPoint, accepted[entryPoint])
+		if err != nil {
+			log.Debug("Failed to wrap user operations", "entryPoint", entryPoint, "err", err)
+			return nil
+		}
+		txs = append(txs, tx)
+		nonce++
+	}
+	return []types.MevBundle{{
+		Txs:            txs,
+		BlockNumber:    env.header.Number,
+		SigningAddress: env.coinbase,
+		Hash:           txpool.MevBundleHash(txs),
+	}}
+}
+
+// simulateUserOps applies a handleOps transaction of the operations to the state, the state is
+// left untouched if it fails.
+func (w *worker) simulateUserOps(env *environment, statedb *state.StateDB, entryPoint common.Address, ops []types.UserOperation) error {
+	tx, err := w.handleOpsTx(env, statedb.GetNonce(env.coinbase), entryPoint, ops)
+	if err != nil {
+		return err
+	}
+	if err := statedb.NewMultiTxSnapshot(); err != nil {
+		return err
+	}
+	var (
+		gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+		gasUsed uint64
+		config  = *w.chain.GetVMConfig()
+	)
+	config.Sandbox = w.config.SimulationLimits.Copy()
+	statedb.SetTxContext(tx.Hash(), 0)
+	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &env.coinbase, gasPool, statedb, env.header, tx, &gasUsed, config, nil)
+	if err == nil {
+		err = config.Sandbox.Err()
+	}
+	if err == nil && receipt.Status == types.ReceiptStatusFailed {
+		err = errBundleTxReverted
+	}
+	if err != nil {
+		if revertErr := statedb.MultiTxSnapshotRevert(); revertErr != nil {
+			return revertErr
+		}
+		return err
+	}
+	return statedb.MultiTxSnapshotCommit()
+}
+
+// handleOpsTx returns the handleOps transaction of the builder submitting the operations to the
+// EntryPoint, with the block coinbase as beneficiary. Its gas limit covers the gas limits of the
+// operations.
+func (w *worker) handleOpsTx(env *environment, nonce uint64, entryPoint common.Address, ops []types.UserOperation) (*types.Transaction, error) {
+	gas := new(big.Int).SetUint64(handleOpsBaseGas)
+	for _, op := range ops {
+		verificationGas := new(big.Int).Set(op.VerificationGasLimit)
+		if len(op.PaymasterAndData) > 0 {
+			// the paymaster validation and post operation are bounded by the verification gas limit
+			verificationGas.Mul(verificationGas, big.NewInt(3))
+		}
+		gas.Add(gas, verificationGas)
+		gas.Add(gas, op.CallGasLimit)
+		gas.Add(gas, op.PreVerificationGas)
+	}
+	if !gas.IsUint64() || gas.Uint64() > env.header.GasLimit {
+		return nil, errUserOpsGasLimit
+	}
+	input, err := entryPointABI.Pack("handleOps", ops, env.coinbase)
+	if err != nil {
+		return nil, err
+	}
+	return w.txSigner.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   w.chainConfig.ChainID,
+		Nonce:     nonce,
+		GasTipCap: new(big.Int),
+		GasFeeCap: env.header.BaseFee,
+		Gas:       gas.Uint64(),
+		To:        &entryPoint,
+		Data:      input,
+	}), env.signer)
+}
@@ -0,0 +1,87 @@
+package miner
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestUserOpBundles(t *testing.T) {
+	var (
+		entryPoint          = common.HexToAddress("0xe1")
+		revertingEntryPoint = common.HexToAddress("0xe2")
+	)
+	alloc := core.GenesisAlloc{
+		testBankAddress:     {Balance: testBankFunds},
+		revertingEntryPoint: {Balance: new(big.Int), Code: contractRevert},
+	}
+	w, b := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), alloc, 0)
+	defer w.close()
+	w.txSigner = NewLocalTxSigner(testBankKey)
+
+	env, err := w.prepareWork(&generateParams{gasLimit: 30000000, coinbase: testBankAddress})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer env.discard()
+
+	userOp := func(nonce int64) types.UserOperation {
+		return types.UserOperation{
+			Sender:               testUserAddress,
+			Nonce:                big.NewInt(nonce),
+			CallGasLimit:         big.NewInt(100_000),
+			VerificationGasLimit: big.NewInt(50_000),
+			PreVerificationGas:   big.NewInt(21_000),
+			MaxFeePerGas:         big.NewInt(2_000_000_000),
+			MaxPriorityFeePerGas: big.NewInt(1_000_000_000),
+		}
+	}
+	for _, bundle := range []types.UserOperationBundle{
+		{EntryPoint: entryPoint, Ops: []types.UserOperation{userOp(0)}},
+		// the handleOps call of the bundle reverts, its operations are dropped
+		{EntryPoint: revertingEntryPoint, Ops: []types.UserOperation{userOp(1)}},
+		{EntryPoint: entryPoint, Ops: []types.UserOperation{userOp(2), userOp(3)}},
+	} {
+		bundle.BlockNumber = env.header.Number
+		if err := b.txPool.AddUserOpBundle(bundle); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bundles := w.userOpBundles(env)
+	if len(bundles) != 1 || len(bundles[0].Txs) != 1 {
+		t.Fatalf("unexpected bundles %v", bundles)
+	}
+	tx := bundles[0].Txs[0]
+	if *tx.To() != entryPoint || tx.Nonce() != env.state.GetNonce(testBankAddress) {
+		t.Errorf("unexpected handleOps tx to %s with nonce %d", tx.To(), tx.Nonce())
+	}
+	input, err := entryPointABI.Pack("handleOps", []types.UserOperation{userOp(0), userOp(2), userOp(3)}, testBankAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(tx.Data(), input) {
+		t.Error("handleOps tx does not submit the operations of the accepted bundles")
+	}
+	if tx.Gas() != handleOpsBaseGas+3*171_000 {
+		t.Errorf("handleOps tx gas %d, want %d", tx.Gas(), handleOpsBaseGas+3*171_000)
+	}
+
+	// the bundle is simulated as any other
+	simulated, _, err := w.simulateBundles(env, bundles, nil, nil)
+	if err != nil || len(simulated) != 1 {
+		t.Fatalf("failed to simulate the user operation bundle: %v", err)
+	}
+
+	// without the key of the coinbase, the builder cannot be the bundler
+	w.txSigner = nil
+	if bundles := w.userOpBundles(env); len(bundles) != 0 {
+		t.Error("user operations bundled without builder key")
+	}
+}
@@ -0,0 +1,217 @@
+package miner
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// valueComparisonLimit is the number of value comparisons kept
+const valueComparisonLimit = 256
+
+var (
+	missedValueHistogram = metrics.NewRegisteredHistogram("miner/value/missed", nil, metrics.NewExpDecaySample(1028, 0.015))
+	missedValueGauge     = metrics.NewRegisteredGauge("miner/value/missed/gauge", nil)
+	valueComparedMeter   = metrics.NewRegisteredMeter("miner/value/compared", nil)
+	valueBehindMeter     = metrics.NewRegisteredMeter("miner/value/behind", nil)
+	valueLandedMeter     = metrics.NewRegisteredMeter("miner/value/landed", nil)
+)
+
+// BlockValueComparison compares the coinbase value of a canonical block with the value of the
+// most valuable block the node built for the same height.
+type BlockValueComparison struct {
+	BlockNumber    hexutil.Uint64 `json:"blockNumber"`
+	CanonicalHash  common.Hash    `json:"canonicalHash"`
+	Coinbase       common.Address `json:"coinbase"`
+	CanonicalValue *hexutil.Big   `json:"canonicalValue"` // Paid to the coinbase by the canonical block
+	BuiltHash      common.Hash    `json:"builtHash"`
+	BuiltValue     *hexutil.Big   `json:"builtValue"` // Paid to the coinbase by the built block
+	Built          hexutil.Uint64 `json:"built"`      // Blocks built by the node for the height
+	Landed         bool           `json:"landed"`     // The canonical block was built by the node
+	// MissedValue is the value of the canonical block the node did not match, 0 if the built
+	// block was at least as valuable
+	MissedValue *hexutil.Big `json:"missedValue"`
+}
+
+// BlockValueReport compares the recent canonical blocks with the blocks built by the node for
+// the same heights, most recent first.
+type BlockValueReport struct {
+	Blocks      []BlockValueComparison `json:"blocks"`
+	Behind      hexutil.Uint64         `json:"behind"`      // Canonical blocks more valuable than the built ones
+	Landed      hexutil.Uint64         `json:"landed"`      // Canonical blocks built by the node
+	MissedValue *hexutil.Big           `json:"missedValue"` // Total missed value of the reported blocks
+}
+
+// builtValue is the most valuable block built by the node for a height.
+type builtValue struct {
+	hash   common.Hash
+	value  *big.Int
+	hashes map[common.Hash]struct{} // All the blocks built for the height
+}
+
+// valueComparisons compares every canonical block imported with the most valuable block the
+// node built for the same height, to measure the value the node misses against the other
+// block producers. The methods are no-ops on a nil tracker.
+type valueComparisons struct {
+	mu          sync.Mutex
+	built       map[uint64]*builtValue // Heights built for waiting for their canonical block
+	comparisons []BlockValueComparison // Oldest first
+}
+
+func newValueComparisons() *valueComparisons {
+	return &valueComparisons{built: make(map[uint64]*builtValue)}
+}
+
+// builtBlockValue returns the value paid to the coinbase of a block built in env: the proposer
+// payment if the block pays the proposer, the profit of the builder coinbase otherwise.
+func builtBlockValue(env *environment, payout *big.Int) *big.Int {
+	if payout != nil && payout.Sign() > 0 {
+		return payout
+	}
+	if env.profit == nil {
+		return new(big.Int)
+	}
+	return env.profit
+}
+
+// blockBuilt records the value of a built block, if it is the most valuable one of its height.
+func (c *valueComparisons) blockBuilt(block *types.Block, value *big.Int) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	number := block.NumberU64()
+	built, ok := c.built[number]
+	if !ok {
+		if len(c.built) >= profitPendingDepth {
+			return
+		}
+		built = &builtValue{hashes: make(map[common.Hash]struct{})}
+		c.built[number] = built
+	}
+	if len(built.hashes) < profitPendingLimit {
+		built.hashes[block.Hash()] = struct{}{}
+	}
+	if built.value == nil || value.Cmp(built.value) > 0 {
+		built.hash = block.Hash()
+		built.value = new(big.Int).Set(value)
+	}
+}
+
+// chainHead compares the head block with the most valuable block built for its height, and
+// forgets the heights too old to be imported. The coinbase value of the head block is read by
+// canonicalValue, only if the node built a block for its height. It returns nil if no block was
+// built for the height or if the head was already compared.
+func (c *valueComparisons) chainHead(head *types.Block, canonicalValue func(*types.Block) (*big.Int, error)) *BlockValueComparison {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	built, ok := c.built[head.NumberU64()]
+	delete(c.built, head.NumberU64())
+	for number := range c.built {
+		if number+profitPendingDepth < head.NumberU64() {
+			delete(c.built, number)
+		}
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	value, err := canonicalValue(head)
+	if err != nil {
+		log.Debug("Failed to compare the canonical block value", "number", head.NumberU64(), "hash", head.Hash(), "err", err)
+		return nil
+	}
+	_, landed := built.hashes[head.Hash()]
+	missed := new(big.Int).Sub(value, built.value)
+	if landed || missed.Sign() < 0 {
+		missed.SetInt64(0)
+	}
+	comparison := BlockValueComparison{
+		BlockNumber:    hexutil.Uint64(head.NumberU64()),
+		CanonicalHash:  head.Hash(),
+		Coinbase:       head.Coinbase(),
+		CanonicalValue: (*hexutil.Big)(value),
+		BuiltHash:      built.hash,
+		BuiltValue:     (*hexutil.Big)(built.value),
+		Built:          hexutil.Uint64(len(built.hashes)),
+		Landed:         landed,
+		MissedValue:    (*hexutil.Big)(missed),
+	}
+
+	c.mu.Lock()
+	c.comparisons = append(c.comparisons, comparison)
+	if len(c.comparisons) > valueComparisonLimit {
+		c.comparisons = c.comparisons[len(c.comparisons)-valueComparisonLimit:]
+	}
+	c.mu.Unlock()
+
+	if metrics.EnabledBuilder {
+		gwei := new(big.Int).Quo(missed, big.NewInt(1e9)).Int64()
+		missedValueHistogram.Update(gwei)
+		missedValueGauge.Update(gwei)
+		valueComparedMeter.Mark(1)
+		if missed.Sign() > 0 {
+			valueBehindMeter.Mark(1)
+		}
+		if landed {
+			valueLandedMeter.Mark(1)
+		}
+	}
+	log.Debug("Compared canonical block value", "number", head.NumberU64(), "hash", head.Hash(), "landed", landed,
+		"canonical", value, "built", built.value, "missed", missed)
+	return &comparison
+}
+
+// report returns the recent comparisons, most recent first, with their totals.
+func (c *valueComparisons) report() *BlockValueReport {
+	report := &BlockValueReport{Blocks: make([]BlockValueComparison, 0), MissedValue: (*hexutil.Big)(new(big.Int))}
+	if c == nil {
+		return report
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	total := new(big.Int)
+	for i := len(c.comparisons) - 1; i >= 0; i-- {
+		comparison := c.comparisons[i]
+		report.Blocks = append(report.Blocks, comparison)
+		if comparison.Landed {
+			report.Landed++
+		}
+		if comparison.MissedValue.ToInt().Sign() > 0 {
+			report.Behind++
+			total.Add(total, comparison.MissedValue.ToInt())
+		}
+	}
+	report.MissedValue = (*hexutil.Big)(total)
+	return report
+}
+
+// canonicalValue returns the value paid to the coinbase by an imported block, the change of the
+// balance of its coinbase between the state of its parent and its own state.
+func (w *worker) canonicalValue(block *types.Block) (*big.Int, error) {
+	parent := w.chain.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent of block %d not found", block.NumberU64())
+	}
+	parentState, err := w.chain.StateAt(parent.Root)
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d unavailable: %w", parent.Number.Uint64(), err)
+	}
+	state, err := w.chain.StateAt(block.Root())
+	if err != nil {
+		return nil, fmt.Errorf("state of block %d unavailable: %w", block.NumberU64(), err)
+	}
+	return new(big.Int).Sub(state.GetBalance(block.Coinbase()), parentState.GetBalance(block.Coinbase())), nil
+}
@@ -0,0 +1,66 @@
+package miner
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestValueComparisons(t *testing.T) {
+	var (
+		built     = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10)})
+		better    = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10), Extra: []byte{1}})
+		canonical = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(10), Extra: []byte{2}})
+		next      = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(11)})
+		unbuilt   = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(12)})
+		valueOf   = func(value int64) func(*types.Block) (*big.Int, error) {
+			return func(*types.Block) (*big.Int, error) { return big.NewInt(value), nil }
+		}
+		unavailable = func(*types.Block) (*big.Int, error) { return nil, errors.New("state unavailable") }
+	)
+	var nilTracker *valueComparisons
+	nilTracker.blockBuilt(built, big.NewInt(1))
+	if nilTracker.chainHead(built, valueOf(1)) != nil || len(nilTracker.report().Blocks) != 0 {
+		t.Fatal("nil tracker is not a no-op")
+	}
+
+	tracker := newValueComparisons()
+	tracker.blockBuilt(built, big.NewInt(100))
+	tracker.blockBuilt(better, big.NewInt(150))
+	tracker.blockBuilt(next, big.NewInt(100))
+
+	// a more valuable canonical block is missed value
+	comparison := tracker.chainHead(canonical, valueOf(200))
+	if comparison == nil {
+		t.Fatal("canonical block not compared")
+	}
+	if comparison.Landed || comparison.BuiltHash != better.Hash() || comparison.Built != 2 || comparison.MissedValue.ToInt().Int64() != 50 {
+		t.Fatalf("unexpected comparison %+v", comparison)
+	}
+	// the height is compared once
+	if comparison := tracker.chainHead(canonical, valueOf(200)); comparison != nil {
+		t.Fatalf("height compared twice %+v", comparison)
+	}
+
+	// a landed block misses no value
+	comparison = tracker.chainHead(next, valueOf(100))
+	if comparison == nil || !comparison.Landed || comparison.MissedValue.ToInt().Sign() != 0 {
+		t.Fatalf("unexpected comparison %+v", comparison)
+	}
+
+	// heights without built blocks or canonical value are not compared
+	if comparison := tracker.chainHead(unbuilt, valueOf(100)); comparison != nil {
+		t.Fatalf("unbuilt height compared %+v", comparison)
+	}
+	tracker.blockBuilt(unbuilt, big.NewInt(100))
+	if comparison := tracker.chainHead(unbuilt, unavailable); comparison != nil {
+		t.Fatalf("unavailable canonical value compared %+v", comparison)
+	}
+
+	report := tracker.report()
+	if len(report.Blocks) != 2 || report.Blocks[0].BlockNumber != 11 || report.Behind != 1 || report.Landed != 1 || report.MissedValue.ToInt().Int64() != 50 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
@@ -0,0 +1,56 @@
+package miner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// maxWitnessBlocks is the number of block heights the witnesses are kept on disk for
+const maxWitnessBlocks = 64
+
+// recordWitness starts recording the state read by a new environment, if the witnesses of the
+// sealed blocks are written.
+func (w *worker) recordWitness(state *state.StateDB) {
+	if w.config.WitnessDir != "" {
+		state.RecordWitness()
+	}
+}
+
+// writeWitness writes the execution witness of a sealed block as <number>-<hash>.rlp in the
+// witness directory, and removes the witnesses of the blocks maxWitnessBlocks below. The witness
+// is built from the state of the block once finalised, the file is written in the background.
+func (w *worker) writeWitness(state *state.StateDB, block *types.Block) {
+	if w.config.WitnessDir == "" {
+		return
+	}
+	witness, err := state.Witness()
+	if err != nil {
+		log.Error("Failed to build block witness", "number", block.Number(), "hash", block.Hash(), "err", err)
+		return
+	}
+	go func(dir string) {
+		data, err := rlp.EncodeToBytes(witness)
+		if err == nil {
+			err = os.MkdirAll(dir, 0700)
+		}
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d-%s.rlp", block.NumberU64(), block.Hash().Hex())), data, 0600)
+		}
+		if err != nil {
+			log.Error("Failed to write block witness", "number", block.Number(), "hash", block.Hash(), "err", err)
+			return
+		}
+		if number := block.NumberU64(); number >= maxWitnessBlocks {
+			stale, _ := filepath.Glob(filepath.Join(dir, fmt.Sprintf("%d-*.rlp", number-maxWitnessBlocks)))
+			for _, file := range stale {
+				os.Remove(file)
+			}
+		}
+	}(w.config.WitnessDir)
+}
@@ -20,6 +20,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"runtime"
+	"runtime/debug"
 
 	"sort"
 	"sync"
@@ -27,15 +29,19 @@ import (
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/ethereum/go-ethereum/builder/buildtrace"
+	"github.com/ethereum/go-ethereum/builder/eventexport"
+	"github.com/ethereum/go-ethereum/builder/profiling"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/internal/redact"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
@@ -103,10 +109,18 @@ type environment struct {
 	coinbase  common.Address
 	profit    *big.Int
 
-	header   *types.Header
-	txs      []*types.Transaction
-	receipts []*types.Receipt
-	uncles   map[common.Hash]*types.Header
+	stateGrowth uint64            // state created by the bundles packed in the block, see StateGrowthConfig
+	decisions   *decisionRecorder // inclusion decisions of the block, nil outside of the block building rounds
+	round       *buildRound       // block building round of the environment, nil if it does not build a block
+	slotStart   time.Time         // timestamp of the parent block, start of the slot the block is built in
+	parentRoot  common.Hash       // state root of the parent block, the bundles are simulated on
+	background  bool              // environment of the background bundle simulations, no block is built in it
+
+	header    *types.Header
+	txs       []*types.Transaction
+	receipts  []*types.Receipt
+	txProfits []*big.Int // coinbase balance change of each transaction in txs
+	uncles    map[common.Hash]*types.Header
 }
 
 // copy creates a deep copy of environment.
@@ -121,6 +135,13 @@ func (env *environment) copy() *environment {
 		profit:    new(big.Int).Set(env.profit),
 		header:    types.CopyHeader(env.header),
 		receipts:  copyReceipts(env.receipts),
+
+		stateGrowth: env.stateGrowth,
+		decisions:   env.decisions,
+		round:       env.round,
+		slotStart:   env.slotStart,
+		parentRoot:  env.parentRoot,
+		background:  env.background,
 	}
 	if env.gasPool != nil {
 		gasPool := *env.gasPool
@@ -130,6 +151,8 @@ func (env *environment) copy() *environment {
 	// to do the expensive deep copy for them.
 	cpy.txs = make([]*types.Transaction, len(env.txs))
 	copy(cpy.txs, env.txs)
+	cpy.txProfits = make([]*big.Int, len(env.txProfits))
+	copy(cpy.txProfits, env.txProfits)
 	cpy.uncles = make(map[common.Hash]*types.Header)
 	for hash, uncle := range env.uncles {
 		cpy.uncles[hash] = uncle
@@ -210,6 +233,9 @@ type worker struct {
 	eth         Backend
 	chain       *core.BlockChain
 	blockList   map[common.Address]struct{}
+	txSigner    TxSigner // Signer of payout and refund txs, nil if the builder has no key
+
+	bundlePolicies []BundlePolicy
 
 	// Feeds
 	pendingLogsFeed event.Feed
@@ -279,6 +305,10 @@ type worker struct {
 
 	flashbots *flashbotsData
 
+	// multiTxSnapshotSpill is the store the multi-transaction snapshots over the memory limit are
+	// spilled to, nil if disabled
+	multiTxSnapshotSpill *multiTxSnapshotSpill
+
 	// Test hooks
 	newTaskHook  func(*task)                        // Method to call upon receiving a new sealing task.
 	skipSealHook func(*task) bool                   // Method to decide whether skipping the sealing.
@@ -288,11 +318,15 @@ type worker struct {
 
 func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, isLocalBlock func(header *types.Header) bool, init bool, flashbots *flashbotsData) *worker {
 	var builderCoinbase common.Address
-	if config.BuilderTxSigningKey == nil {
+	txSigner := config.BuilderTxSigner
+	if txSigner == nil && config.BuilderTxSigningKey != nil {
+		txSigner = NewLocalTxSigner(config.BuilderTxSigningKey)
+	}
+	if txSigner == nil {
 		log.Error("Builder tx signing key is not set")
 		builderCoinbase = config.Etherbase
 	} else {
-		builderCoinbase = crypto.PubkeyToAddress(config.BuilderTxSigningKey.PublicKey)
+		builderCoinbase = txSigner.Address()
 	}
 
 	log.Info("new worker", "builderCoinbase", builderCoinbase.String())
@@ -333,6 +367,8 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		eth:                eth,
 		chain:              eth.BlockChain(),
 		blockList:          blockList,
+		txSigner:           txSigner,
+		bundlePolicies:     newBundlePolicies(config),
 		mux:                mux,
 		isLocalBlock:       isLocalBlock,
 		localUncles:        make(map[common.Hash]*types.Block),
@@ -379,6 +415,7 @@ func newWorker(config *Config, chainConfig *params.ChainConfig, engine consensus
 		log.Warn("Low payload timeout may cause high amount of non-full blocks", "provided", newpayloadTimeout, "default", DefaultConfig.NewPayloadTimeout)
 	}
 	worker.newpayloadTimeout = newpayloadTimeout
+	worker.openMultiTxSnapshotSpill()
 
 	worker.wg.Add(2)
 	go worker.mainLoop()
@@ -417,6 +454,28 @@ func (w *worker) setGasCeil(ceil uint64) {
 	w.config.GasCeil = ceil
 }
 
+// algorithm returns the block building algorithm of the worker.
+func (w *worker) algorithm() AlgoType {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.flashbots.algoType
+}
+
+// setAlgorithm replaces the block building algorithm of the blocks built from now on.
+func (w *worker) setAlgorithm(algo AlgoType) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flashbots.algoType = algo
+}
+
+// bundleFloor returns the floors of the bundles, the shared ones if the worker has them.
+func (w *worker) bundleFloor() BundleFloorConfig {
+	if w.flashbots.floors != nil {
+		return w.flashbots.floors.getConfig()
+	}
+	return w.config.BundleFloor
+}
+
 // setExtra sets the content used to initialize the block extra field.
 func (w *worker) setExtra(extra []byte) {
 	w.mu.Lock()
@@ -491,6 +550,7 @@ func (w *worker) close() {
 	atomic.StoreInt32(&w.running, 0)
 	close(w.exitCh)
 	w.wg.Wait()
+	w.closeMultiTxSnapshotSpill()
 }
 
 // recalcRecommit recalculates the resubmitting interval upon feedback.
@@ -573,6 +633,22 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 			commit(false, commitInterruptNewHead)
 
 		case head := <-w.chainHeadCh:
+			if landed := w.flashbots.profits.chainHead(head.Block); landed != nil && eventexport.Enabled() {
+				exportBlockLanded(landed)
+			}
+			if landed := w.flashbots.searchers.chainHead(head.Block); landed != nil {
+				w.flashbots.reputation.landed(landed, w.chain.GetReceiptsByHash(head.Block.Hash()))
+			}
+			w.flashbots.reputation.chainHead()
+			w.flashbots.sources.chainHead(head.Block)
+			w.flashbots.channels.chainHead()
+			w.flashbots.bundleStats.chainHead(head.Block)
+			w.flashbots.reorgs.chainHead(head.Block)
+			w.flashbots.values.chainHead(head.Block, w.canonicalValue)
+			w.flashbots.bids.chainHead(head.Block)
+			if w.flashbots.prefetch.enabled() {
+				w.flashbots.prefetch.chainHead(head.Block, w.chain.StateCache(), bundleHashes(w.eth.TxPool().PooledMevBundles()))
+			}
 			clearPending(head.Block.NumberU64())
 			timestamp = time.Now().Unix()
 			commit(false, commitInterruptNewHead)
@@ -661,6 +737,9 @@ func (w *worker) mainLoop() {
 				}
 			}()
 		case ev := <-w.chainSideCh:
+			// Side events are also sent for the blocks leaving the canonical chain
+			w.flashbots.reorgs.chainSide(ev.Block, w.flashbots.profits)
+
 			// Short circuit for duplicate side blocks
 			if _, exist := w.localUncles[ev.Block.Hash()]; exist {
 				continue
@@ -792,6 +871,10 @@ func (w *worker) taskLoop() {
 			if w.skipSealHook != nil && w.skipSealHook(task) {
 				continue
 			}
+			if w.config.NoSeal {
+				log.Debug("Not sealing miner block", "blockNumber", task.block.Number(), "sealhash", sealHash)
+				continue
+			}
 			w.pendingMu.Lock()
 			w.pendingTasks[sealHash] = task
 			w.pendingMu.Unlock()
@@ -886,22 +969,26 @@ func (w *worker) resultLoop() {
 func (w *worker) makeEnv(parent *types.Header, header *types.Header, coinbase common.Address) (*environment, error) {
 	// Retrieve the parent state to execute on top and start a prefetcher for
 	// the miner to speed block sealing up a bit.
-	state, err := w.chain.StateAt(parent.Root)
+	state, err := w.flashbots.parentStates.stateAt(w.chain, w.chainConfig, parent)
 	if err != nil {
 		return nil, err
 	}
 	state.StartPrefetcher("miner")
+	w.recordWitness(state)
+	w.configureMultiTxSnapshots(state)
 
 	// Note the passed coinbase may be different with header.Coinbase.
 	env := &environment{
-		signer:    types.MakeSigner(w.chainConfig, header.Number),
-		state:     state,
-		coinbase:  coinbase,
-		ancestors: mapset.NewSet[common.Hash](),
-		family:    mapset.NewSet[common.Hash](),
-		header:    header,
-		uncles:    make(map[common.Hash]*types.Header),
-		profit:    new(big.Int),
+		signer:     types.MakeSigner(w.chainConfig, header.Number),
+		state:      state,
+		coinbase:   coinbase,
+		ancestors:  mapset.NewSet[common.Hash](),
+		family:     mapset.NewSet[common.Hash](),
+		header:     header,
+		uncles:     make(map[common.Hash]*types.Header),
+		profit:     new(big.Int),
+		slotStart:  time.Unix(int64(parent.Time), 0),
+		parentRoot: parent.Root,
 	}
 	// when 08 is processed ancestors contain 07 (quick block)
 	for _, ancestor := range w.chain.GetBlocksFromHash(parent.Hash(), 7) {
@@ -987,6 +1074,7 @@ func (w *worker) commitTransaction(env *environment, tx *types.Transaction) ([]*
 		}
 	}
 
+	coinbaseBefore := new(big.Int).Set(stateDB.GetBalance(env.coinbase))
 	receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &env.coinbase, &gasPool, stateDB, env.header, tx, &envGasUsed, config, hook)
 	if err != nil {
 		stateDB.RevertToSnapshot(snapshot)
@@ -997,11 +1085,13 @@ func (w *worker) commitTransaction(env *environment, tx *types.Transaction) ([]*
 	env.header.GasUsed = envGasUsed
 	env.state = stateDB
 
+	txProfit := new(big.Int).Sub(stateDB.GetBalance(env.coinbase), coinbaseBefore)
 	env.txs = append(env.txs, tx)
 	env.receipts = append(env.receipts, receipt)
+	env.txProfits = append(env.txProfits, txProfit)
 
-	gasUsed := new(big.Int).SetUint64(receipt.GasUsed)
-	env.profit.Add(env.profit, gasUsed.Mul(gasUsed, gasPrice))
+	from, _ := types.Sender(env.signer, tx)
+	env.profit.Add(env.profit, txCoinbaseProfit(from, env.coinbase, receipt.GasUsed, gasPrice, txProfit))
 
 	return receipt.Logs, nil
 }
@@ -1311,8 +1401,8 @@ func (w *worker) fillTransactionsSelectAlgo(interrupt *int32, env *environment)
 		mempoolTxHashes map[common.Hash]struct{}
 		err             error
 	)
-	switch w.flashbots.algoType {
-	case ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP:
+	switch w.algorithm() {
+	case ALGO_GREEDY, ALGO_GREEDY_BUCKETS, ALGO_GREEDY_MULTISNAP, ALGO_GREEDY_BUCKETS_MULTISNAP, ALGO_GREEDY_EGP, ALGO_GREEDY_PROFIT:
 		blockBundles, allBundles, usedSbundles, mempoolTxHashes, err = w.fillTransactionsAlgoWorker(interrupt, env)
 	case ALGO_MEV_GETH:
 		blockBundles, allBundles, mempoolTxHashes, err = w.fillTransactions(interrupt, env)
@@ -1344,11 +1434,17 @@ func (w *worker) fillTransactions(interrupt *int32, env *environment) ([]types.S
 		}
 	}
 
+	if err := w.commitBuilderOnlyTxs(env, interrupt); err != nil {
+		return nil, nil, nil, err
+	}
+
 	var blockBundles []types.SimulatedBundle
 	var allBundles []types.SimulatedBundle
 	if w.flashbots.isFlashbots {
 		bundles, ccBundleCh := w.eth.TxPool().MevBundles(env.header.Number, env.header.Time)
+		bundles = txpool.ChainMevBundles(bundles, env.signer, env.state.GetNonce, env.header.Number)
 		bundles = append(bundles, <-ccBundleCh...)
+		bundles = append(bundles, w.userOpBundles(env)...)
 
 		var (
 			bundleTxs       types.Transactions
@@ -1367,11 +1463,22 @@ func (w *worker) fillTransactions(interrupt *int32, env *environment) ([]types.S
 		if len(bundleTxs) == 0 {
 			return nil, nil, nil, errors.New("no bundles to apply")
 		}
+		tail := blockTail(env.signer, env.txs, env.receipts, nil, nil)
 		if err := w.commitBundle(env, bundleTxs, interrupt); err != nil {
 			return nil, nil, nil, err
 		}
+		// the merged bundles are committed right after the builder-only transactions, the block is
+		// discarded if they frontrun or sandwich the last one
+		committed := len(env.txs) - len(bundleTxs)
+		if err := checkBlockTxPreferences(w.txPreferences(), tail, committedTxs(env.signer, env.txs[committed:], env.receipts[committed:])); err != nil {
+			return nil, nil, nil, err
+		}
+		// the merged bundles may behave differently than simulated, the block is discarded if a
+		// transaction reverts without being allowed to
+		if err := checkBundleReverts(mergedBundles, env.receipts[len(env.receipts)-len(bundleTxs):]); err != nil {
+			return nil, nil, nil, err
+		}
 		blockBundles = mergedBundles
-		env.profit.Add(env.profit, resultingBundle.EthSentToCoinbase)
 	}
 
 	if len(localTxs) > 0 {
@@ -1390,6 +1497,18 @@ func (w *worker) fillTransactions(interrupt *int32, env *environment) ([]types.S
 	return blockBundles, allBundles, mempoolTxHashes, nil
 }
 
+// commitBuilderOnlyTxs commits the executable transactions of the builder-only lane of the txpool,
+// which are never shared with the peers, at the top of the block, before the bundles and the
+// mempool transactions.
+func (w *worker) commitBuilderOnlyTxs(env *environment, interrupt *int32) error {
+	pending := w.eth.TxPool().BuilderOnlyPending()
+	if len(pending) == 0 {
+		return nil
+	}
+	txs := types.NewTransactionsByPriceAndNonce(env.signer, pending, nil, nil, env.header.BaseFee)
+	return w.commitTransactions(env, txs, interrupt)
+}
+
 // fillTransactionsAlgoWorker retrieves the pending transactions and bundles from the txpool and fills them
 // into the given sealing block.
 // Returns error if any, otherwise the bundles that made it into the block and all bundles that passed simulation
@@ -1403,22 +1522,79 @@ func (w *worker) fillTransactionsAlgoWorker(interrupt *int32, env *environment)
 			mempoolTxHashes[tx.Hash()] = struct{}{}
 		}
 	}
-	bundlesToConsider, sbundlesToConsider, err := w.getSimulatedBundles(env)
+	// the bundles are simulated on top of the builder-only transactions
+	if err := w.commitBuilderOnlyTxs(env, interrupt); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	var (
+		bundlesToConsider  []types.SimulatedBundle
+		sbundlesToConsider []*types.SimSBundle
+		err                error
+	)
+	w.flashbots.preSim.setTarget(env)
+	profiling.Do(profiling.StageSimulate, func() {
+		bundlesToConsider, sbundlesToConsider, err = w.getSimulatedBundles(env)
+	})
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
 
+	algoType := w.algorithm()
+	env.decisions = newDecisionRecorder(algoType, bundlesToConsider, sbundlesToConsider)
+
 	var (
 		newEnv       *environment
 		blockBundles []types.SimulatedBundle
 		usedSbundle  []types.UsedSBundle
 		start        = time.Now()
 	)
-	switch w.flashbots.algoType {
+	// The orderflow channels prioritise their orders and exclude the ones breaking their exclusivity
+	bundlesToMerge, sbundlesToMerge := w.flashbots.channels.prioritize(bundlesToConsider, sbundlesToConsider, w.eth.TxPool().PrivateTxTime)
+	if buildtrace.Enabled() {
+		env.decisions.traceInputs(env.header, bundlesToMerge, sbundlesToMerge, pending)
+	}
+
+	// The orders of the designated searchers and contracts are packed first, in the gas reserved
+	// at the top of the block
+	reservedGas, reservedBundles, bundlesToMerge, reservedTxs := w.flashbots.topOfBlock.split(bundlesToMerge, pending)
+	if reservedGas > 0 {
+		var leftBundles []types.SimulatedBundle
+		profiling.Do(profiling.StagePack, func() {
+			blockBundles, leftBundles, err = w.fillTopOfBlock(env, interrupt, reservedGas, reservedBundles, reservedTxs)
+		})
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		bundlesToMerge = append(bundlesToMerge, leftBundles...)
+	}
+	builder, err := w.newBlockBuilder(algoType, env, interrupt, w.flashbots.griefing)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	profiling.Do(profiling.StagePack, func() {
+		var mergedBundles []types.SimulatedBundle
+		newEnv, mergedBundles, usedSbundle = builder.buildBlock(bundlesToMerge, sbundlesToMerge, pending)
+		blockBundles = append(blockBundles, mergedBundles...)
+	})
+
+	if metrics.EnabledBuilder {
+		mergeAlgoTimer.Update(time.Since(start))
+		packSlotPhaseTimers.updateSince(env, start)
+	}
+	*env = *newEnv
+
+	return blockBundles, bundlesToConsider, usedSbundle, mempoolTxHashes, err
+}
+
+// newBlockBuilder returns the block building algorithm packing a block on top of env. Griefing
+// records the bundles whose profit vanishes on commit, it may be nil.
+func (w *worker) newBlockBuilder(algo AlgoType, env *environment, interrupt *int32, griefing *griefingTracker) (blockBuilder, error) {
+	switch algo {
 	case ALGO_GREEDY_BUCKETS:
 		priceCutoffPercent := w.config.PriceCutoffPercent
 		if !(priceCutoffPercent >= 0 && priceCutoffPercent <= 100) {
-			return nil, nil, nil, nil, errors.New("invalid price cutoff percent - must be between 0 and 100")
+			return nil, errors.New("invalid price cutoff percent - must be between 0 and 100")
 		}
 
 		algoConf := &algorithmConfig{
@@ -1426,17 +1602,19 @@ func (w *worker) fillTransactionsAlgoWorker(interrupt *int32, env *environment)
 			EnforceProfit:          true,
 			ProfitThresholdPercent: defaultProfitThresholdPercent,
 			PriceCutoffPercent:     priceCutoffPercent,
+			Griefing:               griefing,
+			MaxStateGrowth:         w.config.StateGrowth.MaxPerBlock,
+			Decisions:              env.decisions,
+			TxPreferences:          w.txPreferences(),
 		}
-		builder := newGreedyBucketsBuilder(
+		return newGreedyBucketsBuilder(
 			w.chain, w.chainConfig, algoConf, w.blockList, env,
-			w.config.BuilderTxSigningKey, interrupt,
-		)
-
-		newEnv, blockBundles, usedSbundle = builder.buildBlock(bundlesToConsider, sbundlesToConsider, pending)
+			w.txSigner, interrupt,
+		), nil
 	case ALGO_GREEDY_BUCKETS_MULTISNAP:
 		priceCutoffPercent := w.config.PriceCutoffPercent
 		if !(priceCutoffPercent >= 0 && priceCutoffPercent <= 100) {
-			return nil, nil, nil, nil, errors.New("invalid price cutoff percent - must be between 0 and 100")
+			return nil, errors.New("invalid price cutoff percent - must be between 0 and 100")
 		}
 
 		algoConf := &algorithmConfig{
@@ -1444,12 +1622,15 @@ func (w *worker) fillTransactionsAlgoWorker(interrupt *int32, env *environment)
 			EnforceProfit:          true,
 			ProfitThresholdPercent: defaultProfitThresholdPercent,
 			PriceCutoffPercent:     priceCutoffPercent,
+			Griefing:               griefing,
+			MaxStateGrowth:         w.config.StateGrowth.MaxPerBlock,
+			Decisions:              env.decisions,
+			TxPreferences:          w.txPreferences(),
 		}
-		builder := newGreedyBucketsMultiSnapBuilder(
+		return newGreedyBucketsMultiSnapBuilder(
 			w.chain, w.chainConfig, algoConf, w.blockList, env,
-			w.config.BuilderTxSigningKey, interrupt,
-		)
-		newEnv, blockBundles, usedSbundle = builder.buildBlock(bundlesToConsider, sbundlesToConsider, pending)
+			w.txSigner, interrupt,
+		), nil
 	case ALGO_GREEDY_MULTISNAP:
 		// For greedy multi-snap builder, set algorithm configuration to default values,
 		// except DropRevertibleTxOnErr which is passed in from worker config
@@ -1457,13 +1638,36 @@ func (w *worker) fillTransactionsAlgoWorker(interrupt *int32, env *environment)
 			DropRevertibleTxOnErr:  w.config.DiscardRevertibleTxOnErr,
 			EnforceProfit:          defaultAlgorithmConfig.EnforceProfit,
 			ProfitThresholdPercent: defaultAlgorithmConfig.ProfitThresholdPercent,
+			MaxStateGrowth:         w.config.StateGrowth.MaxPerBlock,
+			Decisions:              env.decisions,
+			TxPreferences:          w.txPreferences(),
 		}
 
-		builder := newGreedyMultiSnapBuilder(
+		return newGreedyMultiSnapBuilder(
 			w.chain, w.chainConfig, algoConf, w.blockList, env,
-			w.config.BuilderTxSigningKey, interrupt,
-		)
-		newEnv, blockBundles, usedSbundle = builder.buildBlock(bundlesToConsider, sbundlesToConsider, pending)
+			w.txSigner, interrupt,
+		), nil
+	case ALGO_GREEDY_EGP, ALGO_GREEDY_PROFIT:
+		algoConf := &algorithmConfig{
+			DropRevertibleTxOnErr:  w.config.DiscardRevertibleTxOnErr,
+			EnforceProfit:          defaultAlgorithmConfig.EnforceProfit,
+			ProfitThresholdPercent: defaultAlgorithmConfig.ProfitThresholdPercent,
+			Griefing:               griefing,
+			MaxStateGrowth:         w.config.StateGrowth.MaxPerBlock,
+			Decisions:              env.decisions,
+			TxPreferences:          w.txPreferences(),
+		}
+
+		if algo == ALGO_GREEDY_PROFIT {
+			return &algorithmBuilder{newGreedyProfitAlgorithm(
+				w.chain, w.chainConfig, algoConf, w.blockList, env,
+				w.txSigner, interrupt,
+			)}, nil
+		}
+		return &algorithmBuilder{newGreedyEGPAlgorithm(
+			w.chain, w.chainConfig, algoConf, w.blockList, env,
+			w.txSigner, interrupt,
+		)}, nil
 	case ALGO_GREEDY:
 		fallthrough
 	default:
@@ -1473,21 +1677,16 @@ func (w *worker) fillTransactionsAlgoWorker(interrupt *int32, env *environment)
 			DropRevertibleTxOnErr:  w.config.DiscardRevertibleTxOnErr,
 			EnforceProfit:          defaultAlgorithmConfig.EnforceProfit,
 			ProfitThresholdPercent: defaultAlgorithmConfig.ProfitThresholdPercent,
+			MaxStateGrowth:         w.config.StateGrowth.MaxPerBlock,
+			Decisions:              env.decisions,
+			TxPreferences:          w.txPreferences(),
 		}
 
-		builder := newGreedyBuilder(
+		return newGreedyBuilder(
 			w.chain, w.chainConfig, algoConf, w.blockList,
-			env, w.config.BuilderTxSigningKey, interrupt,
-		)
-		newEnv, blockBundles, usedSbundle = builder.buildBlock(bundlesToConsider, sbundlesToConsider, pending)
+			env, w.txSigner, interrupt,
+		), nil
 	}
-
-	if metrics.EnabledBuilder {
-		mergeAlgoTimer.Update(time.Since(start))
-	}
-	*env = *newEnv
-
-	return blockBundles, bundlesToConsider, usedSbundle, mempoolTxHashes, err
 }
 
 func (w *worker) getSimulatedBundles(env *environment) ([]types.SimulatedBundle, []*types.SimSBundle, error) {
@@ -1496,26 +1695,40 @@ func (w *worker) getSimulatedBundles(env *environment) ([]types.SimulatedBundle,
 	}
 
 	bundles, ccBundlesCh := w.eth.TxPool().MevBundles(env.header.Number, env.header.Time)
+	// bundles continuing the nonces of another bundle of their signer are included with it or not
+	// at all, the nonces are read from the parent the block is built on
+	bundles = txpool.ChainMevBundles(bundles, env.signer, env.state.GetNonce, env.header.Number)
+	bundles = append(bundles, w.userOpBundles(env)...)
 	sbundles := w.eth.TxPool().GetSBundles(env.header.Number)
+	if metrics.EnabledBuilder {
+		bundlePoolGauge.Update(int64(len(bundles)))
+		sbundlePoolGauge.Update(int64(len(sbundles)))
+	}
 
 	// TODO: consider interrupt
 	simBundles, simSBundles, err := w.simulateBundles(env, bundles, sbundles, nil) /* do not consider gas impact of mempool txs as bundles are treated as transactions wrt ordering */
 	if err != nil {
-		log.Error("Failed to simulate bundles", "err", err)
+		log.Error("Failed to simulate bundles", "round", env.round.ID(), "err", err)
 		return nil, nil, err
 	}
 
 	ccBundles := <-ccBundlesCh
 	if ccBundles == nil {
+		if metrics.EnabledBuilder {
+			simulatedBundlesGauge.Update(int64(len(simBundles) + len(simSBundles)))
+		}
 		return simBundles, simSBundles, nil
 	}
 
 	simCcBundles, _, err := w.simulateBundles(env, ccBundles, nil, nil) /* do not consider gas impact of mempool txs as bundles are treated as transactions wrt ordering */
 	if err != nil {
-		log.Error("Failed to simulate cc bundles", "err", err)
+		log.Error("Failed to simulate cc bundles", "round", env.round.ID(), "err", err)
 		return simBundles, simSBundles, nil
 	}
 
+	if metrics.EnabledBuilder {
+		simulatedBundlesGauge.Update(int64(len(simBundles) + len(simCcBundles) + len(simSBundles)))
+	}
 	return append(simBundles, simCcBundles...), simSBundles, nil
 }
 
@@ -1531,14 +1744,31 @@ func (w *worker) generateWork(params *generateParams) (*types.Block, *big.Int, e
 		return nil, nil, err
 	}
 	defer work.discard()
+	work.round = newBuildRound()
 
 	finalizeFn := func(env *environment, orderCloseTime time.Time,
 		blockBundles []types.SimulatedBundle, allBundles []types.SimulatedBundle, usedSbundles []types.UsedSBundle, noTxs bool) (*types.Block, *big.Int, error) {
-		block, profit, err := w.finalizeBlock(env, params.withdrawals, validatorCoinbase, noTxs)
+		finalizeStart := time.Now()
+		var (
+			block  *types.Block
+			profit *big.Int
+			err    error
+		)
+		profiling.Do(profiling.StageFinalize, func() {
+			// the state-sync events are committed after the transactions, as a Bor node seals the block
+			if err = w.flashbots.stateSync.commit(env, w.chain, w.chainConfig); err != nil {
+				return
+			}
+			block, profit, err = w.finalizeBlock(env, params.withdrawals, validatorCoinbase, noTxs)
+		})
+		if metrics.EnabledBuilder {
+			finalizeBlockTimer.UpdateSince(finalizeStart)
+		}
 		if err != nil {
-			log.Error("could not finalize block", "err", err)
+			log.Error("could not finalize block", "round", env.round.ID(), "err", err)
 			return nil, nil, err
 		}
+		w.writeWitness(env.state, block)
 
 		var okSbundles, totalSbundles int
 		for _, sb := range usedSbundles {
@@ -1548,10 +1778,13 @@ func (w *worker) generateWork(params *generateParams) (*types.Block, *big.Int, e
 			totalSbundles++
 		}
 
-		log.Info("Block finalized and assembled",
-			"height", block.Number().String(), "blockProfit", ethIntToFloat(profit),
+		breakdown := newBlockProfitBreakdown(env, block, blockBundles, usedSbundles)
+		log.Info("Block finalized and assembled", "round", env.round.ID(),
+			"height", block.Number().String(), "hash", block.Hash(), "blockProfit", ethIntToFloat(profit),
 			"txs", len(env.txs), "bundles", len(blockBundles), "okSbundles", okSbundles, "totalSbundles", totalSbundles,
-			"gasUsed", block.GasUsed(), "time", time.Since(start))
+			"bundlesProfit", ethIntToFloat(breakdown.profit(orderKindBundle)), "sbundlesProfit", ethIntToFloat(breakdown.profit(orderKindSBundle)),
+			"mempoolProfit", ethIntToFloat(breakdown.profit(orderKindMempool)), "gasUsed", block.GasUsed(),
+			"discarded", env.round.summary(env.decisions), "time", time.Since(start))
 		if metrics.EnabledBuilder {
 			buildBlockTimer.Update(time.Since(start))
 			blockProfitHistogram.Update(profit.Int64())
@@ -1559,9 +1792,21 @@ func (w *worker) generateWork(params *generateParams) (*types.Block, *big.Int, e
 			culmulativeProfitGauge.Inc(profit.Int64())
 			gasUsedGauge.Update(int64(block.GasUsed()))
 			transactionNumGauge.Update(int64(len(env.txs)))
+			blockBundlesGauge.Update(int64(len(blockBundles) + okSbundles))
+		}
+		env.decisions.write(block, profit, blockBundles, usedSbundles)
+		w.flashbots.profits.blockBuilt(newBlockProfit(env, block, profit))
+		w.flashbots.breakdowns.blockBuilt(breakdown)
+		w.flashbots.searchers.blockBuilt(block, blockBundles)
+		w.flashbots.sources.blockBuilt(block, blockBundles, usedSbundles)
+		w.flashbots.bundleStats.blockBuilt(block, blockBundles, allBundles, usedSbundles)
+		w.flashbots.reorgs.blockBuilt(block, blockBundles, usedSbundles)
+		w.flashbots.values.blockBuilt(block, builtBlockValue(env, profit))
+		if eventexport.Enabled() {
+			exportBlockBuilt(block, profit, blockBundles, usedSbundles)
 		}
 		if params.onBlock != nil {
-			go params.onBlock(block, profit, orderCloseTime, blockBundles, allBundles, usedSbundles)
+			go params.onBlock(block, profit, orderCloseTime, blockBundles, allBundles, usedSbundles, env.round.ID())
 		}
 
 		return block, profit, nil
@@ -1620,7 +1865,7 @@ func (w *worker) finalizeBlock(work *environment, withdrawals types.Withdrawals,
 		return nil, nil, err
 	}
 
-	if w.config.BuilderTxSigningKey == nil {
+	if w.txSigner == nil {
 		return block, big.NewInt(0), nil
 	}
 
@@ -1628,7 +1873,7 @@ func (w *worker) finalizeBlock(work *environment, withdrawals types.Withdrawals,
 		return block, big.NewInt(0), nil
 	}
 
-	blockProfit, err := w.checkProposerPayment(work, validatorCoinbase)
+	blockProfit, err := w.verifyProposerPayment(work, block, validatorCoinbase)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1636,28 +1881,6 @@ func (w *worker) finalizeBlock(work *environment, withdrawals types.Withdrawals,
 	return block, blockProfit, nil
 }
 
-func (w *worker) checkProposerPayment(work *environment, validatorCoinbase common.Address) (*big.Int, error) {
-	if len(work.txs) == 0 {
-		return nil, errors.New("no proposer payment tx")
-	} else if len(work.receipts) == 0 {
-		return nil, errors.New("no proposer payment receipt")
-	}
-
-	lastTx := work.txs[len(work.txs)-1]
-	receipt := work.receipts[len(work.receipts)-1]
-	if receipt.TxHash != lastTx.Hash() || receipt.Status != types.ReceiptStatusSuccessful {
-		log.Error("proposer payment not successful!", "lastTx", lastTx, "receipt", receipt)
-		return nil, errors.New("last transaction is not proposer payment")
-	}
-	lastTxTo := lastTx.To()
-	if lastTxTo == nil || *lastTxTo != validatorCoinbase {
-		log.Error("last transaction is not to the proposer!", "lastTx", lastTx)
-		return nil, errors.New("last transaction is not proposer payment")
-	}
-
-	return new(big.Int).Set(lastTx.Value()), nil
-}
-
 // commitWork generates several new sealing tasks based on the parent block
 // and submit them to the sealer.
 func (w *worker) commitWork(interrupt *int32, noempty bool, timestamp int64) {
@@ -1810,11 +2033,21 @@ func (w *worker) generateFlashbotsBundle(env *environment, bundles []types.MevBu
 		return simulatedBundles[j].MevGasPrice.Cmp(simulatedBundles[i].MevGasPrice) < 0
 	})
 
-	bundleTxs, bundle, mergedBundles, numBundles, err := w.mergeBundles(env, simulatedBundles, pendingTxs)
+	simCache := w.flashbots.bundleCache.GetBundleCache(env.parentRoot, env.header)
+	accesses := make([]*state.StateDiff, len(simulatedBundles))
+	for i, bundle := range simulatedBundles {
+		accesses[i] = simCache.GetBundleAccess(bundle.OriginalBundle.Hash)
+	}
+
+	bundleTxs, bundle, mergedBundles, numBundles, err := w.mergeBundles(env, simulatedBundles, accesses, pendingTxs)
 	return bundleTxs, bundle, mergedBundles, numBundles, simulatedBundles, err
 }
 
-func (w *worker) mergeBundles(env *environment, bundles []simulatedBundle, pendingTxs map[common.Address]types.Transactions) (types.Transactions, simulatedBundle, []types.SimulatedBundle, int, error) {
+// mergeBundles merges the bundles, sorted by decreasing price, until maxMergedBundles are merged.
+// The bundles interfering with others, according to the state they access, are simulated again on
+// top of the bundles merged before them, the other bundles are merged with their simulation
+// results.
+func (w *worker) mergeBundles(env *environment, bundles []simulatedBundle, accesses []*state.StateDiff, pendingTxs map[common.Address]types.Transactions) (types.Transactions, simulatedBundle, []types.SimulatedBundle, int, error) {
 	mergedBundles := []types.SimulatedBundle{}
 	finalBundle := types.Transactions{}
 
@@ -1829,22 +2062,46 @@ func (w *worker) mergeBundles(env *environment, bundles []simulatedBundle, pendi
 		EthSentToCoinbase: new(big.Int),
 	}
 
-	count := 0
-	for _, bundle := range bundles {
-		prevState = currentState.Copy()
-		prevGasPool = new(core.GasPool).AddGas(gasPool.Gas())
+	partitions, sizes := bundlePartitions(accesses)
 
+	count := 0
+	for i, bundle := range bundles {
 		// the floor gas price is 99/100 what was simulated at the top of the block
 		floorGasPrice := new(big.Int).Mul(bundle.MevGasPrice, big.NewInt(99))
 		floorGasPrice = floorGasPrice.Div(floorGasPrice, big.NewInt(100))
 
-		simmed, err := w.computeBundleGas(env, bundle.OriginalBundle, currentState, gasPool, pendingTxs, len(finalBundle))
-		if err != nil || simmed.MevGasPrice.Cmp(floorGasPrice) <= 0 {
-			currentState = prevState
-			gasPool = prevGasPool
+		if err := checkTxPreferences(w.txPreferences(), bundle.OriginalBundle.Txs); err != nil {
+			log.Trace("Bundle breaks private transaction preferences", "bundle", bundle.OriginalBundle.Hash, "err", err)
 			continue
 		}
 
+		var simmed simulatedBundle
+		if sizes[partitions[i]] == 1 {
+			// no other bundle accesses the state of the bundle, its outcome does not depend on the
+			// bundles merged before
+			if metrics.EnabledBuilder {
+				mergeReusedMeter.Mark(1)
+			}
+			if bundle.MevGasPrice.Cmp(floorGasPrice) <= 0 || gasPool.SubGas(bundle.TotalGasUsed) != nil {
+				continue
+			}
+			simmed = bundle
+		} else {
+			if metrics.EnabledBuilder {
+				mergeResimulatedMeter.Mark(1)
+			}
+			prevState = currentState.Copy()
+			prevGasPool = new(core.GasPool).AddGas(gasPool.Gas())
+
+			var err error
+			simmed, err = w.computeBundleGas(env, bundle.OriginalBundle, currentState, gasPool, pendingTxs, len(finalBundle))
+			if err != nil || simmed.MevGasPrice.Cmp(floorGasPrice) <= 0 {
+				currentState = prevState
+				gasPool = prevGasPool
+				continue
+			}
+		}
+
 		log.Info("Included bundle", "ethToCoinbase", ethIntToFloat(simmed.TotalEth), "gasUsed", simmed.TotalGasUsed, "bundleScore", simmed.MevGasPrice, "bundleLength", len(simmed.OriginalBundle.Txs), "worker", w.flashbots.maxMergedBundles)
 		mergedBundles = append(mergedBundles, simmed)
 		finalBundle = append(finalBundle, bundle.OriginalBundle.Txs...)
@@ -1872,22 +2129,66 @@ func (w *worker) mergeBundles(env *environment, bundles []simulatedBundle, pendi
 
 func (w *worker) simulateBundles(env *environment, bundles []types.MevBundle, sbundles []*types.SBundle, pendingTxs map[common.Address]types.Transactions) ([]simulatedBundle, []*types.SimSBundle, error) {
 	start := time.Now()
-	headerHash := env.header.Hash()
-	simCache := w.flashbots.bundleCache.GetBundleCache(headerHash)
+	simCache := w.flashbots.bundleCache.GetBundleCache(env.parentRoot, env.header)
 
 	simResult := make([]*simulatedBundle, len(bundles))
 	sbSimResult := make([]*types.SimSBundle, len(sbundles))
 
+	// the mev-geth algorithm merges the bundles by conflicting partitions, the state they access
+	// is recorded along with the simulation, and prefetched on the next head
+	var accesses []*state.StateDiff
+	if w.algorithm() == ALGO_MEV_GETH || w.flashbots.prefetch.enabled() {
+		accesses = make([]*state.StateDiff, len(bundles))
+	}
+
+	// under high load the bundles are simulated by reputation of their searcher, with a concurrency
+	// bounded so the order holds, and the bundles of low reputation searchers are capped
+	order, capped, highLoad := w.flashbots.reputation.prioritize(bundles, func(hash common.Hash) bool {
+		_, ok := simCache.GetSimulatedBundle(hash)
+		return ok
+	})
+	for _, i := range capped {
+		log.Trace("Dropping bundle of low reputation searcher", "bundle", bundles[i].Hash, "searcher", bundles[i].SigningAddress)
+		markSimulationFailure(bundleRejectedReputation, true)
+		env.round.discard(bundleRejectedReputation)
+		w.flashbots.searchers.simulationFailed(bundles[i].SigningAddress, bundleRejectedReputation, true)
+	}
+	var limit chan struct{}
+	if highLoad {
+		limit = make(chan struct{}, runtime.NumCPU())
+	}
+
 	var wg sync.WaitGroup
-	for i, bundle := range bundles {
+	for _, i := range order {
+		bundle := bundles[i]
 		if simmed, ok := simCache.GetSimulatedBundle(bundle.Hash); ok {
+			if metrics.EnabledBuilder {
+				simulationCacheHitMeter.Mark(1)
+			}
 			simResult[i] = simmed
 			continue
 		}
+		if w.flashbots.griefing.quarantined(bundle.SigningAddress) {
+			if metrics.EnabledBuilder {
+				griefingDroppedMeter.Mark(1)
+			}
+			log.Trace("Dropping bundle of quarantined searcher", "bundle", bundle.Hash, "searcher", bundle.SigningAddress)
+			markSimulationFailure(bundleRejectedQuarantined, true)
+			env.round.discard(bundleRejectedQuarantined)
+			w.flashbots.searchers.simulationFailed(bundle.SigningAddress, bundleRejectedQuarantined, true)
+			continue
+		}
 
+		if limit != nil {
+			limit <- struct{}{}
+		}
 		wg.Add(1)
 		go func(idx int, bundle types.MevBundle, state *state.StateDB) {
 			defer wg.Done()
+			if limit != nil {
+				defer func() { <-limit }()
+			}
+			defer recoverSimulationPanic(bundle.Hash)
 
 			start := time.Now()
 			if metrics.EnabledBuilder {
@@ -1898,28 +2199,54 @@ func (w *worker) simulateBundles(env *environment, bundles []types.MevBundle, sb
 				return
 			}
 			gasPool := new(core.GasPool).AddGas(env.header.GasLimit)
+			recordAccess := accesses != nil
+			if recordAccess {
+				state.RecordReads()
+				recordAccess = state.NewMultiTxSnapshot() == nil
+			}
 			simmed, err := w.computeBundleGas(env, bundle, state, gasPool, pendingTxs, 0)
+			w.flashbots.sources.simulated(bundle.Hash, err)
+			w.flashbots.bundleStats.simulated(bundle.Hash, env.header, simmed.TotalGasUsed, simmed.MevGasPrice, simmed.TotalEth, err)
+			if recordAccess && err == nil {
+				accesses[idx] = bundleAccess(state, env.coinbase)
+			}
 
 			if metrics.EnabledBuilder {
 				simulationMeter.Mark(1)
 			}
 
 			if err != nil {
+				category, rejected := classifySimulationFailure(err)
+				markSimulationFailure(category, rejected)
+				env.round.discard(category)
+				if !rejected {
+					w.flashbots.reputation.simulated(bundle.SigningAddress, true)
+				}
 				if metrics.EnabledBuilder {
-					simulationRevertedMeter.Mark(1)
 					failedBundleSimulationTimer.UpdateSince(start)
+					bundleSimulationSlotPhaseTimers.updateSince(env, start)
 				}
 
-				log.Trace("Error computing gas for a bundle", "error", err)
+				log.Trace("Error computing gas for a bundle", "bundle", redact.Bundle(&bundle), "error", err, "category", category)
+				w.flashbots.griefing.simulationFailed(bundle.Hash, err)
+				w.flashbots.searchers.simulationFailed(bundle.SigningAddress, category, rejected)
+				if eventexport.Enabled() {
+					exportBundleSimulated(env.header, bundle.Hash, bundle.SigningAddress, false, nil, nil, err)
+				}
 				return
 			}
 			simResult[idx] = &simmed
+			w.flashbots.reputation.simulated(bundle.SigningAddress, false)
+			if eventexport.Enabled() {
+				exportBundleSimulated(env.header, bundle.Hash, bundle.SigningAddress, false, simmed.MevGasPrice, simmed.TotalEth, nil)
+			}
 
 			if metrics.EnabledBuilder {
 				simulationCommittedMeter.Mark(1)
 				successfulBundleSimulationTimer.UpdateSince(start)
+				bundleSimulationSlotPhaseTimers.updateSince(env, start)
 			}
-		}(i, bundle, env.state.Copy())
+		}(i, bundle, env.state.Fork())
 	}
 
 	for i, sbundle := range sbundles {
@@ -1931,6 +2258,7 @@ func (w *worker) simulateBundles(env *environment, bundles []types.MevBundle, sb
 		wg.Add(1)
 		go func(idx int, sbundle *types.SBundle, state *state.StateDB) {
 			defer wg.Done()
+			defer recoverSimulationPanic(sbundle.Hash())
 
 			start := time.Now()
 			if metrics.EnabledBuilder {
@@ -1941,6 +2269,7 @@ func (w *worker) simulateBundles(env *environment, bundles []types.MevBundle, sb
 
 			tmpGasUsed := uint64(0)
 			config := *w.chain.GetVMConfig()
+			config.Sandbox = w.config.SimulationLimits.Copy()
 			var tracer *logger.AccountTouchTracer
 			if len(w.blockList) != 0 {
 				tracer = logger.NewAccountTouchTracer()
@@ -1948,23 +2277,40 @@ func (w *worker) simulateBundles(env *environment, bundles []types.MevBundle, sb
 				config.Debug = true
 			}
 			simRes, err := core.SimBundle(w.chainConfig, w.chain, &env.coinbase, gp, state, env.header, sbundle, 0, &tmpGasUsed, config, false)
+			if sandboxErr := config.Sandbox.Err(); sandboxErr != nil {
+				err = sandboxErr
+			}
+			w.flashbots.sources.simulated(sbundle.Hash(), err)
+			w.flashbots.bundleStats.simulated(sbundle.Hash(), env.header, simRes.GasUsed, simRes.MevGasPrice, simRes.TotalProfit, err)
 			if metrics.EnabledBuilder {
 				simulationMeter.Mark(1)
 			}
 			if err != nil {
+				category, rejected := classifySimulationFailure(err)
+				markSimulationFailure(category, rejected)
+				env.round.discard(category)
 				if metrics.EnabledBuilder {
-					simulationRevertedMeter.Mark(1)
 					failedBundleSimulationTimer.UpdateSince(start)
+					bundleSimulationSlotPhaseTimers.updateSince(env, start)
+				}
+				if eventexport.Enabled() {
+					exportBundleSimulated(env.header, sbundle.Hash(), common.Address{}, true, nil, nil, err)
 				}
 				return
 			}
 			if len(w.blockList) != 0 {
 				for _, address := range tracer.TouchedAddresses() {
 					if _, in := w.blockList[address]; in {
+						if eventexport.Enabled() {
+							exportBundleSimulated(env.header, sbundle.Hash(), common.Address{}, true, nil, nil, errBlocklistViolation)
+						}
 						return
 					}
 				}
 			}
+			if eventexport.Enabled() {
+				exportBundleSimulated(env.header, sbundle.Hash(), common.Address{}, true, simRes.MevGasPrice, simRes.TotalProfit, nil)
+			}
 
 			result := &types.SimSBundle{
 				Bundle:      sbundle,
@@ -1976,13 +2322,16 @@ func (w *worker) simulateBundles(env *environment, bundles []types.MevBundle, sb
 			if metrics.EnabledBuilder {
 				simulationCommittedMeter.Mark(1)
 				successfulBundleSimulationTimer.UpdateSince(start)
+				bundleSimulationSlotPhaseTimers.updateSince(env, start)
 			}
-		}(i, sbundle, env.state.Copy())
+		}(i, sbundle, env.state.Fork())
 	}
 
 	wg.Wait()
 
 	simCache.UpdateSimulatedBundles(simResult, bundles)
+	simCache.UpdateBundleAccesses(accesses, bundles)
+	w.flashbots.prefetch.bundlesSimulated(env.header.Number.Uint64(), bundles, accesses)
 	simulatedBundles := make([]simulatedBundle, 0, len(bundles))
 	for _, bundle := range simResult {
 		if bundle != nil {
@@ -1998,14 +2347,41 @@ func (w *worker) simulateBundles(env *environment, bundles []types.MevBundle, sb
 		}
 	}
 
-	log.Debug("Simulated bundles", "block", env.header.Number, "allBundles", len(bundles), "okBundles", len(simulatedBundles),
+	if env.background {
+		return simulatedBundles, simulatedSbundle, nil
+	}
+	log.Debug("Simulated bundles", "round", env.round.ID(), "block", env.header.Number, "allBundles", len(bundles), "okBundles", len(simulatedBundles),
 		"allSbundles", len(sbundles), "okSbundles", len(simulatedSbundle), "time", time.Since(start))
 	if metrics.EnabledBuilder {
 		blockBundleSimulationTimer.Update(time.Since(start))
+		blockSimulationSlotPhaseTimers.updateSince(env, start)
 	}
 	return simulatedBundles, simulatedSbundle, nil
 }
 
+// bundleAccess returns the state accessed by the bundle simulated on top of the multi-transaction
+// snapshot of the state, nil if unknown. The coinbase is left out, all the bundles pay it, the
+// outcome of the bundles only depends on the payments of the others if they read its balance.
+func bundleAccess(statedb *state.StateDB, coinbase common.Address) *state.StateDiff {
+	access, err := statedb.MultiTxSnapshotDiff()
+	if err != nil || access.Reads == nil {
+		return nil
+	}
+	delete(access.Accounts, coinbase)
+	delete(access.Reads, coinbase)
+	return access
+}
+
+// recoverSimulationPanic turns a panic while simulating a bundle into a failed simulation. Bundles
+// are simulated on a copy of the state, so the panic does not affect other bundles or the block.
+// The panic value is redacted as it may contain bundle contents.
+func recoverSimulationPanic(bundleHash common.Hash) {
+	if r := recover(); r != nil {
+		markSimulationFailure(simFailureInternal, false)
+		log.Error("Bundle simulation crashed", "bundle", bundleHash, "err", redact.Panic(r), "stack", string(debug.Stack()))
+	}
+}
+
 func containsHash(arr []common.Hash, match common.Hash) bool {
 	for _, elem := range arr {
 		if elem == match {
@@ -2023,10 +2399,16 @@ func (w *worker) computeBundleGas(
 ) (simulatedBundle, error) {
 	var totalGasUsed uint64 = 0
 	var tempGasUsed uint64
+	var stateGrowth uint64
 	gasFees := new(big.Int)
 
 	ethSentToCoinbase := new(big.Int)
 
+	var policyTxs []BundleTx
+	if len(w.bundlePolicies) != 0 {
+		policyTxs = make([]BundleTx, 0, len(bundle.Txs))
+	}
+
 	for i, tx := range bundle.Txs {
 		if env.header.BaseFee != nil && tx.Type() == 2 {
 			// Sanity check for extremely large numbers
@@ -2046,18 +2428,38 @@ func (w *worker) computeBundleGas(
 		coinbaseBalanceBefore := state.GetBalance(env.coinbase)
 
 		config := *w.chain.GetVMConfig()
+		config.Sandbox = w.config.SimulationLimits.Copy()
 		var tracer *logger.AccountTouchTracer
 		if len(w.blockList) != 0 {
 			tracer = logger.NewAccountTouchTracer()
 			config.Tracer = tracer
 			config.Debug = true
 		}
+		var (
+			growthTracer *stateGrowthTracer
+			newRecipient bool
+		)
+		if w.config.StateGrowth.enabled() {
+			growthTracer = newStateGrowthTracer(config.Tracer)
+			config.Tracer = growthTracer
+			config.Debug = true
+			newRecipient = tx.To() != nil && tx.Value().Sign() > 0 && !state.Exist(*tx.To())
+		}
 		receipt, err := core.ApplyTransaction(w.chainConfig, w.chain, &env.coinbase, gasPool, state, env.header, tx, &tempGasUsed, config, nil)
+		if err := config.Sandbox.Err(); err != nil {
+			return simulatedBundle{}, err
+		}
 		if err != nil {
 			return simulatedBundle{}, err
 		}
 		if receipt.Status == types.ReceiptStatusFailed && !containsHash(bundle.RevertingTxHashes, receipt.TxHash) {
-			return simulatedBundle{}, errors.New("failed tx")
+			return simulatedBundle{}, &bundleRevertError{GasUsed: totalGasUsed + receipt.GasUsed}
+		}
+		if growthTracer != nil {
+			stateGrowth += growthTracer.Growth()
+			if newRecipient && receipt.Status == types.ReceiptStatusSuccessful {
+				stateGrowth++
+			}
 		}
 		if len(w.blockList) != 0 {
 			for _, address := range tracer.TouchedAddresses() {
@@ -2102,17 +2504,52 @@ func (w *worker) computeBundleGas(
 			// If tx is not in pending pool, count the gas fees
 			gasFees.Add(gasFees, gasFeesTx)
 		}
+
+		if policyTxs != nil {
+			// bundles are simulated without the pending txs to not discount their gas fees, the
+			// policies look the mempool txs up in the pool
+			inMempool := txInPendingPool || w.eth.TxPool().Has(tx.Hash())
+			policyTxs = append(policyTxs, BundleTx{Tx: tx, From: from, InMempool: inMempool, Receipt: receipt})
+		}
+	}
+
+	if policyTxs != nil {
+		if err := checkBundlePolicies(w.bundlePolicies, &bundle, policyTxs); err != nil {
+			if metrics.EnabledBuilder {
+				bundlePolicyRejectedMeter.Mark(1)
+			}
+			log.Debug("Bundle rejected by policy", "bundle", bundle.Hash, "err", err)
+			return simulatedBundle{}, err
+		}
 	}
 
 	totalEth := new(big.Int).Add(ethSentToCoinbase, gasFees)
 
-	return simulatedBundle{
+	if w.config.StateGrowth.disproportionate(stateGrowth, totalEth) {
+		if metrics.EnabledBuilder {
+			stateGrowthRejectedMeter.Mark(1)
+		}
+		log.Debug("Bundle rejected for state growth", "bundle", bundle.Hash, "stateGrowth", stateGrowth, "profit", totalEth)
+		return simulatedBundle{}, fmt.Errorf("%w: %d state items for profit %v", ErrStateGrowthUnprofitable, stateGrowth, totalEth)
+	}
+
+	simmed := simulatedBundle{
 		MevGasPrice:       new(big.Int).Div(totalEth, new(big.Int).SetUint64(totalGasUsed)),
 		TotalEth:          totalEth,
 		EthSentToCoinbase: ethSentToCoinbase,
 		TotalGasUsed:      totalGasUsed,
+		StateGrowth:       stateGrowth,
 		OriginalBundle:    bundle,
-	}, nil
+	}
+	floor := w.bundleFloor()
+	if err := floor.check(env.header.Number.Uint64(), w.config.StateSync.Sprint, &simmed); err != nil {
+		if metrics.EnabledBuilder {
+			bundleFloorRejectedMeter.Mark(1)
+		}
+		log.Debug("Bundle rejected below the floor", "bundle", bundle.Hash, "err", err)
+		return simulatedBundle{}, err
+	}
+	return simmed, nil
 }
 
 // copyReceipts makes a deep copy of the given receipts.
@@ -2153,7 +2590,7 @@ type proposerTxReservation struct {
 }
 
 func (w *worker) proposerTxPrepare(env *environment, validatorCoinbase *common.Address) (*proposerTxReservation, error) {
-	if validatorCoinbase == nil || w.config.BuilderTxSigningKey == nil {
+	if validatorCoinbase == nil || w.txSigner == nil {
 		return nil, nil
 	}
 
@@ -2163,7 +2600,7 @@ func (w *worker) proposerTxPrepare(env *environment, validatorCoinbase *common.A
 	builderBalance := env.state.GetBalance(sender)
 
 	chainData := chainData{w.chainConfig, w.chain, w.blockList}
-	gas, isEOA, err := estimatePayoutTxGas(env, sender, *validatorCoinbase, w.config.BuilderTxSigningKey, chainData)
+	gas, isEOA, err := estimatePayoutTxGas(env, sender, *validatorCoinbase, w.txSigner, chainData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to estimate proposer payout gas: %w", err)
 	}
@@ -2196,7 +2633,8 @@ func (w *worker) proposerTxCommit(env *environment, validatorCoinbase *common.Ad
 
 	env.gasPool.AddGas(reserve.reservedGas)
 	chainData := chainData{w.chainConfig, w.chain, w.blockList}
-	_, err := insertPayoutTx(env, sender, *validatorCoinbase, reserve.reservedGas, reserve.isEOA, availableFunds, w.config.BuilderTxSigningKey, chainData)
+	payout := w.flashbots.bids.payout(availableFunds)
+	_, err := insertPayoutTx(env, sender, *validatorCoinbase, reserve.reservedGas, reserve.isEOA, payout, w.txSigner, chainData)
 	if err != nil {
 		return err
 	}
@@ -738,6 +738,57 @@ func TestSimulateBundles(t *testing.T) {
 	}
 }
 
+func TestSimulateBundlesProtectiveOrdering(t *testing.T) {
+	// the pool emits a uniswap v2 swap log with the calldata as log data
+	var (
+		pool     = common.HexToAddress("0x10")
+		poolCode = append(append(common.FromHex("0x3660006000377f"), uniswapV2SwapTopic.Bytes()...), common.FromHex("0x366000a100")...)
+		alloc    = core.GenesisAlloc{
+			testBankAddress: {Balance: testBankFunds},
+			testAddress1:    {Balance: testBankFunds},
+			testAddress2:    {Balance: testBankFunds},
+			testAddress3:    {Balance: testBankFunds},
+			pool:            {Balance: new(big.Int), Code: poolCode},
+		}
+	)
+	w, b := newTestWorker(t, ethashChainConfig, ethash.NewFaker(), rawdb.NewMemoryDatabase(), alloc, 0)
+	defer w.close()
+	w.bundlePolicies = newBundlePolicies(&Config{ProtectiveOrdering: true})
+
+	env, err := w.prepareWork(&generateParams{gasLimit: 30000000})
+	if err != nil {
+		t.Fatalf("Failed to prepare work: %s", err)
+	}
+
+	swapTx := func(key *ecdsa.PrivateKey, nonce uint64, token0ToOne bool) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, pool, new(big.Int), 100000, new(big.Int).Mul(env.header.BaseFee, big.NewInt(2)), v2SwapLog(pool, token0ToOne).Data), types.HomesteadSigner{}, key)
+		if err != nil {
+			t.Fatalf("Failed to sign tx")
+		}
+		return tx
+	}
+
+	// the victim tx of testAddress1 is pending in the mempool, the one of testAddress3 is not
+	victim := swapTx(testAddress1Key, 0, true)
+	if err := b.txPool.AddLocal(victim); err != nil {
+		t.Fatal(err)
+	}
+	sandwich := types.MevBundle{
+		Txs:  types.Transactions{swapTx(testAddress2Key, 0, true), victim, swapTx(testAddress2Key, 1, false)},
+		Hash: common.HexToHash("0x01"),
+	}
+	private := types.MevBundle{
+		Txs:  types.Transactions{swapTx(testAddress2Key, 0, true), swapTx(testAddress3Key, 0, true), swapTx(testAddress2Key, 1, false)},
+		Hash: common.HexToHash("0x02"),
+	}
+
+	simBundles, _, err := w.simulateBundles(env, []types.MevBundle{sandwich, private}, nil, nil)
+	require.NoError(t, err)
+	if len(simBundles) != 1 || simBundles[0].OriginalBundle.Hash != private.Hash {
+		t.Fatalf("expected only the bundle not sandwiching a mempool tx, got %d bundles", len(simBundles))
+	}
+}
+
 func testBundles(t *testing.T) {
 	// TODO: test cancellations
 	db := rawdb.NewMemoryDatabase()
@@ -818,7 +869,7 @@ func testBundles(t *testing.T) {
 
 		blockNumber := big.NewInt(0).Add(w.chain.CurrentBlock().Number, big.NewInt(1))
 		for _, bundle := range bundles {
-			err := b.txPool.AddMevBundle(bundle.Txs, blockNumber, types.EmptyUUID, common.Address{}, 0, 0, nil)
+			err := b.txPool.AddMevBundle(bundle.Txs, blockNumber, nil, types.EmptyUUID, common.Address{}, 0, 0, nil)
 			require.NoError(t, err)
 		}
 
@@ -25,6 +25,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/internal/redact"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -423,7 +424,7 @@ func (h *handler) handleCallMsg(ctx *callProc, msg *jsonrpcMessage) *jsonrpcMess
 		if resp.Error != nil {
 			ctx = append(ctx, "err", resp.Error.Message)
 			if resp.Error.Data != nil {
-				ctx = append(ctx, "errdata", resp.Error.Data)
+				ctx = append(ctx, "errdata", redact.Value(resp.Error.Data))
 			}
 			h.log.Warn("Served "+msg.Method, ctx...)
 		} else {
@@ -326,6 +326,21 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	connInfo.HTTP.Host = r.Host
 	connInfo.HTTP.Origin = r.Header.Get("Origin")
 	connInfo.HTTP.UserAgent = r.Header.Get("User-Agent")
+	if header := r.Header.Get(SignatureHeader); header != "" {
+		// the signature covers the whole body, it is read before the requests are decoded
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestContentLength))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		signer, err := recoverRequestSigner(header, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		connInfo.HTTP.Signer = signer
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
 	ctx := r.Context()
 	ctx = context.WithValue(ctx, peerInfoContextKey{}, connInfo)
 
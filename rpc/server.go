@@ -22,6 +22,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -192,6 +193,9 @@ type PeerInfo struct {
 		UserAgent string
 		Origin    string
 		Host      string
+		// Sender of the request authenticated by the SignatureHeader, zero if not signed. The
+		// requests of a WebSocket connection are authenticated by its upgrade request.
+		Signer common.Address
 	}
 }
 
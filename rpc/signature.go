@@ -0,0 +1,62 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignatureHeader is the HTTP header authenticating the sender of a request, as
+// <address>:<signature> where the signature is the EIP-191 personal signature of the
+// hex encoded keccak256 hash of the request body. The upgrade request of a WebSocket
+// connection has no body, it signs its request URI instead.
+const SignatureHeader = "X-Flashbots-Signature"
+
+var errInvalidSignature = errors.New("invalid " + SignatureHeader + " header")
+
+// recoverRequestSigner returns the address which signed the request body, checking it
+// matches the address claimed by the signature header.
+func recoverRequestSigner(header string, body []byte) (common.Address, error) {
+	claimed, encodedSig, ok := strings.Cut(header, ":")
+	if !ok || !common.IsHexAddress(claimed) {
+		return common.Address{}, errInvalidSignature
+	}
+	sig, err := hexutil.Decode(encodedSig)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return common.Address{}, errInvalidSignature
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	message := hexutil.Encode(crypto.Keccak256(body))
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	pubkey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, errInvalidSignature
+	}
+	signer := crypto.PubkeyToAddress(*pubkey)
+	if signer != common.HexToAddress(claimed) {
+		return common.Address{}, errInvalidSignature
+	}
+	return signer, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRecoverRequestSigner(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_sendBundle","params":[]}`)
+	message := hexutil.Encode(crypto.Keccak256(body))
+	hash := crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message)))
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	header := address.Hex() + ":" + hexutil.Encode(sig)
+
+	signer, err := recoverRequestSigner(header, body)
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	if signer != address {
+		t.Errorf("signer mismatch, expected %s, got %s", address, signer)
+	}
+
+	invalid := []struct {
+		name   string
+		header string
+		body   []byte
+	}{
+		{"tampered body", header, append([]byte{' '}, body...)},
+		{"other address", crypto.PubkeyToAddress(other.PublicKey).Hex() + ":" + hexutil.Encode(sig), body},
+		{"missing signature", address.Hex(), body},
+		{"short signature", address.Hex() + ":" + hexutil.Encode(sig[:64]), body},
+	}
+	for _, test := range invalid {
+		if _, err := recoverRequestSigner(test.header, test.body); err != errInvalidSignature {
+			t.Errorf("%s: expected invalid signature, got %v", test.name, err)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package rpc
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -26,6 +27,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/gorilla/websocket"
 )
 
@@ -148,6 +151,48 @@ func TestWebsocketPeerInfo(t *testing.T) {
 	}
 }
 
+// This test checks that the requests of a WebSocket connection are authenticated by the
+// signature of its upgrade request.
+func TestWebsocketSigner(t *testing.T) {
+	var (
+		s     = newTestServer()
+		ts    = httptest.NewServer(s.WebsocketHandler([]string{"*"}))
+		tsurl = "ws:" + strings.TrimPrefix(ts.URL, "http:")
+	)
+	defer s.Stop()
+	defer ts.Close()
+
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	sign := func(uri string) string {
+		message := hexutil.Encode(crypto.Keccak256([]byte(uri)))
+		sig, err := crypto.Sign(crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(message), message))), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signer.Hex() + ":" + hexutil.Encode(sig)
+	}
+
+	ctx := context.Background()
+	c, err := DialOptions(ctx, tsurl+"/stream", WithHeader(SignatureHeader, sign("/stream")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	var connInfo PeerInfo
+	if err := c.Call(&connInfo, "test_peerInfo"); err != nil {
+		t.Fatal(err)
+	}
+	if connInfo.HTTP.Signer != signer {
+		t.Errorf("wrong HTTP.Signer %s", connInfo.HTTP.Signer)
+	}
+
+	// the connections signed for another URI are refused
+	if _, err := DialOptions(ctx, tsurl+"/other", WithHeader(SignatureHeader, sign("/stream"))); err == nil {
+		t.Error("connection with invalid signature accepted")
+	}
+}
+
 // This test checks that client handles WebSocket ping frames correctly.
 func TestClientWebsocketPing(t *testing.T) {
 	t.Parallel()
@@ -22,6 +22,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 )
 
 var (
@@ -366,3 +368,36 @@ func copySet(set map[string]struct{}) map[string]struct{} {
 	}
 	return copied
 }
+
+// Tests that the witness of a trie proves the values read from it.
+func TestTrieWitness(t *testing.T) {
+	var (
+		db   = NewDatabase(rawdb.NewMemoryDatabase())
+		trie = NewEmpty(db)
+	)
+	for _, val := range standard {
+		trie.Update([]byte(val.k), []byte(val.v))
+	}
+	root, nodes := trie.Commit(false)
+	db.Update(NewWithNodeSet(nodes))
+
+	// opening the trie resolves its root node
+	trie, _ = New(TrieID(root), db)
+	if witness := trie.Witness(); len(witness) != 1 {
+		t.Fatalf("Unexpected witness of unread trie, %d nodes", len(witness))
+	}
+	key := []byte(standard[0].k)
+	trie.Get(key)
+
+	proof := memorydb.New()
+	for node := range trie.Witness() {
+		proof.Put(crypto.Keccak256([]byte(node)), []byte(node))
+	}
+	val, err := VerifyProof(root, key, proof)
+	if err != nil {
+		t.Fatalf("Failed to verify value with witness %v", err)
+	}
+	if !bytes.Equal(val, []byte(standard[0].v)) {
+		t.Fatalf("Unexpected value, got %x, want %x", val, standard[0].v)
+	}
+}
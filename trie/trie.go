@@ -552,6 +552,17 @@ func (t *Trie) Hash() common.Hash {
 	return common.BytesToHash(hash.(hashNode))
 }
 
+// Witness returns the encoded nodes loaded from the database since the trie was
+// opened or last committed. They prove the values read from the trie, and the
+// nodes restructured by the updates to it.
+func (t *Trie) Witness() map[string]struct{} {
+	witness := make(map[string]struct{}, len(t.tracer.accessList))
+	for _, blob := range t.tracer.accessList {
+		witness[string(blob)] = struct{}{}
+	}
+	return witness
+}
+
 // Commit collects all dirty nodes in the trie and replaces them with the
 // corresponding node hash. All collected nodes (including dirty leaves if
 // collectLeaf is true) will be encapsulated into a nodeset for return.